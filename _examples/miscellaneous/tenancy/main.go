@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/kataras/iris"
+
+	"github.com/kataras/iris/middleware/tenancy"
+)
+
+func main() {
+	app := iris.New()
+
+	t := tenancy.New(tenancy.Config{
+		Resolvers: []tenancy.Resolver{tenancy.FromPathParam("tenant")},
+	})
+	t.Register(tenancy.Tenant{ID: "acme", ViewData: iris.Map{"Title": "ACME Corporation"}})
+	t.Register(tenancy.Tenant{ID: "globex", ViewData: iris.Map{"Title": "Globex"}})
+
+	// http://localhost:8080/acme/users
+	// http://localhost:8080/globex/users
+	// http://localhost:8080/initech/users -> 404 Not Found
+	tenantRoutes := t.Party(app, "/{tenant}")
+	tenantRoutes.Get("/users", func(ctx iris.Context) {
+		ctx.Writef("users of the tenant: %s", tenancy.ID(ctx))
+	})
+
+	app.Run(iris.Addr(":8080"))
+}
@@ -51,12 +51,12 @@ func main() {
 	// The only one Required:
 	// here is how you define how your own context will
 	// be created and acquired from the iris' generic context pool.
-	app.ContextPool.Attach(func() context.Context {
+	app.Configure(iris.WithCustomContext(func(app context.Application) context.Context {
 		return &MyContext{
 			// Optional Part 3:
 			Context: context.NewContext(app),
 		}
-	})
+	}))
 
 	// Register a view engine on .html files inside the ./view/** directory.
 	app.RegisterView(iris.HTML("./view", ".html"))
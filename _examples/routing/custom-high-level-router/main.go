@@ -8,13 +8,15 @@ import (
 	"github.com/kataras/iris/core/router"
 )
 
-/* A Router should contain all three of the following methods:
+/* A Router should contain all four of the following methods:
    - HandleRequest should handle the request based on the Context.
 	  HandleRequest(ctx context.Context)
    - Build should builds the handler, it's being called on router's BuildRouter.
 	  Build(provider router.RoutesProvider) error
    - RouteExists reports whether a particular route exists.
       RouteExists(ctx context.Context, method, path string) bool
+   - AllowedMethods returns the methods of the routes that match a path.
+      AllowedMethods(ctx context.Context, path string) []string
 
 For a more detailed, complete and useful example
 you can take a look at the iris' router itself which is located at:
@@ -72,6 +74,11 @@ func (r *customRouter) RouteExists(ctx context.Context, method, path string) boo
 	return false
 }
 
+func (r *customRouter) AllowedMethods(ctx context.Context, path string) []string {
+	// [...]
+	return nil
+}
+
 func main() {
 	app := iris.New()
 
@@ -0,0 +1,240 @@
+// Package audit provides a tamper-evident audit log of the requests' actions, a compliance feature.
+//
+// Each entry is chained to the previous one through its hash, the SHA-256 of its JSON form which contains
+// the previous entry's hash, so a modified, removed or inserted entry breaks the chain, see `Verify`.
+// The entries are stored to a pluggable `Sink`, i.e a file (`FileSink`) or a database.
+//
+// The handlers append the entries through the `Record`, which enriches them with
+// the request id, the user (from the `context.GetClaims`) and the client's IP:
+//
+//	a, err := audit.New(sink)
+//	app.Use(a.Handler)
+//	app.Delete("/users/{id}", func(ctx iris.Context) {
+//		// [...delete the user]
+//		audit.Record(ctx, "user.delete", "user:"+ctx.Params().Get("id"), map[string]interface{}{"reason": "spam"})
+//	})
+//
+// Note that removing the last entries can't be detected by the chain itself, store the `Auditor#Head`
+// periodically to a different place (i.e a write-once storage) to detect that too.
+// 审计日志: 哈希链防篡改
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+)
+
+var (
+	// ErrTampered is returned by the `Verify` when the chain of the entries is broken.
+	ErrTampered = errors.New("audit: the chain is broken at the entry #%d: %s")
+	// ErrAuditorMissing is returned by the `Record` when no auditor middleware is registered.
+	ErrAuditorMissing = errors.New("audit: no auditor is registered for this request")
+)
+
+// Entry is an audit log entry.
+type Entry struct {
+	// Seq is the position of the entry in the chain, starting from 1.
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id,omitempty"`
+	User      string    `json:"user,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target,omitempty"`
+	// Details are the JSON encoded details of the action.
+	Details json.RawMessage `json:"details,omitempty"`
+	// PrevHash is the hash of the previous entry, empty for the first one.
+	PrevHash string `json:"prev_hash"`
+	// Hash is the hex encoded SHA-256 of the entry's JSON form, without the hash itself.
+	Hash string `json:"hash"`
+}
+
+// ComputeHash returns the hash of the entry, its `Hash` field is not included.
+func (e Entry) ComputeHash() string {
+	e.Hash = ""
+	b, _ := json.Marshal(e)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Auditor appends the chained entries to its sink.
+type Auditor struct {
+	config Config
+	sink   Sink
+
+	mu       sync.Mutex
+	seq      uint64
+	lastHash string
+}
+
+// New returns a new Auditor which stores the entries to the "sink",
+// the chain is continued after the sink's last entry, if any.
+// The default configs are used if "c" is missing.
+func New(sink Sink, c ...Config) (*Auditor, error) {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		config = c[0]
+		if config.RequestIDHeader == "" {
+			config.RequestIDHeader = DefaultConfig().RequestIDHeader
+		}
+		if config.UserClaim == "" {
+			config.UserClaim = DefaultConfig().UserClaim
+		}
+	}
+
+	a := &Auditor{config: config, sink: sink}
+	last, ok, err := sink.Last()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		a.seq, a.lastHash = last.Seq, last.Hash
+	}
+
+	return a, nil
+}
+
+// auditorContextKey is the context's values key of the request's auditor, see `Auditor#Handler`.
+const auditorContextKey = "iris.auditor"
+
+// Handler is the middleware which registers the auditor to the requests, see `Record`.
+func (a *Auditor) Handler(ctx context.Context) {
+	ctx.Values().Set(auditorContextKey, a)
+	ctx.Next()
+}
+
+// Get returns the auditor of the request, as registered by the `Auditor#Handler`,
+// nil if no auditor middleware is registered.
+func Get(ctx context.Context) *Auditor {
+	if a, ok := ctx.Values().Get(auditorContextKey).(*Auditor); ok {
+		return a
+	}
+
+	return nil
+}
+
+// Record appends an audit entry of the "action" (i.e "user.delete") on the "target" (i.e "user:42")
+// through the request's auditor, as registered by the `Auditor#Handler`,
+// it returns the `ErrAuditorMissing` if no auditor is registered.
+//
+// Usage: audit.Record(ctx, "user.delete", "user:42", map[string]interface{}{"reason": "spam"})
+func Record(ctx context.Context, action, target string, details map[string]interface{}) error {
+	a := Get(ctx)
+	if a == nil {
+		return ErrAuditorMissing
+	}
+
+	return a.Audit(ctx, action, target, details)
+}
+
+// Audit appends an entry of the "action" on the "target", enriched with the request's id, user and IP.
+// The "ctx" can be nil, i.e for the actions of the background jobs.
+func (a *Auditor) Audit(ctx context.Context, action, target string, details map[string]interface{}) error {
+	e := Entry{Action: action, Target: target}
+	if len(details) > 0 {
+		b, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+		e.Details = b
+	}
+
+	if ctx != nil {
+		e.RequestID = ctx.GetHeader(a.config.RequestIDHeader)
+		e.User = context.GetClaims(ctx).GetString(a.config.UserClaim)
+		e.IP = ctx.RemoteAddr()
+		e.Method = ctx.Method()
+		e.Path = ctx.Path()
+	}
+
+	_, err := a.Append(e)
+	return err
+}
+
+// Append chains and stores the "e" entry, its `Seq`, `Time` (if zero), `PrevHash` and `Hash` are filled,
+// and returns the stored entry.
+func (a *Auditor) Append(e Entry) (Entry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	// as it's decoded from the JSON form, so the hash can be verified.
+	e.Time = e.Time.Round(0).UTC()
+	e.Seq = a.seq + 1
+	e.PrevHash = a.lastHash
+	e.Hash = e.ComputeHash()
+
+	if err := a.sink.Append(e); err != nil {
+		if a.config.OnError != nil {
+			a.config.OnError(err)
+		}
+		return e, err
+	}
+
+	a.seq, a.lastHash = e.Seq, e.Hash
+	return e, nil
+}
+
+// Head returns the sequence and the hash of the last entry.
+func (a *Auditor) Head() (uint64, string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.seq, a.lastHash
+}
+
+// Verify verifies the chain of the "entries", they can be a part of the whole chain, i.e after a rotation.
+// It returns an `ErrTampered` at the first modified entry or broken link.
+func Verify(entries []Entry) error {
+	for i, e := range entries {
+		if e.ComputeHash() != e.Hash {
+			return ErrTampered.Format(e.Seq, "the hash does not match its content")
+		}
+
+		if i == 0 {
+			if e.Seq == 1 && e.PrevHash != "" {
+				return ErrTampered.Format(e.Seq, "the first entry has a previous hash")
+			}
+			continue
+		}
+
+		prev := entries[i-1]
+		if e.Seq != prev.Seq+1 {
+			return ErrTampered.Format(e.Seq, "the sequence does not follow the previous entry")
+		}
+		if e.PrevHash != prev.Hash {
+			return ErrTampered.Format(e.Seq, "the previous hash does not match the previous entry")
+		}
+	}
+
+	return nil
+}
+
+// VerifyFile verifies the chain of the entries of the "filename", as written by the `FileSink`.
+func VerifyFile(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	if err = ReadEntries(f, func(e Entry) error {
+		entries = append(entries, e)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return Verify(entries)
+}
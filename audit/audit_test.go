@@ -0,0 +1,100 @@
+package audit_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/audit"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestAudit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "audit.log")
+
+	sink, err := audit.NewFileSink(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := audit.New(sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := iris.New()
+	app.Get("/unaudited", func(ctx context.Context) {
+		if err := audit.Record(ctx, "noop", "", nil); !audit.ErrAuditorMissing.Equal(err) {
+			t.Fatalf("expected the ErrAuditorMissing but got: %v", err)
+		}
+	})
+	app.Use(a.Handler)
+	app.Delete("/users/{id}", func(ctx context.Context) {
+		context.SetClaims(ctx, context.Claims{"sub": "admin"})
+		if err := audit.Record(ctx, "user.delete", "user:"+ctx.Params().Get("id"), map[string]interface{}{"reason": "spam"}); err != nil {
+			ctx.StatusCode(iris.StatusInternalServerError)
+		}
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/unaudited").Expect().Status(httptest.StatusOK)
+	e.DELETE("/users/42").WithHeader("X-Request-Id", "req-1").Expect().Status(httptest.StatusOK)
+	e.DELETE("/users/43").Expect().Status(httptest.StatusOK)
+	sink.Close()
+
+	// the chain is continued after a restart.
+	sink, err = audit.NewFileSink(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, err = audit.New(sink); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = a.Append(audit.Entry{Action: "job.cleanup"}); err != nil {
+		t.Fatal(err)
+	}
+	if seq, _ := a.Head(); seq != 3 {
+		t.Fatalf("expected the head at 3 but got %d", seq)
+	}
+	sink.Close()
+
+	if err = audit.VerifyFile(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []audit.Entry
+	f, _ := os.Open(filename)
+	audit.ReadEntries(f, func(e audit.Entry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	f.Close()
+
+	first := entries[0]
+	if first.Action != "user.delete" || first.Target != "user:42" || first.User != "admin" ||
+		first.RequestID != "req-1" || first.Path != "/users/42" || string(first.Details) != `{"reason":"spam"}` {
+		t.Fatalf("unexpected enrichment of the entry: %#v", first)
+	}
+
+	// modified.
+	tampered := append([]audit.Entry(nil), entries...)
+	tampered[1].User = "someone"
+	if err = audit.Verify(tampered); !audit.ErrTampered.Equal(err) || !strings.Contains(err.Error(), "#2") {
+		t.Fatalf("expected the modified entry to be detected but got: %v", err)
+	}
+
+	// removed.
+	removed := []audit.Entry{entries[0], entries[2]}
+	if err = audit.Verify(removed); !audit.ErrTampered.Equal(err) {
+		t.Fatalf("expected the removed entry to be detected but got: %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package audit
+
+// Config the configs for the audit logger.
+type Config struct {
+	// RequestIDHeader is the request header of the request's id, i.e set by a gateway.
+	//
+	// Defaults to "X-Request-Id".
+	RequestIDHeader string
+	// UserClaim is the claim of the `context.GetClaims` which identifies the user.
+	//
+	// Defaults to "sub".
+	UserClaim string
+	// OnError is called when an entry can't be stored by the sink,
+	// the `Record` returns the error too.
+	//
+	// Defaults to nil.
+	OnError func(err error)
+}
+
+// DefaultConfig returns the default configs for the audit logger.
+func DefaultConfig() Config {
+	return Config{
+		RequestIDHeader: "X-Request-Id",
+		UserClaim:       "sub",
+	}
+}
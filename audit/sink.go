@@ -0,0 +1,145 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// Sink stores the audit entries, in order.
+// Implement it to store the entries to a database, the `FileSink` and the `MemorySink` are provided.
+type Sink interface {
+	// Append stores the "entry" after the last one.
+	Append(entry Entry) error
+	// Last returns the last stored entry, false if there is no one,
+	// so the chain is continued after a restart.
+	Last() (Entry, bool, error)
+}
+
+// FileSink stores the entries to a file, one JSON document per line.
+type FileSink struct {
+	mu   sync.Mutex
+	f    *os.File
+	last *Entry
+}
+
+var _ Sink = (*FileSink)(nil)
+
+// NewFileSink opens (or creates) the "filename" file for appending the entries.
+func NewFileSink(filename string) (*FileSink, error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileSink{f: f}
+	if err = ReadEntries(f, func(e Entry) error {
+		s.last = &e
+		return nil
+	}); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Append writes the "entry" as a new line and flushes it to the disk.
+func (s *FileSink) Append(entry Entry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err = s.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if err = s.f.Sync(); err != nil {
+		return err
+	}
+
+	s.last = &entry
+	return nil
+}
+
+// Last returns the last entry of the file.
+func (s *FileSink) Last() (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last == nil {
+		return Entry{}, false, nil
+	}
+	return *s.last, true, nil
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	return s.f.Close()
+}
+
+// MemorySink keeps the entries in memory, i.e for tests.
+type MemorySink struct {
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+var _ Sink = (*MemorySink)(nil)
+
+// Append keeps the "entry".
+func (s *MemorySink) Append(entry Entry) error {
+	s.mu.Lock()
+	s.entries = append(s.entries, entry)
+	s.mu.Unlock()
+	return nil
+}
+
+// Last returns the last kept entry.
+func (s *MemorySink) Last() (Entry, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.entries) == 0 {
+		return Entry{}, false, nil
+	}
+	return s.entries[len(s.entries)-1], true, nil
+}
+
+// Entries returns a copy of the kept entries.
+func (s *MemorySink) Entries() []Entry {
+	s.mu.RLock()
+	entries := make([]Entry, len(s.entries))
+	copy(entries, s.entries)
+	s.mu.RUnlock()
+	return entries
+}
+
+// ReadEntries reads the entries of the "r", as written by the `FileSink`, and calls the "fn" for each one.
+func ReadEntries(r io.Reader, fn func(Entry) error) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var e Entry
+			if uerr := json.Unmarshal(line, &e); uerr != nil {
+				return uerr
+			}
+			if ferr := fn(e); ferr != nil {
+				return ferr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
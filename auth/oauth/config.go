@@ -0,0 +1,58 @@
+package oauth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// Config is the configuration of the OAuth login flows, see `New`.
+type Config struct {
+	// Providers are the configured identity providers, i.e the `Google` and `GitHub` ones,
+	// their names should be unique.
+	Providers []*Provider
+	// SecretKey is the key which signs the state and the login cookies (HMAC-SHA256), it's required.
+	// Use a random key of at least 32 bytes and keep it the same between the restarts.
+	SecretKey []byte
+	// CookieName is the name of the login cookie,
+	// the state cookie of the pending logins is named after it with a "_state" suffix.
+	//
+	// Defaults to "iris_oauth".
+	CookieName string
+	// MaxAge is the lifetime of the login cookie.
+	//
+	// Defaults to 24 hours.
+	MaxAge time.Duration
+	// SuccessRedirect is the path that the user is redirected to after a successful login,
+	// when the login request has no "redirect" url parameter.
+	//
+	// Defaults to "/".
+	SuccessRedirect string
+	// OnLogin is fired after the identity is verified and before the login cookie is set,
+	// i.e to create or update the user's record. A non-nil error fails the login.
+	// Optional.
+	OnLogin func(ctx context.Context, user *context.OAuthUser) error
+	// OnError is fired when a login fails, i.e the state does not match or the provider is down.
+	//
+	// Defaults to a 401 Unauthorized, so the registered error handler is fired.
+	OnError func(ctx context.Context, err error)
+	// Client is the http client which communicates with the providers' token and user info endpoints.
+	//
+	// Defaults to a client with a timeout of 10 seconds.
+	Client *http.Client
+}
+
+// DefaultConfig returns the default configuration, the `Providers` and the `SecretKey` should be set.
+func DefaultConfig() Config {
+	return Config{
+		CookieName:      "iris_oauth",
+		MaxAge:          24 * time.Hour,
+		SuccessRedirect: "/",
+		OnError: func(ctx context.Context, err error) {
+			context.Logger(ctx).Debugf("oauth: %v", err)
+			ctx.StatusCode(http.StatusUnauthorized)
+		},
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
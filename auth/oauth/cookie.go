@@ -0,0 +1,95 @@
+package oauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// encodeCookie is the `context.CookieEncoder` which signs the (base64) "value" with the secret key,
+// the cookie's name is signed too, so a value can not be moved to another cookie.
+func (o *OAuth) encodeCookie(cookieName string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", errInvalidCookie
+	}
+
+	return s + "." + o.sign(cookieName, s), nil
+}
+
+// decodeCookie is the `context.CookieDecoder` which verifies the signature of the "cookieValue",
+// the "v" should be a string pointer.
+func (o *OAuth) decodeCookie(cookieName string, cookieValue string, v interface{}) error {
+	ptr, ok := v.(*string)
+	if !ok {
+		return errInvalidCookie
+	}
+
+	idx := strings.LastIndexByte(cookieValue, '.')
+	if idx <= 0 {
+		return errInvalidCookie
+	}
+
+	payload, signature := cookieValue[:idx], cookieValue[idx+1:]
+	if !hmac.Equal([]byte(signature), []byte(o.sign(cookieName, payload))) {
+		return errInvalidCookie
+	}
+
+	*ptr = payload
+	return nil
+}
+
+func (o *OAuth) sign(cookieName, payload string) string {
+	mac := hmac.New(sha256.New, o.config.SecretKey)
+	mac.Write([]byte(cookieName))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// setSignedCookie sets the "name" cookie to the signed JSON of the "v", it expires after the "maxAge".
+func (o *OAuth) setSignedCookie(ctx context.Context, name string, v interface{}, maxAge time.Duration) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	ctx.SetCookie(&http.Cookie{
+		Name:     name,
+		Value:    base64.RawURLEncoding.EncodeToString(b),
+		Path:     "/",
+		Expires:  time.Now().Add(maxAge),
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   context.IsTLS(ctx),
+		// Lax, so the cookie is sent on the redirect back from the provider.
+		SameSite: http.SameSiteLaxMode,
+	}, context.CookieEncode(o.encodeCookie))
+
+	return nil
+}
+
+// getSignedCookie decodes the verified JSON of the "name" cookie to the "v".
+func (o *OAuth) getSignedCookie(ctx context.Context, name string, v interface{}) error {
+	payload := ctx.GetCookie(name, context.CookieDecode(o.decodeCookie))
+	if payload == "" {
+		return errInvalidCookie
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return errInvalidCookie
+	}
+
+	if err = json.Unmarshal(b, v); err != nil {
+		return errInvalidCookie
+	}
+
+	return nil
+}
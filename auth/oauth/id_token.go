@@ -0,0 +1,59 @@
+package oauth
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// verifyIDToken verifies the claims of the OpenID Connect "idToken":
+// the "aud" should contain the client ID, the "exp" should be in the future,
+// the "nonce" should match and the "iss" should be the `Provider#Issuer`, if set.
+//
+// The signature is not verified, the ID token is received directly from the token endpoint
+// over TLS, which is allowed by the OpenID Connect Core 1.0, section 3.1.3.7.
+func (p *Provider) verifyIDToken(idToken, nonce string) (context.Claims, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, errInvalidIDToken.Format("malformed")
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, errInvalidIDToken.Format("malformed")
+	}
+
+	claims := make(context.Claims)
+	if err = json.Unmarshal(b, &claims); err != nil {
+		return nil, errInvalidIDToken.Format("malformed")
+	}
+
+	if p.Issuer != "" && claims.Issuer() != p.Issuer {
+		return nil, errInvalidIDToken.Format("issuer mismatch")
+	}
+
+	audOK := false
+	for _, aud := range claims.Audience() {
+		if aud == p.ClientID {
+			audOK = true
+			break
+		}
+	}
+	if !audOK {
+		return nil, errInvalidIDToken.Format("audience mismatch")
+	}
+
+	if claims.ExpiresAt().IsZero() || claims.Expired(time.Now()) {
+		return nil, errInvalidIDToken.Format("expired")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.GetString("nonce")), []byte(nonce)) != 1 {
+		return nil, errInvalidIDToken.Format("nonce mismatch")
+	}
+
+	return claims, nil
+}
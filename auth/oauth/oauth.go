@@ -0,0 +1,325 @@
+// Package oauth provides the OAuth2 and OpenID Connect login flows:
+// the provider configuration (i.e `Google` and `GitHub`), the login and callback routes of a Party,
+// the state, nonce and PKCE handling through signed cookies and the verified identity
+// of the logged in user, which is exposed through the `User(ctx)`.
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+	"github.com/kataras/iris/core/router"
+)
+
+var (
+	errNoSecretKey       = errors.New("oauth: the secret key is missing")
+	errInvalidProvider   = errors.New("oauth: invalid provider '%s'")
+	errDuplicateProvider = errors.New("oauth: duplicate provider '%s'")
+	errNoRedirectURL     = errors.New("oauth: the redirect url of the provider '%s' is missing or not absolute")
+	errInvalidCookie     = errors.New("oauth: invalid or expired cookie")
+	errInvalidState      = errors.New("oauth: invalid or expired state")
+	errMissingCode       = errors.New("oauth: the authorization code is missing")
+	errProvider          = errors.New("oauth: provider error '%s': %s")
+	errInvalidResponse   = errors.New("oauth: invalid response of '%s', status code %d")
+	errInvalidIDToken    = errors.New("oauth: invalid ID token: %s")
+	errNoUserID          = errors.New("oauth: the user ID is missing")
+)
+
+// stateMaxAge is the time that a user has to complete a login at the provider.
+const stateMaxAge = 10 * time.Minute
+
+// loginState is the content of the state cookie of a pending login.
+type loginState struct {
+	Provider string `json:"p"`
+	State    string `json:"s"`
+	Nonce    string `json:"n,omitempty"`
+	Verifier string `json:"v"`
+	// RedirectURL is the callback url sent to the provider, it should be sent on the code exchange too.
+	RedirectURL string `json:"u"`
+	// Return is the path that the user is redirected to after the login.
+	Return  string `json:"r,omitempty"`
+	Expires int64  `json:"e"`
+}
+
+// loginCookie is the content of the login cookie.
+type loginCookie struct {
+	context.OAuthUser
+	Expires int64 `json:"exp"`
+}
+
+// OAuth serves the login flows of the configured providers, see `New`.
+// 内置的OAuth2/OIDC登录流程(state/nonce/PKCE保存在签名的cookie中)
+type OAuth struct {
+	config    Config
+	providers map[string]*Provider
+}
+
+// New returns a new OAuth of the "c" configuration,
+// it returns an error if the `Config#SecretKey` is missing or a provider is invalid,
+// i.e its `Provider#RedirectURL` is missing.
+//
+// Usage:
+//
+//	google := oauth.Google(googleID, googleSecret)
+//	google.RedirectURL = "https://example.com/auth/google/callback"
+//	o, err := oauth.New(oauth.Config{
+//	  SecretKey: []byte(os.Getenv("OAUTH_SECRET")),
+//	  Providers: []*oauth.Provider{google},
+//	})
+//	app.Use(o.Handler)
+//	o.Register(app.Party("/auth")) // GET /auth/google, /auth/google/callback and so on.
+//	app.Get("/me", func(ctx iris.Context) {
+//	  user := oauth.User(ctx)
+//	  if user == nil {
+//	    ctx.Redirect("/auth/google?redirect=/me")
+//	    return
+//	  }
+//	  ctx.JSON(user)
+//	})
+func New(c Config) (*OAuth, error) {
+	if len(c.SecretKey) == 0 {
+		return nil, errNoSecretKey
+	}
+
+	def := DefaultConfig()
+	if c.CookieName == "" {
+		c.CookieName = def.CookieName
+	}
+	if c.MaxAge <= 0 {
+		c.MaxAge = def.MaxAge
+	}
+	if c.SuccessRedirect == "" {
+		c.SuccessRedirect = def.SuccessRedirect
+	}
+	if c.OnError == nil {
+		c.OnError = def.OnError
+	}
+	if c.Client == nil {
+		c.Client = def.Client
+	}
+
+	o := &OAuth{config: c, providers: make(map[string]*Provider, len(c.Providers))}
+	for _, p := range c.Providers {
+		if p == nil || p.Name == "" || strings.Contains(p.Name, "/") || p.ClientID == "" ||
+			p.AuthURL == "" || p.TokenURL == "" || (p.UserInfoURL == "" && !p.isOpenID()) {
+			name := ""
+			if p != nil {
+				name = p.Name
+			}
+			return nil, errInvalidProvider.Format(name)
+		}
+
+		// not built from the request's host, which can be spoofed.
+		if u, err := url.Parse(p.RedirectURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, errNoRedirectURL.Format(p.Name)
+		}
+
+		if _, ok := o.providers[p.Name]; ok {
+			return nil, errDuplicateProvider.Format(p.Name)
+		}
+		o.providers[p.Name] = p
+	}
+
+	return o, nil
+}
+
+// Register registers the login and the callback routes of each provider to the "p" Party,
+// the "/{provider}" which redirects the user to the provider
+// and the "/{provider}/callback" which verifies the identity, sets the login cookie
+// and redirects the user to the login's "redirect" url parameter, if it's safe (see `Context#IsSafeRedirect`),
+// or to the `Config#SuccessRedirect`.
+//
+// The `Provider#RedirectURL` should be the "/{provider}/callback" of the Party,
+// i.e "https://example.com/auth/google/callback".
+func (o *OAuth) Register(p router.Party) {
+	p.Get("/{provider:string}", o.login)
+	p.Get("/{provider:string}/callback", o.callback)
+}
+
+// User returns the verified identity of the user logged in through an OAuth2/OpenID Connect provider,
+// as set by the `OAuth#Handler` or the callback, nil if not logged in.
+func User(ctx context.Context) *context.OAuthUser {
+	if u, ok := ctx.Values().Get(context.OAuthUserContextKey).(*context.OAuthUser); ok {
+		return u
+	}
+
+	return nil
+}
+
+// Handler is the middleware which restores the logged in user of the login cookie,
+// so it's available through the `User(ctx)` on the next handlers.
+// It should be registered before the handlers which read the user, i.e with the `Use` or `UseGlobal`.
+func (o *OAuth) Handler(ctx context.Context) {
+	if User(ctx) == nil {
+		var c loginCookie
+		if err := o.getSignedCookie(ctx, o.config.CookieName, &c); err == nil && time.Now().Unix() <= c.Expires {
+			user := c.OAuthUser
+			ctx.Values().Set(context.OAuthUserContextKey, &user)
+		}
+	}
+
+	ctx.Next()
+}
+
+// Logout removes the login cookie and the user of the current request.
+//
+// Usage:
+//
+//	app.Get("/logout", func(ctx iris.Context) {
+//	  o.Logout(ctx)
+//	  ctx.Redirect("/")
+//	})
+func (o *OAuth) Logout(ctx context.Context) {
+	ctx.RemoveCookie(o.config.CookieName)
+	ctx.Values().Remove(context.OAuthUserContextKey)
+}
+
+func (o *OAuth) stateCookieName() string {
+	return o.config.CookieName + "_state"
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (o *OAuth) login(ctx context.Context) {
+	p, ok := o.providers[ctx.Params().Get("provider")]
+	if !ok {
+		ctx.NotFound()
+		return
+	}
+
+	s := loginState{
+		Provider:    p.Name,
+		State:       randomString(),
+		Verifier:    randomString(),
+		RedirectURL: p.RedirectURL,
+		Expires:     time.Now().Add(stateMaxAge).Unix(),
+	}
+
+	if p.isOpenID() {
+		s.Nonce = randomString()
+	}
+
+	// the "redirect" url parameter can not be used as an open redirect.
+	if returnTo := ctx.URLParam("redirect"); returnTo != "" && ctx.IsSafeRedirect(returnTo) {
+		s.Return = returnTo
+	}
+
+	if err := o.setSignedCookie(ctx, o.stateCookieName(), s, stateMaxAge); err != nil {
+		o.config.OnError(ctx, err)
+		return
+	}
+
+	challenge := sha256.Sum256([]byte(s.Verifier))
+	ctx.Redirect(p.authCodeURL(s.RedirectURL, s.State, s.Nonce, base64.RawURLEncoding.EncodeToString(challenge[:])), http.StatusFound)
+}
+
+func (o *OAuth) callback(ctx context.Context) {
+	p, ok := o.providers[ctx.Params().Get("provider")]
+	if !ok {
+		ctx.NotFound()
+		return
+	}
+
+	user, returnTo, err := o.verify(ctx, p)
+	if err != nil {
+		o.config.OnError(ctx, err)
+		return
+	}
+
+	ctx.Values().Set(context.OAuthUserContextKey, user)
+
+	if o.config.OnLogin != nil {
+		if err = o.config.OnLogin(ctx, user); err != nil {
+			ctx.Values().Remove(context.OAuthUserContextKey)
+			o.config.OnError(ctx, err)
+			return
+		}
+	}
+
+	c := loginCookie{OAuthUser: *user, Expires: time.Now().Add(o.config.MaxAge).Unix()}
+	if err = o.setSignedCookie(ctx, o.config.CookieName, c, o.config.MaxAge); err != nil {
+		o.config.OnError(ctx, err)
+		return
+	}
+
+	if returnTo == "" {
+		returnTo = o.config.SuccessRedirect
+	}
+	ctx.Redirect(returnTo, http.StatusFound)
+}
+
+// verify verifies the callback request of the "p" provider, it returns the verified user
+// and the path that the user should be redirected to, if any.
+func (o *OAuth) verify(ctx context.Context, p *Provider) (*context.OAuthUser, string, error) {
+	var s loginState
+	err := o.getSignedCookie(ctx, o.stateCookieName(), &s)
+	ctx.RemoveCookie(o.stateCookieName()) // a state is used once.
+	if err != nil {
+		return nil, "", errInvalidState
+	}
+
+	if code := ctx.URLParam("error"); code != "" {
+		return nil, "", errProvider.Format(code, ctx.URLParam("error_description"))
+	}
+
+	if s.Provider != p.Name || time.Now().Unix() > s.Expires ||
+		subtle.ConstantTimeCompare([]byte(ctx.URLParam("state")), []byte(s.State)) != 1 {
+		return nil, "", errInvalidState
+	}
+
+	code := ctx.URLParam("code")
+	if code == "" {
+		return nil, "", errMissingCode
+	}
+
+	t, err := p.exchange(context.StdContext(ctx), o.config.Client, code, s.RedirectURL, s.Verifier)
+	if err != nil {
+		return nil, "", err
+	}
+
+	claims := make(context.Claims)
+	if t.IDToken != "" {
+		if claims, err = p.verifyIDToken(t.IDToken, s.Nonce); err != nil {
+			return nil, "", err
+		}
+	} else if p.isOpenID() {
+		return nil, "", errInvalidIDToken.Format("missing")
+	}
+
+	if p.UserInfoURL != "" {
+		info, err := p.userInfo(context.StdContext(ctx), o.config.Client, t.AccessToken)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if sub := claims.Subject(); sub != "" && info.Subject() != "" && info.Subject() != sub {
+			return nil, "", errInvalidIDToken.Format("subject mismatch")
+		}
+
+		for k, v := range info {
+			claims[k] = v
+		}
+	}
+
+	user := p.newUser(claims, t)
+	if user.ID == "" {
+		return nil, "", errNoUserID
+	}
+
+	return user, s.Return, nil
+}
@@ -0,0 +1,228 @@
+package oauth_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	stdhttptest "net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iris-contrib/httpexpect"
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/auth/oauth"
+	"github.com/kataras/iris/httptest"
+)
+
+const redirectURL = "https://example.com/auth/fake/callback"
+
+// fakeProvider is an identity provider which accepts the "code" authorization code.
+type fakeProvider struct {
+	*stdhttptest.Server
+	// challenge is the code challenge of the last login, the nonce is the one of the ID token.
+	challenge string
+	nonce     string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	p := new(fakeProvider)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		verifier := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if r.FormValue("code") != "code" || r.FormValue("redirect_uri") != redirectURL ||
+			base64.RawURLEncoding.EncodeToString(verifier[:]) != p.challenge {
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		t := map[string]interface{}{"access_token": "token", "token_type": "bearer", "expires_in": 3600}
+		if p.nonce != "" {
+			claims, _ := json.Marshal(map[string]interface{}{
+				"sub": "42", "aud": "client", "exp": time.Now().Add(time.Minute).Unix(), "nonce": p.nonce,
+			})
+			t["id_token"] = "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".sig"
+		}
+		json.NewEncoder(w).Encode(t)
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 42, "login": "iris", "email": "iris@example.com"})
+	})
+
+	p.Server = stdhttptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *fakeProvider) provider(scopes ...string) *oauth.Provider {
+	return &oauth.Provider{
+		Name:        "fake",
+		ClientID:    "client",
+		AuthURL:     p.URL + "/authorize",
+		TokenURL:    p.URL + "/token",
+		UserInfoURL: p.URL + "/userinfo",
+		Scopes:      scopes,
+		RedirectURL: redirectURL,
+	}
+}
+
+func newApp(t *testing.T, provider *oauth.Provider, configurators ...iris.Configurator) *iris.Application {
+	o, err := oauth.New(oauth.Config{SecretKey: []byte("secret"), Providers: []*oauth.Provider{provider}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := iris.New().Configure(configurators...)
+	app.Use(o.Handler)
+	o.Register(app.Party("/auth"))
+	app.Get("/me", func(ctx iris.Context) {
+		user := oauth.User(ctx)
+		if user == nil {
+			ctx.StatusCode(iris.StatusUnauthorized)
+			return
+		}
+		ctx.Writef("%s %s %s", user.Provider, user.ID, user.Name)
+	})
+
+	return app
+}
+
+// login starts the login of a new client, the client keeps the state cookie,
+// it returns the client and the query of the authorization url.
+func login(t *testing.T, app *iris.Application, p *fakeProvider, returnTo string) (*httpexpect.Expect, url.Values) {
+	t.Helper()
+
+	e := httptest.New(t, app, httptest.URL("http://app.local"))
+	r := e.GET("/auth/fake")
+	if returnTo != "" {
+		r.WithQuery("redirect", returnTo)
+	}
+
+	// the client follows the redirect to the provider.
+	u := r.Expect().Raw().Request.URL
+	if !strings.HasPrefix(u.String(), p.URL+"/authorize?") {
+		t.Fatalf("expected a redirect to the provider but got %s", u)
+	}
+
+	q := u.Query()
+	p.challenge = q.Get("code_challenge")
+	return e, q
+}
+
+func TestNew(t *testing.T) {
+	p := newFakeProvider(t)
+
+	if _, err := oauth.New(oauth.Config{Providers: []*oauth.Provider{p.provider()}}); err == nil {
+		t.Fatalf("expected an error of the missing secret key")
+	}
+
+	for _, redirect := range []string{"", "/auth/fake/callback"} {
+		provider := p.provider()
+		provider.RedirectURL = redirect
+		if _, err := oauth.New(oauth.Config{SecretKey: []byte("secret"), Providers: []*oauth.Provider{provider}}); err == nil {
+			t.Fatalf("expected an error of the redirect url %q", redirect)
+		}
+	}
+
+	if _, err := oauth.New(oauth.Config{SecretKey: []byte("secret"), Providers: []*oauth.Provider{p.provider(), p.provider()}}); err == nil {
+		t.Fatalf("expected an error of the duplicate provider")
+	}
+}
+
+func TestLogin(t *testing.T) {
+	p := newFakeProvider(t)
+	app := newApp(t, p.provider())
+
+	e, q := login(t, app, p, "/me")
+	// the configured one, not of the request's host.
+	if got := q.Get("redirect_uri"); got != redirectURL {
+		t.Fatalf("expected the redirect uri %q but got %q", redirectURL, got)
+	}
+	if q.Get("state") == "" || q.Get("code_challenge_method") != "S256" || q.Get("nonce") != "" {
+		t.Fatalf("expected a state and a code challenge without a nonce but got %v", q)
+	}
+
+	// redirected to the '/me' with the login cookie.
+	r := e.GET("/auth/fake/callback").WithQuery("code", "code").WithQuery("state", q.Get("state")).Expect()
+	r.Status(iris.StatusOK).Body().Equal("fake 42 iris")
+	if got := r.Raw().Request.URL.Path; got != "/me" {
+		t.Fatalf("expected a redirect to the '/me' but got %q", got)
+	}
+}
+
+func TestLoginCookieSecret(t *testing.T) {
+	p := newFakeProvider(t)
+	// the login cookies are signed by the oauth's key, the application's cookie secret does not reject them.
+	app := newApp(t, p.provider(), iris.WithCookieSecret("app secret"))
+
+	e, q := login(t, app, p, "/me")
+	e.GET("/auth/fake/callback").WithQuery("code", "code").WithQuery("state", q.Get("state")).Expect().
+		Status(iris.StatusOK).Body().Equal("fake 42 iris")
+	e.GET("/me").Expect().Status(iris.StatusOK)
+}
+
+func TestLoginUnsafeRedirect(t *testing.T) {
+	p := newFakeProvider(t)
+	app := newApp(t, p.provider())
+
+	for _, returnTo := range []string{"//evil.com", "/\\evil.com", "https://evil.com/me", "http://app.local/me"} {
+		e, q := login(t, app, p, returnTo)
+		u := e.GET("/auth/fake/callback").WithQuery("code", "code").WithQuery("state", q.Get("state")).Expect().Raw().Request.URL
+
+		expected := "http://app.local/"
+		if returnTo == "http://app.local/me" {
+			// the current host.
+			expected = returnTo
+		}
+		if got := u.String(); got != expected {
+			t.Fatalf("expected the redirect of the %q to be %q but got %q", returnTo, expected, got)
+		}
+	}
+}
+
+func TestCallbackInvalid(t *testing.T) {
+	p := newFakeProvider(t)
+	app := newApp(t, p.provider())
+
+	_, q := login(t, app, p, "")
+	// without the state cookie, a client which did not start the login.
+	httptest.New(t, app, httptest.URL("http://app.local")).GET("/auth/fake/callback").
+		WithQueryString("code=code&state=" + q.Get("state")).Expect().Status(iris.StatusUnauthorized)
+
+	for i, query := range []string{"code=code&state=other", "code=other&state={state}", "error=access_denied&state={state}"} {
+		e, q := login(t, app, p, "")
+		res := e.GET("/auth/fake/callback").WithQueryString(strings.Replace(query, "{state}", q.Get("state"), 1)).Expect()
+		res.Status(iris.StatusUnauthorized)
+		if cookies := res.Raw().Cookies(); len(cookies) > 1 {
+			t.Fatalf("[%d] expected a 401 without a login cookie but got %v", i, cookies)
+		}
+	}
+}
+
+func TestOpenID(t *testing.T) {
+	p := newFakeProvider(t)
+	app := newApp(t, p.provider("openid"))
+
+	e, q := login(t, app, p, "")
+	if q.Get("nonce") == "" {
+		t.Fatalf("expected a nonce")
+	}
+
+	p.nonce = "other"
+	e.GET("/auth/fake/callback").WithQuery("code", "code").WithQuery("state", q.Get("state")).Expect().
+		Status(iris.StatusUnauthorized)
+
+	e, q = login(t, app, p, "")
+	p.nonce = q.Get("nonce")
+	// redirected to the root path after the login.
+	if got := e.GET("/auth/fake/callback").WithQuery("code", "code").WithQuery("state", q.Get("state")).Expect().Raw().Request.URL.Path; got != "/" {
+		t.Fatalf("expected a redirect after the login but got %q", got)
+	}
+}
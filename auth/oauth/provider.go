@@ -0,0 +1,254 @@
+package oauth
+
+import (
+	stdContext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// Provider is the configuration of an OAuth2 or OpenID Connect identity provider.
+type Provider struct {
+	// Name is the name of the provider, it's the last segment of the login path,
+	// i.e "google" for the "/auth/google" and "/auth/google/callback".
+	Name         string
+	ClientID     string
+	ClientSecret string
+	// AuthURL is the authorization endpoint that the user is redirected to.
+	AuthURL string
+	// TokenURL is the endpoint which exchanges the authorization code for the tokens.
+	TokenURL string
+	// UserInfoURL is the endpoint which returns the claims of the user.
+	// Optional for the OpenID Connect providers, the claims of the ID token are used instead.
+	UserInfoURL string
+	// Issuer is the expected "iss" claim of the OpenID Connect ID tokens. Optional.
+	Issuer string
+	// Scopes are the requested scopes, the "openid" one enables the OpenID Connect flow,
+	// a nonce is sent and the returned ID token is verified.
+	Scopes []string
+	// RedirectURL is the absolute url of the callback, as registered to the provider,
+	// i.e "https://example.com/auth/google/callback", it's required.
+	RedirectURL string
+	// AuthParams are extra parameters of the authorization request, i.e "prompt": "select_account".
+	AuthParams map[string]string
+	// MapUser fills the "user" from the claims of the ID token and the user info response,
+	// defaults to the standard claims of OpenID Connect ("sub", "email", "name", "picture")
+	// with fallbacks to the common ones ("id", "login", "avatar_url").
+	MapUser func(claims context.Claims, user *context.OAuthUser)
+}
+
+// Google returns the OpenID Connect provider of Google,
+// the default scopes are the "openid", "email" and "profile".
+func Google(clientID, clientSecret string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &Provider{
+		Name:         "google",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		AuthURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		UserInfoURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+		Issuer:       "https://accounts.google.com",
+		Scopes:       scopes,
+	}
+}
+
+// GitHub returns the OAuth2 provider of GitHub,
+// the default scope is the "read:user".
+func GitHub(clientID, clientSecret string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{"read:user"}
+	}
+
+	return &Provider{
+		Name:         "github",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		AuthURL:      "https://github.com/login/oauth/authorize",
+		TokenURL:     "https://github.com/login/oauth/access_token",
+		UserInfoURL:  "https://api.github.com/user",
+		Scopes:       scopes,
+	}
+}
+
+// isOpenID reports whether the "openid" scope is requested.
+func (p *Provider) isOpenID() bool {
+	for _, s := range p.Scopes {
+		if s == "openid" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Provider) authCodeURL(redirectURL, state, nonce, codeChallenge string) string {
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.ClientID)
+	q.Set("redirect_uri", redirectURL)
+	q.Set("state", state)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	if len(p.Scopes) > 0 {
+		q.Set("scope", strings.Join(p.Scopes, " "))
+	}
+	if nonce != "" {
+		q.Set("nonce", nonce)
+	}
+	for k, v := range p.AuthParams {
+		q.Set(k, v)
+	}
+
+	sep := "?"
+	if strings.Contains(p.AuthURL, "?") {
+		sep = "&"
+	}
+
+	return p.AuthURL + sep + q.Encode()
+}
+
+// token is the response of the token endpoint.
+type token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	IDToken      string `json:"id_token"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// do sends the "req" and decodes its JSON response to "v", it returns the status code too.
+func do(client *http.Client, req *http.Request, v interface{}) (int, error) {
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return res.StatusCode, err
+	}
+
+	if err = json.Unmarshal(b, v); err != nil {
+		return res.StatusCode, errInvalidResponse.Format(req.URL.String(), res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// exchange exchanges the authorization "code" for the tokens.
+func (p *Provider) exchange(stdCtx stdContext.Context, client *http.Client, code, redirectURL, codeVerifier string) (*token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURL)
+	form.Set("client_id", p.ClientID)
+	form.Set("client_secret", p.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequest(http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	t := new(token)
+	statusCode, err := do(client, req.WithContext(stdCtx), t)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Error != "" {
+		return nil, errProvider.Format(t.Error, t.ErrorDescription)
+	}
+
+	if statusCode != http.StatusOK || t.AccessToken == "" {
+		return nil, errInvalidResponse.Format(p.TokenURL, statusCode)
+	}
+
+	return t, nil
+}
+
+// userInfo returns the claims of the user info endpoint.
+func (p *Provider) userInfo(stdCtx stdContext.Context, client *http.Client, accessToken string) (context.Claims, error) {
+	req, err := http.NewRequest(http.MethodGet, p.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	claims := make(context.Claims)
+	statusCode, err := do(client, req.WithContext(stdCtx), &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, errInvalidResponse.Format(p.UserInfoURL, statusCode)
+	}
+
+	return claims, nil
+}
+
+// claimString returns the first non-empty claim of the "keys" as string, the numbers are formatted too,
+// i.e the numeric "id" of GitHub.
+func claimString(claims context.Claims, keys ...string) string {
+	for _, key := range keys {
+		switch v := claims.Get(key).(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case float64:
+			return fmt.Sprintf("%.0f", v)
+		case json.Number:
+			return v.String()
+		}
+	}
+
+	return ""
+}
+
+func defaultMapUser(claims context.Claims, user *context.OAuthUser) {
+	user.ID = claimString(claims, "sub", "id")
+	user.Email = claimString(claims, "email")
+	user.Name = claimString(claims, "name", "login", "preferred_username")
+	user.AvatarURL = claimString(claims, "picture", "avatar_url")
+}
+
+// newUser returns the user of the "claims" and the "t" token.
+func (p *Provider) newUser(claims context.Claims, t *token) *context.OAuthUser {
+	user := &context.OAuthUser{
+		Provider:     p.Name,
+		Claims:       claims,
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+	}
+	if t.ExpiresIn > 0 {
+		user.Expiry = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+
+	if p.MapUser != nil {
+		p.MapUser(claims, user)
+	} else {
+		defaultMapUser(claims, user)
+	}
+
+	return user
+}
@@ -1,7 +1,10 @@
 package cache_test
 
 import (
+	"bytes"
+	"compress/gzip"
 	"net/http"
+	stdhttptest "net/http/httptest"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -209,3 +212,178 @@ func TestCacheValidator(t *testing.T) {
 		t.Fatalf(t.Name()+": %v", errTestFailed.Format(3, counter))
 	}
 }
+
+func TestCacheCanonicalQuery(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	app.Get("/", cache.Handler(cacheDuration), func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.WriteString(ctx.CanonicalQuery())
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").WithQueryString("b=2&a=1").Expect().Status(iris.StatusOK).Body().Equal("a=1&b=2")
+	e.GET("/").WithQueryString("a=1&utm_source=newsletter&b=2&fbclid=x").Expect().Status(iris.StatusOK).Body().Equal("a=1&b=2")
+	if expected, got := uint32(1), atomic.LoadUint32(&n); expected != got {
+		t.Fatalf("expected the handler to be executed %d time(s) but executed %d", expected, got)
+	}
+
+	e.GET("/").WithQueryString("a=1&b=3").Expect().Status(iris.StatusOK).Body().Equal("a=1&b=3")
+	if expected, got := uint32(2), atomic.LoadUint32(&n); expected != got {
+		t.Fatalf("expected the handler to be executed %d time(s) but executed %d", expected, got)
+	}
+}
+
+func TestCachePurgeTags(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	c := cache.Cache(time.Minute)
+	app.Get("/users/{id:uint64}", c.ServeHTTP, func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.AddCacheTag("users", "user:"+ctx.Params().Get("id"), "users")
+		ctx.Writef("user %s", ctx.Params().Get("id"))
+	})
+	app.Handle("PURGE", "/cache", c.PurgeHandler())
+
+	e := httptest.New(t, app)
+
+	for i := 0; i < 2; i++ {
+		e.GET("/users/1").Expect().Status(http.StatusOK).
+			Header(context.SurrogateKeyHeaderKey).Equal("users user:1")
+		e.GET("/users/2").Expect().Status(http.StatusOK).
+			Header(context.CacheTagsHeaderKey).Equal("users,user:2")
+	}
+	if expected, got := uint32(2), atomic.LoadUint32(&n); expected != got {
+		t.Fatal(errTestFailed.Format(expected, got))
+	}
+	if expected, got := 2, c.Tags()["users"]; expected != got {
+		t.Fatalf("expected %d entries tagged with 'users' but got %d", expected, got)
+	}
+
+	e.Request("PURGE", "/cache").WithHeader(context.SurrogateKeyHeaderKey, "user:1").Expect().
+		Status(http.StatusOK).JSON().Object().ValueEqual("purged", 1)
+
+	e.GET("/users/1").Expect().Status(http.StatusOK).Body().Equal("user 1")
+	e.GET("/users/2").Expect().Status(http.StatusOK).Body().Equal("user 2")
+	if expected, got := uint32(3), atomic.LoadUint32(&n); expected != got {
+		t.Fatal(errTestFailed.Format(expected, got))
+	}
+
+	if expected, got := 2, c.PurgeTags("users"); expected != got {
+		t.Fatalf("expected %d purged entries but got %d", expected, got)
+	}
+	if len(c.Tags()) != 0 {
+		t.Fatalf("expected no tags after the purge but got %v", c.Tags())
+	}
+}
+
+func TestCachePurgeOn(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	c := cache.Cache(time.Minute)
+	c.PurgeOn(app.Events(), "cache.purge")
+	app.Get("/users/{id:uint64}", c.ServeHTTP, func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.AddCacheTag("user:" + ctx.Params().Get("id"))
+		ctx.Writef("user %s", ctx.Params().Get("id"))
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/users/1").Expect().Status(http.StatusOK)
+	e.GET("/users/2").Expect().Status(http.StatusOK)
+
+	app.Events().Publish("cache.purge", "user:1")
+	e.GET("/users/1").Expect().Status(http.StatusOK).Body().Equal("user 1")
+	e.GET("/users/2").Expect().Status(http.StatusOK).Body().Equal("user 2")
+	if expected, got := uint32(3), atomic.LoadUint32(&n); expected != got {
+		t.Fatal(errTestFailed.Format(expected, got))
+	}
+
+	// a nil payload purges all of them.
+	app.Events().Publish("cache.purge", nil)
+	if len(c.Tags()) != 0 {
+		t.Fatalf("expected no tags after the purge but got %v", c.Tags())
+	}
+}
+
+func TestCachePassThrough(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	body := "a response bigger than the recorder's limit"
+	app.Get("/", context.RecordWithLimit(8, context.RecorderPassThrough), cache.Handler(time.Minute), func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.WriteString(body)
+	})
+
+	e := httptest.New(t, app)
+	for i := 0; i < 2; i++ {
+		e.GET("/").Expect().Status(http.StatusOK).Body().Equal(body)
+	}
+
+	// the sent responses are not cached.
+	if expected, got := uint32(2), atomic.LoadUint32(&n); expected != got {
+		t.Fatal(errTestFailed.Format(expected, got))
+	}
+}
+
+func TestCacheEncoding(t *testing.T) {
+	app := iris.New()
+	var n uint32
+
+	body := "a compressed response"
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write([]byte(body))
+	gw.Close()
+
+	// i.e a pre-compressed asset.
+	app.Get("/", cache.Handler(time.Minute), func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		if ctx.ClientSupportsGzip() {
+			context.AddGzipHeaders(ctx.ResponseWriter())
+			ctx.Write(gzipped.Bytes())
+			return
+		}
+		ctx.WriteString(body)
+	})
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	serve := func(acceptEncoding string) *stdhttptest.ResponseRecorder {
+		r := stdhttptest.NewRequest(http.MethodGet, "/", nil)
+		if acceptEncoding != "" {
+			r.Header.Set(context.AcceptEncodingHeaderKey, acceptEncoding)
+		}
+		w := stdhttptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := serve("gzip"); w.Header().Get(context.ContentEncodingHeaderKey) != context.GzipHeaderValue {
+			t.Fatalf("[%d] expected a gzip response but got %v", i, w.Header())
+		}
+	}
+
+	// the cached gzip response is not sent to the clients which do not accept it.
+	for _, acceptEncoding := range []string{"", "gzip;q=0", "deflate"} {
+		w := serve(acceptEncoding)
+		if got := w.Header().Get(context.ContentEncodingHeaderKey); got != "" {
+			t.Fatalf("%q: expected an uncompressed response but got the %q one", acceptEncoding, got)
+		}
+		if got := w.Body.String(); got != body {
+			t.Fatalf("%q: expected the body %q but got %q", acceptEncoding, body, got)
+		}
+	}
+
+	// once per the gzip and the identity.
+	if expected, got := uint32(2), atomic.LoadUint32(&n); expected != got {
+		t.Fatal(errTestFailed.Format(expected, got))
+	}
+}
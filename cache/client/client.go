@@ -131,7 +131,10 @@ func (h *ClientHandler) ServeHTTP(ctx context.Context) {
 		h.bodyHandler(ctx)
 
 		// check if it's a valid response, if it's not then just return.
-		if !h.rule.Valid(ctx) {
+		// The remote service keeps only the content type, so the compressed responses
+		// are not shared with the clients which may not accept their content coding.
+		if recorder.IsPassThrough() || !h.rule.Valid(ctx) ||
+			recorder.Header().Get(context.ContentEncodingHeaderKey) != "" {
 			return
 		}
 		// save to the remote cache
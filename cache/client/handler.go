@@ -7,6 +7,7 @@ import (
 	"github.com/kataras/iris/cache/client/rule"
 	"github.com/kataras/iris/cache/entry"
 	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/events"
 )
 
 // Handler the local cache service handler contains
@@ -21,6 +22,10 @@ type Handler struct {
 	expiration time.Duration
 	// entries the memory cache stored responses.
 	entries map[string]*entry.Entry
+	// tags are the keys of the entries by their cache tags, see `context#AddCacheTag`,
+	// and keyTags are the tags of each entry's key.
+	tags    map[string]map[string]struct{}
+	keyTags map[string][]string
 	mu      sync.RWMutex
 }
 
@@ -31,6 +36,8 @@ func NewHandler(expiration time.Duration) *Handler {
 		rule:       DefaultRuleSet,
 		expiration: expiration,
 		entries:    make(map[string]*entry.Entry, 0),
+		tags:       make(map[string]map[string]struct{}),
+		keyTags:    make(map[string][]string),
 	}
 }
 
@@ -99,7 +106,13 @@ func (h *Handler) ServeHTTP(ctx context.Context) {
 		response *entry.Response
 		valid    = false
 		// unique per subdomains and paths with different url query.
-		key = scheme + ctx.Host() + ctx.Request().URL.RequestURI()
+		// the query is canonical, so the order of the parameters
+		// and the tracking ones (i.e utm_source) do not create new entries.
+		// The responses may be compressed, i.e by the `Context#Gzip`, so they are cached
+		// per negotiated content coding too, a client which does not accept gzip
+		// (or "identity;q=0") does not get the response of another one.
+		key = scheme + ctx.Host() + ctx.Request().URL.EscapedPath() + "?" + ctx.CanonicalQuery() +
+			"#" + ctx.NegotiateEncoding(context.GzipHeaderValue, context.IdentityEncoding)
 	)
 
 	h.mu.RLock()
@@ -132,8 +145,9 @@ func (h *Handler) ServeHTTP(ctx context.Context) {
 		// now that we have recordered the response,
 		// we are ready to check if that specific response is valid to be stored.
 
-		// check if it's a valid response, if it's not then just return.
-		if !h.rule.Valid(ctx) {
+		// check if it's a valid response, if it's not then just return,
+		// the body of the pass-through responses is not recorded.
+		if recorder.IsPassThrough() || !h.rule.Valid(ctx) {
 			return
 		}
 
@@ -153,6 +167,7 @@ func (h *Handler) ServeHTTP(ctx context.Context) {
 			body,
 			parseLifeChanger(ctx),
 		)
+		h.tag(key, context.ParseCacheTags(recorder.Header().Get(context.SurrogateKeyHeaderKey)))
 
 		// fmt.Printf("reset cache entry\n")
 		// fmt.Printf("key: %s\n", key)
@@ -172,3 +187,129 @@ func (h *Handler) ServeHTTP(ctx context.Context) {
 	// fmt.Printf("write body len: %d\n", len(response.Body()))
 
 }
+
+// tag indexes the "key" entry by its "tags", the previous tags of the entry are replaced.
+func (h *Handler) tag(key string, tags []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.untag(key)
+	if len(tags) == 0 {
+		return
+	}
+
+	h.keyTags[key] = tags
+	for _, tag := range tags {
+		keys, ok := h.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			h.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// untag removes the "key" entry from the tags index, the caller should lock.
+func (h *Handler) untag(key string) {
+	for _, tag := range h.keyTags[key] {
+		if keys, ok := h.tags[tag]; ok {
+			delete(keys, key)
+			if len(keys) == 0 {
+				delete(h.tags, tag)
+			}
+		}
+	}
+	delete(h.keyTags, key)
+}
+
+// PurgeTags invalidates the cached responses which are tagged with any of the "tags",
+// see `context#AddCacheTag`, the next requests of them are served by the original handler.
+//
+// Returns the number of the purged entries.
+//
+// Usage:
+//
+//	h := cache.Cache(time.Hour)
+//	app.Get("/users/{id:uint64}", h.ServeHTTP, getUser) // ctx.AddCacheTag("user:" + id)
+//	app.Put("/users/{id:uint64}", func(ctx iris.Context) {
+//		updateUser(ctx)
+//		h.PurgeTags("user:" + ctx.Params().Get("id"))
+//	})
+func (h *Handler) PurgeTags(tags ...string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := 0
+	for _, tag := range tags {
+		for key := range h.tags[tag] {
+			h.untag(key)
+			delete(h.entries, key)
+			n++
+		}
+	}
+
+	return n
+}
+
+// PurgeAll invalidates all the cached responses, it returns the number of the purged entries.
+func (h *Handler) PurgeAll() int {
+	h.mu.Lock()
+	n := len(h.entries)
+	h.entries = make(map[string]*entry.Entry)
+	h.tags = make(map[string]map[string]struct{})
+	h.keyTags = make(map[string][]string)
+	h.mu.Unlock()
+	return n
+}
+
+// PurgeOn purges the cached responses on the events of the "topic" of the "bus",
+// i.e the `Application#Events`, so the handlers don't need a reference to the cache.
+// The event's payload is the tag, or a slice of tags, to purge, a nil payload purges all of them.
+// The "topic" can end with a "*", see `events#Bus.Subscribe`.
+//
+// Usage:
+//
+//	h.PurgeOn(app.Events(), "cache.purge")
+//	app.Events().Publish("cache.purge", "user:"+id)
+func (h *Handler) PurgeOn(bus *events.Bus, topic string) *events.Subscription {
+	return bus.Subscribe(topic, func(evt events.Event) {
+		if evt.Payload == nil {
+			h.PurgeAll()
+			return
+		}
+
+		h.PurgeTags(events.Strings(evt.Payload)...)
+	})
+}
+
+// Tags returns the number of the cached responses by their tags.
+func (h *Handler) Tags() map[string]int {
+	h.mu.RLock()
+	tags := make(map[string]int, len(h.tags))
+	for tag, keys := range h.tags {
+		tags[tag] = len(keys)
+	}
+	h.mu.RUnlock()
+	return tags
+}
+
+// PurgeHandler returns the handler of a purge endpoint, which invalidates the cached responses
+// of the "Surrogate-Key" (space separated) or the "Cache-Tags" (comma separated) request header's tags
+// and the "tag" URL query parameters, a request without tags purges nothing.
+// The response is the JSON `{"purged": n}`, n is the number of the purged entries.
+// Protect the endpoint, i.e with the `basicauth` middleware.
+//
+// Usage:
+//
+//	app.Handle("PURGE", "/cache", auth, h.PurgeHandler())
+//	curl -X PURGE -H "Surrogate-Key: user:42 users" http://localhost:8080/cache
+func (h *Handler) PurgeHandler() context.Handler {
+	return func(ctx context.Context) {
+		var tags []string
+		tags = append(tags, context.ParseCacheTags(ctx.GetHeader(context.SurrogateKeyHeaderKey))...)
+		tags = append(tags, context.ParseCacheTags(ctx.GetHeader(context.CacheTagsHeaderKey))...)
+		tags = append(tags, ctx.Request().URL.Query()["tag"]...)
+
+		ctx.JSON(context.Map{"purged": h.PurgeTags(tags...)})
+	}
+}
@@ -1,11 +1,19 @@
 package iris
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"net"
+	"net/http"
 	"os"
 	"os/user"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v2"
@@ -70,6 +78,9 @@ func parseYAML(filename string) (Configuration, error) {
 	if err := yaml.Unmarshal(data, &c); err != nil {
 		return c, errConfigurationDecode.AppendErr(err)
 	}
+	if _, err := parseTrustedProxies(c.TrustedProxies); err != nil {
+		return c, errConfigurationDecode.AppendErr(err)
+	}
 	return c, nil
 }
 
@@ -121,8 +132,6 @@ func YAML(filename string) Configuration {
 // app.Configure(iris.WithConfiguration(iris.TOML("myconfig.tml"))) or
 // app.Run([iris.Runner], iris.WithConfiguration(iris.TOML("myconfig.tml"))).
 func TOML(filename string) Configuration {
-	c := DefaultConfiguration()
-
 	// check for globe configuration file and use that, otherwise
 	// return the default configuration if file doesn't exist.
 	if filename == globalConfigurationKeyword {
@@ -132,29 +141,172 @@ func TOML(filename string) Configuration {
 		}
 	}
 
+	c, err := parseTOML(filename)
+	if err != nil {
+		panic(err)
+	}
+	// Author's notes:
+	// The toml's 'usual thing' for key naming is: the_config_key instead of TheConfigKey
+	// but I am always prefer to use the specific programming language's syntax
+	// and the original configuration name fields for external configuration files
+	// so we do 'toml: "TheConfigKeySameAsTheConfigField" instead.
+	return c
+}
+
+func parseTOML(filename string) (Configuration, error) {
+	c := DefaultConfiguration()
 	// get the abs
 	// which will try to find the 'filename' from current workind dir too.
 	tomlAbsPath, err := filepath.Abs(filename)
 	if err != nil {
-		panic(errConfigurationDecode.AppendErr(err))
+		return c, errConfigurationDecode.AppendErr(err)
 	}
 
 	// read the raw contents of the file
 	data, err := ioutil.ReadFile(tomlAbsPath)
 	if err != nil {
-		panic(errConfigurationDecode.AppendErr(err))
+		return c, errConfigurationDecode.AppendErr(err)
 	}
 
 	// put the file's contents as toml to the default configuration(c)
 	if _, err := toml.Decode(string(data), &c); err != nil {
-		panic(errConfigurationDecode.AppendErr(err))
+		return c, errConfigurationDecode.AppendErr(err)
+	}
+	if _, err := parseTrustedProxies(c.TrustedProxies); err != nil {
+		return c, errConfigurationDecode.AppendErr(err)
+	}
+	return c, nil
+}
+
+var errConfigurationSection = errors.New("configuration section '%s': %v")
+
+// decodeSection decodes the "raw" value of a section of the `Configuration#Other`,
+// as it's parsed by the yaml or the toml loader, into the "dest" pointer of a configuration struct.
+// The keys are matched to the fields case-insensitive, the unknown keys are reported as errors,
+// the "dest" is validated by its `Validate() error` method, if any.
+// The section is decoded into a copy of the "dest", which is assigned to the "dest" only if it's valid,
+// so the "dest" is not modified on errors.
+// 通过json编解码将Other中的原始值转换成具体的配置结构
+func decodeSection(name string, raw interface{}, dest interface{}) error {
+	b, err := json.Marshal(normalizeSectionValue(raw))
+	if err != nil {
+		return errConfigurationSection.Format(name, err)
+	}
+
+	v := reflect.ValueOf(dest).Elem()
+	// the current values are kept for the missing keys.
+	fresh := reflect.New(v.Type())
+	fresh.Elem().Set(copySectionValue(v))
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(fresh.Interface()); err != nil {
+		return errConfigurationSection.Format(name, err)
+	}
+
+	if validator, ok := fresh.Interface().(interface{ Validate() error }); ok {
+		if err = validator.Validate(); err != nil {
+			return errConfigurationSection.Format(name, err)
+		}
+	}
+
+	v.Set(fresh.Elem())
+	return nil
+}
+
+// copySectionValue returns a deep copy of the "v",
+// the decoder modifies the maps and the slices of the decoded value in place.
+func copySectionValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(copySectionValue(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(copySectionValue(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copySectionValue(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), copySectionValue(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v) // the unexported fields are copied as they are.
+		for i := 0; i < c.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(copySectionValue(v.Field(i)))
+			}
+		}
+		return c
+	}
+
+	return v
+}
+
+// normalizeSectionValue converts the map[interface{}]interface{} values of the yaml loader
+// to map[string]interface{} ones, so they can be encoded as json.
+func normalizeSectionValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for key, val := range value {
+			m[fmt.Sprint(key)] = normalizeSectionValue(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for key, val := range value {
+			m[key] = normalizeSectionValue(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(value))
+		for i, val := range value {
+			s[i] = normalizeSectionValue(val)
+		}
+		return s
+	case []map[string]interface{}: // toml's array of tables.
+		s := make([]interface{}, len(value))
+		for i, val := range value {
+			s[i] = normalizeSectionValue(val)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// parseConfigurationFile parses a yaml or a toml configuration file based on its extension,
+// see `Application#WatchConfiguration`.
+func parseConfigurationFile(filename string) (Configuration, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".tml", ".toml":
+		return parseTOML(filename)
+	default:
+		return parseYAML(filename)
 	}
-	// Author's notes:
-	// The toml's 'usual thing' for key naming is: the_config_key instead of TheConfigKey
-	// but I am always prefer to use the specific programming language's syntax
-	// and the original configuration name fields for external configuration files
-	// so we do 'toml: "TheConfigKeySameAsTheConfigField" instead.
-	return c
 }
 
 // Configurator is just an interface which accepts the framework instance.
@@ -244,6 +396,13 @@ var WithoutBodyConsumptionOnUnmarshal = func(app *Application) {
 	app.config.DisableBodyConsumptionOnUnmarshal = true
 }
 
+// WithoutJSONErrorDetails disables the JSONErrorDetails setting.
+//
+// See `Configuration`.
+var WithoutJSONErrorDetails = func(app *Application) {
+	app.config.DisableJSONErrorDetails = true
+}
+
 // WithoutAutoFireStatusCode disables the AutoFireStatusCode setting.
 //
 // See `Configuration`.
@@ -258,6 +417,81 @@ var WithPathEscape = func(app *Application) {
 	app.config.EnablePathEscape = true
 }
 
+// WithRestrictRelativeRedirects enables the RestrictRelativeRedirects setting,
+// the "hosts" are the allowed foreign hosts that `context.Redirect` can redirect to, if any.
+//
+// See `Configuration`.
+func WithRestrictRelativeRedirects(hosts ...string) Configurator {
+	return func(app *Application) {
+		app.config.RestrictRelativeRedirects = true
+		app.config.RedirectAllowedHosts = append(app.config.RedirectAllowedHosts, hosts...)
+	}
+}
+
+// WithURLSigningKey sets the URLSigningKey setting.
+//
+// See `Configuration`.
+func WithURLSigningKey(key string) Configurator {
+	return func(app *Application) {
+		app.config.URLSigningKey = key
+	}
+}
+
+// WithCookieSecret sets the CookieSecret setting,
+// the cookies of the `context.SetCookieKV` are signed with the "secret",
+// the `context.GetCookie` verifies them and the `context.GetSignedCookie` accepts the valid ones only.
+//
+// See `Configuration` and `WithCookieEncryption`.
+func WithCookieSecret(secret string) Configurator {
+	return func(app *Application) {
+		app.config.CookieSecret = secret
+	}
+}
+
+// WithCookieEncryption enables the CookieEncryption setting,
+// the cookie values are encrypted too, the `WithCookieSecret` is required.
+//
+// See `Configuration`.
+var WithCookieEncryption = func(app *Application) {
+	app.config.CookieEncryption = true
+}
+
+// WithCookieSameSite sets the CookieSameSite setting,
+// the default SameSite attribute of the `context.SetCookieKV` and `context.RemoveCookie` cookies.
+//
+// See `Configuration`.
+func WithCookieSameSite(sameSite http.SameSite) Configurator {
+	return func(app *Application) {
+		app.config.CookieSameSite = sameSite
+	}
+}
+
+// WithCookieSecure enables the CookieSecure setting.
+//
+// See `Configuration`.
+var WithCookieSecure = func(app *Application) {
+	app.config.CookieSecure = true
+}
+
+// WithCookieDomain sets the CookieDomain setting.
+//
+// See `Configuration`.
+func WithCookieDomain(domain string) Configurator {
+	return func(app *Application) {
+		app.config.CookieDomain = domain
+	}
+}
+
+// WithLogLevel sets the LogLevel setting and the level of the application's logger.
+//
+// See `Configuration`.
+func WithLogLevel(level string) Configurator {
+	return func(app *Application) {
+		app.config.LogLevel = level
+		app.logger.SetLevel(level)
+	}
+}
+
 // WithOptimizations can force the application to optimize for the best performance where is possible.
 //
 // See `Configuration`.
@@ -272,6 +506,53 @@ var WithFireMethodNotAllowed = func(app *Application) {
 	app.config.FireMethodNotAllowed = true
 }
 
+// WithHeaderMutationDetection enables the DetectHeaderMutations setting.
+// Use it in development mode only.
+//
+// See `Configuration`.
+var WithHeaderMutationDetection = func(app *Application) {
+	app.config.DetectHeaderMutations = true
+}
+
+// WithHandlerTiming enables the EnableHandlerTiming setting
+// and sets the SlowRequestThreshold setting to "slowRequestThreshold",
+// zero means that slow requests are not logged.
+//
+// See `Configuration`.
+func WithHandlerTiming(slowRequestThreshold time.Duration) Configurator {
+	return func(app *Application) {
+		app.config.EnableHandlerTiming = true
+		app.config.SlowRequestThreshold = slowRequestThreshold
+	}
+}
+
+// WithCustomContext registers a custom `Context` implementation, the "newFunc" creates the contexts
+// of the requests through the application's context pool, see `context#Pool.Attach`,
+// so the router acquires them instead of the default ones.
+//
+// The custom context should embed the default one, the `context.NewContext(app)`,
+// and it should override the `Next` and the `Do` methods in order to be passed to the handlers,
+// i.e `func (ctx *MyContext) Do(handlers context.Handlers) { context.Do(ctx, handlers) }`.
+// Should be called before `Run`.
+//
+// Usage:
+// app.Configure(iris.WithCustomContext(func(app context.Application) context.Context {
+// 	return &MyContext{Context: context.NewContext(app)}
+// }))
+//
+// Example: https://github.com/kataras/iris/blob/master/_examples/routing/custom-context/method-overriding/main.go
+func WithCustomContext(newFunc func(app context.Application) context.Context) Configurator {
+	return func(app *Application) {
+		if newFunc == nil {
+			return
+		}
+
+		app.ContextPool.Attach(func() context.Context {
+			return newFunc(app)
+		})
+	}
+}
+
 // WithTimeFormat sets the TimeFormat setting.
 //
 // See `Configuration`.
@@ -312,6 +593,8 @@ func WithPostMaxMemory(limit int64) Configurator {
 // you want to enable the "CF-Connecting-IP", inneed you
 // can allow the `ctx.RemoteAddr()` to use any header
 // that the client may sent.
+// The headers are used only if the request comes from one of the `TrustedProxies`, see `WithTrustedProxies`,
+// without them the headers are ignored and a warning is logged on `Run`.
 //
 // Defaults to an empty map but an example usage is:
 // WithRemoteAddrHeader("X-Forwarded-For")
@@ -344,6 +627,56 @@ func WithoutRemoteAddrHeader(headerName string) Configurator {
 	}
 }
 
+// WithTrustedProxies adds CIDR ranges or IPs of the proxies that the forwarded headers,
+// i.e "X-Forwarded-For", are accepted from.
+//
+// Usage:
+// app.Run(iris.Addr(":8080"), iris.WithRemoteAddrHeader("X-Forwarded-For"), iris.WithTrustedProxies("10.0.0.0/8"))
+//
+// It panics on an entry which is not an IP or a CIDR range.
+//
+// See `Configuration#TrustedProxies`.
+func WithTrustedProxies(cidrs ...string) Configurator {
+	return func(app *Application) {
+		app.config.TrustedProxies = append(app.config.TrustedProxies, cidrs...)
+		app.config.trustedProxyNets = mustParseTrustedProxies(app.config.TrustedProxies)
+	}
+}
+
+// WithSSLProxyHeader adds a request header name and its value
+// which, when sent by a trusted proxy, declares that the client connects through https.
+//
+// Usage:
+// WithSSLProxyHeader("X-Forwarded-Proto", "https")
+//
+// Look `context.IsTLS()` for more.
+func WithSSLProxyHeader(headerName, headerValue string) Configurator {
+	return func(app *Application) {
+		if app.config.SSLProxyHeaders == nil {
+			app.config.SSLProxyHeaders = make(map[string]string)
+		}
+		app.config.SSLProxyHeaders[headerName] = headerValue
+	}
+}
+
+// WithHostProxyHeader enables one or more request header names
+// that can be used to retrieve the host as seen by the client, when behind a proxy.
+//
+// Usage:
+// WithHostProxyHeader("X-Forwarded-Host")
+//
+// Look `context.FullRequestURI()` for more.
+func WithHostProxyHeader(headerNames ...string) Configurator {
+	return func(app *Application) {
+		if app.config.HostProxyHeaders == nil {
+			app.config.HostProxyHeaders = make(map[string]bool)
+		}
+		for _, headerName := range headerNames {
+			app.config.HostProxyHeaders[headerName] = true
+		}
+	}
+}
+
 // WithOtherValue adds a value based on a key to the Other setting.
 //
 // See `Configuration`.
@@ -394,6 +727,13 @@ type Configuration struct {
 	// 默认是false，如果是true，则表示服务不能自己手动停止，除非你自己通过一个自定义的host.Task停止
 	DisableInterruptHandler bool `json:"disableInterruptHandler,omitempty" yaml:"DisableInterruptHandler" toml:"DisableInterruptHandler"`
 
+	// LogLevel sets the level of the application's logger, i.e "error", "warn", "info" or "debug",
+	// see `Application#Logger`.
+	// It can be changed while the server is running, see `Application#UpdateConfiguration`.
+	//
+	// Defaults to empty, the logger's level is not modified.
+	LogLevel string `json:"logLevel,omitempty" yaml:"LogLevel" toml:"LogLevel"`
+
 	// DisablePathCorrection corrects and redirects or executes directly the handler of
 	// the requested path to the registered path
 	// for example, if /home/ path is requested but no handler for this Route found,
@@ -439,6 +779,34 @@ type Configuration struct {
 	// Defaults to false.
 	FireMethodNotAllowed bool `json:"fireMethodNotAllowed,omitempty" yaml:"FireMethodNotAllowed" toml:"FireMethodNotAllowed"`
 
+	// DetectHeaderMutations if it's true then the response writer is wrapped by a guard
+	// which reports, as warnings to the application's logger, any response header
+	// that was modified after the headers were sent to the client (the change is silently dropped otherwise)
+	// or any access to the response headers from a goroutine other than the request's one.
+	// The warning contains the name(s) of the handler(s) that touched the headers, see `context#HandlerName`.
+	//
+	// It has a performance cost, it should be enabled in development mode only.
+	//
+	// Defaults to false.
+	DetectHeaderMutations bool `json:"detectHeaderMutations,omitempty" yaml:"DetectHeaderMutations" toml:"DetectHeaderMutations"`
+
+	// EnableHandlerTiming if it's true then each one of the routes' handlers
+	// is wrapped by the `context#TimeHandler` on `Build` in order to measure the time spent on it,
+	// the breakdown of a request can be retrieved by the `context#GetHandlersTiming`.
+	//
+	// It has a performance cost.
+	//
+	// Defaults to false.
+	EnableHandlerTiming bool `json:"enableHandlerTiming,omitempty" yaml:"EnableHandlerTiming" toml:"EnableHandlerTiming"`
+
+	// SlowRequestThreshold is the duration which,
+	// when the handler timing is enabled (see `EnableHandlerTiming`),
+	// requests that took longer than that are logged as warnings
+	// with the detailed handlers timing breakdown.
+	//
+	// Defaults to zero, slow requests are not logged.
+	SlowRequestThreshold time.Duration `json:"slowRequestThreshold,omitempty" yaml:"SlowRequestThreshold" toml:"SlowRequestThreshold"`
+
 	// DisableBodyConsumptionOnUnmarshal manages the reading behavior of the context's body readers/binders.
 	// If setted to true then it
 	// disables the body consumption by the `context.UnmarshalBody/ReadJSON/ReadXML`.
@@ -449,6 +817,13 @@ type Configuration struct {
 	// context.UnmarshalBody/ReadJSON/ReadXML will be not consumed.
 	DisableBodyConsumptionOnUnmarshal bool `json:"disableBodyConsumptionOnUnmarshal,omitempty" yaml:"DisableBodyConsumptionOnUnmarshal" toml:"DisableBodyConsumptionOnUnmarshal"`
 
+	// DisableJSONErrorDetails if true then the `context.ReadJSON` returns the errors of the JSON decoder as they're.
+	// Otherwise the decoding errors are returned as a `context.JSONError` with the offset, the line and the column,
+	// the path of the field and the type mismatch details, its `Problem` can be sent to the client as a 400 response.
+	//
+	// Defaults to false.
+	DisableJSONErrorDetails bool `json:"disableJSONErrorDetails,omitempty" yaml:"DisableJSONErrorDetails" toml:"DisableJSONErrorDetails"`
+
 	// DisableAutoFireStatusCode if true then it turns off the http error status code handler automatic execution
 	// from (`context.StatusCodeNotSuccessful`, defaults to < 200 || >= 400).
 	// If that is false then for a direct error firing, then call the "context#FireStatusCode(statusCode)" manually.
@@ -517,6 +892,7 @@ type Configuration struct {
 	// you want to enable the "CF-Connecting-IP", inneed you
 	// can allow the `ctx.RemoteAddr()` to use any header
 	// that the client may sent.
+	// The headers are used only if the request comes from one of the `TrustedProxies`.
 	//
 	// Defaults to an empty map but an example usage is:
 	// RemoteAddrHeaders {
@@ -528,9 +904,99 @@ type Configuration struct {
 	// Look `context.RemoteAddr()` for more.
 	RemoteAddrHeaders map[string]bool `json:"remoteAddrHeaders,omitempty" yaml:"RemoteAddrHeaders" toml:"RemoteAddrHeaders"`
 
+	// TrustedProxies are the CIDR ranges or the IPs of the proxies in front of the server,
+	// i.e "10.0.0.0/8" or "192.168.1.10". The `RemoteAddrHeaders`, the `SSLProxyHeaders`,
+	// the `HostProxyHeaders` and the "X-Forwarded-Prefix" are used only if the request comes from one of them,
+	// and the "X-Forwarded-For" and "Forwarded" (RFC 7239) lists are walked through the trusted hops only,
+	// so the client's IP is the first untrusted one from the right.
+	// An entry which is not an IP or a CIDR range is rejected, see `WithTrustedProxies` and `UpdateConfiguration`.
+	//
+	// Defaults to an empty list, the forwarded headers are never trusted.
+	// The applications which relied on the `RemoteAddrHeaders` without trusted proxies
+	// should list their proxies, trusting every peer, i.e `WithTrustedProxies("0.0.0.0/0", "::/0")`,
+	// restores the previous behavior but the client can spoof its IP then.
+	TrustedProxies []string `json:"trustedProxies,omitempty" yaml:"TrustedProxies" toml:"TrustedProxies"`
+	// trustedProxyNets are the parsed TrustedProxies, they're parsed once when the TrustedProxies are set
+	// and not on each request.
+	trustedProxyNets []*net.IPNet
+
+	// SSLProxyHeaders defines the set of header key values
+	// that would indicate a valid https Request (look `context.IsTLS()`).
+	// Example: `map[string]string{"X-Forwarded-Proto": "https"}`.
+	// Like the `RemoteAddrHeaders`, enable them only when the server is behind a trusted proxy,
+	// because those headers can manually change by the client.
+	//
+	// Defaults to an empty map.
+	SSLProxyHeaders map[string]string `json:"sslProxyHeaders,omitempty" yaml:"SSLProxyHeaders" toml:"SSLProxyHeaders"`
+
+	// HostProxyHeaders defines the set of headers that may hold the host,
+	// as seen by the client, when the server is behind a trusted proxy (look `context.FullRequestURI()`).
+	// Example: `map[string]bool{"X-Forwarded-Host": true}`.
+	//
+	// Defaults to an empty map.
+	HostProxyHeaders map[string]bool `json:"hostProxyHeaders,omitempty" yaml:"HostProxyHeaders" toml:"HostProxyHeaders"`
+
+	// RestrictRelativeRedirects if true then the `context.Redirect` acts like the `context.SafeRedirect`,
+	// it refuses to redirect to absolute urls of foreign hosts, except the `RedirectAllowedHosts`,
+	// it protects the login/return-to flows against open redirects.
+	//
+	// Defaults to false.
+	RestrictRelativeRedirects bool `json:"restrictRelativeRedirects,omitempty" yaml:"RestrictRelativeRedirects" toml:"RestrictRelativeRedirects"`
+
+	// RedirectAllowedHosts is the list of foreign hosts that the `context.SafeRedirect`
+	// (and `context.Redirect` when `RestrictRelativeRedirects` is true) can redirect to,
+	// a host may start with "*." to allow all of its subdomains, i.e "*.mydomain.com".
+	//
+	// Defaults to an empty list, only same-host redirects are allowed.
+	RedirectAllowedHosts []string `json:"redirectAllowedHosts,omitempty" yaml:"RedirectAllowedHosts" toml:"RedirectAllowedHosts"`
+
+	// URLSigningKey is the secret key of the HMAC signature
+	// of the signed urls, see `Application#SignURL` and `Application#RequireSignedURL`.
+	//
+	// Defaults to empty, a random key is generated,
+	// so the signed urls are not valid after a restart of the application.
+	URLSigningKey string `json:"urlSigningKey,omitempty" yaml:"URLSigningKey" toml:"URLSigningKey"`
+
+	// CookieSecret is the secret key of the HMAC signature of the cookies
+	// which are set by the `context.SetCookieKV`, the `context.GetCookie` returns
+	// an empty value for a signed cookie with an invalid signature, i.e a modified one,
+	// and the unsigned ones as they're, the `context.GetSignedCookie` accepts the valid signed cookies only.
+	// The `context.SetCookie` and the `context.VisitAllCookies` are not affected.
+	//
+	// Defaults to empty, the cookies are not signed.
+	CookieSecret string `json:"cookieSecret,omitempty" yaml:"CookieSecret" toml:"CookieSecret"`
+
+	// CookieEncryption if true then the values of the signed cookies, see `CookieSecret`,
+	// are encrypted (AES-GCM) too, so they can't be read by the clients.
+	//
+	// Defaults to false.
+	CookieEncryption bool `json:"cookieEncryption,omitempty" yaml:"CookieEncryption" toml:"CookieEncryption"`
+
+	// CookieSameSite is the default SameSite attribute of the cookies of the `context.SetCookieKV`
+	// and the `context.RemoveCookie`, i.e `http.SameSiteLaxMode` (2) or `http.SameSiteStrictMode` (3),
+	// the `context.CookieSameSite` option of a call overrides it.
+	//
+	// Defaults to 0, the attribute is not sent.
+	CookieSameSite http.SameSite `json:"cookieSameSite,omitempty" yaml:"CookieSameSite" toml:"CookieSameSite"`
+
+	// CookieSecure if true then the cookies of the `context.SetCookieKV`
+	// and the `context.RemoveCookie` are secure, they are sent by the clients over https only.
+	//
+	// Defaults to false.
+	CookieSecure bool `json:"cookieSecure,omitempty" yaml:"CookieSecure" toml:"CookieSecure"`
+
+	// CookieDomain is the default Domain attribute of the cookies of the `context.SetCookieKV`
+	// and the `context.RemoveCookie`.
+	//
+	// Defaults to empty, the cookies are sent back to the request's host only.
+	CookieDomain string `json:"cookieDomain,omitempty" yaml:"CookieDomain" toml:"CookieDomain"`
+
 	// Other are the custom, dynamic options, can be empty.
 	// This field used only by you to set any app's options you want.
 	//
+	// The typed sections, i.e the middleware configurations, should be registered
+	// with the `Application#ConfigureSection` instead, their values are decoded into the registered structs.
+	//
 	// Defaults to a non-nil empty map.
 	Other map[string]interface{} `json:"other,omitempty" yaml:"Other" toml:"Other"`
 }
@@ -579,6 +1045,30 @@ func (c Configuration) GetFireMethodNotAllowed() bool {
 	return c.FireMethodNotAllowed
 }
 
+// GetDetectHeaderMutations returns the Configuration#DetectHeaderMutations.
+// If true then late or concurrent response header modifications are logged as warnings.
+func (c Configuration) GetDetectHeaderMutations() bool {
+	return c.DetectHeaderMutations
+}
+
+// GetSlowRequestThreshold returns the Configuration#SlowRequestThreshold,
+// requests that took longer than that are logged as warnings with their handlers timing breakdown.
+func (c Configuration) GetSlowRequestThreshold() time.Duration {
+	return c.SlowRequestThreshold
+}
+
+// GetRestrictRelativeRedirects returns the Configuration#RestrictRelativeRedirects,
+// if true then the `context.Redirect` refuses to redirect to foreign hosts.
+func (c Configuration) GetRestrictRelativeRedirects() bool {
+	return c.RestrictRelativeRedirects
+}
+
+// GetRedirectAllowedHosts returns the Configuration#RedirectAllowedHosts,
+// the foreign hosts that the `context.SafeRedirect` can redirect to.
+func (c Configuration) GetRedirectAllowedHosts() []string {
+	return c.RedirectAllowedHosts
+}
+
 // GetDisableBodyConsumptionOnUnmarshal returns the Configuration#GetDisableBodyConsumptionOnUnmarshal,
 // manages the reading behavior of the context's body readers/binders.
 // If returns true then the body consumption by the `context.UnmarshalBody/ReadJSON/ReadXML`
@@ -592,6 +1082,12 @@ func (c Configuration) GetDisableBodyConsumptionOnUnmarshal() bool {
 	return c.DisableBodyConsumptionOnUnmarshal
 }
 
+// GetDisableJSONErrorDetails returns the Configuration#DisableJSONErrorDetails,
+// if true then the `context.ReadJSON` returns the errors of the JSON decoder as they're.
+func (c Configuration) GetDisableJSONErrorDetails() bool {
+	return c.DisableJSONErrorDetails
+}
+
 // GetDisableAutoFireStatusCode returns the Configuration#DisableAutoFireStatusCode.
 // Returns true when the http error status code handler automatic execution turned off.
 func (c Configuration) GetDisableAutoFireStatusCode() bool {
@@ -670,6 +1166,113 @@ func (c Configuration) GetRemoteAddrHeaders() map[string]bool {
 	return c.RemoteAddrHeaders
 }
 
+// GetTrustedProxies returns the Configuration#TrustedProxies,
+// the CIDR ranges or the IPs of the proxies that the forwarded headers are accepted from.
+//
+// Look `context.RemoteAddr()` for more.
+func (c Configuration) GetTrustedProxies() []string {
+	return c.TrustedProxies
+}
+
+// GetTrustedProxyNets returns the parsed Configuration#TrustedProxies.
+func (c Configuration) GetTrustedProxyNets() []*net.IPNet {
+	return c.trustedProxyNets
+}
+
+var errTrustedProxy = errors.New("trusted proxy '%s' is not an IP or a CIDR range")
+
+// parseTrustedProxies parses the CIDR ranges or the IPs of the "list",
+// it fails on the first invalid entry, like the `netutil.ProxyProtocolConfig#TrustedUpstreams`.
+func parseTrustedProxies(list []string) ([]*net.IPNet, error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	nets := make([]*net.IPNet, 0, len(list))
+	for _, entry := range list {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			_, n, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, errTrustedProxy.Format(entry)
+			}
+			nets = append(nets, n)
+			continue
+		}
+
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, errTrustedProxy.Format(entry)
+		}
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+
+	return nets, nil
+}
+
+// mustParseTrustedProxies is like the `parseTrustedProxies` but it panics on an invalid entry,
+// it's used by the configurators, an invalid entry is a programmer's error.
+func mustParseTrustedProxies(list []string) []*net.IPNet {
+	nets, err := parseTrustedProxies(list)
+	if err != nil {
+		panic(err)
+	}
+
+	return nets
+}
+
+// GetSSLProxyHeaders returns the Configuration#SSLProxyHeaders,
+// the request header names and the values which, when sent by a trusted proxy,
+// declare that the client connects through https.
+//
+// Look `context.IsTLS()` for more.
+func (c Configuration) GetSSLProxyHeaders() map[string]string {
+	return c.SSLProxyHeaders
+}
+
+// GetHostProxyHeaders returns the Configuration#HostProxyHeaders,
+// the allowed request header names that can be used to retrieve the host
+// as seen by the client.
+//
+// Look `context.FullRequestURI()` for more.
+func (c Configuration) GetHostProxyHeaders() map[string]bool {
+	return c.HostProxyHeaders
+}
+
+// GetCookieSecret returns the Configuration#CookieSecret,
+// the secret key of the signed cookies.
+func (c Configuration) GetCookieSecret() string {
+	return c.CookieSecret
+}
+
+// GetCookieEncryption returns the Configuration#CookieEncryption,
+// if true then the signed cookies are encrypted too.
+func (c Configuration) GetCookieEncryption() bool {
+	return c.CookieEncryption
+}
+
+// GetCookieSameSite returns the Configuration#CookieSameSite,
+// the default SameSite attribute of the cookies.
+func (c Configuration) GetCookieSameSite() http.SameSite {
+	return c.CookieSameSite
+}
+
+// GetCookieSecure returns the Configuration#CookieSecure,
+// if true then the cookies are secure by default.
+func (c Configuration) GetCookieSecure() bool {
+	return c.CookieSecure
+}
+
+// GetCookieDomain returns the Configuration#CookieDomain,
+// the default Domain attribute of the cookies.
+func (c Configuration) GetCookieDomain() string {
+	return c.CookieDomain
+}
+
 // GetOther returns the Configuration#Other map.
 func (c Configuration) GetOther() map[string]interface{} {
 	return c.Other
@@ -699,6 +1302,11 @@ func WithConfiguration(c Configuration) Configurator {
 			main.DisableInterruptHandler = v
 		}
 
+		if v := c.LogLevel; v != "" {
+			main.LogLevel = v
+			app.logger.SetLevel(v)
+		}
+
 		if v := c.DisablePathCorrection; v {
 			main.DisablePathCorrection = v
 		}
@@ -719,10 +1327,58 @@ func WithConfiguration(c Configuration) Configurator {
 			main.FireMethodNotAllowed = v
 		}
 
+		if v := c.DetectHeaderMutations; v {
+			main.DetectHeaderMutations = v
+		}
+
+		if v := c.EnableHandlerTiming; v {
+			main.EnableHandlerTiming = v
+		}
+
+		if v := c.SlowRequestThreshold; v > 0 {
+			main.SlowRequestThreshold = v
+		}
+
 		if v := c.DisableBodyConsumptionOnUnmarshal; v {
 			main.DisableBodyConsumptionOnUnmarshal = v
 		}
 
+		if v := c.DisableJSONErrorDetails; v {
+			main.DisableJSONErrorDetails = v
+		}
+
+		if v := c.RestrictRelativeRedirects; v {
+			main.RestrictRelativeRedirects = v
+		}
+
+		if v := c.URLSigningKey; v != "" {
+			main.URLSigningKey = v
+		}
+
+		if v := c.CookieSecret; v != "" {
+			main.CookieSecret = v
+		}
+
+		if v := c.CookieEncryption; v {
+			main.CookieEncryption = v
+		}
+
+		if v := c.CookieSameSite; v != 0 {
+			main.CookieSameSite = v
+		}
+
+		if v := c.CookieSecure; v {
+			main.CookieSecure = v
+		}
+
+		if v := c.CookieDomain; v != "" {
+			main.CookieDomain = v
+		}
+
+		if v := c.RedirectAllowedHosts; len(v) > 0 {
+			main.RedirectAllowedHosts = append(main.RedirectAllowedHosts, v...)
+		}
+
 		if v := c.DisableAutoFireStatusCode; v {
 			main.DisableAutoFireStatusCode = v
 		}
@@ -764,6 +1420,29 @@ func WithConfiguration(c Configuration) Configurator {
 			}
 		}
 
+		if v := c.TrustedProxies; len(v) > 0 {
+			main.TrustedProxies = append(main.TrustedProxies, v...)
+			main.trustedProxyNets = mustParseTrustedProxies(main.TrustedProxies)
+		}
+
+		if v := c.SSLProxyHeaders; len(v) > 0 {
+			if main.SSLProxyHeaders == nil {
+				main.SSLProxyHeaders = make(map[string]string, len(v))
+			}
+			for key, value := range v {
+				main.SSLProxyHeaders[key] = value
+			}
+		}
+
+		if v := c.HostProxyHeaders; len(v) > 0 {
+			if main.HostProxyHeaders == nil {
+				main.HostProxyHeaders = make(map[string]bool, len(v))
+			}
+			for key, value := range v {
+				main.HostProxyHeaders[key] = value
+			}
+		}
+
 		if v := c.Other; len(v) > 0 {
 			if main.Other == nil {
 				main.Other = make(map[string]interface{}, len(v))
@@ -771,10 +1450,109 @@ func WithConfiguration(c Configuration) Configurator {
 			for key, value := range v {
 				main.Other[key] = value
 			}
+
+			app.decodeSections()
 		}
 	}
 }
 
+// withLiveFields returns a copy of the "c" with the live fields of the "from",
+// the fields that are read on each request and can be changed while the server is running:
+// LogLevel, SlowRequestThreshold, FireMethodNotAllowed, DisablePathCorrection, DisablePathCorrectionRedirection,
+// DisableBodyConsumptionOnUnmarshal, DisableAutoFireStatusCode, RestrictRelativeRedirects, RedirectAllowedHosts, TrustedProxies,
+// TimeFormat, Charset, PostMaxMemory and Other.
+//
+// Unlike the `WithConfiguration`, the "from" values replace the current ones,
+// i.e a feature toggle can be turned off as well,
+// the empty TimeFormat, Charset and PostMaxMemory fall back to their defaults
+// and an empty LogLevel keeps the current level.
+// It fails if the "from" contains an invalid TrustedProxies entry.
+// 只有运行时(每个请求)读取的配置字段可以热更新，其他字段(如vhost)在Run之后就固定了
+func (c Configuration) withLiveFields(from Configuration) (Configuration, error) {
+	nets, err := parseTrustedProxies(from.TrustedProxies)
+	if err != nil {
+		return c, err
+	}
+
+	def := DefaultConfiguration()
+
+	if from.LogLevel != "" {
+		c.LogLevel = from.LogLevel
+	}
+	c.SlowRequestThreshold = from.SlowRequestThreshold
+	c.FireMethodNotAllowed = from.FireMethodNotAllowed
+	c.DisablePathCorrection = from.DisablePathCorrection
+	c.DisablePathCorrectionRedirection = from.DisablePathCorrectionRedirection
+	c.DisableBodyConsumptionOnUnmarshal = from.DisableBodyConsumptionOnUnmarshal
+	c.DisableAutoFireStatusCode = from.DisableAutoFireStatusCode
+	c.RestrictRelativeRedirects = from.RestrictRelativeRedirects
+	c.RedirectAllowedHosts = append([]string(nil), from.RedirectAllowedHosts...)
+	c.TrustedProxies = append([]string(nil), from.TrustedProxies...)
+	c.trustedProxyNets = nets
+
+	c.TimeFormat = from.TimeFormat
+	if c.TimeFormat == "" {
+		c.TimeFormat = def.TimeFormat
+	}
+	c.Charset = from.Charset
+	if c.Charset == "" {
+		c.Charset = def.Charset
+	}
+	c.PostMaxMemory = from.PostMaxMemory
+	if c.PostMaxMemory <= 0 {
+		c.PostMaxMemory = def.PostMaxMemory
+	}
+
+	c.Other = make(map[string]interface{}, len(from.Other))
+	for key, value := range from.Other {
+		c.Other[key] = value
+	}
+
+	return c, nil
+}
+
+// clone returns a copy of the "c" which does not share its maps and slices,
+// so the copy can be read while the "c" is modified.
+func (c Configuration) clone() Configuration {
+	c.IgnoreServerErrors = append([]string(nil), c.IgnoreServerErrors...)
+	c.TrustedProxies = append([]string(nil), c.TrustedProxies...)
+	c.RedirectAllowedHosts = append([]string(nil), c.RedirectAllowedHosts...)
+
+	if c.RemoteAddrHeaders != nil {
+		m := make(map[string]bool, len(c.RemoteAddrHeaders))
+		for k, v := range c.RemoteAddrHeaders {
+			m[k] = v
+		}
+		c.RemoteAddrHeaders = m
+	}
+
+	if c.HostProxyHeaders != nil {
+		m := make(map[string]bool, len(c.HostProxyHeaders))
+		for k, v := range c.HostProxyHeaders {
+			m[k] = v
+		}
+		c.HostProxyHeaders = m
+	}
+
+	if c.SSLProxyHeaders != nil {
+		m := make(map[string]string, len(c.SSLProxyHeaders))
+		for k, v := range c.SSLProxyHeaders {
+			m[k] = v
+		}
+		c.SSLProxyHeaders = m
+	}
+
+	if c.Other != nil {
+		m := make(map[string]interface{}, len(c.Other))
+		for k, v := range c.Other {
+			m[k] = v
+		}
+		c.Other = m
+	}
+
+	return c
+}
+
 // DefaultConfiguration returns the default configuration for an iris station, fills the main Configuration
 func DefaultConfiguration() Configuration {
 	return Configuration{
@@ -783,6 +1561,7 @@ func DefaultConfiguration() Configuration {
 		DisablePathCorrection:             false,
 		EnablePathEscape:                  false,
 		FireMethodNotAllowed:              false,
+		DetectHeaderMutations:             false,
 		DisableBodyConsumptionOnUnmarshal: false,
 		DisableAutoFireStatusCode:         false,
 		TimeFormat:                        "Mon, Jan 02 2006 15:04:05 GMT",
@@ -799,6 +1578,9 @@ func DefaultConfiguration() Configuration {
 		ViewLayoutContextKey:        "iris.viewLayout",
 		ViewDataContextKey:          "iris.viewData",
 		RemoteAddrHeaders:           make(map[string]bool),
+		SSLProxyHeaders:             make(map[string]string),
+		HostProxyHeaders:            make(map[string]bool),
+		RestrictRelativeRedirects:   false,
 		EnableOptimizations:         false,
 		Other:                       make(map[string]interface{}),
 	}
@@ -1,13 +1,21 @@
 package iris
 
 import (
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v2"
+
+	"github.com/kataras/iris/context"
 )
 
 // $ go test -v -run TestConfiguration*
@@ -324,3 +332,584 @@ Charset = "UTF-8"
 		t.Fatalf("error on TestConfigurationTOML: Expected Other['MyServerName'] %s but got %s", expected, got)
 	}
 }
+
+func TestConfigurationWatch(t *testing.T) {
+	yamlFile, err := ioutil.TempFile("", "configuration_watch.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := yamlFile.Name()
+	defer os.Remove(filename)
+
+	yamlFile.WriteString("FireMethodNotAllowed: true\nEnableOptimizations: true\n")
+	yamlFile.Close()
+
+	app := New().Configure(WithConfiguration(YAML(filename)))
+	w, err := app.WatchConfiguration(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	changed := make(chan [2]Configuration, 1)
+	app.OnConfigurationChange(func(old, new Configuration) {
+		changed <- [2]Configuration{old, new}
+	})
+
+	// the toggle is turned off, the build-time fields are ignored.
+	if err = ioutil.WriteFile(filename, []byte("Charset: \"ISO-8859-1\"\nEnableOptimizations: false\nOther:\n  key: value\n"), os.FileMode(0644)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case configs := <-changed:
+		old, c := configs[0], configs[1]
+		if !old.FireMethodNotAllowed || c.FireMethodNotAllowed {
+			t.Fatalf("expected FireMethodNotAllowed to be changed from true to false but got %v to %v", old.FireMethodNotAllowed, c.FireMethodNotAllowed)
+		}
+		if expected, got := "ISO-8859-1", app.ConfigurationReadOnly().GetCharset(); expected != got {
+			t.Fatalf("expected live charset %s but got %s", expected, got)
+		}
+		if expected, got := DefaultConfiguration().TimeFormat, c.TimeFormat; expected != got {
+			t.Fatalf("expected missing TimeFormat to fall back to %s but got %s", expected, got)
+		}
+		if expected, got := "value", app.ConfigurationReadOnly().GetOther()["key"]; expected != got {
+			t.Fatalf("expected other key's value %s but got %v", expected, got)
+		}
+		if !c.EnableOptimizations {
+			t.Fatalf("expected EnableOptimizations to be kept, it cannot be changed while running")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a configuration change")
+	}
+
+	if app.UpdateConfiguration(*app.config) {
+		t.Fatalf("expected no change for the same configuration")
+	}
+}
+
+func TestConfigurationUpdatePublishesCopy(t *testing.T) {
+	app := New()
+	c := *app.config
+	c.Charset = "ISO-8859-1"
+	c.Other = map[string]interface{}{"key": "value"}
+	if !app.UpdateConfiguration(c) {
+		t.Fatalf("expected a configuration change")
+	}
+
+	live := app.ConfigurationReadOnly().(*Configuration)
+	if live == app.config {
+		t.Fatalf("expected the published configuration to be a copy")
+	}
+
+	// the app's configuration can be modified without affecting the readers.
+	app.config.Other["key"] = "modified"
+	app.config.Other["other"] = "value"
+	if expected, got := "value", live.GetOther()["key"]; expected != got {
+		t.Fatalf("expected the published other key's value %s but got %v", expected, got)
+	}
+	if _, ok := live.GetOther()["other"]; ok {
+		t.Fatalf("expected the published other to not contain a later key")
+	}
+}
+
+func TestConfigurationConfigureAfterUpdate(t *testing.T) {
+	app := New()
+	c := *app.config
+	c.TimeFormat = "2006"
+	if !app.UpdateConfiguration(c) {
+		t.Fatalf("expected a configuration change")
+	}
+
+	app.Configure(WithCharset("ISO-8859-1"), WithTrustedProxies("10.0.0.0/8"))
+	live := app.ConfigurationReadOnly()
+	if expected, got := "ISO-8859-1", live.GetCharset(); expected != got {
+		t.Fatalf("expected the configured charset %s but got %s", expected, got)
+	}
+	if expected, got := 1, len(live.GetTrustedProxyNets()); expected != got {
+		t.Fatalf("expected %d configured trusted proxies but got %d", expected, got)
+	}
+	// the previous update is kept.
+	if expected, got := "2006", live.GetTimeFormat(); expected != got {
+		t.Fatalf("expected the updated time format %s but got %s", expected, got)
+	}
+}
+
+func newTrustedProxiesApp(t *testing.T, trusted ...string) *Application {
+	app := New().Configure(
+		WithRemoteAddrHeader("X-Forwarded-For"),
+		WithRemoteAddrHeader("Forwarded"),
+		WithRemoteAddrHeader("X-Real-Ip"),
+		WithSSLProxyHeader("X-Forwarded-Proto", "https"),
+		WithTrustedProxies(trusted...),
+	)
+	app.Get("/", func(ctx Context) {
+		ctx.Writef("%s %s", ctx.RemoteAddr(), context.Scheme(ctx))
+	})
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+type trustedProxiesTest struct {
+	remoteAddr string
+	headers    map[string]string
+	expected   string
+}
+
+func testTrustedProxies(t *testing.T, app *Application, tests []trustedProxiesTest) {
+	t.Helper()
+
+	for i, tt := range tests {
+		r := httptest.NewRequest(MethodGet, "/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		for k, v := range tt.headers {
+			r.Header.Set(k, v)
+		}
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if got := w.Body.String(); got != tt.expected {
+			t.Fatalf("[%d] expected %q but got %q", i, tt.expected, got)
+		}
+	}
+}
+
+func TestConfigurationTrustedProxies(t *testing.T) {
+	testTrustedProxies(t, newTrustedProxiesApp(t, "10.0.0.0/8", "192.168.1.10"), []trustedProxiesTest{
+		// the headers of an untrusted peer are ignored.
+		{"203.0.113.7:1234", map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Forwarded-Proto": "https"}, "203.0.113.7 http"},
+		// the spoofed entries of the client are skipped.
+		{"10.0.0.2:1234", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.9, 10.1.1.1", "X-Forwarded-Proto": "https"}, "198.51.100.9 https"},
+		{"192.168.1.10:1234", map[string]string{"X-Forwarded-For": "10.1.1.1"}, "10.1.1.1 http"},
+		{"10.0.0.2:1234", map[string]string{"Forwarded": `for=1.2.3.4, for="[2001:db8:cafe::17]:4711";proto=https, for=10.0.0.3`}, "2001:db8:cafe::17 http"},
+		{"10.0.0.2:1234", map[string]string{"Forwarded": "for=unknown, for=10.0.0.3"}, "10.0.0.2 http"},
+		{"10.0.0.2:1234", map[string]string{"X-Real-Ip": "1.2.3.4"}, "1.2.3.4 http"},
+	})
+}
+
+func TestConfigurationNoTrustedProxies(t *testing.T) {
+	// the forwarded headers are never trusted by default.
+	testTrustedProxies(t, newTrustedProxiesApp(t), []trustedProxiesTest{
+		{"10.0.0.2:1234", map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Forwarded-Proto": "https"}, "10.0.0.2 http"},
+		{"10.0.0.2:1234", map[string]string{"Forwarded": "for=1.2.3.4"}, "10.0.0.2 http"},
+		{"203.0.113.7:1234", map[string]string{"X-Real-Ip": "1.2.3.4"}, "203.0.113.7 http"},
+	})
+}
+
+func TestConfigurationTrustAllProxies(t *testing.T) {
+	// the previous behavior, the first hop of the client is used.
+	testTrustedProxies(t, newTrustedProxiesApp(t, "0.0.0.0/0", "::/0"), []trustedProxiesTest{
+		{"203.0.113.7:1234", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.9"}, "1.2.3.4 http"},
+		{"[2001:db8::1]:1234", map[string]string{"X-Real-Ip": "1.2.3.4"}, "1.2.3.4 http"},
+	})
+}
+
+func TestConfigurationUpdateTrustedProxies(t *testing.T) {
+	app := newTrustedProxiesApp(t, "10.0.0.0/8")
+	if expected, got := 1, len(app.ConfigurationReadOnly().GetTrustedProxyNets()); expected != got {
+		t.Fatalf("expected %d parsed trusted proxies but got %d", expected, got)
+	}
+
+	// an invalid entry rejects the whole update.
+	c := app.ConfigurationReadOnly().(*Configuration).clone()
+	c.TrustedProxies = []string{"192.168.1.10", "invalid"}
+	if app.UpdateConfiguration(c) {
+		t.Fatalf("expected the invalid trusted proxies to be rejected")
+	}
+	testTrustedProxies(t, app, []trustedProxiesTest{
+		{"10.0.0.2:1234", map[string]string{"X-Real-Ip": "1.2.3.4"}, "1.2.3.4 http"},
+		{"192.168.1.10:1234", map[string]string{"X-Real-Ip": "1.2.3.4"}, "192.168.1.10 http"},
+	})
+
+	c.TrustedProxies = []string{"192.168.1.10"}
+	if !app.UpdateConfiguration(c) {
+		t.Fatalf("expected the trusted proxies to be updated")
+	}
+	if expected, got := 1, len(app.ConfigurationReadOnly().GetTrustedProxyNets()); expected != got {
+		t.Fatalf("expected %d parsed trusted proxies but got %d", expected, got)
+	}
+
+	testTrustedProxies(t, app, []trustedProxiesTest{
+		{"10.0.0.2:1234", map[string]string{"X-Real-Ip": "1.2.3.4"}, "10.0.0.2 http"},
+		{"192.168.1.10:1234", map[string]string{"X-Real-Ip": "1.2.3.4"}, "1.2.3.4 http"},
+	})
+}
+
+func TestConfigurationInvalidTrustedProxies(t *testing.T) {
+	for _, entry := range []string{"invalid", "10.0.0.0/33", "10.0.0"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected a panic for the invalid trusted proxy %q", entry)
+				}
+			}()
+
+			New().Configure(WithTrustedProxies("10.0.0.0/8", entry))
+		}()
+	}
+}
+
+func TestConfigurationCookieSecret(t *testing.T) {
+	for _, encrypt := range []bool{false, true} {
+		app := New().Configure(WithCookieSecret("secret"))
+		if encrypt {
+			app.Configure(WithCookieEncryption)
+		}
+		app.Get("/set", func(ctx Context) {
+			ctx.SetCookieKV("name", "value")
+		})
+		app.Get("/get", func(ctx Context) {
+			ctx.WriteString(ctx.GetCookie("name"))
+		})
+		app.Get("/get-signed", func(ctx Context) {
+			ctx.WriteString(ctx.GetSignedCookie("name"))
+		})
+		if err := app.Build(); err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(MethodGet, "/set", nil))
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 {
+			t.Fatalf("[encrypt=%v] expected a cookie but got %d", encrypt, len(cookies))
+		}
+		signed := cookies[0]
+		if signed.Value == "value" || (encrypt && strings.Contains(signed.Value, "value")) {
+			t.Fatalf("[encrypt=%v] expected a signed value but got %q", encrypt, signed.Value)
+		}
+
+		tests := []struct {
+			value          string
+			expected       string
+			expectedSigned string
+		}{
+			{signed.Value, "value", "value"},
+			// the unsigned cookies, i.e of the `SetCookie`, are read as they're.
+			{"value", "value", ""},
+			{"header.payload.c2lnbmF0dXJlc2lnbmF0dXJlc2lnbmF0dXJlc2lnbmF0dXJl", "header.payload.c2lnbmF0dXJlc2lnbmF0dXJlc2lnbmF0dXJlc2lnbmF0dXJl", ""},
+			{signed.Value[:3] + "other" + signed.Value[8:], "", ""},
+			{signed.Value[:len(signed.Value)-2] + "xx", "", ""},
+		}
+
+		for i, tt := range tests {
+			for path, expected := range map[string]string{"/get": tt.expected, "/get-signed": tt.expectedSigned} {
+				r := httptest.NewRequest(MethodGet, path, nil)
+				r.AddCookie(&http.Cookie{Name: "name", Value: tt.value})
+				w := httptest.NewRecorder()
+				app.ServeHTTP(w, r)
+				if got := w.Body.String(); got != expected {
+					t.Fatalf("[encrypt=%v][%d] %s: expected %q but got %q", encrypt, i, path, expected, got)
+				}
+			}
+		}
+
+		// the signed cookies of the other mode are still valid.
+		app.Configure(func(app *Application) { app.config.CookieEncryption = !encrypt })
+		r := httptest.NewRequest(MethodGet, "/get-signed", nil)
+		r.AddCookie(&http.Cookie{Name: "name", Value: signed.Value})
+		w = httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if got := w.Body.String(); got != "value" {
+			t.Fatalf("[encrypt=%v] expected the cookie of the other mode to be valid but got %q", encrypt, got)
+		}
+		app.Configure(func(app *Application) { app.config.CookieEncryption = encrypt })
+
+		// a value can't be moved to another cookie.
+		r = httptest.NewRequest(MethodGet, "/get", nil)
+		r.AddCookie(&http.Cookie{Name: "name", Value: signValueOf(t, app, "other", "value")})
+		w = httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if got := w.Body.String(); got != "" {
+			t.Fatalf("[encrypt=%v] expected the cookie of another name to be rejected but got %q", encrypt, got)
+		}
+	}
+}
+
+func TestConfigurationCookieSecretEncode(t *testing.T) {
+	reverse := func(s string) string {
+		b := []byte(s)
+		for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+		return string(b)
+	}
+	encode := context.CookieEncode(func(_ string, value interface{}) (string, error) {
+		return reverse(value.(string)), nil
+	})
+	decode := context.CookieDecode(func(_ string, cookieValue string, v interface{}) error {
+		*(v.(*string)) = reverse(cookieValue)
+		return nil
+	})
+
+	app := New().Configure(WithCookieSecret("secret"))
+	app.Get("/set", func(ctx Context) {
+		ctx.SetCookieKV("name", "value", encode)
+	})
+	app.Get("/get", func(ctx Context) {
+		ctx.WriteString(ctx.GetCookie("name", decode))
+	})
+	app.Get("/get-signed", func(ctx Context) {
+		ctx.WriteString(ctx.GetSignedCookie("name", decode))
+	})
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(MethodGet, "/set", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected a cookie but got %d", len(cookies))
+	}
+
+	// the encoded value is signed, so the decoder receives the verified value.
+	for _, path := range []string{"/get", "/get-signed"} {
+		r := httptest.NewRequest(MethodGet, path, nil)
+		r.AddCookie(cookies[0])
+		w = httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if got := w.Body.String(); got != "value" {
+			t.Fatalf("%s: expected the decoded value but got %q", path, got)
+		}
+	}
+}
+
+func signValueOf(t *testing.T, app *Application, name, value string) string {
+	w := httptest.NewRecorder()
+	ctx := app.ContextPool.Acquire(w, httptest.NewRequest(MethodGet, "/", nil))
+	ctx.SetCookieKV(name, value)
+	app.ContextPool.Release(ctx)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected a cookie but got %d", len(cookies))
+	}
+	return cookies[0].Value
+}
+
+type customContext struct {
+	context.Context
+}
+
+func (ctx *customContext) Do(handlers context.Handlers) {
+	context.Do(ctx, handlers)
+}
+
+func (ctx *customContext) Next() {
+	context.Next(ctx)
+}
+
+func (ctx *customContext) HTML(contents string) (int, error) {
+	return ctx.WriteString("custom: " + contents)
+}
+
+func TestConfigurationCustomContext(t *testing.T) {
+	app := New()
+	// a context created before, it should not be reused.
+	app.ContextPool.Release(app.ContextPool.Acquire(httptest.NewRecorder(), httptest.NewRequest(MethodGet, "/", nil)))
+
+	app.Configure(WithCustomContext(func(app context.Application) context.Context {
+		return &customContext{Context: context.NewContext(app)}
+	}))
+	app.Get("/", func(ctx Context) {
+		ctx.Next()
+	}, func(ctx Context) {
+		if _, ok := ctx.(*customContext); !ok {
+			t.Errorf("expected the custom context but got %T", ctx)
+		}
+		ctx.HTML("hello")
+	})
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(MethodGet, "/", nil))
+		if expected, got := "custom: hello", w.Body.String(); got != expected {
+			t.Fatalf("[%d] expected %q but got %q", i, expected, got)
+		}
+	}
+}
+
+func TestConfigurationSection(t *testing.T) {
+	var yamlConfiguration Configuration
+	err := yaml.Unmarshal([]byte(`
+Other:
+  cors:
+    allowedOrigins: ["https://*.example.com"]
+    AllowCredentials: true
+    MaxAge: 3600000000000
+`), &yamlConfiguration)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := New()
+	// registered before the configuration is loaded.
+	corsOptions := &CORSOptions{ExposedHeaders: []string{"X-Total"}}
+	if err = app.ConfigureSection("cors", corsOptions); err != nil {
+		t.Fatal(err)
+	}
+	app.Configure(WithConfiguration(yamlConfiguration))
+
+	expected := CORSOptions{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		ExposedHeaders:   []string{"X-Total"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}
+	if !reflect.DeepEqual(*corsOptions, expected) {
+		t.Fatalf("expected yaml section %#v but got %#v", expected, *corsOptions)
+	}
+	if got := app.ConfigurationReadOnly().GetOther()["cors"]; got != corsOptions {
+		t.Fatalf("expected Other[cors] to be the registered section but got %#v", got)
+	}
+
+	var tomlConfiguration Configuration
+	if _, err = toml.Decode(`
+[Other.cors]
+	AllowedOrigins = ["https://*.example.com"]
+	AllowCredentials = true
+	MaxAge = 3600000000000
+	ExposedHeaders = ["X-Total"]
+`, &tomlConfiguration); err != nil {
+		t.Fatal(err)
+	}
+
+	// registered after the configuration is loaded.
+	app = New().Configure(WithConfiguration(tomlConfiguration))
+	corsOptions = new(CORSOptions)
+	if err = app.ConfigureSection("cors", corsOptions); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*corsOptions, expected) {
+		t.Fatalf("expected toml section %#v but got %#v", expected, *corsOptions)
+	}
+
+	// unknown keys are reported.
+	app = New().Configure(WithOtherValue("cors", map[interface{}]interface{}{"AllowedOrigin": "*"}))
+	if err = app.ConfigureSection("cors", new(CORSOptions)); err == nil {
+		t.Fatalf("expected an error for an unknown key of the section")
+	}
+
+	if err = New().ConfigureSection("cors", CORSOptions{}); err == nil {
+		t.Fatalf("expected an error for a non-pointer section")
+	}
+}
+
+type limitsSection struct {
+	Max  int
+	Tags []string
+}
+
+func (l *limitsSection) Validate() error {
+	if l.Max < 0 {
+		return fmt.Errorf("negative max")
+	}
+
+	return nil
+}
+
+func TestConfigurationSectionInvalid(t *testing.T) {
+	tags := []string{"a", "b"}
+	limits := &limitsSection{Max: 10, Tags: tags}
+
+	app := New()
+	if err := app.ConfigureSection("limits", limits); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, raw := range []interface{}{
+		// not valid.
+		map[interface{}]interface{}{"Max": -1, "Tags": []interface{}{"c", "d"}},
+		// not decodable, after a decoded field.
+		map[interface{}]interface{}{"Tags": []interface{}{"c", "d"}, "Max": "max"},
+	} {
+		app.Configure(WithOtherValue("limits", raw))
+		if err := app.decodeSection("limits", limits); err == nil {
+			t.Fatalf("[%d] expected an error", i)
+		}
+
+		if limits.Max != 10 || !reflect.DeepEqual(limits.Tags, []string{"a", "b"}) || !reflect.DeepEqual(tags, []string{"a", "b"}) {
+			t.Fatalf("[%d] expected the section to not be modified but got %#v", i, limits)
+		}
+	}
+
+	app.Configure(WithConfiguration(Configuration{Other: map[string]interface{}{"limits": map[interface{}]interface{}{"Max": 5}}}))
+	if limits.Max != 5 || !reflect.DeepEqual(limits.Tags, []string{"a", "b"}) {
+		t.Fatalf("expected the valid section to be decoded but got %#v", limits)
+	}
+}
+
+func TestConfigurationJSONErrorDetails(t *testing.T) {
+	type address struct {
+		Zip int `json:"zip"`
+	}
+	type user struct {
+		Name    string  `json:"name"`
+		Address address `json:"address"`
+	}
+
+	newApp := func(configurators ...Configurator) *Application {
+		app := New().Configure(configurators...)
+		app.Post("/", func(ctx Context) {
+			var u user
+			if err := ctx.ReadJSON(&u); err != nil {
+				if jsonErr, ok := err.(*context.JSONError); ok {
+					ctx.Problem(jsonErr.Problem())
+					return
+				}
+				ctx.StatusCode(StatusBadRequest)
+				ctx.WriteString(err.Error())
+			}
+		})
+		if err := app.Build(); err != nil {
+			t.Fatal(err)
+		}
+		return app
+	}
+
+	tests := []struct {
+		body     string
+		expected context.Problem
+	}{
+		// the offset of a type mismatch is the end of the value.
+		{`{"name":"gopher","address":{"zip":"10001"}}`, context.Problem{
+			"status": 400.0, "offset": 41.0, "line": 1.0, "column": 42.0,
+			"field": "address.zip", "expected": "int", "value": "string",
+		}},
+		{"{\n  \"name\": \"gopher\",\n  \"address\": {\"zip\" 10001}\n}", context.Problem{
+			"status": 400.0, "offset": 42.0, "line": 3.0, "column": 21.0,
+			"field": "address.zip",
+		}},
+	}
+
+	app := newApp()
+	for i, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(MethodPost, "/", strings.NewReader(tt.body)))
+
+		var got context.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("[%d] %v: %s", i, err, w.Body.String())
+		}
+		for key, value := range tt.expected {
+			if got[key] != value {
+				t.Fatalf("[%d] expected problem member %s=%v but got %v", i, key, value, got[key])
+			}
+		}
+	}
+
+	// the errors of the JSON decoder as they're.
+	app = newApp(WithoutJSONErrorDetails)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(MethodPost, "/", strings.NewReader(tests[1].body)))
+	if expected, got := "invalid character '1' after object key", w.Body.String(); got != expected {
+		t.Fatalf("expected %q but got %q", expected, got)
+	}
+}
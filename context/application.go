@@ -22,6 +22,11 @@ type Application interface {
 	// Use context.View to render templates to the client instead.
 	// Returns an error on failure, otherwise nil.
 	View(writer io.Writer, filename string, layout string, bindingData interface{}) error
+	// ViewPartial executes and write the result of a shared partial to the writer.
+	//
+	// Use context.ViewPartial to render partials to the client instead.
+	// Returns an error on failure, otherwise nil.
+	ViewPartial(writer io.Writer, partialName string, bindingData interface{}) error
 
 	// ServeHTTPC is the internal router, it's visible because it can be used for advanced use cases,
 	// i.e: routing within a foreign context.
@@ -57,4 +62,33 @@ type Application interface {
 	// RouteExists reports whether a particular route exists
 	// It will search from the current subdomain of context's host, if not inside the root domain.
 	RouteExists(ctx Context, method, path string) bool
+
+	// AllowedMethods returns the methods of the routes that match the "path"
+	// and the current subdomain of context's host, i.e for the "Allow" header.
+	AllowedMethods(ctx Context, path string) []string
+
+	// GetExecutionInterceptors returns the registered execution interceptors,
+	// they wrap each handler invocation of the requests served by this application.
+	//
+	// Look `ExecutionInterceptor` for more.
+	GetExecutionInterceptors() []ExecutionInterceptor
+
+	// GetValidator returns the registered struct validator, if any,
+	// it's called by the body readers after a successful unmarshal.
+	//
+	// Look `Validator` for more.
+	GetValidator() Validator
+
+	// GetTracer returns the registered tracer, if any,
+	// the router starts a span for each request of a route through it.
+	//
+	// Look `Tracer` for more.
+	GetTracer() Tracer
+
+	// RegisterLongLivedConn registers a long-lived connection, i.e a websocket or an SSE one,
+	// which is notified and closed on the shutdown of the application's hosts.
+	// It returns a function which unregisters it, it should be called when the connection is closed.
+	//
+	// Look `LongLivedConn` for more.
+	RegisterLongLivedConn(conn LongLivedConn) (unregister func())
 }
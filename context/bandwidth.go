@@ -0,0 +1,124 @@
+package context
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+// BandwidthFunc receives the number of the request body bytes read ("in")
+// and of the response body bytes sent to the client ("out"), as they happen,
+// see `Context#TrackBandwidth`.
+type BandwidthFunc func(in, out int64)
+
+// bandwidthTracker wraps the underline, naive, http.ResponseWriter
+// in order to count the bytes that are actually sent to the client,
+// i.e the compressed ones and the ones flushed by the recorder at the end of the request.
+// 统计请求体读取的字节数以及真正发送给客户端的字节数(流量统计)
+type bandwidthTracker struct {
+	http.ResponseWriter
+	listeners []BandwidthFunc
+}
+
+func (t *bandwidthTracker) notify(in, out int64) {
+	for _, listener := range t.listeners {
+		listener(in, out)
+	}
+}
+
+// Write writes the data to the connection as part of an HTTP reply.
+func (t *bandwidthTracker) Write(contents []byte) (int, error) {
+	n, err := t.ResponseWriter.Write(contents)
+	if n > 0 {
+		t.notify(0, int64(n))
+	}
+	return n, err
+}
+
+// Flush sends any buffered data to the client.
+func (t *bandwidthTracker) Flush() {
+	if flusher, ok := t.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection,
+// the bytes of a hijacked connection are not counted.
+func (t *bandwidthTracker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := t.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+
+	return nil, nil, errors.New("hijack is not supported by this ResponseWriter")
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (t *bandwidthTracker) CloseNotify() <-chan bool {
+	if n, ok := t.ResponseWriter.(http.CloseNotifier); ok {
+		return n.CloseNotify()
+	}
+
+	return nil
+}
+
+// Push initiates an HTTP/2 server push.
+func (t *bandwidthTracker) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := t.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+
+	return http.ErrNotSupported
+}
+
+// bandwidthBody counts the bytes read from the request body.
+type bandwidthBody struct {
+	io.ReadCloser
+	tracker *bandwidthTracker
+}
+
+func (b *bandwidthBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.tracker.notify(int64(n), 0)
+	}
+	return n, err
+}
+
+// unwrapBandwidthTracker returns the http.ResponseWriter under the bandwidth tracker, if any.
+func unwrapBandwidthTracker(w http.ResponseWriter) http.ResponseWriter {
+	if t, ok := w.(*bandwidthTracker); ok {
+		return t.ResponseWriter
+	}
+
+	return w
+}
+
+// TrackBandwidth registers a listener which receives the number of the request body bytes read
+// and of the response body bytes sent to the client, as they happen, until the end of the request.
+// The response bytes are the ones that are actually sent, i.e after gzip compression,
+// the headers are not counted.
+//
+// It's the base of the bandwidth accounting, see the `middleware/bandwidth` package.
+func (ctx *context) TrackBandwidth(listener BandwidthFunc) {
+	b := baseResponseWriter(ctx.writer)
+	if b == nil {
+		ctx.Application().Logger().Warnf("%s: bandwidth tracking is not supported by custom response writers", ctx.HandlerName())
+		return
+	}
+
+	t, ok := b.ResponseWriter.(*bandwidthTracker)
+	if !ok {
+		t = &bandwidthTracker{ResponseWriter: b.ResponseWriter}
+		b.ResponseWriter = t
+
+		if body := ctx.request.Body; body != nil && body != http.NoBody {
+			ctx.request.Body = &bandwidthBody{ReadCloser: body, tracker: t}
+		}
+	}
+
+	t.listeners = append(t.listeners, listener)
+}
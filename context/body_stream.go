@@ -0,0 +1,147 @@
+package context
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/json-iterator/go"
+	"github.com/kataras/iris/core/errors"
+)
+
+// ErrRequestBodyTooLarge is returned by the streaming body readers, i.e the `Context#ReadNDJSON`,
+// when the request body exceeds the limit set by the `Context#SetMaxRequestBodySize`.
+var ErrRequestBodyTooLarge = errors.New("request body exceeds the limit of %d bytes")
+
+// CSVOptions are the options of the `Context#ReadCSV`.
+type CSVOptions struct {
+	// Comma is the field separator.
+	//
+	// Defaults to ','.
+	Comma rune
+	// Comment is the character which starts a comment line, if not zero.
+	Comment rune
+	// FieldsPerRecord is the number of the fields of each record,
+	// if zero then it's the number of the first record's fields, if negative then it's not checked.
+	FieldsPerRecord int
+	// LazyQuotes if true then a quote may appear in an unquoted field
+	// and a non-doubled quote may appear in a quoted field.
+	LazyQuotes bool
+	// TrimLeadingSpace if true then the leading white space of a field is ignored.
+	TrimLeadingSpace bool
+	// SkipHeader if true then the first record, the header row, is not passed to the "each".
+	SkipHeader bool
+}
+
+// bodyReader keeps the last error of the request body's reads,
+// in order to report the exceeded body size limit even if the decoder wraps or replaces it.
+type bodyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+// bodyErr returns the `ErrRequestBodyTooLarge` if the body size limit was exceeded,
+// otherwise the "err" as it's, i.e a syntax error of a body of exactly the limit's size.
+func bodyErr(body *bodyReader, err error) error {
+	if tooLarge, ok := body.err.(*http.MaxBytesError); ok {
+		return ErrRequestBodyTooLarge.Format(tooLarge.Limit)
+	}
+
+	return err
+}
+
+// ReadNDJSON reads the newline delimited JSON (application/x-ndjson) request body record by record,
+// without buffering the entire body, i.e for bulk-import endpoints.
+// The "factory" returns a new pointer to decode each record into, which is passed to the "each",
+// a non-nil error of the "each" stops the reading and it's returned.
+// The body size limit, see `SetMaxRequestBodySize`, is enforced, an `ErrRequestBodyTooLarge` is returned then.
+//
+// If a `Validator` is registered then the struct values are validated too, before the "each".
+//
+// Usage:
+//
+//	err := ctx.ReadNDJSON(func() interface{} { return new(Product) }, func(record interface{}) error {
+//		return db.Insert(record.(*Product))
+//	})
+func (ctx *context) ReadNDJSON(factory func() interface{}, each func(record interface{}) error) error {
+	if ctx.request.Body == nil {
+		return nil
+	}
+
+	body := &bodyReader{r: ctx.request.Body}
+	var dec interface{ Decode(v interface{}) error } = json.NewDecoder(body)
+	if ctx.shouldOptimize() {
+		dec = jsoniter.NewDecoder(body)
+	}
+
+	for {
+		record := factory()
+		if err := dec.Decode(record); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return bodyErr(body, err)
+		}
+
+		if err := ctx.validate(record); err != nil {
+			return err
+		}
+
+		if err := each(record); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadCSV reads the CSV (text/csv) request body record by record, without buffering the entire body,
+// a non-nil error of the "each" stops the reading and it's returned.
+// The "opts" can customize the separator, the comments and the header row, see `CSVOptions`.
+// The body size limit, see `SetMaxRequestBodySize`, is enforced, an `ErrRequestBodyTooLarge` is returned then.
+// 流式读取CSV请求体, 不会将整个请求体读入内存
+func (ctx *context) ReadCSV(each func(record []string) error, opts ...CSVOptions) error {
+	if ctx.request.Body == nil {
+		return nil
+	}
+
+	var options CSVOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	body := &bodyReader{r: ctx.request.Body}
+	r := csv.NewReader(body)
+	if options.Comma != 0 {
+		r.Comma = options.Comma
+	}
+	r.Comment = options.Comment
+	r.FieldsPerRecord = options.FieldsPerRecord
+	r.LazyQuotes = options.LazyQuotes
+	r.TrimLeadingSpace = options.TrimLeadingSpace
+
+	for header := options.SkipHeader; ; header = false {
+		record, err := r.Read()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return bodyErr(body, err)
+		}
+
+		if header {
+			continue
+		}
+
+		if err := each(record); err != nil {
+			return err
+		}
+	}
+}
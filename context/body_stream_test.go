@@ -0,0 +1,136 @@
+package context_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+type ndjsonProduct struct {
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+}
+
+// writeBodyErr writes the records read before the "err" and the "err" itself, if any.
+func writeBodyErr(ctx iris.Context, records []string, err error) {
+	if err == nil {
+		ctx.WriteString(strings.Join(records, ","))
+		return
+	}
+
+	if context.ErrRequestBodyTooLarge.Equal(err) {
+		ctx.StatusCode(iris.StatusRequestEntityTooLarge)
+		ctx.WriteString(strings.Join(records, ",") + "|too large")
+		return
+	}
+	ctx.StatusCode(iris.StatusBadRequest)
+	ctx.WriteString(strings.Join(records, ",") + "|" + err.Error())
+}
+
+func TestReadNDJSON(t *testing.T) {
+	app := iris.New()
+	app.Post("/", func(ctx iris.Context) {
+		if limit, _ := ctx.URLParamInt64("limit"); limit > 0 {
+			ctx.SetMaxRequestBodySize(limit)
+		}
+
+		var records []string
+		err := ctx.ReadNDJSON(func() interface{} { return new(ndjsonProduct) }, func(record interface{}) error {
+			p := record.(*ndjsonProduct)
+			if p.Name == "stop" {
+				return errors.New("stopped")
+			}
+			records = append(records, p.Name)
+			return nil
+		})
+		writeBodyErr(ctx, records, err)
+	})
+
+	e := httptest.New(t, app)
+
+	body := "{\"name\":\"a\",\"price\":1}\n{\"name\":\"b\",\"price\":2}\n"
+	e.POST("/").WithBytes([]byte(body)).Expect().Status(httptest.StatusOK).Body().Equal("a,b")
+	e.POST("/").Expect().Status(httptest.StatusOK).Body().Empty()
+	// the "each" error stops the reading.
+	e.POST("/").WithBytes([]byte(`{"name":"a"}{"name":"stop"}{"name":"c"}`)).Expect().
+		Status(httptest.StatusBadRequest).Body().Equal("a|stopped")
+	e.POST("/").WithBytes([]byte(`{"name":"a"}` + "\n{")).Expect().Status(httptest.StatusBadRequest).Body().Equal("a|unexpected EOF")
+
+	// the limit.
+	limit := len(body)
+	e.POST("/").WithQuery("limit", limit).WithBytes([]byte(body)).Expect().Status(httptest.StatusOK).Body().Equal("a,b")
+	e.POST("/").WithQuery("limit", limit-10).WithBytes([]byte(body)).Expect().
+		Status(httptest.StatusRequestEntityTooLarge).Body().Equal("a|too large")
+	// a syntax error of a body of exactly the limit's size is not reported as a too large one.
+	invalid := []byte(`{"name":"a"}` + "\n{\"name\":")
+	e.POST("/").WithQuery("limit", len(invalid)).WithBytes(invalid).Expect().
+		Status(httptest.StatusBadRequest).Body().Equal("a|unexpected EOF")
+}
+
+func TestReadNDJSONValidation(t *testing.T) {
+	app := iris.New()
+	app.Validator(new(testValidator))
+	app.Post("/", func(ctx iris.Context) {
+		var records []string
+		err := ctx.ReadNDJSON(func() interface{} { return new(validatedUser) }, func(record interface{}) error {
+			records = append(records, record.(*validatedUser).Username)
+			return nil
+		})
+		if _, ok := err.(context.ValidationErrors); ok {
+			err = errors.New("invalid")
+		}
+		writeBodyErr(ctx, records, err)
+	})
+
+	httptest.New(t, app).POST("/").WithBytes([]byte(`{"username":"a","age":18}` + "\n" + `{"username":"b","age":1}` + "\n" + `{"username":"c","age":18}`)).
+		Expect().Status(httptest.StatusBadRequest).Body().Equal("a|invalid")
+}
+
+func TestReadCSV(t *testing.T) {
+	app := iris.New()
+	read := func(opts ...context.CSVOptions) iris.Handler {
+		return func(ctx iris.Context) {
+			if limit, _ := ctx.URLParamInt64("limit"); limit > 0 {
+				ctx.SetMaxRequestBodySize(limit)
+			}
+
+			var records []string
+			err := ctx.ReadCSV(func(record []string) error {
+				if record[0] == "stop" {
+					return errors.New("stopped")
+				}
+				records = append(records, strings.Join(record, "+"))
+				return nil
+			}, opts...)
+			writeBodyErr(ctx, records, err)
+		}
+	}
+	app.Post("/", read())
+	app.Post("/options", read(context.CSVOptions{Comma: ';', Comment: '#', TrimLeadingSpace: true, SkipHeader: true}))
+	app.Post("/any", read(context.CSVOptions{FieldsPerRecord: -1}))
+
+	e := httptest.New(t, app)
+
+	body := "a,1\nb,2\n"
+	e.POST("/").WithBytes([]byte(body)).Expect().Status(httptest.StatusOK).Body().Equal("a+1,b+2")
+	e.POST("/").Expect().Status(httptest.StatusOK).Body().Empty()
+	e.POST("/").WithBytes([]byte("a,1\nstop,2\nc,3\n")).Expect().Status(httptest.StatusBadRequest).Body().Equal("a+1|stopped")
+	// the number of the fields of the first record.
+	e.POST("/").WithBytes([]byte("a,1\nb\n")).Expect().Status(httptest.StatusBadRequest).Body().Contains("wrong number of fields")
+	e.POST("/any").WithBytes([]byte("a,1\nb\n")).Expect().Status(httptest.StatusOK).Body().Equal("a+1,b")
+
+	e.POST("/options").WithBytes([]byte("name;price\n# a comment\na; 1\nb;2\n")).Expect().
+		Status(httptest.StatusOK).Body().Equal("a+1,b+2")
+
+	// the limit.
+	e.POST("/").WithQuery("limit", len(body)).WithBytes([]byte(body)).Expect().Status(httptest.StatusOK).Body().Equal("a+1,b+2")
+	e.POST("/").WithQuery("limit", len(body)-3).WithBytes([]byte(body)).Expect().
+		Status(httptest.StatusRequestEntityTooLarge).Body().Equal("a+1|too large")
+	invalid := []byte("a,1\n\"b,2")
+	e.POST("/").WithQuery("limit", len(invalid)).WithBytes(invalid).Expect().
+		Status(httptest.StatusBadRequest).Body().Contains("extraneous or missing \" in quoted-field")
+}
@@ -0,0 +1,59 @@
+package context
+
+import (
+	"strings"
+)
+
+const (
+	// SurrogateKeyHeaderKey is the header key of "Surrogate-Key", the space separated cache tags of a response.
+	SurrogateKeyHeaderKey = "Surrogate-Key"
+	// CacheTagsHeaderKey is the header key of "Cache-Tags", the comma separated cache tags of a response.
+	CacheTagsHeaderKey = "Cache-Tags"
+)
+
+// AddCacheTag adds the "tags" to the response's "Surrogate-Key" and "Cache-Tags" headers,
+// so a CDN or the server-side cache (see `cache#Cache`) can invalidate the cached response by one of its tags,
+// i.e `ctx.AddCacheTag("users", "user:42")` and later purge the "user:42" when the user is modified.
+// The tags can't contain spaces or commas, the invalid and the duplicated tags are ignored.
+// 给响应添加缓存标签, 之后可以通过标签来清除缓存
+func (ctx *context) AddCacheTag(tags ...string) {
+	existing := ctx.CacheTags()
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || strings.ContainsAny(tag, " ,") || containsString(existing, tag) {
+			continue
+		}
+		existing = append(existing, tag)
+	}
+
+	if len(existing) == 0 {
+		return
+	}
+
+	h := ctx.writer.Header()
+	h.Set(SurrogateKeyHeaderKey, strings.Join(existing, " "))
+	h.Set(CacheTagsHeaderKey, strings.Join(existing, ","))
+}
+
+// CacheTags returns the cache tags of the response, see `AddCacheTag`.
+func (ctx *context) CacheTags() []string {
+	return ParseCacheTags(ctx.writer.Header().Get(SurrogateKeyHeaderKey))
+}
+
+// ParseCacheTags returns the tags of a "Surrogate-Key" (space separated)
+// or a "Cache-Tags" (comma separated) header's value.
+func ParseCacheTags(headerValue string) []string {
+	return strings.FieldsFunc(headerValue, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+}
+
+func containsString(slice []string, s string) bool {
+	for _, v := range slice {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
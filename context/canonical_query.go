@@ -0,0 +1,97 @@
+package context
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/kataras/iris/core/memstore"
+)
+
+// DefaultCanonicalQueryExclusions are the URL query parameters
+// that are omitted by the `Context#CanonicalQuery` when no exclusions are given,
+// they're marketing/tracking parameters that do not change the response.
+//
+// An exclusion ending with "*" matches all parameters that start with its prefix.
+var DefaultCanonicalQueryExclusions = []string{"utm_*", "fbclid", "gclid"}
+
+func isQueryParamExcluded(key string, exclusions []string) bool {
+	for _, exclusion := range exclusions {
+		if n := len(exclusion) - 1; n >= 0 && exclusion[n] == '*' {
+			if strings.HasPrefix(key, exclusion[:n]) {
+				return true
+			}
+			continue
+		}
+
+		if key == exclusion {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortedQueryKeys returns the keys of the "query", except the excluded ones, sorted.
+func sortedQueryKeys(query url.Values, exclusions []string) []string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if isQueryParamExcluded(key, exclusions) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// CanonicalQuery returns the encoded "query" with its keys sorted
+// and the "exclusions" omitted, the values of the same key keep their order.
+// The result is deterministic, it can be used to build cache or signature keys.
+//
+// See `Context#CanonicalQuery` too.
+// 返回排序后(并且去掉了exclusions)的query字符串，用于生成缓存的key
+func CanonicalQuery(query url.Values, exclusions ...string) string {
+	var b strings.Builder
+	for _, key := range sortedQueryKeys(query, exclusions) {
+		escapedKey := url.QueryEscape(key)
+		for _, value := range query[key] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(escapedKey)
+			b.WriteByte('=')
+			b.WriteString(url.QueryEscape(value))
+		}
+	}
+
+	return b.String()
+}
+
+// URLParamsSorted returns the URL query parameters sorted by their keys,
+// values of the same key are separated by comma, like the `URLParams`.
+func (ctx *context) URLParamsSorted() []memstore.Entry {
+	query := ctx.request.URL.Query()
+	keys := sortedQueryKeys(query, nil)
+
+	entries := make([]memstore.Entry, len(keys))
+	for i, key := range keys {
+		entries[i] = memstore.Entry{Key: key, ValueRaw: strings.Join(query[key], ",")}
+	}
+
+	return entries
+}
+
+// CanonicalQuery returns the URL query string with its keys sorted
+// and the "exclusions" omitted, if no exclusions are given then
+// the `DefaultCanonicalQueryExclusions` are used instead.
+//
+// Example: "?utm_source=x&b=2&a=1" -> "a=1&b=2".
+func (ctx *context) CanonicalQuery(exclusions ...string) string {
+	if len(exclusions) == 0 {
+		exclusions = DefaultCanonicalQueryExclusions
+	}
+
+	return CanonicalQuery(ctx.request.URL.Query(), exclusions...)
+}
@@ -0,0 +1,115 @@
+package context
+
+import "time"
+
+// Claims is the standard container of the authenticated subject's claims, i.e the parsed JWT claims,
+// it's stored to the Context by the authentication middleware through the `SetClaims`
+// and it's read by the next handlers through the `GetClaims`.
+//
+// It has the same form as the map claims of the common JWT libraries, so they can be converted directly.
+// 认证后的claims(例如JWT)，统一存放在context中
+type Claims map[string]interface{}
+
+// ClaimsContextKey is the context's values key of the request's claims, see `GetClaims`.
+const ClaimsContextKey = "iris.claims"
+
+// Get returns the value of the "key" claim, nil if missing.
+func (c Claims) Get(key string) interface{} {
+	if c == nil {
+		return nil
+	}
+	return c[key]
+}
+
+// GetString returns the value of the "key" claim as string, empty if missing or not a string.
+func (c Claims) GetString(key string) string {
+	v, _ := c.Get(key).(string)
+	return v
+}
+
+// Subject returns the standard "sub" claim, the identifier of the user.
+func (c Claims) Subject() string {
+	return c.GetString("sub")
+}
+
+// Issuer returns the standard "iss" claim.
+func (c Claims) Issuer() string {
+	return c.GetString("iss")
+}
+
+// ID returns the standard "jti" claim.
+func (c Claims) ID() string {
+	return c.GetString("jti")
+}
+
+// Audience returns the standard "aud" claim, which can be a string or a list of strings.
+func (c Claims) Audience() []string {
+	switch v := c.Get("aud").(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		aud := make([]string, 0, len(v))
+		for _, s := range v {
+			if str, ok := s.(string); ok {
+				aud = append(aud, str)
+			}
+		}
+		return aud
+	}
+
+	return nil
+}
+
+func (c Claims) getTime(key string) time.Time {
+	var unix int64
+	switch v := c.Get(key).(type) {
+	case int64:
+		unix = v
+	case int:
+		unix = int64(v)
+	case float64: // as decoded from JSON.
+		unix = int64(v)
+	case time.Time:
+		return v
+	default:
+		return time.Time{}
+	}
+
+	return time.Unix(unix, 0)
+}
+
+// ExpiresAt returns the standard "exp" claim, zero time if missing.
+func (c Claims) ExpiresAt() time.Time {
+	return c.getTime("exp")
+}
+
+// IssuedAt returns the standard "iat" claim, zero time if missing.
+func (c Claims) IssuedAt() time.Time {
+	return c.getTime("iat")
+}
+
+// Expired reports whether the "exp" claim exists and it's before the "now".
+func (c Claims) Expired(now time.Time) bool {
+	exp := c.ExpiresAt()
+	return !exp.IsZero() && now.After(exp)
+}
+
+// GetClaims returns the claims of the authenticated subject of the request,
+// as set by the authentication middleware, nil if not authenticated.
+// Downstream middleware, i.e rate limiting by user or logging,
+// can rely on that instead of a custom values key.
+func GetClaims(ctx Context) Claims {
+	if claims, ok := ctx.Values().Get(ClaimsContextKey).(Claims); ok {
+		return claims
+	}
+
+	return nil
+}
+
+// SetClaims sets the claims of the authenticated subject of the request,
+// it should be called by the authentication middleware, i.e after a JWT verification.
+func SetClaims(ctx Context, claims Claims) {
+	ctx.Values().Set(ClaimsContextKey, claims)
+}
@@ -0,0 +1,71 @@
+package context_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestClaims(t *testing.T) {
+	exp := time.Now().Add(time.Hour)
+
+	app := iris.New()
+	auth := func(ctx iris.Context) {
+		switch ctx.GetHeader("Authorization") {
+		case "valid":
+			context.SetClaims(ctx, context.Claims{
+				"sub": "kataras",
+				"aud": []interface{}{"web", "api"}, // as decoded from JSON.
+				"exp": float64(exp.Unix()),
+			})
+		case "nil":
+			context.SetClaims(ctx, nil)
+		case "wrong":
+			ctx.Values().Set(context.ClaimsContextKey, "not claims")
+		}
+		ctx.Next()
+	}
+	app.Get("/", auth, func(ctx iris.Context) {
+		claims := context.GetClaims(ctx)
+		if claims == nil {
+			ctx.StatusCode(iris.StatusUnauthorized)
+			return
+		}
+
+		ctx.Writef("%s %s %d %v", claims.Subject(), strings.Join(claims.Audience(), ","),
+			claims.ExpiresAt().Unix(), claims.Expired(time.Now()))
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").WithHeader("Authorization", "valid").Expect().Status(iris.StatusOK).
+		Body().Equal(fmt.Sprintf("kataras web,api %d false", exp.Unix()))
+	e.GET("/").Expect().Status(iris.StatusUnauthorized)
+	e.GET("/").WithHeader("Authorization", "nil").Expect().Status(iris.StatusUnauthorized)
+	e.GET("/").WithHeader("Authorization", "wrong").Expect().Status(iris.StatusUnauthorized)
+}
+
+func TestClaimsNil(t *testing.T) {
+	var claims context.Claims
+	if claims.Get("sub") != nil || claims.Subject() != "" || claims.Audience() != nil {
+		t.Fatalf("expected the nil claims to be empty")
+	}
+	if !claims.ExpiresAt().IsZero() || claims.Expired(time.Now()) {
+		t.Fatalf("expected the nil claims to never expire")
+	}
+
+	// a claim of a wrong type.
+	claims = context.Claims{"sub": 42, "aud": 42, "exp": "tomorrow"}
+	if claims.Subject() != "" || claims.Audience() != nil || !claims.ExpiresAt().IsZero() {
+		t.Fatalf("expected the claims of a wrong type to be empty")
+	}
+
+	claims = context.Claims{"exp": time.Now().Add(-time.Minute).Unix()}
+	if !claims.Expired(time.Now()) {
+		t.Fatalf("expected the claims to be expired")
+	}
+}
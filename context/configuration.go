@@ -1,5 +1,11 @@
 package context
 
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
 // ConfigurationReadOnly can be implemented
 // by Configuration, it's being used inside the Context.
 // All methods that it contains should be "safe" to be called by the context
@@ -39,6 +45,15 @@ type ConfigurationReadOnly interface {
 
 	// GetFireMethodNotAllowed returns the configuration.FireMethodNotAllowed.
 	GetFireMethodNotAllowed() bool
+	// GetDetectHeaderMutations returns the configuration.DetectHeaderMutations,
+	// if true then the response headers that are modified after they were sent to the client
+	// or from a goroutine other than the request's one are logged as warnings.
+	// Should be used in development mode only.
+	GetDetectHeaderMutations() bool
+	// GetSlowRequestThreshold returns the configuration.SlowRequestThreshold,
+	// requests that took longer than that are logged as warnings with their handlers timing breakdown,
+	// zero means disabled. Used when the handler timing is enabled only.
+	GetSlowRequestThreshold() time.Duration
 	// GetDisableBodyConsumptionOnUnmarshal returns the configuration.GetDisableBodyConsumptionOnUnmarshal,
 	// manages the reading behavior of the context's body readers/binders.
 	// If returns true then the body consumption by the `context.UnmarshalBody/ReadJSON/ReadXML`
@@ -52,6 +67,10 @@ type ConfigurationReadOnly interface {
 	// 然后用默认的io.ReadAll 从request.Body (io.ReadCloser) 去读取，如果这个参数是true，则胡创建一个缓存来读取请求体
 	// 这个请求体的数据不会被改变，且一直被保存直到 context.UnmarshalBody/ReadJSON/ReadXML 起作用
 	GetDisableBodyConsumptionOnUnmarshal() bool
+	// GetDisableJSONErrorDetails returns the configuration.DisableJSONErrorDetails,
+	// if true then the `context.ReadJSON` returns the errors of the JSON decoder as they're,
+	// otherwise it returns a `*JSONError` with the position and the field of the error.
+	GetDisableJSONErrorDetails() bool
 
 	// GetDisableAutoFireStatusCode returns the configuration.DisableAutoFireStatusCode.
 	// Returns true when the http error status code handler automatic execution turned off.
@@ -102,6 +121,49 @@ type ConfigurationReadOnly interface {
 	//
 	// Look `context.RemoteAddr()` for more.
 	GetRemoteAddrHeaders() map[string]bool
+	// GetTrustedProxies returns the configuration.TrustedProxies,
+	// the CIDR ranges or the IPs of the proxies that the forwarded headers are accepted from.
+	//
+	// Look `context.RemoteAddr()` for more.
+	GetTrustedProxies() []string
+	// GetTrustedProxyNets returns the parsed configuration.TrustedProxies.
+	GetTrustedProxyNets() []*net.IPNet
+
+	// GetSSLProxyHeaders returns the configuration.SSLProxyHeaders,
+	// the request header names and the values which, when sent by a trusted proxy,
+	// declare that the client connects through https, i.e "X-Forwarded-Proto": "https".
+	//
+	// Look `context.IsTLS()` for more.
+	GetSSLProxyHeaders() map[string]string
+	// GetHostProxyHeaders returns the configuration.HostProxyHeaders,
+	// the allowed request header names that can be used to retrieve the host
+	// as seen by the client, i.e "X-Forwarded-Host".
+	//
+	// Look `context.FullRequestURI()` for more.
+	GetHostProxyHeaders() map[string]bool
+	// GetRestrictRelativeRedirects returns the configuration.RestrictRelativeRedirects,
+	// if true then the `context.Redirect` refuses to redirect to foreign hosts.
+	//
+	// Look `context.SafeRedirect()` for more.
+	GetRestrictRelativeRedirects() bool
+	// GetRedirectAllowedHosts returns the configuration.RedirectAllowedHosts,
+	// the foreign hosts that the `context.SafeRedirect` can redirect to.
+	GetRedirectAllowedHosts() []string
+	// GetCookieSecret returns the configuration.CookieSecret,
+	// the secret key of the cookies which are signed by the `context.SetCookieKV`.
+	GetCookieSecret() string
+	// GetCookieEncryption returns the configuration.CookieEncryption,
+	// if true then the signed cookies are encrypted too.
+	GetCookieEncryption() bool
+	// GetCookieSameSite returns the configuration.CookieSameSite,
+	// the default SameSite attribute of the `context.SetCookieKV` and `context.RemoveCookie` cookies.
+	GetCookieSameSite() http.SameSite
+	// GetCookieSecure returns the configuration.CookieSecure,
+	// if true then the `context.SetCookieKV` and `context.RemoveCookie` cookies are secure by default.
+	GetCookieSecure() bool
+	// GetCookieDomain returns the configuration.CookieDomain,
+	// the default Domain attribute of the `context.SetCookieKV` and `context.RemoveCookie` cookies.
+	GetCookieDomain() string
 
 	// GetOther returns the configuration.Other map.
 	GetOther() map[string]interface{}
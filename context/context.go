@@ -1,7 +1,9 @@
 package context
 
 import (
+	"archive/zip"
 	"bytes"
+	"compress/flate"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
@@ -16,6 +18,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -127,6 +130,13 @@ type Context interface {
 	ResponseWriter() ResponseWriter
 	// ResetResponseWriter should change or upgrade the Context's ResponseWriter.
 	ResetResponseWriter(ResponseWriter)
+	// PushTargets initiates an HTTP/2 server push of each of the "targets", i.e "/css/app.css",
+	// it should be called before writing the response body.
+	// A target can be a "Link" header value too, i.e "</css/app.css>; rel=preload; as=style".
+	//
+	// It does nothing when the client does not support or has disabled the server push,
+	// i.e an HTTP/1.x request, the rest errors are returned.
+	PushTargets(targets ...string) error
 
 	// Request returns the original *http.Request, as expected.
 	Request() *http.Request
@@ -325,12 +335,28 @@ type Context interface {
 	// Method returns the request.Method, the client's http method to the server.
 	Method() string
 	// Path returns the full request path,
-	// escaped if EnablePathEscape config field is true.
+	// escaped if EnablePathEscape config field is true
+	// or if the current Party overrides it, see `SetPathEscape`.
 	Path() string
 	// RequestPath returns the full request path,
 	// based on the 'escape'.
 	// 这个根据传递的参数来觉得是否
 	RequestPath(escape bool) string
+	// SetPathEscape overrides the EnablePathEscape config field
+	// for the `Path` calls of the rest of the handlers of this request.
+	// It's used by the `Party#PathEscape` but it can be called manually too.
+	//
+	// Note that the router has already matched the route based on the global setting.
+	SetPathEscape(escape bool)
+	// RawPath returns the original, encoded, request path as sent by the client,
+	// encoded slashes ("%2F") and any other percent-encoded characters are kept as they are.
+	// Useful for proxy-like routes that must forward the original URI exactly.
+	// 原始的没有经过解码的路径
+	RawPath() string
+	// UnescapedPath returns the fully decoded request path,
+	// encoded slashes ("%2F") are decoded to "/" too,
+	// so it cannot be used to split the path to segments safely, see `RawPath` for that.
+	UnescapedPath() string
 
 	// Host returns the host part of the current url.
 	Host() string
@@ -427,6 +453,15 @@ type Context interface {
 	// 表示重定向的方式，前一个表达了重定向的地址，后一个表达状态码，虽然后面是变长参数，但是实现中只是用了第一个，
 	// 内在调用的是原生 server.go 中 http.Redirect
 	Redirect(urlToRedirect string, statusHeader ...int)
+	// SafeRedirect same as `Redirect` but it refuses to redirect to absolute urls of foreign hosts,
+	// except the `Configuration#RedirectAllowedHosts`, useful for the login/return-to flows
+	// where the url is given by the client.
+	//
+	// If the url is not allowed then it redirects to the root path "/" instead.
+	SafeRedirect(urlToRedirect string, statusHeader ...int)
+	// IsSafeRedirect reports whether the "urlToRedirect" is a relative url
+	// or an absolute one of the current host or of one of the `Configuration#RedirectAllowedHosts`.
+	IsSafeRedirect(urlToRedirect string) bool
 
 	//  +------------------------------------------------------------+
 	//  | Various Request and Post Data                              |
@@ -476,6 +511,18 @@ type Context interface {
 	// it returns an empty map if nothing found.
 	// 就是将 url.go 中 Values (type Values map[string][]string）转为对应的格式
 	URLParams() map[string]string
+	// URLParamsSorted returns the URL query parameters sorted by their keys,
+	// values of the same key are separated by comma, like the `URLParams`.
+	URLParamsSorted() []memstore.Entry
+	// CanonicalQuery returns the URL query string with its keys sorted
+	// and the "exclusions" omitted, if no exclusions are given then
+	// the `DefaultCanonicalQueryExclusions` ("utm_*", "fbclid", "gclid") are used instead.
+	// An exclusion ending with "*" matches all parameters that start with its prefix.
+	//
+	// Useful to build deterministic cache or signature keys,
+	// it's used by the cache middleware as well.
+	// Example: "?utm_source=x&b=2&a=1" -> "a=1&b=2".
+	CanonicalQuery(exclusions ...string) string
 
 	// FormValueDefault returns a single parsed form value by its "name",
 	// including both the URL field's query parameters and the POST or PUT form data.
@@ -558,6 +605,52 @@ type Context interface {
 	// Example: https://github.com/kataras/iris/tree/master/_examples/http_request/upload-file
 	// 根据 key 获取第一个客户端上传的文件,通过原生的 http.Request{}.FormFile()
 	FormFile(key string) (multipart.File, *multipart.FileHeader, error)
+	// FormFileBytes reads the whole first uploaded file that received from the client
+	// and returns its contents, its file header and its MIME type,
+	// which is detected from the contents(content sniffing), not from the client's Content-Type.
+	// Useful to forward uploads to an object storage without touching the disk.
+	//
+	// The file is streamed from the request body, see `NextPart`, the form is not parsed,
+	// so the body cannot be used along with the `FormValue` and `FormFile` after that,
+	// unless the form was already parsed before.
+	//
+	// The optional "maxSize" argument is the maximum allowed size of the file in bytes,
+	// it defaults to the `iris#WithPostMaxMemory` setting.
+	// If the file is larger than that it returns an `ErrFormFileTooLarge`, at most "maxSize"+1 bytes are read.
+	// 把上传的文件读到内存中，并且根据文件内容判断MIME类型
+	FormFileBytes(key string, maxSize ...int64) ([]byte, *multipart.FileHeader, string, error)
+	// NextPart returns the next part of a "multipart/form-data" or "multipart/mixed" request body,
+	// in order of arrival, so a handler can process the fields and the files as they're received,
+	// i.e validate a metadata field before accepting a large file, without buffering the whole form.
+	// The part's `FileName()` is empty for the non-file fields.
+	//
+	// It returns `io.EOF` when there are no more parts
+	// or `http.ErrNotMultipart` if the request body is not multipart.
+	//
+	// The request body is consumed, so it cannot be used
+	// along with the `FormValue`, `FormFile` and `UploadFormFiles` on the same request.
+	// 按顺序流式读取multipart的每一部分(字段或者文件)
+	NextPart() (*multipart.Part, error)
+	// MultipartReader returns the streaming reader of a "multipart/form-data" or "multipart/mixed" request body,
+	// the same reader is used by the `NextPart` and `StreamFormFiles` on the same request.
+	//
+	// It returns `http.ErrNotMultipart` if the request body is not multipart.
+	// 返回流式的multipart读取器，不会像ParseMultipartForm一样缓存到内存或者临时文件
+	MultipartReader() (*multipart.Reader, error)
+	// StreamFormFiles is the streaming variant of the `UploadFormFiles`,
+	// it calls the "onFile" for each one of the received file(s) as they arrive,
+	// the "r" reads the file's contents directly from the request body,
+	// nothing is buffered in memory or spilled to temporary files,
+	// so it's the way to go for very large uploads.
+	// The non-file fields are skipped, use the `NextPart` for a more controlled read.
+	//
+	// Returns the length read by the "onFile" callbacks as int64 and
+	// the first error, if any, or http.ErrMissingFile if no file received.
+	//
+	// The request body is consumed, so it cannot be used
+	// along with the `FormValue`, `FormFile` and `UploadFormFiles` on the same request.
+	// 流式处理上传的文件，适用于大文件(GB级别)的上传
+	StreamFormFiles(onFile func(ctx Context, fieldName, fileName string, r io.Reader) error) (n int64, err error)
 	// UploadFormFiles uploads any received file(s) from the client
 	// to the system physical location "destDirectory".
 	// 这是将客户端上传的图片 保存到磁盘中
@@ -567,6 +660,7 @@ type Context interface {
 	// it can be used to change a file's name based on the current request,
 	// all FileHeader's options can be changed. You can ignore it if
 	// you don't need to use this capability before saving a file to the disk.
+	// A file is skipped if a "before" callback clears its Filename.
 	// 参数 before 是用来将文件上传到指定磁盘时候，可以让其多一步操作
 	//
 	// Note that it doesn't check if request body streamed.
@@ -590,6 +684,20 @@ type Context interface {
 	//
 	// Example: https://github.com/kataras/iris/tree/master/_examples/http_request/upload-files
 	UploadFormFiles(destDirectory string, before ...func(Context, *multipart.FileHeader)) (n int64, err error)
+	// UploadFormFilesToSink same as `UploadFormFiles` but it writes the received file(s)
+	// to an `UploadSink` instead, i.e an in-memory sink (`NewMemorySink`),
+	// the local disk (`DirSink`) or a cloud storage adapter.
+	//
+	// The files are uploaded in parallel, the optional "progress" callback (can be nil)
+	// receives the aggregate written bytes of all files and their total size,
+	// calls of it are serialized.
+	//
+	// A "before" callback can reject a file by clearing its `Filename`, the file is skipped then.
+	//
+	// Returns the copied length as int64 and the first error, if any,
+	// or http.ErrMissingFile if no file received.
+	// 与UploadFormFiles类似，只是上传的目的地是UploadSink
+	UploadFormFilesToSink(sink UploadSink, progress func(written, total int64), before ...func(Context, *multipart.FileHeader)) (n int64, err error)
 
 	//  +------------------------------------------------------------+
 	//  | Custom HTTP Errors                                         |
@@ -631,19 +739,51 @@ type Context interface {
 	// Example: https://github.com/kataras/iris/blob/master/_examples/http_request/read-json/main.go
 	// 内部实现直接使用了json.Unmarshaler，如果有优化则jsonitor.Unmashaler
 	// 本质都是通过UnmarshalBody的方法，不过第二参数有修改
+	//
+	// If a `Validator` is registered then the struct values are validated too,
+	// the validation failures are returned as `ValidationErrors`.
+	// The decoding failures are returned as a `*JSONError` with the position and the field of the error,
+	// its `Problem` can be sent to the client, i.e `ctx.Problem(jsonErr.Problem())`.
 	ReadJSON(jsonObjectPtr interface{}) error
 	// ReadXML reads XML from request's body and binds it to a pointer of a value of any xml-valid type.
 	//
 	// Example: https://github.com/kataras/iris/blob/master/_examples/http_request/read-xml/main.go
+	//
+	// If a `Validator` is registered then the struct values are validated too.
 	ReadXML(xmlObjectPtr interface{}) error
 	// ReadForm binds the formObject  with the form data
 	// it supports any kind of type, including custom structs.
 	// It will return nothing if request data are empty.
+	// If a `Validator` is registered then the struct values are validated too.
+	//
+	// The `*multipart.FileHeader` and `[]*multipart.FileHeader` fields are set from the uploaded files
+	// of a multipart form, with size and MIME validation, see `FormFileTagName`.
 	//
 	// Example: https://github.com/kataras/iris/blob/master/_examples/http_request/read-form/main.go
 	// 这是将form格式转化为对象
 	// todo 本质是通过formbinder.Decode()来实现，阅读formbinder.Decode()
 	ReadForm(formObjectPtr interface{}) error
+	// ReadQuery binds the "ptr" with the url query string, i.e "?since=...&limit=...".
+	// The struct fields are matched by their name or by their `url:"..."` tag,
+	// slices, time.Time and pointer fields are supported as well.
+	// It will return nothing if the request has no query string.
+	// If a `Validator` is registered then the struct values are validated too.
+	//
+	// 和ReadForm一样基于formbinder，只是tag名为"url"，数据来源为URL.Query()
+	ReadQuery(ptr interface{}) error
+	// ReadNDJSON reads the newline delimited JSON (application/x-ndjson) request body record by record,
+	// without buffering the entire body, i.e for bulk-import endpoints.
+	// The "factory" returns a new pointer to decode each record into, which is passed to the "each",
+	// a non-nil error of the "each" stops the reading and it's returned.
+	// The body size limit, see `SetMaxRequestBodySize`, is enforced, an `ErrRequestBodyTooLarge` is returned then.
+	//
+	// If a `Validator` is registered then the struct values are validated too, before the "each".
+	ReadNDJSON(factory func() interface{}, each func(record interface{}) error) error
+	// ReadCSV reads the CSV (text/csv) request body record by record, without buffering the entire body,
+	// a non-nil error of the "each" stops the reading and it's returned.
+	// The "opts" can customize the separator, the comments and the header row, see `CSVOptions`.
+	// The body size limit, see `SetMaxRequestBodySize`, is enforced, an `ErrRequestBodyTooLarge` is returned then.
+	ReadCSV(each func(record []string) error, opts ...CSVOptions) error
 
 	//  +------------------------------------------------------------+
 	//  | Body (raw) Writers                                         |
@@ -728,6 +868,14 @@ type Context interface {
 	// which is refreshed every package-level `StaticCacheDuration` field.
 	// 与Write类似，不过多了时间用来修改响应流头协议 Last-Modified
 	WriteWithExpiration(body []byte, modtime time.Time) (int, error)
+	// CheckIfNoneMatch reports whether the "If-None-Match" request header
+	// matches the "etag", with the weak comparison (RFC 7232 section 3.2), "*" matches any.
+	CheckIfNoneMatch(etag string) bool
+	// WriteWithEtag like Write but it sends the "ETag" header of the "body", see `ComputeETag`,
+	// or the existing "ETag" response header if it's already set,
+	// and it sends a 304 "Not Modified" instead when the client's "If-None-Match" matches it.
+	// 根据响应体生成ETag，如果与客户端的If-None-Match匹配则返回304
+	WriteWithEtag(body []byte) (int, error)
 	// StreamWriter registers the given stream writer for populating
 	// response body.
 	//
@@ -752,8 +900,57 @@ type Context interface {
 	//  | Body Writers with compression                              |
 	//  +------------------------------------------------------------+
 	// ClientSupportsGzip retruns true if the client supports gzip compression.
+	// It honors the q-values of the "Accept-Encoding", i.e "gzip;q=0" means that gzip is not acceptable.
 	// 判断iris是否支持Gzip压缩
 	ClientSupportsGzip() bool
+	// NegotiateEncoding returns the most preferable, by the client, content coding
+	// of the "offers" based on the "Accept-Encoding" request header and its q-values
+	// or an empty string if none of them is acceptable, see the package-level `NegotiateEncoding`.
+	//
+	// Usage: switch ctx.NegotiateEncoding("br", "gzip", "identity") {...}
+	NegotiateEncoding(offers ...string) string
+	// SetMaxResponseBodySize sets a limit to the (uncompressed) response body size of the current request,
+	// protecting against runaway handlers. It's enforced inside the response writers' Write,
+	// across the gzip and recorder wrappers. The bytes written after the limit is exceeded are dropped
+	// (the Write returns the `ErrResponseBodyTooLarge`), the error is logged and the execution of the next handlers stops.
+	// If nothing is sent to the client yet then the buffered body is discarded and the status code is set to 500.
+	//
+	// See `LimitResponseBodySize` to set it per route or per party.
+	// 限制响应体的大小
+	SetMaxResponseBodySize(limit int64)
+	// TrackBandwidth registers a listener which receives the number of the request body bytes read
+	// and of the response body bytes sent to the client, as they happen, until the end of the request.
+	// The response bytes are the ones that are actually sent, i.e after gzip compression,
+	// the headers are not counted.
+	//
+	// It's the base of the bandwidth accounting, see the `middleware/bandwidth` package.
+	// 流量统计(请求体读取的字节数和实际发送给客户端的字节数)
+	TrackBandwidth(listener BandwidthFunc)
+	// Problem writes the "p" as an RFC 7807 problem details response (see https://tools.ietf.org/html/rfc7807),
+	// the "application/problem+xml" is used if the client prefers XML, otherwise the "application/problem+json".
+	//
+	// The "status" defaults to the current error status code or 500,
+	// the "title" to its status text and the "type" to "about:blank".
+	//
+	// Usage: ctx.Problem(context.NewProblem().Status(400).Detail("the id is invalid"))
+	// See `ProblemHandler` to render the error status codes as problems too.
+	Problem(p Problem) (int, error)
+	// Negotiate renders the "v" with the most preferable, by the client, content type
+	// of the "offers" based on the "Accept" request header and its q-values,
+	// using the existing JSON, XML, YAML, HTML, Markdown and Text renderers.
+	// The text-based content types (HTML, Markdown, Text) are offered only for string, []byte and fmt.Stringer values.
+	//
+	// If "offers" are missing then the route's ones (see `NegotiationOffers`) are used,
+	// if none then the `DefaultNegotiationOffers`.
+	// The response is gzip-compressed if the "Accept-Encoding" prefers gzip.
+	//
+	// If none of the offers is acceptable then it sets the 406 status code and returns the `ErrNotAcceptable`,
+	// the "Accept-Charset" is disregarded if it does not accept the configured charset.
+	// The "Vary" header lists the "Accept", "Accept-Charset" and "Accept-Encoding".
+	//
+	// Usage: ctx.Negotiate(user) or ctx.Negotiate(user, "application/json", "text/xml")
+	// 内容协商: 根据Accept等请求头自动选择渲染器
+	Negotiate(v interface{}, offers ...string) (int, error)
 	// WriteGzip accepts bytes, which are compressed to gzip format and sent to the client.
 	// returns the number of bytes written and an error ( if the client doesn' supports gzip compression)
 	// You may re-use this function in the same handler
@@ -840,10 +1037,19 @@ type Context interface {
 	// otherwise it will check for previous view data stored by the `ViewData`
 	// even if stored at any previous handler(middleware) for the same request.
 	//
+	// The security context of the request, the `CSPNonce`, the `CSRFToken` and the "X-Request-Id",
+	// is exposed to the map view data as "cspNonce", "csrfToken" and "requestID" respectively,
+	// when a security middleware is registered for the current route.
+	//
 	// Look .ViewData` and .ViewLayout too.
 	//
 	// Examples: https://github.com/kataras/iris/tree/master/_examples/view
 	View(filename string, optionalViewModel ...interface{}) error
+	// ViewPartial renders a shared partial, i.e a header or a component, without a layout,
+	// the partials are registered through the `Application#Partials`,
+	// useful to respond with a fragment of a page.
+	// The view data are resolved like the `View`'s ones.
+	ViewPartial(partialName string, optionalViewModel ...interface{}) error
 
 	// Binary writes out the raw bytes as binary data.
 	Binary(data []byte) (int, error)
@@ -871,11 +1077,11 @@ type Context interface {
 	//
 	// You can define your own "Content-Type" with `context#ContentType`, before this function call.
 	//
-	// This function doesn't support resuming (by range),
-	// use ctx.SendFile or router's `StaticWeb` instead.
+	// It supports the "Range" requests (RFC 7233), single and multiple byte ranges and the "If-Range",
+	// so resumable downloads and video seeking work, the ranges are served without gzip compression.
 	// 自动设置content和headers，是比较低级的方法，可以被.ServeFile()/SendFile()取代
 	// 可以在这个方法前自己定义Conetnt-Type
-	// 这个方法不支持重新设置，可以使用ctx.SendFile 或者是 router's StaticWeb替代
+	// 支持Range请求(断点续传、视频拖动)
 	// todo io.ReadSeeker 源码阅读？？
 	// ServeContent 是通过 io的角度处理
 	ServeContent(content io.ReadSeeker, filename string, modtime time.Time, gzipCompression bool) error
@@ -886,8 +1092,7 @@ type Context interface {
 	//
 	// You can define your own "Content-Type" with `context#ContentType`, before this function call.
 	//
-	// This function doesn't support resuming (by range),
-	// use ctx.SendFile or router's `StaticWeb` instead.
+	// It supports the "Range" requests, see `ServeContent`.
 	//
 	// Use it when you want to serve dynamic files to the client.
 	// 内部实现是通过ServeContent()来实现，这里封装了从File角度处理
@@ -899,6 +1104,38 @@ type Context interface {
 	// 设置加了一个请求头通过"Content-Disposition = attachment;filename= destinationName" 来处理
 	// 然后调用ServeFile
 	SendFile(filename string, destinationName string) error
+	// SendZip packages the "files" into a single zip archive and streams it
+	// to the client for force-download as "destinationName".
+	// The keys of the "files" map are the names of the entries inside the archive,
+	// readers that implement the io.Closer are closed after they were written.
+	//
+	// The optional "compressionLevel" can be any of the `compress/flate` levels,
+	// flate.NoCompression stores the files as they are. Defaults to flate.DefaultCompression.
+	//
+	// The writing is aborted with an `ErrZipAborted` error if the client closes the connection.
+	// 把多个文件打包成一个zip并发送给客户端下载
+	SendZip(destinationName string, files map[string]io.Reader, compressionLevel ...int) error
+	// SendZipFiles is like `SendZip` but the values of the "filenames" map are paths of files in the disk,
+	// i.e {"report.csv": "./exports/2019/report.csv"}, they are opened one by one while the archive is written
+	// and their entries keep their modification time.
+	// A missing file or a directory returns an error before anything is written to the client.
+	// 把磁盘上的多个文件打包成一个zip并发送给客户端下载
+	SendZipFiles(destinationName string, filenames map[string]string, compressionLevel ...int) error
+	// ReverseProxy proxies the current request to the "target", the request's path
+	// is joined to the target's path and the queries are merged.
+	//
+	// The response is written through the context's response writer, so the recorder
+	// and the gzip compression keep working.
+	// If the target fails the status code is set to 502 Bad Gateway,
+	// so the registered error handler is fired, and the error is returned.
+	//
+	// The "options" can rewrite the request and the response headers,
+	// see `ProxyRewriteRequest`, `ProxyRewriteResponse` and `ProxyTransport`.
+	// 反向代理当前请求
+	ReverseProxy(target *url.URL, options ...ProxyOption) error
+	// Forward proxies the current request to the "hostport", i.e "localhost:9090"
+	// or "https://backend:9090", see `ReverseProxy`.
+	Forward(hostport string, options ...ProxyOption) error
 
 	//  +------------------------------------------------------------+
 	//  | Cookies                                                    |
@@ -917,6 +1154,8 @@ type Context interface {
 	// use the `CookieExpires` and `CookiePath` to modify them.
 	// Alternatively: ctx.SetCookie(&http.Cookie{...})
 	//
+	// The value is signed, and encrypted, by the `Configuration#CookieSecret` and `CookieEncryption`, if any.
+	//
 	// If you want to set custom the path:
 	// ctx.SetCookieKV(name, value, iris.CookiePath("/custom/path/cookie/will/be/stored"))
 	//
@@ -932,6 +1171,10 @@ type Context interface {
 	SetCookieKV(name, value string, options ...CookieOption)
 	// GetCookie returns cookie's value by it's name
 	// returns empty string if nothing was found.
+	// When the `Configuration#CookieSecret` is set, the signed cookies of the `SetCookieKV` are verified,
+	// a modified one is not found, the rest of them, i.e the ones of the `SetCookie`, are returned as they're.
+	// Use the `GetSignedCookie` when the cookie must be a signed one.
+	// The "options", i.e a `CookieDecode`, receive the verified value.
 	//
 	// If you want more than the value then:
 	// cookie, err := ctx.Request().Cookie("name")
@@ -939,6 +1182,10 @@ type Context interface {
 	// Example: https://github.com/kataras/iris/tree/master/_examples/cookies/basic
 	// 根据指定的name来查询Cookie
 	GetCookie(name string, options ...CookieOption) string
+	// GetSignedCookie returns the value of the "name" cookie only if it's signed, and not modified,
+	// by the `SetCookieKV` and the `Configuration#CookieSecret`, otherwise an empty string,
+	// i.e an unsigned cookie which is set by the client.
+	GetSignedCookie(name string, options ...CookieOption) string
 	// RemoveCookie deletes a cookie by it's name and path = "/".
 	// Tip: change the cookie's path to the current one by: RemoveCookie("name", iris.CookieCleanPath)
 	//
@@ -956,6 +1203,14 @@ type Context interface {
 	// if header not found or parse failed then it returns -1.
 	// 如果请求头有 Cache-Control ，才返回int64 结构的生存时间，如果没有则返回-1
 	MaxAge() int64
+	// AddCacheTag adds the "tags" to the response's "Surrogate-Key" and "Cache-Tags" headers,
+	// the cached responses can be invalidated by their tags, see `cache/client#Handler.PurgeTags`.
+	//
+	// Usage:
+	// ctx.AddCacheTag("users", "user:42")
+	AddCacheTag(tags ...string)
+	// CacheTags returns the cache tags of the response, see `AddCacheTag`.
+	CacheTags() []string
 
 	//  +------------------------------------------------------------+
 	//  | Advanced: Response Recorder and Transactions               |
@@ -976,6 +1231,20 @@ type Context interface {
 	// else returns nil and false.
 	// 就是断言类型 ResponseRecorder
 	IsRecording() (*ResponseRecorder, bool)
+	// Upgrade upgrades the connection of this request through the "upgrader",
+	// i.e a websocket server. The upgraded connection keeps a reference to this Context,
+	// so the route's parameters, the values and the session are still available through it.
+	// 把当前请求升级为websocket等连接
+	Upgrade(upgrader Upgrader) (UpgradedConn, error)
+	// Buffered(true) buffers the response, the writer is switched to a `ResponseRecorder`
+	// and the status code, the headers and the body are sent to the client at the end of the request,
+	// so the status code and the headers can be set even after the body's writes, i.e by a template.
+	// Buffered(false) sends the response buffered so far, if any, and stops buffering.
+	//
+	// Note that it's a no-op if the response is already sent or the writer is not the default one,
+	// i.e when gzip is enabled. Use the `Party#Buffered` to enable it for a group of routes.
+	// 缓存响应，直到请求结束才发送，这样可以在写入body后再设置状态码和header
+	Buffered(enable bool)
 
 	// todo BeginTransaction 想了解可以看一下？？？
 	// BeginTransaction starts a scoped transaction.
@@ -1017,10 +1286,12 @@ type Context interface {
 	//
 	// User can get the response by simple using rec := ctx.Recorder(); rec.Body()/rec.StatusCode()/rec.Header().
 	//
-	// Context's Values and the Session are kept in order to be able to communicate via the result route.
+	// Context's Values and the Session are kept in order to be able to communicate via the result route,
+	// unless an "options" is passed, then the Values are isolated, only its `ExecOptions.CopyKeys`
+	// are visible to the executed route and only its `ExecOptions.ReturnKeys` flow back, see `ExecOptions`.
 	//
 	// It's for extreme use cases, 99% of the times will never be useful for you.
-	Exec(method, path string)
+	Exec(method, path string, options ...ExecOptions)
 
 	// RouteExists reports whether a particular route exists
 	// It will search from the current subdomain of context's host, if not inside the root domain.
@@ -1060,7 +1331,7 @@ func Do(ctx Context, handlers Handlers) {
 	if len(handlers) > 0 {
 		//给当前的context绑定请求路径的路由的Handler
 		ctx.SetHandlers(handlers)
-		handlers[0](ctx)
+		ExecuteHandler(ctx, handlers[0])
 	}
 }
 
@@ -1128,6 +1399,9 @@ type context struct {
 	// 问题:这里啥时候变更呢？？
 	// 通过context.Next()来进行变更，而且表示包含这个索引以及之前的handler都已经调用过了
 	currentHandlerIndex int
+
+	// the request body size limit, see `SetMaxRequestBodySize`, zero for no limit.
+	maxRequestBodySize int64
 }
 
 // NewContext returns the default, internal, context implementation.
@@ -1156,6 +1430,12 @@ func (ctx *context) BeginRequest(w http.ResponseWriter, r *http.Request) {
 	ctx.params.Store = ctx.params.Store[0:0]
 	ctx.request = r
 	ctx.currentHandlerIndex = 0
+	ctx.currentRouteName = "" // the unmatched requests should not see the route of a previous request.
+	ctx.maxRequestBodySize = 0
+	if ctx.app.ConfigurationReadOnly().GetDetectHeaderMutations() {
+		// development mode only, see header_guard.go.
+		w = newHeaderGuard(ctx, w)
+	}
 	// 这里的writer内在是response_writer.go中的responseWriter struct
 	ctx.writer = AcquireResponseWriter()
 	// 这里就是初始化了responseWriter的初始数据
@@ -1208,12 +1488,28 @@ func (ctx *context) EndRequest() {
 			// we do now.
 			// 这里是通过APIBuilder实现了FireErrorCode()，即根据当前的context.ResponseWriter接口里的实现类responseWriter
 			// 得到的状态码返回错误信息
+			// the streaming goroutines, if any, stop before the error response is written.
+			cancelExecution(ctx)
 			ctx.Application().FireErrorCode(ctx)
 		}
 	}
 
 	ctx.writer.FlushResponse()
+	g := headerGuardOf(ctx)
+	if g != nil {
+		g.end()
+	}
+	if listeners, ok := ctx.values.Get(endListenersContextKey).([]func()); ok {
+		for _, cb := range listeners {
+			cb()
+		}
+	}
 	ctx.writer.EndResponse()
+	if g != nil {
+		g.release()
+	}
+	cancelStdContext(ctx)
+	cancelExecution(ctx)
 }
 
 // ResponseWriter returns an http.ResponseWriter compatible response writer, as expected.
@@ -1231,6 +1527,33 @@ func (ctx *context) Request() *http.Request {
 	return ctx.request
 }
 
+// ResetRequest sets the context's Request,
+// i.e to carry a new standard context through the `Request#WithContext`, like the `Tracer`'s span.
+// The `StdContext` of the request, if it's called before, is not changed.
+//
+// It's not part of the `Context` interface, see the package-level `ResetRequest`.
+func (ctx *context) ResetRequest(r *http.Request) {
+	ctx.request = r
+}
+
+// RequestResetter is the optional interface of a `Context` which can change its Request,
+// the default context implements it, see `ResetRequest`.
+type RequestResetter interface {
+	ResetRequest(r *http.Request)
+}
+
+// ResetRequest sets the Request of the "ctx" if it's a `RequestResetter`,
+// i.e to carry a new standard context through the `Request#WithContext`, like the `Tracer`'s span.
+// It reports whether the request is changed.
+func ResetRequest(ctx Context, r *http.Request) bool {
+	if c, ok := ctx.(RequestResetter); ok {
+		c.ResetRequest(r)
+		return true
+	}
+
+	return false
+}
+
 // SetCurrentRouteName sets the route's name internally,
 // in order to be able to find the correct current "read-only" Route when
 // end-developer calls the `GetCurrentRoute()` function.
@@ -1347,6 +1670,12 @@ func (ctx *context) Proceed(h Handler) bool {
 
 // HandlerName returns the current handler's name, helpful for debugging.
 func (ctx *context) HandlerName() string {
+	// if the handlers are wrapped by the `TimeHandler`
+	// then report the original handler's name instead.
+	if t := getHandlersTiming(ctx); t != nil && t.current != "" {
+		return t.current
+	}
+
 	return HandlerName(ctx.handlers[ctx.currentHandlerIndex])
 }
 
@@ -1374,7 +1703,7 @@ func DefaultNext(ctx Context) {
 	}
 	if n, handlers := ctx.HandlerIndex(-1)+1, ctx.Handlers(); n < len(handlers) {
 		ctx.HandlerIndex(n)
-		handlers[n](ctx)
+		ExecuteHandler(ctx, handlers[n])
 	}
 }
 
@@ -1459,6 +1788,7 @@ const stopExecutionIndex = -1 // I don't set to a max value because we want to b
 // as a result the next handlers in the chain will not be fire.
 func (ctx *context) StopExecution() {
 	ctx.currentHandlerIndex = stopExecutionIndex
+	cancelExecution(ctx)
 }
 
 // IsStopped checks and returns true if the current position of the context is -1,
@@ -1514,6 +1844,22 @@ func (ctx *context) OnConnectionClose(cb func()) bool {
 	return true
 }
 
+// endListenersContextKey is the context's values key of the request's end callbacks, see `OnEnd`.
+const endListenersContextKey = "iris.end.listeners"
+
+// OnEnd registers a callback which is fired at the end of the request, after the response is flushed to the client,
+// so the final status code and the sent body, i.e of a recorder or of a fired error code, can be observed.
+// Unlike the `Context#OnClose`, more than one callbacks can be registered, they're fired by registration order.
+func OnEnd(ctx Context, cb func()) {
+	if cb == nil {
+		return
+	}
+
+	values := ctx.Values()
+	listeners, _ := values.Get(endListenersContextKey).([]func())
+	values.Set(endListenersContextKey, append(listeners, cb))
+}
+
 // OnClose registers the callback function "cb" to the underline connection closing event using the `Context#OnConnectionClose`
 // and also in the end of the request handler using the `ResponseWriter#SetBeforeFlush`.
 // Note that you can register only one callback for the entire request handler chain/per route.
@@ -1592,9 +1938,51 @@ func (ctx *context) Method() string {
 // Path returns the full request path,
 // escaped if EnablePathEscape config field is true.
 func (ctx *context) Path() string {
+	if v, ok := ctx.values.GetEntry(PathEscapeContextKey); ok {
+		if escape, ok := v.ValueRaw.(bool); ok {
+			return ctx.RequestPath(escape)
+		}
+	}
+
 	return ctx.RequestPath(ctx.Application().ConfigurationReadOnly().GetEnablePathEscape())
 }
 
+// PathEscapeContextKey is the context's values key which
+// overrides the EnablePathEscape configuration field per request, see `SetPathEscape`.
+const PathEscapeContextKey = "iris.pathEscape"
+
+// SetPathEscape overrides the EnablePathEscape config field
+// for the `Path` calls of the rest of the handlers of this request.
+// It's used by the `Party#PathEscape` but it can be called manually too.
+//
+// Note that the router has already matched the route based on the global setting.
+func (ctx *context) SetPathEscape(escape bool) {
+	ctx.values.Set(PathEscapeContextKey, escape)
+}
+
+// RawPath returns the original, encoded, request path as sent by the client,
+// encoded slashes ("%2F") and any other percent-encoded characters are kept as they are.
+// Useful for proxy-like routes that must forward the original URI exactly.
+func (ctx *context) RawPath() string {
+	// the request uri is kept as it's, the URL.RawPath is filled
+	// only when the default encoding of the URL.Path differs.
+	if uri := ctx.request.RequestURI; uri != "" && uri[0] == '/' {
+		if idx := strings.IndexByte(uri, '?'); idx >= 0 {
+			uri = uri[0:idx]
+		}
+		return uri
+	}
+
+	return ctx.request.URL.EscapedPath()
+}
+
+// UnescapedPath returns the fully decoded request path,
+// encoded slashes ("%2F") are decoded to "/" too,
+// so it cannot be used to split the path to segments safely, see `RawPath` for that.
+func (ctx *context) UnescapedPath() string {
+	return ctx.request.URL.Path
+}
+
 // DecodeQuery returns the uri parameter as url (string)
 // useful when you want to pass something to a database and be valid to retrieve it via context.Param
 // use it only for special cases, when the default behavior doesn't suits you.
@@ -1665,6 +2053,100 @@ func GetHost(r *http.Request) string {
 	return h
 }
 
+// IsTLS reports whether the client connects through a secure connection,
+// the request was served over TLS or a trusted proxy sent one of the
+// `Configuration#SSLProxyHeaders`, i.e "X-Forwarded-Proto: https".
+// The headers are ignored if the request does not come from one of the `Configuration#TrustedProxies`,
+// they're never trusted when no one is configured.
+func IsTLS(ctx Context) bool {
+	if ctx.Request().TLS != nil || strings.EqualFold(ctx.Request().URL.Scheme, "https") {
+		return true
+	}
+
+	if !IsFromTrustedProxy(ctx) {
+		return false
+	}
+
+	for headerName, headerValue := range ctx.Application().ConfigurationReadOnly().GetSSLProxyHeaders() {
+		if v := ctx.GetHeader(headerName); v != "" && strings.EqualFold(v, headerValue) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Scheme returns the scheme of the request as seen by the client,
+// "https" if `IsTLS` otherwise "http".
+func Scheme(ctx Context) string {
+	if IsTLS(ctx) {
+		return "https"
+	}
+
+	return "http"
+}
+
+// proxyHost returns the host of the request as seen by the client,
+// based on the allowed `Configuration#HostProxyHeaders`.
+func proxyHost(ctx Context) string {
+	if !IsFromTrustedProxy(ctx) {
+		return ctx.Host()
+	}
+
+	for headerName, ok := range ctx.Application().ConfigurationReadOnly().GetHostProxyHeaders() {
+		if !ok {
+			continue
+		}
+
+		if v := ctx.GetHeader(headerName); v != "" {
+			// X-Forwarded-Host may contain a list of hosts, the first one is the client's.
+			if idx := strings.IndexByte(v, ','); idx > 0 {
+				v = v[0:idx]
+			}
+			return strings.TrimSpace(v)
+		}
+	}
+
+	return ctx.Host()
+}
+
+// FullRequestURI returns the absolute request URI, as seen by the client,
+// including the scheme, the host and the query, i.e "https://mydomain.com/path?q=1".
+// The host can be retrieved by one of the `Configuration#HostProxyHeaders`
+// when the server is behind a proxy, i.e "X-Forwarded-Host".
+//
+// Useful for redirects, canonical links and OAuth callbacks.
+func FullRequestURI(ctx Context) string {
+	uri := ctx.Request().URL.RequestURI()
+	return Scheme(ctx) + "://" + proxyHost(ctx) + uri
+}
+
+// ForwardedPrefixHeaderKey is the header key of the path prefix of a proxy, see `ForwardedPrefix`.
+const ForwardedPrefixHeaderKey = "X-Forwarded-Prefix"
+
+// ForwardedPrefix returns the path prefix which a trusted proxy serves the application under,
+// the "X-Forwarded-Prefix" header, i.e "/app", without a trailing slash, empty if none.
+// The header is ignored if the request does not come from one of the `Configuration#TrustedProxies`,
+// it's never trusted when no one is configured, or if it's not a path, i.e "//evil.com".
+func ForwardedPrefix(ctx Context) string {
+	if !IsFromTrustedProxy(ctx) {
+		return ""
+	}
+
+	prefix := strings.TrimSpace(ctx.GetHeader(ForwardedPrefixHeaderKey))
+	// a list of prefixes, the first one is the client's.
+	if idx := strings.IndexByte(prefix, ','); idx != -1 {
+		prefix = strings.TrimSpace(prefix[:idx])
+	}
+
+	prefix = strings.TrimRight(prefix, "/")
+	if prefix == "" || prefix[0] != '/' || strings.HasPrefix(prefix, "//") || strings.ContainsAny(prefix, "\\?#\"<> ") {
+		return ""
+	}
+
+	return prefix
+}
+
 // Subdomain returns the subdomain of this request, if any.
 // Note that this is a fast method which does not cover all cases.
 // todo  这里没有地方调用，不理解这个方法的作用
@@ -1708,43 +2190,54 @@ const xForwardedForHeaderKey = "X-Forwarded-For"
 // RemoteAddr tries to parse and return the real client's request IP.
 //
 // Based on allowed headers names that can be modified from Configuration.RemoteAddrHeaders.
+// The headers are used only if the request comes from one of the `Configuration.TrustedProxies`,
+// they're never trusted when no one is configured.
+// The "X-Forwarded-For" and the "Forwarded" (RFC 7239) headers are lists of hops,
+// they're walked from the right through the trusted hops only, so the client can't spoof its IP.
 //
 // If parse based on these headers fail then it will return the Request's `RemoteAddr` field
 // which is filled by the server before the HTTP handler.
 //
 // Look `Configuration.RemoteAddrHeaders`,
+//      `Configuration.TrustedProxies`,
 //      `Configuration.WithRemoteAddrHeader(...)`,
 //      `Configuration.WithoutRemoteAddrHeader(...)` for more.
 func (ctx *context) RemoteAddr() string {
-	remoteHeaders := ctx.Application().ConfigurationReadOnly().GetRemoteAddrHeaders()
-
-	for headerName, enabled := range remoteHeaders {
-		if enabled {
-			headerValue := ctx.GetHeader(headerName)
-			// exception needed for 'X-Forwarded-For' only , if enabled.
-			if headerName == xForwardedForHeaderKey {
-				idx := strings.IndexByte(headerValue, ',')
-				if idx >= 0 {
-					headerValue = headerValue[0:idx]
-				}
-			}
+	cfg := ctx.Application().ConfigurationReadOnly()
+	trusted := trustedProxies(cfg.GetTrustedProxyNets())
+	peer := peerIP(ctx.request)
+
+	if !trusted.contains(net.ParseIP(peer)) {
+		// the headers are sent by the client or an unknown proxy.
+		return peer
+	}
+
+	for headerName, enabled := range cfg.GetRemoteAddrHeaders() {
+		if !enabled {
+			continue
+		}
 
-			realIP := strings.TrimSpace(headerValue)
-			if realIP != "" {
-				return realIP
+		var realIP string
+		switch http.CanonicalHeaderKey(headerName) {
+		case xForwardedForHeaderKey:
+			// each proxy appends its peer, the header may be sent more than once.
+			var hops []string
+			for _, v := range ctx.request.Header.Values(headerName) {
+				hops = append(hops, strings.Split(v, ",")...)
 			}
+			realIP = forwardedClientIP(hops, trusted)
+		case forwardedHeaderKey:
+			realIP = forwardedClientIP(parseForwardedFor(ctx.request.Header.Values(headerName)), trusted)
+		default:
+			realIP = strings.TrimSpace(ctx.GetHeader(headerName))
 		}
-	}
 
-	addr := strings.TrimSpace(ctx.request.RemoteAddr)
-	if addr != "" {
-		// if addr has port use the net.SplitHostPort otherwise(error occurs) take as it is
-		if ip, _, err := net.SplitHostPort(addr); err == nil {
-			return ip
+		if realIP != "" {
+			return realIP
 		}
 	}
 
-	return addr
+	return peer
 }
 
 // GetHeader returns the request header's value based on its name.
@@ -2317,6 +2810,204 @@ func (ctx *context) FormFile(key string) (multipart.File, *multipart.FileHeader,
 	return ctx.request.FormFile(key)
 }
 
+// ErrFormFileTooLarge may be returned from the `FormFileBytes`
+// when the uploaded file is larger than the allowed size.
+// Can be checked with its `Equal` method, i.e `ErrFormFileTooLarge.Equal(err)`.
+var ErrFormFileTooLarge = errors.New("form file '%s' is too large, exceeds the limit of %d bytes")
+
+// FormFileBytes reads the whole first uploaded file that received from the client
+// and returns its contents, its file header and its MIME type,
+// which is detected from the contents(content sniffing), not from the client's Content-Type.
+// Useful to forward uploads to an object storage without touching the disk.
+//
+// The file is streamed from the request body, see `NextPart`, the form is not parsed,
+// so the body cannot be used along with the `FormValue` and `FormFile` after that,
+// unless the form was already parsed before.
+//
+// The optional "maxSize" argument is the maximum allowed size of the file in bytes,
+// it defaults to the `iris#WithPostMaxMemory` setting.
+// If the file is larger than that it returns an `ErrFormFileTooLarge`, at most "maxSize"+1 bytes are read.
+func (ctx *context) FormFileBytes(key string, maxSize ...int64) ([]byte, *multipart.FileHeader, string, error) {
+	limit := ctx.Application().ConfigurationReadOnly().GetPostMaxMemory()
+	if len(maxSize) > 0 && maxSize[0] > 0 {
+		limit = maxSize[0]
+	}
+
+	var (
+		r  io.Reader
+		fh *multipart.FileHeader
+	)
+
+	if ctx.request.MultipartForm != nil {
+		// the form is already parsed, the body is consumed.
+		file, header, err := ctx.request.FormFile(key)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		defer file.Close()
+
+		if header.Size > limit {
+			return nil, header, "", ErrFormFileTooLarge.Format(header.Filename, limit)
+		}
+		r, fh = file, header
+	} else {
+		mr, err := ctx.MultipartReader()
+		if err != nil {
+			return nil, nil, "", err
+		}
+
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil, nil, "", http.ErrMissingFile
+			}
+			if err != nil {
+				return nil, nil, "", err
+			}
+
+			// the previous parts are discarded by the next call.
+			if part.FormName() == key && part.FileName() != "" {
+				defer part.Close()
+				r, fh = part, &multipart.FileHeader{Filename: part.FileName(), Header: part.Header}
+				break
+			}
+		}
+	}
+
+	// the size is unknown before it's read, read one more byte to be sure.
+	contents, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fh, "", err
+	}
+
+	if int64(len(contents)) > limit {
+		return nil, fh, "", ErrFormFileTooLarge.Format(fh.Filename, limit)
+	}
+	fh.Size = int64(len(contents))
+
+	// http.DetectContentType considers at most the first 512 bytes
+	// and it always returns a valid MIME type, "application/octet-stream" if unknown.
+	return contents, fh, http.DetectContentType(contents), nil
+}
+
+// multipartReaderContextKey is the context's values key of the request body's multipart reader, see `NextPart`.
+const multipartReaderContextKey = "iris.multipartReader"
+
+// NextPart returns the next part of a "multipart/form-data" or "multipart/mixed" request body,
+// in order of arrival, so a handler can process the fields and the files as they're received,
+// i.e validate a metadata field before accepting a large file, without buffering the whole form.
+// The part's `FileName()` is empty for the non-file fields.
+//
+// It returns `io.EOF` when there are no more parts
+// or `http.ErrNotMultipart` if the request body is not multipart.
+//
+// The request body is consumed, so it cannot be used
+// along with the `FormValue`, `FormFile` and `UploadFormFiles` on the same request.
+//
+// Example:
+// for {
+// 	part, err := ctx.NextPart()
+// 	if err == io.EOF {
+// 		break
+// 	}
+// 	if err != nil { ... }
+// 	if part.FileName() == "" { /* read the field's value from the part */ continue }
+// 	io.Copy(dst, part)
+// }
+func (ctx *context) NextPart() (*multipart.Part, error) {
+	mr, err := ctx.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	return mr.NextPart()
+}
+
+// MultipartReader returns the streaming reader of a "multipart/form-data" or "multipart/mixed" request body,
+// the same reader is used by the `NextPart` and `StreamFormFiles` on the same request.
+//
+// It returns `http.ErrNotMultipart` if the request body is not multipart.
+func (ctx *context) MultipartReader() (*multipart.Reader, error) {
+	if mr, ok := ctx.values.Get(multipartReaderContextKey).(*multipart.Reader); ok {
+		return mr, nil
+	}
+
+	mr, err := ctx.request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	ctx.values.Set(multipartReaderContextKey, mr)
+	return mr, nil
+}
+
+// countingReader counts the bytes read from its underline reader, see `StreamFormFiles`.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// StreamFormFiles is the streaming variant of the `UploadFormFiles`,
+// it calls the "onFile" for each one of the received file(s) as they arrive,
+// the "r" reads the file's contents directly from the request body,
+// nothing is buffered in memory or spilled to temporary files,
+// so it's the way to go for very large uploads.
+// The non-file fields are skipped, use the `NextPart` for a more controlled read.
+//
+// Returns the length read by the "onFile" callbacks as int64 and
+// the first error, if any, or http.ErrMissingFile if no file received.
+//
+// The request body is consumed, so it cannot be used
+// along with the `FormValue`, `FormFile` and `UploadFormFiles` on the same request.
+//
+// Example:
+// ctx.StreamFormFiles(func(ctx context.Context, fieldName, fileName string, r io.Reader) error {
+// 	out, err := os.Create(filepath.Join("./uploads", filepath.Base(fileName)))
+// 	if err != nil {
+// 		return err
+// 	}
+// 	defer out.Close()
+// 	_, err = io.Copy(out, r)
+// 	return err
+// })
+func (ctx *context) StreamFormFiles(onFile func(ctx Context, fieldName, fileName string, r io.Reader) error) (n int64, err error) {
+	found := false
+	for {
+		part, err := ctx.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		found = true
+		r := &countingReader{Reader: part}
+		err = onFile(ctx, part.FormName(), part.FileName(), r)
+		n += r.n
+		part.Close()
+		if err != nil {
+			return n, err
+		}
+	}
+
+	if !found {
+		return 0, http.ErrMissingFile
+	}
+
+	return n, nil
+}
+
 // UploadFormFiles uploads any received file(s) from the client
 // to the system physical location "destDirectory".
 // 这是将客户端上传的图片 保存到磁盘中
@@ -2326,6 +3017,7 @@ func (ctx *context) FormFile(key string) (multipart.File, *multipart.FileHeader,
 // it can be used to change a file's name based on the current request,
 // all FileHeader's options can be changed. You can ignore it if
 // you don't need to use this capability before saving a file to the disk.
+// A file is skipped if a "before" callback clears its Filename.
 // 参数 before 是用来将文件上传到指定磁盘时候，可以让其多一步操作
 //
 // Note that it doesn't check if request body streamed.
@@ -2348,6 +3040,28 @@ func (ctx *context) FormFile(key string) (multipart.File, *multipart.FileHeader,
 //
 // Example: https://github.com/kataras/iris/tree/master/_examples/http_request/upload-files
 func (ctx *context) UploadFormFiles(destDirectory string, before ...func(Context, *multipart.FileHeader)) (n int64, err error) {
+	n, err = ctx.UploadFormFilesToSink(DirSink(destDirectory), nil, before...)
+	if err != nil {
+		// 有一个失败就直接为0
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// UploadFormFilesToSink same as `UploadFormFiles` but it writes the received file(s)
+// to an `UploadSink` instead, i.e an in-memory sink (`NewMemorySink`),
+// the local disk (`DirSink`) or a cloud storage adapter.
+//
+// The files are uploaded in parallel, the optional "progress" callback (can be nil)
+// receives the aggregate written bytes of all files and their total size,
+// calls of it are serialized.
+//
+// A "before" callback can reject a file by clearing its `Filename`, the file is skipped then.
+//
+// Returns the copied length as int64 and the first error, if any,
+// or http.ErrMissingFile if no file received.
+func (ctx *context) UploadFormFilesToSink(sink UploadSink, progress func(written, total int64), before ...func(Context, *multipart.FileHeader)) (n int64, err error) {
 	err = ctx.request.ParseMultipartForm(ctx.Application().ConfigurationReadOnly().GetPostMaxMemory())
 	if err != nil {
 		return 0, err
@@ -2356,51 +3070,31 @@ func (ctx *context) UploadFormFiles(destDirectory string, before ...func(Context
 	if ctx.request.MultipartForm != nil {
 		// 下面 MultipartForm.File 的 File 字段的数据类型是 map[string][]*FileHeader
 		if fhs := ctx.request.MultipartForm.File; fhs != nil {
-			for _, files := range fhs {
-				for _, file := range files {
-
+			var files []*multipart.FileHeader
+			for _, fileHeaders := range fhs {
+				for _, file := range fileHeaders {
+					// the "before" callbacks are not safe for concurrent use,
+					// call them before the parallel uploads.
 					for _, b := range before {
 						b(ctx, file)
 					}
-					// 这里才是实际的上传文件的接口
-					// todo FileHeader 结构？？
-					// 内部实际就是通过io.Copy()来进行拷贝
-					n0, err0 := uploadTo(file, destDirectory)
-					// 有一个失败就直接为0
-					if err0 != nil {
-						return 0, err0
+					if file.Filename == "" {
+						// rejected by a "before" callback.
+						continue
 					}
-					n += n0
+					files = append(files, file)
 				}
 			}
-			return n, nil
+
+			if len(files) > 0 {
+				return uploadAll(files, sink, progress)
+			}
 		}
 	}
 
 	return 0, http.ErrMissingFile
 }
 
-// todo 学习原生multipart.FileHeader 源码
-func uploadTo(fh *multipart.FileHeader, destDirectory string) (int64, error) {
-	src, err := fh.Open()
-	if err != nil {
-		return 0, err
-	}
-	// 记得打开文件记得关闭
-	defer src.Close()
-
-	out, err := os.OpenFile(filepath.Join(destDirectory, fh.Filename),
-		os.O_WRONLY|os.O_CREATE, os.FileMode(0666))
-
-	if err != nil {
-		return 0, err
-	}
-	defer out.Close()
-	// 通过io.Copy来复制文件
-	// todo io.Copy() 源码阅读
-	return io.Copy(out, src)
-}
-
 // Redirect sends a redirect response to the client
 // to a specific url or relative path.
 // accepts 2 parameters string and an optional int
@@ -2411,6 +3105,79 @@ func uploadTo(fh *multipart.FileHeader, destDirectory string) (int64, error) {
 // or 303 (StatusSeeOther) if POST method,
 // or StatusTemporaryRedirect(307) if that's nessecery.
 func (ctx *context) Redirect(urlToRedirect string, statusHeader ...int) {
+	if ctx.Application().ConfigurationReadOnly().GetRestrictRelativeRedirects() {
+		ctx.SafeRedirect(urlToRedirect, statusHeader...)
+		return
+	}
+
+	ctx.redirect(urlToRedirect, statusHeader...)
+}
+
+// SafeRedirect same as `Redirect` but it refuses to redirect to absolute urls of foreign hosts,
+// except the `Configuration#RedirectAllowedHosts`, useful for the login/return-to flows
+// where the url is given by the client, i.e `ctx.SafeRedirect(ctx.URLParam("return_to"))`.
+//
+// If the url is not allowed then it redirects to the root path "/" instead.
+//
+// See `IsSafeRedirect` too.
+// 防止开放重定向(open redirect)
+func (ctx *context) SafeRedirect(urlToRedirect string, statusHeader ...int) {
+	if !ctx.IsSafeRedirect(urlToRedirect) {
+		Logger(ctx).Warnf("redirect to '%s' refused, foreign host", urlToRedirect)
+		urlToRedirect = "/"
+	}
+
+	ctx.redirect(urlToRedirect, statusHeader...)
+}
+
+// IsSafeRedirect reports whether the "urlToRedirect" is a relative url
+// or an absolute one of the current host or of one of the `Configuration#RedirectAllowedHosts`.
+func (ctx *context) IsSafeRedirect(urlToRedirect string) bool {
+	urlToRedirect = strings.TrimSpace(urlToRedirect)
+	// browsers treat the back slashes as forward ones,
+	// so "/\\evil.com" and "//evil.com" are protocol-relative urls of a foreign host.
+	if strings.HasPrefix(strings.Replace(urlToRedirect, "\\", "/", -1), "//") {
+		return false
+	}
+
+	u, err := url.Parse(urlToRedirect)
+	if err != nil {
+		return false
+	}
+
+	if u.Scheme == "" && u.Host == "" {
+		return true // relative.
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" { // i.e javascript:
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if current, _, err := net.SplitHostPort(proxyHost(ctx)); err == nil {
+		if host == strings.ToLower(current) {
+			return true
+		}
+	} else if host == strings.ToLower(proxyHost(ctx)) {
+		return true
+	}
+
+	for _, allowed := range ctx.Application().ConfigurationReadOnly().GetRedirectAllowedHosts() {
+		allowed = strings.ToLower(allowed)
+		if host == allowed {
+			return true
+		}
+
+		// "*.mydomain.com" allows "mydomain.com" subdomains.
+		if strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ctx *context) redirect(urlToRedirect string, statusHeader ...int) {
 	ctx.StopExecution()
 	// get the previous status code given by the end-developer.
 	status := ctx.GetStatusCode()
@@ -2445,6 +3212,10 @@ func (ctx *context) Redirect(urlToRedirect string, statusHeader ...int) {
 // 通过原生 request.go 中 maxBytesReader 来限制请求体的大小
 func (ctx *context) SetMaxRequestBodySize(limitOverBytes int64) {
 	ctx.request.Body = http.MaxBytesReader(ctx.writer, ctx.request.Body, limitOverBytes)
+	// the limits are nested, the smallest one is enforced.
+	if ctx.maxRequestBodySize == 0 || limitOverBytes < ctx.maxRequestBodySize {
+		ctx.maxRequestBodySize = limitOverBytes
+	}
 }
 
 // UnmarshalBody reads the request's body and binds it to a value or pointer of any type
@@ -2501,6 +3272,7 @@ func (ctx *context) shouldOptimize() bool {
 }
 
 // ReadJSON reads JSON from request's body and binds it to a value of any json-valid type.
+// The decoding errors are returned as a `*JSONError`, see `Configuration.DisableJSONErrorDetails`.
 //
 // Example: https://github.com/kataras/iris/blob/master/_examples/http_request/read-json/main.go
 func (ctx *context) ReadJSON(jsonObject interface{}) error {
@@ -2510,7 +3282,20 @@ func (ctx *context) ReadJSON(jsonObject interface{}) error {
 	if ctx.shouldOptimize() {
 		unmarshaler = jsoniter.Unmarshal
 	}
-	return ctx.UnmarshalBody(jsonObject, UnmarshalerFunc(unmarshaler))
+	if !ctx.Application().ConfigurationReadOnly().GetDisableJSONErrorDetails() {
+		decode := unmarshaler
+		unmarshaler = func(data []byte, v interface{}) error {
+			if err := decode(data, v); err != nil {
+				return newJSONError(err, data, v)
+			}
+			return nil
+		}
+	}
+	if err := ctx.UnmarshalBody(jsonObject, UnmarshalerFunc(unmarshaler)); err != nil {
+		return err
+	}
+
+	return ctx.validate(jsonObject)
 }
 
 // ReadXML reads XML from request's body and binds it to a value of any xml-valid type.
@@ -2518,7 +3303,11 @@ func (ctx *context) ReadJSON(jsonObject interface{}) error {
 // Example: https://github.com/kataras/iris/blob/master/_examples/http_request/read-xml/main.go
 func (ctx *context) ReadXML(xmlObject interface{}) error {
 	// 这里直接使用了原生的 xml.Unmarshal
-	return ctx.UnmarshalBody(xmlObject, UnmarshalerFunc(xml.Unmarshal))
+	if err := ctx.UnmarshalBody(xmlObject, UnmarshalerFunc(xml.Unmarshal)); err != nil {
+		return err
+	}
+
+	return ctx.validate(xmlObject)
 }
 
 // IsErrPath can be used at `context#ReadForm`.
@@ -2532,21 +3321,65 @@ var IsErrPath = formbinder.IsErrPath
 // it supports any kind of type, including custom structs.
 // It will return nothing if request data are empty.
 //
+// The `*multipart.FileHeader` and `[]*multipart.FileHeader` fields are set from the uploaded files
+// of a multipart form, they're validated by their `FormFileTagName` rules first,
+// i.e a `file:"required,max=2MB,mime=image/*"` tag, the failures are returned as `ValidationErrors`.
+//
 // Example: https://github.com/kataras/iris/blob/master/_examples/http_request/read-form/main.go
 // todo 本质是通过formbinder.Decode()来实现，阅读formbinder.Decode()
 func (ctx *context) ReadForm(formObject interface{}) error {
 	// values 的结构是 map[string][]string
 	values := ctx.FormValues()
+
+	hasFiles := hasFormFileFields(formObject)
+	var files map[string][]*multipart.FileHeader
+	if hasFiles && ctx.request.MultipartForm != nil {
+		files = ctx.request.MultipartForm.File
+	}
+
 	// 这里是要判断是否ctx.FormValues里面是否为nil
+	// the file fields are checked even without a form, their "required" rule should be reported.
+	if len(values) == 0 && !hasFiles {
+		return nil
+	}
+
+	if len(values) > 0 {
+		// or dec := formbinder.NewDecoder(&formbinder.DecoderOptions{TagName: "form"})
+		// somewhere at the app level. I did change the tagName to "form"
+		// inside its source code, so it's not needed for now.
+		// todo 本质的form格式转化为对象实际的调用方式，需要看源码？？？？？
+		if err := formbinder.Decode(values, formObject); err != nil {
+			return err
+		}
+	}
+
+	if hasFiles {
+		// the uploaded files of the multipart form, the required ones are reported even if there are no files.
+		if err := bindFormFiles(formObject, files); err != nil {
+			return err
+		}
+	}
+
+	return ctx.validate(formObject)
+}
+
+// ReadQuery binds the "ptr" with the url query string, i.e "?since=...&limit=...".
+// The struct fields are matched by their name or by their `url:"..."` tag,
+// slices, time.Time and pointer fields are supported as well.
+// It will return nothing if the request has no query string.
+func (ctx *context) ReadQuery(ptr interface{}) error {
+	values := ctx.request.URL.Query()
 	if len(values) == 0 {
 		return nil
 	}
 
-	// or dec := formbinder.NewDecoder(&formbinder.DecoderOptions{TagName: "form"})
-	// somewhere at the app level. I did change the tagName to "form"
-	// inside its source code, so it's not needed for now.
-	// todo 本质的form格式转化为对象实际的调用方式，需要看源码？？？？？
-	return formbinder.Decode(values, formObject)
+	// the decoder keeps state while decoding, so a new one is required per call.
+	dec := formbinder.NewDecoder(&formbinder.DecoderOptions{TagName: "url"})
+	if err := dec.Decode(values, ptr); err != nil {
+		return err
+	}
+
+	return ctx.validate(ptr)
 }
 
 //  +------------------------------------------------------------+
@@ -2768,11 +3601,15 @@ func (ctx *context) StreamWriter(writer func(w io.Writer) bool) {
 	w := ctx.writer
 	// todo 问题:这个是什么意思不理解
 	notifyClosed := w.CloseNotify()
+	done := Done(ctx)
 	for {
 		select {
 		// response writer forced to close, exit.
 		case <-notifyClosed:
 			return
+		// the execution is stopped, i.e by a `StopExecution` of another goroutine.
+		case <-done:
+			return
 		default:
 			// 对响应流进行回调，并进行w.Flush()
 			shouldContinue := writer(w)
@@ -2789,17 +3626,20 @@ func (ctx *context) StreamWriter(writer func(w io.Writer) bool) {
 //  +------------------------------------------------------------+
 
 // ClientSupportsGzip retruns true if the client supports gzip compression.
+// It honors the q-values of the "Accept-Encoding", i.e "gzip;q=0" means that gzip is not acceptable.
 // 判断iris是否支持Gzip压缩
 func (ctx *context) ClientSupportsGzip() bool {
-	// 首先判断请求是否有 Accept-Encoding 参数，且有 gzip ，则可以表示压缩
-	if h := ctx.GetHeader(AcceptEncodingHeaderKey); h != "" {
-		for _, v := range strings.Split(h, ";") {
-			if strings.Contains(v, GzipHeaderValue) { // we do Contains because sometimes browsers has the q=, we don't use it atm. || strings.Contains(v,"deflate"){
-				return true
-			}
-		}
-	}
-	return false
+	// 判断请求的 Accept-Encoding 是否接受 gzip (包括 q 值)
+	return AcceptsEncoding(ctx.GetHeader(AcceptEncodingHeaderKey), GzipHeaderValue)
+}
+
+// NegotiateEncoding returns the most preferable, by the client, content coding
+// of the "offers" based on the "Accept-Encoding" request header and its q-values
+// or an empty string if none of them is acceptable, see the package-level `NegotiateEncoding`.
+//
+// Usage: switch ctx.NegotiateEncoding("br", "gzip", "identity") {...}
+func (ctx *context) NegotiateEncoding(offers ...string) string {
+	return NegotiateEncoding(ctx.GetHeader(AcceptEncodingHeaderKey), offers...)
 }
 
 var (
@@ -2985,6 +3825,10 @@ func (ctx *context) GetViewData() map[string]interface{} {
 // otherwise it will check for previous view data stored by the `ViewData`
 // even if stored at any previous handler(middleware) for the same request.
 //
+// The security context of the request, the `CSPNonce`, the `CSRFToken` and the "X-Request-Id",
+// is exposed to the map view data as "cspNonce", "csrfToken" and "requestID" respectively,
+// when a security middleware is registered for the current route.
+//
 // Look .ViewData and .ViewLayout too.
 //
 // Examples: https://github.com/kataras/iris/tree/master/_examples/view
@@ -3002,6 +3846,7 @@ func (ctx *context) View(filename string, optionalViewModel ...interface{}) erro
 	} else {
 		bindingData = ctx.values.Get(cfg.GetViewDataContextKey())
 	}
+	bindingData = ctx.withSecurityViewData(bindingData)
 
 	// 核心的功能在于View()，在 iris.go 中实现
 	// todo iris.go 中 View() 的实现？？好像是viewEngine啥的，想了解就去了解？？
@@ -3014,6 +3859,30 @@ func (ctx *context) View(filename string, optionalViewModel ...interface{}) erro
 	return err
 }
 
+// ViewPartial renders a shared partial, i.e a header or a component, without a layout,
+// the partials are registered through the `Application#Partials`,
+// useful to respond with a fragment of a page.
+// The view data are resolved like the `View`'s ones.
+func (ctx *context) ViewPartial(partialName string, optionalViewModel ...interface{}) error {
+	ctx.ContentType(ContentHTMLHeaderValue)
+
+	var bindingData interface{}
+	if len(optionalViewModel) > 0 {
+		bindingData = optionalViewModel[0]
+	} else {
+		bindingData = ctx.values.Get(ctx.Application().ConfigurationReadOnly().GetViewDataContextKey())
+	}
+	bindingData = ctx.withSecurityViewData(bindingData)
+
+	err := ctx.Application().ViewPartial(ctx.writer, partialName, bindingData)
+	if err != nil {
+		ctx.StatusCode(http.StatusInternalServerError)
+		ctx.StopExecution()
+	}
+
+	return err
+}
+
 const (
 	// ContentBinaryHeaderValue header value for binary data.
 	ContentBinaryHeaderValue = "application/octet-stream"
@@ -3359,10 +4228,12 @@ var (
 // receives three parameters, it's low-level function, instead you can use .ServeFile(string,bool)/SendFile(string,string)
 //
 // You can define your own "Content-Type" header also, after this function call
-// Doesn't implements resuming (by range), use ctx.SendFile instead
+//
+// It supports the "Range" requests (RFC 7233), single and multiple byte ranges and the "If-Range",
+// so resumable downloads and video seeking work, the ranges are served without gzip compression.
 // 自动设置content和headers，是比较低级的方法，可以被.ServeFile()/SendFile()取代
 // 可以在这个方法前自己定义Conetnt-Type
-// 这个方法不支持重新设置，可以使用ctx.SendFile 或者是 router's StaticWeb替代
+// 支持Range请求(断点续传、视频拖动)
 // todo io.ReadSeeker 源码阅读？？
 // ServeContent 是通过 io的角度处理
 func (ctx *context) ServeContent(content io.ReadSeeker, filename string, modtime time.Time, gzipCompression bool) error {
@@ -3374,6 +4245,14 @@ func (ctx *context) ServeContent(content io.ReadSeeker, filename string, modtime
 
 	ctx.ContentType(filename)
 	ctx.SetLastModified(modtime)
+
+	if served, err := ctx.serveContentRange(content, modtime); served {
+		if err != nil {
+			return errServeContent.With(err)
+		}
+		return nil
+	}
+
 	var out io.Writer
 	if gzipCompression && ctx.ClientSupportsGzip() {
 		AddGzipHeaders(ctx.writer)
@@ -3395,7 +4274,7 @@ func (ctx *context) ServeContent(content io.ReadSeeker, filename string, modtime
 // gzipCompression (bool)
 //
 // You can define your own "Content-Type" header also, after this function call
-// This function doesn't implement resuming (by range), use ctx.SendFile instead
+// It supports the "Range" requests, see `ServeContent`.
 //
 // Use it when you want to serve css/js/... files to the client, for bigger files and 'force-download' use the SendFile.
 // 内部实现是通过ServeContent()来实现，这里封装了从File角度处理
@@ -3427,6 +4306,149 @@ func (ctx *context) SendFile(filename string, destinationName string) error {
 	return ctx.ServeFile(filename, false)
 }
 
+// ErrZipAborted is returned from the `SendZip` when the client closed the connection
+// before the archive was fully written.
+var ErrZipAborted = errors.New("zip: client closed the connection, writing aborted")
+
+// closeNotifyWriter aborts the writing when the client's connection is gone.
+type closeNotifyWriter struct {
+	io.Writer
+	notifyClosed <-chan bool
+}
+
+func (w closeNotifyWriter) Write(b []byte) (int, error) {
+	select {
+	case <-w.notifyClosed:
+		return 0, ErrZipAborted
+	default:
+		return w.Writer.Write(b)
+	}
+}
+
+// SendZip packages the "files" into a single zip archive and streams it
+// to the client for force-download as "destinationName".
+// The keys of the "files" map are the names of the entries inside the archive,
+// readers that implement the io.Closer are closed after they were written.
+//
+// The optional "compressionLevel" can be any of the `compress/flate` levels,
+// flate.NoCompression stores the files as they are. Defaults to flate.DefaultCompression.
+//
+// The writing is aborted with an `ErrZipAborted` error if the client closes the connection.
+func (ctx *context) SendZip(destinationName string, files map[string]io.Reader, compressionLevel ...int) error {
+	names := make([]string, 0, len(files))
+	pending := make(map[string]io.Reader, len(files))
+	for name, r := range files {
+		names = append(names, name)
+		pending[name] = r
+	}
+
+	defer func() {
+		// the ones which were not written because of an error.
+		for _, r := range pending {
+			if closer, ok := r.(io.Closer); ok {
+				closer.Close()
+			}
+		}
+	}()
+
+	modtime := time.Now()
+	return ctx.sendZip(destinationName, names, func(name string) (io.Reader, time.Time, error) {
+		r := pending[name]
+		delete(pending, name)
+		return r, modtime, nil
+	}, compressionLevel)
+}
+
+// SendZipFiles is like `SendZip` but the values of the "filenames" map are paths of files in the disk,
+// i.e {"report.csv": "./exports/2019/report.csv"}, they are opened one by one while the archive is written
+// and their entries keep their modification time.
+// A missing file or a directory returns an error before anything is written to the client.
+func (ctx *context) SendZipFiles(destinationName string, filenames map[string]string, compressionLevel ...int) error {
+	names := make([]string, 0, len(filenames))
+	for name, filename := range filenames {
+		fi, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+
+		if fi.IsDir() {
+			return errZipDirectory.Format(filename)
+		}
+
+		names = append(names, name)
+	}
+
+	return ctx.sendZip(destinationName, names, func(name string) (io.Reader, time.Time, error) {
+		f, err := os.Open(filenames[name])
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, time.Time{}, err
+		}
+
+		return f, fi.ModTime(), nil
+	}, compressionLevel)
+}
+
+var errZipDirectory = errors.New("zip: '%s' is a directory")
+
+// sendZip writes the "names" entries sorted, the "open" returns the contents of an entry,
+// closed after they were written, and its modification time.
+func (ctx *context) sendZip(destinationName string, names []string, open func(name string) (io.Reader, time.Time, error), compressionLevel []int) error {
+	level := flate.DefaultCompression
+	if len(compressionLevel) > 0 {
+		level = compressionLevel[0]
+	}
+
+	// write the entries in the same order every time.
+	sort.Strings(names)
+
+	// the archive's size is unknown, it's streamed to the client.
+	ctx.ContentType("application/zip")
+	ctx.writer.Header().Set(ContentDispositionHeaderKey, "attachment;filename="+destinationName)
+
+	zw := zip.NewWriter(closeNotifyWriter{ctx.writer, ctx.writer.CloseNotify()})
+	method := zip.Deflate
+	if level == flate.NoCompression {
+		method = zip.Store
+	} else {
+		zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(out, level)
+		})
+	}
+
+	for _, name := range names {
+		r, modtime, err := open(name)
+		if err != nil {
+			return err
+		}
+
+		err = writeZipEntry(zw, &zip.FileHeader{Name: name, Method: method, Modified: modtime}, r)
+		if closer, ok := r.(io.Closer); ok {
+			closer.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func writeZipEntry(zw *zip.Writer, fh *zip.FileHeader, r io.Reader) error {
+	w, err := zw.CreateHeader(fh)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, r)
+	return err
+}
+
 //  +------------------------------------------------------------+
 //  | Cookies                                                    |
 //  +------------------------------------------------------------+
@@ -3436,7 +4458,8 @@ func (ctx *context) SendFile(filename string, destinationName string) error {
 // as their (last) variadic input argument to amend the end cookie's form.
 //
 // Any custom or built'n `CookieOption` is valid,
-// see `CookiePath`, `CookieCleanPath`, `CookieExpires` and `CookieHTTPOnly` for more.
+// see `CookiePath`, `CookieCleanPath`, `CookieExpires`, `CookieHTTPOnly`,
+// `CookieSameSite`, `CookieSecure` and `CookieDomain` for more.
 type CookieOption func(*http.Cookie)
 
 // CookiePath is a `CookieOption`.
@@ -3471,6 +4494,51 @@ func CookieHTTPOnly(httpOnly bool) CookieOption {
 	}
 }
 
+// CookieSameSite is a `CookieOption`.
+// Use it to set the cookie's SameSite field, i.e `http.SameSiteLaxMode`.
+// The `http.SameSiteNoneMode` requires a secure cookie, so it sets the cookie's Secure field to true too.
+func CookieSameSite(sameSite http.SameSite) CookieOption {
+	return func(c *http.Cookie) {
+		c.SameSite = sameSite
+		if sameSite == http.SameSiteNoneMode {
+			c.Secure = true
+		}
+	}
+}
+
+// CookieSecure is a `CookieOption`.
+// Use it to set the cookie's Secure field to false or true,
+// a secure cookie is sent by the clients over https only.
+func CookieSecure(secure bool) CookieOption {
+	return func(c *http.Cookie) {
+		c.Secure = secure
+	}
+}
+
+// CookieDomain is a `CookieOption`.
+// Use it to change the cookie's Domain field, i.e "example.com" to share the cookie with its subdomains.
+func CookieDomain(domain string) CookieOption {
+	return func(c *http.Cookie) {
+		c.Domain = domain
+	}
+}
+
+// applyCookieDefaults sets the `Configuration#CookieSameSite`, `CookieSecure` and `CookieDomain` to the "c",
+// before the options of the `SetCookieKV` and `RemoveCookie` calls, so they can be overridden per call.
+// 全局的cookie属性，例如强制 SameSite=Lax
+func (ctx *context) applyCookieDefaults(c *http.Cookie) {
+	cfg := ctx.Application().ConfigurationReadOnly()
+	if v := cfg.GetCookieSameSite(); v != 0 {
+		CookieSameSite(v)(c)
+	}
+	if cfg.GetCookieSecure() {
+		c.Secure = true
+	}
+	if v := cfg.GetCookieDomain(); v != "" {
+		c.Domain = v
+	}
+}
+
 type (
 	// CookieEncoder should encode the cookie value.
 	// Should accept as first argument the cookie name
@@ -3549,6 +4617,8 @@ func (ctx *context) SetCookie(cookie *http.Cookie, options ...CookieOption) {
 // use the `CookieExpires` and `CookiePath` to modify them.
 // Alternatively: ctx.SetCookie(&http.Cookie{...})
 //
+// The value is signed, and encrypted, by the `Configuration#CookieSecret` and `CookieEncryption`, if any.
+//
 // If you want to set custom the path:
 // ctx.SetCookieKV(name, value, iris.CookiePath("/custom/path/cookie/will/be/stored"))
 //
@@ -3572,11 +4642,23 @@ func (ctx *context) SetCookieKV(name, value string, options ...CookieOption) {
 	c.HttpOnly = true
 	c.Expires = time.Now().Add(SetCookieKVExpiration)
 	c.MaxAge = int(SetCookieKVExpiration.Seconds())
-	ctx.SetCookie(c, options...)
+	ctx.applyCookieDefaults(c)
+	for _, opt := range options {
+		opt(c)
+	}
+	// signed last, so the "options", i.e a `CookieEncode`, are covered by the signature too.
+	if cfg := ctx.Application().ConfigurationReadOnly(); cfg.GetCookieSecret() != "" {
+		c.Value = signCookieValue(cfg.GetCookieSecret(), cfg.GetCookieEncryption(), name, c.Value)
+	}
+	ctx.SetCookie(c)
 }
 
 // GetCookie returns cookie's value by it's name
 // returns empty string if nothing was found.
+// When the `Configuration#CookieSecret` is set, the signed cookies of the `SetCookieKV` are verified,
+// a modified one is not found, the rest of them, i.e the ones of the `SetCookie`, are returned as they're.
+// Use the `GetSignedCookie` when the cookie must be a signed one.
+// The "options", i.e a `CookieDecode`, receive the verified value.
 //
 // If you want more than the value then:
 // cookie, err := ctx.Request().Cookie("name")
@@ -3589,6 +4671,40 @@ func (ctx *context) GetCookie(name string, options ...CookieOption) string {
 		return ""
 	}
 
+	if secret := ctx.Application().ConfigurationReadOnly().GetCookieSecret(); secret != "" && isSignedCookieValue(cookie.Value) {
+		var ok bool
+		if cookie.Value, ok = verifyCookieValue(secret, name, cookie.Value); !ok {
+			return ""
+		}
+	}
+
+	for _, opt := range options {
+		opt(cookie)
+	}
+
+	value, _ := url.QueryUnescape(cookie.Value)
+	return value
+}
+
+// GetSignedCookie returns the value of the "name" cookie only if it's signed, and not modified,
+// by the `SetCookieKV` and the `Configuration#CookieSecret`, otherwise an empty string,
+// i.e an unsigned cookie which is set by the client.
+func (ctx *context) GetSignedCookie(name string, options ...CookieOption) string {
+	secret := ctx.Application().ConfigurationReadOnly().GetCookieSecret()
+	if secret == "" {
+		return ""
+	}
+
+	cookie, err := ctx.request.Cookie(name)
+	if err != nil {
+		return ""
+	}
+
+	var ok bool
+	if cookie.Value, ok = verifyCookieValue(secret, name, cookie.Value); !ok {
+		return ""
+	}
+
 	for _, opt := range options {
 		opt(cookie)
 	}
@@ -3617,6 +4733,7 @@ func (ctx *context) RemoveCookie(name string, options ...CookieOption) {
 	exp := time.Now().Add(-time.Duration(1) * time.Minute)
 	c.Expires = exp
 	c.MaxAge = -1
+	ctx.applyCookieDefaults(c)
 	ctx.SetCookie(c, options...)
 	// delete request's cookie also, which is temporary available.
 	// todo 阅读原生 Set("Cookie","")的源码
@@ -3692,6 +4809,28 @@ func (ctx *context) IsRecording() (*ResponseRecorder, bool) {
 	return rr, ok
 }
 
+// Buffered(true) buffers the response, the writer is switched to a `ResponseRecorder`
+// and the status code, the headers and the body are sent to the client at the end of the request,
+// so the status code and the headers can be set even after the body's writes, i.e by a template.
+// Buffered(false) sends the response buffered so far, if any, and stops buffering.
+//
+// Note that it's a no-op if the response is already sent or the writer is not the default one,
+// i.e when gzip is enabled. Use the `Party#Buffered` to enable it for a group of routes.
+func (ctx *context) Buffered(enable bool) {
+	if enable {
+		if ctx.writer.Written() == NoWritten {
+			ctx.Record()
+		}
+		return
+	}
+
+	if rec, ok := ctx.IsRecording(); ok {
+		rec.FlushResponse()
+		ctx.ResetResponseWriter(rec.ResponseWriter)
+		releaseResponseRecorder(rec)
+	}
+}
+
 // non-detailed error log for transacton unexpected panic
 var errTransactionInterrupted = errors.New("transaction interrupted, recovery from panic:\n%s")
 
@@ -3721,7 +4860,7 @@ func (ctx *context) BeginTransaction(pipe func(t *Transaction)) {
 	t := newTransaction(ctx) // it calls this *context, so the overriding with a new pool's New of context.Context wil not work here.
 	defer func() {
 		if err := recover(); err != nil {
-			ctx.Application().Logger().Warn(errTransactionInterrupted.Format(err).Error())
+			Logger(ctx).Warn(errTransactionInterrupted.Format(err).Error())
 			// complete (again or not , doesn't matters) the scope without loud
 			t.Complete(nil)
 			// we continue as normal, no need to return here*
@@ -3732,6 +4871,8 @@ func (ctx *context) BeginTransaction(pipe func(t *Transaction)) {
 		// give back to the transaction the original writer (SetBeforeFlush works this way and only this way)
 		// this is tricky but nessecery if we want ctx.FireStatusCode to work inside transactions
 		t.Context().ResetResponseWriter(ctx.writer)
+		// the transaction's goroutines, if any, stop here.
+		cancelExecution(t.Context())
 
 	}()
 
@@ -3781,11 +4922,22 @@ func (ctx *context) TransactionsSkipped() bool {
 //
 // It's for extreme use cases, 99% of the times will never be useful for you.
 // 这里的实现的功能是服务端处理一个请求逻辑中，中途使用method 方法调用 path，然后再变回来
-func (ctx *context) Exec(method string, path string) {
+func (ctx *context) Exec(method string, path string, options ...ExecOptions) {
 	if path == "" {
 		return
 	}
 
+	if len(options) > 0 {
+		// the executed route sees only the whitelisted values.
+		backupValues := ctx.values
+		ctx.values = options[0].copyValues(backupValues)
+		defer func() {
+			executedValues := ctx.values
+			ctx.values = backupValues
+			options[0].returnValues(executedValues, &ctx.values)
+		}()
+	}
+
 	if method == "" {
 		method = "GET"
 	}
@@ -3823,6 +4975,44 @@ func (ctx *context) Exec(method string, path string) {
 	ctx.currentHandlerIndex = backupPos
 }
 
+// ExecOptions are the options of the `Context#Exec`, when they're passed the Values of the context
+// are isolated between the caller and the executed route, only the whitelisted keys are shared,
+// i.e the auth or the view data of the caller are not leaked to a logically separate route and vice versa.
+//
+// Usage:
+// ctx.Values().Set("user", user)
+// ctx.Values().Set("tenant", tenant)
+// ctx.Exec("GET", "/internal/report", context.ExecOptions{CopyKeys: []string{"tenant"}, ReturnKeys: []string{"report"}})
+// report := ctx.Values().Get("report")
+type ExecOptions struct {
+	// CopyKeys are the keys of the caller's Values which are visible to the executed route.
+	CopyKeys []string
+	// ReturnKeys are the keys of the executed route's Values which are set back to the caller's ones,
+	// the rest of the caller's Values are kept intact.
+	ReturnKeys []string
+}
+
+// copyValues returns a new store with the "CopyKeys" entries of the "values".
+func (opts ExecOptions) copyValues(values memstore.Store) memstore.Store {
+	copied := make(memstore.Store, 0, len(opts.CopyKeys))
+	for _, key := range opts.CopyKeys {
+		if entry, ok := values.GetEntry(key); ok {
+			copied = append(copied, entry)
+		}
+	}
+
+	return copied
+}
+
+// returnValues sets the "ReturnKeys" entries of the "executed" values to the "dest".
+func (opts ExecOptions) returnValues(executed memstore.Store, dest *memstore.Store) {
+	for _, key := range opts.ReturnKeys {
+		if entry, ok := executed.GetEntry(key); ok {
+			dest.Set(key, entry.ValueRaw)
+		}
+	}
+}
+
 // RouteExists reports whether a particular route exists
 // It will search from the current subdomain of context's host, if not inside the root domain.
 // 判断当前的context.Application中是否有对应的方法和路径的路由
@@ -0,0 +1,118 @@
+package context
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"strings"
+)
+
+// signedCookiePrefix and encryptedCookiePrefix mark the values of the signed and the encrypted cookies,
+// so the rest of them, i.e the ones of the `SetCookie` or of the client, are not verified by the `GetCookie`.
+const (
+	signedCookiePrefix    = "~s."
+	encryptedCookiePrefix = "~e."
+)
+
+// isSignedCookieValue reports whether the "value" is signed (or encrypted) by the `signCookieValue`.
+func isSignedCookieValue(value string) bool {
+	return strings.HasPrefix(value, signedCookiePrefix) || strings.HasPrefix(value, encryptedCookiePrefix)
+}
+
+// cookieKey derives a key of the `Configuration#CookieSecret` for the "purpose",
+// so the signing and the encryption keys are different.
+func cookieKey(secret, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+func cookieSignature(secret, name, value string) []byte {
+	mac := hmac.New(sha256.New, cookieKey(secret, "iris.cookie.sign"))
+	mac.Write([]byte(name))
+	mac.Write([]byte{'='})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+func cookieAEAD(secret string) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(cookieKey(secret, "iris.cookie.encrypt"))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// signCookieValue returns the signed "value" of the "name" cookie, "~s.value.signature",
+// or the encrypted one, "~e.ciphertext", the cookie's name is authenticated too, so a value can't be moved to another cookie.
+// 用应用级别的密钥签名(和加密)cookie的值
+func signCookieValue(secret string, encrypt bool, name, value string) string {
+	if !encrypt {
+		return signedCookiePrefix + value + "." + base64.RawURLEncoding.EncodeToString(cookieSignature(secret, name, value))
+	}
+
+	aead, err := cookieAEAD(secret)
+	if err != nil {
+		return ""
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return ""
+	}
+
+	return encryptedCookiePrefix + base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(value), []byte(name)))
+}
+
+// verifyCookieValue returns the original value of a signed (or encrypted) "name" cookie,
+// it reports false for a missing or an invalid signature.
+// The mode is resolved by the value's prefix, so a change of the `Configuration#CookieEncryption`
+// does not invalidate the cookies of the clients.
+func verifyCookieValue(secret string, name, signed string) (string, bool) {
+	if strings.HasPrefix(signed, signedCookiePrefix) {
+		signed = signed[len(signedCookiePrefix):]
+		idx := strings.LastIndexByte(signed, '.')
+		if idx == -1 {
+			return "", false
+		}
+
+		signature, err := base64.RawURLEncoding.DecodeString(signed[idx+1:])
+		if err != nil {
+			return "", false
+		}
+
+		value := signed[:idx]
+		if !hmac.Equal(signature, cookieSignature(secret, name, value)) {
+			return "", false
+		}
+
+		return value, true
+	}
+
+	if !strings.HasPrefix(signed, encryptedCookiePrefix) {
+		return "", false
+	}
+	signed = signed[len(encryptedCookiePrefix):]
+
+	aead, err := cookieAEAD(secret)
+	if err != nil {
+		return "", false
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(signed)
+	if err != nil || len(b) < aead.NonceSize() {
+		return "", false
+	}
+
+	value, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return "", false
+	}
+
+	return string(value), true
+}
@@ -0,0 +1,72 @@
+package context
+
+import "sync"
+
+// doneContextKey is the context's values key of the request's execution state, see `Done`.
+const doneContextKey = "iris.done"
+
+// closedDone is the done channel of the requests which are canceled before the `Done` is called.
+var closedDone = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// canceledExecution is the execution state of the requests which are canceled before the `Done` is called.
+var canceledExecution = &execState{done: closedDone, canceled: true}
+
+// execState is the cancellation state of a request's execution, see `Done`.
+type execState struct {
+	mu       sync.Mutex
+	done     chan struct{}
+	canceled bool
+}
+
+func newExecState() *execState {
+	return &execState{done: make(chan struct{})}
+}
+
+func (e *execState) cancel() {
+	e.mu.Lock()
+	if !e.canceled {
+		e.canceled = true
+		close(e.done)
+	}
+	e.mu.Unlock()
+}
+
+// Done returns a channel which is closed when the execution of the request is canceled:
+// when the `StopExecution` is called, before an error code handler is fired and at the end of the request.
+// The streaming handlers and their goroutines should stop writing on it,
+// i.e `done := context.Done(ctx)` and `select { case <-done: return; case msg := <-messages: ... }`,
+// the `StreamWriter` and the SSE connections stop on it already.
+//
+// Its state is kept in the `Context#Values`, which are not safe for concurrent use,
+// so it should be called by the handler and its channel should be passed to the goroutines.
+//
+// Unlike the `StdContext`, it's not closed when the client disconnects,
+// see the `ResponseWriter#CloseNotify` for that.
+// The context of a transaction has its own one, it's closed when the transaction ends.
+// 请求的执行被取消(StopExecution, 触发错误码处理器之前或者请求结束)时关闭的channel
+func Done(ctx Context) <-chan struct{} {
+	values := ctx.Values()
+	e, ok := values.Get(doneContextKey).(*execState)
+	if !ok {
+		// lazy, the most of the requests never need it.
+		e = newExecState()
+		values.Set(doneContextKey, e)
+	}
+
+	return e.done
+}
+
+// cancelExecution closes the done channel of the request, once, see `Done`.
+func cancelExecution(ctx Context) {
+	values := ctx.Values()
+	if e, ok := values.Get(doneContextKey).(*execState); ok {
+		e.cancel()
+		return
+	}
+
+	values.Set(doneContextKey, canceledExecution)
+}
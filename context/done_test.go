@@ -0,0 +1,142 @@
+package context_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func isClosed(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestDoneStopExecution(t *testing.T) {
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		done := context.Done(ctx)
+		if isClosed(done) {
+			ctx.WriteString("closed before stop")
+			return
+		}
+
+		ctx.StopExecution()
+		if !isClosed(done) {
+			ctx.WriteString("not closed after stop")
+			return
+		}
+
+		if !isClosed(context.Done(ctx)) {
+			ctx.WriteString("not closed on a call after stop")
+			return
+		}
+
+		ctx.WriteString("ok")
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").Expect().Status(httptest.StatusOK).Body().Equal("ok")
+}
+
+func TestDoneErrorHandler(t *testing.T) {
+	app := iris.New()
+	var done <-chan struct{}
+	app.OnErrorCode(iris.StatusNotFound, func(ctx iris.Context) {
+		if !isClosed(done) {
+			ctx.WriteString("not closed before the error handler")
+			return
+		}
+
+		ctx.WriteString("ok")
+	})
+	app.Get("/", func(ctx iris.Context) {
+		done = context.Done(ctx)
+		ctx.StatusCode(iris.StatusNotFound)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").Expect().Status(httptest.StatusNotFound).Body().Equal("ok")
+}
+
+func TestDoneTransaction(t *testing.T) {
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		var transactionDone <-chan struct{}
+		ctx.BeginTransaction(func(tr *context.Transaction) {
+			transactionDone = context.Done(tr.Context())
+			// it should not cancel the parent's execution.
+			tr.Context().StopExecution()
+			tr.Context().WriteString("transaction;")
+		})
+
+		if !isClosed(transactionDone) {
+			ctx.WriteString("transaction's done is not closed after the transaction")
+			return
+		}
+
+		if isClosed(context.Done(ctx)) {
+			ctx.WriteString("transaction canceled the parent's execution")
+			return
+		}
+
+		ctx.WriteString("ok")
+	})
+
+	e := httptest.New(t, app)
+	// the request should end without closing the same channel twice.
+	for i := 0; i < 3; i++ {
+		e.GET("/").Expect().Status(httptest.StatusOK).Body().Equal("transaction;ok")
+	}
+}
+
+type customContext struct {
+	context.Context
+}
+
+func (ctx *customContext) Do(handlers context.Handlers) {
+	context.Do(ctx, handlers)
+}
+
+func (ctx *customContext) Next() {
+	context.Next(ctx)
+}
+
+func TestDoneCustomContext(t *testing.T) {
+	app := iris.New()
+	app.Configure(iris.WithCustomContext(func(app context.Application) context.Context {
+		return &customContext{Context: context.NewContext(app)}
+	}))
+
+	var (
+		done  <-chan struct{}
+		ended bool
+	)
+	app.Get("/", func(ctx iris.Context) {
+		if _, ok := ctx.(*customContext); !ok {
+			t.Errorf("expected the custom context but got %T", ctx)
+		}
+
+		done = context.Done(ctx)
+		context.OnEnd(ctx, func() {
+			ended = true
+		})
+		if isClosed(done) {
+			ctx.WriteString("closed before the end")
+			return
+		}
+
+		ctx.WriteString("ok")
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").Expect().Status(httptest.StatusOK).Body().Equal("ok")
+	if !ended || !isClosed(done) {
+		t.Fatalf("expected the end callback and the done channel of the custom context to be fired")
+	}
+}
@@ -0,0 +1,107 @@
+package context
+
+import (
+	"strconv"
+	"strings"
+)
+
+// IdentityEncoding is the "identity" content coding, no compression.
+const IdentityEncoding = "identity"
+
+// parseAcceptEncoding parses the "Accept-Encoding" request header's value
+// to a map of lowercase codings and their quality values (q-values).
+func parseAcceptEncoding(acceptEncoding string) map[string]float64 {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		coding, q := part, 1.0
+		if idx := strings.IndexByte(part, ';'); idx >= 0 {
+			coding = strings.TrimSpace(part[:idx])
+			for _, param := range strings.Split(part[idx+1:], ";") {
+				param = strings.TrimSpace(param)
+				if len(param) > 2 && (param[0] == 'q' || param[0] == 'Q') && param[1] == '=' {
+					if v, err := strconv.ParseFloat(param[2:], 64); err == nil && v >= 0 && v <= 1 {
+						q = v
+					} else {
+						q = 0 // invalid q-value, do not accept it.
+					}
+				}
+			}
+		}
+
+		coding = strings.ToLower(coding)
+		// if the same coding is given more than once keep the highest quality.
+		if prev, ok := qualities[coding]; !ok || q > prev {
+			qualities[coding] = q
+		}
+	}
+
+	return qualities
+}
+
+// implicitIdentityQuality is the quality of the "identity" when it's not listed,
+// lower than the lowest q-value a client can send (0.001).
+const implicitIdentityQuality = 0.0001
+
+// encodingQuality returns the quality value of the "coding" based on the parsed "qualities",
+// see https://tools.ietf.org/html/rfc7231#section-5.3.4.
+func encodingQuality(qualities map[string]float64, coding string) float64 {
+	if q, ok := qualities[coding]; ok {
+		return q
+	}
+
+	if q, ok := qualities["*"]; ok {
+		return q
+	}
+
+	// the "identity" is always acceptable,
+	// unless it's excluded explicitly by "identity;q=0" or "*;q=0",
+	// but the listed codings are preferred, i.e "br;q=0.5" over the identity.
+	if coding == IdentityEncoding {
+		return implicitIdentityQuality
+	}
+
+	return 0
+}
+
+// NegotiateEncoding returns the most preferable, by the client, content coding
+// of the "offers" based on the "acceptEncoding" request header's value,
+// it fully honors the q-values, i.e "gzip;q=0" means that gzip is not acceptable
+// and "identity;q=0" that the response must be encoded.
+// The order of the "offers" is used as the server's preference on equal q-values.
+//
+// It returns an empty string if none of the offers is acceptable.
+// If "acceptEncoding" is empty then only the `IdentityEncoding` is acceptable.
+//
+// Used by the `Context#ClientSupportsGzip` and `Context#NegotiateEncoding`.
+func NegotiateEncoding(acceptEncoding string, offers ...string) string {
+	if strings.TrimSpace(acceptEncoding) == "" {
+		for _, offer := range offers {
+			if strings.EqualFold(offer, IdentityEncoding) {
+				return offer
+			}
+		}
+		return ""
+	}
+
+	qualities := parseAcceptEncoding(acceptEncoding)
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := encodingQuality(qualities, strings.ToLower(offer)); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// AcceptsEncoding reports whether the "coding" is acceptable
+// based on the "acceptEncoding" request header's value and its q-values.
+func AcceptsEncoding(acceptEncoding string, coding string) bool {
+	return NegotiateEncoding(acceptEncoding, coding) != ""
+}
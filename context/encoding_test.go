@@ -0,0 +1,79 @@
+package context_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	offers := []string{"br", context.GzipHeaderValue, context.IdentityEncoding}
+
+	tests := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		// only the identity.
+		{"", context.IdentityEncoding},
+		{"gzip", context.GzipHeaderValue},
+		{"GZIP, br", "br"},
+		// the client's preference.
+		{"br;q=0.5, gzip", context.GzipHeaderValue},
+		{"br;q=0.8, gzip;q=0.8", "br"},
+		// not acceptable.
+		{"gzip;q=0", context.IdentityEncoding},
+		{"gzip;q=0, br;q=0", context.IdentityEncoding},
+		{"deflate", context.IdentityEncoding},
+		// invalid q-values are not acceptable.
+		{"gzip;q=2", context.IdentityEncoding},
+		{"gzip;q=x", context.IdentityEncoding},
+		// the highest of the duplicates.
+		{"gzip;q=0, gzip;q=0.5", context.GzipHeaderValue},
+		// the wildcard.
+		{"*", "br"},
+		{"gzip;q=0.5, *;q=0.1", context.GzipHeaderValue},
+		{"*;q=0", ""},
+		// the identity is excluded explicitly.
+		{"identity;q=0", ""},
+		{"gzip;q=0, identity;q=0", ""},
+		{"gzip, identity;q=0", context.GzipHeaderValue},
+	}
+
+	for i, tt := range tests {
+		if got := context.NegotiateEncoding(tt.acceptEncoding, offers...); got != tt.expected {
+			t.Fatalf("[%d] %q: expected %q but got %q", i, tt.acceptEncoding, tt.expected, got)
+		}
+	}
+
+	if context.NegotiateEncoding("gzip") != "" {
+		t.Fatalf("expected no encoding without offers")
+	}
+	if !context.AcceptsEncoding("gzip;q=0.001", context.GzipHeaderValue) || context.AcceptsEncoding("gzip;q=0.000", context.GzipHeaderValue) {
+		t.Fatalf("expected gzip to be acceptable only with a positive q-value")
+	}
+}
+
+func TestClientSupportsGzip(t *testing.T) {
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		ctx.Gzip(true)
+		ctx.WriteString("hello")
+	})
+	app.Get("/negotiate", func(ctx iris.Context) {
+		ctx.WriteString(ctx.NegotiateEncoding("br", context.GzipHeaderValue, context.IdentityEncoding))
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/").WithHeader("Accept-Encoding", "gzip;q=0.5").Expect().Status(httptest.StatusOK).
+		Header(context.ContentEncodingHeaderKey).Equal(context.GzipHeaderValue)
+	// "gzip;q=0" must not be treated as a gzip one.
+	r := e.GET("/").WithHeader("Accept-Encoding", "gzip;q=0, deflate").Expect().Status(httptest.StatusOK)
+	r.Header(context.ContentEncodingHeaderKey).Empty()
+	r.Body().Equal("hello")
+
+	e.GET("/negotiate").WithHeader("Accept-Encoding", "gzip;q=0.9, br;q=0.1").Expect().
+		Status(httptest.StatusOK).Body().Equal(context.GzipHeaderValue)
+}
@@ -0,0 +1,94 @@
+package context
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// IfNoneMatchHeaderKey is the header key of "If-None-Match".
+const IfNoneMatchHeaderKey = "If-None-Match"
+
+// ComputeETag returns the entity-tag of the "body", its (truncated) sha256 checksum,
+// i.e `"4f53cda18c2baa0c0354bb5f9a3ecbe5"` or `W/"4f53cda18c2baa0c0354bb5f9a3ecbe5"` if "weak" is true.
+//
+// A strong entity-tag should be used when the response is byte-for-byte the same,
+// a weak one when it's semantically equivalent, i.e before a compression.
+func ComputeETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if weak {
+		etag = "W/" + etag
+	}
+
+	return etag
+}
+
+// CheckIfNoneMatch reports whether the "If-None-Match" request header
+// matches the "etag", with the weak comparison (RFC 7232 section 3.2), "*" matches any.
+func (ctx *context) CheckIfNoneMatch(etag string) bool {
+	inm := ctx.GetHeader(IfNoneMatchHeaderKey)
+	if inm == "" || etag == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(inm, ",") {
+		candidate = textproto.TrimString(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteWithEtag like Write but it sends the "ETag" header of the "body", see `ComputeETag`,
+// or the existing "ETag" response header if it's already set,
+// and it sends a 304 "Not Modified" instead when the client's "If-None-Match" matches it.
+func (ctx *context) WriteWithEtag(body []byte) (int, error) {
+	etag := ctx.writer.Header().Get(ETagHeaderKey)
+	if etag == "" {
+		etag = ComputeETag(body, false)
+		ctx.writer.Header().Set(ETagHeaderKey, etag)
+	}
+
+	if method := ctx.Method(); (method == http.MethodGet || method == http.MethodHead) && ctx.CheckIfNoneMatch(etag) {
+		ctx.WriteNotModified()
+		return 0, nil
+	}
+
+	return ctx.writer.Write(body)
+}
+
+// ETagHandler is a middleware which records the response of the next handlers in the chain
+// and sends the "ETag" header of its body, a weak one if "weak" is true,
+// or a 304 "Not Modified" instead when the client's "If-None-Match" matches it.
+// The successful (200) responses without an "ETag" header, set by the handlers, are tagged only.
+//
+// Usage:
+// app.Get("/users", iris.ETagHandler(false), listUsers)
+// 记录响应体并自动生成ETag，处理条件请求(If-None-Match)
+var ETagHandler = func(weak bool) Handler {
+	return func(ctx Context) {
+		ctx.Record()
+		ctx.Next()
+
+		w, ok := ctx.IsRecording()
+		if !ok || w.IsPassThrough() || w.StatusCode() != http.StatusOK || len(w.Body()) == 0 {
+			return
+		}
+
+		etag := w.Header().Get(ETagHeaderKey)
+		if etag == "" {
+			etag = ComputeETag(w.Body(), weak)
+			w.Header().Set(ETagHeaderKey, etag)
+		}
+
+		if method := ctx.Method(); (method == http.MethodGet || method == http.MethodHead) && ctx.CheckIfNoneMatch(etag) {
+			w.ResetBody()
+			ctx.WriteNotModified()
+		}
+	}
+}
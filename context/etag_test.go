@@ -0,0 +1,124 @@
+package context_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestComputeETag(t *testing.T) {
+	strong := context.ComputeETag([]byte("body"), false)
+	if len(strong) != 34 || strong[0] != '"' || strong[33] != '"' {
+		t.Fatalf("expected a quoted strong entity-tag but got %s", strong)
+	}
+	if got := context.ComputeETag([]byte("body"), false); got != strong {
+		t.Fatalf("expected the same entity-tag of the same body but got %s and %s", strong, got)
+	}
+	if got := context.ComputeETag([]byte("other"), false); got == strong {
+		t.Fatalf("expected a different entity-tag of a different body")
+	}
+	if expected, got := "W/"+strong, context.ComputeETag([]byte("body"), true); expected != got {
+		t.Fatalf("expected the weak entity-tag %s but got %s", expected, got)
+	}
+}
+
+func TestCheckIfNoneMatch(t *testing.T) {
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		if ctx.CheckIfNoneMatch(`"v1"`) {
+			ctx.WriteString("match")
+		}
+	})
+
+	e := httptest.New(t, app)
+
+	for inm, match := range map[string]bool{
+		"":               false,
+		`"v1"`:           true,
+		`W/"v1"`:         true,
+		`"v0", "v1"`:     true,
+		`"v0",W/"v1"`:    true,
+		`*`:              true,
+		`"v2"`:           false,
+		`v1`:             false,
+		`"v0", "v1-old"`: false,
+	} {
+		expected := ""
+		if match {
+			expected = "match"
+		}
+		e.GET("/").WithHeader(context.IfNoneMatchHeaderKey, inm).Expect().Status(httptest.StatusOK).Body().Equal(expected)
+	}
+}
+
+func TestWriteWithEtag(t *testing.T) {
+	body := []byte("users")
+	etag := context.ComputeETag(body, false)
+
+	app := iris.New()
+	app.Any("/", func(ctx iris.Context) {
+		ctx.WriteWithEtag(body)
+	})
+	app.Get("/custom", func(ctx iris.Context) {
+		ctx.Header(context.ETagHeaderKey, `"v1"`)
+		ctx.WriteWithEtag(body)
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/").Expect().Status(httptest.StatusOK).Body().Equal("users")
+	e.GET("/").Expect().Header(context.ETagHeaderKey).Equal(etag)
+	e.GET("/").WithHeader(context.IfNoneMatchHeaderKey, etag).Expect().Status(httptest.StatusNotModified).Body().Empty()
+	e.HEAD("/").WithHeader(context.IfNoneMatchHeaderKey, etag).Expect().Status(httptest.StatusNotModified)
+	// only the safe methods.
+	e.POST("/").WithHeader(context.IfNoneMatchHeaderKey, etag).Expect().Status(httptest.StatusOK).Body().Equal("users")
+
+	// the existing one is kept.
+	e.GET("/custom").Expect().Status(httptest.StatusOK).Header(context.ETagHeaderKey).Equal(`"v1"`)
+	e.GET("/custom").WithHeader(context.IfNoneMatchHeaderKey, `"v1"`).Expect().Status(httptest.StatusNotModified)
+	e.GET("/custom").WithHeader(context.IfNoneMatchHeaderKey, etag).Expect().Status(httptest.StatusOK)
+}
+
+func TestETagHandler(t *testing.T) {
+	body := "users"
+	etag := context.ComputeETag([]byte(body), false)
+
+	app := iris.New()
+	app.Get("/", iris.ETagHandler(false), func(ctx iris.Context) {
+		ctx.WriteString(body)
+	})
+	app.Get("/weak", iris.ETagHandler(true), func(ctx iris.Context) {
+		ctx.WriteString(body)
+	})
+	app.Get("/custom", iris.ETagHandler(false), func(ctx iris.Context) {
+		ctx.Header(context.ETagHeaderKey, `"v1"`)
+		ctx.WriteString(body)
+	})
+	app.Get("/created", iris.ETagHandler(false), func(ctx iris.Context) {
+		ctx.StatusCode(iris.StatusCreated)
+		ctx.WriteString(body)
+	})
+	app.Get("/empty", iris.ETagHandler(false), func(ctx iris.Context) {})
+
+	e := httptest.New(t, app)
+
+	r := e.GET("/").Expect().Status(httptest.StatusOK)
+	r.Header(context.ETagHeaderKey).Equal(etag)
+	r.Body().Equal(body)
+	e.GET("/").WithHeader(context.IfNoneMatchHeaderKey, etag).Expect().Status(httptest.StatusNotModified).Body().Empty()
+
+	e.GET("/weak").Expect().Status(httptest.StatusOK).Header(context.ETagHeaderKey).Equal("W/" + etag)
+	// the weak comparison.
+	e.GET("/weak").WithHeader(context.IfNoneMatchHeaderKey, etag).Expect().Status(httptest.StatusNotModified)
+
+	e.GET("/custom").Expect().Status(httptest.StatusOK).Header(context.ETagHeaderKey).Equal(`"v1"`)
+	e.GET("/custom").WithHeader(context.IfNoneMatchHeaderKey, `"v1"`).Expect().Status(httptest.StatusNotModified)
+
+	// not the non-200 and the empty responses.
+	r = e.GET("/created").WithHeader(context.IfNoneMatchHeaderKey, "*").Expect().Status(httptest.StatusCreated)
+	r.Header(context.ETagHeaderKey).Empty()
+	r.Body().Equal(body)
+	e.GET("/empty").Expect().Status(httptest.StatusOK).Header(context.ETagHeaderKey).Empty()
+}
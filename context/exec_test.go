@@ -0,0 +1,77 @@
+package context_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func newExecApp(options ...context.ExecOptions) *iris.Application {
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		ctx.Values().Set("user", "kataras")
+		ctx.Values().Set("tenant", "acme")
+		ctx.Values().Set("report", "old")
+		ctx.Values().SetImmutable("locked", "caller")
+
+		ctx.Exec("GET", "/internal/report", options...)
+
+		// the request is set back.
+		ctx.Writef("|%s %s|", ctx.Method(), ctx.Path())
+		for _, key := range []string{"user", "tenant", "report", "secret", "locked"} {
+			ctx.Writef("%s=%s ", key, ctx.Values().GetString(key))
+		}
+	})
+
+	app.Post("/internal/report", func(ctx iris.Context) {})
+	app.Get("/internal/report", func(ctx iris.Context) {
+		ctx.Writef("%s %s ", ctx.Method(), ctx.Path())
+		for _, key := range []string{"user", "tenant", "report"} {
+			ctx.Writef("%s=%s ", key, ctx.Values().GetString(key))
+		}
+
+		ctx.Values().Set("report", "new")
+		ctx.Values().Set("secret", "executed")
+		ctx.Values().Set("locked", "executed")
+	})
+
+	return app
+}
+
+func TestExec(t *testing.T) {
+	// the values are shared without options.
+	httptest.New(t, newExecApp()).GET("/").Expect().Status(httptest.StatusOK).Body().
+		Equal("GET /internal/report user=kataras tenant=acme report=old |GET /|user=kataras tenant=acme report=new secret=executed locked=caller ")
+}
+
+func TestExecOptions(t *testing.T) {
+	tests := []struct {
+		options  context.ExecOptions
+		expected string
+	}{
+		{
+			options: context.ExecOptions{CopyKeys: []string{"tenant"}, ReturnKeys: []string{"report"}},
+			// the caller's user is not visible and the executed route's secret does not flow back.
+			expected: "GET /internal/report user= tenant=acme report= |GET /|user=kataras tenant=acme report=new secret= locked=caller ",
+		},
+		{
+			// isolated, the missing keys are ignored.
+			options:  context.ExecOptions{CopyKeys: []string{"missing"}, ReturnKeys: []string{"missing"}},
+			expected: "GET /internal/report user= tenant= report= |GET /|user=kataras tenant=acme report=old secret= locked=caller ",
+		},
+		{
+			// the immutable values of the caller are kept.
+			options:  context.ExecOptions{CopyKeys: []string{"user", "report"}, ReturnKeys: []string{"secret", "locked"}},
+			expected: "GET /internal/report user=kataras tenant= report=old |GET /|user=kataras tenant=acme report=old secret=executed locked=caller ",
+		},
+	}
+
+	for i, tt := range tests {
+		body := httptest.New(t, newExecApp(tt.options)).GET("/").Expect().Status(httptest.StatusOK).Body().Raw()
+		if body != tt.expected {
+			t.Fatalf("[%d] expected the body %q but got %q", i, tt.expected, body)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package context
+
+// ExecutionInterceptor wraps the invocation of each one of the request's handlers,
+// the "next" must be called in order to execute the handler itself.
+// Useful for tracing, per-handler dependency injection and e.t.c.
+//
+// Unlike the package-level `Next`, interceptors are registered per Application,
+// see `iris#Application.UseExecutionInterceptor`.
+// 每个handler执行时的拦截器，按application注册，不会影响其它的app
+type ExecutionInterceptor func(ctx Context, next func())
+
+// ExecuteHandler executes the "h" handler through the
+// application's execution interceptors, if any.
+//
+// It's used by the `Do` and the `DefaultNext` to invoke the handlers of the chain.
+func ExecuteHandler(ctx Context, h Handler) {
+	if g := headerGuardOf(ctx); g != nil {
+		// development mode only, the handler's name is captured for the header guard's reports.
+		var routeName string
+		if r := ctx.GetCurrentRoute(); r != nil {
+			routeName = r.Name()
+		}
+		previous := g.enter(routeName, HandlerName(h))
+		defer g.leave(previous)
+	}
+
+	interceptors := ctx.Application().GetExecutionInterceptors()
+	if len(interceptors) == 0 {
+		h(ctx)
+		return
+	}
+
+	intercept(ctx, h, interceptors)
+}
+
+func intercept(ctx Context, h Handler, interceptors []ExecutionInterceptor) {
+	if len(interceptors) == 0 {
+		h(ctx)
+		return
+	}
+
+	interceptors[0](ctx, func() {
+		intercept(ctx, h, interceptors[1:])
+	})
+}
@@ -0,0 +1,285 @@
+package context
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+// FormFileTagName is the struct tag of the validation rules of the `ReadForm`'s file fields,
+// the rules are separated by commas, an unknown or invalid rule is an error of the `ReadForm`:
+//
+//	required: the file must be uploaded.
+//	max: the maximum size of each file, in bytes or with a KB, MB or GB suffix, i.e "max=5MB".
+//	mime: the allowed MIME types separated by "|", a type can end with "/*", i.e "mime=image/*|application/pdf",
+//	the MIME type is detected from the contents(content sniffing), not from the client's Content-Type.
+//
+// Usage:
+//
+//	type upload struct {
+//		Title  string                  `form:"title"`
+//		Avatar *multipart.FileHeader   `form:"avatar" file:"required,max=2MB,mime=image/png|image/jpeg"`
+//		Docs   []*multipart.FileHeader `form:"docs" file:"max=10MB,mime=application/pdf"`
+//	}
+const FormFileTagName = "file"
+
+// errInvalidFormFileRule is returned by the `ReadForm` when a `FormFileTagName` tag is not valid,
+// i.e "max=2M", it's a bug of the struct and not a validation error of the request.
+var errInvalidFormFileRule = errors.New("form file field '%s': invalid rule '%s'")
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// formFileRules are the parsed rules of a `FormFileTagName` tag.
+type formFileRules struct {
+	required bool
+	maxSize  int64
+	mimes    []string
+}
+
+func parseFormFileRules(field, tag string) (rules formFileRules, err error) {
+	if strings.TrimSpace(tag) == "" {
+		return
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		key, value := strings.TrimSpace(rule), ""
+		if idx := strings.IndexByte(key, '='); idx != -1 {
+			key, value = strings.TrimSpace(key[:idx]), strings.TrimSpace(key[idx+1:])
+		}
+
+		switch key {
+		case "":
+			// i.e a trailing comma.
+		case "required":
+			rules.required = true
+		case "max":
+			n, ok := parseByteSize(value)
+			if !ok {
+				return rules, errInvalidFormFileRule.Format(field, rule)
+			}
+			rules.maxSize = n
+		case "mime":
+			for _, m := range strings.Split(value, "|") {
+				if m = strings.ToLower(strings.TrimSpace(m)); m != "" {
+					rules.mimes = append(rules.mimes, m)
+				}
+			}
+			if len(rules.mimes) == 0 {
+				return rules, errInvalidFormFileRule.Format(field, rule)
+			}
+		default:
+			return rules, errInvalidFormFileRule.Format(field, rule)
+		}
+	}
+
+	return
+}
+
+// parseByteSize parses a size in bytes, i.e "512", "100KB", "5MB", "1GB", it reports false on failure.
+func parseByteSize(s string) (int64, bool) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for suffix, m := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if strings.HasSuffix(s, suffix) {
+			s, multiplier = strings.TrimSpace(strings.TrimSuffix(s, suffix)), m
+			break
+		}
+	}
+	s = strings.TrimSuffix(s, "B")
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	return n * multiplier, true
+}
+
+func (rules formFileRules) allowMIME(mimeType string) bool {
+	if len(rules.mimes) == 0 {
+		return true
+	}
+
+	if idx := strings.IndexByte(mimeType, ';'); idx != -1 {
+		mimeType = mimeType[:idx]
+	}
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	for _, m := range rules.mimes {
+		if m == mimeType || (strings.HasSuffix(m, "/*") && strings.HasPrefix(mimeType, m[:len(m)-1])) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// detectFileMIME detects the MIME type of the uploaded file from its first 512 bytes.
+func detectFileMIME(fh *multipart.FileHeader) (string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	return http.DetectContentType(head[:n]), nil
+}
+
+// hasFormFileFields reports whether the "ptr" is a struct (pointer) with `*multipart.FileHeader` or `[]*multipart.FileHeader` fields.
+func hasFormFileFields(ptr interface{}) bool {
+	typ := reflect.TypeOf(ptr)
+	if typ == nil {
+		return false
+	}
+
+	return hasFileFields(indirectType(typ))
+}
+
+func hasFileFields(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.Type == fileHeaderType || f.Type == fileHeadersType {
+			return true
+		}
+		if f.Anonymous && hasFileFields(indirectType(f.Type)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bindFormFiles sets the `*multipart.FileHeader` and `[]*multipart.FileHeader` fields of the "ptr" struct
+// from the uploaded files of the multipart form and validates them based on their `FormFileTagName` rules.
+// The files are matched by the field's `form` tag or its name.
+func bindFormFiles(ptr interface{}, files map[string][]*multipart.FileHeader) error {
+	v := reflect.ValueOf(ptr)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs ValidationErrors
+	if err := bindFormFileFields(v, files, &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func bindFormFileFields(v reflect.Value, files map[string][]*multipart.FileHeader, errs *ValidationErrors) error {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" && !f.Anonymous { // unexported.
+			continue
+		}
+
+		fv := v.Field(i)
+		if f.Anonymous {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() || !hasFileFields(indirectType(f.Type)) {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := bindFormFileFields(fv, files, errs); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if f.Type != fileHeaderType && f.Type != fileHeadersType {
+			continue
+		}
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("form"); ok {
+			if idx := strings.IndexByte(tag, ','); idx != -1 {
+				tag = tag[:idx]
+			}
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+
+		rules, err := parseFormFileRules(name, f.Tag.Get(FormFileTagName))
+		if err != nil {
+			return err
+		}
+		uploaded := files[name]
+		if len(uploaded) == 0 {
+			if rules.required {
+				*errs = append(*errs, ValidationError{Field: name, Tag: "required", Message: "the file '" + name + "' is required"})
+			}
+			continue
+		}
+
+		valid := true
+		for _, fh := range uploaded {
+			if rules.maxSize > 0 && fh.Size > rules.maxSize {
+				*errs = append(*errs, ValidationError{Field: name, Tag: "max",
+					Message: "the file '" + fh.Filename + "' exceeds the limit of " + strconv.FormatInt(rules.maxSize, 10) + " bytes"})
+				valid = false
+				continue
+			}
+
+			if len(rules.mimes) > 0 {
+				mimeType, err := detectFileMIME(fh)
+				if err != nil {
+					return err
+				}
+
+				if !rules.allowMIME(mimeType) {
+					*errs = append(*errs, ValidationError{Field: name, Tag: "mime",
+						Message: "the file '" + fh.Filename + "' of type '" + mimeType + "' is not allowed"})
+					valid = false
+				}
+			}
+		}
+
+		if !valid {
+			continue
+		}
+
+		if f.Type == fileHeaderType {
+			fv.Set(reflect.ValueOf(uploaded[0]))
+		} else {
+			fv.Set(reflect.ValueOf(uploaded))
+		}
+	}
+
+	return nil
+}
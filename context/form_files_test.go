@@ -0,0 +1,170 @@
+package context_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+// the first bytes of a png image, enough for the content sniffing.
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A" + strings.Repeat("\x00", 32))
+
+type upload struct {
+	Title  string                  `form:"title"`
+	Avatar *multipart.FileHeader   `form:"avatar" file:"required,max=1KB,mime=image/png"`
+	Docs   []*multipart.FileHeader `form:"docs" file:"max=64,mime=text/*"`
+}
+
+// readFormHandler sends the bound upload or the validation errors, field:tag separated by spaces.
+func readFormHandler(ctx iris.Context) {
+	var u upload
+	if err := ctx.ReadForm(&u); err != nil {
+		if errs, ok := err.(context.ValidationErrors); ok {
+			for _, e := range errs {
+				ctx.Writef("%s:%s ", e.Field, e.Tag)
+			}
+			return
+		}
+
+		ctx.StatusCode(iris.StatusInternalServerError)
+		ctx.WriteString(err.Error())
+		return
+	}
+
+	ctx.Writef("%s avatar=%s", u.Title, u.Avatar.Filename)
+	for _, doc := range u.Docs {
+		ctx.Writef(" doc=%s", doc.Filename)
+	}
+}
+
+func TestReadFormFiles(t *testing.T) {
+	app := iris.New()
+	app.Post("/", readFormHandler)
+
+	e := httptest.New(t, app)
+
+	e.POST("/").WithMultipart().WithFormField("title", "t").
+		WithFileBytes("avatar", "avatar.png", pngHeader).
+		WithFileBytes("docs", "a.txt", []byte("first")).
+		WithFileBytes("docs", "b.txt", []byte("second")).
+		Expect().Status(httptest.StatusOK).Body().Equal("t avatar=avatar.png doc=a.txt doc=b.txt")
+
+	// required, even without any form fields.
+	e.POST("/").WithMultipart().WithFileBytes("docs", "a.txt", []byte("first")).
+		Expect().Status(httptest.StatusOK).Body().Equal("avatar:required ")
+	e.POST("/").WithMultipart().WithFormField("title", "t").
+		Expect().Status(httptest.StatusOK).Body().Equal("avatar:required ")
+	e.POST("/").Expect().Status(httptest.StatusOK).Body().Equal("avatar:required ")
+
+	// max.
+	e.POST("/").WithMultipart().
+		WithFileBytes("avatar", "avatar.png", append(pngHeader, bytes.Repeat([]byte{0}, 1024)...)).
+		Expect().Status(httptest.StatusOK).Body().Equal("avatar:max ")
+	e.POST("/").WithMultipart().
+		WithFileBytes("avatar", "avatar.png", pngHeader).
+		WithFileBytes("docs", "a.txt", []byte("first")).
+		WithFileBytes("docs", "b.txt", bytes.Repeat([]byte("a"), 65)).
+		Expect().Status(httptest.StatusOK).Body().Equal("docs:max ")
+
+	// mime, detected from the contents.
+	e.POST("/").WithMultipart().
+		WithFileBytes("avatar", "avatar.png", []byte("not an image")).
+		WithFileBytes("docs", "a.txt", pngHeader).
+		Expect().Status(httptest.StatusOK).Body().Equal("avatar:mime docs:mime ")
+}
+
+func TestReadFormFilesInvalidRule(t *testing.T) {
+	app := iris.New()
+	app.Post("/", func(ctx iris.Context) {
+		var u struct {
+			Avatar *multipart.FileHeader `form:"avatar" file:"max=2M"`
+		}
+
+		if err := ctx.ReadForm(&u); err != nil {
+			if _, ok := err.(context.ValidationErrors); ok {
+				ctx.WriteString("validation error")
+				return
+			}
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.WriteString("no error")
+	})
+
+	e := httptest.New(t, app)
+	e.POST("/").WithMultipart().WithFileBytes("avatar", "avatar.png", pngHeader).
+		Expect().Status(httptest.StatusOK).Body().Equal("form file field 'avatar': invalid rule 'max=2M'")
+}
+
+func TestFormFileBytes(t *testing.T) {
+	app := iris.New()
+	app.Post("/", func(ctx iris.Context) {
+		contents, fh, contentType, err := ctx.FormFileBytes("file", 64)
+		if err != nil {
+			if context.ErrFormFileTooLarge.Equal(err) {
+				ctx.StatusCode(iris.StatusRequestEntityTooLarge)
+			} else {
+				ctx.StatusCode(iris.StatusBadRequest)
+			}
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.Writef("%s %d %s", fh.Filename, fh.Size, contentType)
+		if contentType == "text/plain; charset=utf-8" {
+			ctx.Writef(" %s", contents)
+		}
+	})
+	app.Post("/parsed", func(ctx iris.Context) {
+		// the form is parsed before, the file is read from the parsed form.
+		title := ctx.FormValue("title")
+		_, fh, contentType, err := ctx.FormFileBytes("file", 64)
+		if err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.Writef("%s %s %s", title, fh.Filename, contentType)
+	})
+
+	e := httptest.New(t, app)
+
+	// the type is sniffed from the contents, not from the client's Content-Type or the file's extension.
+	e.POST("/").WithMultipart().
+		WithFormField("other", "skipped").
+		WithFileBytes("other", "other.png", pngHeader).
+		WithFileBytes("file", "avatar.txt", pngHeader).
+		Expect().Status(iris.StatusOK).Body().Equal("avatar.txt 40 image/png")
+	e.POST("/").WithMultipart().
+		WithFileBytes("file", "a.bin", []byte("hello")).
+		Expect().Status(iris.StatusOK).Body().Equal("a.bin 5 text/plain; charset=utf-8 hello")
+
+	// the size cap, exactly the limit is allowed.
+	e.POST("/").WithMultipart().
+		WithFileBytes("file", "a.txt", bytes.Repeat([]byte("a"), 64)).
+		Expect().Status(iris.StatusOK).Body().Contains("a.txt 64 text/plain")
+	e.POST("/").WithMultipart().
+		WithFileBytes("file", "a.txt", bytes.Repeat([]byte("a"), 65)).
+		Expect().Status(iris.StatusRequestEntityTooLarge).Body().Equal(context.ErrFormFileTooLarge.Format("a.txt", 64).Error())
+
+	e.POST("/").WithMultipart().WithFormField("file", "not a file").
+		Expect().Status(iris.StatusBadRequest).Body().Equal(http.ErrMissingFile.Error())
+	e.POST("/").WithFormField("file", "not multipart").
+		Expect().Status(iris.StatusBadRequest).Body().Equal(http.ErrNotMultipart.Error())
+
+	e.POST("/parsed").WithMultipart().
+		WithFormField("title", "t").
+		WithFileBytes("file", "avatar.png", pngHeader).
+		Expect().Status(iris.StatusOK).Body().Equal("t avatar.png image/png")
+	e.POST("/parsed").WithMultipart().WithFormField("title", "t").
+		WithFileBytes("file", "a.txt", bytes.Repeat([]byte("a"), 65)).
+		Expect().Status(iris.StatusBadRequest).Body().Equal(context.ErrFormFileTooLarge.Format("a.txt", 64).Error())
+}
@@ -125,6 +125,9 @@ func (w *GzipResponseWriter) EndResponse() {
 // underline response writer, returns the uncompressed len(contents).
 // 每次写入都放在 chunks 中，返回是未压缩的长度
 func (w *GzipResponseWriter) Write(contents []byte) (int, error) {
+	if err := accountBody(w.ResponseWriter, len(contents)); err != nil {
+		return 0, err
+	}
 	// save the contents to serve them (only gzip data here)
 	w.chunks = append(w.chunks, contents...)
 	return len(contents), nil
@@ -168,6 +171,23 @@ func (w *GzipResponseWriter) WriteString(s string) (n int, err error) {
 // 这里是压缩响应数据的地方，返回压缩后的数据长度
 // 使用 FlushResponse() 之前我们要知道响应压缩后的大小，而且使用压缩后就不能再传输新的协议头字段
 func (w *GzipResponseWriter) WriteNow(contents []byte) (int, error) {
+	if err := accountBody(w.ResponseWriter, len(contents)); err != nil {
+		return 0, err
+	}
+
+	return w.writeNow(contents)
+}
+
+// writeNow is the `WriteNow` without counting the "contents" against the response body limit,
+// they're counted already.
+func (w *GzipResponseWriter) writeNow(contents []byte) (n int, err error) {
+	passthrough(w.ResponseWriter, func() {
+		n, err = w.writeNowUncounted(contents)
+	})
+	return
+}
+
+func (w *GzipResponseWriter) writeNowUncounted(contents []byte) (int, error) {
 	// 如果是关闭的 ，则直接用底层的 ResponseWriter 来响应数据
 	if w.disabled {
 		// type noOp struct{}
@@ -214,12 +234,14 @@ func AddGzipHeaders(w ResponseWriter) {
 // and writes the data to the underline ResponseWriter.
 // 把GzipResponseWriter所有的缓存的数据写入响应流，并完成底层ResponseWriter所需要的方法回调
 func (w *GzipResponseWriter) FlushResponse() {
-	w.WriteNow(w.chunks)
+	w.writeNow(w.chunks)
 	w.ResponseWriter.FlushResponse()
 }
 
-// ResetBody resets the response body.
+// ResetBody resets the response body,
+// the discarded bytes are not counted by the response body limit anymore.
 func (w *GzipResponseWriter) ResetBody() {
+	releaseBody(w.ResponseWriter, int64(len(w.chunks)))
 	w.chunks = w.chunks[0:0]
 }
 
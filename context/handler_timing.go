@@ -0,0 +1,101 @@
+package context
+
+import (
+	"strings"
+	"time"
+)
+
+// HandlerTiming describes the time spent on a single handler of a request,
+// the time spent on the next handlers, called through `ctx.Next()`, is not included.
+type HandlerTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// handlersTiming keeps the timing breakdown of a request,
+// stored to the context's values by the `TimeHandler`.
+type handlersTiming struct {
+	entries []HandlerTiming
+	// the name of the handler that is currently executed.
+	current string
+	// the total time spent on the nested handlers of the current one.
+	nested time.Duration
+	depth  int
+}
+
+// handlersTimingContextKey is the context's values key of the handlers timing breakdown.
+const handlersTimingContextKey = "iris.handlersTiming"
+
+func getHandlersTiming(ctx Context) *handlersTiming {
+	if t, ok := ctx.Values().Get(handlersTimingContextKey).(*handlersTiming); ok {
+		return t
+	}
+
+	return nil
+}
+
+// GetHandlersTiming returns the time spent on each handler of the current request so far,
+// by execution order. It returns nil if handler timing is not enabled,
+// see the `iris#WithHandlerTiming` configurator.
+func GetHandlersTiming(ctx Context) []HandlerTiming {
+	if t := getHandlersTiming(ctx); t != nil {
+		return t.entries
+	}
+
+	return nil
+}
+
+// TimeHandler wraps a handler in order to measure the time spent on it,
+// the result is available through the `GetHandlersTiming`.
+// When the outer handler of the chain is done and the total time
+// is greater than the `Configuration#SlowRequestThreshold` (if not zero),
+// a warning with the breakdown is logged.
+//
+// The router wraps all routes' handlers when the `Configuration#EnableHandlerTiming` is true,
+// `ctx.HandlerName()` still reports the original handler's name.
+// 记录每个handler的执行时间，超过阈值的请求会打印warn日志
+func TimeHandler(h Handler) Handler {
+	name := HandlerName(h)
+
+	return func(ctx Context) {
+		t := getHandlersTiming(ctx)
+		if t == nil {
+			t = new(handlersTiming)
+			ctx.Values().Set(handlersTimingContextKey, t)
+		}
+
+		prevCurrent, prevNested := t.current, t.nested
+		t.current, t.nested = name, 0
+		// reserve the entry's position, the nested handlers are appended after it.
+		idx := len(t.entries)
+		t.entries = append(t.entries, HandlerTiming{Name: name})
+		t.depth++
+
+		start := time.Now()
+		h(ctx)
+		elapsed := time.Since(start)
+
+		t.depth--
+		t.entries[idx].Duration = elapsed - t.nested
+		t.current, t.nested = prevCurrent, prevNested+elapsed
+
+		if t.depth == 0 {
+			logSlowRequest(ctx, t, elapsed)
+		}
+	}
+}
+
+func logSlowRequest(ctx Context, t *handlersTiming, total time.Duration) {
+	threshold := ctx.Application().ConfigurationReadOnly().GetSlowRequestThreshold()
+	if threshold <= 0 || total < threshold {
+		return
+	}
+
+	breakdown := make([]string, len(t.entries))
+	for i, entry := range t.entries {
+		breakdown[i] = entry.Name + "=" + entry.Duration.String()
+	}
+
+	ctx.Application().Logger().Warnf("slow request: %s %s took %s (threshold %s); handlers: %s",
+		ctx.Method(), ctx.Path(), total, threshold, strings.Join(breakdown, ", "))
+}
@@ -0,0 +1,261 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kataras/golog"
+	"github.com/kataras/iris/core/errors"
+)
+
+// headerGuard wraps the underline, naive, http.ResponseWriter when the
+// `Configuration#DetectHeaderMutations` is enabled (development mode).
+//
+// The native http.ResponseWriter silently drops any header change
+// after the headers were written to the client,
+// the guard keeps a copy of the sent headers and compares them
+// with the final headers at the end of the request,
+// it reports the handlers that touched the headers after they were sent
+// and any access from a goroutine other than the request's one.
+//
+// The request's path, route and current handler are captured by the request's goroutine,
+// see `ExecuteHandler`, the accesses of the other goroutines read only them,
+// as the Context may be already released and serving another request.
+// The goroutine of each access is resolved through the `runtime.Stack`, that's why it's used on development mode only.
+// 开发模式下使用，用来检查header被发送之后的修改(会被丢弃)以及其他goroutine对header的访问
+type headerGuard struct {
+	http.ResponseWriter
+	logger *golog.Logger
+	// the goroutine id of the request, the one which called the `BeginRequest`.
+	goroutine uint64
+	path      string
+
+	mu sync.Mutex
+	// the route's name and the handler that is currently executed, captured by the request's goroutine.
+	route   string
+	handler string
+	// true after the end of the request, the headers are not accessible anymore.
+	released bool
+	// a copy of the headers at the time they were sent to the client, nil if not sent yet.
+	sent http.Header
+	// the handlers that accessed the headers after they were sent.
+	lateHandlers []string
+}
+
+func newHeaderGuard(ctx Context, w http.ResponseWriter) *headerGuard {
+	return &headerGuard{
+		ResponseWriter: w,
+		logger:         ctx.Application().Logger(),
+		goroutine:      goroutineID(),
+		path:           ctx.Path(),
+		handler:        "<unknown>",
+	}
+}
+
+// headerGuardOf returns the header guard of the "ctx", nil if the `Configuration#DetectHeaderMutations` is disabled.
+func headerGuardOf(ctx Context) *headerGuard {
+	w := ctx.ResponseWriter()
+	if w == nil {
+		return nil
+	}
+
+	g, _ := unwrapBandwidthTracker(w.Naive()).(*headerGuard)
+	return g
+}
+
+// goroutineID returns the current goroutine's id,
+// it's slow, that's why it's used on development mode only.
+func goroutineID() uint64 {
+	b := make([]byte, 64)
+	b = b[:runtime.Stack(b, false)]
+	// "goroutine 18 [running]:..."
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// enter captures the route and the handler which is about to be executed by the request's goroutine
+// and returns the previous handler, which is restored by the `leave` when the handler returns.
+func (g *headerGuard) enter(routeName, handlerName string) (previous string) {
+	g.mu.Lock()
+	previous = g.handler
+	g.route, g.handler = routeName, handlerName
+	g.mu.Unlock()
+	return
+}
+
+func (g *headerGuard) leave(handlerName string) {
+	g.mu.Lock()
+	g.handler = handlerName
+	g.mu.Unlock()
+}
+
+func (g *headerGuard) describe() string {
+	if g.route != "" {
+		return "'" + g.path + "' (route: " + g.route + ")"
+	}
+
+	return "'" + g.path + "'"
+}
+
+// Header returns the header map that will be sent by WriteHeader.
+func (g *headerGuard) Header() http.Header {
+	id := goroutineID()
+
+	g.mu.Lock()
+	handler, where, released := g.handler, g.describe(), g.released
+	if g.sent != nil && !released {
+		g.lateHandlers = appendUniqueString(g.lateHandlers, handler)
+	}
+	g.mu.Unlock()
+
+	if released {
+		g.logger.Warnf("header guard: response headers of %s accessed from goroutine %d after the end of the request",
+			where, id)
+		// the response is done, the changes are dropped.
+		return make(http.Header)
+	}
+
+	if id != g.goroutine {
+		g.logger.Warnf("header guard: response headers of %s accessed from goroutine %d instead of the request's goroutine %d by handler: %s",
+			where, id, g.goroutine, handler)
+	}
+
+	return g.ResponseWriter.Header()
+}
+
+// markSent keeps a copy of the headers, once, before they are written to the client.
+func (g *headerGuard) markSent() {
+	g.mu.Lock()
+	if g.sent == nil {
+		h := g.ResponseWriter.Header()
+		g.sent = make(http.Header, len(h))
+		for k, v := range h {
+			g.sent[k] = append([]string(nil), v...)
+		}
+	}
+	g.mu.Unlock()
+}
+
+// WriteHeader sends an HTTP response header with the provided status code.
+func (g *headerGuard) WriteHeader(statusCode int) {
+	g.markSent()
+	g.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the data to the connection as part of an HTTP reply.
+func (g *headerGuard) Write(contents []byte) (int, error) {
+	g.markSent()
+	return g.ResponseWriter.Write(contents)
+}
+
+// Flush sends any buffered data to the client.
+func (g *headerGuard) Flush() {
+	if flusher, ok := g.ResponseWriter.(http.Flusher); ok {
+		g.markSent()
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection.
+func (g *headerGuard) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := g.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+
+	return nil, nil, errors.New("hijack is not supported by this ResponseWriter")
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (g *headerGuard) CloseNotify() <-chan bool {
+	if n, ok := g.ResponseWriter.(http.CloseNotifier); ok {
+		return n.CloseNotify()
+	}
+
+	return nil
+}
+
+// Push initiates an HTTP/2 server push.
+func (g *headerGuard) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := g.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+
+	return http.ErrNotSupported
+}
+
+// end compares the sent headers with the final ones
+// and logs a warning for any header that was modified after it was sent.
+// Called by the `EndRequest`, after the response was flushed.
+func (g *headerGuard) end() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.sent == nil {
+		return
+	}
+
+	var changed []string
+	current := g.ResponseWriter.Header()
+	for k, v := range current {
+		if !equalStrings(g.sent[k], v) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range g.sent {
+		if _, ok := current[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+
+	if len(changed) == 0 {
+		return
+	}
+
+	sort.Strings(changed)
+	g.logger.Warnf("header guard: response header(s) [%s] of %s modified after they were sent to the client, the changes were dropped; handler(s): %s",
+		strings.Join(changed, ", "), g.describe(), strings.Join(g.lateHandlers, ", "))
+}
+
+// release marks the end of the request, the later accesses of the headers are reported
+// and they don't reach the underline http.ResponseWriter.
+func (g *headerGuard) release() {
+	g.mu.Lock()
+	g.released = true
+	g.mu.Unlock()
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func appendUniqueString(s []string, v string) []string {
+	for _, existing := range s {
+		if existing == v {
+			return s
+		}
+	}
+
+	return append(s, v)
+}
@@ -0,0 +1,185 @@
+package context
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// JSONError is the structured error that the `Context#ReadJSON` returns when the request body
+// is not a valid JSON or a value does not match the type of its field,
+// it can be sent to the client as a 400 problem details response through its `Problem`.
+//
+// It can be disabled through the `Configuration.DisableJSONErrorDetails`,
+// the errors of the JSON decoder are returned as they're then.
+//
+// Usage:
+// if jsonErr, ok := err.(*context.JSONError); ok { ctx.Problem(jsonErr.Problem()); return }
+type JSONError struct {
+	// Offset is the zero-based byte offset of the body where the error occurred.
+	Offset int64 `json:"offset" xml:"offset" yaml:"Offset"`
+	// Line and Column are the one-based position of the Offset.
+	Line   int `json:"line" xml:"line" yaml:"Line"`
+	Column int `json:"column" xml:"column" yaml:"Column"`
+	// Field is the path of the field that failed or the nearest one before the Offset,
+	// i.e "user.address.zip", empty if the error is not inside an object.
+	Field string `json:"field,omitempty" xml:"field,omitempty" yaml:"Field"`
+	// Expected is the Go type of the field on a type mismatch, i.e "int".
+	Expected string `json:"expected,omitempty" xml:"expected,omitempty" yaml:"Expected"`
+	// Value is the description of the JSON value on a type mismatch, i.e "string".
+	Value string `json:"value,omitempty" xml:"value,omitempty" yaml:"Value"`
+	// Message is the message of the JSON decoder's error,
+	// or of the encoding/json's one when the json-iterator is used.
+	Message string `json:"message" xml:"message" yaml:"Message"`
+	// Err is the JSON decoder's error, i.e a *json.SyntaxError or a *json.UnmarshalTypeError,
+	// or the json-iterator's one.
+	Err error `json:"-" xml:"-" yaml:"-"`
+}
+
+// Error returns the message of the JSON decoder's error.
+func (e *JSONError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the JSON decoder's error.
+func (e *JSONError) Unwrap() error {
+	return e.Err
+}
+
+// Problem returns a 400 Bad Request problem details of the error, see `Context#Problem`,
+// the position, the field and the type mismatch details are its extension members.
+func (e *JSONError) Problem() Problem {
+	p := NewProblem().Status(http.StatusBadRequest).Title("Invalid JSON body").Detail(e.Message).
+		Key("offset", e.Offset).Key("line", e.Line).Key("column", e.Column)
+
+	if e.Field != "" {
+		p.Key("field", e.Field)
+	}
+	if e.Expected != "" {
+		p.Key("expected", e.Expected)
+	}
+	if e.Value != "" {
+		p.Key("value", e.Value)
+	}
+
+	return p
+}
+
+// newJSONError returns a `JSONError` of the "err" of the JSON decoder for the "body" and the "outPtr",
+// the errors which are not about the body, i.e a non-pointer value, are returned as they're.
+//
+// The json-iterator's errors (see `Configuration.EnableOptimizations`) have no exact position,
+// so the body is decoded again by the encoding/json to a new value of the "outPtr" type in order to find it.
+// 将json解码错误转换为包含位置以及字段信息的结构化错误
+func newJSONError(err error, body []byte, outPtr interface{}) error {
+	e := &JSONError{Message: err.Error(), Err: err}
+
+	decodeErr := err
+	switch err.(type) {
+	case *json.SyntaxError, *json.UnmarshalTypeError:
+	default:
+		if typ := reflect.TypeOf(outPtr); typ != nil && typ.Kind() == reflect.Ptr {
+			if stdErr := json.Unmarshal(body, reflect.New(typ.Elem()).Interface()); stdErr != nil {
+				decodeErr = stdErr
+				e.Message = stdErr.Error()
+			}
+		}
+	}
+
+	switch v := decodeErr.(type) {
+	case *json.SyntaxError:
+		// the offending byte is counted by the decoder.
+		e.Offset = v.Offset - 1
+	case *json.UnmarshalTypeError:
+		e.Offset = v.Offset
+		e.Field = v.Field
+		e.Value = v.Value
+		if v.Type != nil {
+			e.Expected = v.Type.String()
+		}
+	default:
+		return err
+	}
+
+	if e.Offset < 0 {
+		e.Offset = 0
+	} else if e.Offset > int64(len(body)) {
+		e.Offset = int64(len(body))
+	}
+
+	prefix := body[:e.Offset]
+	e.Line = bytes.Count(prefix, []byte{'\n'}) + 1
+	e.Column = int(e.Offset) - (bytes.LastIndexByte(prefix, '\n') + 1) + 1
+
+	if e.Field == "" {
+		e.Field = nearestJSONField(body, e.Offset)
+	}
+
+	return e
+}
+
+// nearestJSONField returns the path of the last object key which is read before the "offset" of the "body",
+// the array indexes are omitted, i.e "items.price".
+func nearestJSONField(body []byte, offset int64) string {
+	type scope struct {
+		object bool
+		key    string
+		isKey  bool // true when the next string token is a key.
+	}
+
+	var (
+		stack []*scope
+		field string
+	)
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for dec.InputOffset() < offset {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+
+		var top *scope
+		if n := len(stack); n > 0 {
+			top = stack[n-1]
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				stack = append(stack, &scope{object: t == '{', isKey: t == '{'})
+				continue
+			default:
+				stack = stack[:len(stack)-1]
+				if n := len(stack); n > 0 && stack[n-1].object {
+					stack[n-1].isKey = true
+				}
+				continue
+			}
+		case string:
+			if top != nil && top.object && top.isKey {
+				top.key = t
+				top.isKey = false
+
+				keys := make([]string, 0, len(stack))
+				for _, s := range stack {
+					if s.object && s.key != "" {
+						keys = append(keys, s.key)
+					}
+				}
+				field = strings.Join(keys, ".")
+				continue
+			}
+		}
+
+		// a value is read.
+		if top != nil && top.object {
+			top.isKey = true
+		}
+	}
+
+	return field
+}
@@ -0,0 +1,266 @@
+package context
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+const (
+	// AcceptHeaderKey is the header key of "Accept".
+	AcceptHeaderKey = "Accept"
+	// AcceptCharsetHeaderKey is the header key of "Accept-Charset".
+	AcceptCharsetHeaderKey = "Accept-Charset"
+	// ContentXMLUnreadableHeaderValue is the "application/xml" header value for XML data,
+	// the `Context#Negotiate` renders it like the `ContentXMLHeaderValue`.
+	ContentXMLUnreadableHeaderValue = "application/xml"
+
+	// NegotiationOffersContextKey is the context's values key of the content types
+	// offered by the current route, see `NegotiationOffers`.
+	NegotiationOffersContextKey = "iris.negotiation.offers"
+)
+
+// DefaultNegotiationOffers are the content types that `Context#Negotiate` offers by default,
+// in order of the server's preference.
+var DefaultNegotiationOffers = []string{
+	ContentJSONHeaderValue,
+	ContentXMLHeaderValue,
+	ContentXMLUnreadableHeaderValue,
+	ContentYAMLHeaderValue,
+	ContentHTMLHeaderValue,
+	ContentMarkdownHeaderValue,
+	ContentTextHeaderValue,
+}
+
+// ErrNotAcceptable is returned by the `Context#Negotiate` when none of the offered
+// content types is acceptable by the client, the status code is set to 406 as well.
+var ErrNotAcceptable = errors.New("not acceptable")
+
+// NegotiationOffers returns a Handler which sets the content types
+// that the `Context#Negotiate` of the next handlers offers,
+// useful to override the `DefaultNegotiationOffers` per route or per party.
+//
+// Usage: app.Get("/users/{id}", NegotiationOffers("application/json", "text/xml"), getUser)
+var NegotiationOffers = func(offers ...string) Handler {
+	return func(ctx Context) {
+		ctx.Values().Set(NegotiationOffersContextKey, offers)
+		ctx.Next()
+	}
+}
+
+type acceptedMediaType struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept parses the "Accept" request header's value to its media ranges and their q-values.
+func parseAccept(accept string) (ranges []acceptedMediaType) {
+	for _, part := range strings.Split(accept, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		mediaType, q := part, 1.0
+		if idx := strings.IndexByte(part, ';'); idx >= 0 {
+			mediaType = strings.TrimSpace(part[:idx])
+			for _, param := range strings.Split(part[idx+1:], ";") {
+				param = strings.TrimSpace(param)
+				if len(param) > 2 && (param[0] == 'q' || param[0] == 'Q') && param[1] == '=' {
+					if v, err := strconv.ParseFloat(param[2:], 64); err == nil && v >= 0 && v <= 1 {
+						q = v
+					} else {
+						q = 0
+					}
+				}
+			}
+		}
+
+		typ, subtype := mediaType, "*"
+		if idx := strings.IndexByte(mediaType, '/'); idx >= 0 {
+			typ, subtype = mediaType[:idx], mediaType[idx+1:]
+		}
+
+		ranges = append(ranges, acceptedMediaType{strings.ToLower(typ), strings.ToLower(subtype), q})
+	}
+
+	return
+}
+
+// mediaTypeQuality returns the quality value of the "offer" based on the most specific
+// matched media range, see https://tools.ietf.org/html/rfc7231#section-5.3.2.
+func mediaTypeQuality(ranges []acceptedMediaType, offer string) float64 {
+	offer = strings.ToLower(offer)
+	typ, subtype := offer, ""
+	if idx := strings.IndexByte(offer, '/'); idx >= 0 {
+		typ, subtype = offer[:idx], offer[idx+1:]
+	}
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		}
+
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+
+	return q
+}
+
+// NegotiateMediaType returns the most preferable, by the client, content type
+// of the "offers" based on the "accept" request header's value and its q-values,
+// the more specific media ranges override the less specific ones, i.e "text/*;q=0.5, text/html".
+// The order of the "offers" is used as the server's preference on equal q-values.
+//
+// It returns an empty string if none of the offers is acceptable.
+// If "accept" is empty then the first offer is returned.
+func NegotiateMediaType(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(accept)
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := mediaTypeQuality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// AcceptsCharset reports whether the "charset" is acceptable
+// based on the "acceptCharset" request header's value and its q-values.
+// If "acceptCharset" is empty then any charset is acceptable.
+func AcceptsCharset(acceptCharset string, charset string) bool {
+	if strings.TrimSpace(acceptCharset) == "" {
+		return true
+	}
+
+	// same syntax as the "Accept-Encoding".
+	qualities := parseAcceptEncoding(acceptCharset)
+	if q, ok := qualities[strings.ToLower(charset)]; ok {
+		return q > 0
+	}
+
+	return qualities["*"] > 0
+}
+
+// canRender reports whether the "v" can be rendered as "contentType",
+// the text-based content types accept only string values.
+func canRender(contentType string, v interface{}) bool {
+	switch contentType {
+	case ContentHTMLHeaderValue, ContentMarkdownHeaderValue, ContentTextHeaderValue:
+		switch v.(type) {
+		case string, []byte, fmt.Stringer:
+			return true
+		}
+		return false
+	}
+
+	return true
+}
+
+func textOf(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case []byte:
+		return string(s)
+	case fmt.Stringer:
+		return s.String()
+	}
+
+	return ""
+}
+
+// Negotiate renders the "v" with the most preferable, by the client, content type
+// based on the "Accept", "Accept-Charset" and "Accept-Encoding" request headers.
+// See the `Context` interface's documentation for more.
+func (ctx *context) Negotiate(v interface{}, offers ...string) (int, error) {
+	if len(offers) == 0 {
+		if routeOffers, ok := ctx.values.Get(NegotiationOffersContextKey).([]string); ok && len(routeOffers) > 0 {
+			offers = routeOffers
+		} else {
+			offers = DefaultNegotiationOffers
+		}
+	}
+
+	renderable := make([]string, 0, len(offers))
+	for _, offer := range offers {
+		if canRender(offer, v) {
+			renderable = append(renderable, offer)
+		}
+	}
+
+	gzip := ctx.NegotiateEncoding(GzipHeaderValue, IdentityEncoding) == GzipHeaderValue
+
+	// the response varies based on these request headers,
+	// the "Accept-Encoding" is added by the gzip writer when the response is compressed.
+	vary := []string{AcceptHeaderKey, AcceptCharsetHeaderKey}
+	if !gzip {
+		vary = append(vary, AcceptEncodingHeaderKey)
+	}
+	ctx.writer.Header().Add(VaryHeaderKey, strings.Join(vary, ", "))
+
+	// the response has a single charset, the configured one, if the "Accept-Charset"
+	// does not accept it then the header is disregarded instead of a 406, see RFC 7231 section 5.3.3.
+	// 字符集不匹配时忽略 Accept-Charset 请求头, 而不是返回 406
+	contentType := NegotiateMediaType(ctx.GetHeader(AcceptHeaderKey), renderable...)
+	if contentType == "" {
+		ctx.StatusCode(http.StatusNotAcceptable)
+		return 0, ErrNotAcceptable
+	}
+
+	if gzip {
+		ctx.Gzip(true)
+	}
+
+	switch contentType {
+	case ContentJSONHeaderValue:
+		return ctx.JSON(v)
+	case ContentXMLHeaderValue:
+		return ctx.XML(v)
+	case ContentXMLUnreadableHeaderValue:
+		ctx.ContentType(ContentXMLUnreadableHeaderValue)
+		n, err := WriteXML(ctx.writer, v, DefaultXMLOptions)
+		if err != nil {
+			ctx.StatusCode(http.StatusInternalServerError)
+			return 0, err
+		}
+		return n, nil
+	case ContentYAMLHeaderValue:
+		return ctx.YAML(v)
+	case ContentHTMLHeaderValue:
+		return ctx.HTML(textOf(v))
+	case ContentMarkdownHeaderValue:
+		return ctx.Markdown([]byte(textOf(v)))
+	case ContentTextHeaderValue:
+		return ctx.Text(textOf(v))
+	}
+
+	// custom content type, the "v" should be the raw body.
+	ctx.ContentType(contentType)
+	if b, ok := v.([]byte); ok {
+		return ctx.Write(b)
+	}
+	return ctx.WriteString(textOf(v))
+}
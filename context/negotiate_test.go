@@ -0,0 +1,99 @@
+package context_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestNegotiateMediaType(t *testing.T) {
+	offers := []string{context.ContentJSONHeaderValue, context.ContentXMLHeaderValue, context.ContentHTMLHeaderValue}
+
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		// any, the server's preference.
+		{"", context.ContentJSONHeaderValue},
+		{"*/*", context.ContentJSONHeaderValue},
+		{"text/xml", context.ContentXMLHeaderValue},
+		{"text/xml;q=0.5, text/html", context.ContentHTMLHeaderValue},
+		// the more specific range wins over the q-value of the wildcard.
+		{"text/*;q=0.9, text/html;q=0.1", context.ContentXMLHeaderValue},
+		{"application/json;q=0, */*", context.ContentXMLHeaderValue},
+		{"image/png", ""},
+	}
+
+	for i, tt := range tests {
+		if got := context.NegotiateMediaType(tt.accept, offers...); got != tt.expected {
+			t.Fatalf("[%d] expected %q of the %q but got %q", i, tt.expected, tt.accept, got)
+		}
+	}
+}
+
+func TestAcceptsCharset(t *testing.T) {
+	tests := []struct {
+		acceptCharset string
+		expected      bool
+	}{
+		{"", true},
+		{"utf-8", true},
+		{"UTF-8;q=0.5, iso-8859-1", true},
+		{"iso-8859-1, *;q=0.1", true},
+		{"iso-8859-1", false},
+		{"utf-8;q=0, *", false},
+	}
+
+	for i, tt := range tests {
+		if got := context.AcceptsCharset(tt.acceptCharset, "UTF-8"); got != tt.expected {
+			t.Fatalf("[%d] expected %t of the %q but got %t", i, tt.expected, tt.acceptCharset, got)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	type user struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	app := iris.New()
+	app.Get("/user", func(ctx iris.Context) {
+		ctx.Negotiate(user{Name: "iris"})
+	})
+	app.Get("/text", func(ctx iris.Context) {
+		ctx.Negotiate("iris")
+	})
+	app.Get("/offers", context.NegotiationOffers(context.ContentXMLHeaderValue), func(ctx iris.Context) {
+		ctx.Negotiate(user{Name: "iris"})
+	})
+
+	e := httptest.New(t, app)
+
+	r := e.GET("/user").Expect().Status(httptest.StatusOK)
+	r.ContentType(context.ContentJSONHeaderValue)
+	r.JSON().Object().Equal(map[string]string{"name": "iris"})
+	r.Header(context.VaryHeaderKey).Equal("Accept, Accept-Charset, Accept-Encoding")
+
+	e.GET("/user").WithHeader("Accept", "text/xml, application/json;q=0.5").Expect().Status(httptest.StatusOK).
+		ContentType(context.ContentXMLHeaderValue).Body().Contains("<name>iris</name>")
+
+	// the text-based content types are offered only to the strings.
+	e.GET("/user").WithHeader("Accept", "text/plain").Expect().Status(httptest.StatusNotAcceptable).
+		Header(context.VaryHeaderKey).Equal("Accept, Accept-Charset, Accept-Encoding")
+	e.GET("/text").WithHeader("Accept", "text/plain").Expect().Status(httptest.StatusOK).
+		ContentType(context.ContentTextHeaderValue).Body().Equal("iris")
+
+	// the route's offers.
+	e.GET("/offers").Expect().Status(httptest.StatusOK).ContentType(context.ContentXMLHeaderValue)
+	e.GET("/offers").WithHeader("Accept", "application/json").Expect().Status(httptest.StatusNotAcceptable)
+
+	// the charset is disregarded instead of a 406.
+	e.GET("/user").WithHeader("Accept-Charset", "iso-8859-1").Expect().Status(httptest.StatusOK).
+		ContentType(context.ContentJSONHeaderValue, "UTF-8")
+
+	r = e.GET("/user").WithHeader("Accept-Encoding", "gzip").Expect().Status(httptest.StatusOK)
+	r.Header(context.ContentEncodingHeaderKey).Equal(context.GzipHeaderValue)
+	r.Headers().Value(context.VaryHeaderKey).Array().Equal([]string{"Accept, Accept-Charset", "Accept-Encoding"})
+}
@@ -0,0 +1,26 @@
+package context
+
+import "time"
+
+// OAuthUserContextKey is the context's values key of the logged in user, see `oauth.User`.
+const OAuthUserContextKey = "iris.oauth.user"
+
+// OAuthUser is the verified identity of a user logged in through an OAuth2/OpenID Connect provider,
+// it's stored to the Context by the `auth/oauth` module.
+type OAuthUser struct {
+	// Provider is the name of the provider, i.e "google".
+	Provider string `json:"provider"`
+	// ID is the unique identifier of the user at the provider, the "sub" claim of OpenID Connect.
+	ID        string `json:"id"`
+	Email     string `json:"email,omitempty"`
+	Name      string `json:"name,omitempty"`
+	AvatarURL string `json:"avatar,omitempty"`
+
+	// The fields below are available only on the login (callback) request, they are not kept between requests.
+
+	// Claims are the claims of the ID token and the user info response.
+	Claims       Claims    `json:"-"`
+	AccessToken  string    `json:"-"`
+	RefreshToken string    `json:"-"`
+	Expiry       time.Time `json:"-"`
+}
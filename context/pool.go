@@ -3,12 +3,17 @@ package context
 import (
 	"net/http"
 	"sync"
+	"sync/atomic"
 )
 
 // Pool is the context pool, it's used inside router and the framework by itself.
 //
 // It's the only one real implementation inside this package because it used widely.
 type Pool struct {
+	// active is the number of the acquired and not released yet contexts, accessed atomically.
+	// It's the first field so it's 64-bit aligned on the 32-bit platforms too.
+	active int64
+
 	// 问题:这里是从原生的sync.Pool的作用？
 	// 解答:这里可以看pool的作用，可以看pool.go红Acquire的效果（核心部分是通过给与的newFunc使用的），即本质的池功能靠原生的sync.Pool保证
 	// todo 看原生的sync.Pool的源码
@@ -38,8 +43,15 @@ func New(newFunc func() Context) *Pool {
 // Example: https://github.com/kataras/iris/blob/master/_examples/routing/custom-context/method-overriding/main.go
 // 问题:要理解为啥这样改是可以的?这个问题等待context差不多了再回来看？
 // 解答:可以返回自己想要的Context结构
+//
+// The contexts of the previous function, if any, are not reused. Should be called before serving.
 func (c *Pool) Attach(newFunc func() Context) {
+	if newFunc == nil {
+		return
+	}
+
 	c.newFunc = newFunc
+	c.pool = &sync.Pool{New: func() interface{} { return c.newFunc() }}
 }
 
 // Acquire returns a Context from pool.
@@ -47,6 +59,7 @@ func (c *Pool) Attach(newFunc func() Context) {
 // 这里从原生的sync.Pool总获取参数，然后调用beginRequest来进行数据的清理和赋值
 func (c *Pool) Acquire(w http.ResponseWriter, r *http.Request) Context {
 	ctx := c.pool.Get().(Context)
+	atomic.AddInt64(&c.active, 1)
 	ctx.BeginRequest(w, r)
 	return ctx
 }
@@ -55,6 +68,7 @@ func (c *Pool) Acquire(w http.ResponseWriter, r *http.Request) Context {
 // See Acquire.
 func (c *Pool) Release(ctx Context) {
 	ctx.EndRequest()
+	atomic.AddInt64(&c.active, -1)
 	c.pool.Put(ctx)
 }
 
@@ -62,5 +76,13 @@ func (c *Pool) Release(ctx Context) {
 // clean method is caller's responsibility now, currently this is only used
 // on `SPABuilder`.
 func (c *Pool) ReleaseLight(ctx Context) {
+	atomic.AddInt64(&c.active, -1)
 	c.pool.Put(ctx)
 }
+
+// ActiveRequests returns the number of the in-flight requests,
+// the contexts which are acquired and not released yet.
+// It implements the `host.RequestCounter`, see `Supervisor#Shutdown`.
+func (c *Pool) ActiveRequests() int64 {
+	return atomic.LoadInt64(&c.active)
+}
@@ -0,0 +1,156 @@
+package context
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const (
+	// ContentProblemJSONHeaderValue header value for RFC 7807 problem details in JSON.
+	ContentProblemJSONHeaderValue = "application/problem+json"
+	// ContentProblemXMLHeaderValue header value for RFC 7807 problem details in XML.
+	ContentProblemXMLHeaderValue = "application/problem+xml"
+)
+
+// Problem Details for HTTP APIs, a machine-readable format of an error, see https://tools.ietf.org/html/rfc7807.
+// The standard members are the "type", "title", "status", "detail" and "instance",
+// any other key is an extension member.
+//
+// Create a new one with the `NewProblem` and render it through the `Context#Problem`.
+// RFC 7807 错误响应格式
+type Problem map[string]interface{}
+
+// NewProblem returns a new empty Problem, the "type" defaults to "about:blank",
+// the "status" to the response's status code and the "title" to its status text.
+//
+// Usage: ctx.Problem(NewProblem().Status(400).Detail("the id is invalid").Key("id", id))
+func NewProblem() Problem {
+	return make(Problem)
+}
+
+// Key sets an extension member, i.e "balance" of an "out of credit" problem.
+func (p Problem) Key(key string, value interface{}) Problem {
+	p[key] = value
+	return p
+}
+
+// Type sets the "type" member, a URI reference that identifies the problem type.
+func (p Problem) Type(uri string) Problem {
+	return p.Key("type", uri)
+}
+
+// Title sets the "title" member, a short, human-readable summary of the problem type.
+func (p Problem) Title(title string) Problem {
+	return p.Key("title", title)
+}
+
+// Status sets the "status" member, the HTTP status code.
+func (p Problem) Status(statusCode int) Problem {
+	return p.Key("status", statusCode)
+}
+
+// Detail sets the "detail" member, a human-readable explanation specific to this occurrence of the problem.
+func (p Problem) Detail(detail string) Problem {
+	return p.Key("detail", detail)
+}
+
+// Instance sets the "instance" member, a URI reference that identifies the specific occurrence of the problem.
+func (p Problem) Instance(uri string) Problem {
+	return p.Key("instance", uri)
+}
+
+// GetStatus returns the "status" member, zero if missing.
+func (p Problem) GetStatus() int {
+	switch v := p["status"].(type) {
+	case int:
+		return v
+	case float64: // decoded from JSON.
+		return int(v)
+	}
+
+	return 0
+}
+
+// MarshalXML encodes the problem as <problem xmlns="urn:ietf:rfc:7807">,
+// the members are sorted by their names.
+func (p Problem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{
+		Name: xml.Name{Local: "problem"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: "urn:ietf:rfc:7807"}},
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := e.EncodeElement(p[k], xml.StartElement{Name: xml.Name{Local: k}}); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// Problem writes the "p" as an RFC 7807 problem details response,
+// the "application/problem+xml" is used if the client prefers XML, otherwise the "application/problem+json".
+// See the `Context` interface's documentation for more.
+func (ctx *context) Problem(p Problem) (int, error) {
+	status := p.GetStatus()
+	if status == 0 {
+		status = ctx.GetStatusCode()
+		if !StatusCodeNotSuccessful(status) {
+			status = http.StatusInternalServerError
+		}
+		p.Status(status)
+	}
+
+	if _, ok := p["type"]; !ok {
+		p.Type("about:blank")
+	}
+
+	if _, ok := p["title"]; !ok {
+		p.Title(http.StatusText(status))
+	}
+
+	ctx.StatusCode(status)
+
+	var (
+		b   []byte
+		err error
+	)
+
+	switch NegotiateMediaType(ctx.GetHeader(AcceptHeaderKey),
+		ContentProblemJSONHeaderValue, ContentJSONHeaderValue,
+		ContentProblemXMLHeaderValue, ContentXMLUnreadableHeaderValue, ContentXMLHeaderValue) {
+	case ContentProblemXMLHeaderValue, ContentXMLUnreadableHeaderValue, ContentXMLHeaderValue:
+		ctx.ContentType(ContentProblemXMLHeaderValue)
+		b, err = xml.Marshal(p)
+	default:
+		ctx.ContentType(ContentProblemJSONHeaderValue)
+		b, err = json.Marshal(p)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	ctx.Header(ContentLengthHeaderKey, strconv.Itoa(len(b)))
+	return ctx.Write(b)
+}
+
+// ProblemHandler renders the current error status code as a problem details response,
+// register it through the `app.OnAnyErrorCode(iris.ProblemHandler)`
+// so the API error handlers emit standard machine-readable errors.
+var ProblemHandler = func(ctx Context) {
+	ctx.Problem(NewProblem().Status(ctx.GetStatusCode()).Instance(ctx.Path()))
+}
@@ -0,0 +1,152 @@
+package context
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+// ProxyOption customizes the reverse proxy of a `Context#ReverseProxy` or `Context#Forward` call,
+// see `ProxyRewriteRequest`, `ProxyRewriteResponse` and `ProxyTransport`.
+type ProxyOption func(p *httputil.ReverseProxy)
+
+// ProxyRewriteRequest registers a hook which modifies the outgoing request,
+// i.e its headers, after the target is set.
+func ProxyRewriteRequest(fn func(r *http.Request)) ProxyOption {
+	return func(p *httputil.ReverseProxy) {
+		director := p.Director
+		p.Director = func(r *http.Request) {
+			director(r)
+			fn(r)
+		}
+	}
+}
+
+// ProxyRewriteResponse registers a hook which modifies the response of the target,
+// i.e its headers, before it's sent to the client.
+// A non-nil error is handled as a target failure, see `Context#ReverseProxy`.
+func ProxyRewriteResponse(fn func(res *http.Response) error) ProxyOption {
+	return func(p *httputil.ReverseProxy) {
+		modify := p.ModifyResponse
+		p.ModifyResponse = func(res *http.Response) error {
+			if modify != nil {
+				if err := modify(res); err != nil {
+					return err
+				}
+			}
+
+			return fn(res)
+		}
+	}
+}
+
+// ProxyTransport sets the transport of the outgoing requests, defaults to the http.DefaultTransport.
+func ProxyTransport(transport http.RoundTripper) ProxyOption {
+	return func(p *httputil.ReverseProxy) {
+		p.Transport = transport
+	}
+}
+
+func proxyJoinPath(a, b string) string {
+	aslash, bslash := strings.HasSuffix(a, "/"), strings.HasPrefix(b, "/")
+	switch {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash:
+		return a + "/" + b
+	}
+	return a + b
+}
+
+// ReverseProxy proxies the current request to the "target", the request's path
+// is joined to the target's path and the queries are merged,
+// i.e target "http://backend:8080/api" and request "/users?id=1" to "http://backend:8080/api/users?id=1".
+//
+// The response is written through the context's response writer, so the recorder
+// and the gzip compression keep working. The "X-Forwarded-For", "X-Forwarded-Host"
+// and "X-Forwarded-Proto" headers are set and the outgoing request is canceled with the `StdContext`.
+//
+// If the target fails, i.e it's down, the status code is set to 502 Bad Gateway,
+// so the registered error handler is fired, and the error is returned.
+//
+// Usage:
+// target, _ := url.Parse("http://localhost:9090")
+// app.Any("/api/{p:path}", func(ctx iris.Context) {
+//   ctx.ReverseProxy(target, context.ProxyRewriteRequest(func(r *http.Request) {
+//     r.Header.Set("X-Gateway", "iris")
+//   }))
+// })
+// 反向代理: 通过Context的ResponseWriter写入, 保留recorder、gzip以及错误码的处理
+func (ctx *context) ReverseProxy(target *url.URL, options ...ProxyOption) error {
+	gzipWriter, gzipped := ctx.writer.(*GzipResponseWriter)
+
+	targetQuery := target.RawQuery
+	p := &httputil.ReverseProxy{
+		Director: func(r *http.Request) {
+			r.URL.Scheme = target.Scheme
+			r.URL.Host = target.Host
+			r.Host = target.Host
+			r.URL.Path = proxyJoinPath(target.Path, r.URL.Path)
+			r.URL.RawPath = ""
+			if targetQuery == "" || r.URL.RawQuery == "" {
+				r.URL.RawQuery = targetQuery + r.URL.RawQuery
+			} else {
+				r.URL.RawQuery = targetQuery + "&" + r.URL.RawQuery
+			}
+
+			if _, ok := r.Header["User-Agent"]; !ok {
+				// explicitly disable User-Agent so it's not set to default value.
+				r.Header.Set("User-Agent", "")
+			}
+
+			r.Header.Set("X-Forwarded-Host", ctx.request.Host)
+			r.Header.Set("X-Forwarded-Proto", Scheme(ctx))
+
+			if gzipped {
+				// let the transport decompress the response, it's compressed by the gzip writer.
+				r.Header.Del(AcceptEncodingHeaderKey)
+			}
+		},
+	}
+
+	if gzipped {
+		p.ModifyResponse = func(res *http.Response) error {
+			if res.Header.Get(ContentEncodingHeaderKey) != "" {
+				// already encoded by the target.
+				gzipWriter.Disable()
+			} else {
+				res.Header.Del(ContentLengthHeaderKey)
+			}
+			return nil
+		}
+	}
+
+	var proxyErr error
+	p.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		proxyErr = err
+		ctx.StatusCode(http.StatusBadGateway)
+	}
+
+	for _, opt := range options {
+		opt(p)
+	}
+
+	p.ServeHTTP(ctx.writer, ctx.request.WithContext(StdContext(ctx)))
+	return proxyErr
+}
+
+// Forward proxies the current request to the "hostport", i.e "localhost:9090"
+// or "https://backend:9090", see `ReverseProxy`.
+func (ctx *context) Forward(hostport string, options ...ProxyOption) error {
+	if !strings.Contains(hostport, "://") {
+		hostport = "http://" + hostport
+	}
+
+	target, err := url.Parse(hostport)
+	if err != nil {
+		return err
+	}
+
+	return ctx.ReverseProxy(target, options...)
+}
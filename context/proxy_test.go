@@ -0,0 +1,150 @@
+package context_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	stdhttptest "net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func newBackend(t *testing.T) *stdhttptest.Server {
+	backend := stdhttptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/encoded":
+			var b bytes.Buffer
+			gw := gzip.NewWriter(&b)
+			gw.Write([]byte("encoded by the backend"))
+			gw.Close()
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(b.Bytes())
+		case "/api/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("backend not found"))
+		default:
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Header().Set("X-Backend", "1")
+			w.Write([]byte(strings.Join([]string{
+				r.Method, r.URL.RequestURI(), r.Host, r.Header.Get("X-Forwarded-Host"), r.Header.Get("X-Forwarded-Proto"),
+				r.Header.Get("X-Gateway"), string(body),
+			}, "|")))
+		}
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+func TestReverseProxy(t *testing.T) {
+	backend := newBackend(t)
+	target, _ := url.Parse(backend.URL + "/api?key=1")
+
+	app := iris.New()
+	app.Any("/{p:path}", func(ctx iris.Context) {
+		err := ctx.ReverseProxy(target,
+			context.ProxyRewriteRequest(func(r *http.Request) {
+				r.Header.Set("X-Gateway", "iris")
+			}),
+			context.ProxyRewriteResponse(func(res *http.Response) error {
+				res.Header.Set("X-Proxied", "1")
+				return nil
+			}))
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+
+	r := e.POST("/users").WithQuery("id", "1").WithText("body").Expect().Status(httptest.StatusOK)
+	r.Header("X-Backend").Equal("1")
+	r.Header("X-Proxied").Equal("1")
+	host := strings.TrimPrefix(backend.URL, "http://")
+	// the path is joined, the queries are merged and the host is the target's one.
+	r.Body().Equal("POST|/api/users?key=1&id=1|" + host + "|example.com|http|iris|body")
+
+	// the response of the target is sent as it's.
+	e.GET("/missing").Expect().Status(httptest.StatusNotFound).Body().Equal("backend not found")
+}
+
+func TestReverseProxyGzip(t *testing.T) {
+	backend := newBackend(t)
+	target, _ := url.Parse(backend.URL + "/api")
+
+	app := iris.New()
+	app.Get("/{p:path}", func(ctx iris.Context) {
+		ctx.Gzip(true)
+		ctx.ReverseProxy(target)
+	})
+
+	e := httptest.New(t, app)
+
+	// compressed by the gzip writer, the target's response is not.
+	r := e.GET("/plain").WithHeader("Accept-Encoding", "gzip").Expect().Status(httptest.StatusOK)
+	r.Header(context.ContentEncodingHeaderKey).Equal(context.GzipHeaderValue)
+	if got := gunzipBody(t, r.Body().Raw()); !strings.HasPrefix(got, "GET|/api/plain|") {
+		t.Fatalf("expected the body of the target but got %q", got)
+	}
+
+	// already encoded by the target, it's not compressed twice.
+	r = e.GET("/encoded").WithHeader("Accept-Encoding", "gzip").Expect().Status(httptest.StatusOK)
+	r.Header(context.ContentEncodingHeaderKey).Equal(context.GzipHeaderValue)
+	if expected, got := "encoded by the backend", gunzipBody(t, r.Body().Raw()); expected != got {
+		t.Fatalf("expected the body %q but got %q", expected, got)
+	}
+}
+
+func gunzipBody(t *testing.T, body string) string {
+	t.Helper()
+
+	gr, err := gzip.NewReader(strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(gr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestForward(t *testing.T) {
+	backend := newBackend(t)
+	host := strings.TrimPrefix(backend.URL, "http://")
+
+	var proxyErr error
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		// without a scheme.
+		ctx.Forward(host)
+	})
+	app.Get("/down", func(ctx iris.Context) {
+		proxyErr = ctx.Forward("http://127.0.0.1:1")
+	})
+	app.Get("/invalid", func(ctx iris.Context) {
+		if err := ctx.Forward("http://[::1"); err != nil {
+			ctx.StatusCode(iris.StatusInternalServerError)
+		}
+	})
+	app.OnErrorCode(iris.StatusBadGateway, func(ctx iris.Context) {
+		ctx.WriteString("backend is down")
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/").Expect().Status(httptest.StatusOK).Body().Equal("GET|/|" + host + "||http||")
+
+	// the error handler is fired.
+	e.GET("/down").Expect().Status(httptest.StatusBadGateway).Body().Equal("backend is down")
+	if proxyErr == nil {
+		t.Fatalf("expected the error of the target")
+	}
+
+	e.GET("/invalid").Expect().Status(httptest.StatusInternalServerError)
+}
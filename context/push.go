@@ -0,0 +1,84 @@
+package context
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ParsePushTarget returns the path of a push target,
+// the target can be a path or a preload "Link" header value, i.e "</css/app.css>; rel=preload; as=style",
+// it returns an empty string for the links which are not preloads or are marked as "nopush".
+// Only the first link of a value with many, i.e "</a.css>; rel=preload, </b.js>; rel=preload", is parsed.
+func ParsePushTarget(target string) string {
+	target = strings.TrimSpace(target)
+	if !strings.HasPrefix(target, "<") {
+		return target
+	}
+
+	end := strings.IndexByte(target, '>')
+	if end < 0 {
+		return ""
+	}
+
+	params := target[end+1:]
+	// the parameters of this link only, not of the next ones of the same header value.
+	if idx := strings.IndexByte(params, ','); idx != -1 {
+		params = params[:idx]
+	}
+
+	preload := false
+	for _, param := range strings.Split(params, ";") {
+		param = strings.ToLower(strings.TrimSpace(param))
+		switch {
+		case param == "nopush":
+			return ""
+		case strings.HasPrefix(param, "rel="):
+			for _, rel := range strings.Fields(strings.Trim(param[len("rel="):], `"`)) {
+				if rel == "preload" {
+					preload = true
+				}
+			}
+		}
+	}
+
+	if !preload {
+		return ""
+	}
+
+	return target[1:end]
+}
+
+// PushTargets initiates an HTTP/2 server push of each of the "targets", i.e "/css/app.css",
+// it should be called before writing the response body.
+// A target can be a "Link" header value too, i.e "</css/app.css>; rel=preload; as=style".
+//
+// It does nothing when the client does not support or has disabled the server push,
+// i.e an HTTP/1.x request, the rest errors are returned.
+// HTTP/2 服务器推送, 不支持时静默忽略
+func (ctx *context) PushTargets(targets ...string) error {
+	if ctx.request.ProtoMajor < 2 {
+		return nil
+	}
+
+	var opts *http.PushOptions
+	if acceptEncoding := ctx.request.Header.Get(AcceptEncodingHeaderKey); acceptEncoding != "" {
+		// so the pushed responses can be compressed as well.
+		opts = &http.PushOptions{Header: http.Header{AcceptEncodingHeaderKey: {acceptEncoding}}}
+	}
+
+	for _, target := range targets {
+		target = ParsePushTarget(target)
+		if target == "" {
+			continue
+		}
+
+		if err := ctx.writer.Push(target, opts); err != nil {
+			if ErrPushNotSupported.Equal(err) || err == http.ErrNotSupported {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
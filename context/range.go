@@ -0,0 +1,229 @@
+package context
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+const (
+	// RangeHeaderKey is the header key of "Range".
+	RangeHeaderKey = "Range"
+	// IfRangeHeaderKey is the header key of "If-Range".
+	IfRangeHeaderKey = "If-Range"
+	// AcceptRangesHeaderKey is the header key of "Accept-Ranges".
+	AcceptRangesHeaderKey = "Accept-Ranges"
+	// ContentRangeHeaderKey is the header key of "Content-Range".
+	ContentRangeHeaderKey = "Content-Range"
+)
+
+var (
+	errInvalidRange = errors.New("invalid range")
+	errNoOverlap    = errors.New("invalid range: failed to overlap")
+)
+
+// byteRange is a byte range of the "Range" request header, see `parseRange`.
+type byteRange struct {
+	start, length int64
+}
+
+// contentRange returns the "Content-Range" response header's value of the range.
+func (r byteRange) contentRange(size int64) string {
+	return "bytes " + strconv.FormatInt(r.start, 10) + "-" + strconv.FormatInt(r.start+r.length-1, 10) +
+		"/" + strconv.FormatInt(size, 10)
+}
+
+// parseRange parses the "Range" request header's value of the "bytes" unit (RFC 7233),
+// i.e "bytes=0-499", "bytes=500-", "bytes=-500" or "bytes=0-0,-1".
+// The ranges out of the "size" are ignored, it returns an `errNoOverlap` if none is left.
+//
+// It returns nil ranges and a nil error on a range of another unit, it should be ignored.
+func parseRange(s string, size int64) ([]byteRange, error) {
+	const unit = "bytes="
+	if !strings.HasPrefix(s, unit) {
+		return nil, nil
+	}
+
+	var (
+		ranges    []byteRange
+		noOverlap bool
+	)
+
+	for _, ra := range strings.Split(s[len(unit):], ",") {
+		ra = strings.TrimSpace(ra)
+		if ra == "" {
+			continue
+		}
+
+		i := strings.Index(ra, "-")
+		if i < 0 {
+			return nil, errInvalidRange
+		}
+		start, end := strings.TrimSpace(ra[:i]), strings.TrimSpace(ra[i+1:])
+
+		var r byteRange
+		if start == "" {
+			// the suffix range, i.e "-500" is the last 500 bytes.
+			n, err := strconv.ParseInt(end, 10, 64)
+			if err != nil || n < 0 {
+				return nil, errInvalidRange
+			}
+			if n == 0 || size == 0 {
+				noOverlap = true
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r.start = size - n
+			r.length = n
+		} else {
+			n, err := strconv.ParseInt(start, 10, 64)
+			if err != nil || n < 0 {
+				return nil, errInvalidRange
+			}
+			if n >= size {
+				noOverlap = true
+				continue
+			}
+			r.start = n
+
+			if end == "" {
+				// i.e "500-", from 500 to the end.
+				r.length = size - r.start
+			} else {
+				n, err := strconv.ParseInt(end, 10, 64)
+				if err != nil || r.start > n {
+					return nil, errInvalidRange
+				}
+				if n >= size {
+					n = size - 1
+				}
+				r.length = n - r.start + 1
+			}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		if noOverlap {
+			return nil, errNoOverlap
+		}
+		return nil, errInvalidRange
+	}
+
+	return ranges, nil
+}
+
+// checkIfRange reports whether the "Range" request header should be honored,
+// the "If-Range" is missing or it matches the response's "ETag" or the "modtime" (RFC 7233 section 3.2).
+func (ctx *context) checkIfRange(modtime time.Time) bool {
+	ir := strings.TrimSpace(ctx.GetHeader(IfRangeHeaderKey))
+	if ir == "" {
+		return true
+	}
+
+	if strings.HasPrefix(ir, `"`) || strings.HasPrefix(ir, "W/") {
+		// the entity-tags are compared with the strong comparison,
+		// a weak one never matches.
+		etag := ctx.writer.Header().Get(ETagHeaderKey)
+		return strings.HasPrefix(ir, `"`) && ir == etag
+	}
+
+	if IsZeroTime(modtime) {
+		return false
+	}
+
+	t, err := ParseTime(ctx, ir)
+	return err == nil && modtime.UTC().Truncate(time.Second).Equal(t.UTC())
+}
+
+// serveContentRange serves the requested byte range(s) of the "content",
+// a single range as 206 "Partial Content" and multiple ranges as "multipart/byteranges",
+// or 416 "Requested Range Not Satisfiable" if none of them overlaps the content.
+//
+// It reports whether the response was served, if not the whole content should be served instead,
+// i.e the request has no "Range" header or the "If-Range" does not match.
+// 处理Range请求(断点续传, 视频拖动等)
+func (ctx *context) serveContentRange(content io.ReadSeeker, modtime time.Time) (bool, error) {
+	if _, ok := ctx.writer.(*GzipResponseWriter); ok {
+		// the ranges refer to the uncompressed content.
+		return false, nil
+	}
+
+	size, err := content.Seek(0, io.SeekEnd)
+	if err != nil {
+		return false, nil
+	}
+	if _, err = content.Seek(0, io.SeekStart); err != nil {
+		return false, nil
+	}
+
+	ctx.writer.Header().Set(AcceptRangesHeaderKey, "bytes")
+
+	rangeHeader := ctx.GetHeader(RangeHeaderKey)
+	if rangeHeader == "" || ctx.Method() != http.MethodGet || !ctx.checkIfRange(modtime) {
+		return false, nil
+	}
+
+	ranges, err := parseRange(rangeHeader, size)
+	if err != nil {
+		ctx.writer.Header().Set(ContentRangeHeaderKey, "bytes */"+strconv.FormatInt(size, 10))
+		ctx.StatusCode(http.StatusRequestedRangeNotSatisfiable)
+		return true, nil
+	}
+
+	var sum int64
+	for _, r := range ranges {
+		sum += r.length
+	}
+	// ignore the (i.e overlapped) ranges which are larger than the content itself.
+	if len(ranges) == 0 || sum > size {
+		return false, nil
+	}
+
+	if len(ranges) == 1 {
+		r := ranges[0]
+		if _, err = content.Seek(r.start, io.SeekStart); err != nil {
+			return true, err
+		}
+
+		ctx.writer.Header().Set(ContentRangeHeaderKey, r.contentRange(size))
+		ctx.writer.Header().Set(ContentLengthHeaderKey, strconv.FormatInt(r.length, 10))
+		ctx.StatusCode(http.StatusPartialContent)
+		_, err = io.CopyN(ctx.writer, content, r.length)
+		return true, err
+	}
+
+	contentType := ctx.GetContentType()
+	mw := multipart.NewWriter(ctx.writer)
+	ctx.writer.Header().Set(ContentTypeHeaderKey, "multipart/byteranges; boundary="+mw.Boundary())
+	ctx.StatusCode(http.StatusPartialContent)
+
+	for _, r := range ranges {
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			ContentTypeHeaderKey:  {contentType},
+			ContentRangeHeaderKey: {r.contentRange(size)},
+		})
+		if err != nil {
+			return true, err
+		}
+
+		if _, err = content.Seek(r.start, io.SeekStart); err != nil {
+			return true, err
+		}
+
+		if _, err = io.CopyN(part, content, r.length); err != nil {
+			return true, err
+		}
+	}
+
+	return true, mw.Close()
+}
@@ -0,0 +1,76 @@
+package context_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+type listQuery struct {
+	Since time.Time `url:"since"`
+	Limit int       `url:"limit"`
+	Tags  []string  `url:"tag"`
+	Page  *int      `url:"page"`
+	// by its name.
+	Order string
+}
+
+func TestReadQuery(t *testing.T) {
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		q := listQuery{Limit: 10}
+		if err := ctx.ReadQuery(&q); err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			return
+		}
+
+		page := "nil"
+		if q.Page != nil {
+			page = ctx.URLParam("page")
+		}
+		ctx.Writef("%s %d %v %s %s", q.Since.Format(time.RFC3339), q.Limit, q.Tags, page, q.Order)
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/").WithQueryString("since=2019-01-02T03:04:05Z&limit=5&tag=a&tag=b&page=2&Order=desc").Expect().
+		Status(httptest.StatusOK).Body().Equal("2019-01-02T03:04:05Z 5 [a b] 2 desc")
+	// the defaults are kept without a query string.
+	e.GET("/").Expect().Status(httptest.StatusOK).Body().Equal("0001-01-01T00:00:00Z 10 [] nil ")
+	e.GET("/").WithQuery("limit", "ten").Expect().Status(httptest.StatusBadRequest)
+	e.GET("/").WithQuery("since", "yesterday").Expect().Status(httptest.StatusBadRequest)
+}
+
+type pageQuery struct {
+	Limit int `url:"limit"`
+}
+
+type limitValidator struct{}
+
+func (limitValidator) Struct(s interface{}) error {
+	if q, ok := s.(*pageQuery); ok && q.Limit > 100 {
+		return context.ValidationErrors{{Field: "Limit", Tag: "max", Message: "limit is too big"}}
+	}
+	return nil
+}
+
+func TestReadQueryValidation(t *testing.T) {
+	app := iris.New()
+	app.Validator(limitValidator{})
+	app.Get("/", func(ctx iris.Context) {
+		var q pageQuery
+		if err := ctx.ReadQuery(&q); err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			ctx.WriteString(err.Error())
+			return
+		}
+		ctx.Writef("%d", q.Limit)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").WithQuery("limit", 100).Expect().Status(httptest.StatusOK).Body().Equal("100")
+	e.GET("/").WithQuery("limit", 101).Expect().Status(httptest.StatusBadRequest).Body().Equal("limit is too big")
+}
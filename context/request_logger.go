@@ -0,0 +1,218 @@
+package context
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/kataras/golog"
+)
+
+// RequestLoggerContextKey is the context's values key of the request's logger, see `Logger`.
+const RequestLoggerContextKey = "iris.logger"
+
+// LogField is a key-value pair of a `RequestLogger`.
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+// RequestLogFormatter formats a message of a `RequestLogger` with its fields before it's printed
+// by the application's logger.
+//
+// Defaults to the message followed by the logfmt key=value pairs of the fields,
+// i.e `user created request_id=7f3a method=POST path=/users route=POST/users user=42`.
+var RequestLogFormatter = func(message string, fields []LogField) string {
+	var b strings.Builder
+	b.WriteString(message)
+	for _, f := range fields {
+		b.WriteByte(' ')
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		value := fmt.Sprint(f.Value)
+		if value == "" || strings.ContainsAny(value, " \t\r\n\"=") {
+			value = strconv.Quote(value)
+		}
+		b.WriteString(value)
+	}
+
+	return b.String()
+}
+
+// RequestLogger is a request-scoped structured logger, see `Logger`,
+// its messages are printed by the application's logger with the request's fields.
+// 请求级别的日志, 带有请求的id, method, path 以及路由名称
+type RequestLogger struct {
+	logger *golog.Logger
+	fields []LogField
+}
+
+// NewRequestLogger returns a new request logger which prints through the "logger" with the "fields".
+func NewRequestLogger(logger *golog.Logger, fields ...LogField) *RequestLogger {
+	return &RequestLogger{logger: logger, fields: fields}
+}
+
+// lazyValue is a field's value which is resolved when it's read,
+// i.e the "route" one which is not known yet when the logger is created by a router middleware.
+type lazyValue func() interface{}
+
+func resolveField(f LogField) LogField {
+	if lazy, ok := f.Value.(lazyValue); ok {
+		f.Value = lazy()
+	}
+	return f
+}
+
+// Fields returns a copy of the logger's fields, by order.
+func (l *RequestLogger) Fields() []LogField {
+	fields := make([]LogField, len(l.fields))
+	for i, f := range l.fields {
+		fields[i] = resolveField(f)
+	}
+
+	return fields
+}
+
+// Field returns the value of the "key" field, if any.
+func (l *RequestLogger) Field(key string) (interface{}, bool) {
+	for _, f := range l.fields {
+		if f.Key == key {
+			return resolveField(f).Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// Set sets the "key" field of this logger, it replaces an existing one.
+// A middleware can use it to add a field to all the next messages of the request,
+// i.e `Logger(ctx).Set("tenant", tenantID)`.
+//
+// Returns itself.
+func (l *RequestLogger) Set(key string, value interface{}) *RequestLogger {
+	for i, f := range l.fields {
+		if f.Key == key {
+			l.fields[i].Value = value
+			return l
+		}
+	}
+
+	l.fields = append(l.fields, LogField{Key: key, Value: value})
+	return l
+}
+
+// With returns a child logger with the "key" field, this logger is not modified.
+func (l *RequestLogger) With(key string, value interface{}) *RequestLogger {
+	// the lazy values are kept, so the child resolves them as well.
+	child := &RequestLogger{logger: l.logger, fields: append([]LogField(nil), l.fields...)}
+	return child.Set(key, value)
+}
+
+func (l *RequestLogger) format(message string) string {
+	return RequestLogFormatter(message, l.Fields())
+}
+
+// Debug prints a debug message with the logger's fields.
+func (l *RequestLogger) Debug(v ...interface{}) {
+	l.logger.Debug(l.format(fmt.Sprint(v...)))
+}
+
+// Debugf prints a formatted debug message with the logger's fields.
+func (l *RequestLogger) Debugf(format string, args ...interface{}) {
+	l.logger.Debug(l.format(fmt.Sprintf(format, args...)))
+}
+
+// Info prints an info message with the logger's fields.
+func (l *RequestLogger) Info(v ...interface{}) {
+	l.logger.Info(l.format(fmt.Sprint(v...)))
+}
+
+// Infof prints a formatted info message with the logger's fields.
+func (l *RequestLogger) Infof(format string, args ...interface{}) {
+	l.logger.Info(l.format(fmt.Sprintf(format, args...)))
+}
+
+// Warn prints a warning message with the logger's fields.
+func (l *RequestLogger) Warn(v ...interface{}) {
+	l.logger.Warn(l.format(fmt.Sprint(v...)))
+}
+
+// Warnf prints a formatted warning message with the logger's fields.
+func (l *RequestLogger) Warnf(format string, args ...interface{}) {
+	l.logger.Warn(l.format(fmt.Sprintf(format, args...)))
+}
+
+// Error prints an error message with the logger's fields.
+func (l *RequestLogger) Error(v ...interface{}) {
+	l.logger.Error(l.format(fmt.Sprint(v...)))
+}
+
+// Errorf prints a formatted error message with the logger's fields.
+func (l *RequestLogger) Errorf(format string, args ...interface{}) {
+	l.logger.Error(l.format(fmt.Sprintf(format, args...)))
+}
+
+// Logger returns the request-scoped logger, it's created on the first call of the request
+// with the "request_id", the "method", the "path" and the "route" (name) fields,
+// the route is resolved on each message, so a logger created by a router middleware
+// reports the route which is matched later on, the request id is the "X-Request-Id" request or response header's value, or the context's id.
+// A middleware can add more fields through its `Set`.
+// Prefer it over the `Application().Logger()` inside the handlers, so the logs can be correlated.
+//
+// Usage: Logger(ctx).With("user", id).Infof("user created")
+func Logger(ctx Context) *RequestLogger {
+	values := ctx.Values()
+	if l, ok := values.Get(RequestLoggerContextKey).(*RequestLogger); ok {
+		return l
+	}
+
+	requestID := ctx.GetHeader("X-Request-Id")
+	if requestID == "" {
+		requestID = ctx.ResponseWriter().Header().Get("X-Request-Id")
+	}
+	if requestID == "" {
+		requestID = strconv.FormatUint(contextID(ctx), 10)
+	}
+
+	var (
+		routeName string
+		ended     bool
+	)
+	route := lazyValue(func() interface{} {
+		if !ended {
+			if r := ctx.GetCurrentRoute(); r != nil {
+				routeName = r.Name()
+			}
+		}
+		return routeName
+	})
+	// the context is released after the request, keep its route.
+	OnEnd(ctx, func() {
+		route()
+		ended = true
+	})
+
+	l := NewRequestLogger(ctx.Application().Logger(),
+		LogField{Key: "request_id", Value: requestID},
+		LogField{Key: "method", Value: ctx.Method()},
+		LogField{Key: "path", Value: ctx.Path()},
+		LogField{Key: "route", Value: route},
+	)
+	values.Set(RequestLoggerContextKey, l)
+	return l
+}
+
+// contextID returns the id of the default context implementation, see `context#String`,
+// a new one for the custom contexts.
+func contextID(ctx Context) uint64 {
+	c, ok := ctx.(*context)
+	if !ok {
+		return atomic.AddUint64(&lastCapturedContextID, 1)
+	}
+
+	if c.id == 0 {
+		c.id = atomic.AddUint64(&lastCapturedContextID, 1)
+	}
+	return c.id
+}
@@ -0,0 +1,105 @@
+package context_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func newLoggerApp() (*iris.Application, *bytes.Buffer) {
+	buf := new(bytes.Buffer)
+	app := iris.New()
+	app.Logger().SetOutput(buf)
+	return app, buf
+}
+
+func expectLog(t *testing.T, buf *bytes.Buffer, expected ...string) {
+	t.Helper()
+
+	got := buf.String()
+	for _, s := range expected {
+		if !strings.Contains(got, s) {
+			t.Fatalf("expected the logs to contain %q but got %q", s, got)
+		}
+	}
+	buf.Reset()
+}
+
+func TestRequestLogger(t *testing.T) {
+	app, buf := newLoggerApp()
+	app.Use(func(ctx iris.Context) {
+		context.Logger(ctx).Set("tenant", "acme")
+		ctx.Next()
+	})
+	app.Post("/users/{id}", func(ctx iris.Context) {
+		if context.Logger(ctx) != context.Logger(ctx) {
+			t.Fatalf("expected the same logger of the request")
+		}
+		context.Logger(ctx).With("user", ctx.Params().Get("id")).Infof("user %s", "created")
+		context.Logger(ctx).Warn("no user")
+	}).Name = "createUser"
+
+	e := httptest.New(t, app, httptest.LogLevel("debug"))
+
+	e.POST("/users/42").WithHeader("X-Request-Id", "7f3a").Expect().Status(httptest.StatusOK)
+	expectLog(t, buf,
+		"user created request_id=7f3a method=POST path=/users/42 route=createUser tenant=acme user=42",
+		// the child's field is not added to its parent.
+		"no user request_id=7f3a method=POST path=/users/42 route=createUser tenant=acme\n")
+
+	// the context's id without a request id.
+	e.POST("/users/42").Expect().Status(httptest.StatusOK)
+	if strings.Contains(buf.String(), `request_id="`) {
+		t.Fatalf("expected a request id but got %q", buf.String())
+	}
+	buf.Reset()
+}
+
+func TestRequestLoggerRouterMiddleware(t *testing.T) {
+	var logger *context.RequestLogger
+
+	app, buf := newLoggerApp()
+	// the route is not matched yet.
+	app.UseRouter(func(ctx iris.Context) {
+		logger = context.Logger(ctx).With("stage", "router")
+		context.Logger(ctx).Debug("begin")
+		ctx.Next()
+	})
+	app.Get("/", func(ctx iris.Context) {
+		context.Logger(ctx).Info("index")
+		logger.Info("index")
+	}).Name = "index"
+
+	e := httptest.New(t, app, httptest.LogLevel("debug"))
+
+	e.GET("/").Expect().Status(httptest.StatusOK)
+	expectLog(t, buf,
+		`method=GET path=/ route=""`,
+		" method=GET path=/ route=index\n",
+		" method=GET path=/ route=index stage=router")
+
+	// the logger keeps the route of its request after the end of it.
+	if route, _ := logger.Field("route"); route != "index" {
+		t.Fatalf("expected the route %q but got %v", "index", route)
+	}
+	e.GET("/missing").Expect().Status(httptest.StatusNotFound)
+	expectLog(t, buf, `method=GET path=/missing route=""`)
+	if route, _ := logger.Field("route"); route != "" {
+		t.Fatalf("expected no route but got %v", route)
+	}
+	if fields := logger.Fields(); len(fields) != 5 || fields[3].Value != "" || fields[4].Value != "router" {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+}
+
+func TestRequestLogFormatter(t *testing.T) {
+	expected := `message a=1 b="two words" c="" d="k=v"`
+	got := context.RequestLogFormatter("message", []context.LogField{{Key: "a", Value: 1}, {Key: "b", Value: "two words"}, {Key: "c", Value: ""}, {Key: "d", Value: "k=v"}})
+	if expected != got {
+		t.Fatalf("expected %q but got %q", expected, got)
+	}
+}
@@ -12,6 +12,10 @@ import (
 // RequestParams is a key string - value string storage which
 // context's request dynamic path params are being kept.
 // Empty if the route is static.
+//
+// The typed getters, i.e `GetInt`, `GetIntDefault`, `GetInt64`, `GetUint64`, `GetBool` and `GetFloat64`,
+// are inherited by the `memstore.Store` and they work for both the macro's typed values,
+// i.e `{id:uint64}`, and the string ones, i.e `{id}`, like the `ctx.URLParam*` family does for the query.
 // 这是用于动态路径使用的，如果路径是静态的，则为empty
 type RequestParams struct {
 	memstore.Store
@@ -51,6 +55,12 @@ func (r RequestParams) Get(key string) string {
 	return r.GetString(key)
 }
 
+// GetValue returns a path parameter's typed value, i.e the time.Time of a custom `{d:date}` parameter type,
+// see `macro#Evaluator`. Returns nil if it's missing.
+func (r RequestParams) GetValue(key string) interface{} {
+	return r.Store.Get(key)
+}
+
 // GetTrim returns a path parameter's value without trailing spaces based on its route's dynamic path key.
 func (r RequestParams) GetTrim(key string) string {
 	return strings.TrimSpace(r.Get(key))
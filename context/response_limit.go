@@ -0,0 +1,123 @@
+package context
+
+import (
+	"net/http"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+// ErrResponseBodyTooLarge is returned by the response writers' Write
+// when the response body exceeds the limit set by the `Context#SetMaxResponseBodySize`.
+var ErrResponseBodyTooLarge = errors.New("response body exceeds the limit of %d bytes")
+
+// baseResponseWriter returns the iris' response writer under the gzip and recorder wrappers, if any.
+func baseResponseWriter(w ResponseWriter) *responseWriter {
+	for {
+		switch v := w.(type) {
+		case *responseWriter:
+			return v
+		case *GzipResponseWriter:
+			w = v.ResponseWriter
+		case *ResponseRecorder:
+			w = v.ResponseWriter
+		default:
+			return nil
+		}
+	}
+}
+
+// accountBody counts the "n" body bytes produced by the handlers against the response body limit, if any.
+// The bytes that a wrapper flushes to its underline writer are not counted twice,
+// so the limit is about the plain body, not the compressed one.
+func accountBody(w ResponseWriter, n int) error {
+	if b := baseResponseWriter(w); b != nil {
+		return b.account(n)
+	}
+
+	return nil
+}
+
+// passthrough marks the writes of the "fn" as already counted, see `accountBody`.
+func passthrough(w ResponseWriter, fn func()) {
+	b := baseResponseWriter(w)
+	if b == nil {
+		fn()
+		return
+	}
+
+	b.passthrough++
+	fn()
+	b.passthrough--
+}
+
+// releaseBody uncounts the "n" body bytes which are discarded before they're sent,
+// i.e by the `ResetBody` of a wrapper, so the next writes are not limited by them.
+func releaseBody(w ResponseWriter, n int64) {
+	b := baseResponseWriter(w)
+	if b == nil || b.passthrough > 0 {
+		return
+	}
+
+	b.bodySize -= n
+	if b.bodySize < 0 {
+		// written before the limit was set.
+		b.bodySize = 0
+	}
+}
+
+func (w *responseWriter) account(n int) error {
+	if w.maxBodySize <= 0 || w.passthrough > 0 {
+		return nil
+	}
+
+	if w.bodySize+int64(n) > w.maxBodySize {
+		if !w.bodySizeExceeded {
+			w.bodySizeExceeded = true
+			if w.onBodySizeExceeded != nil {
+				w.onBodySizeExceeded()
+			}
+		}
+
+		return ErrResponseBodyTooLarge.Format(w.maxBodySize)
+	}
+
+	w.bodySize += int64(n)
+	return nil
+}
+
+// SetMaxResponseBodySize sets a limit to the response body size, the bytes that handlers write after
+// the limit is exceeded are dropped, the error is logged and the execution of the next handlers stops.
+// If nothing is sent to the client yet (i.e recorder or gzip writer)
+// then the buffered body is discarded and the status code is set to 500.
+// See the `Context` interface's documentation for more.
+func (ctx *context) SetMaxResponseBodySize(limit int64) {
+	b := baseResponseWriter(ctx.writer)
+	if b == nil {
+		ctx.Application().Logger().Warnf("%s: response body limit is not supported by custom response writers", ctx.HandlerName())
+		return
+	}
+
+	b.maxBodySize = limit
+	b.onBodySizeExceeded = func() {
+		ctx.Application().Logger().Errorf("%s: %s %s: response body exceeds the limit of %d bytes, aborted",
+			ctx.HandlerName(), ctx.Method(), ctx.Path(), limit)
+
+		if b.Written() == NoWritten {
+			if w, ok := ctx.writer.(interface{ ResetBody() }); ok {
+				w.ResetBody()
+			}
+			ctx.StatusCode(http.StatusInternalServerError)
+		}
+
+		ctx.StopExecution()
+	}
+}
+
+// LimitResponseBodySize is a middleware which sets a response body size limit
+// for all next handlers in the chain, see `Context#SetMaxResponseBodySize`.
+var LimitResponseBodySize = func(maxResponseBodySizeBytes int64) Handler {
+	return func(ctx Context) {
+		ctx.SetMaxResponseBodySize(maxResponseBodySizeBytes)
+		ctx.Next()
+	}
+}
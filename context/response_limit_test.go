@@ -0,0 +1,99 @@
+package context_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestResponseBodyLimit(t *testing.T) {
+	limit := context.LimitResponseBodySize(10)
+
+	app := iris.New()
+	app.Logger().SetLevel("disable")
+	app.Get("/", limit, func(ctx iris.Context) {
+		ctx.WriteString("12345678")
+		ctx.WriteString("90")
+	})
+	app.Get("/exceeded", limit, func(ctx iris.Context) {
+		ctx.WriteString("12345678901")
+		ctx.Next()
+	}, func(ctx iris.Context) {
+		ctx.Header("X-Next", "called")
+	})
+	app.Get("/streamed", limit, func(ctx iris.Context) {
+		ctx.WriteString("12345678")
+		ctx.ResponseWriter().Flush()
+		if _, err := ctx.WriteString("901"); !context.ErrResponseBodyTooLarge.Equal(err) {
+			t.Errorf("expected the response body too large error but got %v", err)
+		}
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").Expect().Status(httptest.StatusOK).Body().Equal("1234567890")
+	r := e.GET("/exceeded").Expect().Status(httptest.StatusInternalServerError)
+	r.Header("X-Next").Empty()
+	r.Body().Empty()
+	// the headers and the first bytes are sent already.
+	e.GET("/streamed").Expect().Status(httptest.StatusOK).Body().Equal("12345678")
+}
+
+func TestResponseBodyLimitRecorder(t *testing.T) {
+	limit := context.LimitResponseBodySize(10)
+	setBody := func(body string) context.Handler {
+		return func(ctx iris.Context) {
+			ctx.Record()
+			ctx.Next()
+			ctx.Recorder().SetBodyString(body)
+		}
+	}
+
+	app := iris.New()
+	app.Logger().SetLevel("disable")
+	// the replaced body is released.
+	app.Get("/reset", limit, func(ctx iris.Context) {
+		ctx.Record()
+		ctx.WriteString("12345678")
+		ctx.Recorder().ResetBody()
+		ctx.WriteString("abcdefgh")
+	})
+	app.Get("/set", limit, func(ctx iris.Context) {
+		ctx.Record()
+		ctx.WriteString("12345678")
+		ctx.Recorder().SetBodyString("ab")
+		ctx.WriteString("cdefgh")
+	})
+	// the new body is counted.
+	app.Get("/set-exceeded", limit, setBody(strings.Repeat("a", 11)), func(ctx iris.Context) {
+		ctx.WriteString("1234")
+	})
+	app.Get("/set-smaller", limit, setBody("ab"), func(ctx iris.Context) {
+		ctx.WriteString("1234567890")
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/reset").Expect().Status(httptest.StatusOK).Body().Equal("abcdefgh")
+	e.GET("/set").Expect().Status(httptest.StatusOK).Body().Equal("abcdefgh")
+	e.GET("/set-exceeded").Expect().Status(httptest.StatusInternalServerError).Body().Empty()
+	e.GET("/set-smaller").Expect().Status(httptest.StatusOK).Body().Equal("ab")
+}
+
+func TestResponseBodyLimitGzip(t *testing.T) {
+	app := iris.New()
+	app.Logger().SetLevel("disable")
+	app.Get("/", context.LimitResponseBodySize(10), func(ctx iris.Context) {
+		ctx.Gzip(true)
+		ctx.WriteString("12345678")
+		ctx.GzipResponseWriter().ResetBody()
+		if _, err := ctx.WriteString("abcdefgh"); err != nil {
+			t.Errorf("expected the reset body to be released but got %v", err)
+		}
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").WithHeader("Accept-Encoding", "gzip").Expect().Status(httptest.StatusOK).
+		Header(context.ContentEncodingHeaderKey).Equal(context.GzipHeaderValue)
+}
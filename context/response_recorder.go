@@ -2,7 +2,9 @@ package context
 
 import (
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"sync"
 )
 
@@ -37,6 +39,17 @@ type ResponseRecorder struct {
 	chunks []byte
 	// the saved headers
 	headers http.Header
+
+	// the in-memory body limit and its overflow behavior, see `SetMaxBodySize`.
+	maxBodySize int64
+	overflow    RecorderOverflow
+	// the temporary file of the body when it's spilled.
+	file     *os.File
+	fileSize int64
+	// true when the body is written directly to the underline writer.
+	passing bool
+	// decides on the first write if the body is recorded, see `SetRecordFilter`.
+	filter func(*ResponseRecorder) bool
 }
 
 var _ ResponseWriter = (*ResponseRecorder)(nil)
@@ -52,12 +65,17 @@ func (w *ResponseRecorder) Naive() http.ResponseWriter {
 func (w *ResponseRecorder) BeginRecord(underline ResponseWriter) {
 	w.ResponseWriter = underline
 	w.headers = underline.Header()
-	w.ResetBody()
+	w.maxBodySize = 0
+	w.passing = false
+	w.filter = nil
+	// the previous body is not counted by the new writer.
+	w.clearBody()
 }
 
 // EndResponse is auto-called when the whole client's request is done,
 // releases the response recorder and its underline ResponseWriter.
 func (w *ResponseRecorder) EndResponse() {
+	w.removeFile()
 	releaseResponseRecorder(w)
 	w.ResponseWriter.EndResponse()
 }
@@ -84,6 +102,33 @@ func (w *ResponseRecorder) EndResponse() {
 // by all HTTP/2 clients. Handlers should read before writing if
 // possible to maximize compatibility.
 func (w *ResponseRecorder) Write(contents []byte) (int, error) {
+	if err := accountBody(w.ResponseWriter, len(contents)); err != nil {
+		return 0, err
+	}
+
+	if w.passing {
+		var err error
+		passthrough(w.ResponseWriter, func() {
+			_, err = w.ResponseWriter.Write(contents)
+		})
+		return len(contents), err
+	}
+
+	if filter := w.filter; filter != nil {
+		w.filter = nil
+		if !filter(w) {
+			return len(contents), w.passThrough(contents)
+		}
+	}
+
+	if w.file != nil {
+		return len(contents), w.writeFile(contents)
+	}
+
+	if w.maxBodySize > 0 && int64(len(w.chunks)+len(contents)) > w.maxBodySize {
+		return len(contents), w.overflowBody(contents)
+	}
+
 	w.chunks = append(w.chunks, contents...)
 	// Remember that we should not return all the written length within `Write`:
 	// see https://github.com/kataras/iris/pull/931
@@ -105,7 +150,20 @@ func (w *ResponseRecorder) WriteString(s string) (n int, err error) {
 }
 
 // SetBody overrides the body and sets it to a slice of bytes value.
+// The new body replaces the previous one on the response body limit, if any,
+// if it exceeds that then the body is empty, see `Context#SetMaxResponseBodySize`.
+//
+// It's a no-op in the pass-through mode, the body is sent already, see `IsPassThrough`.
 func (w *ResponseRecorder) SetBody(b []byte) {
+	if w.passing {
+		return
+	}
+
+	w.ResetBody()
+	if accountBody(w.ResponseWriter, len(b)) != nil {
+		return
+	}
+
 	w.chunks = b
 }
 
@@ -116,12 +174,27 @@ func (w *ResponseRecorder) SetBodyString(s string) {
 
 // Body returns the body tracked from the writer so far
 // do not use this for edit.
+//
+// A body which is spilled to a temporary file is read in memory, prefer the `BodyReader` for that,
+// see `SetMaxBodySize`. It's empty in the pass-through mode.
 func (w *ResponseRecorder) Body() []byte {
+	if w.file != nil {
+		return w.fileBody()
+	}
+
 	return w.chunks
 }
 
-// ResetBody resets the response body.
+// ResetBody resets the response body,
+// the discarded bytes are not counted by the response body limit anymore.
 func (w *ResponseRecorder) ResetBody() {
+	releaseBody(w.ResponseWriter, int64(len(w.chunks))+w.fileSize)
+	w.clearBody()
+}
+
+// clearBody resets the body without releasing it from the response body limit, i.e it's sent already.
+func (w *ResponseRecorder) clearBody() {
+	w.removeFile()
 	w.chunks = w.chunks[0:0]
 }
 
@@ -149,6 +222,11 @@ func (w *ResponseRecorder) Reset() {
 // FlushResponse the full body, headers and status code to the underline response writer
 // called automatically at the end of each request.
 func (w *ResponseRecorder) FlushResponse() {
+	if w.passing {
+		// the headers and the status code are sent on the switch to the pass-through mode.
+		return
+	}
+
 	// copy the headers to the underline response writer
 	if w.headers != nil {
 		h := w.ResponseWriter.Header()
@@ -166,8 +244,16 @@ func (w *ResponseRecorder) FlushResponse() {
 	w.ResponseWriter.FlushResponse()
 
 	if len(w.chunks) > 0 {
-		// ignore error
-		w.ResponseWriter.Write(w.chunks)
+		// ignore error, the chunks are already counted by the `Write`.
+		passthrough(w.ResponseWriter, func() {
+			w.ResponseWriter.Write(w.chunks)
+		})
+	}
+
+	if w.file != nil {
+		passthrough(w.ResponseWriter, func() {
+			io.Copy(w.ResponseWriter, w.BodyReader())
+		})
 	}
 }
 
@@ -176,7 +262,7 @@ func (w *ResponseRecorder) FlushResponse() {
 func (w *ResponseRecorder) Clone() ResponseWriter {
 	wc := &ResponseRecorder{}
 	wc.headers = w.headers
-	wc.chunks = w.chunks[0:]
+	wc.chunks = w.Body()[0:]
 	if resW, ok := w.ResponseWriter.(*responseWriter); ok {
 		wc.ResponseWriter = &(*resW) // clone it
 	} else { // else just copy, may pointer, developer can change its behavior
@@ -230,9 +316,9 @@ func (w *ResponseRecorder) WriteTo(res ResponseWriter) {
 		}
 
 		// append the body
-		if len(w.chunks) > 0 {
+		if body := w.Body(); len(body) > 0 {
 			// ignore error
-			to.Write(w.chunks)
+			to.Write(body)
 		}
 	}
 }
@@ -240,7 +326,7 @@ func (w *ResponseRecorder) WriteTo(res ResponseWriter) {
 // Flush sends any buffered data to the client.
 func (w *ResponseRecorder) Flush() {
 	w.ResponseWriter.Flush()
-	w.ResetBody()
+	w.clearBody()
 }
 
 // Push initiates an HTTP/2 server push. This constructs a synthetic
@@ -267,7 +353,7 @@ func (w *ResponseRecorder) Push(target string, opts *http.PushOptions) error {
 	w.FlushResponse()
 	err := w.ResponseWriter.Push(target, opts)
 	// NOTE: we have to reset them even if the push failed.
-	w.ResetBody()
+	w.clearBody()
 	w.ResetHeaders()
 
 	return err
@@ -0,0 +1,128 @@
+package context
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// RecorderOverflow is the behavior of a `ResponseRecorder` when its body exceeds
+// the limit of the `ResponseRecorder#SetMaxBodySize`.
+type RecorderOverflow uint8
+
+const (
+	// RecorderSpillToFile moves the recorded body to a temporary file
+	// and records the rest of it there, the file is removed when the request is done.
+	RecorderSpillToFile RecorderOverflow = iota
+	// RecorderPassThrough sends the headers, the status code and the recorded body to the client
+	// and writes the rest of it directly, the body cannot be reset or changed after that.
+	RecorderPassThrough
+)
+
+// RecordWithLimit is a middleware which records the response, like the `Recorder`,
+// with a limit of the in-memory body, see `ResponseRecorder#SetMaxBodySize`.
+var RecordWithLimit = func(maxBodySize int64, overflow RecorderOverflow) Handler {
+	return func(ctx Context) {
+		ctx.Recorder().SetMaxBodySize(maxBodySize, overflow)
+		ctx.Next()
+	}
+}
+
+// SetMaxBodySize sets a limit to the body that the recorder keeps in memory,
+// when it's exceeded the "overflow" is applied: the body is moved to a temporary file
+// or the recorder switches to the pass-through mode, see `RecorderSpillToFile` and `RecorderPassThrough`.
+// A zero or negative "limit" disables the limit, which is the default behavior.
+// 限制录制器在内存中缓存的响应体大小, 超出后写入临时文件或者直接发送给客户端
+func (w *ResponseRecorder) SetMaxBodySize(limit int64, overflow RecorderOverflow) {
+	w.maxBodySize = limit
+	w.overflow = overflow
+}
+
+// IsPassThrough reports whether the recorder switched to the pass-through mode,
+// the headers, the status code and the body are sent to the client already.
+// The middlewares which read or modify the recorded body should skip the response then:
+// the `Body` is empty and the `SetBody` is a no-op.
+func (w *ResponseRecorder) IsPassThrough() bool {
+	return w.passing
+}
+
+// SetRecordFilter sets a "filter" which is called on the first write of the body,
+// the headers and the status code are set already, i.e the "Content-Type".
+// If it returns false the recorder switches to the pass-through mode, like the `RecorderPassThrough`,
+// so a middleware can record only the responses it modifies, the "filter" can still modify the headers.
+// 在第一次写入响应体时决定是否录制, 不录制的响应直接发送给客户端
+func (w *ResponseRecorder) SetRecordFilter(filter func(w *ResponseRecorder) bool) {
+	w.filter = filter
+}
+
+// BodyReader returns a reader of the body recorded so far, even if it's spilled to a temporary file,
+// which does not buffer it, unlike the `Body`.
+// The body written after the call is not readable by the returned reader.
+func (w *ResponseRecorder) BodyReader() io.Reader {
+	if w.file != nil {
+		return io.NewSectionReader(w.file, 0, w.fileSize)
+	}
+
+	return bytes.NewReader(w.chunks)
+}
+
+// overflowBody applies the overflow behavior, the "contents" are the ones which exceeded the limit.
+func (w *ResponseRecorder) overflowBody(contents []byte) (err error) {
+	if w.overflow == RecorderPassThrough {
+		return w.passThrough(contents)
+	}
+
+	f, err := ioutil.TempFile("", "iris-recorder-")
+	if err != nil {
+		return err
+	}
+
+	w.file = f
+	for _, b := range [][]byte{w.chunks, contents} {
+		if err = w.writeFile(b); err != nil {
+			return err
+		}
+	}
+	w.chunks = w.chunks[0:0]
+	return nil
+}
+
+// passThrough sends the headers, the status code and the recorded body
+// and switches to the pass-through mode, the "contents" are written directly.
+func (w *ResponseRecorder) passThrough(contents []byte) (err error) {
+	w.FlushResponse()
+	w.chunks = w.chunks[0:0]
+	w.passing = true
+
+	// already counted by the `Write`.
+	passthrough(w.ResponseWriter, func() {
+		_, err = w.ResponseWriter.Write(contents)
+	})
+	return
+}
+
+func (w *ResponseRecorder) writeFile(contents []byte) error {
+	n, err := w.file.Write(contents)
+	w.fileSize += int64(n)
+	return err
+}
+
+// fileBody returns the body of the temporary file.
+func (w *ResponseRecorder) fileBody() []byte {
+	b := make([]byte, w.fileSize)
+	n, _ := w.file.ReadAt(b, 0)
+	return b[:n]
+}
+
+// removeFile removes the temporary file of the body, if any.
+func (w *ResponseRecorder) removeFile() {
+	if w.file == nil {
+		return
+	}
+
+	w.file.Close()
+	os.Remove(w.file.Name())
+	w.file = nil
+	w.fileSize = 0
+}
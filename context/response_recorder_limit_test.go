@@ -0,0 +1,133 @@
+package context_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestRecorderSpillToFile(t *testing.T) {
+	body := strings.Repeat("abcdefgh", 16)
+
+	app := iris.New()
+	app.Get("/", context.RecordWithLimit(10, context.RecorderSpillToFile), func(ctx iris.Context) {
+		ctx.Next()
+
+		w := ctx.Recorder()
+		if w.IsPassThrough() {
+			t.Fatalf("expected the recorder to not be in the pass-through mode")
+		}
+
+		if got := string(w.Body()); got != body {
+			t.Fatalf("expected the spilled body to be recorded but got %q", got)
+		}
+
+		b, err := ioutil.ReadAll(w.BodyReader())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(b); got != body {
+			t.Fatalf("expected the body reader to read the spilled body but got %q", got)
+		}
+	}, func(ctx iris.Context) {
+		for i := 0; i < len(body); i += 8 {
+			ctx.WriteString(body[i : i+8])
+		}
+	})
+	app.Get("/set", context.RecordWithLimit(10, context.RecorderSpillToFile), func(ctx iris.Context) {
+		ctx.Next()
+		ctx.Recorder().SetBodyString("replaced")
+	}, func(ctx iris.Context) {
+		ctx.WriteString(body)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").Expect().Status(httptest.StatusOK).Body().Equal(body)
+	e.GET("/set").Expect().Status(httptest.StatusOK).Body().Equal("replaced")
+}
+
+func TestRecorderPassThrough(t *testing.T) {
+	app := iris.New()
+	app.Get("/", context.RecordWithLimit(10, context.RecorderPassThrough), func(ctx iris.Context) {
+		ctx.Next()
+
+		w := ctx.Recorder()
+		if !w.IsPassThrough() {
+			t.Fatalf("expected the recorder to be in the pass-through mode")
+		}
+
+		if n := len(w.Body()); n != 0 {
+			t.Fatalf("expected an empty body in the pass-through mode but got %d bytes", n)
+		}
+
+		// the body is sent already, it's a no-op.
+		w.SetBodyString("replaced")
+		ctx.StatusCode(iris.StatusInternalServerError)
+	}, func(ctx iris.Context) {
+		ctx.Header("X-Early", "true")
+		ctx.StatusCode(iris.StatusAccepted)
+		ctx.WriteString("12345")
+		ctx.WriteString("6789012345")
+		ctx.WriteString("end")
+	})
+
+	e := httptest.New(t, app)
+	r := e.GET("/").Expect().Status(httptest.StatusAccepted)
+	r.Header("X-Early").Equal("true")
+	r.Body().Equal("123456789012345end")
+}
+
+func TestRecorderPassThroughETag(t *testing.T) {
+	body := strings.Repeat("a", 64)
+
+	app := iris.New()
+	app.Get("/", context.RecordWithLimit(10, context.RecorderPassThrough), context.ETagHandler(false), func(ctx iris.Context) {
+		ctx.WriteString(body)
+	})
+	app.Get("/small", context.RecordWithLimit(len64(body)+1, context.RecorderPassThrough), context.ETagHandler(false), func(ctx iris.Context) {
+		ctx.WriteString(body)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/").Expect().Status(httptest.StatusOK).Header(context.ETagHeaderKey).Empty()
+	etag := e.GET("/small").Expect().Status(httptest.StatusOK).Header(context.ETagHeaderKey).NotEmpty().Raw()
+	e.GET("/small").WithHeader("If-None-Match", etag).Expect().Status(httptest.StatusNotModified)
+}
+
+func TestRecorderFilter(t *testing.T) {
+	filter := func(ctx iris.Context) {
+		ctx.Record()
+		ctx.Recorder().SetRecordFilter(func(w *context.ResponseRecorder) bool {
+			w.Header().Set("X-Filtered", "true")
+			return w.Header().Get(context.ContentTypeHeaderKey) == context.ContentHTMLHeaderValue+"; charset=UTF-8"
+		})
+		ctx.Next()
+
+		if w := ctx.Recorder(); !w.IsPassThrough() {
+			w.SetBodyString("recorded")
+		}
+	}
+
+	app := iris.New()
+	app.Get("/html", filter, func(ctx iris.Context) {
+		ctx.HTML("<p>html</p>")
+	})
+	app.Get("/text", filter, func(ctx iris.Context) {
+		ctx.StatusCode(iris.StatusAccepted)
+		ctx.Text("text")
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/html").Expect().Status(httptest.StatusOK).Body().Equal("recorded")
+	r := e.GET("/text").Expect().Status(httptest.StatusAccepted)
+	r.Header("X-Filtered").Equal("true")
+	r.Body().Equal("text")
+}
+
+func len64(s string) int64 {
+	return int64(len(s))
+}
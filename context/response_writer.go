@@ -143,6 +143,14 @@ type responseWriter struct {
 	// Sometimes is useful to keep the event,
 	// so we keep one func only and let the user decide when he/she wants to override it with an empty func before the FireStatusCode (context's behavior)
 	beforeFlush func()
+
+	// the response body limit, see `Context#SetMaxResponseBodySize`.
+	maxBodySize        int64
+	bodySize           int64
+	bodySizeExceeded   bool
+	onBodySizeExceeded func()
+	// >0 while a wrapper flushes its already counted body.
+	passthrough int
 }
 
 var _ ResponseWriter = (*responseWriter)(nil)
@@ -166,6 +174,11 @@ func (w *responseWriter) Naive() http.ResponseWriter {
 // 这里接受的参数是原生的http.ResponseWriter，然后初始化了responseWriter
 func (w *responseWriter) BeginResponse(underline http.ResponseWriter) {
 	w.beforeFlush = nil
+	w.maxBodySize = 0
+	w.bodySize = 0
+	w.bodySizeExceeded = false
+	w.onBodySizeExceeded = nil
+	w.passthrough = 0
 	w.written = NoWritten
 	w.statusCode = defaultStatusCode
 	w.ResponseWriter = underline
@@ -235,6 +248,9 @@ func (w *responseWriter) tryWriteHeader() {
 // possible to maximize compatibility.
 // 当使用responseWrite调用Write()给客户端的时候
 func (w *responseWriter) Write(contents []byte) (int, error) {
+	if err := w.account(len(contents)); err != nil {
+		return 0, err
+	}
 	// 如果written为noWrite(-1)的话，则通过原生的responseWriter的writeHead来填写状态值，并将written变为0
 	w.tryWriteHeader()
 	n, err := w.ResponseWriter.Write(contents)
@@ -253,6 +269,9 @@ func (w *responseWriter) Writef(format string, a ...interface{}) (n int, err err
 //
 // Returns the number of bytes written and any write error encountered.
 func (w *responseWriter) WriteString(s string) (int, error) {
+	if err := w.account(len(s)); err != nil {
+		return 0, err
+	}
 	w.tryWriteHeader()
 	n, err := io.WriteString(w.ResponseWriter, s)
 	w.written += n
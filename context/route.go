@@ -1,6 +1,10 @@
 package context
 
-import "github.com/kataras/iris/macro"
+import (
+	"time"
+
+	"github.com/kataras/iris/macro"
+)
 
 // RouteReadOnly allows decoupled access to the current route
 // inside the context.
@@ -42,4 +46,37 @@ type RouteReadOnly interface {
 
 	// MainHandlerName returns the first registered handler for the route.
 	MainHandlerName() string
+
+	// Stats returns a snapshot of the route's statistics,
+	// they're kept and updated by the router on each request.
+	Stats() RouteStats
+
+	// Description returns the route's documentation string, if any.
+	Description() string
+	// Deprecation returns the route's deprecation, nil if the route is not deprecated.
+	Deprecation() *RouteDeprecation
+}
+
+// RouteStats describes the statistics of a route,
+// see `RouteReadOnly#Stats`.
+type RouteStats struct {
+	// Hits is the number of the served requests.
+	Hits uint64 `json:"hits"`
+	// Errors is the number of the requests that were responded
+	// with a not successful status code, see `StatusCodeNotSuccessful`.
+	Errors uint64 `json:"errors"`
+	// MeanLatency is the average time spent on the route's handlers.
+	MeanLatency time.Duration `json:"meanLatency"`
+	// LastError is the message of the last error, i.e "500 Internal Server Error", can be empty.
+	LastError string `json:"lastError,omitempty"`
+	// LastErrorTime is the time that the last error occurred.
+	LastErrorTime time.Time `json:"lastErrorTime,omitempty"`
+}
+
+// RouteDeprecation describes a deprecated route, see `RouteReadOnly#Deprecation`.
+type RouteDeprecation struct {
+	// Since is the time that the route is deprecated, can be zero.
+	Since time.Time `json:"since,omitempty"`
+	// Alternative is the URL or the path of the route's successor, can be empty.
+	Alternative string `json:"alternative,omitempty"`
 }
@@ -0,0 +1,86 @@
+package context
+
+const (
+	// CSPNonceContextKey is the context's values key of the current request's
+	// Content-Security-Policy nonce, it's set by the security middleware, i.e the `middleware/secure`.
+	// See `CSPNonce`.
+	CSPNonceContextKey = "iris.csp.nonce"
+	// CSRFTokenContextKey is the context's values key of the current request's CSRF token,
+	// it's set by a CSRF protection middleware.
+	CSRFTokenContextKey = "iris.csrf.token"
+
+	// CSPNonceViewDataKey is the view data key of the Content-Security-Policy nonce,
+	// i.e `<script nonce="{{ .cspNonce }}">` or `<script nonce="{{ cspNonce $ }}">` through the html view engine's function.
+	CSPNonceViewDataKey = "cspNonce"
+	// CSRFTokenViewDataKey is the view data key of the CSRF token,
+	// i.e `<input type="hidden" name="csrf_token" value="{{ .csrfToken }}">`.
+	CSRFTokenViewDataKey = "csrfToken"
+	// RequestIDViewDataKey is the view data key of the "X-Request-Id" request header's value.
+	RequestIDViewDataKey = "requestID"
+)
+
+// CSPNonce returns the Content-Security-Policy nonce of the current request
+// or empty if no security middleware generated one.
+func CSPNonce(ctx Context) string {
+	return ctx.Values().GetString(CSPNonceContextKey)
+}
+
+// CSRFToken returns the CSRF token of the current request
+// or empty if no CSRF protection middleware generated one.
+func CSRFToken(ctx Context) string {
+	return ctx.Values().GetString(CSRFTokenContextKey)
+}
+
+// securityViewData returns the security context of the current request which is exposed to the views,
+// it's empty if no security middleware is registered for the current route.
+func (ctx *context) securityViewData() Map {
+	nonce, token := CSPNonce(ctx), CSRFToken(ctx)
+	if nonce == "" && token == "" {
+		return nil
+	}
+
+	data := Map{
+		CSPNonceViewDataKey:  nonce,
+		CSRFTokenViewDataKey: token,
+	}
+	if requestID := ctx.GetHeader("X-Request-Id"); requestID != "" {
+		data[RequestIDViewDataKey] = requestID
+	}
+
+	return data
+}
+
+// withSecurityViewData injects the security context into the "bindingData"
+// of the `View` when it's a map and the keys are not already there,
+// so the handlers don't have to copy them on each request.
+// The custom struct view models are left untouched.
+// 自动将安全相关的数据(csp nonce, csrf token, request id)注入到模板数据中
+func (ctx *context) withSecurityViewData(bindingData interface{}) interface{} {
+	sec := ctx.securityViewData()
+	if sec == nil {
+		return bindingData
+	}
+
+	var m map[string]interface{}
+	switch v := bindingData.(type) {
+	case nil:
+		return sec
+	case Map:
+		m = v
+	case map[string]interface{}:
+		m = v
+	default:
+		return bindingData
+	}
+
+	// do not modify the given map, it may be shared between requests.
+	data := make(Map, len(m)+len(sec))
+	for k, v := range sec {
+		data[k] = v
+	}
+	for k, v := range m {
+		data[k] = v
+	}
+
+	return data
+}
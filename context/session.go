@@ -0,0 +1,6 @@
+package context
+
+// SessionContextKey is the context's values key of the request's session,
+// it's set by the sessions manager's middleware, see `sessions.Sessions#Handler`,
+// use the `sessions.Get(ctx)` to retrieve it.
+const SessionContextKey = "iris.session"
@@ -0,0 +1,65 @@
+package context
+
+import (
+	stdContext "context"
+	"time"
+)
+
+// stdContextContextKey is the context's values key of the request's standard context, see `StdContext`.
+const stdContextContextKey = "iris.std.context"
+
+// requestStdContext is the standard context of a request and its cancel function,
+// which is called on the connection's close and at the end of the request.
+type requestStdContext struct {
+	stdContext.Context
+	cancel stdContext.CancelFunc
+}
+
+// StdContext returns the standard library's context of the current request,
+// it's canceled when the client disconnects (through the `ResponseWriter#CloseNotifier`),
+// when the request's context is canceled (i.e on server shutdown) or when the request ends.
+//
+// Pass it to the database calls and the outgoing requests so they are canceled
+// as soon as there is no one to receive their result.
+// 返回与当前请求绑定的标准库context(客户端断开连接或请求结束时取消), 用于数据库调用等
+func StdContext(ctx Context) stdContext.Context {
+	values := ctx.Values()
+	if v, ok := values.Get(stdContextContextKey).(*requestStdContext); ok {
+		return v
+	}
+
+	c, cancel := stdContext.WithCancel(ctx.Request().Context())
+	v := &requestStdContext{Context: c, cancel: cancel}
+	values.Set(stdContextContextKey, v)
+
+	if notifier, ok := ctx.ResponseWriter().CloseNotifier(); ok {
+		closed := notifier.CloseNotify()
+		go func() {
+			select {
+			case <-closed:
+				cancel()
+			case <-c.Done():
+			}
+		}()
+	}
+
+	return v
+}
+
+// WithTimeout returns a child of the `StdContext` which is canceled after the "timeout" too,
+// the caller should call the returned cancel function as soon as the operation is completed.
+//
+// Usage:
+// c, cancel := context.WithTimeout(ctx, 2 * time.Second)
+// defer cancel()
+// rows, err := db.QueryContext(c, "SELECT ...")
+func WithTimeout(ctx Context, timeout time.Duration) (stdContext.Context, stdContext.CancelFunc) {
+	return stdContext.WithTimeout(StdContext(ctx), timeout)
+}
+
+// cancelStdContext cancels the request's standard context, if any, it's called at the end of the request.
+func cancelStdContext(ctx Context) {
+	if v, ok := ctx.Values().Get(stdContextContextKey).(*requestStdContext); ok {
+		v.cancel()
+	}
+}
@@ -0,0 +1,134 @@
+package context_test
+
+import (
+	stdContext "context"
+	"net/http"
+	stdhttptest "net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+)
+
+// closeNotifyRecorder is a response recorder of a client which disconnects when the "closed" is closed.
+type closeNotifyRecorder struct {
+	*stdhttptest.ResponseRecorder
+	closed chan bool
+}
+
+func (w *closeNotifyRecorder) CloseNotify() <-chan bool {
+	return w.closed
+}
+
+type stdContextKey struct{}
+
+func serveStd(t *testing.T, app *iris.Application, w http.ResponseWriter, r *http.Request) {
+	t.Helper()
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	app.ServeHTTP(w, r)
+}
+
+func isDone(c stdContext.Context) bool {
+	select {
+	case <-c.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestStdContext(t *testing.T) {
+	var c stdContext.Context
+
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		c = context.StdContext(ctx)
+		if context.StdContext(ctx) != c {
+			t.Fatalf("expected the same standard context")
+		}
+		if isDone(c) {
+			t.Fatalf("expected the standard context to not be canceled while the request is served")
+		}
+		// the values of the request's context.
+		if expected, got := "value", c.Value(stdContextKey{}); expected != got {
+			t.Fatalf("expected the value %q but got %v", expected, got)
+		}
+	})
+
+	r := stdhttptest.NewRequest(iris.MethodGet, "/", nil)
+	r = r.WithContext(stdContext.WithValue(r.Context(), stdContextKey{}, "value"))
+	serveStd(t, app, stdhttptest.NewRecorder(), r)
+
+	// at the end of the request.
+	if c == nil || !isDone(c) {
+		t.Fatalf("expected the standard context to be canceled at the end of the request")
+	}
+	if c.Err() != stdContext.Canceled {
+		t.Fatalf("expected the canceled error but got %v", c.Err())
+	}
+}
+
+func TestStdContextCanceled(t *testing.T) {
+	canceled := make(chan bool, 1)
+
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		select {
+		case <-context.StdContext(ctx).Done():
+			canceled <- true
+		case <-time.After(time.Second):
+			canceled <- false
+		}
+	})
+
+	// on the client's disconnect.
+	w := &closeNotifyRecorder{ResponseRecorder: stdhttptest.NewRecorder(), closed: make(chan bool)}
+	close(w.closed)
+	serveStd(t, app, w, stdhttptest.NewRequest(iris.MethodGet, "/", nil))
+	if !<-canceled {
+		t.Fatalf("expected the standard context to be canceled when the client disconnects")
+	}
+
+	// on the request's context cancel, i.e on server shutdown.
+	parent, cancel := stdContext.WithCancel(stdContext.Background())
+	cancel()
+	serveStd(t, app, stdhttptest.NewRecorder(), stdhttptest.NewRequest(iris.MethodGet, "/", nil).WithContext(parent))
+	if !<-canceled {
+		t.Fatalf("expected the standard context to be canceled with the request's context")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	var c stdContext.Context
+
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		select {
+		case <-timeout.Done():
+		case <-time.After(time.Second):
+		}
+		if timeout.Err() != stdContext.DeadlineExceeded {
+			t.Fatalf("expected the deadline exceeded error but got %v", timeout.Err())
+		}
+		// the request's one is not canceled.
+		if isDone(context.StdContext(ctx)) {
+			t.Fatalf("expected the standard context to not be canceled")
+		}
+
+		// not canceled here, the end of the request cancels it.
+		c, _ = context.WithTimeout(ctx, time.Hour)
+	})
+
+	serveStd(t, app, stdhttptest.NewRecorder(), stdhttptest.NewRequest(iris.MethodGet, "/", nil))
+	// a child of the request's one.
+	if !isDone(c) || c.Err() != stdContext.Canceled {
+		t.Fatalf("expected the timeout context to be canceled at the end of the request but got %v", c.Err())
+	}
+}
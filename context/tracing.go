@@ -0,0 +1,242 @@
+package context
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanContextKey is the context's values key of the request's span, see `GetSpan`.
+const SpanContextKey = "iris.span"
+
+// TraceparentHeaderKey is the header key of the W3C Trace Context "traceparent".
+const TraceparentHeaderKey = "traceparent"
+
+// SpanContext identifies a span of a trace, it's compatible with the W3C Trace Context
+// and the OpenTelemetry's trace.SpanContext.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+	// Remote reports whether it's propagated by the client, through the "traceparent" header.
+	Remote bool
+}
+
+// IsValid reports whether the trace and the span ids are not zero.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceIDString returns the hex encoded trace id.
+func (sc SpanContext) TraceIDString() string {
+	return hex.EncodeToString(sc.TraceID[:])
+}
+
+// SpanIDString returns the hex encoded span id.
+func (sc SpanContext) SpanIDString() string {
+	return hex.EncodeToString(sc.SpanID[:])
+}
+
+// Traceparent returns the W3C "traceparent" header's value of the span,
+// i.e to propagate the trace to an outgoing request, empty if it's not valid.
+func (sc SpanContext) Traceparent() string {
+	if !sc.IsValid() {
+		return ""
+	}
+
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceIDString() + "-" + sc.SpanIDString() + "-" + flags
+}
+
+// ParseTraceparent parses a W3C "traceparent" header's value, the second output is false if it's not valid.
+func ParseTraceparent(headerValue string) (SpanContext, bool) {
+	var sc SpanContext
+
+	parts := strings.Split(strings.TrimSpace(headerValue), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, false
+	}
+
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return sc, false
+	}
+
+	sc.Sampled = flags[0]&1 == 1
+	sc.Remote = true
+	return sc, sc.IsValid()
+}
+
+// SpanStatus is the status of a span, see `Span#SetStatus`.
+type SpanStatus uint8
+
+const (
+	// SpanStatusUnset is the default status of a span.
+	SpanStatusUnset SpanStatus = iota
+	// SpanStatusError marks a failed span, the router sets it on the 5xx responses.
+	SpanStatusError
+	// SpanStatusOK marks a span as successful explicitly.
+	SpanStatusOK
+)
+
+// Span is a traced operation, the router starts a server span for each request of a route,
+// see `Tracer`.
+type Span interface {
+	// SpanContext returns the identity of the span, to propagate it.
+	SpanContext() SpanContext
+	// SetAttribute sets an attribute of the span, i.e "http.status_code".
+	SetAttribute(key string, value interface{})
+	// SetStatus sets the status of the span and its description.
+	SetStatus(code SpanStatus, description string)
+	// RecordError records an error of the span, it does not change its status.
+	RecordError(err error)
+	// End completes the span.
+	End()
+}
+
+// Tracer starts the spans of the requests, see `Application#Tracer`.
+//
+// The router starts a span, named after the method and the template of the matched route,
+// i.e "GET /api/user/{id:uint64}" instead of the raw path, before the route's handlers, even the guards,
+// with the "http.method", "http.route" and "http.target" attributes,
+// and it ends it after them with the "http.status_code" attribute, the 5xx responses are marked as errors.
+//
+// It's implemented by `NewTracer` and by the OpenTelemetry adapter of the iris/otel package,
+// which extracts the remote parent of the request through its propagator
+// and updates the request's context through the `ResetRequest`, so the outgoing calls are traced too.
+type Tracer interface {
+	// Start starts the server span of the request.
+	Start(ctx Context, spanName string) Span
+}
+
+// SpanData is the recorded data of an ended span of the `NewTracer`.
+type SpanData struct {
+	Name        string
+	SpanContext SpanContext
+	// Parent is the remote parent of the "traceparent" request header, if any.
+	Parent            SpanContext
+	Start, End        time.Time
+	Attributes        map[string]interface{}
+	Status            SpanStatus
+	StatusDescription string
+	Errors            []error
+}
+
+// NewTracer returns a W3C Trace Context tracer, its spans continue the trace of the "traceparent" request header, if any,
+// and the "onEnd" receives their data when they're ended, i.e to log or export them.
+// The remote parent's sampled flag is kept, the new traces are sampled.
+func NewTracer(onEnd func(SpanData)) Tracer {
+	return &simpleTracer{onEnd: onEnd}
+}
+
+type simpleTracer struct {
+	onEnd func(SpanData)
+}
+
+func (t *simpleTracer) Start(ctx Context, spanName string) Span {
+	s := &simpleSpan{onEnd: t.onEnd}
+	s.data.Name = spanName
+	s.data.Start = time.Now()
+	s.data.Attributes = make(map[string]interface{})
+
+	sc := SpanContext{Sampled: true}
+	if parent, ok := ParseTraceparent(ctx.GetHeader(TraceparentHeaderKey)); ok {
+		s.data.Parent = parent
+		sc.TraceID, sc.Sampled = parent.TraceID, parent.Sampled
+	} else {
+		rand.Read(sc.TraceID[:])
+	}
+	rand.Read(sc.SpanID[:])
+	s.data.SpanContext = sc
+
+	return s
+}
+
+type simpleSpan struct {
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+	onEnd func(SpanData)
+}
+
+func (s *simpleSpan) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+func (s *simpleSpan) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	s.data.Attributes[key] = value
+	s.mu.Unlock()
+}
+
+func (s *simpleSpan) SetStatus(code SpanStatus, description string) {
+	s.mu.Lock()
+	s.data.Status, s.data.StatusDescription = code, description
+	s.mu.Unlock()
+}
+
+func (s *simpleSpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.data.Errors = append(s.data.Errors, err)
+	s.mu.Unlock()
+}
+
+func (s *simpleSpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if s.onEnd != nil {
+		data.Attributes = make(map[string]interface{}, len(s.data.Attributes))
+		for k, v := range s.data.Attributes {
+			data.Attributes[k] = v
+		}
+		s.onEnd(data)
+	}
+}
+
+// noopSpan is the span of the requests which are not traced.
+type noopSpan struct{}
+
+func (noopSpan) SpanContext() SpanContext         { return SpanContext{} }
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) SetStatus(SpanStatus, string)     {}
+func (noopSpan) RecordError(error)                {}
+func (noopSpan) End()                             {}
+
+// GetSpan returns the server span of the request, which is started by the router
+// and named after the matched route's template, a no-op one if the request is not traced.
+// A handler can record its errors and attributes to it, i.e `context.GetSpan(ctx).RecordError(err)`,
+// and propagate the trace through its `SpanContext`,
+// i.e `req.Header.Set("traceparent", context.GetSpan(ctx).SpanContext().Traceparent())`.
+func GetSpan(ctx Context) Span {
+	if s, ok := ctx.Values().Get(SpanContextKey).(Span); ok {
+		return s
+	}
+
+	return noopSpan{}
+}
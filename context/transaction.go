@@ -1,5 +1,7 @@
 package context
 
+import "github.com/kataras/iris/core/memstore"
+
 // TransactionErrResult could be named also something like 'MaybeError',
 // it is useful to send it on transaction.Complete in order to execute a custom error mesasge to the user.
 //
@@ -68,6 +70,11 @@ type Transaction struct {
 
 func newTransaction(from *context) *Transaction {
 	tempCtx := *from
+	// the transaction has its own execution, its `StopExecution` should not cancel the parent's one,
+	// it's canceled when the transaction ends, see `context#BeginTransaction`.
+	// Its values are copied, so the parent's execution state is not replaced.
+	tempCtx.values = append(memstore.Store(nil), from.values...)
+	tempCtx.values.Set(doneContextKey, newExecState())
 	writer := tempCtx.ResponseWriter().Clone()
 	tempCtx.ResetResponseWriter(writer)
 	t := &Transaction{
@@ -0,0 +1,99 @@
+package context
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+const forwardedHeaderKey = "Forwarded"
+
+// trustedProxies are the parsed `Configuration#TrustedProxies`, see `ConfigurationReadOnly#GetTrustedProxyNets`.
+type trustedProxies []*net.IPNet
+
+func (t trustedProxies) contains(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range t {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// peerIP returns the IP of the request's direct peer, without the port.
+func peerIP(r *http.Request) string {
+	addr := strings.TrimSpace(r.RemoteAddr)
+	if addr != "" {
+		// if addr has port use the net.SplitHostPort otherwise(error occurs) take as it is
+		if ip, _, err := net.SplitHostPort(addr); err == nil {
+			return ip
+		}
+	}
+
+	return addr
+}
+
+// IsFromTrustedProxy reports whether the direct peer of the request is one of the `Configuration#TrustedProxies`,
+// it's false if no trusted proxies are configured.
+// Use it before trusting a custom header which is set by a proxy, i.e a tenant or a user identifier.
+func IsFromTrustedProxy(ctx Context) bool {
+	trusted := trustedProxies(ctx.Application().ConfigurationReadOnly().GetTrustedProxyNets())
+	return len(trusted) > 0 && trusted.contains(net.ParseIP(peerIP(ctx.Request())))
+}
+
+// parseForwardedFor returns the "for" parameters of the "Forwarded" (RFC 7239) header values,
+// by order, i.e `for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8:cafe::17]:4711"`.
+func parseForwardedFor(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				pair = strings.TrimSpace(pair)
+				if len(pair) > 4 && strings.EqualFold(pair[:4], "for=") {
+					hops = append(hops, strings.Trim(pair[4:], `"`))
+				}
+			}
+		}
+	}
+
+	return hops
+}
+
+// hopIP returns the IP of a forwarded hop, without the brackets of an IPv6 and the port.
+func hopIP(hop string) string {
+	hop = strings.TrimSpace(hop)
+	if ip, _, err := net.SplitHostPort(hop); err == nil {
+		return ip
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(hop, "["), "]")
+}
+
+// forwardedClientIP returns the client's IP of the forwarded "hops", the first one is the client's
+// and each proxy appends its peer. The chain is walked from the right through the trusted hops only
+// and the first untrusted one is returned, so the spoofed entries of the client are skipped.
+// Without trusted proxies or on an invalid or an obfuscated, i.e "unknown", hop an empty string is returned.
+func forwardedClientIP(hops []string, trusted trustedProxies) string {
+	if len(hops) == 0 || len(trusted) == 0 {
+		return ""
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		h := hopIP(hops[i])
+		ip := net.ParseIP(h)
+		if ip == nil {
+			return ""
+		}
+
+		if !trusted.contains(ip) || i == 0 {
+			return h
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,51 @@
+package context
+
+// UpgradedConn is a connection which is upgraded from a request to another protocol,
+// i.e a websocket one, see `Context#Upgrade`.
+//
+// The websocket's `Connection` implements it, so the result of the `Upgrade`
+// can be converted to that through a type assertion
+// in order to use its events, rooms and broadcast features.
+type UpgradedConn interface {
+	// ID returns the connection's identifier.
+	ID() string
+	// Write writes a message with a specific type to the client,
+	// i.e `websocket.TextMessage` or `websocket.BinaryMessage`.
+	Write(messageType int, data []byte) error
+	// Wait blocks and serves the connection until it's closed,
+	// it should be called last, after the registration of its callbacks.
+	Wait()
+	// Disconnect closes the connection.
+	Disconnect() error
+}
+
+// Upgrader upgrades the connection of a request to another protocol,
+// the `websocket.Server` is an Upgrader.
+type Upgrader interface {
+	UpgradeContext(ctx Context) (UpgradedConn, error)
+}
+
+// Upgrade upgrades the connection of this request through the "upgrader",
+// i.e a websocket server. The upgraded connection keeps a reference to this Context,
+// so the route's parameters, the values and the session are still available through it.
+//
+// Example:
+// c, err := ctx.Upgrade(ws)
+// if err != nil { return }
+// conn := c.(websocket.Connection)
+// conn.OnMessage(func(data []byte) { ... })
+// conn.Wait()
+func (ctx *context) Upgrade(upgrader Upgrader) (UpgradedConn, error) {
+	return upgrader.UpgradeContext(ctx)
+}
+
+// LongLivedConn is a long-lived connection, i.e a websocket or an SSE one, which is registered
+// to the application through the `Application#RegisterLongLivedConn`, so the shutdown of its hosts
+// notifies it and closes it after a grace period, see `host#Supervisor.Connections`.
+type LongLivedConn interface {
+	// NotifyShutdown notifies the client that the server is shutting down,
+	// i.e through a close frame or a final SSE event, so it can close the connection itself.
+	NotifyShutdown()
+	// Close closes the connection.
+	Close() error
+}
@@ -0,0 +1,178 @@
+package context
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// UploadSink is the destination of the uploaded form files,
+// see `Context#UploadFormFilesToSink`.
+//
+// Implementations can write to the local disk (`DirSink`),
+// to the memory (`MemorySink`) or directly to a cloud storage adapter, i.e S3.
+// 上传文件的目的地，可以是本地磁盘、内存或者云存储
+type UploadSink interface {
+	// Open returns a writer which the contents of the file "name" will be written to.
+	// The writer is closed when the file was copied or on failure.
+	// It may be called concurrently.
+	Open(name string) (io.WriteCloser, error)
+}
+
+// UploadSinkFunc is an adapter to allow the use of an ordinary function as an `UploadSink`.
+type UploadSinkFunc func(name string) (io.WriteCloser, error)
+
+// Open calls f(name).
+func (f UploadSinkFunc) Open(name string) (io.WriteCloser, error) {
+	return f(name)
+}
+
+// DirSink returns an `UploadSink` which writes the files
+// to the system physical location "destDirectory".
+func DirSink(destDirectory string) UploadSink {
+	return UploadSinkFunc(func(name string) (io.WriteCloser, error) {
+		return os.OpenFile(filepath.Join(destDirectory, name),
+			os.O_WRONLY|os.O_CREATE, os.FileMode(0666))
+	})
+}
+
+// MemorySink is an `UploadSink` which keeps the uploaded files in memory,
+// useful for tests or for small files that are going to be processed right after.
+type MemorySink struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+var _ UploadSink = (*MemorySink)(nil)
+
+// NewMemorySink returns a new, empty, in-memory `UploadSink`.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{files: make(map[string][]byte)}
+}
+
+// Open returns a writer which stores the contents of the file "name"
+// when it's closed, it overrides any previous file with the same name.
+func (s *MemorySink) Open(name string) (io.WriteCloser, error) {
+	return &memorySinkFile{sink: s, name: name}, nil
+}
+
+// Get returns the contents of a stored file and true if found, otherwise nil and false.
+func (s *MemorySink) Get(name string) ([]byte, bool) {
+	s.mu.RLock()
+	b, ok := s.files[name]
+	s.mu.RUnlock()
+	return b, ok
+}
+
+// Names returns the names of the stored files.
+func (s *MemorySink) Names() []string {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.files))
+	for name := range s.files {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+	return names
+}
+
+type memorySinkFile struct {
+	bytes.Buffer
+	sink *MemorySink
+	name string
+}
+
+func (f *memorySinkFile) Close() error {
+	f.sink.mu.Lock()
+	f.sink.files[f.name] = f.Bytes()
+	f.sink.mu.Unlock()
+	return nil
+}
+
+// uploadProgress keeps track of the aggregate written bytes of the uploads
+// and reports them, serialized, to the caller's "progress" callback.
+type uploadProgress struct {
+	mu       sync.Mutex
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (p *uploadProgress) add(n int) {
+	if p.progress == nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.written += int64(n)
+	p.progress(p.written, p.total)
+	p.mu.Unlock()
+}
+
+type progressWriter struct {
+	io.Writer
+	p *uploadProgress
+}
+
+func (w progressWriter) Write(b []byte) (int, error) {
+	n, err := w.Writer.Write(b)
+	w.p.add(n)
+	return n, err
+}
+
+// uploadTo copies the form file "fh" to the "sink".
+func uploadTo(fh *multipart.FileHeader, sink UploadSink, p *uploadProgress) (int64, error) {
+	src, err := fh.Open()
+	if err != nil {
+		return 0, err
+	}
+	// 记得打开文件记得关闭
+	defer src.Close()
+
+	out, err := sink.Open(fh.Filename)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(progressWriter{out, p}, src)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	return n, err
+}
+
+// uploadAll copies all the "files" to the "sink" in parallel
+// and returns the total copied length or the first error.
+func uploadAll(files []*multipart.FileHeader, sink UploadSink, progress func(written, total int64)) (int64, error) {
+	p := &uploadProgress{progress: progress}
+	for _, fh := range files {
+		p.total += fh.Size
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		n        int64
+		firstErr error
+	)
+
+	for _, fh := range files {
+		wg.Add(1)
+		go func(fh *multipart.FileHeader) {
+			defer wg.Done()
+			n0, err0 := uploadTo(fh, sink, p)
+			mu.Lock()
+			if err0 != nil && firstErr == nil {
+				firstErr = err0
+			}
+			n += n0
+			mu.Unlock()
+		}(fh)
+	}
+
+	wg.Wait()
+	return n, firstErr
+}
@@ -0,0 +1,209 @@
+package context_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestUploadFormFilesToMemorySink(t *testing.T) {
+	sink := context.NewMemorySink()
+
+	var (
+		mu    sync.Mutex
+		calls [][2]int64
+	)
+	progress := func(written, total int64) {
+		mu.Lock()
+		calls = append(calls, [2]int64{written, total})
+		mu.Unlock()
+	}
+
+	app := iris.New()
+	app.Post("/", func(ctx iris.Context) {
+		n, err := ctx.UploadFormFilesToSink(sink, progress, func(ctx iris.Context, fh *multipart.FileHeader) {
+			if strings.HasSuffix(fh.Filename, ".exe") {
+				fh.Filename = "" // rejected.
+				return
+			}
+			fh.Filename = "user-" + fh.Filename
+		})
+		if err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.Writef("%d", n)
+	})
+
+	e := httptest.New(t, app)
+	e.POST("/").WithMultipart().
+		WithFileBytes("files", "a.txt", []byte("hello")).
+		WithFileBytes("files", "b.txt", []byte("world!")).
+		WithFileBytes("other", "c.exe", []byte("rejected")).
+		Expect().Status(iris.StatusOK).Body().Equal("11")
+
+	names := sink.Names()
+	sort.Strings(names)
+	if expected, got := "user-a.txt,user-b.txt", strings.Join(names, ","); expected != got {
+		t.Fatalf("expected the stored files %s but got %s", expected, got)
+	}
+	if b, _ := sink.Get("user-b.txt"); string(b) != "world!" {
+		t.Fatalf("expected the stored contents 'world!' but got '%s'", b)
+	}
+
+	// the aggregate progress of the accepted files.
+	if len(calls) == 0 {
+		t.Fatalf("expected the progress to be reported")
+	}
+	var prev int64
+	for _, c := range calls {
+		if c[1] != 11 || c[0] < prev {
+			t.Fatalf("expected an increasing written size of the total 11 but got %v", calls)
+		}
+		prev = c[0]
+	}
+	if last := calls[len(calls)-1]; last[0] != 11 {
+		t.Fatalf("expected the last progress to report all the 11 bytes but got %d", last[0])
+	}
+
+	e.POST("/").WithMultipart().WithFormField("name", "no files").
+		Expect().Status(iris.StatusBadRequest)
+}
+
+// barrierWriter blocks its writes until all the files of the upload are opened,
+// so it fails if the files are not uploaded in parallel.
+type barrierWriter struct {
+	io.Writer
+	opened <-chan struct{}
+}
+
+func (w barrierWriter) Write(b []byte) (int, error) {
+	select {
+	case <-w.opened:
+		return w.Writer.Write(b)
+	case <-time.After(2 * time.Second):
+		return 0, errors.New("the files are not uploaded in parallel")
+	}
+}
+
+func (w barrierWriter) Close() error {
+	return nil
+}
+
+func TestUploadFormFilesToSinkParallel(t *testing.T) {
+	const files = 3
+
+	var (
+		mu     sync.Mutex
+		count  int
+		opened = make(chan struct{})
+	)
+	sink := context.UploadSinkFunc(func(name string) (io.WriteCloser, error) {
+		mu.Lock()
+		if count++; count == files {
+			close(opened)
+		}
+		mu.Unlock()
+		return barrierWriter{ioutil.Discard, opened}, nil
+	})
+
+	app := iris.New()
+	app.Post("/", func(ctx iris.Context) {
+		n, err := ctx.UploadFormFilesToSink(sink, nil)
+		if err != nil {
+			ctx.StatusCode(iris.StatusInternalServerError)
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.Writef("%d", n)
+	})
+
+	e := httptest.New(t, app)
+	e.POST("/").WithMultipart().
+		WithFileBytes("files", "a.txt", []byte("a")).
+		WithFileBytes("files", "b.txt", []byte("bb")).
+		WithFileBytes("files", "c.txt", []byte("ccc")).
+		Expect().Status(iris.StatusOK).Body().Equal("6")
+}
+
+func TestUploadFormFilesToFailingSink(t *testing.T) {
+	errSink := errors.New("storage is unavailable")
+	memory := context.NewMemorySink()
+	sink := context.UploadSinkFunc(func(name string) (io.WriteCloser, error) {
+		if name == "bad.txt" {
+			return nil, errSink
+		}
+		return memory.Open(name)
+	})
+
+	app := iris.New()
+	app.Post("/", func(ctx iris.Context) {
+		n, err := ctx.UploadFormFilesToSink(sink, nil)
+		if err != errSink {
+			ctx.StatusCode(iris.StatusInternalServerError)
+			ctx.Writef("unexpected error: %v", err)
+			return
+		}
+
+		ctx.Writef("%d", n)
+	})
+
+	e := httptest.New(t, app)
+	// the first error is returned, the rest of the files are still uploaded.
+	e.POST("/").WithMultipart().
+		WithFileBytes("files", "good.txt", []byte("hello")).
+		WithFileBytes("files", "bad.txt", []byte("world!")).
+		Expect().Status(iris.StatusOK).Body().Equal("5")
+
+	if _, ok := memory.Get("good.txt"); !ok {
+		t.Fatalf("expected the good file to be stored")
+	}
+}
+
+func TestUploadFormFilesToDirSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := iris.New()
+	app.Post("/", func(ctx iris.Context) {
+		n, err := ctx.UploadFormFiles(dir)
+		if err != nil {
+			ctx.StatusCode(iris.StatusInternalServerError)
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.Writef("%d", n)
+	})
+
+	e := httptest.New(t, app)
+	e.POST("/").WithMultipart().
+		WithFileBytes("files", "a.txt", []byte("hello")).
+		Expect().Status(iris.StatusOK).Body().Equal("5")
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, got := "hello", string(b); expected != got {
+		t.Fatalf("expected the file's contents %s but got %s", expected, got)
+	}
+}
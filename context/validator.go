@@ -0,0 +1,106 @@
+package context
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Validator is the interface of the struct validators that the `Context#ReadJSON`, `Context#ReadXML`
+// and `Context#ReadForm` call after a successful unmarshal, register one through the `app.Validator`.
+//
+// It's completed by the go-playground/validator's `*validator.Validate` as it's.
+// 请求体绑定后的校验器
+type Validator interface {
+	Struct(s interface{}) error
+}
+
+// ValidationError is a single field's validation failure.
+type ValidationError struct {
+	// Field is the name of the field that failed.
+	Field string `json:"field" xml:"field" yaml:"Field"`
+	// Tag is the validation rule that failed, i.e "required" or "min".
+	Tag string `json:"tag,omitempty" xml:"tag,omitempty" yaml:"Tag"`
+	// Message is the human-readable explanation.
+	Message string `json:"message" xml:"message" yaml:"Message"`
+}
+
+// ValidationErrors is the structured error that the body readers return when the validation fails,
+// it can be sent to the client as it's, i.e through `ctx.JSON(errs)`.
+//
+// Usage:
+// if errs, ok := err.(context.ValidationErrors); ok { ctx.StatusCode(400); ctx.JSON(errs) }
+type ValidationErrors []ValidationError
+
+// Error returns the messages of the field errors separated by a new line.
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Message)
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// fieldError is completed by the go-playground/validator's `FieldError`.
+type fieldError interface {
+	error
+	Field() string
+	Tag() string
+}
+
+// toValidationErrors converts the "err" returned by a `Validator`
+// to `ValidationErrors`, if it's a list of field errors,
+// otherwise the "err" is returned as it's.
+func toValidationErrors(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(ValidationErrors); ok {
+		return err
+	}
+
+	if fieldErr, ok := err.(fieldError); ok {
+		return ValidationErrors{{Field: fieldErr.Field(), Tag: fieldErr.Tag(), Message: fieldErr.Error()}}
+	}
+
+	// i.e the validator.ValidationErrors which is a []validator.FieldError.
+	v := reflect.ValueOf(err)
+	if v.Kind() != reflect.Slice {
+		return err
+	}
+
+	errs := make(ValidationErrors, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		fieldErr, ok := v.Index(i).Interface().(fieldError)
+		if !ok {
+			return err
+		}
+
+		errs = append(errs, ValidationError{Field: fieldErr.Field(), Tag: fieldErr.Tag(), Message: fieldErr.Error()})
+	}
+
+	return errs
+}
+
+// validate calls the registered `Validator`, if any, for the struct values.
+func (ctx *context) validate(v interface{}) error {
+	validator := ctx.Application().GetValidator()
+	if validator == nil {
+		return nil
+	}
+
+	if typ := reflect.TypeOf(v); typ == nil || indirectType(typ).Kind() != reflect.Struct {
+		return nil
+	}
+
+	return toValidationErrors(validator.Struct(v))
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
@@ -0,0 +1,137 @@
+package context_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+// testFieldError and testFieldErrors look like the go-playground/validator's errors.
+type testFieldError struct {
+	field, tag string
+}
+
+func (e testFieldError) Error() string { return e.field + " failed on the " + e.tag + " rule" }
+func (e testFieldError) Field() string { return e.field }
+func (e testFieldError) Tag() string   { return e.tag }
+
+type testFieldErrors []testFieldError
+
+func (errs testFieldErrors) Error() string { return "validation failed" }
+
+type testValidator struct {
+	calls int
+}
+
+func (v *testValidator) Struct(s interface{}) error {
+	v.calls++
+
+	u := s.(*validatedUser)
+	switch {
+	case u.Username == "single":
+		return testFieldError{"Username", "alpha"}
+	case u.Username == "other":
+		return errors.New("other error")
+	}
+
+	var errs testFieldErrors
+	if u.Username == "" {
+		errs = append(errs, testFieldError{"Username", "required"})
+	}
+	if u.Age < 18 {
+		errs = append(errs, testFieldError{"Age", "min"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+type validatedUser struct {
+	Username string `json:"username" xml:"username" form:"username"`
+	Age      int    `json:"age" xml:"age" form:"age"`
+}
+
+func TestValidator(t *testing.T) {
+	v := new(testValidator)
+
+	app := iris.New()
+	app.Validator(v)
+	read := func(ctx iris.Context, readBody func(interface{}) error) {
+		var u validatedUser
+		err := readBody(&u)
+		if errs, ok := err.(context.ValidationErrors); ok {
+			ctx.StatusCode(iris.StatusBadRequest)
+			ctx.JSON(errs)
+			return
+		}
+		if err != nil {
+			ctx.StatusCode(iris.StatusInternalServerError)
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.Writef("%s %d", u.Username, u.Age)
+	}
+	app.Post("/json", func(ctx iris.Context) { read(ctx, ctx.ReadJSON) })
+	app.Post("/xml", func(ctx iris.Context) { read(ctx, ctx.ReadXML) })
+	app.Post("/form", func(ctx iris.Context) { read(ctx, ctx.ReadForm) })
+	app.Post("/map", func(ctx iris.Context) {
+		m := make(map[string]interface{})
+		if err := ctx.ReadJSON(&m); err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+		}
+	})
+
+	e := httptest.New(t, app)
+
+	e.POST("/json").WithJSON(validatedUser{"iris", 18}).Expect().Status(httptest.StatusOK).Body().Equal("iris 18")
+	e.POST("/json").WithJSON(validatedUser{"", 1}).Expect().Status(httptest.StatusBadRequest).JSON().Equal([]context.ValidationError{
+		{Field: "Username", Tag: "required", Message: "Username failed on the required rule"},
+		{Field: "Age", Tag: "min", Message: "Age failed on the min rule"},
+	})
+	// a single field error.
+	e.POST("/json").WithJSON(validatedUser{"single", 18}).Expect().Status(httptest.StatusBadRequest).JSON().Equal([]context.ValidationError{
+		{Field: "Username", Tag: "alpha", Message: "Username failed on the alpha rule"},
+	})
+	// the other errors are returned as they're.
+	e.POST("/json").WithJSON(validatedUser{"other", 18}).Expect().Status(httptest.StatusInternalServerError).Body().Equal("other error")
+
+	e.POST("/xml").WithHeader("Content-Type", "application/xml").WithBytes([]byte("<validatedUser><username>iris</username><age>18</age></validatedUser>")).
+		Expect().Status(httptest.StatusOK).Body().Equal("iris 18")
+	e.POST("/xml").WithHeader("Content-Type", "application/xml").WithBytes([]byte("<validatedUser><username>iris</username><age>1</age></validatedUser>")).
+		Expect().Status(httptest.StatusBadRequest).JSON().Array().Length().Equal(1)
+
+	e.POST("/form").WithFormField("username", "iris").WithFormField("age", "18").Expect().Status(httptest.StatusOK).Body().Equal("iris 18")
+	e.POST("/form").WithFormField("age", "18").Expect().Status(httptest.StatusBadRequest).JSON().Array().Length().Equal(1)
+
+	calls := v.calls
+	// the invalid bodies and the non-struct values are not validated.
+	e.POST("/json").WithBytes([]byte("{")).Expect().Status(httptest.StatusInternalServerError)
+	e.POST("/map").WithJSON(map[string]interface{}{"username": ""}).Expect().Status(httptest.StatusOK)
+	if calls != v.calls {
+		t.Fatalf("expected the validator to not be called")
+	}
+}
+
+func TestValidatorNotRegistered(t *testing.T) {
+	app := iris.New()
+	app.Post("/", func(ctx iris.Context) {
+		var u validatedUser
+		if err := ctx.ReadJSON(&u); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	httptest.New(t, app).POST("/").WithJSON(validatedUser{}).Expect().Status(httptest.StatusOK)
+}
+
+func TestValidationErrors(t *testing.T) {
+	errs := context.ValidationErrors{{Field: "A", Message: "a is required"}, {Field: "B", Message: "b is invalid"}}
+	if expected, got := "a is required\nb is invalid", errs.Error(); expected != got {
+		t.Fatalf("expected the error %q but got %q", expected, got)
+	}
+}
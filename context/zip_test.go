@@ -0,0 +1,157 @@
+package context_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+type closeReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func readZip(t *testing.T, body string) map[string]*zip.File {
+	t.Helper()
+
+	zr, err := zip.NewReader(strings.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]*zip.File)
+	for i, f := range zr.File {
+		if i > 0 && zr.File[i-1].Name > f.Name {
+			t.Fatalf("expected the entries sorted but got %q before %q", zr.File[i-1].Name, f.Name)
+		}
+		files[f.Name] = f
+	}
+
+	return files
+}
+
+func expectZipEntry(t *testing.T, files map[string]*zip.File, name, contents string, method uint16) {
+	t.Helper()
+
+	f, ok := files[name]
+	if !ok {
+		t.Fatalf("expected the %q entry", name)
+	}
+	if f.Method != method {
+		t.Fatalf("%s: expected the method %d but got %d", name, method, f.Method)
+	}
+
+	r, err := f.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != contents {
+		t.Fatalf("%s: expected the contents %q but got %q", name, contents, got)
+	}
+}
+
+func TestSendZip(t *testing.T) {
+	readme := &closeReader{Reader: strings.NewReader("read me")}
+
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		err := ctx.SendZip("export.zip", map[string]io.Reader{
+			"readme.txt":    readme,
+			"data/a.csv":    strings.NewReader("a,b\n1,2\n"),
+			"data/b.json":   bytes.NewBufferString(`{"b":1}`),
+			"data/empty.md": strings.NewReader(""),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	app.Get("/store", func(ctx iris.Context) {
+		ctx.SendZip("store.zip", map[string]io.Reader{"a.txt": strings.NewReader("a")}, flate.NoCompression)
+	})
+
+	e := httptest.New(t, app)
+
+	r := e.GET("/").Expect().Status(httptest.StatusOK)
+	r.ContentType("application/zip")
+	r.Header(context.ContentDispositionHeaderKey).Equal("attachment;filename=export.zip")
+
+	files := readZip(t, r.Body().Raw())
+	if len(files) != 4 {
+		t.Fatalf("expected 4 entries but got %d", len(files))
+	}
+	expectZipEntry(t, files, "readme.txt", "read me", zip.Deflate)
+	expectZipEntry(t, files, "data/a.csv", "a,b\n1,2\n", zip.Deflate)
+	expectZipEntry(t, files, "data/b.json", `{"b":1}`, zip.Deflate)
+	expectZipEntry(t, files, "data/empty.md", "", zip.Deflate)
+	if !readme.closed {
+		t.Fatalf("expected the reader to be closed")
+	}
+
+	files = readZip(t, e.GET("/store").Expect().Status(httptest.StatusOK).Body().Raw())
+	expectZipEntry(t, files, "a.txt", "a", zip.Store)
+}
+
+func TestSendZipFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	report := filepath.Join(dir, "report.csv")
+	if err = ioutil.WriteFile(report, []byte("id,name\n1,iris\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modtime := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err = os.Chtimes(report, modtime, modtime); err != nil {
+		t.Fatal(err)
+	}
+
+	app := iris.New()
+	app.Get("/{name:path}", func(ctx iris.Context) {
+		filename := filepath.Join(dir, ctx.Params().Get("name"))
+		if err := ctx.SendZipFiles("export.zip", map[string]string{"exports/report.csv": filename}); err != nil {
+			ctx.StatusCode(iris.StatusNotFound)
+		}
+	})
+
+	e := httptest.New(t, app)
+
+	r := e.GET("/report.csv").Expect().Status(httptest.StatusOK)
+	r.Header(context.ContentDispositionHeaderKey).Equal("attachment;filename=export.zip")
+
+	files := readZip(t, r.Body().Raw())
+	expectZipEntry(t, files, "exports/report.csv", "id,name\n1,iris\n", zip.Deflate)
+	if got := files["exports/report.csv"].Modified; !got.Equal(modtime) {
+		t.Fatalf("expected the modification time %s but got %s", modtime, got)
+	}
+
+	// nothing is written before the error.
+	e.GET("/missing.csv").Expect().Status(httptest.StatusNotFound).Header(context.ContentDispositionHeaderKey).Empty()
+	if err = os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/sub").Expect().Status(httptest.StatusNotFound).Header(context.ContentDispositionHeaderKey).Empty()
+}
@@ -0,0 +1,119 @@
+package host
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LongLivedConn is a long-lived connection, i.e a websocket or an SSE one,
+// which is not tracked by the `http.Server#Shutdown` after its hijack.
+type LongLivedConn interface {
+	// NotifyShutdown notifies the client that the server is shutting down,
+	// i.e through a close frame or a final SSE event, so it can close the connection itself.
+	NotifyShutdown()
+	// Close closes the connection.
+	Close() error
+}
+
+// DefaultShutdownGracePeriod is the default `Supervisor#ShutdownGracePeriod`.
+const DefaultShutdownGracePeriod = 5 * time.Second
+
+// ConnRegistry keeps the long-lived connections of the realtime modules,
+// so the `Supervisor#Shutdown` can notify them and close them after a grace period.
+// 长连接的注册表, 关闭服务时通知客户端并在宽限期后强制关闭
+type ConnRegistry struct {
+	mu      sync.Mutex
+	conns   map[*connEntry]struct{}
+	changed chan struct{}
+}
+
+type connEntry struct {
+	conn LongLivedConn
+}
+
+// NewConnRegistry returns a new empty connection registry.
+func NewConnRegistry() *ConnRegistry {
+	return &ConnRegistry{conns: make(map[*connEntry]struct{})}
+}
+
+// Register registers the "conn" and returns a function which unregisters it,
+// the caller should call it when the connection is closed.
+func (r *ConnRegistry) Register(conn LongLivedConn) (unregister func()) {
+	e := &connEntry{conn: conn}
+
+	r.mu.Lock()
+	r.conns[e] = struct{}{}
+	r.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.conns, e)
+			if r.changed != nil && len(r.conns) == 0 {
+				close(r.changed)
+				r.changed = nil
+			}
+			r.mu.Unlock()
+		})
+	}
+}
+
+// Len returns the number of the registered connections.
+func (r *ConnRegistry) Len() int {
+	r.mu.Lock()
+	n := len(r.conns)
+	r.mu.Unlock()
+	return n
+}
+
+func (r *ConnRegistry) list() []LongLivedConn {
+	r.mu.Lock()
+	conns := make([]LongLivedConn, 0, len(r.conns))
+	for e := range r.conns {
+		conns = append(conns, e.conn)
+	}
+	r.mu.Unlock()
+	return conns
+}
+
+// Shutdown notifies the registered connections and waits for them to be unregistered,
+// up to the "gracePeriod" or until the "ctx" is done, then it closes the rest of them.
+// It returns the number of the force-closed connections.
+func (r *ConnRegistry) Shutdown(ctx context.Context, gracePeriod time.Duration) int {
+	conns := r.list()
+	if len(conns) == 0 {
+		return 0
+	}
+
+	r.mu.Lock()
+	empty := make(chan struct{})
+	if len(r.conns) == 0 {
+		close(empty)
+	} else {
+		r.changed = empty
+	}
+	r.mu.Unlock()
+
+	for _, c := range conns {
+		c.NotifyShutdown()
+	}
+
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-empty:
+		return 0
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	remaining := r.list()
+	for _, c := range remaining {
+		c.Close()
+	}
+
+	return len(remaining)
+}
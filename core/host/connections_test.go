@@ -0,0 +1,87 @@
+package host
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testLongLivedConn struct {
+	// closes itself on notification, like a client which answers the close frame.
+	cooperative bool
+	unregister  func()
+	notified    int32
+	closed      int32
+}
+
+func (c *testLongLivedConn) NotifyShutdown() {
+	atomic.AddInt32(&c.notified, 1)
+	if c.cooperative {
+		go c.Close()
+	}
+}
+
+func (c *testLongLivedConn) Close() error {
+	atomic.AddInt32(&c.closed, 1)
+	c.unregister()
+	return nil
+}
+
+func TestSupervisorShutdownConnections(t *testing.T) {
+	srv := &http.Server{Handler: http.NotFoundHandler()}
+	su := New(srv)
+	su.Connections = NewConnRegistry()
+	su.ShutdownGracePeriod = 200 * time.Millisecond
+
+	cooperative, stubborn := &testLongLivedConn{cooperative: true}, new(testLongLivedConn)
+	cooperative.unregister = su.Connections.Register(cooperative)
+	stubborn.unregister = su.Connections.Register(stubborn)
+	if n := su.Connections.Len(); n != 2 {
+		t.Fatalf("expected 2 registered connections but got %d", n)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go su.Serve(l)
+	time.Sleep(20 * time.Millisecond)
+
+	start := time.Now()
+	if err = su.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed < su.ShutdownGracePeriod {
+		t.Fatalf("expected the shutdown to wait for the grace period but it returned after %s", elapsed)
+	}
+
+	for name, c := range map[string]*testLongLivedConn{"cooperative": cooperative, "stubborn": stubborn} {
+		if atomic.LoadInt32(&c.notified) != 1 || atomic.LoadInt32(&c.closed) != 1 {
+			t.Fatalf("expected the %s connection to be notified and closed once but got %d notifications and %d closes",
+				name, c.notified, c.closed)
+		}
+	}
+
+	if n := su.Connections.Len(); n != 0 {
+		t.Fatalf("expected no registered connections but got %d", n)
+	}
+}
+
+func TestConnRegistryShutdownCooperative(t *testing.T) {
+	r := NewConnRegistry()
+	c := &testLongLivedConn{cooperative: true}
+	c.unregister = r.Register(c)
+
+	start := time.Now()
+	if forced := r.Shutdown(context.Background(), 5*time.Second); forced != 0 {
+		t.Fatalf("expected no force-closed connections but got %d", forced)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the shutdown to return when the connections are closed but it took %s", elapsed)
+	}
+}
@@ -77,6 +77,24 @@ func NewProxy(hostAddr string, target *url.URL) *Supervisor {
 	return proxy
 }
 
+// ResolverProxyHandler returns a new ReverseProxy like the `ProxyHandler`
+// but the requests are sent to the endpoints of the "r" resolver,
+// the target's host is kept as the "Host" header, see `Resolver#Transport`.
+func ResolverProxyHandler(target *url.URL, r *Resolver) *httputil.ReverseProxy {
+	p := ProxyHandler(target)
+	p.Transport = r.Transport(p.Transport)
+	return p
+}
+
+// NewResolverProxy returns a new host (server supervisor) which
+// proxies all requests to the endpoints of the "r" resolver, see `ResolverProxyHandler`.
+func NewResolverProxy(hostAddr string, target *url.URL, r *Resolver) *Supervisor {
+	return New(&http.Server{
+		Addr:    hostAddr,
+		Handler: ResolverProxyHandler(target, r),
+	})
+}
+
 // NewRedirection returns a new host (server supervisor) which
 // redirects all requests to the target.
 // Usage:
@@ -0,0 +1,405 @@
+package host
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+// DefaultResolveInterval is the default interval that the `Resolver` refreshes its endpoints.
+var DefaultResolveInterval = 30 * time.Second
+
+var (
+	// ErrNoEndpoints is returned when a `Resolver` has no healthy endpoints.
+	ErrNoEndpoints    = errors.New("resolver: no healthy endpoints of '%s'")
+	errResolverTarget = errors.New("resolver: invalid target '%s', expected a '_service._proto.name' or a 'host:port'")
+)
+
+// LoadBalancePolicy is the upstream selection policy of a `Resolver`.
+type LoadBalancePolicy uint8
+
+const (
+	// RoundRobin selects the endpoints in turn.
+	RoundRobin LoadBalancePolicy = iota
+	// LeastConn selects the endpoint with the fewest in-flight requests.
+	LeastConn
+)
+
+// DNSLookup looks up the SRV and the A/AAAA records, the net.DefaultResolver implements it.
+type DNSLookup interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (cname string, addrs []*net.SRV, err error)
+	LookupHost(ctx context.Context, host string) (addrs []string, err error)
+}
+
+type endpoint struct {
+	addr     string
+	priority uint16
+	active   int64 // in-flight requests, accessed atomically.
+	down     int32 // non-zero when a request failed to reach it, until the next refresh.
+}
+
+// Resolver is a DNS-based service discovery, it resolves the endpoints of an upstream service
+// through its SRV or A/AAAA records, caches them and refreshes them periodically,
+// on its own goroutine, and it selects one of the healthy ones per request, see `Next`.
+// It's used by the `ResolverProxyHandler` and the outbound `Client`, through its `Transport`.
+// 基于DNS的服务发现(SRV/A记录), 带缓存、健康检查以及轮询/最少连接的负载均衡
+type Resolver struct {
+	// Interval is the interval between the refreshes of the endpoints.
+	// Defaults to the `DefaultResolveInterval`.
+	Interval time.Duration
+	// Timeout is the timeout of each lookup.
+	// Defaults to 5 seconds.
+	Timeout time.Duration
+	// Policy is the upstream selection policy.
+	// Defaults to `RoundRobin`.
+	Policy LoadBalancePolicy
+	// HealthCheck reports whether the endpoint of the "addr" is healthy,
+	// it's called on each refresh, the unhealthy endpoints are not selected.
+	// Defaults to nil, all the resolved endpoints are healthy.
+	HealthCheck func(addr string) bool
+	// Lookup is the DNS resolver.
+	// Defaults to the net.DefaultResolver.
+	Lookup DNSLookup
+	// OnError is called when a refresh fails, the previous endpoints are kept.
+	OnError func(error)
+
+	target               string
+	service, proto, name string // SRV.
+	host, port           string // A/AAAA.
+	srv                  bool
+	mu                   sync.RWMutex
+	endpoints            []*endpoint
+	next                 uint32
+	started              bool
+	once                 sync.Once
+	stop, done           chan struct{}
+}
+
+// NewResolver returns a new Resolver of the "target", a SRV name, i.e "_api._tcp.example.com",
+// or a "host:port" which is resolved through its A/AAAA records, i.e "api.internal:8080".
+// Call its `Start` to resolve the endpoints and `Stop` to stop the refreshes on shutdown.
+//
+// Usage:
+// r := host.NewResolver("_api._tcp.service.consul")
+// r.Policy = host.LeastConn
+// if err := r.Start(); err != nil { ... }
+// iris.RegisterOnInterrupt(r.Stop)
+// target, _ := url.Parse("http://api")
+// app.Any("/api/{p:path}", iris.FromStd(host.ResolverProxyHandler(target, r)))
+func NewResolver(target string) *Resolver {
+	return &Resolver{
+		Interval: DefaultResolveInterval,
+		Timeout:  5 * time.Second,
+		target:   target,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (r *Resolver) parseTarget() error {
+	if strings.HasPrefix(r.target, "_") {
+		parts := strings.SplitN(r.target, ".", 3)
+		if len(parts) != 3 || !strings.HasPrefix(parts[1], "_") || parts[2] == "" {
+			return errResolverTarget.Format(r.target)
+		}
+
+		r.srv = true
+		r.service, r.proto, r.name = parts[0][1:], parts[1][1:], parts[2]
+		return nil
+	}
+
+	host, port, err := net.SplitHostPort(r.target)
+	if err != nil || host == "" || port == "" {
+		return errResolverTarget.Format(r.target)
+	}
+
+	r.host, r.port = host, port
+	return nil
+}
+
+// Start resolves the endpoints and starts refreshing them on a new goroutine.
+// It returns an error if the target is invalid or the first resolution fails.
+func (r *Resolver) Start() error {
+	if err := r.parseTarget(); err != nil {
+		return err
+	}
+
+	if r.Lookup == nil {
+		r.Lookup = net.DefaultResolver
+	}
+
+	if err := r.Refresh(); err != nil {
+		return err
+	}
+	r.started = true
+
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultResolveInterval
+	}
+
+	go r.loop(interval)
+	return nil
+}
+
+func (r *Resolver) loop(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if err := r.Refresh(); err != nil && r.OnError != nil {
+				r.OnError(err)
+			}
+		}
+	}
+}
+
+// Stop stops the refreshes, the cached endpoints are still selected.
+// It's safe to be called more than once.
+func (r *Resolver) Stop() {
+	r.once.Do(func() {
+		close(r.stop)
+		if r.started {
+			<-r.done
+		}
+	})
+}
+
+func (r *Resolver) lookup() ([]*endpoint, error) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var endpoints []*endpoint
+	if r.srv {
+		_, records, err := r.Lookup.LookupSRV(ctx, r.service, r.proto, r.name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rec := range records {
+			endpoints = append(endpoints, &endpoint{
+				addr:     net.JoinHostPort(strings.TrimSuffix(rec.Target, "."), strconv.Itoa(int(rec.Port))),
+				priority: rec.Priority,
+			})
+		}
+		return endpoints, nil
+	}
+
+	hosts, err := r.Lookup.LookupHost(ctx, r.host)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range hosts {
+		endpoints = append(endpoints, &endpoint{addr: net.JoinHostPort(h, r.port)})
+	}
+	return endpoints, nil
+}
+
+// healthy returns the endpoints which pass the `HealthCheck`, the checks run concurrently.
+func (r *Resolver) healthy(endpoints []*endpoint) []*endpoint {
+	if r.HealthCheck == nil {
+		return endpoints
+	}
+
+	ok := make([]bool, len(endpoints))
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			ok[i] = r.HealthCheck(addr)
+		}(i, e.addr)
+	}
+	wg.Wait()
+
+	healthy := endpoints[:0]
+	for i, e := range endpoints {
+		if ok[i] {
+			healthy = append(healthy, e)
+		}
+	}
+	return healthy
+}
+
+// Refresh resolves and health checks the endpoints now, after the `Start`,
+// the previous ones are kept if it fails or there is no healthy endpoint.
+// Of the SRV records, the healthy ones of the lowest priority value are selected only.
+func (r *Resolver) Refresh() error {
+	endpoints, err := r.lookup()
+	if err != nil {
+		return err
+	}
+
+	endpoints = r.healthy(endpoints)
+	if len(endpoints) == 0 {
+		return ErrNoEndpoints.Format(r.target)
+	}
+
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		return endpoints[i].priority < endpoints[j].priority
+	})
+	for i, e := range endpoints {
+		if e.priority != endpoints[0].priority {
+			endpoints = endpoints[:i]
+			break
+		}
+	}
+
+	r.mu.Lock()
+	// keep the existing endpoints, their in-flight requests are tracked for the `LeastConn`.
+	for i, e := range endpoints {
+		for _, prev := range r.endpoints {
+			if prev.addr == e.addr {
+				prev.priority = e.priority
+				atomic.StoreInt32(&prev.down, 0)
+				endpoints[i] = prev
+				break
+			}
+		}
+	}
+	r.endpoints = endpoints
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Endpoints returns the addresses of the selectable endpoints.
+func (r *Resolver) Endpoints() []string {
+	r.mu.RLock()
+	addrs := make([]string, 0, len(r.endpoints))
+	for _, e := range r.endpoints {
+		addrs = append(addrs, e.addr)
+	}
+	r.mu.RUnlock()
+
+	return addrs
+}
+
+// MarkDown excludes the endpoint of the "addr" from the selection until the next refresh,
+// the `Transport` calls it when a request fails to reach it.
+func (r *Resolver) MarkDown(addr string) {
+	r.mu.RLock()
+	for _, e := range r.endpoints {
+		if e.addr == addr {
+			atomic.StoreInt32(&e.down, 1)
+		}
+	}
+	r.mu.RUnlock()
+}
+
+// Next selects an endpoint based on the `Policy` and returns its address
+// and a function which should be called when the request to it is completed.
+// The endpoints which are marked as down are selected only if all of them are down.
+func (r *Resolver) Next() (addr string, done func(), err error) {
+	r.mu.RLock()
+	all := r.endpoints
+	r.mu.RUnlock()
+
+	endpoints := make([]*endpoint, 0, len(all))
+	for _, e := range all {
+		if atomic.LoadInt32(&e.down) == 0 {
+			endpoints = append(endpoints, e)
+		}
+	}
+	if len(endpoints) == 0 {
+		endpoints = all
+	}
+	if len(endpoints) == 0 {
+		return "", nil, ErrNoEndpoints.Format(r.target)
+	}
+
+	start := int(atomic.AddUint32(&r.next, 1)-1) % len(endpoints)
+	e := endpoints[start]
+	if r.Policy == LeastConn {
+		for i := 1; i < len(endpoints); i++ {
+			candidate := endpoints[(start+i)%len(endpoints)]
+			if atomic.LoadInt64(&candidate.active) < atomic.LoadInt64(&e.active) {
+				e = candidate
+			}
+		}
+	}
+
+	atomic.AddInt64(&e.active, 1)
+	var once sync.Once
+	return e.addr, func() { once.Do(func() { atomic.AddInt64(&e.active, -1) }) }, nil
+}
+
+// Transport returns a RoundTripper which sends the requests to the selected endpoints,
+// the request's URL host is replaced by the endpoint's address and it's kept as the "Host" header.
+// A nil "base" defaults to the http.DefaultTransport.
+func (r *Resolver) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &resolverTransport{resolver: r, base: base}
+}
+
+// Client returns a new http.Client which sends the requests to the selected endpoints,
+// i.e client.Get("http://api/users"), see `Transport`.
+func (r *Resolver) Client(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: r.Transport(nil)}
+}
+
+type resolverTransport struct {
+	resolver *Resolver
+	base     http.RoundTripper
+}
+
+func (t *resolverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	addr, done, err := t.resolver.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	outreq := req.Clone(req.Context())
+	if outreq.Host == "" {
+		outreq.Host = req.URL.Host
+	}
+	outreq.URL.Host = addr
+
+	res, err := t.base.RoundTrip(outreq)
+	if err != nil {
+		if req.Context().Err() == nil {
+			t.resolver.MarkDown(addr)
+		}
+		done()
+		return nil, err
+	}
+
+	res.Body = &releaseOnClose{ReadCloser: res.Body, done: done}
+	return res, nil
+}
+
+// releaseOnClose completes the request of a `Resolver#Next` when its response body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	done func()
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.done()
+	return err
+}
@@ -0,0 +1,150 @@
+package host
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type testLookup struct {
+	mu    sync.Mutex
+	srv   []*net.SRV
+	hosts []string
+}
+
+func (l *testLookup) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return name, l.srv, nil
+}
+
+func (l *testLookup) LookupHost(ctx context.Context, host string) ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.hosts, nil
+}
+
+func newResolverTestServer(t *testing.T, name string) (*httptest.Server, *net.SRV) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(name + ":" + r.Host + r.URL.Path))
+	}))
+
+	host, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return srv, &net.SRV{Target: host + ".", Port: uint16(p), Priority: 10}
+}
+
+func TestResolverRoundRobin(t *testing.T) {
+	a, recA := newResolverTestServer(t, "a")
+	defer a.Close()
+	b, recB := newResolverTestServer(t, "b")
+	defer b.Close()
+	// a lower priority group which is not selected while the first one is healthy.
+	backup := &net.SRV{Target: "backup.", Port: 80, Priority: 20}
+
+	lookup := &testLookup{srv: []*net.SRV{backup, recA, recB}}
+	r := NewResolver("_api._tcp.example.com")
+	r.Lookup = lookup
+	if err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	if expected, got := 2, len(r.Endpoints()); expected != got {
+		t.Fatalf("expected %d endpoints but got %d: %v", expected, got, r.Endpoints())
+	}
+
+	target, _ := url.Parse("http://api")
+	proxy := httptest.NewServer(ResolverProxyHandler(target, r))
+	defer proxy.Close()
+
+	got := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		res, err := http.Get(proxy.URL + "/users")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		got[string(body)]++
+	}
+
+	if got["a:api/users"] != 2 || got["b:api/users"] != 2 {
+		t.Fatalf("expected the requests to be balanced between the endpoints but got: %v", got)
+	}
+
+	// the unhealthy endpoints are filtered out on refresh.
+	r.HealthCheck = func(addr string) bool { return addr != b.Listener.Addr().String() }
+	if err := r.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	client := r.Client(0)
+	for i := 0; i < 2; i++ {
+		res, err := client.Get("http://api/health")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if expected, got := "a:api/health", string(body); expected != got {
+			t.Fatalf("expected %q but got %q", expected, got)
+		}
+	}
+
+	// no healthy endpoints keeps the previous ones.
+	r.HealthCheck = func(string) bool { return false }
+	if err := r.Refresh(); !ErrNoEndpoints.Equal(err) {
+		t.Fatalf("expected the no endpoints error but got: %v", err)
+	}
+	if expected, got := 1, len(r.Endpoints()); expected != got {
+		t.Fatalf("expected %d endpoints to be kept but got %d", expected, got)
+	}
+}
+
+func TestResolverLeastConn(t *testing.T) {
+	r := NewResolver("api.internal:8080")
+	r.Lookup = &testLookup{hosts: []string{"10.0.0.1", "10.0.0.2"}}
+	r.Policy = LeastConn
+	if err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	first, done, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first one is busy.
+	for i := 0; i < 3; i++ {
+		addr, release, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr == first {
+			t.Fatalf("expected the least busy endpoint but got the busy %s", addr)
+		}
+		release()
+	}
+
+	done()
+	r.MarkDown(first)
+	for i := 0; i < 2; i++ {
+		addr, release, _ := r.Next()
+		if addr == first {
+			t.Fatalf("expected the endpoint %s which is down to be skipped", first)
+		}
+		release()
+	}
+
+	if err := NewResolver("api.internal").Start(); err == nil {
+		t.Fatal("expected an error of a target without a port")
+	}
+}
@@ -0,0 +1,129 @@
+package host
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kataras/iris/core/errors"
+	"github.com/kataras/iris/core/netutil"
+)
+
+var (
+	errRestartNoListener = errors.New("hot restart: the host is not served yet")
+	errRestartNotReady   = errors.New("hot restart: the new process exited before it served the listener. Trace: %v")
+)
+
+// Restart performs a zero-downtime binary upgrade, i.e after the executable is replaced by a new version.
+// It starts a new process of the executable, with the same arguments and environment,
+// which inherits the served listener through its file descriptor, see `netutil#ListenFDsEnv`,
+// waits for it to serve the listener, see `netutil#NotifyReady`, and then it shuts down
+// this host gracefully, through the `Shutdown` and its drain phase, if any.
+// The new process serves the inherited listener on its `ListenAndServe`, `ListenAndServeTLS`,
+// `ListenAndServeAutoTLS` or `Serve`, so the connections are never refused in the meantime.
+//
+// If the new process exits, or the "ctx" is done, before it serves the listener
+// then this host keeps serving and the error is returned.
+//
+// Each host is restarted separately, the hosts of the same process should be restarted
+// together through the `RestartOnSignal` of each one.
+// The listeners of the `Serve` should be tcp or unix ones, not wrapped.
+// 热重启: 启动新进程并把监听器的文件描述符传给它, 等待新进程开始服务, 然后优雅地关闭当前的服务
+func (su *Supervisor) Restart(ctx context.Context) error {
+	su.mu.Lock()
+	l := su.listener
+	su.mu.Unlock()
+
+	if l == nil {
+		return errRestartNoListener
+	}
+
+	f, err := netutil.ListenerFile(l)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyReader.Close()
+
+	env := make([]string, 0, len(os.Environ())+2)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, netutil.ListenFDsEnv+"=") && !strings.HasPrefix(kv, netutil.ReadyFDEnv+"=") {
+			env = append(env, kv)
+		}
+	}
+	// the extra files are the descriptors 3 and 4 of the child process,
+	// 0, 1 and 2 are the standard input, output and error.
+	env = append(env, netutil.ListenFDsEnv+"="+restartKey(su.Server.Addr, l)+"=3", netutil.ReadyFDEnv+"=4")
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = []*os.File{f, readyWriter}
+	err = cmd.Start()
+	// the child has its own copy, the reader gets EOF when the child closes it.
+	readyWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	// the descriptor is passed in blocking mode, which is shared with the served listener,
+	// its accepts would block and its close would wait for a new connection.
+	if err = setNonblock(f); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+
+	// the child outlives this process, its exit status is collected when it exits.
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	ready := make(chan error, 1)
+	go func() {
+		_, err := readyReader.Read(make([]byte, 1))
+		ready <- err
+	}()
+
+	select {
+	case err = <-ready:
+		if err != nil {
+			cmd.Process.Kill()
+			return errRestartNotReady.Format(err)
+		}
+	case err = <-exited:
+		return errRestartNotReady.Format(err)
+	case <-ctx.Done():
+		cmd.Process.Kill()
+		return ctx.Err()
+	}
+
+	// the unix socket's file is used by the child, it should not be removed on shutdown.
+	if ul, ok := l.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+
+	return su.Shutdown(ctx)
+}
+
+// restartKey returns the key of the "l" listener of the `netutil#ListenFDsEnv`,
+// the server's address or, if it's empty (i.e on `Serve`), the listener's one.
+func restartKey(addr string, l net.Listener) string {
+	if addr != "" {
+		return addr
+	}
+	return l.Addr().String()
+}
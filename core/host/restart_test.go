@@ -0,0 +1,260 @@
+//go:build !windows
+
+package host
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/core/netutil"
+)
+
+// restartChildEnv is the mode of the test binary when it's started as the child of a `Restart`.
+const restartChildEnv = "IRIS_TEST_RESTART_CHILD"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(restartChildEnv); mode != "" && os.Getenv(netutil.ListenFDsEnv) != "" {
+		runRestartChild(mode)
+		return
+	}
+
+	os.Exit(m.Run())
+}
+
+func runRestartChild(mode string) {
+	if mode == "fail" {
+		os.Exit(1)
+	}
+
+	entry := os.Getenv(netutil.ListenFDsEnv)
+	addr := entry[:strings.LastIndexByte(entry, '=')]
+
+	su := New(&http.Server{Addr: addr, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pid":
+			w.Write([]byte(strconv.Itoa(os.Getpid())))
+		case "/exit":
+			w.Write([]byte("bye"))
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				os.Exit(0)
+			}()
+		default:
+			w.Write([]byte("child"))
+		}
+	})})
+
+	var err error
+	if mode == "unix" {
+		// the listener of the `Serve` is replaced by the inherited one.
+		var l net.Listener
+		if l, err = net.Listen("unix", addr+".child"); err == nil {
+			err = su.Serve(l)
+		}
+	} else {
+		err = su.ListenAndServe()
+	}
+
+	if err != nil && err != http.ErrServerClosed {
+		os.Exit(2)
+	}
+	os.Exit(0)
+}
+
+func textHandler(text string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(text))
+	})
+}
+
+func waitListen(t *testing.T, su *Supervisor) {
+	for i := 0; su.Addr() == nil; i++ {
+		if i == 500 {
+			t.Fatal("timeout while waiting for the listener")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func restartGet(t *testing.T, client *http.Client, url string) string {
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// waitExit waits for the "pid" process to exit and to be reaped by its parent,
+// a zombie process still accepts the signal 0.
+func waitExit(t *testing.T, pid int) {
+	for i := 0; syscall.Kill(pid, 0) == nil; i++ {
+		if i == 500 {
+			t.Fatalf("the process %d is still there", pid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	os.Setenv(restartChildEnv, "serve")
+	defer os.Unsetenv(restartChildEnv)
+
+	su := New(&http.Server{Addr: "127.0.0.1:0", Handler: textHandler("parent")})
+	served := make(chan error, 1)
+	go func() { served <- su.ListenAndServe() }()
+	waitListen(t, su)
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	url := "http://" + su.Addr().String()
+	if expected, got := "parent", restartGet(t, client, url); expected != got {
+		t.Fatalf("expected '%s' but got '%s'", expected, got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := su.Restart(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-served; err != http.ErrServerClosed {
+		t.Fatalf("expected the parent to be closed but got: %v", err)
+	}
+
+	// the child serves the same address.
+	if expected, got := "child", restartGet(t, client, url); expected != got {
+		t.Fatalf("expected '%s' but got '%s'", expected, got)
+	}
+
+	pid, err := strconv.Atoi(restartGet(t, client, url+"/pid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	restartGet(t, client, url+"/exit")
+	waitExit(t, pid)
+}
+
+func TestRestartNotReady(t *testing.T) {
+	os.Setenv(restartChildEnv, "fail")
+	defer os.Unsetenv(restartChildEnv)
+
+	su := New(&http.Server{Addr: "127.0.0.1:0", Handler: textHandler("parent")})
+	go su.ListenAndServe()
+	defer su.Shutdown(context.Background())
+	waitListen(t, su)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := su.Restart(ctx); !errRestartNotReady.Equal(err) {
+		t.Fatalf("expected the not ready error but got: %v", err)
+	}
+
+	// the parent keeps serving.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	if expected, got := "parent", restartGet(t, client, "http://"+su.Addr().String()); expected != got {
+		t.Fatalf("expected '%s' but got '%s'", expected, got)
+	}
+}
+
+func TestRestartUnix(t *testing.T) {
+	os.Setenv(restartChildEnv, "unix")
+	defer os.Unsetenv(restartChildEnv)
+
+	path := filepath.Join(t.TempDir(), "iris.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	su := New(&http.Server{Handler: textHandler("parent")})
+	served := make(chan error, 1)
+	go func() { served <- su.Serve(l) }()
+	waitListen(t, su)
+
+	client := &http.Client{Transport: &http.Transport{
+		DisableKeepAlives: true,
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return new(net.Dialer).DialContext(ctx, "unix", path)
+		},
+	}}
+	if expected, got := "parent", restartGet(t, client, "http://unix"); expected != got {
+		t.Fatalf("expected '%s' but got '%s'", expected, got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err = su.Restart(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-served; err != http.ErrServerClosed {
+		t.Fatalf("expected the parent to be closed but got: %v", err)
+	}
+
+	// the socket's file is not removed by the parent's shutdown.
+	if expected, got := "child", restartGet(t, client, "http://unix"); expected != got {
+		t.Fatalf("expected '%s' but got '%s'", expected, got)
+	}
+
+	pid, err := strconv.Atoi(restartGet(t, client, "http://unix/pid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	restartGet(t, client, "http://unix/exit")
+	waitExit(t, pid)
+}
+
+func TestServeInherited(t *testing.T) {
+	parent, err := netutil.TCPKeepAlive("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parent.Close()
+
+	f, err := netutil.ListenerFile(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	addr := parent.Addr().String()
+	os.Setenv(netutil.ListenFDsEnv, addr+"="+strconv.Itoa(int(f.Fd())))
+	defer os.Unsetenv(netutil.ListenFDsEnv)
+
+	other, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	su := New(&http.Server{Addr: addr, Handler: textHandler("inherited")})
+	go su.Serve(other)
+	defer su.Shutdown(context.Background())
+	waitListen(t, su)
+
+	if expected, got := addr, su.Addr().String(); expected != got {
+		t.Fatalf("expected the inherited listener of %s but got %s", expected, got)
+	}
+	// the given listener is closed.
+	if conn, err := net.Dial("tcp", other.Addr().String()); err == nil {
+		conn.Close()
+		t.Fatal("expected the given listener to be closed")
+	}
+
+	parent.Close()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	if expected, got := "inherited", restartGet(t, client, "http://"+addr); expected != got {
+		t.Fatalf("expected '%s' but got '%s'", expected, got)
+	}
+}
@@ -0,0 +1,62 @@
+//go:build !windows
+
+package host
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// setNonblock sets the "f"'s descriptor to non-blocking mode.
+func setNonblock(f *os.File) error {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	if cerr := rc.Control(func(fd uintptr) {
+		err = syscall.SetNonblock(int(fd), true)
+	}); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// RestartOnSignal listens for the SIGUSR2 signal, i.e `kill -USR2 <pid>`, and performs a `Restart`
+// with the "shutdownTimeout" for the graceful shutdown of this process, the errors are passed to the `RegisterOnError` callbacks.
+// The listening is stopped on `Shutdown`.
+//
+// Usage:
+//
+//	app.Run(iris.Addr(":8080", func(su *host.Supervisor) {
+//		su.DrainTimeout = 10 * time.Second
+//		su.RestartOnSignal(15 * time.Second)
+//	}))
+func (su *Supervisor) RestartOnSignal(shutdownTimeout time.Duration) error {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR2)
+
+	stop := make(chan struct{})
+	su.RegisterOnShutdown(func() {
+		signal.Stop(ch)
+		close(stop)
+	})
+
+	go func() {
+		select {
+		case <-stop:
+		case <-ch:
+			signal.Stop(ch)
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			if err := su.Restart(ctx); err != nil {
+				su.notifyErr(err)
+			}
+		}
+	}()
+
+	return nil
+}
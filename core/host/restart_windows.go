@@ -0,0 +1,23 @@
+//go:build windows
+
+package host
+
+import (
+	"os"
+	"time"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+var errRestartSignalNotSupported = errors.New("hot restart: the SIGUSR2 signal is not supported on windows")
+
+// RestartOnSignal is not supported on windows, there is no SIGUSR2 signal and the
+// listeners can't be inherited by a child process, it returns an error.
+func (su *Supervisor) RestartOnSignal(shutdownTimeout time.Duration) error {
+	return errRestartSignalNotSupported
+}
+
+// setNonblock does nothing, the listeners can't be inherited on windows.
+func setNonblock(f *os.File) error {
+	return nil
+}
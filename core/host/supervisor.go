@@ -47,6 +47,8 @@ type Supervisor struct {
 	// 这个暂时不知道什么作用(预计是Server真正调用走这里)?
 	// 看204行，不过是通过TaskHost来实现的
 	onServe []func(TaskHost)
+	// onListen are fired with the bound address when the listener is ready, see `RegisterOnListen`.
+	onListen []func(net.Addr)
 
 	// IgnoreErrors should contains the errors that should be ignored
 	// on both serve functions return statements and error handlers.
@@ -59,11 +61,67 @@ type Supervisor struct {
 	// 表示在返回状态码或者error handler 应该忽视的一些错误
 	IgnoredErrors []string
 
+	// DrainTimeout enables the drain phase of the `Shutdown`, the maximum duration that it waits
+	// for the in-flight requests, including the hijacked ones, to be completed,
+	// their connections are closed when it's exceeded.
+	//
+	// Defaults to zero, no drain phase, the `Shutdown` waits for the connections until the context is done.
+	DrainTimeout time.Duration
+	// RequestCounter reports the in-flight requests of the drain phase, see `ActiveRequests`.
+	// The `Application#NewHost` sets it to the application's context pool.
+	//
+	// Defaults to nil, the drain phase waits for the connections only.
+	RequestCounter RequestCounter
+	// Connections are the long-lived connections of the realtime modules, i.e the websocket and the SSE ones,
+	// the `Shutdown` notifies them, i.e with a close frame or a final SSE event,
+	// and closes them after the `ShutdownGracePeriod`, in parallel with the rest of the shutdown.
+	// The `Application#NewHost` sets it to the application's registry, see `Application#RegisterLongLivedConn`.
+	//
+	// Defaults to nil, the hijacked connections are not notified.
+	Connections *ConnRegistry
+	// ShutdownGracePeriod is the maximum duration that the `Shutdown` waits for the notified `Connections`
+	// to be closed by their clients, before it closes them.
+	//
+	// Defaults to the `DefaultShutdownGracePeriod`.
+	ShutdownGracePeriod time.Duration
+	// ConnLimits are the maximum concurrent connections, in total and per remote IP,
+	// which are enforced by the served listeners before any HTTP parsing, see `netutil#LimitConns`.
+	// Use the `WithConnLimits` host configurator to set them through the `iris.Addr` or the `iris.Listener`.
+	//
+	// Defaults to zero, no limits.
+	ConnLimits netutil.ConnLimits
+	// ProxyProtocol enables the PROXY protocol, when the server is behind a TCP load balancer,
+	// the client's address of the connections is read by their PROXY protocol header, see `netutil#ProxyProtocol`.
+	// Use the `WithProxyProtocol` host configurator to set it through the `iris.Addr` or the `iris.Listener`.
+	//
+	// Defaults to nil, disabled.
+	ProxyProtocol *netutil.ProxyProtocolConfig
+
 	//表示对error所要进行的处理
 	onErr      []func(error)
 	onShutdown []func()
+	onDrain    []func(active int64)
+
+	// listener is the served listener, before the TLS wrapping, it's passed to the child process of a `Restart`.
+	listener net.Listener
+	// inherited reports whether the listener is inherited from the parent process of a `Restart`.
+	inherited bool
+	// addr is the bound address of the served listener, see `Addr`.
+	addr net.Addr
+}
+
+// RequestCounter reports the number of the in-flight requests, see `Supervisor#DrainTimeout`.
+type RequestCounter interface {
+	ActiveRequests() int64
 }
 
+// ErrDrainTimeout is returned by the `Supervisor#Shutdown`
+// when the `Supervisor#DrainTimeout` is exceeded and some requests are still in-flight.
+var ErrDrainTimeout = errors.New("host: drain timeout exceeded with %d active request(s)")
+
+// drainInterval is the interval of the in-flight requests checks of the drain phase.
+const drainInterval = 50 * time.Millisecond
+
 // New returns a new host supervisor
 // based on a native net/http "srv".
 //
@@ -143,11 +201,15 @@ func (su *Supervisor) newListener() (net.Listener, error) {
 	// 这里表示服务中真实的调用某个服务的地址,返回net.Listener
 	// 学习netutil.TCPKeepAlive是怎么执行的
 	// 里面的本质还是通过原生的net.Listen("tcp",addr)
-	l, err := netutil.TCPKeepAlive(su.Server.Addr)
+	l, err := su.newPlainListener()
 	if err != nil {
 		return nil, err
 	}
 
+	if l, err = su.wrapListener(l); err != nil {
+		return nil, err
+	}
+
 	// here we can check for sure, without the need of the supervisor's `manuallyTLS` field.
 	// 判断这个服务是否是传输层协议
 	// 判断这个服务是否要安全认证
@@ -161,6 +223,29 @@ func (su *Supervisor) newListener() (net.Listener, error) {
 	return l, nil
 }
 
+// newPlainListener returns the listener of the server's address, before the TLS wrapping,
+// and keeps it for a `Restart`.
+func (su *Supervisor) newPlainListener() (net.Listener, error) {
+	// a hot restarted process serves the listener of its parent, see `Restart`.
+	l, err := netutil.Inherited(su.Server.Addr)
+	if err != nil {
+		return nil, err
+	}
+	inherited := l != nil
+	if !inherited {
+		if l, err = netutil.TCPKeepAlive(su.Server.Addr); err != nil {
+			return nil, err
+		}
+	}
+
+	su.mu.Lock()
+	su.listener = l
+	su.inherited = inherited
+	su.mu.Unlock()
+
+	return l, nil
+}
+
 // RegisterOnError registers a function to call when errors occurred by the underline http server.
 // 这里就是注册当error出现的时候(排除ignoreError设置的errors)，所要执行的func
 func (su *Supervisor) RegisterOnError(cb func(error)) {
@@ -218,6 +303,44 @@ func (su *Supervisor) notifyServe(host TaskHost) {
 	su.mu.Unlock()
 }
 
+// RegisterOnListen registers a function to call with the actual bound address of the server,
+// i.e the random port of the ":0" address, when its listener is accepting connections,
+// just before the serve. The functions are called synchronously, they should not block.
+//
+// Usage:
+//
+//	app.Run(iris.Addr(":0", func(su *host.Supervisor) {
+//		su.RegisterOnListen(func(addr net.Addr) { register(addr.String()) })
+//	}))
+func (su *Supervisor) RegisterOnListen(cb func(net.Addr)) {
+	su.mu.Lock()
+	su.onListen = append(su.onListen, cb)
+	su.mu.Unlock()
+}
+
+// notifyListen keeps the bound address of the "l" listener and fires the `RegisterOnListen` functions.
+func (su *Supervisor) notifyListen(l net.Listener) {
+	addr := l.Addr()
+
+	su.mu.Lock()
+	su.addr = addr
+	listeners := su.onListen
+	su.mu.Unlock()
+
+	for _, f := range listeners {
+		f(addr)
+	}
+}
+
+// Addr returns the bound address of the server's listener, i.e with the actual port of the ":0" address,
+// it's nil until the server is listening.
+func (su *Supervisor) Addr() net.Addr {
+	su.mu.Lock()
+	addr := su.addr
+	su.mu.Unlock()
+	return addr
+}
+
 // Remove all channels, do it with events
 // or with channels but with a different channel on each task proc
 // I don't know channels are not so safe, when go func and race risk..
@@ -270,11 +393,92 @@ func (su *Supervisor) supervise(blockFunc func() error) error {
 // Serve always returns a non-nil error. After Shutdown or Close, the
 // returned error is http.ErrServerClosed.
 //
+// A hot restarted process serves the listener of its parent instead of the "l", see `Restart`.
+//
 //内部其实就是原生的server.Serve()
 func (su *Supervisor) Serve(l net.Listener) error {
+	inherited, err := netutil.Inherited(restartKey(su.Server.Addr, l))
+	if err != nil {
+		return err
+	}
+	if inherited != nil {
+		// the unix socket's file is shared with the inherited listener, it should not be removed.
+		if ul, ok := l.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+		l.Close()
+		l = inherited
+	}
+
+	su.mu.Lock()
+	if su.listener == nil {
+		su.listener = l
+		su.inherited = inherited != nil
+	}
+	su.mu.Unlock()
+
+	if l, err = su.wrapListener(l); err != nil {
+		return err
+	}
+
+	return su.serve(l)
+}
+
+func (su *Supervisor) serve(l net.Listener) error {
+	su.notifyListen(l)
+
+	su.mu.Lock()
+	inherited := su.inherited
+	su.mu.Unlock()
+	if inherited {
+		// the parent of a `Restart` waits for this process to serve its listener.
+		if err := netutil.NotifyReady(); err != nil {
+			su.notifyErr(err)
+		}
+	}
+
 	return su.supervise(func() error { return su.Server.Serve(l) })
 }
 
+// wrapListener wraps the "l" with the `ConnLimits` and the `ProxyProtocol`, if any,
+// it's called before the TLS wrapping.
+// The limits are enforced by the address of the direct peer, the load balancer's one behind the PROXY protocol.
+func (su *Supervisor) wrapListener(l net.Listener) (net.Listener, error) {
+	if su.ConnLimits.Enabled() {
+		l = netutil.LimitConns(l, su.ConnLimits)
+	}
+
+	if su.ProxyProtocol != nil {
+		return netutil.ProxyProtocol(l, *su.ProxyProtocol)
+	}
+
+	return l, nil
+}
+
+// WithConnLimits returns a host configurator which sets the `Supervisor#ConnLimits`.
+//
+// Usage:
+//
+//	app.Run(iris.Addr(":8080", host.WithConnLimits(netutil.ConnLimits{MaxConns: 10000, MaxConnsPerIP: 100})))
+//	// or
+//	app.Run(iris.Listener(l, host.WithConnLimits(netutil.ConnLimits{MaxConnsPerIP: 100, OnReject: netutil.RejectClose})))
+func WithConnLimits(limits netutil.ConnLimits) Configurator {
+	return func(su *Supervisor) {
+		su.ConnLimits = limits
+	}
+}
+
+// WithProxyProtocol returns a host configurator which sets the `Supervisor#ProxyProtocol`.
+//
+// Usage:
+//
+//	app.Run(iris.Addr(":8080", host.WithProxyProtocol(netutil.ProxyProtocolConfig{TrustedUpstreams: []string{"10.0.0.0/8"}})))
+func WithProxyProtocol(c netutil.ProxyProtocolConfig) Configurator {
+	return func(su *Supervisor) {
+		su.ProxyProtocol = &c
+	}
+}
+
 // ListenAndServe listens on the TCP network address addr
 // and then calls Serve with handler to handle requests
 // on incoming connections.
@@ -285,7 +489,7 @@ func (su *Supervisor) ListenAndServe() error {
 	if err != nil {
 		return err
 	}
-	return su.Serve(l)
+	return su.serve(l)
 }
 
 // ListenAndServeTLS acts identically to ListenAndServe, except that it
@@ -323,7 +527,18 @@ func (su *Supervisor) ListenAndServeTLS(certFile string, keyFile string) error {
 		return errors.New("certFile or keyFile missing")
 	}
 
-	return su.supervise(func() error { return su.Server.ListenAndServeTLS("", "") })
+	// the TLS wrapping is done by the ServeTLS.
+	l, err := su.newPlainListener()
+	if err != nil {
+		return err
+	}
+
+	if l, err = su.wrapListener(l); err != nil {
+		return err
+	}
+
+	su.notifyListen(l)
+	return su.supervise(func() error { return su.Server.ServeTLS(l, "", "") })
 }
 
 // ListenAndServeAutoTLS acts identically to ListenAndServe, except that it
@@ -433,6 +648,34 @@ func (su *Supervisor) notifyShutdown() {
 	// end
 }
 
+// ActiveRequests returns the number of the in-flight requests of the `RequestCounter`,
+// zero if it's nil.
+func (su *Supervisor) ActiveRequests() int64 {
+	if su.RequestCounter == nil {
+		return 0
+	}
+
+	return su.RequestCounter.ActiveRequests()
+}
+
+// RegisterOnDrain registers a function which is called with the number of the in-flight requests
+// periodically during the drain phase of the `Shutdown`, useful to observe its progress.
+func (su *Supervisor) RegisterOnDrain(cb func(active int64)) {
+	su.mu.Lock()
+	su.onDrain = append(su.onDrain, cb)
+	su.mu.Unlock()
+}
+
+func (su *Supervisor) notifyDrain(active int64) {
+	su.mu.Lock()
+	callbacks := su.onDrain
+	su.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb(active)
+	}
+}
+
 // Shutdown gracefully shuts down the server without interrupting any
 // active connections. Shutdown works by first closing all open
 // listeners, then closing all idle connections, and then waiting
@@ -441,17 +684,98 @@ func (su *Supervisor) notifyShutdown() {
 // then the context's error is returned.
 //
 // Shutdown does not attempt to close nor wait for hijacked
-// connections such as WebSockets. The caller of Shutdown should
-// separately notify such long-lived connections of shutdown and wait
-// for them to close, if desired.
+// connections such as WebSockets, unless they're registered to the `Connections`,
+// which are notified of the shutdown and closed after the `ShutdownGracePeriod`.
 //
 // 这里的Shutdown没有强制打断各类链接。首先关闭所有的舰艇个，然后关闭所有的空闲链接，
 // 然后无限制的等待之前的连接返回然后关闭。如果之前的在关闭完成前超时，也会报出错误
 //
 // shutdown不会阐释出关闭或等待劫持链接例如WebSocket，只等待那些存活长的链接然后等待去关闭
 // webSocket了解下 https://zh.wikipedia.org/wiki/WebSocket
+//
+// When the `DrainTimeout` is set, the listeners are closed, so the new connections are refused,
+// and it waits for the connections and for the in-flight requests of the `RequestCounter`,
+// including the hijacked ones, up to the `DrainTimeout`, the `RegisterOnDrain` callbacks report its progress.
+// If the timeout is exceeded the connections are closed and an `ErrDrainTimeout` is returned.
+// 排空阶段: 停止接收新连接, 等待进行中的请求完成(最多DrainTimeout), 超时则强制关闭连接
 func (su *Supervisor) Shutdown(ctx context.Context) error {
 	atomic.AddInt32(&su.closedManually, 1) // future-use
 	su.notifyShutdown()
-	return su.Server.Shutdown(ctx)
+
+	connsDone := su.shutdownConnections(ctx)
+	var err error
+	if su.DrainTimeout <= 0 {
+		err = su.Server.Shutdown(ctx)
+	} else {
+		err = su.drain(ctx)
+	}
+
+	<-connsDone
+	return err
+}
+
+// shutdownConnections notifies and closes the long-lived `Connections` in the background,
+// the returned channel is closed when it's done.
+func (su *Supervisor) shutdownConnections(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	if su.Connections == nil {
+		close(done)
+		return done
+	}
+
+	gracePeriod := su.ShutdownGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultShutdownGracePeriod
+	}
+
+	go func() {
+		su.Connections.Shutdown(ctx, gracePeriod)
+		close(done)
+	}()
+
+	return done
+}
+
+func (su *Supervisor) drain(ctx context.Context) error {
+	drainCtx, cancel := context.WithTimeout(ctx, su.DrainTimeout)
+	defer cancel()
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- su.Server.Shutdown(drainCtx)
+	}()
+
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	closed := false
+	for {
+		if closed && su.ActiveRequests() <= 0 {
+			return nil
+		}
+
+		select {
+		case err := <-shutdownErr:
+			if err != nil {
+				if ctx.Err() != nil {
+					// the caller's context is done before the drain timeout.
+					return err
+				}
+
+				su.Server.Close()
+				return ErrDrainTimeout.Format(su.ActiveRequests())
+			}
+			closed = true
+			shutdownErr = nil
+		case <-ticker.C:
+			su.notifyDrain(su.ActiveRequests())
+		case <-drainCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			su.Server.Close()
+			return ErrDrainTimeout.Format(su.ActiveRequests())
+		}
+	}
 }
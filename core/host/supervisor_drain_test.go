@@ -0,0 +1,115 @@
+package host
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testRequestCounter struct {
+	active int64
+}
+
+func (c *testRequestCounter) ActiveRequests() int64 {
+	return atomic.LoadInt64(&c.active)
+}
+
+// startDrainServer serves a handler which is blocked until the "release" is closed
+// and returns the supervisor once a request is in-flight.
+func startDrainServer(t *testing.T, release <-chan struct{}, drainTimeout time.Duration) (*Supervisor, chan error) {
+	counter := new(testRequestCounter)
+	started := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&counter.active, 1)
+		defer atomic.AddInt64(&counter.active, -1)
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+	})}
+
+	su := New(srv)
+	su.DrainTimeout = drainTimeout
+	su.RequestCounter = counter
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go su.Serve(l)
+
+	requestErr := make(chan error, 1)
+	go func() {
+		res, err := http.Get("http://" + l.Addr().String())
+		if err == nil {
+			res.Body.Close()
+		}
+		requestErr <- err
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout while waiting for the request")
+	}
+
+	return su, requestErr
+}
+
+func TestSupervisorShutdownDrain(t *testing.T) {
+	release := make(chan struct{})
+	su, requestErr := startDrainServer(t, release, 5*time.Second)
+
+	// the request is completed after the first report of the drain progress.
+	var (
+		mu       sync.Mutex
+		progress []int64
+		once     sync.Once
+	)
+	su.RegisterOnDrain(func(active int64) {
+		mu.Lock()
+		progress = append(progress, active)
+		mu.Unlock()
+		once.Do(func() { close(release) })
+	})
+
+	if expected, got := int64(1), su.ActiveRequests(); expected != got {
+		t.Fatalf("expected %d active requests but got %d", expected, got)
+	}
+
+	if err := su.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-requestErr; err != nil {
+		t.Fatalf("expected the in-flight request to be completed but got: %v", err)
+	}
+
+	if got := su.ActiveRequests(); got != 0 {
+		t.Fatalf("expected no active requests but got %d", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(progress) == 0 || progress[0] != 1 {
+		t.Fatalf("expected the drain progress to report 1 active request first but got %v", progress)
+	}
+}
+
+func TestSupervisorShutdownDrainTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	su, requestErr := startDrainServer(t, release, 100*time.Millisecond)
+
+	err := su.Shutdown(context.Background())
+	if !ErrDrainTimeout.Equal(err) {
+		t.Fatalf("expected the drain timeout error but got: %v", err)
+	}
+
+	if err = <-requestErr; err == nil {
+		t.Fatal("expected the connection of the in-flight request to be closed")
+	}
+}
@@ -0,0 +1,49 @@
+package host
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSupervisorOnListen(t *testing.T) {
+	srv := &http.Server{Addr: "127.0.0.1:0", Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ready"))
+	})}
+
+	su := New(srv)
+	if su.Addr() != nil {
+		t.Fatalf("expected a nil address before the listen but got: %s", su.Addr())
+	}
+
+	ready := make(chan net.Addr, 1)
+	su.RegisterOnListen(func(addr net.Addr) { ready <- addr })
+	go su.ListenAndServe()
+	defer su.Shutdown(context.Background())
+
+	var addr net.Addr
+	select {
+	case addr = <-ready:
+	case <-time.After(3 * time.Second):
+		t.Fatal("the listen event was not fired")
+	}
+
+	if _, port, _ := net.SplitHostPort(addr.String()); port == "0" || port == "" {
+		t.Fatalf("expected the actual bound port but got: %s", addr)
+	}
+	if su.Addr().String() != addr.String() {
+		t.Fatalf("expected the supervisor's address to be %s but got: %s", addr, su.Addr())
+	}
+
+	res, err := http.Get("http://" + addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if b, _ := ioutil.ReadAll(res.Body); string(b) != "ready" {
+		t.Fatalf("expected the response body to be 'ready' but got: %s", b)
+	}
+}
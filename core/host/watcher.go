@@ -0,0 +1,173 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// WatchOp describes the kind of a file system change, see `WatchEvent`.
+type WatchOp uint8
+
+const (
+	// WatchCreate is the operation of a new file.
+	WatchCreate WatchOp = iota + 1
+	// WatchWrite is the operation of a modified file.
+	WatchWrite
+	// WatchRemove is the operation of a removed file.
+	WatchRemove
+)
+
+// String returns the text representation of the operation.
+func (op WatchOp) String() string {
+	switch op {
+	case WatchCreate:
+		return "CREATE"
+	case WatchWrite:
+		return "WRITE"
+	case WatchRemove:
+		return "REMOVE"
+	default:
+		return ""
+	}
+}
+
+// WatchEvent is fired by the `Watcher` for each changed file.
+type WatchEvent struct {
+	Path string
+	Op   WatchOp
+}
+
+// DefaultWatchInterval is the default delay of a check after a file system notification,
+// or the interval of the checks when the `Watcher` polls, see `Watcher`.
+var DefaultWatchInterval = 500 * time.Millisecond
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher watches files and directories (recursively) for changes,
+// by comparing their modification times and sizes, and fires
+// the events to a callback on its own, single, goroutine.
+//
+// The paths are checked on the file system notifications (fsnotify),
+// or on an interval if the notifications are not available or the `WatchPoll` is called.
+// 文件监听(基于文件系统通知, 不可用时轮询)，用于模板、配置、证书等的热加载
+type Watcher struct {
+	// Interval is the delay of a check after a notification, which coalesces the close ones,
+	// or the interval between the checks when it polls.
+	// Defaults to the `DefaultWatchInterval`.
+	Interval time.Duration
+
+	paths   []string
+	poll    bool
+	onEvent func(WatchEvent)
+
+	state   map[string]fileState
+	started bool
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewWatcher returns a new Watcher of the "paths",
+// the "onEvent" is called on each change, after `Start`.
+func NewWatcher(paths []string, onEvent func(WatchEvent)) *Watcher {
+	return &Watcher{
+		Interval: DefaultWatchInterval,
+		paths:    paths,
+		onEvent:  onEvent,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// WatchPoll makes the watcher to check the paths on each `Interval` instead of the file system notifications,
+// i.e for the network file systems which do not send them. It should be called before `Start`.
+func (w *Watcher) WatchPoll() *Watcher {
+	w.poll = true
+	return w
+}
+
+// scan returns the current state of the watched files.
+func (w *Watcher) scan() (map[string]fileState, error) {
+	state := make(map[string]fileState)
+	for _, root := range w.paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path != root {
+					return nil // removed while walking.
+				}
+				return err
+			}
+
+			if !info.IsDir() {
+				state[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
+
+// Start takes the initial state of the watched paths
+// and starts watching them for changes on a new goroutine.
+// It returns an error if a path cannot be read.
+func (w *Watcher) Start() error {
+	state, err := w.scan()
+	if err != nil {
+		return err
+	}
+	w.state = state
+
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	return w.watch(interval)
+}
+
+func (w *Watcher) check() {
+	state, err := w.scan()
+	if err != nil {
+		// a watched root may be temporarily missing, i.e while it's replaced,
+		// keep the previous state and check again on the next tick.
+		return
+	}
+
+	for path, current := range state {
+		prev, ok := w.state[path]
+		if !ok {
+			w.onEvent(WatchEvent{Path: path, Op: WatchCreate})
+		} else if !prev.modTime.Equal(current.modTime) || prev.size != current.size {
+			w.onEvent(WatchEvent{Path: path, Op: WatchWrite})
+		}
+	}
+
+	for path := range w.state {
+		if _, ok := state[path]; !ok {
+			w.onEvent(WatchEvent{Path: path, Op: WatchRemove})
+		}
+	}
+
+	w.state = state
+}
+
+// Stop stops watching and waits for an in-progress event callback, if any, to finish.
+// It's safe to be called more than once.
+func (w *Watcher) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+		if w.started {
+			<-w.done
+		}
+	})
+}
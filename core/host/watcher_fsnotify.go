@@ -0,0 +1,98 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watch checks the watched paths for changes on the file system notifications,
+// the close ones are coalesced, the check runs "interval" after the first of them.
+// It falls back to the polling if the notifications are not available, i.e the limit of the inotify watches is reached.
+func (w *Watcher) watch(interval time.Duration) error {
+	if w.poll {
+		return w.watchPoll(interval)
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return w.watchPoll(interval)
+	}
+
+	if err = w.addDirs(fw); err != nil {
+		fw.Close()
+		if os.IsNotExist(err) {
+			return err
+		}
+		return w.watchPoll(interval)
+	}
+
+	w.started = true
+	go w.notifyLoop(fw, interval)
+	return nil
+}
+
+// addDirs adds the watched directories, recursively, and the parent directories of the watched files,
+// the files are replaced by the editors, to the "fw".
+func (w *Watcher) addDirs(fw *fsnotify.Watcher) error {
+	for _, root := range w.paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			if err = fw.Add(filepath.Dir(root)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || !info.IsDir() {
+				return nil // removed while walking.
+			}
+			return fw.Add(path)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *Watcher) notifyLoop(fw *fsnotify.Watcher, interval time.Duration) {
+	defer close(w.done)
+	defer fw.Close()
+
+	var pending <-chan time.Time
+	for {
+		select {
+		case <-w.stop:
+			return
+		case _, ok := <-fw.Events:
+			if !ok {
+				return
+			}
+			if pending == nil {
+				pending = time.After(interval)
+			}
+		case _, ok := <-fw.Errors:
+			// i.e an overflow of the notifications, check them all.
+			if !ok {
+				return
+			}
+			if pending == nil {
+				pending = time.After(interval)
+			}
+		case <-pending:
+			pending = nil
+			w.check()
+			// watch the new directories too, a removed one is not an error.
+			w.addDirs(fw)
+		}
+	}
+}
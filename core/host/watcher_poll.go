@@ -0,0 +1,28 @@
+package host
+
+import (
+	"time"
+)
+
+// watchPoll checks the watched paths for changes on each "interval".
+func (w *Watcher) watchPoll(interval time.Duration) error {
+	w.started = true
+	go w.pollLoop(interval)
+	return nil
+}
+
+func (w *Watcher) pollLoop(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
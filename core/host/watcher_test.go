@@ -0,0 +1,90 @@
+package host
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	testWatcher(t, false)
+}
+
+func TestWatcherPoll(t *testing.T) {
+	testWatcher(t, true)
+}
+
+func testWatcher(t *testing.T, poll bool) {
+	dir, err := ioutil.TempDir("", "iris-watcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "config.yml")
+	if err = ioutil.WriteFile(filename, []byte("a: 1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan WatchEvent, 10)
+	w := NewWatcher([]string{dir}, func(evt WatchEvent) {
+		events <- evt
+	})
+	w.Interval = 10 * time.Millisecond
+	if poll {
+		w.WatchPoll()
+	}
+	if err = w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	expectEvent := func(op WatchOp, path string) {
+		select {
+		case evt := <-events:
+			if evt.Op != op || evt.Path != path {
+				t.Fatalf("expected %s %s but got %s %s", op, path, evt.Op, evt.Path)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout while waiting for %s %s", op, path)
+		}
+	}
+
+	if err = ioutil.WriteFile(filename, []byte("a: 22"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(WatchWrite, filename)
+
+	newFilename := filepath.Join(dir, "cert.pem")
+	if err = ioutil.WriteFile(newFilename, []byte("cert"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(WatchCreate, newFilename)
+
+	if err = os.Remove(newFilename); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(WatchRemove, newFilename)
+
+	// the new directories are watched too.
+	subdir := filepath.Join(dir, "templates")
+	if err = os.Mkdir(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	subFilename := filepath.Join(subdir, "index.html")
+	if err = ioutil.WriteFile(subFilename, []byte("index"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(WatchCreate, subFilename)
+
+	time.Sleep(50 * time.Millisecond) // let the directory be watched, if it's not yet.
+	if err = ioutil.WriteFile(subFilename, []byte("index v2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(WatchWrite, subFilename)
+
+	w.Stop()
+	w.Stop() // safe to be called twice.
+}
@@ -244,15 +244,37 @@ func (e Entry) Int64Default(def int64) (int64, error) {
 
 	switch vv := v.(type) {
 	case string:
-		return strconv.ParseInt(vv, 10, 64)
+		val, err := strconv.ParseInt(vv, 10, 64)
+		if err != nil {
+			return def, err
+		}
+		return val, nil
 	case int64:
 		return vv, nil
 	case int32:
 		return int64(vv), nil
+	case int16:
+		return int64(vv), nil
 	case int8:
 		return int64(vv), nil
 	case int:
 		return int64(vv), nil
+	case uint8:
+		return int64(vv), nil
+	case uint16:
+		return int64(vv), nil
+	case uint32:
+		return int64(vv), nil
+	case uint:
+		if uint64(vv) > math.MaxInt64 {
+			return def, errFindParse.Format("int64", e.Key)
+		}
+		return int64(vv), nil
+	case uint64:
+		if vv > math.MaxInt64 {
+			return def, errFindParse.Format("int64", e.Key)
+		}
+		return int64(vv), nil
 	}
 
 	return def, errFindParse.Format("int64", e.Key)
@@ -474,9 +496,17 @@ func (e Entry) Uint64Default(def uint64) (uint64, error) {
 		return uint64(vv), nil
 	case uint64:
 		return vv, nil
+	case uint:
+		return uint64(vv), nil
 	case int64:
+		if vv < 0 {
+			return def, errFindParse.Format("uint64", e.Key)
+		}
 		return uint64(vv), nil
 	case int:
+		if vv < 0 {
+			return def, errFindParse.Format("uint64", e.Key)
+		}
 		return uint64(vv), nil
 	}
 
@@ -536,10 +566,22 @@ func (e Entry) Float64Default(def float64) (float64, error) {
 		return vv, nil
 	case int:
 		return float64(vv), nil
+	case int8:
+		return float64(vv), nil
+	case int16:
+		return float64(vv), nil
+	case int32:
+		return float64(vv), nil
 	case int64:
 		return float64(vv), nil
 	case uint:
 		return float64(vv), nil
+	case uint8:
+		return float64(vv), nil
+	case uint16:
+		return float64(vv), nil
+	case uint32:
+		return float64(vv), nil
 	case uint64:
 		return float64(vv), nil
 	}
@@ -110,3 +110,36 @@ func TestImmutableSetOnlyWithSetImmutable(t *testing.T) {
 		t.Fatalf("caller should be able to change the immutable entry with a `SetImmutable`")
 	}
 }
+
+func TestTypedGetters(t *testing.T) {
+	var p Store
+
+	p.Set("uint", uint(42))
+	p.Set("int16", int16(42))
+	p.Set("uint64", uint64(42))
+	p.Set("string", "42")
+	p.Set("invalid", "forty-two")
+
+	for _, key := range []string{"uint", "int16", "uint64", "string"} {
+		if v, err := p.GetInt64(key); err != nil || v != 42 {
+			t.Fatalf("[%s] expected int64 42 but got %d: %v", key, v, err)
+		}
+
+		if v, err := p.GetFloat64(key); err != nil || v != 42 {
+			t.Fatalf("[%s] expected float64 42 but got %f: %v", key, v, err)
+		}
+	}
+
+	if v, err := p.GetUint64("uint"); err != nil || v != 42 {
+		t.Fatalf("expected uint64 42 but got %d: %v", v, err)
+	}
+
+	if v := p.GetInt64Default("invalid", 7); v != 7 {
+		t.Fatalf("expected the default value 7 on invalid int64 but got %d", v)
+	}
+
+	p.Set("negative", -1)
+	if _, err := p.GetUint64("negative"); err == nil {
+		t.Fatalf("expected an error on negative uint64")
+	}
+}
@@ -0,0 +1,123 @@
+package netutil
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+// ListenFDsEnv is the environment variable of the listeners which a process inherits
+// from its parent on a hot restart, a comma separated list of "addr=fd", i.e ":8080=3".
+const ListenFDsEnv = "IRIS_LISTEN_FDS"
+
+// ReadyFDEnv is the environment variable of the pipe's file descriptor which a process
+// inherits from its parent on a hot restart, the process writes to it when it serves
+// its inherited listener so the parent can shut down, see `NotifyReady`.
+const ReadyFDEnv = "IRIS_READY_FD"
+
+var (
+	errListenerFile  = errors.New("listener of type %T cannot be passed to a child process")
+	errInheritedFD   = errors.New("invalid inherited listener '%s' of %s")
+	errInheritedFile = errors.New("cannot use the inherited listener of '%s'. Trace: %s")
+)
+
+// inheritedFD returns the file descriptor of the "addr"'s listener from the `ListenFDsEnv`, if any.
+func inheritedFD(addr string) (uintptr, bool, error) {
+	for _, entry := range strings.Split(os.Getenv(ListenFDsEnv), ",") {
+		idx := strings.LastIndexByte(entry, '=')
+		if idx <= 0 || entry[:idx] != addr {
+			continue
+		}
+
+		fd, err := strconv.ParseUint(entry[idx+1:], 10, 32)
+		if err != nil {
+			return 0, false, errInheritedFD.Format(entry, ListenFDsEnv)
+		}
+		return uintptr(fd), true, nil
+	}
+
+	return 0, false, nil
+}
+
+// Inherited returns the listener of the "addr" which is inherited from the parent process
+// on a hot restart, see `ListenFDsEnv`, or nil if there is no one.
+// The inherited tcp listeners set keep-alive timeouts on the accepted connections, like the `TCPKeepAlive`.
+// 热重启: 返回从父进程继承的监听器(通过文件描述符)
+func Inherited(addr string) (net.Listener, error) {
+	fd, ok, err := inheritedFD(addr)
+	if !ok || err != nil {
+		return nil, err
+	}
+
+	f := os.NewFile(fd, addr)
+	if f == nil {
+		return nil, errInheritedFD.Format(addr, ListenFDsEnv)
+	}
+	// the net.FileListener duplicates the descriptor.
+	defer f.Close()
+
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, errInheritedFile.Format(addr, err.Error())
+	}
+
+	if tl, ok := l.(*net.TCPListener); ok {
+		return tcpKeepAliveListener{tl}, nil
+	}
+
+	return l, nil
+}
+
+var (
+	readyOnce sync.Once
+	readyErr  error
+)
+
+// NotifyReady tells the parent process that this one serves its inherited listener,
+// see `ReadyFDEnv`. It does nothing if the process is not a hot restarted one,
+// the parent is notified once.
+// 热重启: 通知父进程新进程已经开始服务
+func NotifyReady() error {
+	readyOnce.Do(func() {
+		v := os.Getenv(ReadyFDEnv)
+		if v == "" {
+			return
+		}
+		// the children of this process should not write to the same pipe.
+		os.Unsetenv(ReadyFDEnv)
+
+		fd, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			readyErr = errInheritedFD.Format(v, ReadyFDEnv)
+			return
+		}
+
+		f := os.NewFile(uintptr(fd), ReadyFDEnv)
+		if f == nil {
+			readyErr = errInheritedFD.Format(v, ReadyFDEnv)
+			return
+		}
+		defer f.Close()
+
+		_, readyErr = f.Write([]byte{1})
+	})
+
+	return readyErr
+}
+
+// ListenerFile returns a duplicate of the file descriptor of the "l" listener,
+// which can be passed to a child process, see `ListenFDsEnv`.
+// The tcp and unix listeners are supported, a TLS one should be unwrapped first.
+func ListenerFile(l net.Listener) (*os.File, error) {
+	if fl, ok := l.(interface {
+		File() (*os.File, error)
+	}); ok {
+		return fl.File()
+	}
+
+	return nil, errListenerFile.Format(l)
+}
@@ -0,0 +1,61 @@
+package netutil
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestInherited(t *testing.T) {
+	if l, err := Inherited(":0"); l != nil || err != nil {
+		t.Fatalf("expected no inherited listener but got: %v, %v", l, err)
+	}
+
+	parent, err := TCPKeepAlive("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parent.Close()
+
+	f, err := ListenerFile(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	addr := parent.Addr().String()
+	os.Setenv(ListenFDsEnv, ":9999=100,"+addr+"="+strconv.Itoa(int(f.Fd())))
+	defer os.Unsetenv(ListenFDsEnv)
+
+	child, err := Inherited(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child == nil {
+		t.Fatal("expected the inherited listener")
+	}
+	defer child.Close()
+
+	if expected, got := addr, child.Addr().String(); expected != got {
+		t.Fatalf("expected the inherited listener of %s but got %s", expected, got)
+	}
+
+	// the connections are accepted by the inherited listener too.
+	parent.Close()
+	go func() {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+		}
+	}()
+
+	conn, err := child.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	if _, err = ListenerFile(struct{ net.Listener }{child}); err == nil {
+		t.Fatal("expected an error of a listener without a file descriptor")
+	}
+}
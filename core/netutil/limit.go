@@ -0,0 +1,168 @@
+package netutil
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+var (
+	// ErrTooManyConns is passed to the `ConnLimits#OnReject` when the `ConnLimits#MaxConns` is reached.
+	ErrTooManyConns = errors.New("too many connections")
+	// ErrTooManyConnsPerIP is passed to the `ConnLimits#OnReject` when the `ConnLimits#MaxConnsPerIP` of the client's IP is reached.
+	ErrTooManyConnsPerIP = errors.New("too many connections from %s")
+)
+
+// ConnLimits are the connection limits of a `LimitListener`, the zero values mean no limit.
+type ConnLimits struct {
+	// MaxConns is the maximum number of the concurrent connections.
+	MaxConns int
+	// MaxConnsPerIP is the maximum number of the concurrent connections of a remote IP.
+	MaxConnsPerIP int
+	// OnReject is called with the rejected connection and the reason of the rejection,
+	// `ErrTooManyConns` or `ErrTooManyConnsPerIP`, the connection is closed after it.
+	// It runs in its own goroutine, so it doesn't block the next accepts.
+	// The rejection happens before any read, there is no HTTP request yet.
+	//
+	// Defaults to the `RejectWith503`.
+	OnReject func(c net.Conn, reason error)
+}
+
+// Enabled reports whether any of the limits is set.
+func (l ConnLimits) Enabled() bool {
+	return l.MaxConns > 0 || l.MaxConnsPerIP > 0
+}
+
+var response503 = []byte("HTTP/1.1 503 Service Unavailable\r\nConnection: close\r\nContent-Length: 0\r\n\r\n")
+
+// RejectWith503 is a `ConnLimits#OnReject` which writes a minimal "503 Service Unavailable" HTTP response,
+// so the HTTP/1.x clients receive an error instead of a reset connection.
+func RejectWith503(c net.Conn, reason error) {
+	c.SetWriteDeadline(time.Now().Add(time.Second))
+	c.Write(response503)
+}
+
+// RejectClose is a `ConnLimits#OnReject` which closes the connection without a response,
+// i.e for TLS listeners, where a plain response can't be read by the clients.
+func RejectClose(c net.Conn, reason error) {}
+
+// LimitListener is a listener which enforces the `ConnLimits` on the accepted connections,
+// the rejected connections are never returned by its `Accept`.
+// 在HTTP解析之前限制连接数量(全局以及每个IP)
+type LimitListener struct {
+	net.Listener
+	limits ConnLimits
+
+	mu     sync.Mutex
+	active int
+	perIP  map[string]int
+}
+
+// LimitConns returns a new `LimitListener` which wraps the "l" with the "limits".
+func LimitConns(l net.Listener, limits ConnLimits) *LimitListener {
+	if limits.OnReject == nil {
+		limits.OnReject = RejectWith503
+	}
+
+	return &LimitListener{
+		Listener: l,
+		limits:   limits,
+		perIP:    make(map[string]int),
+	}
+}
+
+// Accept waits for and returns the next connection which does not exceed the limits.
+func (l *LimitListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		ip := remoteIP(c)
+		if reason := l.acquire(ip); reason != nil {
+			// don't block the next accepts on a slow client.
+			go func() {
+				l.limits.OnReject(c, reason)
+				c.Close()
+			}()
+			continue
+		}
+
+		return &limitedConn{Conn: c, release: func() { l.release(ip) }}, nil
+	}
+}
+
+func (l *LimitListener) acquire(ip string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if max := l.limits.MaxConns; max > 0 && l.active >= max {
+		return ErrTooManyConns
+	}
+
+	if max := l.limits.MaxConnsPerIP; max > 0 && l.perIP[ip] >= max {
+		return ErrTooManyConnsPerIP.Format(ip)
+	}
+
+	l.active++
+	l.perIP[ip]++
+	return nil
+}
+
+func (l *LimitListener) release(ip string) {
+	l.mu.Lock()
+	l.active--
+	if n := l.perIP[ip] - 1; n > 0 {
+		l.perIP[ip] = n
+	} else {
+		delete(l.perIP, ip)
+	}
+	l.mu.Unlock()
+}
+
+// Active returns the number of the open connections.
+func (l *LimitListener) Active() int {
+	l.mu.Lock()
+	n := l.active
+	l.mu.Unlock()
+	return n
+}
+
+// ActiveIP returns the number of the open connections of the "ip".
+func (l *LimitListener) ActiveIP(ip string) int {
+	l.mu.Lock()
+	n := l.perIP[ip]
+	l.mu.Unlock()
+	return n
+}
+
+// remoteIP returns the IP of the connection's remote address, without the port.
+func remoteIP(c net.Conn) string {
+	switch addr := c.RemoteAddr().(type) {
+	case *net.TCPAddr:
+		return addr.IP.String()
+	case nil:
+		return ""
+	default:
+		if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+			return host
+		}
+		return addr.String()
+	}
+}
+
+// limitedConn releases its slot of the `LimitListener` once, on close.
+type limitedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
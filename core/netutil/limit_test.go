@@ -0,0 +1,143 @@
+package netutil
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimitConns(t *testing.T) {
+	l, err := TCP("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rejected := make(chan error, 10)
+	ll := LimitConns(l, ConnLimits{
+		MaxConns:      3,
+		MaxConnsPerIP: 2,
+		OnReject: func(c net.Conn, reason error) {
+			RejectWith503(c, reason)
+			rejected <- reason
+		},
+	})
+	defer ll.Close()
+
+	accepted := make(chan net.Conn, 10)
+	go func() {
+		for {
+			c, err := ll.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- c
+		}
+	}()
+
+	dial := func() net.Conn {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	expectAccepted := func() net.Conn {
+		select {
+		case c := <-accepted:
+			return c
+		case reason := <-rejected:
+			t.Fatalf("expected an accepted connection but got rejected: %v", reason)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for an accepted connection")
+		}
+		return nil
+	}
+
+	c1, c2 := dial(), dial()
+	defer c1.Close()
+	defer c2.Close()
+	s1, s2 := expectAccepted(), expectAccepted()
+
+	if expected, got := 2, ll.ActiveIP("127.0.0.1"); expected != got {
+		t.Fatalf("expected %d active connections of the IP but got %d", expected, got)
+	}
+
+	// the third one of the same IP is rejected with a 503 before any request is read.
+	c3 := dial()
+	defer c3.Close()
+	select {
+	case reason := <-rejected:
+		if !ErrTooManyConnsPerIP.Equal(reason) {
+			t.Fatalf("expected the per IP limit error but got: %v", reason)
+		}
+	case <-accepted:
+		t.Fatal("expected the connection to be rejected")
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for the rejection")
+	}
+
+	c3.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := bufio.NewReader(c3).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(line, "HTTP/1.1 503") {
+		t.Fatalf("expected a 503 response but got: %q", line)
+	}
+
+	// closing a connection releases its slot, twice is fine.
+	s1.Close()
+	s1.Close()
+	if expected, got := 1, ll.Active(); expected != got {
+		t.Fatalf("expected %d active connections but got %d", expected, got)
+	}
+
+	c4 := dial()
+	defer c4.Close()
+	s4 := expectAccepted()
+	defer s4.Close()
+	defer s2.Close()
+
+	if expected, got := 2, ll.Active(); expected != got {
+		t.Fatalf("expected %d active connections but got %d", expected, got)
+	}
+}
+
+func TestLimitConnsTotal(t *testing.T) {
+	l, err := TCP("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rejected := make(chan error, 1)
+	ll := LimitConns(l, ConnLimits{MaxConns: 1, OnReject: func(c net.Conn, reason error) { rejected <- reason }})
+	defer ll.Close()
+
+	go func() {
+		for {
+			if _, err := ll.Accept(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 2; i++ {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+	}
+
+	select {
+	case reason := <-rejected:
+		if !ErrTooManyConns.Equal(reason) {
+			t.Fatalf("expected the total limit error but got: %v", reason)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for the rejection")
+	}
+}
@@ -0,0 +1,301 @@
+package netutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+var (
+	// ErrProxyHeaderInvalid is returned by the `Read` of a connection with an invalid PROXY protocol header,
+	// the connection should be closed then.
+	ErrProxyHeaderInvalid = errors.New("invalid PROXY protocol header: %s")
+	// ErrProxyHeaderMissing is returned by the `Read` of a connection of a trusted upstream without a PROXY protocol header,
+	// when the `ProxyProtocolConfig#Required` is true.
+	ErrProxyHeaderMissing = errors.New("PROXY protocol header is missing")
+	errTrustedUpstream    = errors.New("invalid trusted upstream %q")
+)
+
+// proxyV2Signature is the signature of the binary, version 2, header.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+const (
+	proxyV1Prefix = "PROXY "
+	// proxyV1MaxLength is the maximum length of a version 1 header, including the CRLF.
+	proxyV1MaxLength = 107
+	// proxyV2HeaderLength is the length of the signature, the version/command, the family and the length fields.
+	proxyV2HeaderLength = 16
+)
+
+// DefaultProxyHeaderTimeout is the default `ProxyProtocolConfig#HeaderTimeout`.
+const DefaultProxyHeaderTimeout = 5 * time.Second
+
+// ProxyProtocolConfig is the configuration of a `ProxyProtocol` listener.
+type ProxyProtocolConfig struct {
+	// TrustedUpstreams are the CIDR ranges or the IPs of the load balancers which send the PROXY protocol header,
+	// i.e "10.0.0.0/8". The header of the rest connections is not parsed, their address is kept as it's,
+	// so a client can't spoof its address by sending a header of its own.
+	//
+	// Defaults to empty, all upstreams are trusted.
+	TrustedUpstreams []string
+	// Required rejects the connections of the trusted upstreams without a PROXY protocol header,
+	// otherwise they are served with their own address, i.e the health checks of a load balancer.
+	//
+	// Defaults to false.
+	Required bool
+	// HeaderTimeout is the maximum duration for reading the header of a connection.
+	//
+	// Defaults to the `DefaultProxyHeaderTimeout`.
+	HeaderTimeout time.Duration
+}
+
+// ProxyProtocol returns a listener which reads the HAProxy PROXY protocol header, version 1 (text) and 2 (binary),
+// of the connections of the trusted upstreams, see `ProxyProtocolConfig`.
+// The client's address of the header is returned by the connections' `RemoteAddr`,
+// so it's the `http.Request#RemoteAddr` and the `Context#RemoteAddr` too.
+//
+// The header is read on the first use of the connection, by its own goroutine, not by the `Accept`.
+// A TLS listener should wrap the returned listener, the header comes before the TLS handshake.
+// 在TCP负载均衡器后面时获取真实的客户端地址
+func ProxyProtocol(l net.Listener, c ProxyProtocolConfig) (net.Listener, error) {
+	trusted, err := parseUpstreams(c.TrustedUpstreams)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.HeaderTimeout <= 0 {
+		c.HeaderTimeout = DefaultProxyHeaderTimeout
+	}
+
+	return &proxyListener{Listener: l, config: c, trusted: trusted}, nil
+}
+
+// parseUpstreams parses the CIDR ranges or the IPs of the "list".
+func parseUpstreams(list []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(list))
+	for _, entry := range list {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			_, n, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, errTrustedUpstream.Format(entry)
+			}
+			nets = append(nets, n)
+			continue
+		}
+
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, errTrustedUpstream.Format(entry)
+		}
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+
+	return nets, nil
+}
+
+type proxyListener struct {
+	net.Listener
+	config  ProxyProtocolConfig
+	trusted []*net.IPNet
+}
+
+func (l *proxyListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	if !l.isTrusted(c.RemoteAddr()) {
+		return c, nil
+	}
+
+	return &proxyConn{Conn: c, r: bufio.NewReader(c), config: l.config}, nil
+}
+
+func (l *proxyListener) isTrusted(addr net.Addr) bool {
+	if len(l.trusted) == 0 {
+		return true
+	}
+
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	for _, n := range l.trusted {
+		if n.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// proxyConn is a connection of a trusted upstream, its header is read once, on its first use.
+type proxyConn struct {
+	net.Conn
+	r      *bufio.Reader
+	config ProxyProtocolConfig
+
+	once       sync.Once
+	remoteAddr net.Addr
+	localAddr  net.Addr
+	err        error
+}
+
+func (c *proxyConn) readHeader() {
+	c.once.Do(func() {
+		c.Conn.SetReadDeadline(time.Now().Add(c.config.HeaderTimeout))
+		c.remoteAddr, c.localAddr, c.err = readProxyHeader(c.r, c.config.Required)
+		c.Conn.SetReadDeadline(time.Time{})
+	})
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	if c.readHeader(); c.err != nil {
+		return 0, c.err
+	}
+
+	return c.r.Read(b)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	if c.readHeader(); c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+func (c *proxyConn) LocalAddr() net.Addr {
+	if c.readHeader(); c.localAddr != nil {
+		return c.localAddr
+	}
+
+	return c.Conn.LocalAddr()
+}
+
+// readProxyHeader reads the PROXY protocol header of the "r", if any,
+// the returned addresses are nil for a header without addresses, i.e "UNKNOWN" or "LOCAL".
+func readProxyHeader(r *bufio.Reader, required bool) (remoteAddr, localAddr net.Addr, err error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	switch b[0] {
+	case proxyV1Prefix[0]:
+		if b, err = r.Peek(len(proxyV1Prefix)); err == nil && string(b) == proxyV1Prefix {
+			return readProxyV1(r)
+		}
+	case proxyV2Signature[0]:
+		if b, err = r.Peek(len(proxyV2Signature)); err == nil && bytes.Equal(b, proxyV2Signature) {
+			return readProxyV2(r)
+		}
+	}
+
+	if required {
+		return nil, nil, ErrProxyHeaderMissing
+	}
+
+	return nil, nil, nil
+}
+
+// readProxyV1 reads a text header, i.e "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n".
+func readProxyV1(r *bufio.Reader) (remoteAddr, localAddr net.Addr, err error) {
+	var line []byte
+	for len(line) < proxyV1MaxLength {
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, ErrProxyHeaderInvalid.Format(err)
+		}
+		line = append(line, c)
+		if c == '\n' {
+			break
+		}
+	}
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, ErrProxyHeaderInvalid.Format("the line is too long or not CRLF terminated")
+	}
+
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, nil, ErrProxyHeaderInvalid.Format(strconv.Quote(string(line)))
+	}
+
+	src, dst := net.ParseIP(fields[2]), net.ParseIP(fields[3])
+	srcPort, srcErr := strconv.ParseUint(fields[4], 10, 16)
+	dstPort, dstErr := strconv.ParseUint(fields[5], 10, 16)
+	if src == nil || dst == nil || srcErr != nil || dstErr != nil {
+		return nil, nil, ErrProxyHeaderInvalid.Format(strconv.Quote(string(line)))
+	}
+
+	return &net.TCPAddr{IP: src, Port: int(srcPort)}, &net.TCPAddr{IP: dst, Port: int(dstPort)}, nil
+}
+
+// readProxyV2 reads a binary header, its TLVs are skipped.
+func readProxyV2(r *bufio.Reader) (remoteAddr, localAddr net.Addr, err error) {
+	header := make([]byte, proxyV2HeaderLength)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return nil, nil, ErrProxyHeaderInvalid.Format(err)
+	}
+
+	if version := header[12] >> 4; version != 2 {
+		return nil, nil, ErrProxyHeaderInvalid.Format("unsupported version " + strconv.Itoa(int(version)))
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return nil, nil, ErrProxyHeaderInvalid.Format(err)
+	}
+
+	switch command := header[12] & 0x0F; command {
+	case 0x0: // LOCAL, i.e the health checks of the upstream itself.
+		return nil, nil, nil
+	case 0x1: // PROXY.
+	default:
+		return nil, nil, ErrProxyHeaderInvalid.Format("unsupported command " + strconv.Itoa(int(command)))
+	}
+
+	var ipLen int
+	switch family := header[13] >> 4; family {
+	case 0x1: // AF_INET
+		ipLen = net.IPv4len
+	case 0x2: // AF_INET6
+		ipLen = net.IPv6len
+	default: // AF_UNSPEC or AF_UNIX, the connection's addresses are kept.
+		return nil, nil, nil
+	}
+
+	if len(payload) < 2*ipLen+4 {
+		return nil, nil, ErrProxyHeaderInvalid.Format("short addresses block")
+	}
+
+	src := net.IP(payload[:ipLen])
+	dst := net.IP(payload[ipLen : 2*ipLen])
+	srcPort := binary.BigEndian.Uint16(payload[2*ipLen:])
+	dstPort := binary.BigEndian.Uint16(payload[2*ipLen+2:])
+
+	return &net.TCPAddr{IP: src, Port: int(srcPort)}, &net.TCPAddr{IP: dst, Port: int(dstPort)}, nil
+}
@@ -0,0 +1,100 @@
+package netutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"testing"
+)
+
+func proxyV2Header(src, dst net.IP, srcPort, dstPort uint16) []byte {
+	b := append([]byte{}, proxyV2Signature...)
+	b = append(b, 0x21, 0x11) // version 2, PROXY, AF_INET, STREAM.
+	payload := append(append([]byte{}, src.To4()...), dst.To4()...)
+	payload = append(payload, 0, 0, 0, 0)
+	binary.BigEndian.PutUint16(payload[8:], srcPort)
+	binary.BigEndian.PutUint16(payload[10:], dstPort)
+	payload = append(payload, 0x04, 0x00, 0x01, 0xFF) // a TLV, it's skipped.
+	b = append(b, byte(len(payload)>>8), byte(len(payload)))
+	return append(b, payload...)
+}
+
+func TestProxyProtocol(t *testing.T) {
+	tests := []struct {
+		name           string
+		trusted        []string
+		required       bool
+		send           []byte
+		expectedAddr   string // empty for the connection's one.
+		expectedBody   string
+		expectedFailed bool
+	}{
+		{"v1", nil, false, []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nGET / HTTP/1.1\r\n"), "192.0.2.1:56324", "GET / HTTP/1.1\r\n", false},
+		{"v1 ipv6", nil, false, []byte("PROXY TCP6 2001:db8::1 2001:db8::2 4711 443\r\nbody"), "[2001:db8::1]:4711", "body", false},
+		{"v1 unknown", nil, false, []byte("PROXY UNKNOWN\r\nbody"), "", "body", false},
+		{"v2", nil, false, append(proxyV2Header(net.ParseIP("203.0.113.7"), net.ParseIP("10.0.0.1"), 1234, 80), "body"...), "203.0.113.7:1234", "body", false},
+		{"missing", nil, false, []byte("POST / HTTP/1.1\r\n"), "", "POST / HTTP/1.1\r\n", false},
+		{"missing required", nil, true, []byte("POST / HTTP/1.1\r\n"), "", "", true},
+		{"invalid", nil, false, []byte("PROXY TCP4 nope\r\nbody"), "", "", true},
+		{"untrusted upstream", []string{"10.0.0.0/8"}, true, []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nbody"), "", "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nbody", false},
+		{"trusted upstream", []string{"127.0.0.1"}, true, []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nbody"), "192.0.2.1:56324", "body", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := TCP("127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pl, err := ProxyProtocol(l, ProxyProtocolConfig{TrustedUpstreams: tt.trusted, Required: tt.required})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer pl.Close()
+
+			client, err := net.Dial("tcp", l.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.Write(tt.send)
+			client.Close()
+
+			c, err := pl.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			expectedAddr := tt.expectedAddr
+			if expectedAddr == "" {
+				expectedAddr = client.LocalAddr().String()
+			}
+			if got := c.RemoteAddr().String(); got != expectedAddr {
+				t.Fatalf("expected remote address %q but got %q", expectedAddr, got)
+			}
+
+			body, err := ioutil.ReadAll(c)
+			if tt.expectedFailed {
+				if err == nil {
+					t.Fatalf("expected a read error but got the body %q", body)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(body, []byte(tt.expectedBody)) {
+				t.Fatalf("expected body %q but got %q", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestProxyProtocolInvalidUpstream(t *testing.T) {
+	if _, err := ProxyProtocol(nil, ProxyProtocolConfig{TrustedUpstreams: []string{"10.0.0.0/33"}}); err == nil {
+		t.Fatal("expected an error for the invalid trusted upstream")
+	}
+}
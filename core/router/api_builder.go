@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/kataras/iris/context"
@@ -36,23 +37,32 @@ var (
 // repository passed to all parties(subrouters), it's the object witch keeps
 // all the routes.
 //repository表示所有的路由
+// It's safe for concurrent use, the routes can be removed while the requests read them, see `RemoveRoute`.
 type repository struct {
+	mu     sync.RWMutex
 	routes []*Route
 }
 
 //注册route，即只要method以及subdomain以及tmpl.Src()不一致，则可以，如果重复，则取第一个
 func (r *repository) register(route *Route) {
-	for _, r := range r.routes {
-		if r.String() == route.String() {
-			return // do not register any duplicates, the sooner the better.
-		}
-	}
-
+	// duplicates are kept because predicates (see `Route#Headers`) may be added
+	// after the registration, they're removed by the `getAll` instead.
+	r.mu.Lock()
 	r.routes = append(r.routes, route)
+	r.mu.Unlock()
+}
+
+// list returns the registered routes, even the duplicates.
+// The slice is not modified by a later `remove`, it's replaced.
+func (r *repository) list() []*Route {
+	r.mu.RLock()
+	routes := r.routes
+	r.mu.RUnlock()
+	return routes
 }
 
 func (r *repository) get(routeName string) *Route {
-	for _, r := range r.routes {
+	for _, r := range r.list() {
 		if r.Name == routeName {
 			return r
 		}
@@ -60,8 +70,34 @@ func (r *repository) get(routeName string) *Route {
 	return nil
 }
 
+// getAll returns the registered routes, without any duplicates,
+// routes with the same method, subdomain and path are duplicates when their predicates are equal too.
+// The first registered one is kept.
 func (r *repository) getAll() []*Route {
-	return r.routes
+	var routes []*Route // lazy, nil if no duplicates found.
+
+	all := r.list()
+	seen := make(map[string]struct{}, len(all))
+	for i, route := range all {
+		key := route.String() + "\x00" + route.predicatesKey()
+		if _, duplicate := seen[key]; duplicate {
+			if routes == nil {
+				routes = append(make([]*Route, 0, len(all)), all[0:i]...)
+			}
+			continue
+		}
+		seen[key] = struct{}{}
+
+		if routes != nil {
+			routes = append(routes, route)
+		}
+	}
+
+	if routes == nil {
+		return all
+	}
+
+	return routes
 }
 
 // APIBuilder the visible API for constructing the router
@@ -78,6 +114,10 @@ type APIBuilder struct {
 	// the api builder global routes repository
 	// 表示全局的routes
 	routes *repository
+	// the pre-routing handlers of all parties, see `UseRouter`.
+	routerMiddleware *routerMiddleware
+	// the single page applications of all parties, see `SPA`.
+	spa *spaRegistry
 
 	// the api builder global route path reverser object
 	// used by the view engine but it can be used anywhere.
@@ -125,6 +165,15 @@ type APIBuilder struct {
 	// 每一个party以及他的孩子在begin、main、done的执行规则
 	// 这是可以修改handler的执行规则，让其不用ctx.Next()也可以自动进行下去
 	handlerExecutionRules ExecutionRules
+
+	// the context decorators of this Party and its children, see `DecorateContext`.
+	decorators *contextDecorators
+	// the CORS policy of this Party and its children, see `CORS`.
+	cors *corsPolicy
+	// the quality of service class of this Party's routes and its children's, see `QoS`.
+	qosClass QoSClass
+	// the request prioritization layer of all parties, see `EnableQoS`.
+	qos *QoSLimiter
 }
 
 var _ Party = (*APIBuilder)(nil)
@@ -140,6 +189,9 @@ func NewAPIBuilder() *APIBuilder {
 		reporter:          errors.NewReporter(),
 		relativePath:      "/",
 		routes:            new(repository),
+		routerMiddleware:  new(routerMiddleware),
+		spa:               new(spaRegistry),
+		qos:               new(QoSLimiter),
 	}
 
 	return api
@@ -263,6 +315,11 @@ func (api *APIBuilder) Handle(method string, relativePath string, handlers ...co
 	// 注意：这里并没有处理Global中的handler的情况
 	applyExecutionRules(api.handlerExecutionRules, &beginHandlers, &doneHandlers, &mainHandlers)
 
+	// the Party's handlers receive the decorated context, if any, see `DecorateContext`.
+	beginHandlers = api.decorators.wrap(beginHandlers)
+	mainHandlers = api.decorators.wrap(mainHandlers)
+	doneHandlers = api.decorators.wrap(doneHandlers)
+
 	//这里开始将所有的handler进行整合，以begin、main、done排序
 	// global begin handlers -> middleware that are registered before route registration
 	// -> handlers that are passed to this Handle function.
@@ -298,11 +355,25 @@ func (api *APIBuilder) Handle(method string, relativePath string, handlers ...co
 			return nil // fail on first error.
 		}
 
+		// keep the position of the main handlers for the `SwapHandlers`.
+		route.mainIndex += len(beginHandlers)
+		route.mainLen = len(mainHandlers)
+		route.execRules = api.handlerExecutionRules
+		route.decorators = api.decorators
+		route.qosClass = api.qosClass
+		route.beginLen, route.doneLen = len(beginHandlers), len(doneHandlers)
+
 		// Add UseGlobal & DoneGlobal Handlers
 		// 这里使用use以及done来表示全局的
 		route.use(api.beginGlobalHandlers)
 		route.done(api.doneGlobalHandlers)
 
+		if api.cors != nil {
+			// first of all, so the CORS headers are sent on the rejected requests too.
+			route.cors = api.cors
+			route.beginHandlers = append(context.Handlers{api.cors.handler}, route.beginHandlers...)
+		}
+
 		// global
 		api.routes.register(route)
 	}
@@ -415,6 +486,9 @@ func (api *APIBuilder) Party(relativePath string, handlers ...context.Handler) P
 		// global/api builder
 		macros:              api.macros,
 		routes:              api.routes,
+		routerMiddleware:    api.routerMiddleware,
+		spa:                 api.spa,
+		qos:                 api.qos,
 		errorCodeHandlers:   api.errorCodeHandlers,
 		beginGlobalHandlers: api.beginGlobalHandlers,
 		doneGlobalHandlers:  api.doneGlobalHandlers,
@@ -425,6 +499,9 @@ func (api *APIBuilder) Party(relativePath string, handlers ...context.Handler) P
 		relativePath:          fullpath,
 		allowMethods:          allowMethods,
 		handlerExecutionRules: api.handlerExecutionRules,
+		decorators:            api.decorators,
+		cors:                  api.cors,
+		qosClass:              api.qosClass,
 	}
 }
 
@@ -571,7 +648,7 @@ func (api *APIBuilder) Use(handlers ...context.Handler) {
 // It's always a good practise to call it right before the `Application#Run` function.
 //则在当前的每一个路由用use（看来具体的路由实现是通过路由里的beginHandlers来运行,而APIBUilder中的beginGolbalHandlers只是拿来记录)
 func (api *APIBuilder) UseGlobal(handlers ...context.Handler) {
-	for _, r := range api.routes.routes {
+	for _, r := range api.routes.list() {
 		r.use(handlers) // prepend the handlers to the existing routes
 	}
 	// set as begin handlers for the next routes as well.
@@ -599,7 +676,7 @@ api.doneHandlers = append(api.doneHandlers, handlers...)
 // It's always a good practise to call it right before the `Application#Run` function.
 // 这个与UseGlobal同理
 func (api *APIBuilder) DoneGlobal(handlers ...context.Handler) {
-	for _, r := range api.routes.routes {
+	for _, r := range api.routes.list() {
 		r.done(handlers) // append the handlers to the existing routes
 	}
 	// set as done handlers for the next routes as well.
@@ -957,6 +1034,24 @@ func (api *APIBuilder) FireErrorCode(ctx context.Context) {
 	api.errorCodeHandlers.Fire(ctx)
 }
 
+// SetErrorRenderer sets an `ErrorRenderer` which fully controls the error responses of the whole application,
+// i.e content negotiation, templating or localization,
+// instead of the error code handlers registered by the `OnErrorCode` and `OnAnyErrorCode`.
+// A nil "renderer" restores the error code handlers.
+//
+// Usage:
+// app.SetErrorRenderer(router.ErrorRendererFunc(func(ctx context.Context) {
+// 	ctx.Negotiate(iris.Map{"code": ctx.GetStatusCode()})
+// }))
+func (api *APIBuilder) SetErrorRenderer(renderer ErrorRenderer) {
+	api.errorCodeHandlers.SetRenderer(renderer)
+}
+
+// GetErrorRenderer returns the registered `ErrorRenderer`, if any.
+func (api *APIBuilder) GetErrorRenderer() ErrorRenderer {
+	return api.errorCodeHandlers.Renderer()
+}
+
 // Layout overrides the parent template layout with a more specific layout for this Party.
 // It returns the current Party.
 //
@@ -980,6 +1075,48 @@ func (api *APIBuilder) Layout(tmplLayoutFile string) Party {
 	return api
 }
 
+// PathEscape overrides the global `EnablePathEscape` configuration field
+// for this Party's routes and its children, it changes the result of the `ctx.Path()`.
+// It returns the current Party.
+//
+// Note that the route matching itself still follows the global setting,
+// use `ctx.RawPath()` to get the original, encoded, request path instead.
+//
+// Like the `Use`, it affects only the routes which are registered after its call,
+// so it should be called right after the `Party`.
+//
+// Usage:
+// proxy := app.Party("/proxy").PathEscape(false)
+// 	proxy.Get("/{p:path}", func(ctx iris.Context) {
+// 		forward(ctx.RawPath())
+// 	})
+func (api *APIBuilder) PathEscape(enable bool) Party {
+	api.Use(func(ctx context.Context) {
+		ctx.SetPathEscape(enable)
+		ctx.Next()
+	})
+
+	return api
+}
+
+// Buffered buffers the responses of this Party's routes and its children,
+// the status code and the headers can be set even after the body's writes
+// because the response is sent to the client at the end of the request.
+// It returns the current Party.
+//
+// See `Context#Buffered` for more.
+//
+// Usage:
+// pages := app.Party("/pages").Buffered(true)
+func (api *APIBuilder) Buffered(enable bool) Party {
+	api.Use(func(ctx context.Context) {
+		ctx.Buffered(enable)
+		ctx.Next()
+	})
+
+	return api
+}
+
 // joinHandlers uses to create a copy of all Handlers and return them in order to use inside the node
 func joinHandlers(h1 context.Handlers, h2 context.Handlers) context.Handlers {
 	nowLen := len(h1)
@@ -0,0 +1,233 @@
+package router
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/kataras/iris/context"
+)
+
+// BlueprintRoute is a route of a `Blueprint`, it's registered when the blueprint is mounted.
+type BlueprintRoute struct {
+	Method string
+	Path   string
+	// Name is the route's name, it's namespaced by the blueprint's name when it's mounted,
+	// i.e "index" of the "blog" blueprint is the "blog.index" route.
+	//
+	// Defaults to empty, the default name of the route.
+	Name     string
+	Handlers context.Handlers
+}
+
+type blueprintErrorHandler struct {
+	statusCode int
+	handlers   context.Handlers
+}
+
+// Blueprint is a reusable feature module, it bundles routes, middleware, error handlers,
+// view templates and static assets that a package can export
+// and the applications can mount under a path prefix, see `APIBuilder#MountBlueprint`.
+//
+// Unlike a `Party`, a blueprint is not bound to an application,
+// its route names are namespaced by its name, i.e "blog.index",
+// its templates by its name too, i.e "blog/index.html"
+// and its error handlers are fired only for the requests under its mount path.
+// 可复用的功能模块, 包括路由, 中间件, 错误处理, 模板以及静态资源, 通过 MountBlueprint 挂载到应用的指定路径下
+//
+// Usage:
+//
+//	package blog
+//
+//	func Blueprint() *router.Blueprint {
+//		b := router.NewBlueprint("blog")
+//		b.Use(loadPosts)
+//		b.Get("/", index).Name = "index"
+//		b.Get("/{slug}", post).Name = "post"
+//		b.OnErrorCode(iris.StatusNotFound, postNotFound)
+//		b.Template("index.html", `<h1>{{ .Title }}</h1>`)
+//		b.StaticFS("/assets", assetsFS)
+//		return b
+//	}
+//
+//	app.MountBlueprint("/blog", blog.Blueprint())
+type Blueprint struct {
+	name          string
+	middleware    context.Handlers
+	doneHandlers  context.Handlers
+	routes        []*BlueprintRoute
+	errorHandlers []blueprintErrorHandler
+	// templates are the view templates by their (not namespaced) names.
+	templates     map[string]string
+	templateNames []string
+	setups        []func(p Party)
+	err           error
+}
+
+// NewBlueprint returns a new empty blueprint, the "name" is the namespace of its route names and templates,
+// i.e "blog".
+func NewBlueprint(name string) *Blueprint {
+	return &Blueprint{name: strings.Trim(name, "./ "), templates: make(map[string]string)}
+}
+
+// Name returns the name of the blueprint.
+func (b *Blueprint) Name() string {
+	return b.name
+}
+
+// RouteName returns the namespaced name of the blueprint's "routeName" route,
+// i.e "blog.index", to be used by the `GetRoute` or by the `urlpath` template function.
+func (b *Blueprint) RouteName(routeName string) string {
+	return b.name + "." + routeName
+}
+
+// TemplateName returns the namespaced name of the blueprint's "name" template,
+// i.e "blog/index.html", to be rendered by the `Context#View` or the `Context#ViewPartial`.
+func (b *Blueprint) TemplateName(name string) string {
+	return b.name + "/" + strings.TrimLeft(name, "/")
+}
+
+// Use appends middleware to the routes of the blueprint, they're executed
+// after the middleware of the application's party which the blueprint is mounted to.
+func (b *Blueprint) Use(middleware ...context.Handler) *Blueprint {
+	b.middleware = append(b.middleware, middleware...)
+	return b
+}
+
+// Done appends handlers which are executed after the main handlers of the blueprint's routes.
+func (b *Blueprint) Done(handlers ...context.Handler) *Blueprint {
+	b.doneHandlers = append(b.doneHandlers, handlers...)
+	return b
+}
+
+// Handle adds a route of the "method" and the "path", relative to the blueprint's mount path.
+// Returns the route in order to set its `Name`.
+func (b *Blueprint) Handle(method string, path string, handlers ...context.Handler) *BlueprintRoute {
+	r := &BlueprintRoute{Method: method, Path: path, Handlers: handlers}
+	b.routes = append(b.routes, r)
+	return r
+}
+
+// Get adds a route of the "GET" method, see `Handle`.
+func (b *Blueprint) Get(path string, handlers ...context.Handler) *BlueprintRoute {
+	return b.Handle("GET", path, handlers...)
+}
+
+// Post adds a route of the "POST" method, see `Handle`.
+func (b *Blueprint) Post(path string, handlers ...context.Handler) *BlueprintRoute {
+	return b.Handle("POST", path, handlers...)
+}
+
+// Put adds a route of the "PUT" method, see `Handle`.
+func (b *Blueprint) Put(path string, handlers ...context.Handler) *BlueprintRoute {
+	return b.Handle("PUT", path, handlers...)
+}
+
+// Delete adds a route of the "DELETE" method, see `Handle`.
+func (b *Blueprint) Delete(path string, handlers ...context.Handler) *BlueprintRoute {
+	return b.Handle("DELETE", path, handlers...)
+}
+
+// Patch adds a route of the "PATCH" method, see `Handle`.
+func (b *Blueprint) Patch(path string, handlers ...context.Handler) *BlueprintRoute {
+	return b.Handle("PATCH", path, handlers...)
+}
+
+// OnErrorCode registers an error handler of the "statusCode" which is fired only
+// for the requests under the blueprint's mount path, the rest status codes
+// are fired by the application's error handlers.
+func (b *Blueprint) OnErrorCode(statusCode int, handlers ...context.Handler) *Blueprint {
+	b.errorHandlers = append(b.errorHandlers, blueprintErrorHandler{statusCode: statusCode, handlers: handlers})
+	return b
+}
+
+// Template adds a view template, a Go (html/template) one, which is registered as the
+// namespaced shared partial of the application when the blueprint is mounted,
+// i.e the "index.html" of the "blog" blueprint is rendered by `ctx.View("blog/index.html")`
+// through the html view engine or by `ctx.ViewPartial("blog/index.html")`.
+// The templates of the blueprint use each other by their namespaced names too,
+// i.e {{ template "blog/header.html" . }}, see `TemplateName`.
+func (b *Blueprint) Template(name string, contents string) *Blueprint {
+	name = strings.TrimLeft(name, "/")
+	if _, ok := b.templates[name]; !ok {
+		b.templateNames = append(b.templateNames, name)
+	}
+	b.templates[name] = contents
+	return b
+}
+
+// TemplateFile adds the contents of the "filename" as the "name" view template, see `Template`.
+// A read error is reported when the blueprint is mounted.
+func (b *Blueprint) TemplateFile(name string, filename string) *Blueprint {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return b
+	}
+
+	return b.Template(name, string(contents))
+}
+
+// Templates calls the "visitor" for each view template of the blueprint, by order,
+// with its namespaced name, i.e "blog/index.html".
+func (b *Blueprint) Templates(visitor func(name string, contents string)) {
+	for _, name := range b.templateNames {
+		visitor(b.TemplateName(name), b.templates[name])
+	}
+}
+
+// StaticFS serves the static assets of the "fileSystem" under the "requestPath",
+// relative to the blueprint's mount path, see `Party#StaticFS`.
+func (b *Blueprint) StaticFS(requestPath string, fileSystem interface{}, options ...StaticFSOptions) *Blueprint {
+	return b.Setup(func(p Party) {
+		p.StaticFS(requestPath, fileSystem, options...)
+	})
+}
+
+// Setup registers a function which receives the party of the blueprint when it's mounted,
+// i.e to register the features of the `Party` that the blueprint does not cover.
+func (b *Blueprint) Setup(setup func(p Party)) *Blueprint {
+	b.setups = append(b.setups, setup)
+	return b
+}
+
+// MountBlueprint registers the routes, the middleware, the static assets and the error handlers of the "b" blueprint
+// under the "relativePath" of this Party, the route names are namespaced by the blueprint's name,
+// i.e "blog.index", and the error handlers are fired only for the requests under that path.
+// The "relativePath" should be a static path, i.e "/blog".
+//
+// The templates of the blueprint are registered by the `Application#MountBlueprint`.
+//
+// Returns the party of the blueprint.
+func (api *APIBuilder) MountBlueprint(relativePath string, b *Blueprint) Party {
+	p := api.Party(relativePath, b.middleware...).(*APIBuilder)
+	if b.err != nil {
+		api.reporter.Add("blueprint '%s': %v", b.name, b.err)
+	}
+
+	if len(b.doneHandlers) > 0 {
+		p.Done(b.doneHandlers...)
+	}
+
+	for _, r := range b.routes {
+		route := p.Handle(r.Method, r.Path, r.Handlers...)
+		if route != nil && r.Name != "" {
+			route.Name = b.RouteName(r.Name)
+		}
+	}
+
+	if len(b.errorHandlers) > 0 {
+		_, path := splitSubdomainAndPath(p.relativePath)
+		scope := api.errorCodeHandlers.Scope(path)
+		for _, h := range b.errorHandlers {
+			scope.Register(h.statusCode, joinHandlers(api.beginGlobalHandlers, h.handlers)...)
+		}
+	}
+
+	for _, setup := range b.setups {
+		setup(p)
+	}
+
+	return p
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"github.com/kataras/iris/context"
+)
+
+// ContextDecorator wraps the Context of a request, i.e to a struct which embeds the Context
+// and adds typed helpers to it, see `APIBuilder#DecorateContext`.
+type ContextDecorator func(ctx context.Context) context.Context
+
+// decoratedContextKey is the key of the request's decorated context inside the `Context#Values`.
+const decoratedContextKey = "iris.router.decorated_context"
+
+// contextDecorators are the decorators of a Party, by order, the parent Party's ones first.
+// They're never modified after creation, so the routes keep the decorators of their registration.
+type contextDecorators struct {
+	decorators []ContextDecorator
+}
+
+type decoratedContext struct {
+	owner *contextDecorators
+	ctx   context.Context
+}
+
+// decorate returns the decorated "ctx", the decorators run once per request
+// and their result is shared by all the handlers of the route.
+func (d *contextDecorators) decorate(ctx context.Context) context.Context {
+	if v, ok := ctx.Values().Get(decoratedContextKey).(*decoratedContext); ok && v.owner == d {
+		return v.ctx
+	}
+
+	decorated := ctx
+	for _, decorator := range d.decorators {
+		decorated = decorator(decorated)
+	}
+
+	ctx.Values().Set(decoratedContextKey, &decoratedContext{owner: d, ctx: decorated})
+	return decorated
+}
+
+// wrap returns the "handlers" which receive the decorated context.
+func (d *contextDecorators) wrap(handlers context.Handlers) context.Handlers {
+	if d == nil || len(handlers) == 0 {
+		return handlers
+	}
+
+	wrapped := make(context.Handlers, len(handlers))
+	for i, h := range handlers {
+		h := h
+		wrapped[i] = func(ctx context.Context) {
+			h(d.decorate(ctx))
+		}
+	}
+
+	return wrapped
+}
+
+// DecorateContext registers decorators of the Context of this Party's routes and its children's,
+// which are registered after this call. The handlers, the Party's middleware
+// and the done ones, receive the decorated Context, so different sections of the app
+// can add typed helpers without affecting the unrelated routes
+// or replacing the app-wide Context implementation of the context pool.
+//
+// The decorators run once per request, after the context is acquired from the pool,
+// the parent Party's ones first, so a decorator receives the result of the previous one.
+// All the handlers of a route receive the Context decorated by all the decorators of its Party,
+// so a parent Party's middleware should assert an interface of its helpers
+// instead of its concrete type. The decorated Context should embed the received one,
+// the global handlers, see `UseGlobal`, receive the plain Context.
+//
+// Usage:
+//
+//	type adminContext struct {
+//		iris.Context
+//	}
+//
+//	func (ctx *adminContext) User() *User { return ctx.Values().Get("user").(*User) }
+//
+//	admin := app.Party("/admin")
+//	admin.DecorateContext(func(ctx iris.Context) iris.Context { return &adminContext{ctx} })
+//	admin.Get("/", func(ctx iris.Context) { ctx.(*adminContext).User() })
+//
+// 按Party装饰Context: 为某一部分路由提供带类型辅助方法的Context, 不影响其他路由
+func (api *APIBuilder) DecorateContext(decorators ...ContextDecorator) {
+	if len(decorators) == 0 {
+		return
+	}
+
+	var all []ContextDecorator
+	if api.decorators != nil {
+		all = append(all, api.decorators.decorators...)
+	}
+
+	api.decorators = &contextDecorators{decorators: append(all, decorators...)}
+}
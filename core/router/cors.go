@@ -0,0 +1,264 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// CORSOptions are the options of the `Party#CORS`, the Cross-Origin Resource Sharing policy of a Party's routes.
+type CORSOptions struct {
+	// AllowedOrigins are the origins which can access the routes, i.e "https://example.com",
+	// a single "*" wildcard can be used inside an origin, i.e "https://*.example.com", or as the whole origin.
+	// The origins are compared case-insensitive.
+	//
+	// Defaults to "*", all origins are allowed.
+	AllowedOrigins []string
+	// AllowedMethods are the methods which are allowed on a preflight request.
+	//
+	// Defaults to the methods of the registered routes of the requested path, as found by the router.
+	AllowedMethods []string
+	// AllowedHeaders are the request headers which are allowed on a preflight request, "*" for all.
+	//
+	// Defaults to all, the requested headers are allowed.
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers which can be read by the client's scripts.
+	ExposedHeaders []string
+	// AllowCredentials allows the cookies and the authorization headers to be sent,
+	// the origin is sent back as it's instead of the "*" then.
+	AllowCredentials bool
+	// MaxAge is the time that the result of a preflight request can be cached by the client.
+	//
+	// Defaults to 0, the header is not sent.
+	MaxAge time.Duration
+}
+
+// corsPolicy is the compiled `CORSOptions` of a Party.
+type corsPolicy struct {
+	anyOrigin      bool
+	origins        []string
+	methods        string
+	anyHeader      bool
+	headers        map[string]struct{}
+	exposedHeaders string
+	credentials    bool
+	maxAge         string
+}
+
+func newCORSPolicy(opts CORSOptions) *corsPolicy {
+	p := &corsPolicy{
+		anyOrigin:      len(opts.AllowedOrigins) == 0,
+		methods:        strings.ToUpper(strings.Join(opts.AllowedMethods, ", ")),
+		anyHeader:      len(opts.AllowedHeaders) == 0,
+		headers:        make(map[string]struct{}, len(opts.AllowedHeaders)),
+		exposedHeaders: strings.Join(opts.ExposedHeaders, ", "),
+		credentials:    opts.AllowCredentials,
+	}
+
+	for _, origin := range opts.AllowedOrigins {
+		if origin == "*" {
+			p.anyOrigin = true
+			continue
+		}
+		p.origins = append(p.origins, strings.ToLower(origin))
+	}
+
+	for _, h := range opts.AllowedHeaders {
+		if h == "*" {
+			p.anyHeader = true
+			continue
+		}
+		p.headers[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+
+	if opts.MaxAge > 0 {
+		p.maxAge = strconv.FormatInt(int64(opts.MaxAge/time.Second), 10)
+	}
+
+	return p
+}
+
+// matchOrigin reports whether the "pattern" matches the "origin", the pattern can contain a single "*".
+func matchOrigin(pattern, origin string) bool {
+	idx := strings.IndexByte(pattern, '*')
+	if idx == -1 {
+		return pattern == origin
+	}
+
+	prefix, suffix := pattern[:idx], pattern[idx+1:]
+	return len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
+
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	for _, pattern := range p.origins {
+		if matchOrigin(pattern, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeOrigin sends the allowed "origin" back.
+func (p *corsPolicy) writeOrigin(ctx context.Context, origin string) {
+	if p.anyOrigin && !p.credentials {
+		ctx.Header("Access-Control-Allow-Origin", "*")
+	} else {
+		ctx.Header("Access-Control-Allow-Origin", origin)
+	}
+
+	if p.credentials {
+		ctx.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func isPreflight(ctx context.Context) bool {
+	return ctx.Method() == http.MethodOptions && ctx.GetHeader("Origin") != "" &&
+		ctx.GetHeader("Access-Control-Request-Method") != ""
+}
+
+// handler is the first handler of the Party's routes, it sends the CORS headers of the actual requests
+// and answers the preflight requests of the routes which are registered for the OPTIONS method too.
+func (p *corsPolicy) handler(ctx context.Context) {
+	if isPreflight(ctx) {
+		p.preflight(ctx, ctx.Application().AllowedMethods(ctx, ctx.Path()))
+		ctx.StopExecution()
+		return
+	}
+
+	if origin := ctx.GetHeader("Origin"); origin != "" {
+		ctx.ResponseWriter().Header().Add("Vary", "Origin")
+		if p.allowOrigin(origin) {
+			p.writeOrigin(ctx, origin)
+			if p.exposedHeaders != "" {
+				ctx.Header("Access-Control-Expose-Headers", p.exposedHeaders)
+			}
+		}
+	}
+
+	ctx.Next()
+}
+
+// preflight answers a preflight request, the "routeMethods" are the methods of the routes of the requested path.
+// A disallowed origin, method or header is answered with 403 (Forbidden) without the CORS headers.
+func (p *corsPolicy) preflight(ctx context.Context, routeMethods []string) {
+	h := ctx.ResponseWriter().Header()
+	h.Add("Vary", "Origin")
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	origin := ctx.GetHeader("Origin")
+	method := strings.ToUpper(ctx.GetHeader("Access-Control-Request-Method"))
+	if !p.allowOrigin(origin) {
+		ctx.StatusCode(http.StatusForbidden)
+		return
+	}
+
+	methods := p.methods
+	if methods == "" {
+		methods = strings.Join(routeMethods, ", ")
+	}
+
+	if !containsToken(methods, method) {
+		ctx.StatusCode(http.StatusForbidden)
+		return
+	}
+
+	requestedHeaders := ctx.GetHeader("Access-Control-Request-Headers")
+	if !p.anyHeader {
+		for _, name := range strings.Split(requestedHeaders, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if _, ok := p.headers[http.CanonicalHeaderKey(name)]; !ok {
+				ctx.StatusCode(http.StatusForbidden)
+				return
+			}
+		}
+	}
+
+	p.writeOrigin(ctx, origin)
+	ctx.Header("Access-Control-Allow-Methods", methods)
+	if requestedHeaders != "" {
+		ctx.Header("Access-Control-Allow-Headers", requestedHeaders)
+	}
+	if p.maxAge != "" {
+		ctx.Header("Access-Control-Max-Age", p.maxAge)
+	}
+
+	ctx.StatusCode(http.StatusNoContent)
+}
+
+// containsToken reports whether the comma separated "list" contains the "token".
+func containsToken(list, token string) bool {
+	for _, t := range strings.Split(list, ",") {
+		if strings.TrimSpace(t) == token {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CORS registers the Cross-Origin Resource Sharing policy of this Party's future routes and its children's,
+// a child Party can register its own policy which overrides the parent's one.
+//
+// The CORS headers are sent before any other handler of the routes, so the rejected requests,
+// i.e by an authentication middleware, can still be read by the allowed origins.
+// The preflight (OPTIONS) requests of the routes are answered automatically by the router,
+// there is no need to register OPTIONS routes or to call the `AllowMethods(iris.MethodOptions)`.
+//
+// Usage:
+// api := app.Party("/api")
+// api.CORS(router.CORSOptions{AllowedOrigins: []string{"https://*.example.com"}, AllowCredentials: true, MaxAge: time.Hour})
+// api.Get("/users", listUsers)
+// api.Post("/users", createUser)
+func (api *APIBuilder) CORS(options CORSOptions) Party {
+	api.cors = newCORSPolicy(options)
+	return api
+}
+
+// servePreflight answers the preflight request of a route with a CORS policy, it reports whether it's answered.
+// The route of the requested method is found through the router's trie.
+func (s *routerState) servePreflight(ctx context.Context, path string) bool {
+	if len(s.cors) == 0 || !isPreflight(ctx) {
+		return false
+	}
+
+	method := strings.ToUpper(ctx.GetHeader("Access-Control-Request-Method"))
+	var params context.RequestParams
+	for _, t := range s.trees {
+		if t.method != method {
+			continue
+		}
+		if s.hosts && t.subdomain != "" && !s.matchSubdomain(ctx, t.subdomain) {
+			continue
+		}
+
+		n := t.search(path, &params)
+		if n == nil {
+			continue
+		}
+
+		routeName := n.RouteName
+		if routeName == "" && len(n.predicated) > 0 {
+			routeName = n.predicated[0].RouteName
+		}
+
+		if p, ok := s.cors[routeName]; ok {
+			p.preflight(ctx, s.allowedMethods(ctx, path))
+			return true
+		}
+		return false
+	}
+
+	return false
+}
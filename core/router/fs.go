@@ -1,6 +1,8 @@
 package router
 
 import (
+	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
@@ -87,18 +89,28 @@ func StaticEmbeddedHandler(vdir string, assetFn func(name string) ([]byte, error
 			cType := TypeByFilename(path)
 
 			buf, err := assetFn(path) // remove the first slash
-
-			if assetsGziped {
-				// this will add the "Vary" : "Accept-Encoding"
-				// and 					"Content-Encoding": "gzip"
-				// headers.
-				context.AddGzipHeaders(ctx.ResponseWriter())
-			}
-
 			if err != nil {
 				continue
 			}
 
+			if assetsGziped {
+				if ctx.ClientSupportsGzip() {
+					// this will add the "Vary" : "Accept-Encoding"
+					// and 					"Content-Encoding": "gzip"
+					// headers.
+					context.AddGzipHeaders(ctx.ResponseWriter())
+				} else {
+					// the client does not accept gzip (i.e "gzip;q=0"),
+					// send the decompressed asset instead.
+					ctx.Header(context.VaryHeaderKey, context.AcceptEncodingHeaderKey)
+					if buf, err = gunzip(buf); err != nil {
+						ctx.StatusCode(http.StatusInternalServerError)
+						ctx.StopExecution()
+						return
+					}
+				}
+			}
+
 			ctx.ContentType(cType)
 			if _, err := ctx.Write(buf); err != nil {
 				ctx.StatusCode(http.StatusInternalServerError)
@@ -114,6 +126,17 @@ func StaticEmbeddedHandler(vdir string, assetFn func(name string) ([]byte, error
 	return h
 }
 
+// gunzip decompresses the gzip "data".
+func gunzip(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
+
 // StaticHandler returns a new Handler which is ready
 // to serve all kind of static files.
 //
@@ -272,7 +295,8 @@ func (w *fsHandler) Build() context.Handler {
 				path.Clean(upath),
 				false,
 				w.listDirectories,
-				gzipEnabled)
+				gzipEnabled,
+				indexPage)
 
 			// check for any http errors after the file handler executed
 			if context.StatusCodeNotSuccessful(prevStatusCode) { // error found (404 or 400 or 500 usually)
@@ -736,11 +760,12 @@ func checkIfRange(ctx context.Context, etagEmptyOrStrongMatch func(ifRangeValue
 const indexPage = "/index.html"
 
 // name is '/'-separated, not filepath.Separator.
-func serveFile(ctx context.Context, fs http.FileSystem, name string, redirect bool, showList bool, gzip bool) (string, int) {
+// index is the '/'-prefixed name of the directories' index file, i.e the "/index.html".
+func serveFile(ctx context.Context, fs http.FileSystem, name string, redirect bool, showList bool, gzip bool, index string) (string, int) {
 	// redirect .../index.html to .../
 	// can't use Redirect() because that would make the path absolute,
 	// which would be a problem running under StripPrefix
-	if strings.HasSuffix(ctx.Request().URL.Path, indexPage) {
+	if strings.HasSuffix(ctx.Request().URL.Path, index) {
 		localRedirect(ctx, "./")
 		return "", http.StatusMovedPermanently
 	}
@@ -784,8 +809,7 @@ func serveFile(ctx context.Context, fs http.FileSystem, name string, redirect bo
 
 	// use contents of index.html for directory, if present
 	if d.IsDir() {
-		index := strings.TrimSuffix(name, "/") + indexPage
-		ff, err := fs.Open(index)
+		ff, err := fs.Open(strings.TrimSuffix(name, "/") + index)
 		if err == nil {
 			defer ff.Close()
 			dd, err := ff.Stat()
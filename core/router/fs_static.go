@@ -0,0 +1,154 @@
+package router
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// StaticFSOptions are the options of the `StaticFSHandler` and the `Party#StaticFS`.
+type StaticFSOptions struct {
+	// IndexName is the file which is served for the directories.
+	//
+	// Defaults to "index.html".
+	IndexName string
+	// ShowList lists the files of the directories which have no index file.
+	//
+	// Defaults to false, the directories are forbidden.
+	ShowList bool
+	// Gzip compresses the files when the client supports it.
+	//
+	// Defaults to false.
+	Gzip bool
+	// CacheMaxAge sets the "Cache-Control: public, max-age=..." header of the served files,
+	// i.e 365 * 24 * time.Hour for the fingerprinted assets.
+	//
+	// Defaults to zero, no header is set.
+	CacheMaxAge time.Duration
+	// SPA serves the index file of the root, with a "Cache-Control: no-cache" header,
+	// for the not found paths without a file extension,
+	// so the client-side router of a single page application can handle them.
+	//
+	// Defaults to false.
+	SPA bool
+}
+
+// toFileSystem returns the http.FileSystem of an http.FileSystem, a fs.FS (i.e an embed.FS) or a directory.
+func toFileSystem(fileSystem interface{}) http.FileSystem {
+	switch v := fileSystem.(type) {
+	case http.FileSystem:
+		return v
+	case fs.FS:
+		return http.FS(v)
+	case string:
+		return http.Dir(Abs(v))
+	default:
+		panic(fmt.Sprintf("static fs: unsupported file system of type %T", fileSystem))
+	}
+}
+
+// StaticFSHandler returns a Handler which serves the files of the "fileSystem",
+// an http.FileSystem (i.e packr's boxes), a fs.FS (i.e an embed.FS of the go:embed) or a system directory.
+// The request path is the name of the file, use the `StripPrefix` to remove a path prefix,
+// or use the `Party#StaticFS` instead.
+//
+// The sub directory of an embed.FS can be served with the fs.Sub, i.e fs.Sub(embedFS, "public").
+//
+// It panics if the "fileSystem" is not one of the supported types.
+// 支持 http.FileSystem 和 fs.FS(go:embed) 的静态文件服务
+func StaticFSHandler(fileSystem interface{}, options ...StaticFSOptions) context.Handler {
+	fsys := toFileSystem(fileSystem)
+
+	var opts StaticFSOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.IndexName == "" {
+		opts.IndexName = "index.html"
+	}
+	index := "/" + strings.TrimPrefix(opts.IndexName, "/")
+
+	cacheControl := ""
+	if opts.CacheMaxAge > 0 {
+		cacheControl = fmt.Sprintf("public, max-age=%d", int64(opts.CacheMaxAge/time.Second))
+	}
+
+	return func(ctx context.Context) {
+		upath := ctx.Request().URL.Path
+		if !strings.HasPrefix(upath, "/") {
+			upath = "/" + upath
+			ctx.Request().URL.Path = upath
+		}
+
+		gzipEnabled := opts.Gzip
+		if !gzipEnabled {
+			_, gzipEnabled = ctx.ResponseWriter().(*context.GzipResponseWriter)
+		}
+
+		if cacheControl != "" {
+			ctx.Header(context.CacheControlHeaderKey, cacheControl)
+		}
+
+		name := path.Clean(upath)
+		if path.Ext(name) == "" && !strings.HasSuffix(upath, "/") {
+			// the router removes the trailing slash of the directories' paths,
+			// add it back so their index is served instead of a redirect.
+			if f, err := fsys.Open(name); err == nil {
+				if d, err := f.Stat(); err == nil && d.IsDir() {
+					ctx.Request().URL.Path = upath + "/"
+				}
+				f.Close()
+			}
+		}
+
+		_, statusCode := serveFile(ctx, fsys, name, false, opts.ShowList, gzipEnabled, index)
+		if statusCode == http.StatusNotFound && opts.SPA && path.Ext(name) == "" {
+			ctx.Header(context.CacheControlHeaderKey, "no-cache")
+			_, statusCode = serveFile(ctx, fsys, index, false, false, gzipEnabled, index)
+		}
+
+		if context.StatusCodeNotSuccessful(statusCode) {
+			if writer, ok := ctx.ResponseWriter().(*context.GzipResponseWriter); ok && writer != nil {
+				writer.ResetBody()
+				writer.Disable()
+			}
+			ctx.ResponseWriter().Header().Del(context.CacheControlHeaderKey)
+			ctx.StatusCode(statusCode)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// StaticFS registers a route which serves the files of the "fileSystem" under the "requestPath",
+// the "fileSystem" can be an http.FileSystem, a fs.FS (i.e an embed.FS of the go:embed) or a system directory,
+// see `StaticFSHandler` and `StaticFSOptions` for more.
+//
+// Usage:
+//
+//	//go:embed public
+//	var embedFS embed.FS
+//
+//	public, _ := fs.Sub(embedFS, "public")
+//	app.StaticFS("/", public, router.StaticFSOptions{Gzip: true, SPA: true})
+//
+// Returns the GET *Route.
+func (api *APIBuilder) StaticFS(requestPath string, fileSystem interface{}, options ...StaticFSOptions) *Route {
+	fullpath := joinPath(api.relativePath, requestPath)
+	_, fullpath = splitSubdomainAndPath(fullpath)
+
+	h := StaticFSHandler(fileSystem, options...)
+	if fullpath != "/" {
+		h = StripPrefix(fullpath, h)
+		// serve the index of the root directory without a trailing slash too.
+		api.registerResourceRoute(requestPath, h)
+	}
+
+	return api.registerResourceRoute(joinPath(requestPath, WildcardParam("file")), h)
+}
@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/kataras/golog"
 
@@ -27,22 +30,62 @@ type RequestHandler interface {
 	// RouteExists reports whether a particular route exists.
 	//判断指定的路由是否存在
 	RouteExists(ctx context.Context, method, path string) bool
+
+	// AllowedMethods returns the methods of the routes that match the "path"
+	// and the current subdomain of context's host.
+	AllowedMethods(ctx context.Context, path string) []string
 }
 
 //routerHandler实现了RequestHanlder,说明这里算是一个核心
 type routerHandler struct {
+	// state is the built state of the router, a new one is stored on each `Build`,
+	// so the rebuilds, i.e a `RefreshRouter`, never race with the requests being served.
+	state atomic.Value // *routerState
+	// mu serializes the changes of the state, the requests are served without locking.
+	mu sync.Mutex
+	// if true then the routes' handlers are wrapped by the `context#TimeHandler`.
+	timing bool
+}
+
+// routerState is the built, copy-on-write, state of the `routerHandler`,
+// a request is served by the same state from the start to the end, see `routerHandler#Build`.
+// 路由构建后的状态, 重新构建时整体替换(写时复制), 处理请求时无需加锁
+type routerState struct {
 	//为啥是数组？因为第一个路径可能不一样
 	trees []*trie
 	//只有有其中一个route包含subDomain，则
 	hosts bool // true if at least one route contains a Subdomain.
+	// if true then the routes' handlers are wrapped by the `context#TimeHandler`.
+	timing bool
+	// the pre-routing handlers, in their execution order, see `APIBuilder#UseRouter`.
+	routerMiddleware []*routerMiddlewareEntry
+	// the single page applications, the most specific first, see `APIBuilder#SPA`.
+	spaFallbacks []*spaFallback
+	// the CORS policies by route name, for the preflight requests, see `APIBuilder#CORS`.
+	cors map[string]*corsPolicy
+	// the request prioritization layer and the non-normal classes by route name, see `APIBuilder#EnableQoS`.
+	qos        *QoSLimiter
+	qosClasses map[string]QoSClass
 }
 
 var _ RequestHandler = &routerHandler{}
 
+// emptyRouterState is the state of a router handler which is not built yet.
+var emptyRouterState = new(routerState)
+
+// load returns the current state of the router.
+func (h *routerHandler) load() *routerState {
+	if s, ok := h.state.Load().(*routerState); ok {
+		return s
+	}
+
+	return emptyRouterState
+}
+
 //这里根据方法类型以及子域来判断
-func (h *routerHandler) getTree(method, subdomain string) *trie {
-	for i := range h.trees {
-		t := h.trees[i]
+func (s *routerState) getTree(method, subdomain string) *trie {
+	for i := range s.trees {
+		t := s.trees[i]
 		//因此可以看出subdomain以及method的不同组合分别可以独立代表一个分支
 		if t.method == method && t.subdomain == subdomain {
 			return t
@@ -52,7 +95,7 @@ func (h *routerHandler) getTree(method, subdomain string) *trie {
 	return nil
 }
 
-func (h *routerHandler) addRoute(r *Route) error {
+func (s *routerState) addRoute(r *Route) error {
 	var (
 		routeName = r.Name
 		method    = r.Method
@@ -61,19 +104,76 @@ func (h *routerHandler) addRoute(r *Route) error {
 		handlers  = r.Handlers
 	)
 
-	t := h.getTree(method, subdomain)
+	t := s.getTree(method, subdomain)
 
 	if t == nil {
 		n := newTrieNode()
 		// first time we register a route to this method with this subdomain
 		t = &trie{method: method, subdomain: subdomain, root: n}
-		h.trees = append(h.trees, t)
+		s.trees = append(s.trees, t)
 	}
 	//根据method和subdomain直接开始进行填充
-	t.insert(path, routeName, handlers)
+	handlers = s.wrapHandlers(handlers)
+
+	stats := r.stats
+	if stats == nil {
+		// routes that are not created through `NewRoute`.
+		stats = newRouteStats()
+		r.stats = stats
+	}
+
+	t.insert(path, routeName, handlers, r.buildGuard(), stats, r.buildPredicate())
 	return nil
 }
 
+// wrapHandlers returns the handlers to be inserted into the trie.
+func (s *routerState) wrapHandlers(handlers context.Handlers) context.Handlers {
+	if s.timing {
+		// wrap a copy, the route's handlers are kept as they're,
+		// so a rebuild will not wrap them twice.
+		timed := make(context.Handlers, len(handlers))
+		for i, handler := range handlers {
+			timed[i] = context.TimeHandler(handler)
+		}
+		handlers = timed
+	}
+
+	return handlers
+}
+
+// swapHandlers stores the current handlers of the "r" route to its trie node,
+// it reports whether the route was found.
+func (h *routerHandler) swapHandlers(r *Route) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.load()
+	t := s.getTree(r.Method, r.Subdomain)
+	if t == nil {
+		return false
+	}
+
+	n := t.find(r.Path)
+	if n == nil {
+		return false
+	}
+
+	handlers := s.wrapHandlers(r.Handlers)
+	if n.RouteName == r.Name {
+		n.swapped.Store(handlers)
+		return true
+	}
+
+	for _, p := range n.predicated {
+		if p.RouteName == r.Name {
+			p.swapped.Store(handlers)
+			return true
+		}
+	}
+
+	return false
+}
+
 // NewDefaultHandler returns the handler which is responsible
 // to map the request with a route (aka mux implementation).
 // 直接返回一个默认的routerHandler
@@ -82,6 +182,15 @@ func NewDefaultHandler() RequestHandler {
 	return h
 }
 
+// NewTimingHandler same as `NewDefaultHandler` but each one of the routes' handlers
+// is wrapped by the `context#TimeHandler` in order to measure the time spent on it.
+//
+// Used by the Application when the `Configuration#EnableHandlerTiming` is true.
+func NewTimingHandler() RequestHandler {
+	h := &routerHandler{timing: true}
+	return h
+}
+
 // RoutesProvider should be implemented by
 // iteral which contains the registered routes.
 //(APIBuilder实现了RoutesProvider)
@@ -91,20 +200,47 @@ type RoutesProvider interface {
 	GetRoute(routeName string) *Route
 }
 
+// Build builds the routes of the "provider" into the router's tries.
+// The routes are inserted to a new state which replaces the current one atomically when it's fully built,
+// the in-flight requests are served by the previous state, so it's safe to be called at serve-time,
+// i.e by the `RefreshRouter` after a `Route#ChangeMethod`.
 // iris就是通过这里来实现路由与树的绑定，在router.go中由于是通过interface之间的调用，所以找不到
 func (h *routerHandler) Build(provider RoutesProvider) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	registeredRoutes := provider.GetRoutes()
-	//这里重置了routerHandler的trees
-	h.trees = h.trees[0:0] // reset, inneed when rebuilding.
+	//每次构建一个新的状态, 构建完成后再替换
+	s := &routerState{timing: h.timing}
+
+	if p, ok := provider.(routerMiddlewareProvider); ok {
+		s.routerMiddleware = p.getRouterMiddleware()
+	}
+
+	if p, ok := provider.(spaFallbackProvider); ok {
+		s.spaFallbacks = p.getSPAFallbacks()
+	}
+
+	if p, ok := provider.(qosProvider); ok {
+		s.qos, s.qosClasses = p.getQoS()
+	}
 
 	// sort, subdomains goes first.
 	// 这就是将此时的routesProvider的route排序
 	// 首先根据路径层次的长度(strings.Count())，然后再通过Route的tmpl字段中的Params字段
-	sort.Slice(registeredRoutes, func(i, j int) bool {
+	sort.SliceStable(registeredRoutes, func(i, j int) bool {
 		first, second := registeredRoutes[i], registeredRoutes[j]
 		lsub1 := len(first.Subdomain)
 		lsub2 := len(second.Subdomain)
 
+		// the wildcard subdomain goes after the specific ones, so it never shadows them,
+		// the routes registered in the opposite order are reported, see `RouteConflictShadowed`.
+		if lsub1 > 0 && lsub2 > 0 {
+			if w1, w2 := first.Subdomain == SubdomainWildcardIndicator, second.Subdomain == SubdomainWildcardIndicator; w1 != w2 {
+				return w2
+			}
+		}
+
 		firstSlashLen := strings.Count(first.Path, "/")
 		secondSlashLen := strings.Count(second.Path, "/")
 
@@ -134,7 +270,25 @@ func (h *routerHandler) Build(provider RoutesProvider) error {
 	//这里的Reporter也是iris自己定义的
 	rp := errors.NewReporter()
 
+	// the conflicting registrations, they're not inserted to the trie.
+	if p, ok := provider.(registeredRoutesProvider); ok {
+		for _, conflict := range routeConflicts(p.getRegisteredRoutes()) {
+			if conflict.Reason == RouteConflictShadowed {
+				golog.Warnf("%s", conflict.Error())
+				continue
+			}
+			rp.AddErr(conflict)
+		}
+	}
+
 	for _, r := range registeredRoutes {
+		if r.cors != nil {
+			if s.cors == nil {
+				s.cors = make(map[string]*corsPolicy)
+			}
+			s.cors[r.Name] = r.cors
+		}
+
 		// build the r.Handlers based on begin and done handlers, if any.
 		//这就是之前的每个route通过routeHandle.build()来进行整合handler
 		r.BuildHandlers()
@@ -144,7 +298,7 @@ func (h *routerHandler) Build(provider RoutesProvider) error {
 		// 这里的subDomain可以看handler.go中的Handle()方法，对应逻辑中的subDomain值
 		// todo 问题：不理解有subDomain实际中的作用
 		if r.Subdomain != "" {
-			h.hosts = true
+			s.hosts = true
 		}
 
 		// the only "bad" with this is if the user made an error
@@ -156,7 +310,7 @@ func (h *routerHandler) Build(provider RoutesProvider) error {
 		//实际上就是使用interface来调用，所以隐藏了
 		// 问题：不过哪里代码实现的还需要寻找？？
 		// 解答：这里 h 是APIBuilder
-		if err := h.addRoute(r); err != nil {
+		if err := s.addRoute(r); err != nil {
 			// node errors:
 			rp.Add("%v -> %s", err, r.String())
 			continue
@@ -165,10 +319,37 @@ func (h *routerHandler) Build(provider RoutesProvider) error {
 		golog.Debugf(r.Trace())
 	}
 
+	h.state.Store(s)
 	return rp.Return()
 }
 
 func (h *routerHandler) HandleRequest(ctx context.Context) {
+	// the same state is used for the whole request, even if a rebuild happens meanwhile.
+	s := h.load()
+	if len(s.routerMiddleware) > 0 {
+		var handlers context.Handlers
+		for _, e := range s.routerMiddleware {
+			if (e.subdomain == "" || s.matchSubdomain(ctx, e.subdomain)) && e.matchPath(ctx.Path()) {
+				handlers = append(handlers, e.handlers...)
+			}
+		}
+
+		if len(handlers) > 0 {
+			// the routing is the last handler of the chain.
+			ctx.Do(append(handlers, func(ctx context.Context) {
+				// the route's handlers start from the beginning.
+				ctx.HandlerIndex(0)
+				s.serveRoute(ctx)
+			}))
+			return
+		}
+	}
+
+	s.serveRoute(ctx)
+}
+
+// serveRoute finds the route of the request and executes its handlers.
+func (s *routerState) serveRoute(ctx context.Context) {
 	method := ctx.Method()
 	path := ctx.Path()
 	//ctx.Application().ConfigurationReadOnly()返回iris.Configuration,然后再调用GetDisablePathCorrection()
@@ -222,55 +403,60 @@ func (h *routerHandler) HandleRequest(ctx context.Context) {
 		}
 	}
 
-	for i := range h.trees {
-		t := h.trees[i]
+	for i := range s.trees {
+		t := s.trees[i]
 		if method != t.method {
 			continue
 		}
 		// 问题：这里是判断路由中是否有子域，这里的t是trie，看一下trie中的subdomain怎么生成的，应该也是APIBuilder中NewRoute()产生的？？？
 		// 解答：按一般的常规写法，这里的t.subdomain都为 "",除非一些特殊的，比如有用 *. 等等
-		if h.hosts && t.subdomain != "" {
-			//返回当前http请求的url
-			requestHost := ctx.Host()
-			if netutil.IsLoopbackSubdomain(requestHost) { //这里就是来修复 127.0.0.1这个bug来引起subdomain的问题
-				// this fixes a bug when listening on
-				// 127.0.0.1:8080 for example
-				// and have a wildcard subdomain and a route registered to root domain.
-				continue // it's not a subdomain, it's something like 127.0.0.1 probably
-			}
-			// it's a dynamic wildcard subdomain, we have just to check if ctx.subdomain is not empty
-			//todo 这个表示有子域，先暂时不考虑
-			if t.subdomain == SubdomainWildcardIndicator { // SubdomainWildcardIndicator="*."
-				// mydomain.com -> invalid
-				// localhost -> invalid
-				// sub.mydomain.com -> valid
-				// sub.localhost -> valid
-				serverHost := ctx.Application().ConfigurationReadOnly().GetVHost()
-				if serverHost == requestHost {
-					continue // it's not a subdomain, it's a full domain (with .com...)
-				}
-
-				dotIdx := strings.IndexByte(requestHost, '.')
-				slashIdx := strings.IndexByte(requestHost, '/')
-				if dotIdx > 0 && (slashIdx == -1 || slashIdx > dotIdx) {
-					// if "." was found anywhere but not at the first path segment (host).
-				} else {
-					continue
-				}
-				// continue to that, any subdomain is valid.
-			} else if !strings.HasPrefix(requestHost, t.subdomain) { // t.subdomain contains the dot.
-				// 这种情况是真的判断t.subdomain是否是requestHost的前缀，一般t.subdomain 到这里比较少见（或者是我不熟悉）
-				// 用了两层Party,一层是Party("test/home")，这里是为了hasSubDomain有第二个/，还有一个是Party("v1.")
-				// 此时生成的subDomain为v1.test ，path为/home，因此如果不符合则会跳过
-				continue
-			}
+		if s.hosts && t.subdomain != "" && !s.matchSubdomain(ctx, t.subdomain) {
+			continue
 		}
 		//这里暂时只考虑静态路径的流程，动态的先不管，所以ctx.Params()在静态流程中是无所谓的
 		n := t.search(path, ctx.Params())
 		if n != nil {
+			routeName, handlers, guard, stats := n.RouteName, n.handlers(), n.Guard, n.stats
+			// the match filter stage, the first route with matched predicates wins.
+			for _, p := range n.predicated {
+				if p.match(ctx) {
+					routeName, handlers, guard, stats = p.RouteName, p.handlers(), p.Guard, p.stats
+					break
+				}
+			}
+
+			if len(handlers) == 0 {
+				// none of the routes' predicates matched the request
+				// and there is no a route without predicates for that path.
+				break
+			}
+
 			//找到指定的路由，然后设置其名称，然后调用其Handlers
-			ctx.SetCurrentRouteName(n.RouteName)
-			ctx.Do(n.Handlers)
+			ctx.SetCurrentRouteName(routeName)
+			if tracer := ctx.Application().GetTracer(); tracer != nil {
+				// the span is named after the matched route's template, it includes the guards too.
+				span := startRouteSpan(ctx, tracer)
+				defer endRouteSpan(ctx, span)
+			}
+			start := time.Now()
+			if guard != nil {
+				// the route's guards are evaluated before any handler.
+				if ok, status := guard(ctx); !ok {
+					if status == 0 {
+						status = http.StatusForbidden
+					}
+					ctx.StatusCode(status)
+					stats.record(ctx, time.Since(start))
+					return
+				}
+			}
+			if s.qos != nil {
+				// under load the request may wait or it's shed, based on its route's class.
+				s.qos.serve(ctx, s.qosClasses[routeName], handlers)
+			} else {
+				ctx.Do(handlers)
+			}
+			stats.record(ctx, time.Since(start))
 			// found
 			return
 		}
@@ -278,33 +464,92 @@ func (h *routerHandler) HandleRequest(ctx context.Context) {
 		break
 	}
 
+	if method == http.MethodOptions && s.servePreflight(ctx, path) {
+		return
+	}
+
+	if (method == http.MethodGet || method == http.MethodHead) && s.serveSPA(ctx, path) {
+		return
+	}
+
 	//这下面的逻辑FireMethodNotAllowed表示如果找不到的话用405顶替，而不是404(具体可以看Configuration中的FireMethodNotAllowed字段)
 	if ctx.Application().ConfigurationReadOnly().GetFireMethodNotAllowed() {
-		for i := range h.trees {
-			t := h.trees[i]
-			// if `Configuration#FireMethodNotAllowed` is kept as defaulted(false) then this function will not
-			// run, therefore performance kept as before.
-			// 寻找是否有路由的方法是""的,里面的逻辑跟上面类似，感觉上面也可以用subdomainAndPathAndMethodExists来代替
-			if h.subdomainAndPathAndMethodExists(ctx, t, "", path) {
-				// RCF rfc2616 https://www.w3.org/Protocols/rfc2616/rfc2616-sec10.html
-				// The response MUST include an Allow header containing a list of valid methods for the requested resource.
-				//添加这个Allow头文件是因为rfc2616中规定返回405所要求的
-				ctx.Header("Allow", t.method)
-				ctx.StatusCode(http.StatusMethodNotAllowed)
-				return
-			}
+		// if `Configuration#FireMethodNotAllowed` is kept as defaulted(false) then this function will not
+		// run, therefore performance kept as before.
+		// 收集所有匹配该路径的路由方法
+		if methods := s.allowedMethods(ctx, path); len(methods) > 0 {
+			// RCF rfc2616 https://www.w3.org/Protocols/rfc2616/rfc2616-sec10.html
+			// The response MUST include an Allow header containing a list of valid methods for the requested resource.
+			//添加这个Allow头文件是因为rfc2616中规定返回405所要求的
+			ctx.Header("Allow", strings.Join(methods, ", "))
+			ctx.StatusCode(http.StatusMethodNotAllowed)
+			return
 		}
 	}
 
 	ctx.StatusCode(http.StatusNotFound)
 }
 
-func (h *routerHandler) subdomainAndPathAndMethodExists(ctx context.Context, t *trie, method, path string) bool {
+// serveSPA serves the unmatched request through the most specific single page application, if any,
+// it reports whether one matched.
+func (s *routerState) serveSPA(ctx context.Context, path string) bool {
+	for _, f := range s.spaFallbacks {
+		if (f.subdomain == "" || s.matchSubdomain(ctx, f.subdomain)) && f.matchPath(path) {
+			ctx.Do(f.handlers)
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchSubdomain reports whether the request's host matches the "subdomain" (which contains the dot),
+// i.e "admin." or the wildcard "*.".
+func (s *routerState) matchSubdomain(ctx context.Context, subdomain string) bool {
+	//返回当前http请求的url
+	requestHost := ctx.Host()
+	if netutil.IsLoopbackSubdomain(requestHost) { //这里就是来修复 127.0.0.1这个bug来引起subdomain的问题
+		// this fixes a bug when listening on
+		// 127.0.0.1:8080 for example
+		// and have a wildcard subdomain and a route registered to root domain.
+		return false // it's not a subdomain, it's something like 127.0.0.1 probably
+	}
+	// it's a dynamic wildcard subdomain, we have just to check if ctx.subdomain is not empty
+	if subdomain == SubdomainWildcardIndicator { // SubdomainWildcardIndicator="*."
+		// mydomain.com -> invalid
+		// localhost -> invalid
+		// sub.mydomain.com -> valid
+		// sub.localhost -> valid
+		serverHost := ctx.Application().ConfigurationReadOnly().GetVHost()
+		if serverHost == requestHost {
+			return false // it's not a subdomain, it's a full domain (with .com...)
+		}
+
+		dotIdx := strings.IndexByte(requestHost, '.')
+		slashIdx := strings.IndexByte(requestHost, '/')
+		// if "." was found anywhere but not at the first path segment (host),
+		// any subdomain is valid.
+		return dotIdx > 0 && (slashIdx == -1 || slashIdx > dotIdx)
+	}
+
+	// 这种情况是真的判断subdomain是否是requestHost的前缀
+	// 用了两层Party,一层是Party("test/home")，这里是为了hasSubDomain有第二个/，还有一个是Party("v1.")
+	// 此时生成的subDomain为v1.test ，path为/home，因此如果不符合则会跳过
+	return strings.HasPrefix(requestHost, subdomain) // subdomain contains the dot.
+}
+
+func (s *routerState) subdomainAndPathAndMethodExists(ctx context.Context, t *trie, method, path string) bool {
+	return s.subdomainAndPathAndMethodMatch(ctx, t, method, path, ctx.Params())
+}
+
+// subdomainAndPathAndMethodMatch same as `subdomainAndPathAndMethodExists`
+// but it fills the given "params" instead of the context's ones.
+func (s *routerState) subdomainAndPathAndMethodMatch(ctx context.Context, t *trie, method, path string, params *context.RequestParams) bool {
 	if method != "" && method != t.method {
 		return false
 	}
 
-	if h.hosts && t.subdomain != "" {
+	if s.hosts && t.subdomain != "" {
 		requestHost := ctx.Host()
 		if netutil.IsLoopbackSubdomain(requestHost) {
 			// this fixes a bug when listening on
@@ -336,20 +581,62 @@ func (h *routerHandler) subdomainAndPathAndMethodExists(ctx context.Context, t *
 		}
 	}
 
-	n := t.search(path, ctx.Params())
+	n := t.search(path, params)
 	return n != nil
 }
 
 // RouteExists reports whether a particular route exists
 // It will search from the current subdomain of context's host, if not inside the root domain.
 func (h *routerHandler) RouteExists(ctx context.Context, method, path string) bool {
+	return h.load().routeExists(ctx, method, path)
+}
+
+func (s *routerState) routeExists(ctx context.Context, method, path string) bool {
 	//这里直接通过所有的代表路由的各类树的根节点开始遍历
-	for i := range h.trees {
-		t := h.trees[i]
-		if h.subdomainAndPathAndMethodExists(ctx, t, method, path) {
+	for i := range s.trees {
+		t := s.trees[i]
+		if s.subdomainAndPathAndMethodExists(ctx, t, method, path) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// AllowedMethods returns the methods of the routes that match the "path"
+// and the current subdomain of context's host, in order of registration.
+// It's used to fill the "Allow" header of the 405 responses,
+// it can be useful for CORS and automatic OPTIONS responses too.
+//
+// The context's path parameters are not modified.
+func (h *routerHandler) AllowedMethods(ctx context.Context, path string) []string {
+	return h.load().allowedMethods(ctx, path)
+}
+
+func (s *routerState) allowedMethods(ctx context.Context, path string) []string {
+	var (
+		methods []string
+		params  context.RequestParams
+	)
+
+	for i := range s.trees {
+		t := s.trees[i]
+		if !s.subdomainAndPathAndMethodMatch(ctx, t, "", path, &params) {
+			continue
+		}
+
+		exists := false
+		for _, m := range methods {
+			if m == t.method {
+				exists = true
+				break
+			}
+		}
+
+		if !exists {
+			methods = append(methods, t.method)
+		}
+	}
+
+	return methods
+}
@@ -56,6 +56,28 @@ type Party interface {
 	// Use appends Handler(s) to the current Party's routes and child routes.
 	// If the current Party is the root, then it registers the middleware to all child Parties' routes too.
 	Use(middleware ...context.Handler)
+	// UseRouter registers handlers which are executed before the routing,
+	// on each request which matches the subdomain and the path prefix of this Party,
+	// even if no route matches it, in contrast to the `Use` ones which run after a route is matched.
+	// The routing happens when the last of them calls the `ctx.Next()`.
+	//
+	// See `APIBuilder#UseRouter` for the execution order.
+	UseRouter(handlers ...context.Handler)
+	// DecorateContext registers decorators of the Context of this Party's routes and its children's,
+	// so their handlers receive a Context with typed helpers, without affecting the unrelated routes.
+	//
+	// See `APIBuilder#DecorateContext` for more.
+	DecorateContext(decorators ...ContextDecorator)
+	// CORS registers the Cross-Origin Resource Sharing policy of this Party's routes and its children's,
+	// the preflight requests of the routes are answered automatically.
+	//
+	// See `APIBuilder#CORS` for more.
+	CORS(options CORSOptions) Party
+	// QoS sets the quality of service class of this Party's routes and its children's,
+	// under load the bulk requests are shed first and the critical ones proceed.
+	//
+	// See `APIBuilder#QoS` and `APIBuilder#EnableQoS` for more.
+	QoS(class QoSClass) Party
 
 	// Done appends to the very end, Handler(s) to the current Party's routes and child routes.
 	// The difference from .Use is that this/or these Handler(s) are being always running last.
@@ -252,6 +274,19 @@ type Party interface {
 	//
 	// Returns the GET *Route.
 	StaticWeb(requestPath string, systemPath string) *Route
+	// StaticFS registers a route which serves the files of the "fileSystem" under the "requestPath",
+	// the "fileSystem" can be an http.FileSystem, a fs.FS (i.e an embed.FS of the go:embed) or a system directory.
+	// The "options" can enable the gzip compression, the cache headers, the directory listing
+	// and the single page application fallback, see `StaticFSOptions`.
+	//
+	// Returns the GET *Route.
+	StaticFS(requestPath string, fileSystem interface{}, options ...StaticFSOptions) *Route
+	// SPA registers a single page application to this Party, it serves the GET and HEAD requests
+	// under the Party's path which do not match a route, through the "assetHandler"
+	// and it falls back to the Party's root path (index.html) for the client-side routes.
+	//
+	// See `APIBuilder#SPA` for more.
+	SPA(assetHandler context.Handler) *SPABuilder
 
 	// Layout overrides the parent template layout with a more specific layout for this Party.
 	// It returns the current Party.
@@ -268,4 +303,18 @@ type Party interface {
 	//
 	// Examples: https://github.com/kataras/iris/tree/master/_examples/view
 	Layout(tmplLayoutFile string) Party
+
+	// PathEscape overrides the global `EnablePathEscape` configuration field
+	// for this Party's routes and its children, it changes the result of the `ctx.Path()`.
+	// It returns the current Party.
+	//
+	// Note that the route matching itself still follows the global setting,
+	// use `ctx.RawPath()` to get the original, encoded, request path instead.
+	//
+	// Like the `Use`, it affects only the routes which are registered after its call.
+	PathEscape(enable bool) Party
+	// Buffered buffers the responses of this Party's routes and its children,
+	// see `Context#Buffered` for more.
+	// It returns the current Party.
+	Buffered(enable bool) Party
 }
@@ -0,0 +1,100 @@
+package router
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+)
+
+var errInvalidPushManifest = errors.New("push manifest: invalid format of '%s'")
+
+// PushManifest maps the request paths (or the routes' registered paths, i.e "/users/{id}")
+// to the targets that should be pushed along with them, see `LoadPushManifest`.
+type PushManifest map[string][]string
+
+// LoadPushManifest loads a push manifest from a JSON file,
+// the targets of each path can be a list, i.e {"/": ["/css/app.css", "/js/app.js"]}
+// or the object of the push_manifest.json format,
+// i.e {"/": {"/css/app.css": {"type": "style", "weight": 1}}}, which are sorted by their name.
+func LoadPushManifest(filename string) (PushManifest, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err = json.Unmarshal(b, &raw); err != nil {
+		return nil, errInvalidPushManifest.Format(filename)
+	}
+
+	m := make(PushManifest, len(raw))
+	for path, v := range raw {
+		var targets []string
+		if err = json.Unmarshal(v, &targets); err != nil {
+			var assets map[string]json.RawMessage
+			if err = json.Unmarshal(v, &assets); err != nil {
+				return nil, errInvalidPushManifest.Format(filename)
+			}
+
+			for target := range assets {
+				targets = append(targets, target)
+			}
+			sort.Strings(targets)
+		}
+
+		m[path] = targets
+	}
+
+	return m, nil
+}
+
+// Targets returns the targets of the request's path or, if missing, of its route's registered path.
+func (m PushManifest) Targets(ctx context.Context) []string {
+	if targets, ok := m[ctx.Path()]; ok {
+		return targets
+	}
+
+	if route := ctx.GetCurrentRoute(); route != nil {
+		return m[route.Tmpl().Src]
+	}
+
+	return nil
+}
+
+// Handler is the middleware which pushes the targets of the request, see `Context#PushTargets`.
+// The push errors are logged on the debug level.
+//
+// Usage:
+// manifest, err := router.LoadPushManifest("./push_manifest.json")
+// app.Use(manifest.Handler)
+func (m PushManifest) Handler(ctx context.Context) {
+	if targets := m.Targets(ctx); len(targets) > 0 {
+		if err := ctx.PushTargets(targets...); err != nil {
+			context.Logger(ctx).Debugf("push: %v", err)
+		}
+	}
+
+	ctx.Next()
+}
+
+// Push registers the "targets" to be pushed to the HTTP/2 clients before the route's handlers,
+// a target can be a path or a preload "Link" header value, i.e "</css/app.css>; rel=preload; as=style".
+// The clients which do not support the server push are served as usual.
+//
+// Returns the route itself.
+//
+// Usage:
+// app.Get("/", index).Push("/css/app.css", "</js/app.js>; rel=preload; as=script")
+func (r *Route) Push(targets ...string) *Route {
+	if len(targets) == 0 {
+		return r
+	}
+
+	push := PushManifest{r.tmpl.Src: targets}
+	// first of the begin handlers, so the targets are pushed before any body is written.
+	r.beginHandlers = append(context.Handlers{push.Handler}, r.beginHandlers...)
+	return r
+}
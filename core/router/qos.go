@@ -0,0 +1,299 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// QoSClass is the quality of service class of a route, see `APIBuilder#QoS` and `Route#SetQoS`.
+// Under load the requests of the lower classes are queued or shed first.
+type QoSClass uint8
+
+const (
+	// QoSNormal is the default class of the routes,
+	// its requests are queued under load, up to the `QoSOptions.QueueTimeout`.
+	QoSNormal QoSClass = iota
+	// QoSCritical requests always proceed, i.e the health checks and the payments.
+	QoSCritical
+	// QoSBulk requests are shed first under load, i.e the exports and the reports,
+	// they're queued only if the `QoSOptions.BulkQueueTimeout` is set
+	// and they're resumed after the queued normal ones.
+	QoSBulk
+)
+
+var qosClassNames = [...]string{QoSNormal: "normal", QoSCritical: "critical", QoSBulk: "bulk"}
+
+// String returns the name of the class, i.e "bulk".
+func (c QoSClass) String() string {
+	if int(c) < len(qosClassNames) {
+		return qosClassNames[c]
+	}
+	return "QoSClass(" + strconv.Itoa(int(c)) + ")"
+}
+
+// MarshalText implements the encoding.TextMarshaler, the classes are the keys of the JSON `QoSStats`.
+func (c QoSClass) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// QoSOptions are the options of the `APIBuilder#EnableQoS`.
+type QoSOptions struct {
+	// MaxInFlight is the number of the concurrently served requests after which the router is under load,
+	// the critical requests are always served, the rest are queued or shed until the in-flight requests drop below it.
+	MaxInFlight int
+	// MaxQueue is the maximum number of the queued requests, the rest are shed.
+	//
+	// Defaults to the `MaxInFlight`.
+	MaxQueue int
+	// QueueTimeout is the maximum time that a normal request waits in the queue before it's shed.
+	//
+	// Defaults to `DefaultQoSQueueTimeout`.
+	QueueTimeout time.Duration
+	// BulkQueueTimeout is the maximum time that a bulk request waits in the queue before it's shed.
+	//
+	// Defaults to 0, the bulk requests are shed immediately under load.
+	BulkQueueTimeout time.Duration
+	// RetryAfter is the "Retry-After" header's value of the shed requests.
+	//
+	// Defaults to one second.
+	RetryAfter time.Duration
+	// OnShed is fired instead of the route's handlers when a request is shed.
+	//
+	// Defaults to the "Retry-After" header and the 503 (Service Unavailable) status code.
+	OnShed func(ctx context.Context, class QoSClass)
+}
+
+// DefaultQoSQueueTimeout is the default `QoSOptions.QueueTimeout`.
+var DefaultQoSQueueTimeout = 2 * time.Second
+
+// QoSClassStats are the counters of a `QoSClass`.
+type QoSClassStats struct {
+	// Admitted is the number of the served requests, including the queued ones.
+	Admitted uint64 `json:"admitted"`
+	// Queued is the number of the requests which waited in the queue, served or shed.
+	Queued uint64 `json:"queued"`
+	// Shed is the number of the rejected requests.
+	Shed uint64 `json:"shed"`
+}
+
+// QoSStats is a snapshot of the metrics of a `QoSLimiter`.
+type QoSStats struct {
+	InFlight int                        `json:"inFlight"`
+	Waiting  int                        `json:"waiting"`
+	Classes  map[QoSClass]QoSClassStats `json:"classes"`
+}
+
+type qosWaiter struct {
+	ready chan struct{}
+}
+
+// QoSLimiter is the request prioritization layer of the router, see `APIBuilder#EnableQoS`.
+// 请求优先级队列: 负载过高时, 先排队或丢弃低优先级(bulk)的请求, critical 的请求总是会被处理
+type QoSLimiter struct {
+	mu      sync.Mutex
+	opts    QoSOptions
+	enabled bool
+
+	inFlight int
+	// waiting are the queued requests by class, only the normal and the bulk ones are queued.
+	waiting [len(qosClassNames)][]*qosWaiter
+	stats   [len(qosClassNames)]QoSClassStats
+}
+
+func (l *QoSLimiter) enable(opts QoSOptions) {
+	if opts.MaxQueue <= 0 {
+		opts.MaxQueue = opts.MaxInFlight
+	}
+	if opts.QueueTimeout <= 0 {
+		opts.QueueTimeout = DefaultQoSQueueTimeout
+	}
+	if opts.RetryAfter <= 0 {
+		opts.RetryAfter = time.Second
+	}
+	if opts.OnShed == nil {
+		retryAfter := strconv.FormatInt(int64((opts.RetryAfter+time.Second-1)/time.Second), 10)
+		opts.OnShed = func(ctx context.Context, class QoSClass) {
+			ctx.Header("Retry-After", retryAfter)
+			ctx.StatusCode(http.StatusServiceUnavailable)
+		}
+	}
+
+	l.mu.Lock()
+	l.opts = opts
+	l.enabled = opts.MaxInFlight > 0
+	l.mu.Unlock()
+}
+
+// Enabled reports whether the limiter is enabled through the `APIBuilder#EnableQoS`.
+func (l *QoSLimiter) Enabled() bool {
+	l.mu.Lock()
+	enabled := l.enabled
+	l.mu.Unlock()
+	return enabled
+}
+
+func (l *QoSLimiter) queued() int {
+	n := 0
+	for _, w := range l.waiting {
+		n += len(w)
+	}
+	return n
+}
+
+// acquire reports whether a request of the "class" can be served, it waits in the queue if needed,
+// an admitted request must call the `release` after its handlers.
+func (l *QoSLimiter) acquire(ctx context.Context, class QoSClass) bool {
+	if int(class) >= len(qosClassNames) {
+		class = QoSNormal
+	}
+
+	l.mu.Lock()
+	stats := &l.stats[class]
+	if class == QoSCritical || l.inFlight < l.opts.MaxInFlight {
+		l.inFlight++
+		stats.Admitted++
+		l.mu.Unlock()
+		return true
+	}
+
+	timeout := l.opts.QueueTimeout
+	if class == QoSBulk {
+		timeout = l.opts.BulkQueueTimeout
+	}
+
+	if timeout <= 0 || l.queued() >= l.opts.MaxQueue {
+		stats.Shed++
+		l.mu.Unlock()
+		return false
+	}
+
+	w := &qosWaiter{ready: make(chan struct{})}
+	l.waiting[class] = append(l.waiting[class], w)
+	stats.Queued++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-w.ready:
+		return true
+	case <-timer.C:
+	case <-ctx.Request().Context().Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, queued := range l.waiting[class] {
+		if queued == w {
+			l.waiting[class] = append(l.waiting[class][:i], l.waiting[class][i+1:]...)
+			stats.Shed++
+			return false
+		}
+	}
+
+	// resumed by a release while it was timed out.
+	return true
+}
+
+// release frees the slot of a served request and resumes the queued ones, the normal ones first.
+func (l *QoSLimiter) release() {
+	l.mu.Lock()
+	l.inFlight--
+	for _, class := range [...]QoSClass{QoSNormal, QoSBulk} {
+		for l.inFlight < l.opts.MaxInFlight && len(l.waiting[class]) > 0 {
+			w := l.waiting[class][0]
+			l.waiting[class] = l.waiting[class][1:]
+			l.inFlight++
+			l.stats[class].Admitted++
+			close(w.ready)
+		}
+	}
+	l.mu.Unlock()
+}
+
+// Stats returns a snapshot of the limiter's metrics.
+func (l *QoSLimiter) Stats() QoSStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := QoSStats{
+		InFlight: l.inFlight,
+		Waiting:  l.queued(),
+		Classes:  make(map[QoSClass]QoSClassStats, len(l.stats)),
+	}
+	for class, s := range l.stats {
+		stats.Classes[QoSClass(class)] = s
+	}
+
+	return stats
+}
+
+// StatsHandler returns a handler which renders the limiter's metrics as JSON,
+// i.e `app.Get("/debug/qos", qos.StatsHandler()).SetQoS(router.QoSCritical)`.
+func (l *QoSLimiter) StatsHandler() context.Handler {
+	return func(ctx context.Context) {
+		ctx.JSON(l.Stats())
+	}
+}
+
+// serve runs the "handlers" of a route of the "class", if the request is admitted, otherwise it's shed.
+func (l *QoSLimiter) serve(ctx context.Context, class QoSClass, handlers context.Handlers) {
+	if !l.acquire(ctx, class) {
+		l.opts.OnShed(ctx, class)
+		return
+	}
+
+	defer l.release()
+	ctx.Do(handlers)
+}
+
+// QoS sets the quality of service class of this Party's future routes and its children's,
+// it has effect only if the `EnableQoS` is called.
+//
+// Usage:
+//
+//	app.EnableQoS(router.QoSOptions{MaxInFlight: 200, BulkQueueTimeout: time.Second})
+//	app.Get("/health", health).SetQoS(router.QoSCritical)
+//	reports := app.Party("/reports")
+//	reports.QoS(router.QoSBulk)
+//	reports.Get("/export", export)
+func (api *APIBuilder) QoS(class QoSClass) Party {
+	api.qosClass = class
+	return api
+}
+
+// EnableQoS enables the request prioritization of the router, the requests are classified by their routes' `QoSClass`,
+// under load (the `QoSOptions.MaxInFlight`) the bulk requests are shed first, the normal ones are queued
+// and the critical ones proceed. It should be called before the `Application#Build`.
+//
+// Returns the limiter, its `Stats` are the shed and the queued counters per class.
+func (api *APIBuilder) EnableQoS(options QoSOptions) *QoSLimiter {
+	api.qos.enable(options)
+	return api.qos
+}
+
+// getQoS returns the limiter of the router and the classes of the routes by name, nil if it's not enabled.
+func (api *APIBuilder) getQoS() (*QoSLimiter, map[string]QoSClass) {
+	if !api.qos.Enabled() {
+		return nil, nil
+	}
+
+	classes := make(map[string]QoSClass)
+	for _, r := range api.GetRoutes() {
+		if r.qosClass != QoSNormal {
+			classes[r.Name] = r.qosClass
+		}
+	}
+
+	return api.qos, classes
+}
+
+// qosProvider is implemented by the `RoutesProvider`s which support the `EnableQoS`, i.e the `APIBuilder`.
+type qosProvider interface {
+	getQoS() (*QoSLimiter, map[string]QoSClass)
+}
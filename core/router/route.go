@@ -2,6 +2,7 @@ package router
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/kataras/iris/context"
@@ -48,8 +49,45 @@ type Route struct {
 	// used by Application to validate param values of a Route based on its name.
 	// todo 这个是用于动态路径，不影响大致逻辑
 	FormattedPath string `json:"formattedPath"`
+
+	// guards are evaluated by the router before the Handlers, see `Guard`.
+	guards []RouteGuard
+
+	// stats are the persistent route's statistics, they're shared with the router's trie node.
+	stats *routeStats
+
+	// predicates are evaluated by the router after the path match,
+	// routes with the same method and path can be dispatched based on them, see `Headers`.
+	predicates []routePredicate
+
+	// the position of the main handlers inside the `Handlers`,
+	// and the execution rules of its Party, see `SwapHandlers`.
+	mainIndex, mainLen int
+	execRules          ExecutionRules
+	beginLen, doneLen  int
+	// decorators are the context decorators of its Party, see `APIBuilder#DecorateContext`.
+	decorators *contextDecorators
+	// cors is the CORS policy of its Party, see `APIBuilder#CORS`.
+	cors *corsPolicy
+	// qosClass is the quality of service class of the route, see `SetQoS`.
+	qosClass QoSClass
+	// description and deprecation document the route, see `SetDescription` and `Deprecated`.
+	description string
+	deprecation *context.RouteDeprecation
 }
 
+// RouteGuard is a declarative allow rule of a route,
+// it's evaluated by the router before running the route's handlers.
+// If it returns false then the handlers are not executed at all and
+// the "status" is sent to the client instead, if "status" is zero then the 403 (Forbidden) is sent.
+//
+// The route's handlers include the middleware of its Party, i.e an authentication one,
+// so a guard can't read the values which are set by them, i.e the claims or the session,
+// use a handler after that middleware for such checks instead.
+//
+// See `Route#Guard`.
+type RouteGuard func(ctx context.Context) (ok bool, status int)
+
 // NewRoute returns a new route based on its method,
 // subdomain, the path (unparsed or original),
 // handlers and the macro container which all routes should share.
@@ -69,9 +107,11 @@ func NewRoute(method, subdomain, unparsedPath, mainHandlerName string,
 	path := convertMacroTmplToNodePath(tmpl)
 	// prepend the macro handler to the route, now,
 	// right before the register to the tree, so APIBuilder#UseGlobal will work as expected.
+	macroHandlers := 0
 	if handler.CanMakeHandler(tmpl) {
 		macroEvaluatorHandler := handler.MakeHandler(tmpl)
 		handlers = append(context.Handlers{macroEvaluatorHandler}, handlers...)
+		macroHandlers = 1
 	}
 	//************处理好path，包括原始、macro等************
 	path = cleanPath(path) // maybe unnecessary here but who cares in this moment
@@ -90,6 +130,9 @@ func NewRoute(method, subdomain, unparsedPath, mainHandlerName string,
 		Handlers:        handlers,
 		MainHandlerName: mainHandlerName,
 		FormattedPath:   formattedPath,
+		stats:           newRouteStats(),
+		mainLen:         len(handlers) - macroHandlers,
+		mainIndex:       macroHandlers,
 	}
 	return route, nil
 }
@@ -154,6 +197,162 @@ func (r *Route) RestoreStatus() bool {
 	return r.ChangeMethod(r.methodBckp)
 }
 
+// Guard registers one or more allow rules for this route,
+// they're evaluated, by order, by the router before running the route's handlers,
+// the first guard which returns false rejects the request with its status code.
+// Useful for fast declarative rejection (role checks, feature flags)
+// without polluting the handlers chain.
+// Note that they run before the `Use` middleware too, see `RouteGuard`.
+//
+// The guards are composed into the route's tree node on `Application#Build`,
+// a call of `RefreshRouter` is required if they're added at serve-time.
+//
+// Returns the route itself.
+//
+// Usage:
+// app.Get("/admin", adminHandler).Guard(func(ctx iris.Context) (bool, int) {
+// 	return isAdmin(ctx), iris.StatusForbidden
+// })
+func (r *Route) Guard(guards ...RouteGuard) *Route {
+	r.guards = append(r.guards, guards...)
+	return r
+}
+
+// SetQoS sets the quality of service class of the route, it overrides the one of its Party,
+// see `APIBuilder#EnableQoS`.
+//
+// Returns the route itself.
+func (r *Route) SetQoS(class QoSClass) *Route {
+	r.qosClass = class
+	return r
+}
+
+// QoSClass returns the quality of service class of the route, see `SetQoS`.
+func (r *Route) QoSClass() QoSClass {
+	return r.qosClass
+}
+
+// buildGuard composes the route's guards into a single one, nil if no guards registered.
+func (r *Route) buildGuard() RouteGuard {
+	switch len(r.guards) {
+	case 0:
+		return nil
+	case 1:
+		return r.guards[0]
+	}
+
+	guards := make([]RouteGuard, len(r.guards))
+	copy(guards, r.guards)
+	return func(ctx context.Context) (bool, int) {
+		for _, g := range guards {
+			if ok, status := g(ctx); !ok {
+				return false, status
+			}
+		}
+		return true, 0
+	}
+}
+
+// routePredicate is a request match filter of a route, see `Route#Headers` and `Route#ContentType`.
+type routePredicate struct {
+	// key describes the predicate, it's used to separate the routes with the same path.
+	key   string
+	match func(ctx context.Context) bool
+}
+
+// Headers adds request header predicates to the route, by key-value pairs,
+// they're evaluated by the router after the path match and before the handlers,
+// so more than one route can be registered with the same method and path
+// and the request is dispatched to the first one that its predicates match.
+// A route without predicates, if any, is used when none of the others match.
+// An empty value matches any non-empty header value.
+//
+// Returns the route itself.
+//
+// Usage:
+// app.Get("/things", thingsV2).Headers("X-API-Version", "2")
+// app.Get("/things", things)
+func (r *Route) Headers(keyValues ...string) *Route {
+	for i := 0; i < len(keyValues); i += 2 {
+		key, value := keyValues[i], ""
+		if i+1 < len(keyValues) {
+			value = keyValues[i+1]
+		}
+
+		r.predicates = append(r.predicates, routePredicate{
+			key: http.CanonicalHeaderKey(key) + "=" + value,
+			match: func(ctx context.Context) bool {
+				got := ctx.GetHeader(key)
+				if value == "" {
+					return got != ""
+				}
+				return got == value
+			},
+		})
+	}
+
+	return r
+}
+
+// ContentType adds a request "Content-Type" predicate to the route,
+// the request's media type, without its parameters, should be one of the "mimes".
+// See `Headers` for more.
+//
+// Returns the route itself.
+//
+// Usage:
+// app.Post("/things", createFromJSON).ContentType(context.ContentJSONHeaderValue)
+// app.Post("/things", createFromForm).ContentType("multipart/form-data")
+// 根据请求的Content-Type分发到不同的handler
+func (r *Route) ContentType(mimes ...string) *Route {
+	r.predicates = append(r.predicates, routePredicate{
+		key: "Content-Type=" + strings.Join(mimes, ","),
+		match: func(ctx context.Context) bool {
+			contentType := ctx.GetContentTypeRequested()
+			if idx := strings.IndexByte(contentType, ';'); idx >= 0 {
+				contentType = contentType[0:idx]
+			}
+			contentType = strings.TrimSpace(contentType)
+
+			for _, mime := range mimes {
+				if strings.EqualFold(contentType, mime) {
+					return true
+				}
+			}
+			return false
+		},
+	})
+
+	return r
+}
+
+// predicatesKey returns the description of the route's predicates, empty if none.
+func (r *Route) predicatesKey() string {
+	keys := make([]string, len(r.predicates))
+	for i, p := range r.predicates {
+		keys[i] = p.key
+	}
+	return strings.Join(keys, ";")
+}
+
+// buildPredicate composes the route's predicates into a single one, nil if no predicates registered.
+func (r *Route) buildPredicate() func(ctx context.Context) bool {
+	if len(r.predicates) == 0 {
+		return nil
+	}
+
+	predicates := make([]routePredicate, len(r.predicates))
+	copy(predicates, r.predicates)
+	return func(ctx context.Context) bool {
+		for _, p := range predicates {
+			if !p.match(ctx) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
 // BuildHandlers is executed automatically by the router handler
 // at the `Application#Build` state. Do not call it manually, unless
 // you were defined your own request mux handler.
@@ -163,6 +362,7 @@ func (r *Route) RestoreStatus() bool {
 func (r *Route) BuildHandlers() {
 	if len(r.beginHandlers) > 0 {
 		r.Handlers = append(r.beginHandlers, r.Handlers...)
+		r.mainIndex += len(r.beginHandlers)
 		r.beginHandlers = r.beginHandlers[0:0]
 	}
 
@@ -172,6 +372,32 @@ func (r *Route) BuildHandlers() {
 	} // note: no mutex needed, this should be called in-sync when server is not running of course.
 }
 
+// UseBeforeMain inserts the "handlers" right before the main handlers of the route,
+// after the middleware of its Party, i.e a per-route validation middleware which should run after the authentication one.
+// The handlers receive the decorated context of its Party, if any.
+//
+// Returns the route itself.
+func (r *Route) UseBeforeMain(handlers ...context.Handler) *Route {
+	if len(handlers) == 0 {
+		return r
+	}
+
+	handlers = r.decorators.wrap(handlers)
+	idx := r.mainIndex
+	if idx > len(r.Handlers) {
+		idx = len(r.Handlers)
+	}
+
+	newHandlers := make(context.Handlers, 0, len(r.Handlers)+len(handlers))
+	newHandlers = append(newHandlers, r.Handlers[:idx]...)
+	newHandlers = append(newHandlers, handlers...)
+	newHandlers = append(newHandlers, r.Handlers[idx:]...)
+
+	r.Handlers = newHandlers
+	r.mainIndex = idx + len(handlers)
+	return r
+}
+
 // String returns the form of METHOD, SUBDOMAIN, TMPL PATH.
 //路由的名称以 方法名、子域、r.Tmpl().Src
 func (r Route) String() string {
@@ -296,6 +522,9 @@ func (r Route) Trace() string {
 		printfmt += fmt.Sprintf(" %s", r.Subdomain)
 	}
 	printfmt += fmt.Sprintf(" %s ", r.Tmpl().Src)
+	if r.deprecation != nil {
+		printfmt += "(deprecated) "
+	}
 
 	if l := r.RegisteredHandlersLen(); l > 1 {
 		printfmt += fmt.Sprintf("-> %s() and %d more", r.MainHandlerName, l-1)
@@ -342,3 +571,15 @@ func (rd routeReadOnlyWrapper) Tmpl() macro.Template {
 func (rd routeReadOnlyWrapper) MainHandlerName() string {
 	return rd.Route.MainHandlerName
 }
+
+func (rd routeReadOnlyWrapper) Stats() context.RouteStats {
+	return rd.Route.stats.snapshot()
+}
+
+func (rd routeReadOnlyWrapper) Description() string {
+	return rd.Route.description
+}
+
+func (rd routeReadOnlyWrapper) Deprecation() *context.RouteDeprecation {
+	return rd.Route.Deprecation()
+}
@@ -0,0 +1,117 @@
+package router
+
+import (
+	"strings"
+)
+
+// RouteConflictReason is the kind of a `RouteConflictError`.
+type RouteConflictReason string
+
+const (
+	// RouteConflictDuplicate is the reason of a route which is registered again, with the same method, subdomain,
+	// path and predicates, but with a different main handler, the first registered one is served.
+	RouteConflictDuplicate RouteConflictReason = "duplicate"
+	// RouteConflictAmbiguous is the reason of routes with the same method, subdomain and predicates
+	// whose paths differ only by their parameters' names or types, i.e /user/{id:uint64} and /user/{name:string},
+	// they're matched by the same node of the router so only one of them can be served.
+	RouteConflictAmbiguous RouteConflictReason = "ambiguous"
+	// RouteConflictShadowed is the reason of a route of a specific subdomain which is registered after
+	// a route of the wildcard subdomain with the same method, path and predicates,
+	// the wildcard one would shadow it by the registration order.
+	// The router matches the specific subdomains first so it's served, it's a warning, not a build error.
+	RouteConflictShadowed RouteConflictReason = "shadowed"
+)
+
+// RouteConflictError is reported by the router's build for a route which can not be served
+// because of an already registered one, see `APIBuilder#RouteConflicts`.
+// 路由冲突: 重复注册或者只有参数名称和类型不同的路由
+type RouteConflictError struct {
+	Reason RouteConflictReason
+	// Route is the conflicting route and Existing is the one which was registered before it.
+	Route    *Route
+	Existing *Route
+}
+
+// Error returns the reason and the traces of both routes.
+func (e *RouteConflictError) Error() string {
+	return "route conflict (" + string(e.Reason) + "): " + e.Route.Trace() + " conflicts with " + e.Existing.Trace()
+}
+
+// nodePathKey returns the path of the router's node of the "path" route path,
+// without the parameters' names, i.e "/user/:" of the "/user/:id".
+func nodePathKey(path string) string {
+	segments := strings.Split(path, pathSep)
+	for i, s := range segments {
+		if s == "" {
+			continue
+		}
+
+		switch s[0] {
+		case ParamStart[0]:
+			segments[i] = ParamStart
+		case WildcardParamStart[0]:
+			segments[i] = WildcardParamStart
+		}
+	}
+
+	return strings.Join(segments, pathSep)
+}
+
+// routeConflicts returns the conflicts of the "routes", by registration order,
+// the duplicates of the same main handler, i.e through the `AllowMethods`, are not conflicts.
+func routeConflicts(routes []*Route) []*RouteConflictError {
+	var conflicts []*RouteConflictError
+
+	existing := make(map[string]*Route, len(routes))
+	// the routes of the wildcard subdomain, without the subdomain in their keys.
+	wildcards := make(map[string]*Route)
+	for _, r := range routes {
+		pathKey := r.Method + "\x00" + nodePathKey(r.Path) + "\x00" + r.predicatesKey()
+		if r.Subdomain == SubdomainWildcardIndicator {
+			if _, ok := wildcards[pathKey]; !ok {
+				wildcards[pathKey] = r
+			}
+		} else if w, ok := wildcards[pathKey]; ok && r.Subdomain != "" {
+			conflicts = append(conflicts, &RouteConflictError{Reason: RouteConflictShadowed, Route: r, Existing: w})
+		}
+
+		key := r.Subdomain + "\x00" + pathKey
+		e, ok := existing[key]
+		if !ok {
+			existing[key] = r
+			continue
+		}
+
+		if e.Tmpl().Src != r.Tmpl().Src {
+			conflicts = append(conflicts, &RouteConflictError{Reason: RouteConflictAmbiguous, Route: r, Existing: e})
+		} else if e.MainHandlerName != r.MainHandlerName {
+			conflicts = append(conflicts, &RouteConflictError{Reason: RouteConflictDuplicate, Route: r, Existing: e})
+		}
+	}
+
+	return conflicts
+}
+
+// RouteConflicts returns the conflicting registrations of the routes, by registration order,
+// they're reported by the `Application#Build` too.
+//
+// The conflicts are the routes which can not be served because of an already registered one:
+// the routes registered again with a different main handler and the routes whose paths
+// differ only by their parameters, see `RouteConflictReason`.
+// The `RouteConflictShadowed` ones are served and they're logged as warnings by the build.
+// The static paths and the parameters of the same path prefix are not conflicts, i.e /user/me and /user/{id},
+// the static ones are matched first.
+func (api *APIBuilder) RouteConflicts() []*RouteConflictError {
+	return routeConflicts(api.routes.list())
+}
+
+// getRegisteredRoutes returns all the registered routes, even the duplicates.
+func (api *APIBuilder) getRegisteredRoutes() []*Route {
+	return api.routes.list()
+}
+
+// registeredRoutesProvider is implemented by the `RoutesProvider`s which keep the duplicate registrations,
+// i.e the `APIBuilder`, the router's build reports their conflicts.
+type registeredRoutesProvider interface {
+	getRegisteredRoutes() []*Route
+}
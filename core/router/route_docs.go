@@ -0,0 +1,64 @@
+package router
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// SetDescription sets the documentation string of the route, i.e for an API listing or an OpenAPI generator,
+// see `context#RouteReadOnly.Description`.
+//
+// Returns the route itself.
+func (r *Route) SetDescription(description string) *Route {
+	r.description = description
+	return r
+}
+
+// Description returns the documentation string of the route, see `SetDescription`.
+func (r *Route) Description() string {
+	return r.description
+}
+
+// Deprecated marks the route as deprecated since the "since" time (can be zero) in favor of the "alternative",
+// the URL or the path of its successor (can be empty). Its responses send the "Deprecation" header,
+// "@" and the unix time of the "since" or "true", and the `Link: <alternative>; rel="successor-version"` header,
+// the route is flagged in the routes' listing (see `StatsHandler`) too.
+// It should be called before the `Application#Build`.
+//
+// Returns the route itself.
+//
+// Usage:
+//
+//	app.Get("/v1/users", listUsersV1).Deprecated(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), "/v2/users")
+func (r *Route) Deprecated(since time.Time, alternative string) *Route {
+	if r.deprecation == nil {
+		// first of all, so the rejected requests, i.e by an authentication middleware, send the headers too.
+		r.beginHandlers = append(context.Handlers{r.deprecationHandler}, r.beginHandlers...)
+	}
+
+	r.deprecation = &context.RouteDeprecation{Since: since, Alternative: alternative}
+	return r
+}
+
+// Deprecation returns the deprecation of the route, nil if it's not deprecated, see `Deprecated`.
+func (r *Route) Deprecation() *context.RouteDeprecation {
+	return r.deprecation
+}
+
+func (r *Route) deprecationHandler(ctx context.Context) {
+	if d := r.deprecation; d != nil {
+		value := "true"
+		if !d.Since.IsZero() {
+			value = "@" + strconv.FormatInt(d.Since.Unix(), 10)
+		}
+		ctx.Header("Deprecation", value)
+
+		if d.Alternative != "" {
+			ctx.ResponseWriter().Header().Add("Link", "<"+d.Alternative+`>; rel="successor-version"`)
+		}
+	}
+
+	ctx.Next()
+}
@@ -0,0 +1,105 @@
+package router
+
+import (
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+	"github.com/kataras/iris/macro/handler"
+)
+
+var errSetNoHandlers = errors.New("set handlers: missing handlers for route '%s'")
+
+// SetHandlers replaces all the handlers of the route, unlike the `SwapHandlers`
+// the middleware of its Party and the global ones (`UseGlobal`, `DoneGlobal`) are not kept,
+// the "handlers" are the complete chain of the route, only its parameters' evaluator runs before them.
+// A call of `RefreshRouter` is required after this type of change in order to change to be really applied.
+// 替换路由的全部处理器(不保留Party以及全局的中间件), 调用RefreshRouter后生效
+func (r *Route) SetHandlers(handlers ...context.Handler) error {
+	if len(handlers) == 0 {
+		return errSetNoHandlers.Format(r.Name)
+	}
+
+	newHandlers := make(context.Handlers, 0, len(handlers)+1)
+	if handler.CanMakeHandler(r.tmpl) {
+		newHandlers = append(newHandlers, handler.MakeHandler(r.tmpl))
+	}
+	r.mainIndex = len(newHandlers)
+	newHandlers = append(newHandlers, handlers...)
+
+	r.Handlers = newHandlers
+	r.mainLen = len(handlers)
+	r.MainHandlerName = context.HandlerName(handlers[0])
+	r.beginHandlers, r.doneHandlers = nil, nil
+	r.beginLen, r.doneLen = 0, 0
+	r.execRules = ExecutionRules{}
+	r.decorators = nil
+	return nil
+}
+
+// remove removes the routes of the "routeName", even the duplicates, and returns the first one, if any.
+// The routes are copied, so the readers of the previous ones are not affected.
+func (r *repository) remove(routeName string) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var removed *Route
+	routes := make([]*Route, 0, len(r.routes))
+	for _, route := range r.routes {
+		if route.Name != routeName {
+			routes = append(routes, route)
+			continue
+		}
+
+		if removed == nil {
+			removed = route
+		}
+	}
+
+	r.routes = routes
+	return removed
+}
+
+// RemoveRoute removes the "routeName" route, it returns the removed route or nil if it's not registered.
+// A call of `RefreshRouter` (or of the `Router#UnregisterRoute`) is required after this type of change
+// in order to change to be really applied, see the `Application#RemoveRoute` too.
+func (api *APIBuilder) RemoveRoute(routeName string) *Route {
+	return api.routes.remove(routeName)
+}
+
+// UnregisterRoute removes the "r" route, i.e after an `APIBuilder#RemoveRoute`, from the running router
+// without a rebuild, the rest routes are not affected.
+// It reports whether the route was found in the built router.
+func (router *Router) UnregisterRoute(r *Route) bool {
+	h, ok := router.requestHandler.(*routerHandler)
+	if !ok {
+		return false
+	}
+
+	return h.removeRoute(r)
+}
+
+// removeRoute removes the "r" route from a copy of its trie which replaces the current one,
+// the in-flight requests are served by the previous state, it reports whether the route was found.
+func (h *routerHandler) removeRoute(r *Route) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.load()
+	for i, t := range s.trees {
+		if t.method != r.Method || t.subdomain != r.Subdomain {
+			continue
+		}
+
+		t = t.clone()
+		if !t.remove(r.Path, r.Name) {
+			return false
+		}
+
+		newState := *s
+		newState.trees = append([]*trie(nil), s.trees...)
+		newState.trees[i] = t
+		h.state.Store(&newState)
+		return true
+	}
+
+	return false
+}
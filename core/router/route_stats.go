@@ -0,0 +1,98 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// routeStats keeps the persistent, per-route, counters,
+// they're updated lock-free by the router on each served request.
+// 每个路由的统计信息(请求次数、错误次数、平均耗时、最后一次错误)
+type routeStats struct {
+	// keep the 64-bit fields first, they're accessed atomically.
+	hits       uint64
+	errors     uint64
+	totalNanos int64
+
+	lastError atomic.Value // *routeLastError
+}
+
+type routeLastError struct {
+	message string
+	time    time.Time
+}
+
+func newRouteStats() *routeStats {
+	return new(routeStats)
+}
+
+// record updates the counters based on the response status code
+// and the time spent on the route's handlers.
+func (s *routeStats) record(ctx context.Context, elapsed time.Duration) {
+	atomic.AddUint64(&s.hits, 1)
+	atomic.AddInt64(&s.totalNanos, int64(elapsed))
+
+	if statusCode := ctx.GetStatusCode(); context.StatusCodeNotSuccessful(statusCode) {
+		atomic.AddUint64(&s.errors, 1)
+		s.lastError.Store(&routeLastError{
+			message: strconv.Itoa(statusCode) + " " + http.StatusText(statusCode),
+			time:    time.Now(),
+		})
+	}
+}
+
+// snapshot returns a copy of the current counters.
+func (s *routeStats) snapshot() (stats context.RouteStats) {
+	if s == nil {
+		return
+	}
+
+	stats.Hits = atomic.LoadUint64(&s.hits)
+	stats.Errors = atomic.LoadUint64(&s.errors)
+	if stats.Hits > 0 {
+		stats.MeanLatency = time.Duration(atomic.LoadInt64(&s.totalNanos) / int64(stats.Hits))
+	}
+
+	if lastErr, ok := s.lastError.Load().(*routeLastError); ok {
+		stats.LastError = lastErr.message
+		stats.LastErrorTime = lastErr.time
+	}
+
+	return
+}
+
+// StatsHandler returns a handler which renders the statistics of
+// all the registered routes as JSON, it can be registered to a debug endpoint,
+// i.e `app.Get("/debug/routes", router.StatsHandler())`.
+func StatsHandler() context.Handler {
+	type routeStatsEntry struct {
+		Name   string `json:"name"`
+		Method string `json:"method"`
+		Path   string `json:"path"`
+		// Description and Deprecation document the route, see `Route#SetDescription` and `Route#Deprecated`.
+		Description string                    `json:"description,omitempty"`
+		Deprecation *context.RouteDeprecation `json:"deprecation,omitempty"`
+		context.RouteStats
+	}
+
+	return func(ctx context.Context) {
+		routes := ctx.Application().GetRoutesReadOnly()
+		entries := make([]routeStatsEntry, 0, len(routes))
+		for _, r := range routes {
+			entries = append(entries, routeStatsEntry{
+				Name:        r.Name(),
+				Method:      r.Method(),
+				Path:        r.Path(),
+				Description: r.Description(),
+				Deprecation: r.Deprecation(),
+				RouteStats:  r.Stats(),
+			})
+		}
+
+		ctx.JSON(entries)
+	}
+}
@@ -0,0 +1,64 @@
+package router
+
+import (
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+)
+
+var errSwapNoMainHandlers = errors.New("swap handlers: missing handlers for route '%s'")
+
+// SwapHandlers replaces the main handlers of the route, the ones that were passed on its registration,
+// the begin (`Use`, `UseGlobal`) and done (`Done`, `DoneGlobal`) handlers and the execution rules of its Party
+// are kept as they're. The new handlers receive the decorated context of its Party, if any.
+//
+// It changes the `Handlers` field only, use the `Router#SwapRouteHandlers` (or the `Application#SwapHandlers`)
+// to apply the change to a running router without a rebuild.
+func (r *Route) SwapHandlers(handlers ...context.Handler) error {
+	if len(handlers) == 0 {
+		return errSwapNoMainHandlers.Format(r.Name)
+	}
+
+	main := joinHandlers(handlers, nil)
+	if rules := r.execRules; rules.Begin.Force || rules.Main.Force || rules.Done.Force {
+		// the begin and done handlers are already wrapped,
+		// the placeholders are used to apply the same rules to the new main handlers only.
+		noop := func(context.Context) {}
+		begin, done := make(context.Handlers, r.beginLen), make(context.Handlers, r.doneLen)
+		for i := range begin {
+			begin[i] = noop
+		}
+		for i := range done {
+			done[i] = noop
+		}
+		applyExecutionRules(rules, &begin, &done, &main)
+	}
+	main = r.decorators.wrap(main)
+
+	end := r.mainIndex + r.mainLen
+	if end > len(r.Handlers) {
+		// i.e the `Handlers` were modified manually.
+		end = len(r.Handlers)
+	}
+
+	newHandlers := make(context.Handlers, 0, len(r.Handlers)-r.mainLen+len(main))
+	newHandlers = append(newHandlers, r.Handlers[:r.mainIndex]...)
+	newHandlers = append(newHandlers, main...)
+	newHandlers = append(newHandlers, r.Handlers[end:]...)
+
+	r.Handlers = newHandlers
+	r.mainLen = len(main)
+	r.MainHandlerName = context.HandlerName(handlers[0])
+	return nil
+}
+
+// SwapRouteHandlers applies the current `Handlers` of the "r" route, i.e after a `Route#SwapHandlers`,
+// to the running router atomically, without a rebuild, the in-flight requests are not affected.
+// It reports whether the route was found in the built router.
+func (router *Router) SwapRouteHandlers(r *Route) bool {
+	h, ok := router.requestHandler.(*routerHandler)
+	if !ok {
+		return false
+	}
+
+	return h.swapHandlers(r)
+}
@@ -79,6 +79,14 @@ func (router *Router) BuildRouter(cPool *context.Pool, requestHandler RequestHan
 	router.mu.Lock()
 	defer router.mu.Unlock()
 
+	if h, ok := requestHandler.(*routerHandler); ok && force && router.mainHandler != nil &&
+		router.requestHandler == RequestHandler(h) && router.cPool == cPool {
+		// a rebuild, i.e `RefreshRouter`, the router handler has replaced its state atomically already,
+		// the main handler is kept as it's, so the requests being served are not affected.
+		router.routesProvider = routesProvider
+		return nil
+	}
+
 	// store these for RefreshRouter's needs.
 	// force为true 就是表示强制更新里面的数据
 	if force {
@@ -106,8 +114,9 @@ func (router *Router) BuildRouter(cPool *context.Pool, requestHandler RequestHan
 	router.mainHandler = func(w http.ResponseWriter, r *http.Request) {
 		// todo context.Pool 的源码解析
 		ctx := cPool.Acquire(w, r)
+		// deferred, so a panic does not leave the request counted as in-flight, see `Pool#ActiveRequests`.
+		defer cPool.Release(ctx)
 		router.requestHandler.HandleRequest(ctx)
-		cPool.Release(ctx)
 	}
 
 	// 这里的wrapperFunc我的理解是在进行主要的mainHandler之前，先进行wrapperFunc的处理，然后(内部会有router.mainHandler的处理)
@@ -204,6 +213,12 @@ func (router *Router) RouteExists(ctx context.Context, method, path string) bool
 	return router.requestHandler.RouteExists(ctx, method, path)
 }
 
+// AllowedMethods returns the methods of the routes that match the "path"
+// and the current subdomain of context's host.
+func (router *Router) AllowedMethods(ctx context.Context, path string) []string {
+	return router.requestHandler.AllowedMethods(ctx, path)
+}
+
 type wrapper struct {
 	router      http.HandlerFunc // http.HandlerFunc to catch the CURRENT state of its .ServeHTTP on case of future change.
 	wrapperFunc func(http.ResponseWriter, *http.Request, http.HandlerFunc)
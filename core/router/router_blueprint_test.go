@@ -0,0 +1,81 @@
+package router_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
+	"github.com/kataras/iris/httptest"
+)
+
+func newBlogBlueprint() *router.Blueprint {
+	b := router.NewBlueprint("blog")
+	b.Use(func(ctx context.Context) {
+		ctx.Header("X-Blueprint", "blog")
+		ctx.Next()
+	})
+	b.Get("/", func(ctx context.Context) {
+		ctx.ViewPartial("blog/index.html", iris.Map{"Title": "Posts"})
+	}).Name = "index"
+	b.Get("/{slug}", func(ctx context.Context) {
+		if ctx.Params().Get("slug") == "missing" {
+			ctx.NotFound()
+			return
+		}
+		ctx.WriteString(ctx.Params().Get("slug"))
+	}).Name = "post"
+	b.OnErrorCode(iris.StatusNotFound, func(ctx context.Context) {
+		ctx.WriteString("post not found")
+	})
+	b.Template("index.html", `<h1>{{ .Title }}</h1>{{ template "blog/footer.html" }}`)
+	b.Template("footer.html", `<footer>blog</footer>`)
+	b.StaticFS("/assets", fstest.MapFS{"app.css": {Data: []byte("body{}")}})
+	return b
+}
+
+func TestMountBlueprint(t *testing.T) {
+	app := iris.New()
+	app.OnErrorCode(iris.StatusNotFound, func(ctx context.Context) {
+		ctx.WriteString("app not found")
+	})
+	app.Get("/", func(ctx context.Context) {
+		ctx.WriteString("home")
+	})
+	app.MountBlueprint("/blog", newBlogBlueprint())
+
+	e := httptest.New(t, app)
+
+	e.GET("/").Expect().Status(iris.StatusOK).Body().Equal("home")
+	e.GET("/blog").Expect().Status(iris.StatusOK).
+		Header("X-Blueprint").Equal("blog")
+	e.GET("/blog").Expect().Body().Equal("<h1>Posts</h1><footer>blog</footer>")
+	e.GET("/blog/hello").Expect().Status(iris.StatusOK).Body().Equal("hello")
+	e.GET("/blog/assets/app.css").Expect().Status(iris.StatusOK).Body().Equal("body{}")
+
+	// the error handlers of the blueprint are fired only under its mount path.
+	e.GET("/blog/missing").Expect().Status(iris.StatusNotFound).Body().Equal("post not found")
+	e.GET("/blog/assets/missing.css").Expect().Status(iris.StatusNotFound).Body().Equal("post not found")
+	e.GET("/blogs").Expect().Status(iris.StatusNotFound).Body().Equal("app not found")
+	e.GET("/missing").Expect().Status(iris.StatusNotFound).Body().Equal("app not found")
+
+	// the route names are namespaced.
+	if r := app.GetRoute("blog.post"); r == nil || r.Tmpl().Src != "/blog/{slug}" {
+		t.Fatalf("expected the 'blog.post' route of the '/blog/{slug}' path but got: %v", r)
+	}
+	if r := app.GetRoute("post"); r != nil {
+		t.Fatalf("expected no 'post' route but got: %v", r)
+	}
+}
+
+func TestMountBlueprintTemplateError(t *testing.T) {
+	app := iris.New()
+	b := router.NewBlueprint("broken")
+	b.Template("index.html", `{{ .Title `)
+	app.MountBlueprint("/broken", b)
+
+	if err := app.Build(); err == nil {
+		t.Fatalf("expected a build error of the invalid template")
+	}
+}
@@ -0,0 +1,79 @@
+package router_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestCORS(t *testing.T) {
+	app := iris.New()
+
+	api := app.Party("/api")
+	api.CORS(iris.CORSOptions{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"X-Total"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	})
+	api.Get("/users/{id:int}", func(ctx context.Context) {
+		ctx.WriteString("user")
+	})
+	api.Delete("/users/{id:int}", func(ctx context.Context) {
+		ctx.StatusCode(iris.StatusUnauthorized)
+	})
+
+	// a child overrides the parent's policy.
+	public := api.Party("/public")
+	public.CORS(iris.CORSOptions{})
+	public.Get("/", func(ctx context.Context) {
+		ctx.WriteString("public")
+	})
+
+	app.Get("/nocors", func(ctx context.Context) {
+		ctx.WriteString("nocors")
+	})
+
+	e := httptest.New(t, app)
+	origin := "https://app.example.com"
+
+	// preflight, answered by the router.
+	r := e.OPTIONS("/api/users/42").WithHeader("Origin", origin).
+		WithHeader("Access-Control-Request-Method", "DELETE").
+		WithHeader("Access-Control-Request-Headers", "authorization").Expect().Status(httptest.StatusNoContent)
+	r.Header("Access-Control-Allow-Origin").Equal(origin)
+	r.Header("Access-Control-Allow-Credentials").Equal("true")
+	r.Header("Access-Control-Allow-Methods").Equal("GET, DELETE")
+	r.Header("Access-Control-Allow-Headers").Equal("authorization")
+	r.Header("Access-Control-Max-Age").Equal("3600")
+
+	// disallowed origin, header and method.
+	e.OPTIONS("/api/users/42").WithHeader("Origin", "https://example.org").
+		WithHeader("Access-Control-Request-Method", "GET").Expect().Status(httptest.StatusForbidden).
+		Header("Access-Control-Allow-Origin").Empty()
+	e.OPTIONS("/api/users/42").WithHeader("Origin", origin).WithHeader("Access-Control-Request-Method", "GET").
+		WithHeader("Access-Control-Request-Headers", "X-Custom").Expect().Status(httptest.StatusForbidden)
+	e.OPTIONS("/api/users/42").WithHeader("Origin", origin).WithHeader("Access-Control-Request-Method", "PUT").
+		Expect().Status(httptest.StatusNotFound)
+
+	// actual requests, the headers are sent on the rejected ones too.
+	r = e.GET("/api/users/42").WithHeader("Origin", origin).Expect().Status(httptest.StatusOK)
+	r.Body().Equal("user")
+	r.Header("Access-Control-Allow-Origin").Equal(origin)
+	r.Header("Access-Control-Expose-Headers").Equal("X-Total")
+	e.DELETE("/api/users/42").WithHeader("Origin", origin).Expect().Status(httptest.StatusUnauthorized).
+		Header("Access-Control-Allow-Origin").Equal(origin)
+	e.GET("/api/users/42").WithHeader("Origin", "https://example.com").Expect().Status(httptest.StatusOK).
+		Header("Access-Control-Allow-Origin").Empty()
+
+	e.OPTIONS("/api/public").WithHeader("Origin", "https://example.org").
+		WithHeader("Access-Control-Request-Method", "GET").Expect().Status(httptest.StatusNoContent).
+		Header("Access-Control-Allow-Origin").Equal("*")
+
+	e.OPTIONS("/nocors").WithHeader("Origin", origin).
+		WithHeader("Access-Control-Request-Method", "GET").Expect().Status(httptest.StatusNotFound)
+}
@@ -0,0 +1,73 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+type adminContext struct {
+	context.Context
+}
+
+func (ctx *adminContext) Greet() string {
+	return "admin:" + ctx.Path()
+}
+
+type tenantContext struct {
+	*adminContext
+}
+
+func (ctx *tenantContext) Tenant() string {
+	return ctx.Params().Get("tenant")
+}
+
+func TestDecorateContext(t *testing.T) {
+	app := iris.New()
+
+	admin := app.Party("/admin")
+	admin.DecorateContext(func(ctx context.Context) context.Context {
+		return &adminContext{ctx}
+	})
+	admin.Use(func(ctx context.Context) {
+		// the child Parties' routes receive their own decorations on top of this one.
+		ctx.Header("X-Admin", ctx.(interface{ Greet() string }).Greet())
+		ctx.Values().Set("decorated", ctx)
+		ctx.Next()
+	})
+	admin.Get("/", func(ctx context.Context) {
+		actx := ctx.(*adminContext)
+		// decorated once per request.
+		if ctx.Values().Get("decorated") != ctx {
+			t.Fatalf("expected the same decorated context of the middleware")
+		}
+		ctx.WriteString(actx.Greet())
+	})
+
+	tenants := admin.Party("/{tenant}")
+	tenants.DecorateContext(func(ctx context.Context) context.Context {
+		return &tenantContext{ctx.(*adminContext)}
+	})
+	tenants.Get("/", func(ctx context.Context) {
+		tctx := ctx.(*tenantContext)
+		ctx.WriteString(tctx.Greet() + "|" + tctx.Tenant())
+	})
+
+	app.Get("/", func(ctx context.Context) {
+		if _, ok := ctx.(*adminContext); ok {
+			t.Fatalf("unrelated routes should not receive the decorated context")
+		}
+		ctx.WriteString("root")
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/admin").Expect().Status(iris.StatusOK).
+		Header("X-Admin").Equal("admin:/admin")
+	e.GET("/admin").Expect().Body().Equal("admin:/admin")
+	e.GET("/admin/acme").Expect().Status(iris.StatusOK).
+		Header("X-Admin").Equal("admin:/admin/acme")
+	e.GET("/admin/acme").Expect().Body().Equal("admin:/admin/acme|acme")
+	e.GET("/").Expect().Status(iris.StatusOK).Body().Equal("root")
+}
@@ -0,0 +1,52 @@
+// black-box testing
+package router_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
+
+	"github.com/kataras/iris/httptest"
+)
+
+func TestRouteDeprecated(t *testing.T) {
+	app := iris.New()
+	since := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	app.Get("/v1/users", func(ctx context.Context) {
+		ctx.WriteString("v1")
+	}).SetDescription("Lists the users.").Deprecated(since, "/v2/users").Name = "v1.users"
+	app.Get("/v2/users", func(ctx context.Context) {
+		ctx.WriteString("v2")
+	}).Name = "v2.users"
+	app.Get("/debug/routes", router.StatsHandler())
+
+	e := httptest.New(t, app)
+
+	r := e.GET("/v1/users").Expect().Status(iris.StatusOK)
+	r.Header("Deprecation").Equal("@1546300800")
+	r.Header("Link").Equal(`</v2/users>; rel="successor-version"`)
+	r.Body().Equal("v1")
+
+	r = e.GET("/v2/users").Expect().Status(iris.StatusOK)
+	r.Header("Deprecation").Empty()
+	r.Header("Link").Empty()
+
+	route := app.GetRouteReadOnly("v1.users")
+	if expected, got := "Lists the users.", route.Description(); expected != got {
+		t.Fatalf("expected description '%s' but got '%s'", expected, got)
+	}
+	if d := route.Deprecation(); d == nil || !d.Since.Equal(since) || d.Alternative != "/v2/users" {
+		t.Fatalf("expected the route to be deprecated but got %#v", d)
+	}
+	if app.GetRouteReadOnly("v2.users").Deprecation() != nil {
+		t.Fatalf("expected the v2 route to not be deprecated")
+	}
+
+	e.GET("/debug/routes").Expect().Status(iris.StatusOK).
+		JSON().Array().Element(0).Object().
+		ValueEqual("description", "Lists the users.").
+		Value("deprecation").Object().ValueEqual("alternative", "/v2/users")
+}
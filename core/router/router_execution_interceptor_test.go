@@ -0,0 +1,41 @@
+// black-box testing
+package router_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+
+	"github.com/kataras/iris/httptest"
+)
+
+func TestExecutionInterceptor(t *testing.T) {
+	app := iris.New()
+	app.UseExecutionInterceptor(func(ctx context.Context, next func()) {
+		ctx.WriteString("[")
+		next()
+		ctx.WriteString("]")
+	})
+
+	app.Use(func(ctx context.Context) {
+		ctx.WriteString("m")
+		ctx.Next()
+	})
+	app.Get("/", func(ctx context.Context) {
+		ctx.WriteString("h")
+	})
+
+	// another application in the same process should not be affected.
+	other := iris.New()
+	other.Use(func(ctx context.Context) {
+		ctx.WriteString("m")
+		ctx.Next()
+	})
+	other.Get("/", func(ctx context.Context) {
+		ctx.WriteString("h")
+	})
+
+	httptest.New(t, app).GET("/").Expect().Status(iris.StatusOK).Body().Equal("[m[h]]")
+	httptest.New(t, other).GET("/").Expect().Status(iris.StatusOK).Body().Equal("mh")
+}
@@ -0,0 +1,52 @@
+// black-box testing
+package router_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+
+	"github.com/kataras/iris/httptest"
+)
+
+func slowMiddleware(ctx context.Context) {
+	time.Sleep(10 * time.Millisecond)
+	ctx.Next()
+}
+
+func writeTimingBreakdown(ctx context.Context) {
+	ctx.WriteString(ctx.HandlerName())
+	for _, entry := range context.GetHandlersTiming(ctx) {
+		ctx.Writef("|%s", entry.Name)
+	}
+}
+
+func TestHandlerTiming(t *testing.T) {
+	app := iris.New()
+	app.Configure(iris.WithHandlerTiming(5 * time.Millisecond))
+
+	buff := &bytes.Buffer{}
+	app.Logger().SetOutput(buff)
+	defer app.Logger().SetOutput(os.Stdout)
+
+	app.Get("/", slowMiddleware, writeTimingBreakdown)
+
+	e := httptest.New(t, app, httptest.LogLevel("warn"))
+
+	expectedBody := strings.Join([]string{
+		context.HandlerName(writeTimingBreakdown),
+		context.HandlerName(slowMiddleware),
+		context.HandlerName(writeTimingBreakdown),
+	}, "|")
+	e.GET("/").Expect().Status(iris.StatusOK).Body().Equal(expectedBody)
+
+	got := buff.String()
+	if !strings.Contains(got, "slow request: GET /") || !strings.Contains(got, context.HandlerName(slowMiddleware)+"=") {
+		t.Fatalf("expected a slow request warning with the handlers breakdown but got: %s", got)
+	}
+}
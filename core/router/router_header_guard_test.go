@@ -0,0 +1,80 @@
+// black-box testing
+package router_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+
+	"github.com/kataras/iris/httptest"
+)
+
+func TestHeaderMutationDetection(t *testing.T) {
+	app := iris.New()
+	app.Configure(iris.WithHeaderMutationDetection)
+
+	buff := &bytes.Buffer{}
+	app.Logger().SetOutput(buff)
+	defer app.Logger().SetOutput(os.Stdout)
+
+	lateHeaderHandler := func(ctx context.Context) {
+		ctx.WriteString("body")
+		ctx.Header("X-Late", "dropped")
+	}
+	app.Get("/late", lateHeaderHandler).Name = "late"
+	app.Get("/ok", func(ctx context.Context) {
+		ctx.Header("X-Early", "sent")
+		ctx.WriteString("body")
+	})
+
+	e := httptest.New(t, app, httptest.LogLevel("warn"))
+
+	e.GET("/ok").Expect().Status(iris.StatusOK).Header("X-Early").Equal("sent")
+	if got := buff.String(); got != "" {
+		t.Fatalf("expected no warnings but got: %s", got)
+	}
+
+	e.GET("/late").Expect().Status(iris.StatusOK)
+	got := buff.String()
+	if !strings.Contains(got, "[X-Late]") || !strings.Contains(got, "'/late' (route: late)") {
+		t.Fatalf("expected a warning about the X-Late header but got: %s", got)
+	}
+	if expectedName := context.HandlerName(lateHeaderHandler); !strings.Contains(got, expectedName) {
+		t.Fatalf("expected the warning to contain the handler name '%s' but got: %s", expectedName, got)
+	}
+}
+
+func TestHeaderMutationDetectionAfterEnd(t *testing.T) {
+	app := iris.New()
+	app.Configure(iris.WithHeaderMutationDetection)
+
+	buff := &bytes.Buffer{}
+	app.Logger().SetOutput(buff)
+	defer app.Logger().SetOutput(os.Stdout)
+
+	accessed := make(chan struct{})
+	app.Get("/", func(ctx context.Context) {
+		w := ctx.ResponseWriter().Naive()
+		context.OnEnd(ctx, func() {
+			// the context is released after the request, the goroutine should not reach its headers.
+			go func() {
+				w.Header().Set("X-Foreign", "dropped")
+				close(accessed)
+			}()
+		})
+		ctx.WriteString("body")
+	})
+
+	e := httptest.New(t, app, httptest.LogLevel("warn"))
+	e.GET("/").Expect().Status(iris.StatusOK).Header("X-Foreign").Empty()
+	<-accessed
+
+	if got := buff.String(); !strings.Contains(got, "response headers of '/' (route: GET/) accessed from goroutine") ||
+		!strings.Contains(got, "after the end of the request") {
+		t.Fatalf("expected a warning about the access after the end of the request but got: %s", got)
+	}
+}
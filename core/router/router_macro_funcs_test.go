@@ -0,0 +1,53 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestMacroFuncsValidation(t *testing.T) {
+	app := iris.New()
+
+	// the children routes share the validation of the party's parameter.
+	users := app.Party("/users/{id:uint64 min(1) max(9999)}")
+	users.Get("/", func(ctx context.Context) {
+		ctx.Writef("user %d", ctx.Params().GetUint64Default("id", 0))
+	})
+	users.Get("/posts/{slug:string regexp(^[a-z]+(-[a-z]+)*$)}", func(ctx context.Context) {
+		ctx.Writef("post %s of %d", ctx.Params().Get("slug"), ctx.Params().GetUint64Default("id", 0))
+	})
+	app.Get("/codes/{code:int range(1,5) else 400}", func(ctx context.Context) {
+		ctx.Writef("code %d", ctx.Params().GetIntDefault("code", 0))
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/users/42").Expect().Status(iris.StatusOK).Body().Equal("user 42")
+	// not of its type.
+	e.GET("/users/abc").Expect().Status(iris.StatusNotFound)
+	// of its type but its functions failed.
+	e.GET("/users/0").Expect().Status(iris.StatusUnprocessableEntity)
+	e.GET("/users/10000/posts/hello").Expect().Status(iris.StatusUnprocessableEntity)
+	e.GET("/users/42/posts/hello-world").Expect().Status(iris.StatusOK).Body().Equal("post hello-world of 42")
+	e.GET("/users/42/posts/Hello").Expect().Status(iris.StatusUnprocessableEntity)
+	// the "else" sets both.
+	e.GET("/codes/3").Expect().Status(iris.StatusOK).Body().Equal("code 3")
+	e.GET("/codes/9").Expect().Status(iris.StatusBadRequest)
+	e.GET("/codes/x").Expect().Status(iris.StatusBadRequest)
+}
+
+func TestMacroFuncsRegistrationErrors(t *testing.T) {
+	app := iris.New()
+	app.Get("/users/{id:uint64 mni(1)}", func(ctx context.Context) {})
+	if err := app.Build(); err == nil {
+		t.Fatalf("expected an error of the unknown 'mni' function")
+	}
+
+	app = iris.New()
+	app.Get("/users/{id:uint64 min(abc)}", func(ctx context.Context) {})
+	if err := app.Build(); err == nil {
+		t.Fatalf("expected an error of the invalid arguments of the 'min' function")
+	}
+}
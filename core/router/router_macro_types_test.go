@@ -0,0 +1,54 @@
+package router_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/macro"
+)
+
+func TestCustomMacroTypes(t *testing.T) {
+	// the app.Macros() are the process-global macro.Defaults.
+	defaults := *macro.Defaults
+	defer func() { *macro.Defaults = defaults }()
+
+	app := iris.New()
+
+	date := app.Macros().Register("testdate", "", false, false, macro.Evaluator(func(paramValue string) (time.Time, error) {
+		return time.Parse("2006-01-02", paramValue)
+	}))
+	date.RegisterFunc("after", func(year int) func(time.Time) bool {
+		return func(paramValue time.Time) bool {
+			return paramValue.Year() > year
+		}
+	})
+	app.Macros().Register("testslug", "", false, false, macro.Evaluator(macro.MustRegexp("^[a-z0-9]+(-[a-z0-9]+)*$")))
+
+	if app.Macros().Register("testdate", "", false, false, nil) != nil {
+		t.Fatalf("expected a nil macro of a taken parameter type")
+	}
+
+	app.Get("/archive/{d:testdate after(2000)}", func(ctx context.Context) {
+		d, ok := ctx.Params().GetValue("d").(time.Time)
+		if !ok {
+			ctx.StatusCode(iris.StatusInternalServerError)
+			return
+		}
+		ctx.Writef("%s %s", d.Weekday(), d.Format("Jan 2006"))
+	})
+	app.Get("/posts/{s:testslug min(3)}", func(ctx context.Context) {
+		ctx.WriteString(ctx.Params().Get("s"))
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/archive/2019-03-04").Expect().Status(iris.StatusOK).Body().Equal("Monday Mar 2019")
+	e.GET("/archive/2019-13-04").Expect().Status(iris.StatusNotFound)
+	e.GET("/archive/1999-03-04").Expect().Status(iris.StatusUnprocessableEntity)
+	e.GET("/posts/hello-world").Expect().Status(iris.StatusOK).Body().Equal("hello-world")
+	e.GET("/posts/Hello").Expect().Status(iris.StatusNotFound)
+	// the string's functions are available to all the types.
+	e.GET("/posts/ab").Expect().Status(iris.StatusUnprocessableEntity)
+}
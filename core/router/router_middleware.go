@@ -0,0 +1,107 @@
+package router
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kataras/iris/context"
+)
+
+// routerMiddlewareEntry is a group of pre-routing handlers of a Party, see `APIBuilder#UseRouter`.
+type routerMiddlewareEntry struct {
+	subdomain string
+	// prefix is the static path prefix of the Party, without the trailing slash, empty for the root.
+	prefix   string
+	handlers context.Handlers
+}
+
+// matchPath reports whether the "path" is the prefix or it's under the prefix.
+func (e *routerMiddlewareEntry) matchPath(path string) bool {
+	if e.prefix == "" {
+		return true
+	}
+
+	return path == e.prefix || (strings.HasPrefix(path, e.prefix) && path[len(e.prefix)] == '/')
+}
+
+// routerMiddleware keeps the pre-routing handlers of all parties, it's shared between them.
+type routerMiddleware struct {
+	entries []*routerMiddlewareEntry
+}
+
+// sorted returns the entries in their execution order,
+// the less specific first (root, then by the length of the path prefix)
+// and by registration order on the same prefix.
+func (m *routerMiddleware) sorted() []*routerMiddlewareEntry {
+	entries := make([]*routerMiddlewareEntry, len(m.entries))
+	copy(entries, m.entries)
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if (entries[i].subdomain == "") != (entries[j].subdomain == "") {
+			return entries[i].subdomain == ""
+		}
+		return len(entries[i].prefix) < len(entries[j].prefix)
+	})
+
+	return entries
+}
+
+// UseRouter registers handlers which are executed before the routing,
+// on each request which matches the subdomain and the path prefix of this Party,
+// even if no route matches it (i.e 404), in contrast to the `Use` ones which run after a route is matched.
+// The routing happens when the last of them calls the `ctx.Next()`, so they can stop the request,
+// rewrite its path or wrap the response writer.
+//
+// Their execution order is deterministic, the root's first, then the parties' ones
+// by the length of their path prefix and then by registration order.
+// The dynamic parts of a Party's path are ignored, its static prefix is matched instead.
+//
+// It's the iris handler form of the `Router#WrapRouter`, scoped per Party.
+// Should be called before `Build`.
+//
+// Usage:
+// app.UseRouter(func(ctx iris.Context) { ctx.Header("X-Server", "iris"); ctx.Next() })
+// admin := app.Party("/admin")
+// admin.UseRouter(requireAdminIP)
+// 路由匹配之前执行的中间件(可以按Party的子域和路径前缀来限定范围)
+func (api *APIBuilder) UseRouter(handlers ...context.Handler) {
+	if len(handlers) == 0 {
+		return
+	}
+
+	subdomain, path := api.relativePath, "/"
+	if strings.Contains(subdomain, "/") {
+		subdomain, path = splitSubdomainAndPath(api.relativePath)
+	}
+	if idx := strings.IndexByte(path, '{'); idx >= 0 {
+		path = path[:idx]
+	}
+	if idx := strings.IndexByte(path, '*'); idx >= 0 {
+		path = path[:idx]
+	}
+	path = strings.TrimRight(path, "/")
+
+	for _, e := range api.routerMiddleware.entries {
+		if e.subdomain == subdomain && e.prefix == path {
+			e.handlers = joinHandlers(e.handlers, handlers)
+			return
+		}
+	}
+
+	api.routerMiddleware.entries = append(api.routerMiddleware.entries, &routerMiddlewareEntry{
+		subdomain: subdomain,
+		prefix:    path,
+		handlers:  joinHandlers(nil, handlers),
+	})
+}
+
+// getRouterMiddleware returns the pre-routing handlers of all parties, in their execution order.
+func (api *APIBuilder) getRouterMiddleware() []*routerMiddlewareEntry {
+	return api.routerMiddleware.sorted()
+}
+
+// routerMiddlewareProvider is implemented by the `RoutesProvider`s which
+// register pre-routing handlers, i.e the `APIBuilder`.
+type routerMiddlewareProvider interface {
+	getRouterMiddleware() []*routerMiddlewareEntry
+}
@@ -0,0 +1,200 @@
+// black-box testing
+package router_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
+)
+
+// pushRecorder records the pushed targets of an HTTP/2 response.
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+	opts   []*http.PushOptions
+	// body is the response body when the first target was pushed.
+	body string
+	err  error
+}
+
+func (w *pushRecorder) Push(target string, opts *http.PushOptions) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if len(w.pushed) == 0 {
+		w.body = w.Body.String()
+	}
+	w.pushed = append(w.pushed, target)
+	w.opts = append(w.opts, opts)
+	return nil
+}
+
+func servePush(t *testing.T, app *iris.Application, path string, http2 bool, header ...string) *pushRecorder {
+	t.Helper()
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(iris.MethodGet, path, nil)
+	if http2 {
+		r.Proto, r.ProtoMajor, r.ProtoMinor = "HTTP/2.0", 2, 0
+	}
+	for i := 1; i < len(header); i += 2 {
+		r.Header.Set(header[i-1], header[i])
+	}
+
+	w := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	app.ServeHTTP(w, r)
+	return w
+}
+
+func TestParsePushTarget(t *testing.T) {
+	tests := map[string]string{
+		"/css/app.css":                                     "/css/app.css",
+		" /js/app.js ":                                     "/js/app.js",
+		"</css/app.css>; rel=preload; as=style":            "/css/app.css",
+		`</font.woff2>; rel="preload prefetch"; as=font`:   "/font.woff2",
+		"</css/app.css>; REL=Preload":                      "/css/app.css",
+		"</css/app.css>; rel=preload; as=style; nopush":    "",
+		"</css/app.css>; rel=prefetch":                     "",
+		"</css/app.css>":                                   "",
+		"</css/app.css; rel=preload":                       "",
+		"</other.css>; rel=stylesheet, </x>; rel=preload;": "",
+		"</a.css>; rel=preload, </b.js>; rel=preload":      "/a.css",
+	}
+
+	for target, expected := range tests {
+		if got := context.ParsePushTarget(target); got != expected {
+			t.Fatalf("%q: expected %q but got %q", target, expected, got)
+		}
+	}
+}
+
+func TestRoutePush(t *testing.T) {
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		ctx.WriteString("index")
+	}).Push("/css/app.css", "</js/app.js>; rel=preload; as=script", "</img.png>; rel=preload; nopush")
+	app.Get("/none", func(ctx iris.Context) {})
+
+	w := servePush(t, app, "/", true, "Accept-Encoding", "gzip")
+	if expected := []string{"/css/app.css", "/js/app.js"}; !reflect.DeepEqual(w.pushed, expected) {
+		t.Fatalf("expected the pushed targets %v but got %v", expected, w.pushed)
+	}
+	// before the body.
+	if w.body != "" || w.Body.String() != "index" {
+		t.Fatalf("expected the targets to be pushed before the body %q but got %q", w.Body.String(), w.body)
+	}
+	// the pushed responses can be compressed as well.
+	if opts := w.opts[0]; opts == nil || opts.Header.Get("Accept-Encoding") != "gzip" {
+		t.Fatalf("expected the push options to carry the accept encoding but got %#v", opts)
+	}
+
+	if w = servePush(t, app, "/", true); w.opts[0] != nil {
+		t.Fatalf("expected no push options without an accept encoding but got %#v", w.opts[0])
+	}
+
+	// an HTTP/1.x request.
+	if w = servePush(t, app, "/", false); len(w.pushed) != 0 || w.Body.String() != "index" {
+		t.Fatalf("expected no pushed targets but got %v", w.pushed)
+	}
+
+	if w = servePush(t, app, "/none", true); len(w.pushed) != 0 {
+		t.Fatalf("expected no pushed targets but got %v", w.pushed)
+	}
+}
+
+func TestPushTargetsErrors(t *testing.T) {
+	var pushErr error
+	app := iris.New()
+	app.Get("/", func(ctx iris.Context) {
+		pushErr = ctx.PushTargets("/css/app.css")
+	})
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	serve := func(err error) error {
+		pushErr = nil
+		r := httptest.NewRequest(iris.MethodGet, "/", nil)
+		r.ProtoMajor = 2
+		app.ServeHTTP(&pushRecorder{ResponseRecorder: httptest.NewRecorder(), err: err}, r)
+		return pushErr
+	}
+
+	// i.e disabled by the client.
+	if err := serve(http.ErrNotSupported); err != nil {
+		t.Fatalf("expected the not supported push to be ignored but got %v", err)
+	}
+
+	otherErr := errors.New("recursive push")
+	if err := serve(otherErr); err != otherErr {
+		t.Fatalf("expected the error %v but got %v", otherErr, err)
+	}
+
+	// a response writer which is not a pusher.
+	pushErr = errors.New("not called")
+	r := httptest.NewRequest(iris.MethodGet, "/", nil)
+	r.ProtoMajor = 2
+	app.ServeHTTP(httptest.NewRecorder(), r)
+	if pushErr != nil {
+		t.Fatalf("expected no error but got %v", pushErr)
+	}
+}
+
+func TestPushManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, contents string) string {
+		filename := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(filename, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+
+	// both of the formats.
+	manifest, err := router.LoadPushManifest(write("push_manifest.json", `{
+		"/": ["/css/app.css", "/js/app.js"],
+		"/users/{id}": {"/js/user.js": {"type": "script", "weight": 1}, "/css/user.css": {"type": "style", "weight": 1}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, filename := range []string{write("invalid.json", `["/"]`), write("invalid_targets.json", `{"/": 1}`), filepath.Join(dir, "missing.json")} {
+		if _, err = router.LoadPushManifest(filename); err == nil {
+			t.Fatalf("%s: expected an error", filename)
+		}
+	}
+
+	app := iris.New()
+	app.Use(manifest.Handler)
+	app.Get("/", func(ctx iris.Context) {})
+	app.Get("/users/{id}", func(ctx iris.Context) {})
+
+	tests := map[string][]string{
+		"/":         {"/css/app.css", "/js/app.js"},
+		"/users/42": {"/css/user.css", "/js/user.js"},
+	}
+	for path, expected := range tests {
+		if w := servePush(t, app, path, true); !reflect.DeepEqual(w.pushed, expected) {
+			t.Fatalf("%s: expected the pushed targets %v but got %v", path, expected, w.pushed)
+		}
+	}
+}
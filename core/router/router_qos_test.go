@@ -0,0 +1,79 @@
+// black-box testing
+package router_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
+
+	"github.com/kataras/iris/httptest"
+)
+
+func TestQoS(t *testing.T) {
+	app := iris.New()
+	qos := app.EnableQoS(router.QoSOptions{MaxInFlight: 1, QueueTimeout: 5 * time.Second, RetryAfter: 3 * time.Second})
+
+	started, unblock := make(chan struct{}), make(chan struct{})
+	app.Get("/slow", func(ctx context.Context) {
+		close(started)
+		<-unblock
+		ctx.WriteString("slow")
+	})
+	app.Get("/normal", func(ctx context.Context) {
+		ctx.WriteString("normal")
+	})
+	app.Get("/health", func(ctx context.Context) {
+		ctx.WriteString("healthy")
+	}).SetQoS(router.QoSCritical)
+	reports := app.Party("/reports")
+	reports.QoS(router.QoSBulk)
+	reports.Get("/export", func(ctx context.Context) {
+		ctx.WriteString("export")
+	})
+
+	e := httptest.New(t, app)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		e.GET("/slow").Expect().Status(iris.StatusOK).Body().Equal("slow")
+	}()
+	<-started
+
+	// under load: the critical proceeds, the bulk is shed and the normal is queued.
+	e.GET("/health").Expect().Status(iris.StatusOK).Body().Equal("healthy")
+	e.GET("/reports/export").Expect().Status(iris.StatusServiceUnavailable).Header("Retry-After").Equal("3")
+
+	go func() {
+		defer wg.Done()
+		e.GET("/normal").Expect().Status(iris.StatusOK).Body().Equal("normal")
+	}()
+
+	for qos.Stats().Waiting == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(unblock)
+	wg.Wait()
+
+	stats := qos.Stats()
+	if stats.InFlight != 0 || stats.Waiting != 0 {
+		t.Fatalf("expected no in-flight and waiting requests but got %d and %d", stats.InFlight, stats.Waiting)
+	}
+	if expected, got := (router.QoSClassStats{Admitted: 0, Shed: 1}), stats.Classes[router.QoSBulk]; expected != got {
+		t.Fatalf("expected bulk stats %#v but got %#v", expected, got)
+	}
+	if expected, got := (router.QoSClassStats{Admitted: 2, Queued: 1}), stats.Classes[router.QoSNormal]; expected != got {
+		t.Fatalf("expected normal stats %#v but got %#v", expected, got)
+	}
+	if expected, got := uint64(1), stats.Classes[router.QoSCritical].Admitted; expected != got {
+		t.Fatalf("expected %d admitted critical requests but got %d", expected, got)
+	}
+
+	// not under load.
+	e.GET("/reports/export").Expect().Status(iris.StatusOK).Body().Equal("export")
+}
@@ -0,0 +1,70 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/kataras/iris"
+)
+
+// Run with -race, the rebuilds should not race with the requests being served.
+func TestRefreshRouterWhileServing(t *testing.T) {
+	app := iris.New()
+	app.Get("/", writeHandler("index"))
+	route := app.Get("/toggle", writeHandler("toggle"))
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				w := httptest.NewRecorder()
+				app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+				if w.Code != iris.StatusOK || w.Body.String() != "index" {
+					t.Errorf("expected the index route to be served during the rebuilds but got: %d %s", w.Code, w.Body.String())
+					return
+				}
+
+				w = httptest.NewRecorder()
+				app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/toggle", nil))
+				if w.Code != iris.StatusOK && w.Code != iris.StatusNotFound {
+					t.Errorf("unexpected status code of the toggled route: %d", w.Code)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		route.SetStatusOffline()
+		if err := app.RefreshRouter(); err != nil {
+			t.Fatal(err)
+		}
+		route.RestoreStatus()
+		if err := app.RefreshRouter(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/toggle", nil))
+	if w.Body.String() != "toggle" {
+		t.Fatalf("expected the restored route to be served but got: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,139 @@
+package router_test
+
+import (
+	"net/http"
+	stdhttptest "net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestRemoveRoute(t *testing.T) {
+	app := iris.New()
+	app.Get("/", writeHandler("index"))
+	app.Get("/plugins/stats", writeHandler("stats")).Name = "plugin.stats"
+	app.Get("/plugins/{id:uint64}", writeHandler("plugin")).Name = "plugin"
+	app.Get("/files/{path:path}", writeHandler("files")).Name = "files"
+	app.Get("/files/{path:path}", writeHandler("files v2")).
+		Headers("X-Version", "2").Name = "files.v2"
+
+	e := httptest.New(t, app)
+
+	e.GET("/plugins/stats").Expect().Status(iris.StatusOK).Body().Equal("stats")
+	if err := app.RemoveRoute("plugin.stats"); err != nil {
+		t.Fatal(err)
+	}
+	// removed from the running router, "stats" is not a valid id of the parameter's route.
+	e.GET("/plugins/stats").Expect().Status(iris.StatusNotFound)
+	e.GET("/plugins/42").Expect().Status(iris.StatusOK).Body().Equal("plugin")
+
+	if err := app.RemoveRoute("plugin"); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/plugins/42").Expect().Status(iris.StatusNotFound)
+
+	// a predicated route.
+	e.GET("/files/a/b").WithHeader("X-Version", "2").Expect().Body().Equal("files v2")
+	if err := app.RemoveRoute("files.v2"); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/files/a/b").WithHeader("X-Version", "2").Expect().Body().Equal("files")
+
+	if err := app.RemoveRoute("plugin"); err == nil {
+		t.Fatalf("expected an error for a route which is already removed")
+	}
+
+	// the removed routes are not registered again on refresh.
+	if err := app.RefreshRouter(); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/plugins/stats").Expect().Status(iris.StatusNotFound)
+	e.GET("/plugins/42").Expect().Status(iris.StatusNotFound)
+	e.GET("/").Expect().Status(iris.StatusOK).Body().Equal("index")
+	e.GET("/files/a").Expect().Status(iris.StatusOK).Body().Equal("files")
+}
+
+// Run with -race, the removals should not race with the requests being served.
+func TestRemoveRouteWhileServing(t *testing.T) {
+	app := iris.New()
+	app.Get("/", writeHandler("index"))
+	for i := 0; i < 50; i++ {
+		app.Get("/plugins/"+strconv.Itoa(i), writeHandler("plugin")).Name = "plugin" + strconv.Itoa(i)
+	}
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				w := stdhttptest.NewRecorder()
+				app.ServeHTTP(w, stdhttptest.NewRequest(http.MethodGet, "/", nil))
+				if w.Code != iris.StatusOK || w.Body.String() != "index" {
+					t.Errorf("expected the index route to be served during the removals but got: %d %s", w.Code, w.Body.String())
+					return
+				}
+
+				w = stdhttptest.NewRecorder()
+				app.ServeHTTP(w, stdhttptest.NewRequest(http.MethodGet, "/plugins/"+strconv.Itoa(i), nil))
+				if w.Code != iris.StatusOK && w.Code != iris.StatusNotFound {
+					t.Errorf("unexpected status code of a removed route: %d", w.Code)
+					return
+				}
+			}
+		}(i)
+	}
+
+	for i := 0; i < 50; i++ {
+		if err := app.RemoveRoute("plugin" + strconv.Itoa(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestRouteSetHandlers(t *testing.T) {
+	app := iris.New()
+	app.Use(func(ctx context.Context) {
+		ctx.Header("X-Middleware", "1")
+		ctx.Next()
+	})
+	route := app.Get("/user/{id:uint64}", writeHandler("v1"))
+
+	e := httptest.New(t, app)
+	e.GET("/user/42").Expect().Status(iris.StatusOK).
+		Header("X-Middleware").Equal("1")
+
+	if err := route.SetHandlers(writeHandler("v2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.RefreshRouter(); err != nil {
+		t.Fatal(err)
+	}
+
+	e.GET("/user/42").Expect().Status(iris.StatusOK).
+		Header("X-Middleware").Empty()
+	e.GET("/user/42").Expect().Body().Equal("v2")
+	// the parameter's type is still evaluated.
+	e.GET("/user/me").Expect().Status(iris.StatusNotFound)
+
+	if err := route.SetHandlers(); err == nil {
+		t.Fatalf("expected an error for missing handlers")
+	}
+}
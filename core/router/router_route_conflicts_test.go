@@ -0,0 +1,88 @@
+package router_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
+	"github.com/kataras/iris/httptest"
+)
+
+func writeHandler(s string) context.Handler {
+	return func(ctx context.Context) {
+		ctx.WriteString(s)
+	}
+}
+
+func TestRouteConflicts(t *testing.T) {
+	app := iris.New()
+	app.Get("/things", func(ctx context.Context) {
+		ctx.WriteString("first")
+	})
+	// duplicate without predicates.
+	app.Get("/things", func(ctx context.Context) {
+		ctx.WriteString("duplicate")
+	})
+	// ambiguous, the same node of the router.
+	app.Get("/user/{id:uint64}", writeHandler("id"))
+	app.Get("/user/{name:string}", writeHandler("name"))
+
+	// not conflicts.
+	app.Get("/user/me", writeHandler("me"))
+	app.Post("/things", writeHandler("post"))
+	app.Get("/things", writeHandler("v2")).Headers("X-API-Version", "2")
+	app.Party("/allow").AllowMethods("GET").Get("/", writeHandler("allow"))
+
+	conflicts := app.RouteConflicts()
+	if expected, got := 2, len(conflicts); expected != got {
+		t.Fatalf("expected %d conflicts but got %d: %v", expected, got, conflicts)
+	}
+
+	if c := conflicts[0]; c.Reason != router.RouteConflictDuplicate || c.Route.Tmpl().Src != "/things" || c.Existing.Tmpl().Src != "/things" {
+		t.Fatalf("expected a duplicate conflict of the '/things' but got: %v", c)
+	}
+
+	if c := conflicts[1]; c.Reason != router.RouteConflictAmbiguous ||
+		c.Route.Tmpl().Src != "/user/{name:string}" || c.Existing.Tmpl().Src != "/user/{id:uint64}" {
+		t.Fatalf("expected an ambiguous conflict of the '/user/{name:string}' but got: %v", c)
+	}
+
+	err := app.Build()
+	if err == nil {
+		t.Fatalf("expected a build error of the conflicts")
+	}
+
+	for _, c := range conflicts {
+		if !strings.Contains(err.Error(), c.Error()) {
+			t.Fatalf("expected the build error to contain: %s but got: %s", c.Error(), err.Error())
+		}
+	}
+}
+
+func TestRouteConflictsSubdomainShadowing(t *testing.T) {
+	app := iris.New()
+	// the wildcard subdomain is registered first but it does not shadow the specific one.
+	app.WildcardSubdomain().Get("/", writeHandler("wildcard"))
+	app.Subdomain("a").Get("/", writeHandler("a"))
+	// the specific subdomain is registered first, the expected order.
+	app.Subdomain("b").Get("/b", writeHandler("b"))
+	app.WildcardSubdomain().Get("/b", writeHandler("wildcard b"))
+
+	conflicts := app.RouteConflicts()
+	if expected, got := 1, len(conflicts); expected != got {
+		t.Fatalf("expected %d conflicts but got %d: %v", expected, got, conflicts)
+	}
+
+	if c := conflicts[0]; c.Reason != router.RouteConflictShadowed || c.Route.Subdomain != "a." || c.Existing.Subdomain != router.SubdomainWildcardIndicator {
+		t.Fatalf("expected a shadowed conflict of the 'a.' subdomain but got: %v", c)
+	}
+
+	// reported but served, not a build error.
+	e := httptest.New(t, app)
+	e.GET("/").WithURL("http://a.mydomain.com").Expect().Status(iris.StatusOK).Body().Equal("a")
+	e.GET("/").WithURL("http://c.mydomain.com").Expect().Status(iris.StatusOK).Body().Equal("wildcard")
+	e.GET("/b").WithURL("http://b.mydomain.com").Expect().Status(iris.StatusOK).Body().Equal("b")
+	e.GET("/b").WithURL("http://c.mydomain.com").Expect().Status(iris.StatusOK).Body().Equal("wildcard b")
+}
@@ -0,0 +1,52 @@
+// black-box testing
+package router_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
+
+	"github.com/kataras/iris/httptest"
+)
+
+func TestRouteStats(t *testing.T) {
+	app := iris.New()
+	app.Get("/ok", func(ctx context.Context) {
+		ctx.WriteString("ok")
+	}).Name = "ok"
+	app.Get("/fail", func(ctx context.Context) {
+		ctx.StatusCode(iris.StatusInternalServerError)
+	}).Name = "fail"
+	app.Get("/debug/routes", router.StatsHandler())
+
+	e := httptest.New(t, app)
+
+	for i := 0; i < 3; i++ {
+		e.GET("/ok").Expect().Status(iris.StatusOK)
+	}
+	e.GET("/fail").Expect().Status(iris.StatusInternalServerError)
+
+	okStats := app.GetRouteReadOnly("ok").Stats()
+	if expected, got := uint64(3), okStats.Hits; expected != got {
+		t.Fatalf("expected %d hits but got %d", expected, got)
+	}
+	if okStats.Errors != 0 || okStats.LastError != "" {
+		t.Fatalf("expected no errors but got %d: '%s'", okStats.Errors, okStats.LastError)
+	}
+
+	failStats := app.GetRouteReadOnly("fail").Stats()
+	if failStats.Hits != 1 || failStats.Errors != 1 {
+		t.Fatalf("expected one hit and one error but got %d hits and %d errors", failStats.Hits, failStats.Errors)
+	}
+	if expected, got := "500 Internal Server Error", failStats.LastError; expected != got {
+		t.Fatalf("expected last error '%s' but got '%s'", expected, got)
+	}
+	if failStats.LastErrorTime.IsZero() {
+		t.Fatalf("expected the last error time to be filled")
+	}
+
+	e.GET("/debug/routes").Expect().Status(iris.StatusOK).
+		JSON().Array().Element(0).Object().ContainsKey("hits")
+}
@@ -0,0 +1,62 @@
+package router_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestServeContentRange(t *testing.T) {
+	var (
+		content = []byte("0123456789abcdefghij")
+		modtime = time.Date(2019, 1, 2, 15, 4, 5, 0, time.UTC)
+	)
+
+	app := iris.New()
+	app.Get("/file.txt", func(ctx context.Context) {
+		ctx.ServeContent(bytes.NewReader(content), "file.txt", modtime, false)
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/file.txt").Expect().Status(iris.StatusOK).
+		Header(context.AcceptRangesHeaderKey).Equal("bytes")
+
+	r := e.GET("/file.txt").WithHeader(context.RangeHeaderKey, "bytes=2-5").Expect().Status(iris.StatusPartialContent)
+	r.Header(context.ContentRangeHeaderKey).Equal("bytes 2-5/20")
+	r.Header(context.ContentLengthHeaderKey).Equal("4")
+	r.Body().Equal("2345")
+
+	e.GET("/file.txt").WithHeader(context.RangeHeaderKey, "bytes=-3").Expect().
+		Status(iris.StatusPartialContent).Body().Equal("hij")
+	e.GET("/file.txt").WithHeader(context.RangeHeaderKey, "bytes=18-100").Expect().
+		Status(iris.StatusPartialContent).Body().Equal("ij")
+
+	e.GET("/file.txt").WithHeader(context.RangeHeaderKey, "bytes=20-").Expect().
+		Status(iris.StatusRequestedRangeNotSatisfiable).Header(context.ContentRangeHeaderKey).Equal("bytes */20")
+
+	// multiple ranges.
+	r = e.GET("/file.txt").WithHeader(context.RangeHeaderKey, "bytes=0-1, 10-11").Expect().Status(iris.StatusPartialContent)
+	r.Header(context.ContentTypeHeaderKey).Contains("multipart/byteranges; boundary=")
+	body := r.Body().Raw()
+	for _, expected := range []string{"Content-Range: bytes 0-1/20", "\r\n\r\n01\r\n", "Content-Range: bytes 10-11/20", "\r\n\r\nab\r\n"} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("expected multipart body to contain %q but got:\n%s", expected, body)
+		}
+	}
+
+	// If-Range matches the Last-Modified, the range is served, otherwise the whole content.
+	lastModified := modtime.Format(app.ConfigurationReadOnly().GetTimeFormat())
+	e.GET("/file.txt").WithHeader(context.RangeHeaderKey, "bytes=0-0").WithHeader(context.IfRangeHeaderKey, lastModified).
+		Expect().Status(iris.StatusPartialContent).Body().Equal("0")
+	e.GET("/file.txt").WithHeader(context.RangeHeaderKey, "bytes=0-0").
+		WithHeader(context.IfRangeHeaderKey, modtime.Add(-time.Hour).Format(app.ConfigurationReadOnly().GetTimeFormat())).
+		Expect().Status(iris.StatusOK).Body().Equal(string(content))
+	e.GET("/file.txt").WithHeader(context.RangeHeaderKey, "bytes=0-0").WithHeader(context.IfRangeHeaderKey, `"etag"`).
+		Expect().Status(iris.StatusOK).Body().Equal(string(content))
+}
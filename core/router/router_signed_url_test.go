@@ -0,0 +1,47 @@
+package router_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestSignedURL(t *testing.T) {
+	app := iris.New()
+	app.Configure(iris.WithURLSigningKey("secret"))
+	app.Get("/download/{file}", app.RequireSignedURL(), func(ctx context.Context) {
+		ctx.Writef("%s %s", ctx.Params().Get("file"), ctx.URLParam("email"))
+	}).Name = "download"
+
+	link, err := app.SignURL("download", []interface{}{"report.pdf"}, time.Hour, url.Values{"email": {"me@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired, err := app.SignURL("download", []interface{}{"report.pdf"}, time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if _, err = app.SignURL("notfound", nil, time.Hour); err == nil {
+		t.Fatalf("expected an error on a missing route")
+	}
+
+	e := httptest.New(t, app)
+	u, _ := url.Parse(link)
+	e.GET(u.Path).WithQueryString(u.RawQuery).Expect().Status(iris.StatusOK).Body().Equal("report.pdf me@example.com")
+	// tampered claim.
+	e.GET(u.Path).WithQueryString(u.RawQuery+"&email=other@example.com").Expect().Status(iris.StatusForbidden)
+	// tampered path.
+	e.GET("/download/other.pdf").WithQueryString(u.RawQuery).Expect().Status(iris.StatusForbidden)
+	// unsigned.
+	e.GET(u.Path).Expect().Status(iris.StatusForbidden)
+
+	u, _ = url.Parse(expired)
+	e.GET(u.Path).WithQueryString(u.RawQuery).Expect().Status(iris.StatusForbidden)
+}
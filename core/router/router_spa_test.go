@@ -0,0 +1,60 @@
+package router_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestPartySPA(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html": {Data: []byte("app")},
+		"app.js":     {Data: []byte("js")},
+	}
+	adminFiles := fstest.MapFS{
+		"index.html": {Data: []byte("admin")},
+	}
+
+	app := iris.New()
+	app.Use(func(ctx context.Context) {
+		ctx.Header("X-Middleware", "root")
+		ctx.Next()
+	})
+	app.Get("/api/users", func(ctx context.Context) {
+		ctx.WriteString("users")
+	})
+	app.SPA(router.StaticFSHandler(files)).Exclude("/api")
+
+	admin := app.Party("/admin")
+	admin.Get("/stats", func(ctx context.Context) {
+		ctx.WriteString("stats")
+	})
+	admin.SPA(router.StaticFSHandler(adminFiles))
+
+	e := httptest.New(t, app)
+
+	// the routes are kept intact.
+	e.GET("/api/users").Expect().Status(iris.StatusOK).Body().Equal("users")
+	e.GET("/admin/stats").Expect().Status(iris.StatusOK).Body().Equal("stats")
+	// the unmatched API paths are not served by the application.
+	e.GET("/api/missing").Expect().Status(iris.StatusNotFound)
+
+	e.GET("/").Expect().Status(iris.StatusOK).Body().Equal("app")
+	e.GET("/app.js").Expect().Status(iris.StatusOK).Body().Equal("js")
+	e.GET("/users/42").Expect().Status(iris.StatusOK).
+		Header("X-Middleware").Equal("root")
+	e.GET("/users/42").Expect().Body().Equal("app")
+	// the path correction is applied before.
+	e.GET("/users/42/").Expect().Status(iris.StatusOK).Body().Equal("app")
+
+	// the most specific Party's application.
+	e.GET("/admin").Expect().Status(iris.StatusOK).Body().Equal("admin")
+	e.GET("/admin/settings/profile").Expect().Status(iris.StatusOK).Body().Equal("admin")
+
+	// only GET and HEAD.
+	e.POST("/users/42").Expect().Status(iris.StatusNotFound)
+}
@@ -0,0 +1,41 @@
+package router_test
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/core/router"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestStaticFS(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html":    {Data: []byte("<h1>app</h1>")},
+		"css/style.css": {Data: []byte("body{}")},
+		"docs/home.htm": {Data: []byte("docs")},
+	}
+
+	app := iris.New()
+	app.StaticFS("/public", files, router.StaticFSOptions{CacheMaxAge: time.Hour})
+	app.StaticFS("/docs", files, router.StaticFSOptions{IndexName: "home.htm"})
+	app.StaticFS("/app", files, router.StaticFSOptions{SPA: true})
+
+	e := httptest.New(t, app)
+
+	e.GET("/public/css/style.css").Expect().Status(iris.StatusOK).
+		Header("Cache-Control").Equal("public, max-age=3600")
+	e.GET("/public/css/style.css").Expect().Body().Equal("body{}")
+	e.GET("/public/").Expect().Status(iris.StatusOK).Body().Equal("<h1>app</h1>")
+	e.GET("/public/missing.css").Expect().Status(iris.StatusNotFound).
+		Header("Cache-Control").Empty()
+	// no listing by default.
+	e.GET("/public/css/").Expect().Status(iris.StatusForbidden)
+
+	e.GET("/docs/docs/").Expect().Status(iris.StatusOK).Body().Equal("docs")
+
+	// the client-side routes fallback to the index, the missing files do not.
+	e.GET("/app/users/42").Expect().Status(iris.StatusOK).Body().Equal("<h1>app</h1>")
+	e.GET("/app/missing.js").Expect().Status(iris.StatusNotFound)
+}
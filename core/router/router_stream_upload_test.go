@@ -0,0 +1,106 @@
+package router_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestStreamFormFiles(t *testing.T) {
+	app := iris.New()
+	app.Post("/", func(ctx context.Context) {
+		var received []string
+		n, err := ctx.StreamFormFiles(func(ctx context.Context, fieldName, fileName string, r io.Reader) error {
+			b, err := ioutil.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			received = append(received, fieldName+":"+fileName+":"+string(b))
+			return nil
+		})
+		if err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		ctx.Writef("%d|%s", n, strings.Join(received, ","))
+	})
+
+	e := httptest.New(t, app)
+	e.POST("/").WithMultipart().
+		WithFormField("description", "skipped").
+		WithFileBytes("first", "a.txt", []byte("hello")).
+		WithFileBytes("second", "b.txt", []byte("world!")).
+		Expect().Status(iris.StatusOK).Body().Equal("11|first:a.txt:hello,second:b.txt:world!")
+
+	e.POST("/").WithMultipart().WithFormField("description", "no files").
+		Expect().Status(iris.StatusBadRequest).Body().Equal(http.ErrMissingFile.Error())
+
+	e.POST("/").WithFormField("description", "not multipart").
+		Expect().Status(iris.StatusBadRequest).Body().Equal(http.ErrNotMultipart.Error())
+}
+
+func TestNextPart(t *testing.T) {
+	app := iris.New()
+	app.Post("/", func(ctx context.Context) {
+		var received []string
+		for {
+			part, err := ctx.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				ctx.StatusCode(iris.StatusBadRequest)
+				ctx.WriteString(err.Error())
+				return
+			}
+
+			b, err := ioutil.ReadAll(part)
+			if err != nil {
+				ctx.StatusCode(iris.StatusBadRequest)
+				ctx.WriteString(err.Error())
+				return
+			}
+			received = append(received, part.FormName()+":"+part.FileName()+":"+string(b))
+		}
+
+		ctx.WriteString(strings.Join(received, ","))
+	})
+
+	e := httptest.New(t, app)
+	// the parts in order of arrival, the fields have no file name.
+	e.POST("/").WithMultipart().
+		WithFormField("title", "t").
+		WithFileBytes("first", "a.txt", []byte("hello")).
+		WithFormField("description", "d").
+		WithFileBytes("second", "b.txt", []byte("world!")).
+		Expect().Status(iris.StatusOK).Body().Equal("title::t,first:a.txt:hello,description::d,second:b.txt:world!")
+
+	e.POST("/").WithMultipart().
+		Expect().Status(iris.StatusOK).Body().Equal("")
+
+	e.POST("/").WithFormField("description", "not multipart").
+		Expect().Status(iris.StatusBadRequest).Body().Equal(http.ErrNotMultipart.Error())
+
+	// the boundary of the content type is missing.
+	e.POST("/").WithHeader("Content-Type", "multipart/form-data").
+		WithBytes([]byte("--xyz\r\n\r\nvalue\r\n--xyz--\r\n")).
+		Expect().Status(iris.StatusBadRequest).Body().Equal(http.ErrMissingBoundary.Error())
+
+	// the body does not match the boundary of the content type.
+	e.POST("/").WithHeader("Content-Type", "multipart/form-data; boundary=xyz").
+		WithBytes([]byte("--abc\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nt\r\n--abc--\r\n")).
+		Expect().Status(iris.StatusBadRequest).Body().NotEmpty()
+
+	// the closing boundary is missing.
+	e.POST("/").WithHeader("Content-Type", "multipart/form-data; boundary=xyz").
+		WithBytes([]byte("--xyz\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nt")).
+		Expect().Status(iris.StatusBadRequest).Body().NotEmpty()
+}
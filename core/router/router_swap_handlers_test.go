@@ -0,0 +1,45 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestSwapHandlers(t *testing.T) {
+	app := iris.New()
+	app.Use(func(ctx context.Context) {
+		ctx.WriteString("begin|")
+		ctx.Next()
+	})
+	app.Done(func(ctx context.Context) {
+		ctx.WriteString("|done")
+	})
+	app.Get("/greet/{name:string min(2)}", func(ctx context.Context) {
+		ctx.Writef("v1 %s", ctx.Params().Get("name"))
+		ctx.Next()
+	}).Name = "greet"
+
+	e := httptest.New(t, app)
+	e.GET("/greet/iris").Expect().Status(iris.StatusOK).Body().Equal("begin|v1 iris|done")
+
+	if err := app.SwapHandlers("greet", func(ctx context.Context) {
+		ctx.Writef("v2 %s", ctx.Params().Get("name"))
+		ctx.Next()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/greet/iris").Expect().Status(iris.StatusOK).Body().Equal("begin|v2 iris|done")
+
+	// kept on rebuild.
+	if err := app.RefreshRouter(); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/greet/iris").Expect().Status(iris.StatusOK).Body().Equal("begin|v2 iris|done")
+
+	if err := app.SwapHandlers("missing", func(ctx context.Context) {}); err == nil {
+		t.Fatal("expected an error for a missing route")
+	}
+}
@@ -1,10 +1,14 @@
 package router_test
 
 import (
+	stdhttptest "net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/kataras/iris"
 	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
 	"github.com/kataras/iris/httptest"
 )
 
@@ -39,3 +43,266 @@ func TestRouteExists(t *testing.T) {
 	// run the tests
 	httptest.New(t, app, httptest.Debug(false)).Request("GET", "/route-test").Expect().Status(iris.StatusOK)
 }
+
+func TestPartyPathEscapeAndRawPath(t *testing.T) {
+	app := iris.New()
+	writePaths := func(ctx context.Context) {
+		ctx.Writef("%s|%s|%s", ctx.Path(), ctx.RawPath(), ctx.UnescapedPath())
+	}
+
+	app.Get("/global/{p:path}", writePaths)
+	escaped := app.Party("/escaped")
+	// registered before the PathEscape call, it's not affected.
+	escaped.Get("/before/{p:path}", writePaths)
+	escaped.PathEscape(true).Get("/{p:path}", writePaths)
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		uri      string
+		expected string
+	}{
+		{"/global/a%2Fb%20c", "/global/a/b c|/global/a%2Fb%20c|/global/a/b c"},
+		{"/escaped/a+b?q=1", "/escaped/a b|/escaped/a+b|/escaped/a+b"},
+		{"/global/a+b", "/global/a+b|/global/a+b|/global/a+b"},
+		{"/escaped/before/a+b", "/escaped/before/a+b|/escaped/before/a+b|/escaped/before/a+b"},
+	}
+
+	for i, tt := range tests {
+		// the httpexpect encodes the path, so use the std httptest
+		// in order to send the uri as it's.
+		rec := stdhttptest.NewRecorder()
+		app.ServeHTTP(rec, stdhttptest.NewRequest(iris.MethodGet, tt.uri, nil))
+		if got := rec.Body.String(); got != tt.expected {
+			t.Fatalf("[%d] expected '%s' but got '%s'", i, tt.expected, got)
+		}
+	}
+}
+
+func TestRouteGuard(t *testing.T) {
+	app := iris.New()
+	executed := false
+	h := func(ctx context.Context) {
+		executed = true
+		ctx.WriteString("main")
+	}
+
+	allowedByHeader := func(ctx context.Context) (bool, int) {
+		return ctx.GetHeader("X-Role") == "admin", iris.StatusUnauthorized
+	}
+	featureDisabled := func(ctx context.Context) (bool, int) {
+		return false, 0
+	}
+
+	app.Get("/admin", h).Guard(allowedByHeader)
+	app.Get("/feature", h).Guard(allowedByHeader, featureDisabled)
+
+	e := httptest.New(t, app)
+
+	e.GET("/admin").Expect().Status(iris.StatusUnauthorized)
+	if executed {
+		t.Fatalf("expected the route's handlers to not be executed when a guard rejects the request")
+	}
+	e.GET("/admin").WithHeader("X-Role", "admin").Expect().Status(iris.StatusOK).Body().Equal("main")
+	e.GET("/feature").WithHeader("X-Role", "admin").Expect().Status(iris.StatusForbidden)
+}
+
+func TestRouteGuardWithAuthMiddleware(t *testing.T) {
+	app := iris.New()
+	authenticated := false
+	auth := func(ctx context.Context) {
+		if ctx.GetHeader("Authorization") != "Bearer token" {
+			ctx.StatusCode(iris.StatusUnauthorized)
+			return
+		}
+		authenticated = true
+		ctx.Values().Set("user", "kataras")
+		ctx.Next()
+	}
+
+	var guardUser interface{}
+	admin := app.Party("/admin")
+	admin.Use(auth)
+	admin.Get("/", func(ctx context.Context) {
+		ctx.WriteString(ctx.Values().GetString("user"))
+	}).Guard(func(ctx context.Context) (bool, int) {
+		// the guard runs before the party's middleware, the user is not set yet.
+		guardUser = ctx.Values().Get("user")
+		return ctx.GetHeader("X-Role") == "admin", iris.StatusForbidden
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/admin").WithHeader("Authorization", "Bearer token").Expect().Status(iris.StatusForbidden)
+	if authenticated {
+		t.Fatalf("expected the auth middleware to not be executed when the guard rejects the request")
+	}
+
+	e.GET("/admin").WithHeader("X-Role", "admin").Expect().Status(iris.StatusUnauthorized)
+	e.GET("/admin").WithHeader("X-Role", "admin").WithHeader("Authorization", "Bearer token").
+		Expect().Status(iris.StatusOK).Body().Equal("kataras")
+	if guardUser != nil {
+		t.Fatalf("expected the guard to run before the auth middleware but it got the user %v", guardUser)
+	}
+}
+
+func TestFullRequestURIBehindProxy(t *testing.T) {
+	newApp := func(configurators ...iris.Configurator) *iris.Application {
+		app := iris.New()
+		app.Configure(iris.WithSSLProxyHeader("X-Forwarded-Proto", "https"), iris.WithHostProxyHeader("X-Forwarded-Host"))
+		app.Configure(configurators...)
+		app.Get("/uri", func(ctx context.Context) {
+			ctx.Writef("%s %t %s", context.Scheme(ctx), context.IsTLS(ctx), context.FullRequestURI(ctx))
+		})
+		if err := app.Build(); err != nil {
+			t.Fatal(err)
+		}
+		return app
+	}
+
+	tests := []struct {
+		app        *iris.Application
+		remoteAddr string
+		forwarded  bool
+		expected   string
+	}{
+		{newApp(iris.WithTrustedProxies("10.0.0.0/8")), "10.0.0.2:1234", false, "http false http://localhost:8080/uri?q=1"},
+		{newApp(iris.WithTrustedProxies("10.0.0.0/8")), "10.0.0.2:1234", true, "https true https://mydomain.com/uri?q=1"},
+		// from a client, not the proxy.
+		{newApp(iris.WithTrustedProxies("10.0.0.0/8")), "203.0.113.7:1234", true, "http false http://localhost:8080/uri?q=1"},
+		// no trusted proxies, the forwarded headers are never trusted.
+		{newApp(), "10.0.0.2:1234", true, "http false http://localhost:8080/uri?q=1"},
+	}
+
+	for i, tt := range tests {
+		rec := stdhttptest.NewRecorder()
+		req := stdhttptest.NewRequest(iris.MethodGet, "http://localhost:8080/uri?q=1", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.forwarded {
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Forwarded-Host", "mydomain.com, proxy.local")
+		}
+
+		tt.app.ServeHTTP(rec, req)
+		if got := rec.Body.String(); got != tt.expected {
+			t.Fatalf("[%d] expected %q but got %q", i, tt.expected, got)
+		}
+	}
+}
+
+func TestRoutePredicates(t *testing.T) {
+	app := iris.New()
+	app.Post("/things", func(ctx context.Context) {
+		ctx.WriteString("json")
+	}).ContentType(context.ContentJSONHeaderValue)
+	app.Post("/things", func(ctx context.Context) {
+		ctx.WriteString("multipart")
+	}).ContentType("multipart/form-data")
+
+	app.Get("/things", func(ctx context.Context) {
+		ctx.WriteString("v1")
+	})
+	app.Get("/things", func(ctx context.Context) {
+		ctx.WriteString("v2")
+	}).Headers("X-API-Version", "2")
+	e := httptest.New(t, app)
+
+	e.POST("/things").WithJSON(map[string]string{"name": "thing"}).Expect().Status(iris.StatusOK).Body().Equal("json")
+	e.POST("/things").WithMultipart().WithFormField("name", "thing").Expect().Status(iris.StatusOK).Body().Equal("multipart")
+	e.POST("/things").WithFormField("name", "thing").Expect().Status(iris.StatusNotFound)
+
+	e.GET("/things").Expect().Status(iris.StatusOK).Body().Equal("v1")
+	e.GET("/things").WithHeader("X-API-Version", "2").Expect().Status(iris.StatusOK).Body().Equal("v2")
+	e.GET("/things").WithHeader("X-API-Version", "3").Expect().Status(iris.StatusOK).Body().Equal("v1")
+
+	// duplicate without predicates, it's a build error and the first registered one is kept.
+	app.Get("/things", func(ctx context.Context) {
+		ctx.WriteString("duplicate")
+	})
+
+	err := app.RefreshRouter()
+	if err == nil || !strings.Contains(err.Error(), "route conflict ("+string(router.RouteConflictDuplicate)+")") {
+		t.Fatalf("expected a build error of the duplicate route but got: %v", err)
+	}
+
+	e.GET("/things").Expect().Status(iris.StatusOK).Body().Equal("v1")
+}
+
+func TestPartyBuffered(t *testing.T) {
+	app := iris.New()
+	pages := app.Party("/pages").Buffered(true)
+	pages.Get("/late", func(ctx context.Context) {
+		ctx.WriteString("rendered")
+		// set after the body's write, it's still sent because the response is buffered.
+		ctx.Header("X-Late", "value")
+		ctx.StatusCode(iris.StatusAccepted)
+	})
+	pages.Get("/unbuffered", func(ctx context.Context) {
+		ctx.Buffered(false)
+		ctx.WriteString("streamed")
+		ctx.StatusCode(iris.StatusAccepted)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/pages/late").Expect().Status(iris.StatusAccepted).
+		Header("X-Late").Equal("value")
+	e.GET("/pages/late").Expect().Body().Equal("rendered")
+	e.GET("/pages/unbuffered").Expect().Status(iris.StatusOK).Body().Equal("streamed")
+}
+
+func TestSafeRedirect(t *testing.T) {
+	app := iris.New()
+	app.Configure(iris.WithRestrictRelativeRedirects("*.trusted.com"))
+	app.Logger().SetLevel("disable")
+	app.Get("/login", func(ctx context.Context) {
+		ctx.Redirect(ctx.URLParam("return_to"))
+	})
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		to       string
+		expected string
+	}{
+		{"/profile?tab=1", "/profile?tab=1"},
+		{"http://example.com/profile", "http://example.com/profile"},
+		{"https://api.trusted.com/cb", "https://api.trusted.com/cb"},
+		{"https://evil.com", "/"},
+		{"//evil.com", "/"},
+		{"/\\evil.com", "/"},
+		{"javascript:alert(1)", "/"},
+	}
+
+	for i, tt := range tests {
+		rec := stdhttptest.NewRecorder()
+		req := stdhttptest.NewRequest(iris.MethodGet, "/login?return_to="+url.QueryEscape(tt.to), nil)
+		app.ServeHTTP(rec, req)
+		if got := rec.Header().Get("Location"); got != tt.expected {
+			t.Fatalf("[%d] expected location '%s' but got '%s'", i, tt.expected, got)
+		}
+	}
+}
+
+func TestMethodNotAllowedAllowHeader(t *testing.T) {
+	app := iris.New()
+	app.Configure(iris.WithFireMethodNotAllowed)
+	h := func(ctx context.Context) {}
+	app.Get("/users/{id:uint64}", h)
+	app.Put("/users/{id:uint64}", h)
+	app.Delete("/users/{id:uint64}", h)
+	app.Post("/users", h)
+	app.Get("/methods/{p:path}", func(ctx context.Context) {
+		ctx.Writef("%v %s", ctx.Application().AllowedMethods(ctx, "/users/42"), ctx.Params().Get("p"))
+	})
+
+	e := httptest.New(t, app)
+	e.POST("/users/42").Expect().Status(iris.StatusMethodNotAllowed).
+		Header("Allow").Equal("GET, PUT, DELETE")
+	e.GET("/users").Expect().Status(iris.StatusMethodNotAllowed).
+		Header("Allow").Equal("POST")
+	e.GET("/methods/keep").Expect().Status(iris.StatusOK).Body().Equal("[GET PUT DELETE] keep")
+}
@@ -0,0 +1,138 @@
+// black-box testing
+package router_test
+
+import (
+	stdhttptest "net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+	recovermiddleware "github.com/kataras/iris/middleware/recover"
+
+	"github.com/kataras/iris/httptest"
+)
+
+func TestRouteTracing(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		spans []context.SpanData
+	)
+
+	app := iris.New()
+	app.Tracer(context.NewTracer(func(s context.SpanData) {
+		mu.Lock()
+		spans = append(spans, s)
+		mu.Unlock()
+	}))
+
+	api := app.Party("/api")
+	api.Get("/user/{id:uint64}", func(ctx context.Context) {
+		ctx.WriteString(context.GetSpan(ctx).SpanContext().TraceIDString())
+	})
+	api.Get("/fail", func(ctx context.Context) {
+		context.GetSpan(ctx).RecordError(errors.New("database is down"))
+		ctx.StatusCode(iris.StatusInternalServerError)
+	})
+
+	e := httptest.New(t, app)
+
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	e.GET("/api/user/42").WithHeader("traceparent", parent).Expect().
+		Status(iris.StatusOK).Body().Equal("4bf92f3577b34da6a3ce929d0e0e4736")
+	e.GET("/api/user/43").Expect().Status(iris.StatusOK)
+	e.GET("/api/fail").Expect().Status(iris.StatusInternalServerError)
+	e.GET("/api/notfound").Expect().Status(iris.StatusNotFound)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if expected, got := 3, len(spans); expected != got {
+		t.Fatalf("expected %d spans but got %d", expected, got)
+	}
+
+	for i, s := range spans[:2] {
+		if expected, got := "GET /api/user/{id:uint64}", s.Name; expected != got {
+			t.Fatalf("[%d] expected span name '%s' but got '%s'", i, expected, got)
+		}
+		if expected, got := iris.StatusOK, s.Attributes["http.status_code"]; expected != got {
+			t.Fatalf("[%d] expected status code attribute %v but got %v", i, expected, got)
+		}
+		if s.Status != context.SpanStatusUnset || !s.SpanContext.IsValid() {
+			t.Fatalf("[%d] expected a valid span without status but got %#v", i, s)
+		}
+	}
+
+	if got := spans[0].Parent.SpanIDString(); got != "00f067aa0ba902b7" {
+		t.Fatalf("expected the remote parent span but got '%s'", got)
+	}
+	if spans[1].Parent.IsValid() || spans[1].SpanContext.TraceID == spans[0].SpanContext.TraceID {
+		t.Fatalf("expected a new trace without a parent")
+	}
+
+	if fail := spans[2]; fail.Status != context.SpanStatusError || len(fail.Errors) != 1 {
+		t.Fatalf("expected an error span with a recorded error but got %#v", fail)
+	}
+}
+
+func TestRouteTracingPanic(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		spans []context.SpanData
+	)
+
+	app := iris.New()
+	app.Tracer(context.NewTracer(func(s context.SpanData) {
+		mu.Lock()
+		spans = append(spans, s)
+		mu.Unlock()
+	}))
+
+	app.Get("/recovered", recovermiddleware.New(), func(ctx context.Context) {
+		panic("recovered panic")
+	})
+	app.Get("/panic", func(ctx context.Context) {
+		panic("unrecovered panic")
+	})
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := stdhttptest.NewRecorder()
+	app.ServeHTTP(w, stdhttptest.NewRequest("GET", "/recovered", nil))
+	if expected, got := iris.StatusInternalServerError, w.Code; expected != got {
+		t.Fatalf("expected status code %d but got %d", expected, got)
+	}
+
+	func() {
+		defer func() {
+			if v := recover(); v != "unrecovered panic" {
+				t.Fatalf("expected the panic to be re-panicked but got %v", v)
+			}
+		}()
+		app.ServeHTTP(stdhttptest.NewRecorder(), stdhttptest.NewRequest("GET", "/panic", nil))
+	}()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if expected, got := 2, len(spans); expected != got {
+		t.Fatalf("expected %d spans but got %d", expected, got)
+	}
+
+	for i, s := range spans {
+		if s.Status != context.SpanStatusError || len(s.Errors) != 1 {
+			t.Fatalf("[%d] expected an error span with the recorded panic but got %#v", i, s)
+		}
+		if expected, got := iris.StatusInternalServerError, s.Attributes["http.status_code"]; expected != got {
+			t.Fatalf("[%d] expected status code attribute %v but got %v", i, expected, got)
+		}
+	}
+
+	if !strings.Contains(spans[1].Errors[0].Error(), "unrecovered panic") || !strings.Contains(spans[1].StatusDescription, "panic") {
+		t.Fatalf("expected the unrecovered panic to be recorded but got %#v", spans[1])
+	}
+}
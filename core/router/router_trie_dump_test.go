@@ -0,0 +1,59 @@
+package router_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
+)
+
+func TestDumpTrie(t *testing.T) {
+	app := iris.New()
+	h := func(ctx context.Context) {}
+	app.Get("/", h).Name = "index"
+	app.Get("/users/me", h).Name = "users.me"
+	app.Get("/users/{id:int}", h).Name = "users.get"
+	app.Get("/files/{p:path}", h)
+	app.Post("/users", h).Name = "users.create"
+	app.Party("admin.").Get("/", h).Name = "admin"
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err := app.DumpTrie(&b, router.TrieDumpText); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `GET
+├── /  [/] index
+├── files
+│   └── *p  [/files/*p] GET/files/{p:path}
+└── users
+    ├── me  [/users/me] users.me
+    └── :id  [/users/:id] users.get
+POST
+└── users  [/users] users.create
+GET admin.
+└── /  [/] admin
+`
+	if got := b.String(); got != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	b.Reset()
+	if err := app.DumpTrie(&b, router.TrieDumpDot); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); !strings.HasPrefix(got, "digraph routes {") || !strings.Contains(got, `":id\n/users/:id\nusers.get"`) {
+		t.Fatalf("unexpected dot output:\n%s", got)
+	}
+
+	if err := app.DumpTrie(&b, "yaml"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,69 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestRouterTriePrecedence(t *testing.T) {
+	app := iris.New()
+	writeRoute := func(name string) context.Handler {
+		return func(ctx context.Context) {
+			ctx.WriteString(name)
+			ctx.Params().Visit(func(key, value string) {
+				ctx.WriteString(" " + key + "=" + value)
+			})
+		}
+	}
+
+	// static, named parameter and wildcard siblings.
+	app.Get("/users/new", writeRoute("new"))
+	app.Get("/users/{id}", writeRoute("id"))
+	app.Get("/users/{id}/edit", writeRoute("edit"))
+	app.Get("/users/{p:path}", writeRoute("wildcard"))
+	// optional trailing wildcard.
+	app.Get("/docs/{p:path}", writeRoute("docs"))
+	// mid-path wildcard.
+	app.Get("/files/{p:path}/edit", writeRoute("files-edit"))
+	app.Get("/files/{p:path}", writeRoute("files"))
+	// a static path which is a prefix of a parameterized one.
+	app.Get("/a/{id}", writeRoute("a-id"))
+	app.Get("/a/b/c", writeRoute("a-b-c"))
+
+	e := httptest.New(t, app)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		// the static segment wins.
+		{"/users/new", "new"},
+		// then the named parameter.
+		{"/users/42", "id id=42"},
+		// the static segment is not a dead end, the named parameter is tried next.
+		{"/users/new/edit", "edit id=new"},
+		{"/users/42/edit", "edit id=42"},
+		// and the wildcard last.
+		{"/users/42/posts/1", "wildcard p=42/posts/1"},
+		{"/users/new/other", "wildcard p=new/other"},
+		{"/docs", "docs p="},
+		{"/docs/intro", "docs p=intro"},
+		{"/docs/guide/routing", "docs p=guide/routing"},
+		{"/files/a/b/edit", "files-edit p=a/b"},
+		{"/files/a/edit", "files-edit p=a"},
+		{"/files/a/b", "files p=a/b"},
+		{"/files/edit", "files p=edit"},
+		{"/a/b", "a-id id=b"},
+		{"/a/b/c", "a-b-c"},
+	}
+
+	for _, tt := range tests {
+		e.GET(tt.path).Expect().Status(iris.StatusOK).Body().Equal(tt.expected)
+	}
+
+	e.GET("/a/b/d").Expect().Status(iris.StatusNotFound)
+	e.GET("/a").Expect().Status(iris.StatusNotFound)
+}
@@ -0,0 +1,144 @@
+package router_test
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/http"
+	stdhttptest "net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/websocket"
+
+	gorilla "github.com/gorilla/websocket"
+)
+
+func TestOnUpgrade(t *testing.T) {
+	app := iris.New()
+	app.Get("/", func(ctx context.Context) {
+		ctx.WriteString("routed:" + ctx.GetHeader("Upgrade"))
+	})
+	app.OnUpgrade("echo", func(ctx context.Context, conn net.Conn, rw *bufio.ReadWriter) {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		rw.WriteString(ctx.Path() + ":" + line)
+		rw.Flush()
+	})
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := stdhttptest.NewServer(app)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.Write([]byte("GET /any HTTP/1.1\r\nHost: localhost\r\nUpgrade: ECHO/1, other\r\nConnection: keep-alive, Upgrade\r\n\r\nhello\n"))
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != iris.StatusSwitchingProtocols {
+		t.Fatalf("expected status code %d but got %d", iris.StatusSwitchingProtocols, resp.StatusCode)
+	}
+	if expected, got := "ECHO/1", resp.Header.Get("Upgrade"); expected != got {
+		t.Fatalf("expected upgrade header '%s' but got '%s'", expected, got)
+	}
+
+	line, err := br.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "/any:hello\n"; line != expected {
+		t.Fatalf("expected '%s' but got '%s'", expected, line)
+	}
+
+	// not registered protocols are served by the router.
+	req, _ := http.NewRequest(iris.MethodGet, srv.URL+"/", nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if expected, got := "routed:websocket", string(b); expected != got {
+		t.Fatalf("expected body '%s' but got '%s'", expected, got)
+	}
+}
+
+func TestContextUpgrade(t *testing.T) {
+	ws := websocket.New(websocket.Config{})
+
+	app := iris.New()
+	app.Get("/chat/{room}", func(ctx context.Context) {
+		ctx.Values().Set("user", "kataras")
+		ctx.Next()
+	}, func(ctx context.Context) {
+		c, err := ctx.Upgrade(ws)
+		if err != nil {
+			return
+		}
+
+		conn := c.(websocket.Connection)
+		conn.OnMessage(func(data []byte) {
+			// the route's parameters and values are still available after the hijack.
+			conn.Write(websocket.TextMessage, []byte(ctx.Params().Get("room")+":"+ctx.Values().GetString("user")+":"+string(data)))
+		})
+		conn.Wait()
+	})
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := stdhttptest.NewServer(app)
+	defer srv.Close()
+
+	conn, resp, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/chat/room1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if resp.StatusCode != iris.StatusSwitchingProtocols {
+		t.Fatalf("expected status code %d but got %d", iris.StatusSwitchingProtocols, resp.StatusCode)
+	}
+
+	if err = conn.WriteMessage(gorilla.TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, got := "room1:kataras:hello", string(data); expected != got {
+		t.Fatalf("expected message '%s' but got '%s'", expected, got)
+	}
+
+	// a request without the "Upgrade" header.
+	res, err := http.Get(srv.URL + "/chat/room1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != iris.StatusUpgradeRequired {
+		t.Fatalf("expected status code %d but got %d", iris.StatusUpgradeRequired, res.StatusCode)
+	}
+	if expected, got := "websocket", res.Header.Get("Upgrade"); expected != got {
+		t.Fatalf("expected upgrade header '%s' but got '%s'", expected, got)
+	}
+}
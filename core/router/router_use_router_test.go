@@ -0,0 +1,57 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+)
+
+func TestUseRouter(t *testing.T) {
+	app := iris.New()
+
+	trace := func(s string) context.Handler {
+		return func(ctx context.Context) {
+			ctx.Values().Set("trace", ctx.Values().GetString("trace")+s+"|")
+			ctx.Next()
+		}
+	}
+	writeTrace := func(s string) context.Handler {
+		return func(ctx context.Context) {
+			ctx.WriteString(ctx.Values().GetString("trace") + s)
+		}
+	}
+
+	users := app.Party("/users")
+	users.UseRouter(trace("users"))
+	users.Get("/", writeTrace("list"))
+
+	// registered after the party's one but it's executed first.
+	app.UseRouter(trace("root1"), trace("root2"))
+	app.Use(trace("use"))
+	app.Get("/", writeTrace("index"))
+
+	admin := app.Party("admin.")
+	admin.UseRouter(func(ctx context.Context) {
+		if ctx.URLParam("token") != "secret" {
+			ctx.StatusCode(iris.StatusForbidden)
+			return
+		}
+		ctx.Next()
+	})
+	admin.Get("/", writeTrace("admin"))
+
+	app.OnErrorCode(iris.StatusNotFound, writeTrace("not found"))
+
+	e := httptest.New(t, app)
+	e.GET("/").Expect().Status(iris.StatusOK).Body().Equal("root1|root2|use|index")
+	e.GET("/users").Expect().Status(iris.StatusOK).Body().Equal("root1|root2|users|list")
+	// the pre-routing handlers run on not found too.
+	e.GET("/users/notfound").Expect().Status(iris.StatusNotFound).Body().Equal("root1|root2|users|not found")
+	e.GET("/usersx").Expect().Status(iris.StatusNotFound).Body().Equal("root1|root2|not found")
+
+	e.GET("/").WithURL("http://admin.mydomain.com").Expect().Status(iris.StatusForbidden)
+	e.GET("/").WithURL("http://admin.mydomain.com").WithQueryString("token=secret").
+		Expect().Status(iris.StatusOK).Body().Equal("root1|root2|use|admin")
+}
@@ -139,8 +139,8 @@ func TestRouterWildcardRootManyAndRootStatic(t *testing.T) {
 			{"GET", "", "/", iris.StatusOK, same_as_request_path},
 		}},
 		{"GET", "/other/{paramother:path}", h2, []testRouteRequest{
-			// OK and not h2 because of the root wildcard.
-			{"GET", "", "/other", iris.StatusOK, same_as_request_path},
+			// h2 and not the root wildcard, the trailing wildcard is optional and the longest prefix wins.
+			{"GET", "", "/other", iris.StatusForbidden, same_as_request_path},
 			{"GET", "", "/other/wildcard", iris.StatusForbidden, same_as_request_path},
 			{"GET", "", "/other/wildcard/here", iris.StatusForbidden, same_as_request_path},
 		}},
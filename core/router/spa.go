@@ -1,6 +1,7 @@
 package router
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/kataras/iris/context"
@@ -109,7 +110,11 @@ func (s *SPABuilder) Handler(ctx context.Context) {
 				ctx.NotFound()
 				return
 			}
-			localRedirect(ctx, "."+s.Root)
+			if s.Root == "/" {
+				localRedirect(ctx, "./")
+			} else {
+				localRedirect(ctx, s.Root)
+			}
 			// s.Root should be manually registered to a route
 			// (not always, only if custom handler used).
 			// We don't setup an index handler here,
@@ -165,3 +170,116 @@ func (s *SPABuilder) Handler(ctx context.Context) {
 
 	}
 }
+
+// Exclude adds an `AssetValidator` which fires 404 for the paths under the "prefixes",
+// i.e the "/api", so the unmatched paths of an API are not served by the single page application.
+func (s *SPABuilder) Exclude(prefixes ...string) *SPABuilder {
+	s.AssetValidators = append(s.AssetValidators, func(path string) bool {
+		for _, prefix := range prefixes {
+			prefix = strings.TrimRight(prefix, "/")
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return false
+			}
+		}
+		return true
+	})
+	return s
+}
+
+// spaEntry is a single page application of a Party, see `APIBuilder#SPA`.
+type spaEntry struct {
+	api       *APIBuilder
+	subdomain string
+	// prefix is the static path prefix of the Party, without the trailing slash, empty for the root.
+	prefix  string
+	builder *SPABuilder
+}
+
+// spaFallback is a built `spaEntry`, with the Party's middleware and done handlers.
+type spaFallback struct {
+	routerMiddlewareEntry
+}
+
+// spaRegistry keeps the single page applications of all parties, it's shared between them.
+type spaRegistry struct {
+	entries []*spaEntry
+}
+
+// SPA registers a single page application to this Party, it serves the GET and HEAD requests
+// under the Party's path which do not match a route, so the routes, i.e the API ones, are kept intact
+// and the path correction (trailing slash) is applied before it.
+//
+// The "assetHandler" serves the files, i.e a `StaticFSHandler` or a `StaticHandler`,
+// the Party's path prefix is stripped from the request path before it.
+// If it fails, i.e the file does not exist, it's executed again with the Party's root path,
+// so the index.html of the client-side routes is served.
+//
+// The Party's middleware and done handlers are executed as well,
+// the returned `SPABuilder` can configure the index names and the asset validators, see `SPABuilder#Exclude` too.
+// The most specific Party's application is served if more than one match.
+//
+// Usage:
+// app.Get("/api/users", listUsers)
+// app.SPA(app.StaticHandler("./public", false, false)).Exclude("/api")
+// admin := app.Party("/admin")
+// admin.SPA(router.StaticFSHandler(adminFS)).AddIndexName("index.html")
+// 单页应用模式: 只处理未匹配到路由的GET/HEAD请求
+func (api *APIBuilder) SPA(assetHandler context.Handler) *SPABuilder {
+	subdomain, path := api.relativePath, "/"
+	if strings.Contains(subdomain, "/") {
+		subdomain, path = splitSubdomainAndPath(api.relativePath)
+	}
+	if idx := strings.IndexByte(path, '{'); idx >= 0 {
+		path = path[:idx]
+	}
+	path = strings.TrimRight(path, "/")
+
+	if path != "" && assetHandler != nil {
+		assetHandler = StripPrefix(path, assetHandler)
+	}
+
+	s := NewSPABuilder(assetHandler)
+	s.Root = path + "/"
+
+	api.spa.entries = append(api.spa.entries, &spaEntry{
+		api:       api,
+		subdomain: subdomain,
+		prefix:    path,
+		builder:   s,
+	})
+
+	return s
+}
+
+// getSPAFallbacks returns the single page applications of all parties,
+// the most specific first.
+func (api *APIBuilder) getSPAFallbacks() []*spaFallback {
+	fallbacks := make([]*spaFallback, 0, len(api.spa.entries))
+	for _, e := range api.spa.entries {
+		handlers := joinHandlers(e.api.beginGlobalHandlers, e.api.middleware)
+		handlers = joinHandlers(handlers, context.Handlers{e.builder.Handler})
+		handlers = joinHandlers(handlers, e.api.doneHandlers)
+		handlers = joinHandlers(handlers, e.api.doneGlobalHandlers)
+
+		fallbacks = append(fallbacks, &spaFallback{routerMiddlewareEntry{
+			subdomain: e.subdomain,
+			prefix:    e.prefix,
+			handlers:  handlers,
+		}})
+	}
+
+	sort.SliceStable(fallbacks, func(i, j int) bool {
+		if (fallbacks[i].subdomain == "") != (fallbacks[j].subdomain == "") {
+			return fallbacks[i].subdomain != ""
+		}
+		return len(fallbacks[i].prefix) > len(fallbacks[j].prefix)
+	})
+
+	return fallbacks
+}
+
+// spaFallbackProvider is implemented by the `RoutesProvider`s which
+// register single page applications, i.e the `APIBuilder`.
+type spaFallbackProvider interface {
+	getSPAFallbacks() []*spaFallback
+}
@@ -2,6 +2,8 @@ package router
 
 import (
 	"net/http" // just for status codes
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/kataras/iris/context"
@@ -25,12 +27,46 @@ type ErrorCodeHandler struct {
 // it's being wrapped to make sure that the handler
 // will render(给予) correctly.
 func (ch *ErrorCodeHandler) Fire(ctx context.Context) {
+	if !prepareErrorResponse(ctx, ch.StatusCode) {
+		return
+	}
+
+	// ctx.StopExecution() // not uncomment this, is here to remember why to.
+	// note for me: I don't stopping the execution of the other handlers
+	// because may the user want to add a fallback error code
+	// i.e
+	// users := app.Party("/users")
+	// users.Done(func(ctx context.Context){ if ctx.StatusCode() == 400 { /*  custom error code for /users */ }})
+	// 这里不调用 .StopExecution() ，是因为有些用户可能想有一个错误回调
+	// use .HandlerIndex
+	// that sets the current handler index to zero
+	// in order to:
+	// ignore previous runs that may changed the handler index,
+	// via ctx.Next or ctx.StopExecution, if any.
+	//
+	// use .Do
+	// that overrides the existing handlers and sets and runs these error handlers.
+	// in order to:
+	// ignore the route's after-handlers, if any.
+	// 这里将 handleIndex=0 ，且重新将此时的Context的handlers进行设置
+	ctx.HandlerIndex(0)
+	ctx.Do(ch.Handlers)
+}
+
+// prepareErrorResponse resets the response body, if possible, before an error response,
+// it reports whether the error response can be written.
+func prepareErrorResponse(ctx context.Context, statusCode int) bool {
 	// if we can reset the body
 	// 目的是将响应体数据清空
 	// todo 这个 IsRecording() 还不了解 ？？
 	if w, ok := ctx.IsRecording(); ok {
+		if w.IsPassThrough() {
+			// the status code and a part of the body are sent already.
+			return false
+		}
+
 		if statusCodeSuccessful(w.StatusCode()) { // if not an error status code
-			w.WriteHeader(ch.StatusCode) // then set it manually here, otherwise it should be setted via ctx.StatusCode(...)
+			w.WriteHeader(statusCode) // then set it manually here, otherwise it should be setted via ctx.StatusCode(...)
 		}
 		// reset if previous content and it's recorder, keep the status code.
 		w.ClearHeaders()
@@ -48,30 +84,31 @@ func (ch *ErrorCodeHandler) Fire(ctx context.Context) {
 		// 这里表示如果不能重置body，且body还有数据，说明状态码数据已经返回，如果<=0那本来就不用管了
 		// todo 调用这个的时候，默认的应该已经Reset了，或者是说默认是IsRecording()那个流程？？
 		if ctx.ResponseWriter().Written() > 0 { // != -1, rel: context/context.go#EndRequest
-			return
+			return false
 		}
 	}
 
-	// ctx.StopExecution() // not uncomment this, is here to remember why to.
-	// note for me: I don't stopping the execution of the other handlers
-	// because may the user want to add a fallback error code
-	// i.e
-	// users := app.Party("/users")
-	// users.Done(func(ctx context.Context){ if ctx.StatusCode() == 400 { /*  custom error code for /users */ }})
-	// 这里不调用 .StopExecution() ，是因为有些用户可能想有一个错误回调
-	// use .HandlerIndex
-	// that sets the current handler index to zero
-	// in order to:
-	// ignore previous runs that may changed the handler index,
-	// via ctx.Next or ctx.StopExecution, if any.
-	//
-	// use .Do
-	// that overrides the existing handlers and sets and runs these error handlers.
-	// in order to:
-	// ignore the route's after-handlers, if any.
-	// 这里将 handleIndex=0 ，且重新将此时的Context的handlers进行设置
-	ctx.HandlerIndex(0)
-	ctx.Do(ch.Handlers)
+	return true
+}
+
+// ErrorRenderer is the interface which can be used to fully control the error responses,
+// i.e content negotiation, templating or localization, see `APIBuilder#SetErrorRenderer`.
+// It replaces the registered error code handlers.
+//
+// The response body is already reset, if possible, before the `RenderError`.
+// 可插拔的错误响应渲染器
+type ErrorRenderer interface {
+	// RenderError writes the error response of the current error status code,
+	// i.e ctx.GetStatusCode() == 404.
+	RenderError(ctx context.Context)
+}
+
+// ErrorRendererFunc is the function form of the `ErrorRenderer`.
+type ErrorRendererFunc func(ctx context.Context)
+
+// RenderError calls the "fn" itself.
+func (fn ErrorRendererFunc) RenderError(ctx context.Context) {
+	fn(ctx)
 }
 
 // 修改ErrorCodeHandler的 handler链
@@ -88,6 +125,20 @@ func (ch *ErrorCodeHandler) updateHandlers(handlers context.Handlers) {
 // 包含所有的不同HTTP状态码的 ErrorCodeHandler
 type ErrorCodeHandlers struct {
 	handlers []*ErrorCodeHandler
+	// renderer, if not nil, replaces the handlers, see `SetRenderer`.
+	renderer ErrorRenderer
+	// scopes are the error code handlers of the path prefixes, i.e of the mounted blueprints, see `Scope`.
+	scopes []*errorCodeScope
+}
+
+// errorCodeScope are the error code handlers of the requests under a path prefix.
+type errorCodeScope struct {
+	prefix   string
+	handlers *ErrorCodeHandlers
+}
+
+func (sc *errorCodeScope) matches(path string) bool {
+	return sc.prefix == "/" || path == sc.prefix || strings.HasPrefix(path, sc.prefix+"/")
 }
 
 // 默认的状态码有404、405、500
@@ -170,9 +221,72 @@ func (s *ErrorCodeHandlers) Fire(ctx context.Context) {
 	if statusCodeSuccessful(statusCode) {
 		return
 	}
+
+	// the longest path prefix first, its handlers take precedence,
+	// the rest status codes are fired by these handlers.
+	// The route's path is preferred, the request's one may be modified by its handlers, i.e `StripPrefix`.
+	path := ctx.Path()
+	if route := ctx.GetCurrentRoute(); route != nil {
+		path = route.Path()
+	}
+	for _, sc := range s.scopes {
+		if !sc.matches(path) {
+			continue
+		}
+
+		if ch := sc.handlers.Get(statusCode); ch != nil {
+			ch.Fire(ctx)
+			return
+		}
+	}
+
+	if s.renderer != nil {
+		if prepareErrorResponse(ctx, statusCode) {
+			s.renderer.RenderError(ctx)
+		}
+		return
+	}
+
 	ch := s.Get(statusCode)
 	if ch == nil {
 		ch = s.Register(statusCode, statusText(statusCode))
 	}
 	ch.Fire(ctx)
 }
+
+// SetRenderer sets an error renderer which replaces the registered handlers,
+// a nil "renderer" restores the handlers.
+func (s *ErrorCodeHandlers) SetRenderer(renderer ErrorRenderer) {
+	s.renderer = renderer
+}
+
+// Renderer returns the error renderer, if any, see `SetRenderer`.
+func (s *ErrorCodeHandlers) Renderer() ErrorRenderer {
+	return s.renderer
+}
+
+// Scope returns the error code handlers of the requests under the "pathPrefix", i.e "/blog",
+// they take precedence over these handlers and the `ErrorRenderer`
+// and the status codes without a handler of the scope fall back to these ones.
+// It's used by the `APIBuilder#MountBlueprint` to isolate the error handlers of a blueprint.
+func (s *ErrorCodeHandlers) Scope(pathPrefix string) *ErrorCodeHandlers {
+	pathPrefix = strings.TrimRight(pathPrefix, "/")
+	if pathPrefix == "" {
+		pathPrefix = "/"
+	}
+
+	for _, sc := range s.scopes {
+		if sc.prefix == pathPrefix {
+			return sc.handlers
+		}
+	}
+
+	sc := &errorCodeScope{prefix: pathPrefix, handlers: new(ErrorCodeHandlers)}
+	s.scopes = append(s.scopes, sc)
+	// keep the longest prefixes first.
+	sort.SliceStable(s.scopes, func(i, j int) bool {
+		return len(s.scopes[i].prefix) > len(s.scopes[j].prefix)
+	})
+
+	return sc.handlers
+}
@@ -3,7 +3,10 @@ package router_test
 
 import (
 	"bytes"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/kataras/iris"
@@ -71,3 +74,88 @@ func checkAndClearBuf(t *testing.T, buff *bytes.Buffer, expected string) {
 
 	buff.Reset()
 }
+
+func TestProblemErrorHandler(t *testing.T) {
+	app := iris.New()
+	app.OnAnyErrorCode(iris.ProblemHandler)
+	app.Get("/credit", func(ctx context.Context) {
+		ctx.Problem(iris.NewProblem().Type("https://example.com/probs/out-of-credit").
+			Status(iris.StatusForbidden).Detail("your current balance is 30").Key("balance", 30))
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/credit").Expect().Status(iris.StatusForbidden).ContentType(context.ContentProblemJSONHeaderValue).
+		Body().Equal(`{"balance":30,"detail":"your current balance is 30","status":403,"title":"Forbidden","type":"https://example.com/probs/out-of-credit"}`)
+
+	e.GET("/notfound").WithHeader("Accept", "application/xml").Expect().Status(iris.StatusNotFound).
+		ContentType(context.ContentProblemXMLHeaderValue).Body().
+		Equal(`<problem xmlns="urn:ietf:rfc:7807"><instance>/notfound</instance><status>404</status><title>Not Found</title><type>about:blank</type></problem>`)
+}
+
+func TestErrorRenderer(t *testing.T) {
+	app := iris.New()
+	app.OnErrorCode(iris.StatusNotFound, defaultErrHandler)
+	app.SetErrorRenderer(iris.ErrorRendererFunc(func(ctx context.Context) {
+		if ctx.GetHeader("Accept") == "text/plain" {
+			ctx.Writef("error %d", ctx.GetStatusCode())
+			return
+		}
+		ctx.JSON(iris.Map{"code": ctx.GetStatusCode()})
+	}))
+	app.Get("/recorded", func(ctx context.Context) {
+		ctx.Record()
+		ctx.WriteString("this should be reset")
+		ctx.StatusCode(iris.StatusBadRequest)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/notfound").Expect().Status(iris.StatusNotFound).JSON().Equal(iris.Map{"code": 404})
+	e.GET("/recorded").WithHeader("Accept", "text/plain").Expect().Status(iris.StatusBadRequest).
+		Body().Equal("error 400")
+
+	// restore the error code handlers.
+	app.SetErrorRenderer(nil)
+	e.GET("/notfound").Expect().Status(iris.StatusNotFound).Body().Equal(http.StatusText(iris.StatusNotFound))
+}
+
+func TestSetErrorView(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "errors"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "errors", "error.html"),
+		[]byte("{{.StatusCode}} {{.StatusText}} {{.Path}} {{.RequestID}} {{.Custom}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := iris.New()
+	app.RegisterView(iris.HTML(dir, ".html"))
+	app.SetErrorView(iris.StatusNotFound, "errors/error.html")
+	app.SetErrorView(iris.StatusInternalServerError, "errors/error.html")
+	app.SetErrorView(iris.StatusBadRequest, "errors/missing.html")
+	app.Get("/fail", func(ctx context.Context) {
+		ctx.ViewData("Custom", "data")
+		ctx.StatusCode(iris.StatusInternalServerError)
+	})
+	app.Get("/bad", func(ctx context.Context) {
+		ctx.StatusCode(iris.StatusBadRequest)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/notfound").WithHeader("X-Request-Id", "42").Expect().Status(iris.StatusNotFound).
+		Body().Equal("404 Not Found /notfound 42 ")
+	e.GET("/fail").WithHeader("X-Request-Id", "43").Expect().Status(iris.StatusInternalServerError).
+		Body().Equal("500 Internal Server Error /fail 43 data")
+	// the template cannot be rendered, fallback to the status text.
+	e.GET("/bad").Expect().Status(iris.StatusBadRequest).
+		ContentType(context.ContentTextHeaderValue).Body().Equal(http.StatusText(iris.StatusBadRequest))
+}
+
+func TestSetErrorViewWithoutViewEngine(t *testing.T) {
+	app := iris.New()
+	app.SetErrorView(iris.StatusNotFound, "errors/error.html")
+
+	e := httptest.New(t, app)
+	e.GET("/notfound").Expect().Status(iris.StatusNotFound).
+		ContentType(context.ContentTextHeaderValue).Body().Equal(http.StatusText(iris.StatusNotFound))
+}
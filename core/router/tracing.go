@@ -0,0 +1,49 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kataras/iris/context"
+)
+
+// startRouteSpan starts the server span of the matched route through the application's tracer,
+// it's named after the route's template, so the spans of the same route are grouped, whatever its parameters are.
+func startRouteSpan(ctx context.Context, tracer context.Tracer) context.Span {
+	method, routePath := ctx.Method(), ctx.Path()
+	if route := ctx.GetCurrentRoute(); route != nil {
+		routePath = route.Tmpl().Src
+	}
+
+	span := tracer.Start(ctx, method+" "+routePath)
+	span.SetAttribute("http.method", method)
+	span.SetAttribute("http.route", routePath)
+	span.SetAttribute("http.target", ctx.Request().URL.RequestURI())
+	ctx.Values().Set(context.SpanContextKey, span)
+	return span
+}
+
+// endRouteSpan records the response's status code to the span and ends it, the 5xx responses are marked as errors.
+// A panic of the route's handlers, which is not recovered by them, is recorded as an error before it's re-panicked.
+func endRouteSpan(ctx context.Context, span context.Span) {
+	if v := recover(); v != nil {
+		err, ok := v.(error)
+		if !ok {
+			err = fmt.Errorf("%v", v)
+		}
+
+		span.RecordError(err)
+		span.SetAttribute("http.status_code", http.StatusInternalServerError)
+		span.SetStatus(context.SpanStatusError, "panic: "+err.Error())
+		span.End()
+		panic(v)
+	}
+
+	statusCode := ctx.GetStatusCode()
+	span.SetAttribute("http.status_code", statusCode)
+	if statusCode >= http.StatusInternalServerError {
+		span.SetStatus(context.SpanStatusError, strconv.Itoa(statusCode)+" "+http.StatusText(statusCode))
+	}
+	span.End()
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"strings"
+	"sync/atomic"
 
 	"github.com/kataras/iris/context"
 )
@@ -36,15 +37,49 @@ type trieNode struct {
 	//如果是叶子节点，代表这个叶子节点的完整的路径 187行
 	key string // if end == true then key is filled with the original value of the insertion's key.
 
-	// if key != "" && its parent has childWildcardParameter == true,
-	// we need it to track the static part for the closest-wildcard's parameter storage.
-	//如果key!=""且他的兄弟节点有动态路径，则保存最长的路径存储
-	staticKey string
-
 	// insert data.
 	//记录到当前的节点的路由
 	Handlers  context.Handlers
 	RouteName string
+	// Guard is the composed route's guards, if any, see `Route#Guard`.
+	Guard RouteGuard
+	// stats are the route's statistics, updated on each served request.
+	stats *routeStats
+
+	// predicated are the routes of the same path which are selected based on their predicates,
+	// evaluated by registration order, the above are used when none of them match.
+	predicated []*predicatedRoute
+
+	// swapped holds the Handlers which replaced the above at serve-time, see `Router#SwapRouteHandlers`.
+	swapped atomic.Value // context.Handlers
+}
+
+// handlers returns the current handlers of the node's route.
+func (tn *trieNode) handlers() context.Handlers {
+	if handlers, ok := tn.swapped.Load().(context.Handlers); ok {
+		return handlers
+	}
+
+	return tn.Handlers
+}
+
+// predicatedRoute is the insert data of a route with predicates, see `Route#Headers`.
+type predicatedRoute struct {
+	match     func(ctx context.Context) bool
+	Handlers  context.Handlers
+	RouteName string
+	Guard     RouteGuard
+	stats     *routeStats
+	swapped   atomic.Value // context.Handlers
+}
+
+// handlers returns the current handlers of the route.
+func (p *predicatedRoute) handlers() context.Handlers {
+	if handlers, ok := p.swapped.Load().(context.Handlers); ok {
+		return handlers
+	}
+
+	return p.Handlers
 }
 
 func newTrieNode() *trieNode {
@@ -79,21 +114,6 @@ func (tn *trieNode) addChild(s string, n *trieNode) {
 	tn.children[s] = n
 }
 
-//寻找到最上层的静态的动态路由中静态的部分，如果没有动态路由，则返回nil
-//这里动态路由是通过寻找接下来的第一个key为*的节点
-func (tn *trieNode) findClosestParentWildcardNode() *trieNode {
-	tn = tn.parent
-	for tn != nil {
-		if tn.childWildcardParameter {
-			return tn.getChild(WildcardParamStart)
-		}
-
-		tn = tn.parent
-	}
-
-	return nil
-}
-
 //返回当前key所代表的路径
 func (tn *trieNode) String() string {
 	return tn.key
@@ -117,6 +137,11 @@ type trie struct {
 	// subdomain is empty for default-hostname routes,
 	// ex: mysubdomain.
 	subdomain string
+
+	// static are the nodes of the static paths, without parameters, by their path,
+	// they're matched by a single lookup, without walking through the trie, see `search`.
+	// 静态路径的节点, 通过map直接查找, 不需要遍历trie
+	static map[string]*trieNode
 }
 
 func newTrie() *trie {
@@ -140,7 +165,7 @@ func slowPathSplit(path string) []string {
 }
 
 //handler.go中addRoute()中使用
-func (tr *trie) insert(path, routeName string, handlers context.Handlers) {
+func (tr *trie) insert(path, routeName string, handlers context.Handlers, guard RouteGuard, stats *routeStats, match func(ctx context.Context) bool) {
 	input := slowPathSplit(path)
 
 	n := tr.root
@@ -149,12 +174,14 @@ func (tr *trie) insert(path, routeName string, handlers context.Handlers) {
 	}
 
 	var paramKeys []string
+	dynamic := false
 
 	for _, s := range input {
 		//这里是拿到每个//之间的数据，判断第一个值是否是*或:来判断是否是动态路由
 		c := s[0]
 
 		if isParam, isWildcard := c == ParamStart[0], c == WildcardParamStart[0]; isParam || isWildcard {
+			dynamic = true
 			n.hasDynamicChild = true
 			paramKeys = append(paramKeys, s[1:]) // without : or *.
 
@@ -181,26 +208,203 @@ func (tr *trie) insert(path, routeName string, handlers context.Handlers) {
 		n = n.getChild(s)
 	}
 	//此时的n表示当前路径所对应的叶子节点
-	n.RouteName = routeName
-	n.Handlers = handlers
+	if match != nil {
+		n.predicated = append(n.predicated, &predicatedRoute{
+			match:     match,
+			Handlers:  handlers,
+			RouteName: routeName,
+			Guard:     guard,
+			stats:     stats,
+		})
+	} else {
+		n.RouteName = routeName
+		n.Handlers = handlers
+		n.Guard = guard
+		n.stats = stats
+	}
 	n.paramKeys = paramKeys
 	n.key = path
 	n.end = true
 
-	//todo 由于现在暂时不考虑静态路由，则先跳过
-	i := strings.Index(path, ParamStart)
-	if i == -1 {
-		i = strings.Index(path, WildcardParamStart)
+	if !dynamic && path != pathSep {
+		if tr.static == nil {
+			tr.static = make(map[string]*trieNode)
+		}
+		tr.static[path] = n
+	}
+}
+
+// find returns the node of the registered "path", as it was inserted, or nil.
+func (tr *trie) find(path string) *trieNode {
+	n := tr.root
+	for _, s := range slowPathSplit(path) {
+		switch s[0] {
+		case ParamStart[0]:
+			s = ParamStart
+		case WildcardParamStart[0]:
+			s = WildcardParamStart
+		}
+
+		if n = n.getChild(s); n == nil {
+			return nil
+		}
+	}
+
+	if !n.end {
+		return nil
+	}
+
+	return n
+}
+
+// clone returns a deep copy of the trie, the copy can be modified while the original is in use.
+func (tr *trie) clone() *trie {
+	c := *tr
+	c.root = tr.root.clone(nil)
+	c.static = nil
+	for path := range tr.static {
+		if c.static == nil {
+			c.static = make(map[string]*trieNode, len(tr.static))
+		}
+		c.static[path] = c.find(path)
+	}
+	return &c
+}
+
+// clone returns a deep copy of the node and its children, the route's data are shared.
+func (tn *trieNode) clone(parent *trieNode) *trieNode {
+	c := &trieNode{
+		parent:                 parent,
+		hasDynamicChild:        tn.hasDynamicChild,
+		childNamedParameter:    tn.childNamedParameter,
+		childWildcardParameter: tn.childWildcardParameter,
+		paramKeys:              tn.paramKeys,
+		end:                    tn.end,
+		key:                    tn.key,
+		Handlers:               tn.Handlers,
+		RouteName:              tn.RouteName,
+		Guard:                  tn.Guard,
+		stats:                  tn.stats,
+	}
+	if handlers, ok := tn.swapped.Load().(context.Handlers); ok {
+		c.swapped.Store(handlers)
+	}
+
+	for _, p := range tn.predicated {
+		cp := &predicatedRoute{match: p.match, Handlers: p.Handlers, RouteName: p.RouteName, Guard: p.Guard, stats: p.stats}
+		if handlers, ok := p.swapped.Load().(context.Handlers); ok {
+			cp.swapped.Store(handlers)
+		}
+		c.predicated = append(c.predicated, cp)
+	}
+
+	if len(tn.children) > 0 {
+		c.children = make(map[string]*trieNode, len(tn.children))
+		for s, child := range tn.children {
+			c.children[s] = child.clone(c)
+		}
+	}
+
+	return c
+}
+
+// remove removes the "routeName" route of the registered "path", as it was inserted,
+// the nodes which are left without routes and children are removed too.
+// It reports whether the route was found.
+func (tr *trie) remove(path, routeName string) bool {
+	n := tr.find(path)
+	if n == nil || !n.delete(routeName) {
+		return false
+	}
+
+	if !n.end {
+		delete(tr.static, path)
+	}
+
+	tr.hasRootSlash = tr.find(pathSep) != nil
+	tr.hasRootWildcard = tr.root.childWildcardParameter
+	return true
+}
+
+// delete removes the "routeName" route of the node, the route without predicates or a predicated one,
+// when the node is left without routes it's not a complete node anymore
+// and it's removed from its parent if it has no children, and so on for its parents.
+// It reports whether the route was found.
+// 从节点中删除路由, 没有路由以及子节点的节点也会从父节点中删除
+func (tn *trieNode) delete(routeName string) bool {
+	if tn.RouteName == routeName && tn.Handlers != nil {
+		tn.RouteName = ""
+		tn.Handlers = nil
+		tn.Guard = nil
+		tn.stats = nil
+		tn.swapped = atomic.Value{}
+	} else {
+		idx := -1
+		for i, p := range tn.predicated {
+			if p.RouteName == routeName {
+				idx = i
+				break
+			}
+		}
+
+		if idx == -1 {
+			return false
+		}
+
+		tn.predicated = append(tn.predicated[:idx], tn.predicated[idx+1:]...)
 	}
-	if i == -1 {
-		i = len(n.key)
+
+	if tn.Handlers != nil || len(tn.predicated) > 0 {
+		return true
+	}
+
+	tn.end = false
+	tn.key = ""
+	tn.paramKeys = nil
+
+	for n := tn; n.parent != nil && !n.end && len(n.children) == 0; {
+		parent := n.parent
+		parent.removeChild(n)
+		n = parent
+	}
+
+	return true
+}
+
+// removeChild removes the "child" node and resets the dynamic child flags of its kind.
+func (tn *trieNode) removeChild(child *trieNode) {
+	for s, n := range tn.children {
+		if n != child {
+			continue
+		}
+
+		delete(tn.children, s)
+		switch s {
+		case ParamStart:
+			tn.childNamedParameter = false
+		case WildcardParamStart:
+			tn.childWildcardParameter = false
+		}
+		tn.hasDynamicChild = tn.childNamedParameter || tn.childWildcardParameter
+		child.parent = nil
+		return
 	}
-	//静态路径则是得到动态路由之前的固定路由
-	n.staticKey = path[:i]
 }
 
+// search returns the node of the "q" request path, the segments are matched by priority:
+// the static ones first, then the named parameters and the wildcards last,
+// if a path fails later on then the next kind is tried, i.e
+// routes: /users/new and /users/:id/edit
+// req: /users/new/edit => /users/:id/edit
+//
+// A wildcard matches the rest of the path, even if it's empty, i.e
+// route: /docs/*path
+// reqs: /docs/a/b => path=a/b and /docs => path=""
+// unless it's followed by more segments, i.e
+// route: /files/*path/edit
+// req: /files/a/b/edit => path=a/b, the shortest value which the next segments match.
 //context.RequestParams表示动态路径的时候，存储的key value值，如果是静态路径，则为空
-//这个查询方式不是模糊查询
+// 按优先级匹配: 静态 > :param > *wildcard, 匹配失败时回溯尝试下一种
 func (tr *trie) search(q string, params *context.RequestParams) *trieNode {
 	end := len(q)
 
@@ -219,98 +423,80 @@ func (tr *trie) search(q string, params *context.RequestParams) *trieNode {
 		return nil
 	}
 
-	n := tr.root
-	start := 1
-	i := 1
-	var paramValues []string
-
-	for {//每次拿到/与/之间的数据
-		if i == end || q[i] == pathSepB { //当path到末尾或者是/，
-			if child := n.getChild(q[start:i]); child != nil {
-				n = child
-			} else if n.childNamedParameter {
-				n = n.getChild(ParamStart)
-				if ln := len(paramValues); cap(paramValues) > ln {
-					paramValues = paramValues[:ln+1]
-					paramValues[ln] = q[start:i]
-				} else {
-					paramValues = append(paramValues, q[start:i])
-				}
-			} else if n.childWildcardParameter {
-				n = n.getChild(WildcardParamStart)
-				if ln := len(paramValues); cap(paramValues) > ln {
-					paramValues = paramValues[:ln+1]
-					paramValues[ln] = q[start:]
-				} else {
-					paramValues = append(paramValues, q[start:])
-				}
-				break
-			} else {
-				n = n.findClosestParentWildcardNode()
-				if n != nil {
-					// means that it has :param/static and *wildcard, we go trhough the :param
-					// but the next path segment is not the /static, so go back to *wildcard
-					// instead of not found.
-					//
-					// Fixes:
-					// /hello/*p
-					// /hello/:p1/static/:p2
-					// req: http://localhost:8080/hello/dsadsa/static/dsadsa => found
-					// req: http://localhost:8080/hello/dsadsa => but not found!
-					// and
-					// /second/wild/*p
-					// /second/wild/static/otherstatic/
-					// req: /second/wild/static/otherstatic/random => but not found!
-					params.Set(n.paramKeys[0], q[len(n.staticKey):])
-					return n
-				}
+	// the fast path, the static routes are matched first anyway.
+	if n, ok := tr.static[q]; ok {
+		return n
+	}
 
-				return nil
-			}
+	n, paramValues := tr.root.match(q[1:], false, nil)
+	if n == nil {
+		return nil
+	}
 
-			if i == end {
-				break
-			}
+	for i, paramValue := range paramValues {
+		if len(n.paramKeys) > i {
+			params.Set(n.paramKeys[i], paramValue)
+		}
+	}
 
-			i++
-			start = i
-			continue
+	return n
+}
+
+// match returns the end node of the "rest" path segments, without the leading slash,
+// and the parameter values by order, see `trie#search`.
+// The "done" reports whether all the segments are already matched.
+func (tn *trieNode) match(rest string, done bool, values []string) (*trieNode, []string) {
+	if done {
+		if tn.end {
+			return tn, values
 		}
 
-		i++
-	}
-	//如果查询的q得到的路径是nil或者不是叶子节点
-	if n == nil || !n.end {
-		if n != nil { // we need it on both places, on last segment (below) or on the first unnknown (above).
-			//则返回表示最长的表示:开始的节点
-			if n = n.findClosestParentWildcardNode(); n != nil {
-				params.Set(n.paramKeys[0], q[len(n.staticKey):])
-				return n
+		// an optional trailing wildcard, i.e /docs of the /docs/*path.
+		if tn.childWildcardParameter {
+			if w := tn.getChild(WildcardParamStart); w.end {
+				return w, append(values, "")
 			}
 		}
-		//如果根路径就是动态路由，则wildcardParamStart
-		if tr.hasRootWildcard {
-			// that's the case for root wildcard, tests are passing
-			// even without it but stick with it for reference.
-			// Note ote that something like:
-			// Routes: /other2/*myparam and /other2/static
-			// Reqs: /other2/staticed will be handled
-			// the /other2/*myparam and not the root wildcard, which is what we want.
-			//
-			n = tr.root.getChild(WildcardParamStart)
-			params.Set(n.paramKeys[0], q[1:])
-			return n
+
+		return nil, values
+	}
+
+	segment, next, last := rest, "", true
+	if idx := strings.IndexByte(rest, pathSepB); idx != -1 {
+		segment, next, last = rest[:idx], rest[idx+1:], false
+	}
+
+	if child := tn.getChild(segment); child != nil {
+		if n, v := child.match(next, last, values); n != nil {
+			return n, v
 		}
+	}
 
-		return nil
+	if tn.childNamedParameter {
+		if n, v := tn.getChild(ParamStart).match(next, last, append(values, segment)); n != nil {
+			return n, v
+		}
 	}
 
-	//todo 这些都是动态路由的事情，以后再弄
-	for i, paramValue := range paramValues {
-		if len(n.paramKeys) > i {
-			params.Set(n.paramKeys[i], paramValue)
+	if tn.childWildcardParameter {
+		w := tn.getChild(WildcardParamStart)
+		// a mid-path wildcard, the shortest value which the next segments match.
+		if len(w.children) > 0 {
+			for idx := len(segment); idx < len(rest); idx++ {
+				if rest[idx] != pathSepB {
+					continue
+				}
+
+				if n, v := w.match(rest[idx+1:], false, append(values, rest[:idx])); n != nil {
+					return n, v
+				}
+			}
+		}
+
+		if w.end {
+			return w, append(values, rest)
 		}
 	}
 
-	return n
+	return nil, values
 }
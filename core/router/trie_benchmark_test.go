@@ -0,0 +1,48 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/kataras/iris/context"
+)
+
+func newBenchmarkTrie() *trie {
+	tr := newTrie()
+	handlers := context.Handlers{func(ctx context.Context) {}}
+	for _, path := range []string{
+		"/", "/api/users", "/api/users/me", "/api/users/:id", "/api/users/:id/posts",
+		"/api/posts", "/api/posts/:id", "/api/settings/notifications", "/static/*file",
+	} {
+		tr.insert(path, path, handlers, nil, nil, nil)
+	}
+
+	return tr
+}
+
+// go test -run=XXX -bench=BenchmarkTrieSearch -benchmem
+func BenchmarkTrieSearchStatic(b *testing.B) {
+	tr := newBenchmarkTrie()
+	var params context.RequestParams
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if n := tr.search("/api/settings/notifications", &params); n == nil {
+			b.Fatal("expected a static node")
+		}
+	}
+}
+
+func BenchmarkTrieSearchDynamic(b *testing.B) {
+	tr := newBenchmarkTrie()
+	var params context.RequestParams
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		params.Store = params.Store[0:0]
+		if n := tr.search("/api/users/42/posts", &params); n == nil {
+			b.Fatal("expected a dynamic node")
+		}
+	}
+}
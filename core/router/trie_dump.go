@@ -0,0 +1,287 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+// TrieDumpFormat is the output format of the `Router#DumpTrie`.
+type TrieDumpFormat string
+
+const (
+	// TrieDumpText is the human-readable tree format.
+	TrieDumpText TrieDumpFormat = "text"
+	// TrieDumpJSON is the JSON format of the `TrieDump`s.
+	TrieDumpJSON TrieDumpFormat = "json"
+	// TrieDumpDot is the graphviz DOT format, i.e `dot -Tsvg routes.dot > routes.svg`.
+	TrieDumpDot TrieDumpFormat = "dot"
+)
+
+var (
+	errTrieDumpNotSupported = errors.New("dump trie: the request handler is not the default router")
+	errTrieDumpFormat       = errors.New("dump trie: unknown format '%s'")
+)
+
+// TrieNode is a node of a dumped trie, see `Router#Tries`.
+type TrieNode struct {
+	// Segment is the path segment of the node, the ":name" for a named parameter
+	// and the "*name" for a wildcard one.
+	Segment string `json:"segment"`
+	// Dynamic reports whether the segment is a named or a wildcard parameter.
+	Dynamic bool `json:"dynamic,omitempty"`
+	// Path is the full path of the route which ends at this node, if any.
+	Path string `json:"path,omitempty"`
+	// RouteName is the name of the route which ends at this node, if any.
+	RouteName string `json:"route,omitempty"`
+	// Predicated are the names of the routes with predicates which end at this node, if any.
+	Predicated []string `json:"predicated,omitempty"`
+	// Children are the child nodes in the matching precedence order,
+	// the static ones first, then the named parameter and then the wildcard one.
+	Children []*TrieNode `json:"children,omitempty"`
+}
+
+// TrieDump is a dumped trie of a method and a subdomain, see `Router#Tries`.
+type TrieDump struct {
+	Method    string    `json:"method"`
+	Subdomain string    `json:"subdomain,omitempty"`
+	Root      *TrieNode `json:"root"`
+}
+
+// Tries returns a snapshot of all the built tries, of each method and subdomain,
+// sorted by subdomain and method.
+// Useful for debugging the routing precedence and for the documentation generators.
+//
+// It returns nil if the router is not built yet or a custom `RequestHandler` is used.
+func (router *Router) Tries() []TrieDump {
+	h, ok := router.requestHandler.(*routerHandler)
+	if !ok {
+		return nil
+	}
+
+	trees := h.load().trees
+	dumps := make([]TrieDump, 0, len(trees))
+	for _, t := range trees {
+		dumps = append(dumps, TrieDump{
+			Method:    t.method,
+			Subdomain: t.subdomain,
+			Root:      dumpTrieNode("", t.root, 0),
+		})
+	}
+
+	sort.SliceStable(dumps, func(i, j int) bool {
+		if dumps[i].Subdomain != dumps[j].Subdomain {
+			return dumps[i].Subdomain < dumps[j].Subdomain
+		}
+		return dumps[i].Method < dumps[j].Method
+	})
+
+	return dumps
+}
+
+// DumpTrie writes all the built tries, including the subdomains ones,
+// to "w" as text, JSON or graphviz DOT.
+//
+// Example of the text format:
+//
+//	GET
+//	└── users
+//	    ├── me  [/users/me] users.me
+//	    └── :id  [/users/:id] users.get
+func (router *Router) DumpTrie(w io.Writer, format TrieDumpFormat) error {
+	if _, ok := router.requestHandler.(*routerHandler); !ok {
+		return errTrieDumpNotSupported
+	}
+
+	dumps := router.Tries()
+
+	switch format {
+	case TrieDumpText, "":
+		return dumpTrieText(w, dumps)
+	case TrieDumpJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(dumps)
+	case TrieDumpDot:
+		return dumpTrieDot(w, dumps)
+	default:
+		return errTrieDumpFormat.Format(format)
+	}
+}
+
+// dumpTrieNode converts the "n" node, of the "segment" at the "depth", to a `TrieNode`.
+func dumpTrieNode(segment string, n *trieNode, depth int) *TrieNode {
+	node := &TrieNode{Segment: segment}
+
+	if segment == ParamStart || segment == WildcardParamStart {
+		node.Dynamic = true
+		// the parameters are named per route, show the name of the first route which goes through this node.
+		node.Segment += paramName(n, depth)
+	}
+
+	if n.end {
+		node.Path = n.key
+		node.RouteName = n.RouteName
+		for _, p := range n.predicated {
+			node.Predicated = append(node.Predicated, p.RouteName)
+		}
+	}
+
+	keys := make([]string, 0, len(n.children))
+	for k := range n.children {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return segmentPrecedence(keys[i]) < segmentPrecedence(keys[j]) ||
+			(segmentPrecedence(keys[i]) == segmentPrecedence(keys[j]) && keys[i] < keys[j])
+	})
+
+	for _, k := range keys {
+		node.Children = append(node.Children, dumpTrieNode(k, n.children[k], depth+1))
+	}
+
+	return node
+}
+
+func segmentPrecedence(segment string) int {
+	switch segment {
+	case ParamStart:
+		return 1
+	case WildcardParamStart:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// paramName returns the parameter name of the segment at the "depth" (starting from 1)
+// from the path of the first route which ends at or under the "n".
+func paramName(n *trieNode, depth int) string {
+	for !n.end {
+		next := ""
+		for k := range n.children {
+			if next == "" || segmentPrecedence(k) < segmentPrecedence(next) ||
+				(segmentPrecedence(k) == segmentPrecedence(next) && k < next) {
+				next = k
+			}
+		}
+		if next == "" {
+			return ""
+		}
+		n = n.children[next]
+	}
+
+	segments := slowPathSplit(n.key)
+	if depth <= 0 || depth > len(segments) {
+		return ""
+	}
+
+	return strings.TrimLeft(segments[depth-1], ParamStart+WildcardParamStart)
+}
+
+func trieDumpTitle(d TrieDump) string {
+	if d.Subdomain == "" {
+		return d.Method
+	}
+
+	return d.Method + " " + d.Subdomain
+}
+
+func dumpTrieText(w io.Writer, dumps []TrieDump) error {
+	for _, d := range dumps {
+		if _, err := fmt.Fprintln(w, trieDumpTitle(d)); err != nil {
+			return err
+		}
+
+		for i, child := range d.Root.Children {
+			if err := dumpTrieTextNode(w, child, "", i == len(d.Root.Children)-1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func dumpTrieTextNode(w io.Writer, n *TrieNode, prefix string, last bool) error {
+	branch, indent := "├── ", "│   "
+	if last {
+		branch, indent = "└── ", "    "
+	}
+
+	line := prefix + branch + n.Segment
+	if n.Path != "" {
+		line += "  [" + n.Path + "]"
+		if n.RouteName != "" {
+			line += " " + n.RouteName
+		}
+	}
+	for _, name := range n.Predicated {
+		line += " (" + name + ")"
+	}
+
+	if _, err := fmt.Fprintln(w, line); err != nil {
+		return err
+	}
+
+	for i, child := range n.Children {
+		if err := dumpTrieTextNode(w, child, prefix+indent, i == len(n.Children)-1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dotQuote quotes the "s" DOT label, its escaped new lines are kept.
+func dotQuote(s string) string {
+	return `"` + strings.Replace(s, `"`, `\"`, -1) + `"`
+}
+
+func dumpTrieDot(w io.Writer, dumps []TrieDump) error {
+	var (
+		b    strings.Builder
+		next int
+	)
+
+	var walk func(parent string, n *TrieNode)
+	walk = func(parent string, n *TrieNode) {
+		id := fmt.Sprintf("n%d", next)
+		next++
+
+		label, shape := n.Segment, "ellipse"
+		if n.Path != "" {
+			label += "\\n" + n.Path
+			if n.RouteName != "" {
+				label += "\\n" + n.RouteName
+			}
+			shape = "box"
+		}
+
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s];\n", id, dotQuote(label), shape)
+		fmt.Fprintf(&b, "  %s -> %s;\n", parent, id)
+
+		for _, child := range n.Children {
+			walk(id, child)
+		}
+	}
+
+	b.WriteString("digraph routes {\n  rankdir=LR;\n")
+	for _, d := range dumps {
+		id := fmt.Sprintf("n%d", next)
+		next++
+		fmt.Fprintf(&b, "  %s [label=%s, shape=doublecircle];\n", id, dotQuote(trieDumpTitle(d)))
+
+		for _, child := range d.Root.Children {
+			walk(id, child)
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,129 @@
+package router
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/kataras/iris/context"
+)
+
+// UpgradeHandler serves a connection which is upgraded to a custom protocol, see `UpgradeRegistry`.
+//
+// The "conn" is the hijacked connection, the "101 Switching Protocols" response
+// is already sent to the client, the "rw" buffers any data that the client sent after the request.
+// The handler should serve the connection until it's done,
+// the connection is closed and the "ctx" is released when the handler returns.
+type UpgradeHandler func(ctx context.Context, conn net.Conn, rw *bufio.ReadWriter)
+
+// UpgradeRegistry keeps the handlers of the custom protocols that a client can switch to
+// through the "Upgrade" and "Connection: Upgrade" request headers (RFC 7230 section 6.7).
+//
+// The requests of a registered protocol are served before the routing, on any path,
+// the rest, i.e the websocket ones (when no handler is registered for the "websocket"),
+// are served by the router as usual.
+// 协议升级(Upgrade)的注册表, 用于支持自定义的协议
+type UpgradeRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]UpgradeHandler
+}
+
+// NewUpgradeRegistry returns a new, empty, `UpgradeRegistry`.
+func NewUpgradeRegistry() *UpgradeRegistry {
+	return &UpgradeRegistry{handlers: make(map[string]UpgradeHandler)}
+}
+
+// Handle registers the "handler" of the "protocol", i.e "my-protocol" or "my-protocol/2".
+// The protocol name is case-insensitive, a protocol without a version
+// matches the requests of any version of it.
+func (u *UpgradeRegistry) Handle(protocol string, handler UpgradeHandler) {
+	protocol = strings.ToLower(strings.TrimSpace(protocol))
+	if protocol == "" {
+		return
+	}
+
+	u.mu.Lock()
+	if handler == nil {
+		delete(u.handlers, protocol)
+	} else {
+		u.handlers[protocol] = handler
+	}
+	u.mu.Unlock()
+}
+
+// Lookup returns the handler of the first protocol of the request's "Upgrade" header which is registered.
+// It returns a nil handler if the request is not an upgrade request or none of its protocols is registered.
+func (u *UpgradeRegistry) Lookup(r *http.Request) (protocol string, handler UpgradeHandler) {
+	if !headerHasToken(r.Header, "Connection", "upgrade") {
+		return "", nil
+	}
+
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	for _, v := range r.Header["Upgrade"] {
+		// the protocols are listed in order of the client's preference.
+		for _, p := range strings.Split(v, ",") {
+			p = strings.TrimSpace(p)
+			key := strings.ToLower(p)
+			if h, ok := u.handlers[key]; ok {
+				return p, h
+			}
+
+			if idx := strings.IndexByte(key, '/'); idx > 0 {
+				if h, ok := u.handlers[key[:idx]]; ok {
+					return p, h
+				}
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// Wrapper returns the router wrapper which serves the upgrade requests of the registered protocols,
+// it acquires the contexts from the "pool". See `Router#WrapRouter`.
+func (u *UpgradeRegistry) Wrapper(pool *context.Pool) WrapperFunc {
+	return func(w http.ResponseWriter, r *http.Request, router http.HandlerFunc) {
+		protocol, handler := u.Lookup(r)
+		if handler == nil {
+			router(w, r)
+			return
+		}
+
+		ctx := pool.Acquire(w, r)
+		defer pool.Release(ctx)
+
+		conn, rw, err := ctx.ResponseWriter().Hijack()
+		if err != nil {
+			// i.e HTTP/2, it does not support the "Upgrade" header.
+			context.Logger(ctx).Errorf("upgrade to '%s': %v", protocol, err)
+			ctx.StatusCode(http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: " + protocol + "\r\nConnection: Upgrade\r\n\r\n")
+		if err = rw.Flush(); err != nil {
+			return
+		}
+
+		handler(ctx, conn, rw)
+	}
+}
+
+// headerHasToken reports whether the comma-separated values of the "key" header contain the "token",
+// case-insensitive.
+func headerHasToken(h http.Header, key, token string) bool {
+	for _, v := range h[key] {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,139 @@
+package router
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+)
+
+const (
+	// SignedURLExpiresParam is the url query parameter of a signed url's expiration unix time.
+	SignedURLExpiresParam = "expires"
+	// SignedURLSignatureParam is the url query parameter of a signed url's signature.
+	SignedURLSignatureParam = "signature"
+)
+
+var (
+	// ErrSignedURLInvalid is returned by the `URLSigner#Verify` when the signature is missing or it does not match.
+	ErrSignedURLInvalid = errors.New("signed url: invalid signature")
+	// ErrSignedURLExpired is returned by the `URLSigner#Verify` when the signed url has been expired.
+	ErrSignedURLExpired       = errors.New("signed url: expired")
+	errSignedURLRouteNotFound = errors.New("signed url: route '%s' not found")
+)
+
+// URLSigner creates and verifies session-less signed urls of the named routes,
+// with an expiration and optional scoped claims,
+// useful for secure download links and e-mail confirmation flows.
+//
+// The signature is the HMAC-SHA256 of the path and the sorted query,
+// the host is not signed so the links are valid behind proxies too.
+// 签名URL: 带有效期的安全链接
+type URLSigner struct {
+	key      []byte
+	reverser *RoutePathReverser
+}
+
+// NewURLSigner returns a new url signer based on the secret "key" and a routes provider,
+// needed to reverse the path of a route based on its name.
+// If "key" is empty then a random one is generated, the signed urls are not valid after a restart in that case.
+func NewURLSigner(key []byte, apiRoutesProvider RoutesProvider) *URLSigner {
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+
+	return &URLSigner{
+		key:      key,
+		reverser: NewRoutePathReverser(apiRoutesProvider),
+	}
+}
+
+func (s *URLSigner) sign(escapedPath string, query url.Values) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(escapedPath))
+	mac.Write([]byte{'?'})
+	mac.Write([]byte(context.CanonicalQuery(query)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Sign returns the signed path (with the query) of the "routeName" route,
+// which expires after "ttl" (zero for never),
+// the "claims" are signed with the url as query parameters, i.e the user's e-mail of a confirmation link.
+func (s *URLSigner) Sign(routeName string, paramValues []interface{}, ttl time.Duration, claims ...url.Values) (string, error) {
+	p := s.reverser.Path(routeName, paramValues...)
+	if p == "" {
+		return "", errSignedURLRouteNotFound.Format(routeName)
+	}
+
+	u, err := url.Parse(p)
+	if err != nil {
+		return "", err
+	}
+
+	query := make(url.Values)
+	for _, c := range claims {
+		for k, v := range c {
+			query[k] = append(query[k], v...)
+		}
+	}
+
+	if ttl > 0 {
+		query.Set(SignedURLExpiresParam, strconv.FormatInt(time.Now().Add(ttl).Unix(), 10))
+	}
+
+	query.Set(SignedURLSignatureParam, s.sign(u.EscapedPath(), query))
+	return u.EscapedPath() + "?" + query.Encode(), nil
+}
+
+// Verify reports, through an error, whether the request's url is a valid and non-expired signed url.
+func (s *URLSigner) Verify(r *http.Request) error {
+	query := r.URL.Query()
+	signature := query.Get(SignedURLSignatureParam)
+	if signature == "" {
+		return ErrSignedURLInvalid
+	}
+	query.Del(SignedURLSignatureParam)
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(r.URL.EscapedPath(), query))) {
+		return ErrSignedURLInvalid
+	}
+
+	if expires := query.Get(SignedURLExpiresParam); expires != "" {
+		unix, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil {
+			return ErrSignedURLInvalid
+		}
+
+		if time.Now().After(time.Unix(unix, 0)) {
+			return ErrSignedURLExpired
+		}
+	}
+
+	return nil
+}
+
+// Handler returns a middleware which allows only the valid and non-expired signed urls,
+// it fires 403 Forbidden otherwise. The signed claims can be retrieved through the `ctx.URLParam`.
+func (s *URLSigner) Handler() context.Handler {
+	return s.Serve
+}
+
+// Serve is the middleware of the `Handler`.
+func (s *URLSigner) Serve(ctx context.Context) {
+	if err := s.Verify(ctx.Request()); err != nil {
+		context.Logger(ctx).Debugf("%s: %v", ctx.Path(), err)
+		ctx.StatusCode(http.StatusForbidden)
+		ctx.StopExecution()
+		return
+	}
+
+	ctx.Next()
+}
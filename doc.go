@@ -856,7 +856,9 @@ Example Code:
 	})
 
 	// http://localhost:8080/profile/id>=1
-	// this will throw 404 even if it's found as route on : /profile/0, /profile/blabla, /profile/-1
+	// this will throw 404 if it's not of the parameter's type: /profile/blabla, /profile/-1
+	// and 422 (unprocessable entity) if one of its functions is not passed: /profile/0.
+	// The unknown functions, i.e a typo, are registration errors.
 	// macro parameter functions are optional of course.
 	app.Get("/profile/{id:uint64 min(1)}", func(ctx iris.Context) {
 		// second parameter is the error but it will always nil because we use macros,
@@ -0,0 +1,22 @@
+package events
+
+// Config is the configuration of the `Bus`, see `New`.
+type Config struct {
+	// QueueSize is the capacity of the queue of each asynchronous subscriber,
+	// the events are dropped, with an `ErrQueueFull`, when it's full.
+	//
+	// Defaults to 256.
+	QueueSize int
+	// OnError is called with the event and the error of an asynchronous subscriber,
+	// its recovered panic or the `ErrQueueFull` and the `ErrClosed` of the dropped events.
+	//
+	// Defaults to nil, the errors are ignored.
+	OnError func(evt Event, err error)
+}
+
+// DefaultConfig returns the default configuration of the `Bus`.
+func DefaultConfig() Config {
+	return Config{
+		QueueSize: 256,
+	}
+}
@@ -0,0 +1,323 @@
+// Package events provides a lightweight, in-memory and topic-based publish/subscribe bus,
+// so the components of an Application can communicate without global variables,
+// i.e a cache invalidation on a "products.updated" event, see `Application#Events`.
+//
+// The synchronous subscribers are called by the publisher's goroutine,
+// the asynchronous ones receive the events in order through their own queue and goroutine
+// and they are drained on `Close`.
+package events
+
+import (
+	stdContext "context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+var (
+	// ErrQueueFull is passed to the `Config#OnError`, and returned from the `Bus#Publish`,
+	// when the queue of an asynchronous subscriber is full and the event is dropped.
+	ErrQueueFull = errors.New("events: the queue of a subscriber is full")
+	// ErrClosed is returned from the `Bus#Publish` after `Close`
+	// and it's passed to the `Config#OnError` for the queued events which are dropped on close.
+	ErrClosed = errors.New("events: closed")
+
+	errPanic = errors.New("events: subscriber of '%s' panicked: %v")
+)
+
+// TopicViewInvalidate is the topic which the bus of the `Application#Events` invalidates
+// the render cache of the views on, see `Application#InvalidateViewCache`.
+// Its payload is the template file's name, a slice of them or nil for all of them.
+//
+// Usage:
+//
+//	app.Events().Publish(events.TopicViewInvalidate, "products/index.html")
+const TopicViewInvalidate = "iris.view.invalidate"
+
+// Strings returns the "payload" as a slice of strings, if it's a string or a slice of them,
+// otherwise nil, i.e the names of the `TopicViewInvalidate` and the cache tags of a purge.
+func Strings(payload interface{}) []string {
+	switch v := payload.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	default:
+		return nil
+	}
+}
+
+// Event is the event which is passed to the subscribers.
+type Event struct {
+	// Topic is the topic that the event was published to, i.e "products.updated".
+	Topic string
+	// Payload is the data of the event, can be nil.
+	Payload interface{}
+	// Time is the time that the event was published.
+	Time time.Time
+}
+
+// Handler is the function of a subscriber.
+type Handler func(evt Event)
+
+// Subscription is a subscriber of a `Bus`, see `Bus#Subscribe` and `Bus#SubscribeAsync`.
+type Subscription struct {
+	bus     *Bus
+	topic   string
+	handler Handler
+
+	queue chan Event // nil for the synchronous subscribers.
+}
+
+// Topic returns the topic, or the topic pattern, of the subscription.
+func (s *Subscription) Topic() string {
+	return s.topic
+}
+
+// Unsubscribe removes the subscription from its bus,
+// the queued events of an asynchronous subscriber are still delivered.
+// It's safe to call it more than once.
+func (s *Subscription) Unsubscribe() {
+	s.bus.unsubscribe(s)
+}
+
+// Bus is an in-memory publish/subscribe bus of events, see `New`.
+// 进程内的发布/订阅事件总线, 同步订阅者在发布者的goroutine中执行, 异步订阅者有自己的队列
+type Bus struct {
+	config Config
+
+	mu          sync.RWMutex // protects the subscriptions and the queues' close.
+	subscribers []*Subscription
+	closed      bool
+
+	wg        sync.WaitGroup
+	abort     chan struct{}
+	abortOnce sync.Once
+}
+
+// New returns a new event bus of the optional "c" configuration.
+// Its `Close` should be called on shutdown, it's called automatically on the shutdown
+// of the Application when it's created through the `Application#Events`.
+func New(c ...Config) *Bus {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		config = c[0]
+		if config.QueueSize <= 0 {
+			config.QueueSize = DefaultConfig().QueueSize
+		}
+	}
+
+	return &Bus{
+		config: config,
+		abort:  make(chan struct{}),
+	}
+}
+
+// matchTopic reports whether the "topic" matches the "pattern" of a subscription,
+// a pattern which ends with "*" matches all the topics with that prefix, i.e "products.*" matches "products.updated"
+// and a single "*" matches all the topics.
+func matchTopic(pattern, topic string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(topic, pattern[:len(pattern)-1])
+	}
+
+	return pattern == topic
+}
+
+// Subscribe registers a synchronous subscriber of the "topic", it's called by the goroutine of the `Publish`,
+// before it returns, so it should be fast, i.e an in-memory cache invalidation.
+// The "topic" can end with a "*" in order to match all the topics with that prefix, i.e "products.*".
+//
+// Usage:
+//
+//	bus.Subscribe("products.updated", func(evt events.Event) {
+//		cache.Invalidate(evt.Payload.(*Product).ID)
+//	})
+func (b *Bus) Subscribe(topic string, handler Handler) *Subscription {
+	return b.subscribe(&Subscription{bus: b, topic: topic, handler: handler})
+}
+
+// SubscribeAsync registers an asynchronous subscriber of the "topic",
+// it receives the events in the published order on its own goroutine,
+// i.e for slow work like notifying the websocket clients.
+// Its panics are recovered and passed to the `Config#OnError`.
+// The "topic" can end with a "*" in order to match all the topics with that prefix, i.e "products.*".
+func (b *Bus) SubscribeAsync(topic string, handler Handler) *Subscription {
+	s := &Subscription{bus: b, topic: topic, handler: handler, queue: make(chan Event, b.config.QueueSize)}
+	return b.subscribe(s)
+}
+
+func (b *Bus) subscribe(s *Subscription) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		// the subscription is inactive, its events are never received.
+		if s.queue != nil {
+			close(s.queue)
+		}
+		return s
+	}
+
+	if s.queue != nil {
+		b.wg.Add(1)
+		go b.work(s)
+	}
+
+	b.subscribers = append(b.subscribers, s)
+	return s
+}
+
+func (b *Bus) unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed { // the queues are closed already.
+		return
+	}
+
+	for i, sub := range b.subscribers {
+		if sub != s {
+			continue
+		}
+
+		copy(b.subscribers[i:], b.subscribers[i+1:])
+		b.subscribers[len(b.subscribers)-1] = nil
+		b.subscribers = b.subscribers[:len(b.subscribers)-1]
+
+		if s.queue != nil {
+			close(s.queue)
+		}
+		return
+	}
+}
+
+// Publish publishes the "payload" to the subscribers of the "topic",
+// the synchronous subscribers are called before it returns.
+// It returns `ErrClosed` after `Close` and `ErrQueueFull` if the event
+// was dropped by an asynchronous subscriber, the rest subscribers receive the event anyway.
+//
+// Usage:
+// err := app.Events().Publish("products.updated", product)
+func (b *Bus) Publish(topic string, payload interface{}) error {
+	evt := Event{Topic: topic, Payload: payload, Time: time.Now()}
+
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrClosed
+	}
+
+	var (
+		syncSubscribers []*Subscription
+		err             error
+	)
+
+	for _, s := range b.subscribers {
+		if !matchTopic(s.topic, topic) {
+			continue
+		}
+
+		if s.queue == nil {
+			syncSubscribers = append(syncSubscribers, s)
+			continue
+		}
+
+		select {
+		case s.queue <- evt:
+		default:
+			err = ErrQueueFull
+			b.onError(evt, err)
+		}
+	}
+	b.mu.RUnlock()
+
+	// called without the lock, so they can publish or unsubscribe.
+	for _, s := range syncSubscribers {
+		s.handler(evt)
+	}
+
+	return err
+}
+
+// Pending returns the number of the queued events of the asynchronous subscribers.
+func (b *Bus) Pending() (n int) {
+	b.mu.RLock()
+	for _, s := range b.subscribers {
+		n += len(s.queue)
+	}
+	b.mu.RUnlock()
+
+	return
+}
+
+// Close stops accepting events and waits for the queued ones to be received by the asynchronous subscribers,
+// if the "ctx" is done first the remaining events are dropped and the ctx's error is returned.
+// It's safe to call it more than once.
+func (b *Bus) Close(ctx stdContext.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, s := range b.subscribers {
+			if s.queue != nil {
+				close(s.queue)
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		b.abortOnce.Do(func() { close(b.abort) })
+		return ctx.Err()
+	}
+}
+
+func (b *Bus) aborted() bool {
+	select {
+	case <-b.abort:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *Bus) onError(evt Event, err error) {
+	if b.config.OnError != nil {
+		b.config.OnError(evt, err)
+	}
+}
+
+func (b *Bus) work(s *Subscription) {
+	defer b.wg.Done()
+
+	for evt := range s.queue {
+		if b.aborted() {
+			b.onError(evt, ErrClosed)
+			continue
+		}
+
+		b.call(s, evt)
+	}
+}
+
+func (b *Bus) call(s *Subscription, evt Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.onError(evt, errPanic.Format(evt.Topic, fmt.Sprint(r)))
+		}
+	}()
+
+	s.handler(evt)
+}
@@ -0,0 +1,109 @@
+package events_test
+
+import (
+	stdContext "context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/events"
+)
+
+func TestBusPublishSubscribe(t *testing.T) {
+	bus := events.New()
+
+	var got []string
+	bus.Subscribe("products.updated", func(evt events.Event) {
+		got = append(got, "exact:"+evt.Payload.(string))
+	})
+	bus.Subscribe("products.*", func(evt events.Event) {
+		got = append(got, "prefix:"+evt.Topic)
+	})
+	all := bus.Subscribe("*", func(evt events.Event) {
+		got = append(got, "all:"+evt.Topic)
+	})
+
+	if err := bus.Publish("products.updated", "42"); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Publish("users.created", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	all.Unsubscribe()
+	all.Unsubscribe()
+	bus.Publish("products.deleted", nil)
+
+	expected := []string{"exact:42", "prefix:products.updated", "all:products.updated", "all:users.created", "prefix:products.deleted"}
+	if len(got) != len(expected) {
+		t.Fatalf("expected events %v but got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected events %v but got %v", expected, got)
+		}
+	}
+}
+
+func TestBusAsyncDrainAndClose(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		errs     []error
+		received []int
+	)
+
+	bus := events.New(events.Config{
+		QueueSize: 2,
+		OnError: func(evt events.Event, err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		},
+	})
+
+	release := make(chan struct{})
+	bus.SubscribeAsync("jobs", func(evt events.Event) {
+		<-release
+		n := evt.Payload.(int)
+		if n == 2 {
+			panic("job failed")
+		}
+
+		mu.Lock()
+		received = append(received, n)
+		mu.Unlock()
+	})
+
+	// the first one is received by the worker, the next two are queued and the last one is dropped.
+	if err := bus.Publish("jobs", 1); err != nil {
+		t.Fatal(err)
+	}
+	for bus.Pending() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	bus.Publish("jobs", 2)
+	bus.Publish("jobs", 3)
+	if err := bus.Publish("jobs", 4); !events.ErrQueueFull.Equal(err) {
+		t.Fatalf("expected ErrQueueFull but got %v", err)
+	}
+
+	close(release)
+	if err := bus.Close(stdContext.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.Publish("jobs", 5); !events.ErrClosed.Equal(err) {
+		t.Fatalf("expected ErrClosed but got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(received) != 2 || received[0] != 1 || received[1] != 3 {
+		t.Fatalf("expected the events [1 3] in order but got %v", received)
+	}
+	// the dropped event and the recovered panic.
+	if len(errs) != 2 || !events.ErrQueueFull.Equal(errs[0]) {
+		t.Fatalf("expected the ErrQueueFull and the panic errors but got %v", errs)
+	}
+}
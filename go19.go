@@ -27,6 +27,10 @@ type (
 	//
 	// Example: https://github.com/kataras/iris/blob/master/_examples/http_request/read-custom-via-unmarshaler/main.go
 	UnmarshalerFunc = context.UnmarshalerFunc
+	// CSVOptions are the options of the `Context#ReadCSV`.
+	//
+	// A shortcut for the `context#CSVOptions`.
+	CSVOptions = context.CSVOptions
 	// A Handler responds to an HTTP request.
 	// It writes reply headers and data to the Context.ResponseWriter() and then return.
 	// Returning signals that the request is finished;
@@ -44,6 +48,10 @@ type (
 	Handler = context.Handler
 	// A Map is a shortcut of the map[string]interface{}.
 	Map = context.Map
+	// Problem Details for HTTP APIs, see https://tools.ietf.org/html/rfc7807.
+	//
+	// A shortcut for the `context#Problem`, see `NewProblem` and `context#Problem`.
+	Problem = context.Problem
 
 	// Supervisor is a shortcut of the `host#Supervisor`.
 	// Used to add supervisor configurators on common Runners
@@ -57,6 +65,36 @@ type (
 	//
 	// A shortcut for the `core/router#Party`, useful when `PartyFunc` is being used.
 	Party = router.Party
+	// Blueprint is a reusable feature module, its routes, middleware, error handlers, templates and static assets
+	// are mounted under a path prefix, see `Application#MountBlueprint`.
+	//
+	// A shortcut for the `core/router#Blueprint`.
+	Blueprint = router.Blueprint
+	// ErrorRenderer fully controls the error responses, see `Application#SetErrorRenderer`.
+	//
+	// A shortcut for the `core/router#ErrorRenderer`.
+	ErrorRenderer = router.ErrorRenderer
+	// ErrorRendererFunc is the function form of the `ErrorRenderer`.
+	//
+	// A shortcut for the `core/router#ErrorRendererFunc`.
+	ErrorRendererFunc = router.ErrorRendererFunc
+	// UpgradeHandler serves a connection which is upgraded to a custom protocol, see `Application#OnUpgrade`.
+	//
+	// A shortcut for the `core/router#UpgradeHandler`.
+	UpgradeHandler = router.UpgradeHandler
+	// StaticFSOptions are the options of the `Party#StaticFS`,
+	// the gzip compression, the cache headers, the directory listing and the single page application fallback.
+	//
+	// A shortcut for the `core/router#StaticFSOptions`.
+	StaticFSOptions = router.StaticFSOptions
+	// ContextDecorator wraps the Context of a Party's routes, see `Party#DecorateContext`.
+	//
+	// A shortcut for the `core/router#ContextDecorator`.
+	ContextDecorator = router.ContextDecorator
+	// CORSOptions are the Cross-Origin Resource Sharing options of a Party, see `Party#CORS`.
+	//
+	// A shortcut for the `core/router#CORSOptions`.
+	CORSOptions = router.CORSOptions
 
 	// ExecutionRules gives control to the execution of the route handlers outside of the handlers themselves.
 	// Usage:
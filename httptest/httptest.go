@@ -2,6 +2,7 @@ package httptest
 
 import (
 	"crypto/tls"
+	"io"
 	"net/http"
 	"testing"
 
@@ -35,6 +36,10 @@ type Configuration struct {
 	// LogLevel sets the application's log level.
 	// Defaults to "disable" when testing.
 	LogLevel string
+	// RemoteAddr sets the remote address, "IP:port", of the requests,
+	// i.e to test the trusted proxies or a per client middleware.
+	// Defaults to empty string "".
+	RemoteAddr string
 }
 
 // Set implements the OptionSetter for the Configuration itself
@@ -44,6 +49,9 @@ func (c Configuration) Set(main *Configuration) {
 	if c.LogLevel != "" {
 		main.LogLevel = c.LogLevel
 	}
+	if c.RemoteAddr != "" {
+		main.RemoteAddr = c.RemoteAddr
+	}
 }
 
 var (
@@ -69,8 +77,29 @@ var (
 			c.LogLevel = val
 		}
 	}
+
+	// RemoteAddr sets the remote address "IP:port" of the requests.
+	// Defaults to empty string "".
+	RemoteAddr = func(ipAndPort string) OptionSet {
+		return func(c *Configuration) {
+			c.RemoteAddr = ipAndPort
+		}
+	}
 )
 
+// remoteAddrRequestFactory is the httpexpect's request factory of the `Configuration#RemoteAddr`.
+type remoteAddrRequestFactory string
+
+func (remoteAddr remoteAddrRequestFactory) NewRequest(method, urlStr string, body io.Reader) (*http.Request, error) {
+	r, err := httpexpect.DefaultRequestFactory{}.NewRequest(method, urlStr, body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.RemoteAddr = string(remoteAddr)
+	return r, nil
+}
+
 // DefaultConfiguration returns the default configuration for the httptest.
 func DefaultConfiguration() *Configuration {
 	return &Configuration{URL: "", Debug: false, LogLevel: "disable"}
@@ -103,6 +132,10 @@ func New(t *testing.T, app *iris.Application, setters ...OptionSetter) *httpexpe
 		Reporter: httpexpect.NewAssertReporter(t),
 	}
 
+	if conf.RemoteAddr != "" {
+		testConfiguration.RequestFactory = remoteAddrRequestFactory(conf.RemoteAddr)
+	}
+
 	if conf.Debug {
 		testConfiguration.Printers = []httpexpect.Printer{
 			httpexpect.NewDebugPrinter(t, true),
@@ -7,7 +7,10 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"net/url"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kataras/golog"
@@ -23,6 +26,10 @@ import (
 	"github.com/kataras/iris/core/handlerconv"
 	// cache conversions
 	"github.com/kataras/iris/cache"
+	"github.com/kataras/iris/events"
+	"github.com/kataras/iris/mailer"
+	"github.com/kataras/iris/metrics"
+	"github.com/kataras/iris/sessions"
 	// view
 	"github.com/kataras/iris/view"
 	// middleware used in Default method
@@ -142,6 +149,15 @@ type Application struct {
 	// all fields defaults to something that is working, developers don't have to set it.
 	//Application的Configuration只有一个，通过ConfigurationReadOnly()获取
 	config *Configuration
+	// liveConfig holds the published copy of the config, the *Configuration that the `ConfigurationReadOnly` returns.
+	// The config is copied on each change, see `publishConfiguration`, so the readers see a consistent snapshot.
+	liveConfig atomic.Value
+	// configMu serializes the changes of the config.
+	configMu sync.Mutex
+	// sections are the registered typed configuration sections of the `Configuration#Other`, see `ConfigureSection`.
+	sections map[string]interface{}
+	// configListeners are the listeners of the configuration changes, see `OnConfigurationChange`.
+	configListeners []func(old, new Configuration)
 
 	// the golog logger instance, defaults to "Info" level messages (all except "Debug")
 	logger *golog.Logger
@@ -168,6 +184,46 @@ type Application struct {
 	Hosts []*host.Supervisor
 	// 给每一个准备添加到当前application的supervisor配置[]host.Configurator
 	hostConfigurators []host.Configurator
+
+	// executionInterceptors wrap each handler invocation, see `UseExecutionInterceptor`.
+	executionInterceptors []context.ExecutionInterceptor
+
+	// watchers are the started file watchers, see `Watch`.
+	watchers []*host.Watcher
+
+	// validator validates the request bodies, see `Validator`.
+	validator context.Validator
+
+	// tracer starts the spans of the requests, see `Tracer`.
+	tracer context.Tracer
+
+	// urlSigner signs and verifies the signed urls, see `SignURL`.
+	urlSigner     *router.URLSigner
+	urlSignerOnce sync.Once
+
+	// metrics are the Prometheus metrics of the requests, see `Metrics`.
+	metrics     *metrics.Metrics
+	metricsOnce sync.Once
+
+	// connections are the long-lived connections, notified on the hosts' shutdown, see `RegisterLongLivedConn`.
+	connections *host.ConnRegistry
+
+	// upgrades are the handlers of the custom "Upgrade" protocols, see `OnUpgrade`.
+	upgrades     *router.UpgradeRegistry
+	upgradesOnce sync.Once
+
+	// mailer is the SMTP mailer, see `ConfigureMailer` and `Mailer`.
+	mailer     *mailer.Mailer
+	mailerOnce sync.Once
+
+	// events is the in-memory publish/subscribe bus, see `Events`.
+	events     *events.Bus
+	eventsOnce sync.Once
+
+	// liveHosts is the number of the hosts which are not shut down yet, see `onHostShutdown`.
+	liveHosts int
+	// closeOnce closes the mailer and the events bus once, see `closeComponents`.
+	closeOnce sync.Once
 }
 
 // New creates and returns a fresh empty iris *Application instance.
@@ -177,13 +233,15 @@ func New() *Application {
 	app := &Application{
 		config:     &config,
 		logger:     golog.Default,
-		APIBuilder: router.NewAPIBuilder(),
-		Router:     router.NewRouter(),
+		APIBuilder:  router.NewAPIBuilder(),
+		Router:      router.NewRouter(),
+		connections: host.NewConnRegistry(),
 	}
 
 	app.ContextPool = context.New(func() context.Context {
 		return context.NewContext(app)
 	})
+	app.publishConfiguration()
 
 	return app
 }
@@ -244,12 +302,187 @@ func (app *Application) Configure(configurators ...Configurator) *Application {
 		cfg(app)
 	}
 
+	app.publishConfiguration()
 	return app
 }
 
+// publishConfiguration publishes a copy of the config to the readers of the `ConfigurationReadOnly`,
+// it's called after each change of the config, i.e by the `Configure` and the `UpdateConfiguration`.
+func (app *Application) publishConfiguration() {
+	app.configMu.Lock()
+	app.publishConfigurationLocked()
+	app.configMu.Unlock()
+}
+
+func (app *Application) publishConfigurationLocked() {
+	published := app.config.clone()
+	app.liveConfig.Store(&published)
+}
+
+var errSectionNotPointer = errors.New("configuration section '%s': a non-nil pointer is required")
+
+// ConfigureSection registers the "dest" pointer of a typed configuration struct, i.e of a middleware,
+// as the "name" section of the `Configuration#Other`, so the config-file users
+// can set it by a yaml or a toml file, i.e:
+//
+//	Other:
+//	  cors:
+//	    AllowedOrigins: ["https://*.example.com"]
+//	    AllowCredentials: true
+//
+// The section's keys are matched to the fields case-insensitive and the unknown ones are reported as errors,
+// the "dest" is validated by its `Validate() error` method, if any.
+// The section is decoded now and on each `WithConfiguration` which contains it,
+// the `Other[name]` holds the "dest" after that.
+//
+// Usage:
+// corsOptions := &iris.CORSOptions{}
+// if err := app.ConfigureSection("cors", corsOptions); err != nil { [...] }
+// app.CORS(*corsOptions)
+// 注册类型化的配置段, 替代自由格式的Other
+func (app *Application) ConfigureSection(name string, dest interface{}) error {
+	if v := reflect.ValueOf(dest); v.Kind() != reflect.Ptr || v.IsNil() {
+		return errSectionNotPointer.Format(name)
+	}
+
+	app.mu.Lock()
+	if app.sections == nil {
+		app.sections = make(map[string]interface{})
+	}
+	app.sections[name] = dest
+	app.mu.Unlock()
+
+	return app.decodeSection(name, dest)
+}
+
+// decodeSections decodes the registered sections of the current `Configuration#Other`,
+// the errors are logged, see `WithConfiguration`.
+func (app *Application) decodeSections() {
+	app.mu.Lock()
+	sections := make(map[string]interface{}, len(app.sections))
+	for name, dest := range app.sections {
+		sections[name] = dest
+	}
+	app.mu.Unlock()
+
+	for name, dest := range sections {
+		if err := app.decodeSection(name, dest); err != nil {
+			app.logger.Error(err)
+		}
+	}
+}
+
+func (app *Application) decodeSection(name string, dest interface{}) error {
+	// the Other is modified, see `UpdateConfiguration`.
+	app.configMu.Lock()
+	defer app.configMu.Unlock()
+
+	raw, ok := app.config.Other[name]
+	if !ok || raw == dest {
+		if app.config.Other == nil {
+			app.config.Other = make(map[string]interface{})
+		}
+		app.config.Other[name] = dest
+		app.publishConfigurationLocked()
+		return nil
+	}
+
+	if err := decodeSection(name, raw, dest); err != nil {
+		return err
+	}
+
+	app.config.Other[name] = dest
+	app.publishConfigurationLocked()
+	return nil
+}
+
 // ConfigurationReadOnly returns an object which doesn't allow field writing.
+// It's the published copy of the configuration, see `Configure` and `UpdateConfiguration`.
 func (app *Application) ConfigurationReadOnly() context.ConfigurationReadOnly {
-	return app.config
+	return app.liveConfig.Load().(*Configuration)
+}
+
+// OnConfigurationChange registers one or more listeners which are called
+// with the previous and the current configuration when the configuration is changed
+// while the server is running, see `UpdateConfiguration` and `WatchConfiguration`.
+func (app *Application) OnConfigurationChange(listeners ...func(old, new Configuration)) {
+	app.mu.Lock()
+	app.configListeners = append(app.configListeners, listeners...)
+	app.mu.Unlock()
+}
+
+// UpdateConfiguration hot-applies the live fields of the "c" configuration,
+// the ones which are read on each request: LogLevel, SlowRequestThreshold, the feature toggles
+// (FireMethodNotAllowed, DisablePathCorrection, DisablePathCorrectionRedirection,
+// DisableBodyConsumptionOnUnmarshal, DisableAutoFireStatusCode, RestrictRelativeRedirects),
+// RedirectAllowedHosts, TimeFormat, Charset, PostMaxMemory and Other.
+// The rest of the fields are ignored, they cannot be changed after `Run`.
+//
+// It's safe for concurrent use, the in-flight requests keep the previous configuration
+// and the `OnConfigurationChange` listeners are called if something is changed.
+// It reports whether the configuration was changed,
+// a configuration with an invalid TrustedProxies entry is logged and not applied.
+// 运行时热更新配置(仅限于每个请求都会读取的字段)
+func (app *Application) UpdateConfiguration(c Configuration) bool {
+	app.configMu.Lock()
+	defer app.configMu.Unlock()
+
+	old := *app.config
+	updated, err := old.withLiveFields(c)
+	if err != nil {
+		// i.e a typo in a watched configuration file, the current configuration is kept.
+		app.logger.Errorf("configuration: %v", err)
+		return false
+	}
+	if reflect.DeepEqual(old, updated) {
+		return false
+	}
+
+	if updated.LogLevel != old.LogLevel {
+		app.logger.SetLevel(updated.LogLevel)
+	}
+
+	// the readers get their own copy, the app.config can still be modified, i.e by the `ConfigureSection`.
+	*app.config = updated
+	app.publishConfigurationLocked()
+
+	app.mu.Lock()
+	listeners := app.configListeners
+	app.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(old, updated)
+	}
+
+	return true
+}
+
+// WatchConfiguration watches the "filename" yaml or toml configuration file
+// and hot-applies its live fields on each change, see `UpdateConfiguration`.
+// Note that it does not apply the file's current contents, use the `WithConfiguration` for that.
+//
+// The watcher is stopped on `Shutdown`.
+//
+// Usage:
+// app.Configure(iris.WithConfiguration(iris.YAML("./iris.yml")))
+// app.WatchConfiguration("./iris.yml")
+// app.OnConfigurationChange(func(old, new iris.Configuration) { ... })
+func (app *Application) WatchConfiguration(filename string) (*host.Watcher, error) {
+	return app.Watch([]string{filename}, func(evt host.WatchEvent) {
+		if evt.Op == host.WatchRemove {
+			return
+		}
+
+		c, err := parseConfigurationFile(filename)
+		if err != nil {
+			app.logger.Errorf("configuration: %s: %v", filename, err)
+			return
+		}
+
+		if app.UpdateConfiguration(c) {
+			app.logger.Infof("configuration: %s reloaded", filename)
+		}
+	})
 }
 
 // Logger returns the golog logger instance(pointer) that is being used inside the "app".
@@ -345,12 +578,481 @@ func (app *Application) View(writer io.Writer, filename string, layout string, b
 	return err
 }
 
+// Partials returns the shared partials registry of the view engines, the common fragments,
+// i.e headers, footers and components, are registered once and they're available to all the view engines,
+// through the {{ shared "name" . }} template function, and to the `context#ViewPartial`.
+// The partials are Go templates, the html engine can use them as its own templates too, i.e {{ template "name" . }}.
+//
+// Usage:
+// app.Partials().Reload(true) // on development, the partial files are reloaded on change.
+// app.Partials().AddFile("header", "./partials/header.html")
+// app.Partials().Add("alert", `<div class="alert">{{ .Message }}</div>`)
+// app.RegisterView(iris.HTML("./views", ".html"))
+// app.RegisterView(iris.Handlebars("./emails", ".hbs"))
+func (app *Application) Partials() *view.Partials {
+	return app.view.Partials()
+}
+
+// ViewPartial executes and writes the result of a shared partial to the writer,
+// see `Partials`.
+//
+// Use context.ViewPartial to render partials to the client instead.
+// Returns an error on failure, otherwise nil.
+func (app *Application) ViewPartial(writer io.Writer, partialName string, bindingData interface{}) error {
+	err := app.view.ExecutePartial(writer, partialName, bindingData)
+	if err != nil {
+		app.Logger().Error(err)
+	}
+	return err
+}
+
+// EnableViewCache enables the render cache of the view engines,
+// a rendered template is reused for "ttl" when it's executed again
+// with the same layout and the same view data, instead of re-executing it.
+// Use it for heavy templates which are served, mostly, to anonymous traffic.
+//
+// Look `InvalidateViewCache` too.
+func (app *Application) EnableViewCache(ttl time.Duration, maxEntries ...int) {
+	app.view.EnableRenderCache(ttl, maxEntries...)
+}
+
+// InvalidateViewCache removes the cached renders of the "templateFiles",
+// all of them if no templateFiles are given.
+//
+// Look `EnableViewCache` too.
+func (app *Application) InvalidateViewCache(templateFiles ...string) {
+	app.view.InvalidateRenderCache(templateFiles...)
+}
+
+// SetErrorView registers an error code handler for the "statusCode"
+// which renders the "templateFile", relative to the templates directory, through the registered view engine(s).
+// The template receives the following view data, in addition to any previous `ctx.ViewData`:
+// "StatusCode", "StatusText", "Path" and "RequestID", the latter is the "X-Request-Id" request header's value
+// or the context's string representation if missing.
+//
+// It falls back to the status text as plain text when no view engine is registered
+// or the template cannot be rendered.
+//
+// Usage:
+// app.RegisterView(iris.HTML("./views", ".html"))
+// app.SetErrorView(iris.StatusNotFound, "errors/404.html")
+// app.SetErrorView(iris.StatusInternalServerError, "errors/500.html")
+func (app *Application) SetErrorView(statusCode int, templateFile string) {
+	app.OnErrorCode(statusCode, func(ctx context.Context) {
+		if app.view.Len() > 0 {
+			requestID := ctx.GetHeader("X-Request-Id")
+			if requestID == "" {
+				requestID = ctx.String()
+			}
+
+			ctx.ViewData("StatusCode", statusCode)
+			ctx.ViewData("StatusText", http.StatusText(statusCode))
+			ctx.ViewData("Path", ctx.Path())
+			ctx.ViewData("RequestID", requestID)
+
+			if err := ctx.View(templateFile); err == nil {
+				return
+			}
+			// the error is already logged by the app.View,
+			// restore the status code and fallback to plain text.
+			ctx.StatusCode(statusCode)
+		}
+
+		ctx.ContentType(context.ContentTextHeaderValue)
+		ctx.WriteString(http.StatusText(statusCode))
+	})
+}
+
+// MountBlueprint registers the routes, the middleware, the static assets and the error handlers of the "b" blueprint
+// under the "relativePath", see `router#APIBuilder.MountBlueprint`, and its view templates
+// as shared partials, namespaced by the blueprint's name, i.e "blog/index.html", see `Partials`.
+// A template error is reported on `Build`.
+//
+// Usage:
+// app.RegisterView(iris.HTML("./views", ".html"))
+// app.MountBlueprint("/blog", blog.Blueprint())
+// and inside a handler of the blueprint: ctx.View("blog/index.html")
+//
+// Returns the party of the blueprint.
+func (app *Application) MountBlueprint(relativePath string, b *router.Blueprint) router.Party {
+	b.Templates(func(name string, contents string) {
+		if err := app.Partials().Add(name, contents); err != nil {
+			app.APIBuilder.GetReporter().Add("blueprint '%s': template '%s': %v", b.Name(), name, err)
+		}
+	})
+
+	return app.APIBuilder.MountBlueprint(relativePath, b)
+}
+
+// UseExecutionInterceptor registers one or more interceptors which wrap
+// each handler invocation of this application's requests,
+// an interceptor must call the "next" in order to execute the handler.
+// The first registered interceptor is the outer one.
+//
+// Unlike overriding the package-level `context.Next`, the interceptors are per application,
+// so they're safe to be used when more than one applications run in the same process.
+// They should be registered before `Run` or `Build`.
+//
+// Usage:
+// app.UseExecutionInterceptor(func(ctx iris.Context, next func()) {
+// 	start := time.Now()
+// 	next()
+// 	ctx.Application().Logger().Debugf("%s took %s", ctx.HandlerName(), time.Since(start))
+// })
+func (app *Application) UseExecutionInterceptor(interceptors ...context.ExecutionInterceptor) {
+	app.executionInterceptors = append(app.executionInterceptors, interceptors...)
+}
+
+// GetExecutionInterceptors returns the registered execution interceptors,
+// see `UseExecutionInterceptor`.
+func (app *Application) GetExecutionInterceptors() []context.ExecutionInterceptor {
+	return app.executionInterceptors
+}
+
+// Validator registers a struct validator which is called by the `ctx.ReadJSON`, `ctx.ReadXML` and `ctx.ReadForm`
+// after a successful unmarshal, its field errors are returned as `context.ValidationErrors`.
+//
+// Usage:
+// app.Validator(validator.New()) // github.com/go-playground/validator
+func (app *Application) Validator(v context.Validator) {
+	app.validator = v
+}
+
+// GetValidator returns the registered struct validator, see `Validator`.
+func (app *Application) GetValidator() context.Validator {
+	return app.validator
+}
+
+// Tracer registers the tracer of the requests, the router starts a span for each request of a route,
+// named after the route's method and template, i.e "GET /api/user/{id:uint64}", and ends it
+// with the response's status code, see `context#Tracer`.
+// Use the `context#NewTracer` for the W3C Trace Context or the `otel#New` for OpenTelemetry.
+//
+// Usage:
+//
+//	app.Tracer(context.NewTracer(func(s context.SpanData) {
+//		app.Logger().Infof("%s trace_id=%s took %s", s.Name, s.SpanContext.TraceIDString(), s.End.Sub(s.Start))
+//	}))
+func (app *Application) Tracer(t context.Tracer) {
+	app.tracer = t
+}
+
+// GetTracer returns the registered tracer, see `Tracer`.
+func (app *Application) GetTracer() context.Tracer {
+	return app.tracer
+}
+
+// RegisterLongLivedConn registers a long-lived connection, i.e a websocket or an SSE one,
+// the websocket module registers its connections automatically.
+// The shutdown of the application's hosts notifies the registered connections and closes them
+// after the `host#Supervisor.ShutdownGracePeriod`.
+// It returns a function which unregisters the connection, it should be called when the connection is closed.
+func (app *Application) RegisterLongLivedConn(conn context.LongLivedConn) (unregister func()) {
+	return app.connections.Register(conn)
+}
+
+// Metrics returns the Prometheus metrics of the requests, the request count, the duration and the response size
+// labeled by the route name, the method and the status code, and the in-flight requests.
+// The first call registers its instrumentation as a router middleware, see `UseRouter`,
+// so it should be called before `Run` or `Build`.
+//
+// Usage:
+//
+//	app.Get("/metrics", app.Metrics().Handler())
+func (app *Application) Metrics() *metrics.Metrics {
+	app.metricsOnce.Do(func() {
+		app.metrics = metrics.New()
+		app.UseRouter(app.metrics.Instrument)
+	})
+
+	return app.metrics
+}
+
+func (app *Application) getURLSigner() *router.URLSigner {
+	app.urlSignerOnce.Do(func() {
+		app.urlSigner = router.NewURLSigner([]byte(app.config.URLSigningKey), app.APIBuilder)
+	})
+
+	return app.urlSigner
+}
+
+// SignURL returns a session-less signed path of the "routeName" route, with the "params" values,
+// which expires after "ttl" (zero for never), the optional "claims" are signed as query parameters.
+// The route should be protected by the `RequireSignedURL` middleware.
+//
+// The HMAC key is the `Configuration#URLSigningKey`.
+//
+// Usage:
+// app.Get("/download/{file}", app.RequireSignedURL(), download).Name = "download"
+// link, err := app.SignURL("download", []interface{}{"report.pdf"}, time.Hour)
+func (app *Application) SignURL(routeName string, params []interface{}, ttl time.Duration, claims ...url.Values) (string, error) {
+	return app.getURLSigner().Sign(routeName, params, ttl, claims...)
+}
+
+// RequireSignedURL returns a middleware which allows only the valid, non-expired, signed urls
+// created by the `SignURL`, it fires 403 Forbidden otherwise.
+func (app *Application) RequireSignedURL() context.Handler {
+	return func(ctx context.Context) {
+		app.getURLSigner().Serve(ctx)
+	}
+}
+
+var errRouteNotFound = errors.New("route '%s' not found")
+
+// SwapHandlers replaces the main handlers of the "routeName" route at serve-time,
+// atomically and without a rebuild of the router, the in-flight requests are served by the old ones.
+// The route's middleware (`Use`, `UseGlobal`, `Done`...) and the execution rules are kept.
+// Useful for plugin systems and A/B rollouts of a handler.
+//
+// Usage:
+// app.Get("/greet", greetV1).Name = "greet"
+// ...
+// app.SwapHandlers("greet", greetV2)
+func (app *Application) SwapHandlers(routeName string, handlers ...context.Handler) error {
+	r := app.GetRoute(routeName)
+	if r == nil {
+		return errRouteNotFound.Format(routeName)
+	}
+
+	if err := r.SwapHandlers(handlers...); err != nil {
+		return err
+	}
+
+	// no-op if the router is not built yet, the route's handlers are used on build.
+	app.SwapRouteHandlers(r)
+	return nil
+}
+
+// RemoveRoute unregisters the "routeName" route, even at serve-time,
+// it's removed from the routes, so a `RefreshRouter` will not register it again,
+// and from the running router without a rebuild, the rest routes are not affected.
+// Useful for long-running servers which unregister the endpoints of a plugin.
+//
+// Usage:
+// app.Get("/plugins/stats", stats).Name = "plugin.stats"
+// ...
+// app.RemoveRoute("plugin.stats")
+func (app *Application) RemoveRoute(routeName string) error {
+	r := app.APIBuilder.RemoveRoute(routeName)
+	if r == nil {
+		return errRouteNotFound.Format(routeName)
+	}
+
+	// no-op if the router is not built yet.
+	app.UnregisterRoute(r)
+	return nil
+}
+
+// OnUpgrade registers a "handler" of a custom "protocol" which the clients can switch to
+// through the "Upgrade: protocol" and "Connection: Upgrade" request headers, on any path.
+// The handler receives the hijacked connection after the "101 Switching Protocols" response is sent.
+//
+// The upgrade requests of the protocols that are not registered, i.e the "websocket" ones,
+// are served by the routes as usual, so it can be used alongside the websocket module.
+// A nil "handler" unregisters the "protocol".
+//
+// Should be called before `Run`.
+//
+// Usage:
+// app.OnUpgrade("my-protocol", func(ctx iris.Context, conn net.Conn, rw *bufio.ReadWriter) {
+//   line, _ := rw.ReadString('\n')
+//   rw.WriteString("echo: " + line)
+//   rw.Flush()
+// })
+func (app *Application) OnUpgrade(protocol string, handler UpgradeHandler) {
+	app.upgradesOnce.Do(func() {
+		app.upgrades = router.NewUpgradeRegistry()
+		app.WrapRouter(app.upgrades.Wrapper(app.ContextPool))
+	})
+
+	app.upgrades.Handle(protocol, handler)
+}
+
+// mailerDrainTimeout is the time that the mailer has to deliver its queued messages on shutdown.
+const mailerDrainTimeout = 10 * time.Second
+
+// ConfigureMailer creates the SMTP mailer of the "c" configuration, which is returned by the `Mailer`.
+// The `Message#Template` is rendered by the registered view engines, see `RegisterView`.
+// The queued messages are delivered on the application's shutdown, when its last host is shut down
+// or on `Shutdown`, for up to 10 seconds.
+//
+// Should be called once, before `Run`.
+//
+// Usage:
+// app.ConfigureMailer(mailer.Config{
+//   Host: "smtp.example.com", Username: "apikey", Password: os.Getenv("SMTP_PASSWORD"),
+//   From: "Example <noreply@example.com>",
+// })
+func (app *Application) ConfigureMailer(c mailer.Config) *mailer.Mailer {
+	m := mailer.New(c, app.View)
+
+	app.mu.Lock()
+	app.mailer = m
+	app.mu.Unlock()
+
+	return m
+}
+
+// Mailer returns the SMTP mailer, it's created with the `mailer.DefaultConfig`
+// (localhost:587) if the `ConfigureMailer` was not called before.
+//
+// Usage:
+// app.Post("/signup", func(ctx iris.Context) {
+//   // [...]
+//   err := app.Mailer().Send(&mailer.Message{To: []string{email}, Subject: "Welcome", Template: "emails/welcome.html", Data: user})
+// })
+func (app *Application) Mailer() *mailer.Mailer {
+	app.mailerOnce.Do(func() {
+		if app.mailer == nil {
+			app.ConfigureMailer(mailer.DefaultConfig())
+		}
+	})
+
+	return app.mailer
+}
+
+// eventsDrainTimeout is the time that the asynchronous subscribers have to receive their queued events on shutdown.
+const eventsDrainTimeout = 10 * time.Second
+
+// Events returns the in-memory publish/subscribe bus of the application,
+// the components, i.e the realtime modules and the caches, can communicate through topics without global variables.
+// The errors of the asynchronous subscribers are logged and their queued events
+// are drained on the application's shutdown, when its last host is shut down
+// or on `Shutdown`, for up to 10 seconds.
+//
+// The render cache of the views is invalidated on the `events.TopicViewInvalidate`,
+// see the `cache/client#Handler.PurgeOn` and the `websocket#Server.EmitEvents` too.
+//
+// Usage:
+//
+//	app.Events().Subscribe("products.updated", func(evt events.Event) {
+//		productsCache.Delete(evt.Payload.(*Product).ID)
+//	})
+//	app.Put("/products/{id:uint64}", func(ctx iris.Context) {
+//		// [...]
+//		app.Events().Publish("products.updated", product)
+//	})
+func (app *Application) Events() *events.Bus {
+	app.eventsOnce.Do(func() {
+		bus := events.New(events.Config{
+			OnError: func(evt events.Event, err error) {
+				app.logger.Errorf("events: %s: %v", evt.Topic, err)
+			},
+		})
+		bus.Subscribe(events.TopicViewInvalidate, func(evt events.Event) {
+			app.InvalidateViewCache(events.Strings(evt.Payload)...)
+		})
+
+		app.mu.Lock()
+		app.events = bus
+		app.mu.Unlock()
+	})
+
+	return app.events
+}
+
+// onHostShutdown is registered to each host, see `NewHost`,
+// when the last one is shut down it waits for the in-flight requests,
+// up to the drain timeouts, and it closes the application's components.
+func (app *Application) onHostShutdown() {
+	app.mu.Lock()
+	app.liveHosts--
+	last := app.liveHosts <= 0
+	app.mu.Unlock()
+
+	if !last {
+		return
+	}
+
+	// the requests may still queue messages and events.
+	deadline := time.Now().Add(mailerDrainTimeout)
+	for app.ContextPool.ActiveRequests() > 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	app.closeComponents()
+}
+
+// closeComponents drains and closes the events bus and the mailer, if they are used, once.
+// The events are drained first, their subscribers may send messages.
+func (app *Application) closeComponents() {
+	app.closeOnce.Do(func() {
+		app.mu.Lock()
+		bus, m := app.events, app.mailer
+		app.mu.Unlock()
+
+		if bus != nil {
+			ctx, cancel := stdContext.WithTimeout(stdContext.Background(), eventsDrainTimeout)
+			if err := bus.Close(ctx); err != nil {
+				app.logger.Warnf("events: %d queued events are dropped: %v", bus.Pending(), err)
+			}
+			cancel()
+		}
+
+		if m != nil {
+			ctx, cancel := stdContext.WithTimeout(stdContext.Background(), mailerDrainTimeout)
+			if err := m.Close(ctx); err != nil {
+				app.logger.Warnf("mailer: %d queued messages are dropped: %v", m.Pending(), err)
+			}
+			cancel()
+		}
+	})
+}
+
+// UseSessions registers the sessions manager's middleware to all routes,
+// the session of each request is started before any other handler
+// and it can be retrieved through the `sessions.Get(ctx)`.
+//
+// Usage:
+// sess := sessions.New(sessions.Config{Cookie: "sessionid", Expires: 2 * time.Hour})
+// db, _ := file.New("./sessions", 0)
+// sess.UseDatabase(db)
+// app.UseSessions(sess)
+func (app *Application) UseSessions(sess *sessions.Sessions) {
+	app.UseGlobal(sess.Handler())
+}
+
 var (
 	// LimitRequestBodySize is a middleware which sets a request body size limit
 	// for all next handlers in the chain.
 	//
 	// A shortcut for the `context#LimitRequestBodySize`.
 	LimitRequestBodySize = context.LimitRequestBodySize
+	// NegotiationOffers is a middleware which sets the content types
+	// that the `context#Negotiate` of the next handlers offers.
+	//
+	// A shortcut for the `context#NegotiationOffers`.
+	NegotiationOffers = context.NegotiationOffers
+	// LimitResponseBodySize is a middleware which sets a response body size limit
+	// for all next handlers in the chain.
+	//
+	// A shortcut for the `context#LimitResponseBodySize`.
+	LimitResponseBodySize = context.LimitResponseBodySize
+	// ETagHandler is a middleware which sends the "ETag" header of the next handlers' response body
+	// and a 304 "Not Modified" when the client's "If-None-Match" matches it.
+	// Usage: app.Get("/users", iris.ETagHandler(false), listUsers)
+	//
+	// A shortcut for the `context#ETagHandler`.
+	ETagHandler = context.ETagHandler
+	// ProxyRewriteRequest registers a hook which modifies the outgoing request
+	// of a `Context#ReverseProxy` or `Context#Forward` call.
+	//
+	// A shortcut for the `context#ProxyRewriteRequest`.
+	ProxyRewriteRequest = context.ProxyRewriteRequest
+	// ProxyRewriteResponse registers a hook which modifies the target's response
+	// of a `Context#ReverseProxy` or `Context#Forward` call.
+	//
+	// A shortcut for the `context#ProxyRewriteResponse`.
+	ProxyRewriteResponse = context.ProxyRewriteResponse
+	// ProblemHandler renders the current error status code as an RFC 7807 problem details response.
+	// Usage: app.OnAnyErrorCode(iris.ProblemHandler)
+	//
+	// A shortcut for the `context#ProblemHandler`.
+	ProblemHandler = context.ProblemHandler
+	// NewProblem returns a new Problem to be rendered by the `context#Problem`.
+	//
+	// A shortcut for the `context#NewProblem`.
+	NewProblem = context.NewProblem
 	// StaticEmbeddedHandler returns a Handler which can serve
 	// embedded into executable files.
 	//
@@ -455,6 +1157,21 @@ var (
 	//
 	// A shortcut for the `context#CookieHTTPOnly`.
 	CookieHTTPOnly = context.CookieHTTPOnly
+	// CookieSameSite is a `CookieOption`.
+	// Use it to set the cookie's SameSite field, i.e `http.SameSiteLaxMode`.
+	//
+	// A shortcut for the `context#CookieSameSite`.
+	CookieSameSite = context.CookieSameSite
+	// CookieSecure is a `CookieOption`.
+	// Use it to set the cookie's Secure field to false or true.
+	//
+	// A shortcut for the `context#CookieSecure`.
+	CookieSecure = context.CookieSecure
+	// CookieDomain is a `CookieOption`.
+	// Use it to change the cookie's Domain field.
+	//
+	// A shortcut for the `context#CookieDomain`.
+	CookieDomain = context.CookieDomain
 	// CookieEncode is a `CookieOption`.
 	// Provides encoding functionality when adding a cookie.
 	// Accepts a `context#CookieEncoder` and sets the cookie's value to the encoded value.
@@ -487,11 +1204,13 @@ var (
 // it's a helper function which just makes some checks based on the `IndexNames` and `AssetValidators`
 // before the assetHandler call.
 //
+// It serves the GET and HEAD requests which do not match a route, so the rest routes are kept intact,
+// use the `SPABuilder#Exclude` to fire 404 for the unmatched paths of an API.
+// See `Party#SPA` for the single page applications of a Party.
+//
 // Example: https://github.com/kataras/iris/tree/master/_examples/file-server/single-page-application
 func (app *Application) SPA(assetHandler context.Handler) *router.SPABuilder {
-	s := router.NewSPABuilder(assetHandler)
-	app.APIBuilder.HandleMany("GET HEAD", "/{f:path}", s.Handler)
-	return s
+	return app.APIBuilder.SPA(assetHandler)
 }
 
 // ConfigureHost accepts one or more `host#Configuration`, these configurators functions
@@ -545,6 +1264,13 @@ func (app *Application) NewHost(srv *http.Server) *host.Supervisor {
 	// bind the constructed server and return it
 	// 这里返回一个Supervisor
 	su := host.New(srv)
+	// the in-flight requests of the drain phase, see `Supervisor#DrainTimeout`.
+	su.RequestCounter = app.ContextPool
+	// the long-lived connections, i.e the websocket ones, are notified on shutdown.
+	su.Connections = app.connections
+	// the mailer and the events bus are closed when the last host is shut down.
+	app.liveHosts++
+	su.RegisterOnShutdown(app.onHostShutdown)
 
 	if app.config.vhost == "" { // vhost now is useful for router subdomain on wildcard subdomains,
 		// in order to correct decide what to do on:
@@ -554,6 +1280,19 @@ func (app *Application) NewHost(srv *http.Server) *host.Supervisor {
 		// sub.localhost -> valid
 		// we need the host (without port if 80 or 443) in order to validate these, so:
 		app.config.vhost = netutil.ResolveVHost(srv.Addr)
+		app.publishConfiguration()
+	}
+
+	if netutil.ResolvePort(app.config.vhost) == 0 {
+		// the random port of the ":0" address is known when the server is listening.
+		su.RegisterOnListen(func(addr net.Addr) {
+			if _, port, err := net.SplitHostPort(addr.String()); err == nil {
+				app.configMu.Lock()
+				app.config.vhost = netutil.ResolveVHost(netutil.ResolveHostname(app.config.vhost) + ":" + port)
+				app.publishConfigurationLocked()
+				app.configMu.Unlock()
+			}
+		})
 	}
 
 	app.logger.Debugf("Host: virtual host is %s", app.config.vhost)
@@ -592,9 +1331,12 @@ func (app *Application) NewHost(srv *http.Server) *host.Supervisor {
 // A shortcut for the `host#RegisterOnInterrupt`.
 var RegisterOnInterrupt = host.RegisterOnInterrupt
 
-// Shutdown gracefully terminates all the application's server hosts.
+// Shutdown gracefully terminates all the application's server hosts
+// and then it drains and closes the events bus and the mailer, if they are used.
 // Returns an error on the first failure, otherwise nil.
 func (app *Application) Shutdown(ctx stdContext.Context) error {
+	app.stopWatchers()
+
 	for i, su := range app.Hosts {
 		app.logger.Debugf("Host[%d]: Shutdown now", i)
 		if err := su.Shutdown(ctx); err != nil {
@@ -602,9 +1344,63 @@ func (app *Application) Shutdown(ctx stdContext.Context) error {
 			return err
 		}
 	}
+
+	app.closeComponents()
 	return nil
 }
 
+// Watch watches the "paths", files or directories (recursively), for changes
+// and calls the "onEvent" for each changed file, on a single goroutine.
+// Useful to reload configuration files, certificates and templates, see `WatchViews`,
+// without each feature spawning its own watcher goroutines.
+//
+// The watchers are stopped on `Shutdown`.
+// Returns the started watcher or an error if a path cannot be read.
+//
+// Usage:
+// app.Watch([]string{"./config"}, func(evt host.WatchEvent) {
+// 	app.Logger().Infof("%s: %s", evt.Op, evt.Path)
+// })
+//
+// See `WatchViews` for the templates.
+func (app *Application) Watch(paths []string, onEvent func(host.WatchEvent)) (*host.Watcher, error) {
+	w := host.NewWatcher(paths, onEvent)
+	if err := w.Start(); err != nil {
+		return nil, err
+	}
+
+	app.mu.Lock()
+	app.watchers = append(app.watchers, w)
+	app.mu.Unlock()
+	return w, nil
+}
+
+// WatchViews watches the templates directories of the registered view engines
+// and the files of the shared partials, see `Partials`, for changes,
+// the templates are loaded again before the next render of a change and the view cache is invalidated.
+// Unlike the engines' `Reload`, the templates are not parsed on each render.
+// It should be called after the view engines are registered.
+//
+// Usage:
+// app.RegisterView(iris.HTML("./views", ".html"))
+// app.WatchViews()
+func (app *Application) WatchViews() (*host.Watcher, error) {
+	return app.Watch(app.view.Paths(), func(host.WatchEvent) {
+		app.view.Reload()
+	})
+}
+
+func (app *Application) stopWatchers() {
+	app.mu.Lock()
+	watchers := app.watchers
+	app.watchers = nil
+	app.mu.Unlock()
+
+	for _, w := range watchers {
+		w.Stop()
+	}
+}
+
 // Runner is just an interface which accepts the framework instance
 // and returns an error.
 //
@@ -634,6 +1430,7 @@ func Listener(l net.Listener, hostConfigs ...host.Configurator) Runner {
 	return func(app *Application) error {
 		// todo 这里是获得虚拟的主机地址，但是怎么使用呢?
 		app.config.vhost = netutil.ResolveVHost(l.Addr().String())
+		app.publishConfiguration()
 		// 下面部分与Addr()类似，只是Addr的listener是通过addr分析得出的
 		return app.NewHost(&http.Server{Addr: l.Addr().String()}).
 			Configure(hostConfigs...).
@@ -790,13 +1587,16 @@ func (app *Application) Build() error {
 			// router
 			// create the request handler, the default routing handler
 			routerHandler := router.NewDefaultHandler()
+			if app.config.EnableHandlerTiming {
+				routerHandler = router.NewTimingHandler()
+			}
 			// 这里的app.Router.BuildRouter()是最核心的地方
 			rp.Describe("router: %v", app.Router.BuildRouter(app.ContextPool, routerHandler, app.APIBuilder, false))
 			// re-build of the router from outside can be done with;
 			// app.RefreshRouter()
 		}
 
-		if app.view.Len() > 0 {
+		if app.view.Len() > 0 || app.view.HasPartials() {
 			app.logger.Debugf("Application: %d registered view engine(s)", app.view.Len())
 			// view engine
 			// here is where we declare the closed-relative framework functions.
@@ -838,6 +1638,14 @@ func (app *Application) Run(serve Runner, withOrWithout ...Configurator) error {
 	}
 	//这里专门针对当前的application进行配置
 	app.Configure(withOrWithout...)
+	if len(app.config.TrustedProxies) == 0 {
+		for headerName, enabled := range app.config.RemoteAddrHeaders {
+			if enabled {
+				// the headers were trusted from any peer before the TrustedProxies.
+				app.logger.Warnf("Application: the remote address header '%s' is ignored without trusted proxies, see WithTrustedProxies", headerName)
+			}
+		}
+	}
 	app.logger.Debugf("Application: running using %d host(s)", len(app.Hosts)+1)
 
 	// this will block until an error(unless supervisor's DeferFlow called from a Task).
@@ -848,3 +1656,57 @@ func (app *Application) Run(serve Runner, withOrWithout ...Configurator) error {
 
 	return err
 }
+
+// ErrRunTimeout is returned by the `RunAndWaitReady` when the server is not listening in time.
+var ErrRunTimeout = errors.New("server is not listening after %s")
+
+// RunAndWaitReady calls the `Run` in the background and waits until the server of the "serve" Runner
+// is accepting connections, instead of a time.Sleep.
+// Returns its actual bound address, i.e the random port of the `Addr(":0")`,
+// or the error of the `Run` if it's failed before that
+// or the `ErrRunTimeout` if the "timeout", when positive, is exceeded.
+// The server keeps running until the `Shutdown`, its later errors are logged by the `Run`.
+//
+// Usage:
+//
+//	addr, err := app.RunAndWaitReady(iris.Addr("127.0.0.1:0"), 5*time.Second, iris.WithoutStartupLog)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer app.Shutdown(context.Background())
+//	resp, err := http.Get("http://" + addr.String() + "/health")
+//
+// 在后台运行服务, 等待监听成功后返回实际的地址(例如 :0 随机分配的端口), 不再需要 time.Sleep
+func (app *Application) RunAndWaitReady(serve Runner, timeout time.Duration, withOrWithout ...Configurator) (net.Addr, error) {
+	ready := make(chan net.Addr, 1)
+	var once sync.Once
+	app.ConfigureHost(func(su *host.Supervisor) {
+		su.RegisterOnListen(func(addr net.Addr) {
+			once.Do(func() { ready <- addr })
+		})
+	})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- app.Run(serve, withOrWithout...)
+	}()
+
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	select {
+	case addr := <-ready:
+		return addr, nil
+	case err := <-errCh:
+		if err == nil {
+			err = http.ErrServerClosed
+		}
+		return nil, err
+	case <-timeoutCh:
+		return nil, ErrRunTimeout.Format(timeout)
+	}
+}
@@ -0,0 +1,225 @@
+package iris
+
+import (
+	"bytes"
+	stdContext "context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/core/host"
+	"github.com/kataras/iris/events"
+	"github.com/kataras/iris/mailer"
+)
+
+// newTestHosts returns the "n" serving hosts of the "app".
+func newTestHosts(t *testing.T, app *Application, n int) []*host.Supervisor {
+	app.Logger().SetLevel("disable")
+	app.Configure(WithoutStartupLog, WithoutInterruptHandler, func(app *Application) {
+		// the virtual host is not resolved by the random ports.
+		app.config.vhost = "localhost:8080"
+	})
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := make([]*host.Supervisor, n)
+	for i := range hosts {
+		hosts[i] = app.NewHost(&http.Server{Addr: "127.0.0.1:0"})
+		go hosts[i].ListenAndServe()
+	}
+
+	for _, su := range hosts {
+		for i := 0; su.Addr() == nil; i++ {
+			if i == 500 {
+				t.Fatal("timeout while waiting for the listener")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	return hosts
+}
+
+func TestMailerCloseOnLastHostShutdown(t *testing.T) {
+	app := New()
+	hosts := newTestHosts(t, app, 2)
+
+	// the mailer is created after the hosts, i.e by a handler.
+	app.ConfigureMailer(mailer.Config{Host: "127.0.0.1", Port: 1, MaxRetries: -1})
+	msg := &mailer.Message{From: "from@example.com", To: []string{"to@example.com"}, Subject: "subject", Text: "text"}
+
+	if err := hosts[0].Shutdown(stdContext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	// the rest of the hosts are still serving, the mailer should not be closed.
+	if err := app.Mailer().Send(msg); err != nil {
+		t.Fatalf("expected the mailer to be open but got: %v", err)
+	}
+
+	if err := hosts[1].Shutdown(stdContext.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; !mailer.ErrClosed.Equal(app.Mailer().Send(msg)); i++ {
+		if i == 500 {
+			t.Fatal("expected the mailer to be closed after the shutdown of the last host")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMailerCloseOnShutdown(t *testing.T) {
+	app := New()
+	m := app.Mailer()
+
+	// no hosts, i.e behind the lambda adapter.
+	if err := app.Shutdown(stdContext.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &mailer.Message{From: "from@example.com", To: []string{"to@example.com"}, Subject: "subject", Text: "text"}
+	if err := m.Send(msg); !mailer.ErrClosed.Equal(err) {
+		t.Fatalf("expected the mailer to be closed but got: %v", err)
+	}
+}
+
+func TestEventsCloseOnLastHostShutdown(t *testing.T) {
+	app := New()
+	hosts := newTestHosts(t, app, 2)
+
+	// the bus is created after the hosts, i.e by a handler.
+	bus := app.Events()
+	delivered := make(chan struct{})
+	bus.SubscribeAsync("slow", func(evt events.Event) {
+		time.Sleep(50 * time.Millisecond)
+		close(delivered)
+	})
+
+	if err := hosts[0].Shutdown(stdContext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	// the rest of the hosts are still serving, the bus should not be closed.
+	if err := bus.Publish("slow", nil); err != nil {
+		t.Fatalf("expected the bus to be open but got: %v", err)
+	}
+
+	if err := hosts[1].Shutdown(stdContext.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the queued event to be drained")
+	}
+
+	for i := 0; !events.ErrClosed.Equal(bus.Publish("slow", nil)); i++ {
+		if i == 500 {
+			t.Fatal("expected the bus to be closed after the shutdown of the last host")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEventsCloseOnShutdown(t *testing.T) {
+	app := New()
+	bus := app.Events()
+
+	if err := app.Shutdown(stdContext.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.Publish("any", nil); !events.ErrClosed.Equal(err) {
+		t.Fatalf("expected the bus to be closed but got: %v", err)
+	}
+}
+
+func TestEventsViewInvalidate(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("{{ renders }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var renders int32
+	engine := HTML(dir, ".html")
+	engine.AddFunc("renders", func() int32 { return atomic.AddInt32(&renders, 1) })
+
+	app := New()
+	app.RegisterView(engine)
+	app.EnableViewCache(time.Minute)
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	render := func() string {
+		var b bytes.Buffer
+		if err := app.View(&b, "index.html", "", nil); err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+
+	if expected, got := "1", render(); expected != got {
+		t.Fatalf("expected '%s' but got '%s'", expected, got)
+	}
+	if expected, got := "1", render(); expected != got {
+		t.Fatalf("expected the cached '%s' but got '%s'", expected, got)
+	}
+
+	app.Events().Publish(events.TopicViewInvalidate, "index.html")
+	if expected, got := "2", render(); expected != got {
+		t.Fatalf("expected '%s' after the invalidation but got '%s'", expected, got)
+	}
+}
+
+func TestWatchViews(t *testing.T) {
+	interval := host.DefaultWatchInterval
+	host.DefaultWatchInterval = 10 * time.Millisecond
+	defer func() { host.DefaultWatchInterval = interval }()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "index.html")
+	if err := ioutil.WriteFile(filename, []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := New()
+	app.RegisterView(HTML(dir, ".html"))
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.WatchViews(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.stopWatchers()
+
+	render := func() string {
+		buf := new(bytes.Buffer)
+		if err := app.View(buf, "index.html", "", nil); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	if expected, got := "v1", render(); expected != got {
+		t.Fatalf("expected %q but got %q", expected, got)
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("v2 changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for render() != "v2 changed" {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout while waiting for the changed template to be rendered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,82 @@
+package lambda
+
+// The event types are compatible, field by field (JSON), with the ones of the
+// github.com/aws/aws-lambda-go/events package, so the adapter's methods can be passed
+// directly to the `lambda.Start` of the aws-lambda-go runtime, without depending on it.
+
+// APIGatewayProxyRequest is the request event of an API Gateway (REST API) Lambda proxy integration.
+type APIGatewayProxyRequest struct {
+	Resource                        string                        `json:"resource"`
+	Path                            string                        `json:"path"`
+	HTTPMethod                      string                        `json:"httpMethod"`
+	Headers                         map[string]string             `json:"headers"`
+	MultiValueHeaders               map[string][]string           `json:"multiValueHeaders"`
+	QueryStringParameters           map[string]string             `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string           `json:"multiValueQueryStringParameters"`
+	PathParameters                  map[string]string             `json:"pathParameters"`
+	StageVariables                  map[string]string             `json:"stageVariables"`
+	RequestContext                  APIGatewayProxyRequestContext `json:"requestContext"`
+	Body                            string                        `json:"body"`
+	IsBase64Encoded                 bool                          `json:"isBase64Encoded,omitempty"`
+}
+
+// APIGatewayProxyRequestContext contains the information about the request's API Gateway context.
+type APIGatewayProxyRequestContext struct {
+	AccountID    string                    `json:"accountId"`
+	ResourceID   string                    `json:"resourceId"`
+	Stage        string                    `json:"stage"`
+	RequestID    string                    `json:"requestId"`
+	DomainName   string                    `json:"domainName"`
+	Identity     APIGatewayRequestIdentity `json:"identity"`
+	ResourcePath string                    `json:"resourcePath"`
+	HTTPMethod   string                    `json:"httpMethod"`
+	APIID        string                    `json:"apiId"`
+}
+
+// APIGatewayRequestIdentity contains the identity information of the request's caller.
+type APIGatewayRequestIdentity struct {
+	SourceIP  string `json:"sourceIp"`
+	UserAgent string `json:"userAgent"`
+}
+
+// APIGatewayProxyResponse is the response of an API Gateway (REST API) Lambda proxy integration.
+type APIGatewayProxyResponse struct {
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded,omitempty"`
+}
+
+// ALBTargetGroupRequest is the request event of an Application Load Balancer Lambda target.
+type ALBTargetGroupRequest struct {
+	HTTPMethod                      string                       `json:"httpMethod"`
+	Path                            string                       `json:"path"`
+	QueryStringParameters           map[string]string            `json:"queryStringParameters,omitempty"`
+	MultiValueQueryStringParameters map[string][]string          `json:"multiValueQueryStringParameters,omitempty"`
+	Headers                         map[string]string            `json:"headers,omitempty"`
+	MultiValueHeaders               map[string][]string          `json:"multiValueHeaders,omitempty"`
+	RequestContext                  ALBTargetGroupRequestContext `json:"requestContext"`
+	IsBase64Encoded                 bool                         `json:"isBase64Encoded"`
+	Body                            string                       `json:"body"`
+}
+
+// ALBTargetGroupRequestContext contains the information about the load balancer that invoked the function.
+type ALBTargetGroupRequestContext struct {
+	ELB ELBContext `json:"elb"`
+}
+
+// ELBContext contains the information about the target group.
+type ELBContext struct {
+	TargetGroupArn string `json:"targetGroupArn"`
+}
+
+// ALBTargetGroupResponse is the response of an Application Load Balancer Lambda target.
+type ALBTargetGroupResponse struct {
+	StatusCode        int                 `json:"statusCode"`
+	StatusDescription string              `json:"statusDescription"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
@@ -0,0 +1,224 @@
+// Package lambda provides an adapter which runs an iris Application on AWS Lambda,
+// behind an API Gateway proxy integration or an Application Load Balancer.
+// The events are converted to *http.Request values and they're served by the Router directly,
+// without a server or a host supervisor, the recorded responses are returned in the event's format.
+//
+// Usage, with the github.com/aws/aws-lambda-go runtime:
+// app := iris.New()
+// app.Get("/", index)
+// adapter, err := lambda.New(app)
+// if err != nil { ... }
+// awslambda.Start(adapter.ProxyWithContext)
+package lambda
+
+import (
+	stdContext "context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kataras/iris"
+)
+
+// Adapter converts the Lambda events to http requests and feeds the application's Router.
+type Adapter struct {
+	handler http.Handler
+}
+
+// New builds the "app" and returns a new Adapter for it.
+func New(app *iris.Application) (*Adapter, error) {
+	if err := app.Build(); err != nil {
+		return nil, err
+	}
+
+	return &Adapter{handler: app}, nil
+}
+
+// ProxyWithContext serves an API Gateway proxy integration's request event.
+func (a *Adapter) ProxyWithContext(ctx stdContext.Context, event APIGatewayProxyRequest) (APIGatewayProxyResponse, error) {
+	r, err := newRequest(ctx, event.HTTPMethod, event.Path,
+		event.QueryStringParameters, event.MultiValueQueryStringParameters,
+		event.Headers, event.MultiValueHeaders, event.Body, event.IsBase64Encoded)
+	if err != nil {
+		return APIGatewayProxyResponse{}, err
+	}
+	r.RemoteAddr = event.RequestContext.Identity.SourceIP
+	if r.Host == "" {
+		r.Host = event.RequestContext.DomainName
+	}
+	if event.RequestContext.RequestID != "" && r.Header.Get("X-Request-Id") == "" {
+		r.Header.Set("X-Request-Id", event.RequestContext.RequestID)
+	}
+
+	w := newRecorder()
+	a.handler.ServeHTTP(w, r)
+
+	body, isBase64 := w.encodedBody()
+	return APIGatewayProxyResponse{
+		StatusCode:        w.statusCode,
+		Headers:           singleValueHeaders(w.header),
+		MultiValueHeaders: w.header,
+		Body:              body,
+		IsBase64Encoded:   isBase64,
+	}, nil
+}
+
+// Proxy same as `ProxyWithContext` but with a background context.
+func (a *Adapter) Proxy(event APIGatewayProxyRequest) (APIGatewayProxyResponse, error) {
+	return a.ProxyWithContext(stdContext.Background(), event)
+}
+
+// ALBWithContext serves an Application Load Balancer's request event.
+func (a *Adapter) ALBWithContext(ctx stdContext.Context, event ALBTargetGroupRequest) (ALBTargetGroupResponse, error) {
+	r, err := newRequest(ctx, event.HTTPMethod, event.Path,
+		event.QueryStringParameters, event.MultiValueQueryStringParameters,
+		event.Headers, event.MultiValueHeaders, event.Body, event.IsBase64Encoded)
+	if err != nil {
+		return ALBTargetGroupResponse{}, err
+	}
+	if ip := lastForwardedFor(r.Header); ip != "" {
+		r.RemoteAddr = ip
+	}
+
+	w := newRecorder()
+	a.handler.ServeHTTP(w, r)
+
+	body, isBase64 := w.encodedBody()
+	resp := ALBTargetGroupResponse{
+		StatusCode:        w.statusCode,
+		StatusDescription: strconv.Itoa(w.statusCode) + " " + http.StatusText(w.statusCode),
+		Body:              body,
+		IsBase64Encoded:   isBase64,
+	}
+	// the load balancer accepts either the single or the multi value headers,
+	// based on the target group's configuration, the same as the request's ones.
+	if event.MultiValueHeaders != nil {
+		resp.MultiValueHeaders = w.header
+	} else {
+		resp.Headers = singleValueHeaders(w.header)
+	}
+
+	return resp, nil
+}
+
+// ALB same as `ALBWithContext` but with a background context.
+func (a *Adapter) ALB(event ALBTargetGroupRequest) (ALBTargetGroupResponse, error) {
+	return a.ALBWithContext(stdContext.Background(), event)
+}
+
+func newRequest(ctx stdContext.Context, method, path string,
+	query map[string]string, multiValueQuery map[string][]string,
+	headers map[string]string, multiValueHeaders map[string][]string,
+	body string, isBase64Encoded bool) (*http.Request, error) {
+
+	values := make(url.Values)
+	for key, value := range query {
+		values.Set(key, value)
+	}
+	for key, v := range multiValueQuery {
+		values[key] = v
+	}
+
+	u := &url.URL{Path: path, RawQuery: values.Encode()}
+
+	var bodyReader io.Reader = strings.NewReader(body)
+	if isBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = strings.NewReader(string(decoded))
+	}
+
+	r, err := http.NewRequest(method, u.String(), bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range headers {
+		r.Header.Set(key, value)
+	}
+	for key, v := range multiValueHeaders {
+		r.Header[http.CanonicalHeaderKey(key)] = v
+	}
+
+	r.Host = r.Header.Get("Host")
+	r.RequestURI = u.RequestURI()
+	if r.Header.Get("X-Forwarded-Proto") == "https" {
+		// API Gateway and ALB terminate the TLS, see `context.IsTLS`.
+		r.URL.Scheme = "https"
+	}
+
+	return r.WithContext(ctx), nil
+}
+
+// lastForwardedFor returns the last entry of the "X-Forwarded-For" header,
+// which is the one appended by the load balancer itself,
+// the rest of them are sent by the client and they can't be trusted.
+func lastForwardedFor(h http.Header) string {
+	values := h["X-Forwarded-For"]
+	for i := len(values) - 1; i >= 0; i-- {
+		entries := strings.Split(values[i], ",")
+		if ip := strings.TrimSpace(entries[len(entries)-1]); ip != "" {
+			return ip
+		}
+	}
+	return ""
+}
+
+func singleValueHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for key, values := range h {
+		if len(values) > 0 {
+			headers[key] = strings.Join(values, ",")
+		}
+	}
+	return headers
+}
+
+// recorder is the http.ResponseWriter of the events' requests.
+type recorder struct {
+	header      http.Header
+	statusCode  int
+	wroteHeader bool
+	body        []byte
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: make(http.Header), statusCode: http.StatusOK}
+}
+
+func (w *recorder) Header() http.Header {
+	return w.header
+}
+
+func (w *recorder) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.statusCode = statusCode
+	w.wroteHeader = true
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.body = append(w.body, b...)
+	return len(b), nil
+}
+
+// Flush is a no-op, the response is returned as a whole.
+func (w *recorder) Flush() {}
+
+// encodedBody returns the body as it is if it's a valid text,
+// otherwise base64 encoded and true.
+func (w *recorder) encodedBody() (string, bool) {
+	if w.header.Get("Content-Encoding") == "" && utf8.Valid(w.body) {
+		return string(w.body), false
+	}
+
+	return base64.StdEncoding.EncodeToString(w.body), true
+}
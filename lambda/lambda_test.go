@@ -0,0 +1,104 @@
+package lambda
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"testing"
+
+	"github.com/kataras/iris"
+)
+
+func newTestAdapter(t *testing.T) *Adapter {
+	app := iris.New()
+	app.Post("/users/{id}", func(ctx iris.Context) {
+		body, _ := ioutil.ReadAll(ctx.Request().Body)
+		ctx.Header("X-Id", ctx.Params().Get("id"))
+		ctx.StatusCode(iris.StatusCreated)
+		ctx.Writef("%s %s %s %s", ctx.URLParam("q"), ctx.GetHeader("X-Custom"), body, ctx.RemoteAddr())
+	})
+	app.Get("/binary", func(ctx iris.Context) {
+		ctx.Write([]byte{0xff, 0xfe, 0x00})
+	})
+
+	adapter, err := New(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return adapter
+}
+
+func TestProxy(t *testing.T) {
+	adapter := newTestAdapter(t)
+
+	resp, err := adapter.Proxy(APIGatewayProxyRequest{
+		HTTPMethod:            "POST",
+		Path:                  "/users/42",
+		QueryStringParameters: map[string]string{"q": "search"},
+		Headers:               map[string]string{"X-Custom": "custom", "Host": "api.example.com"},
+		Body:                  base64.StdEncoding.EncodeToString([]byte("payload")),
+		IsBase64Encoded:       true,
+		RequestContext: APIGatewayProxyRequestContext{
+			Identity: APIGatewayRequestIdentity{SourceIP: "1.2.3.4"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := iris.StatusCreated, resp.StatusCode; expected != got {
+		t.Fatalf("expected status code %d but got %d", expected, got)
+	}
+	if expected, got := "search custom payload 1.2.3.4", resp.Body; expected != got {
+		t.Fatalf("expected body '%s' but got '%s'", expected, got)
+	}
+	if expected, got := "42", resp.Headers["X-Id"]; expected != got {
+		t.Fatalf("expected header '%s' but got '%s'", expected, got)
+	}
+
+	resp, err = adapter.Proxy(APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/binary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsBase64Encoded || resp.Body != base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe, 0x00}) {
+		t.Fatalf("expected a base64 encoded body but got '%s'", resp.Body)
+	}
+}
+
+func TestALB(t *testing.T) {
+	adapter := newTestAdapter(t)
+
+	resp, err := adapter.ALB(ALBTargetGroupRequest{
+		HTTPMethod:        "GET",
+		Path:              "/notfound",
+		MultiValueHeaders: map[string][]string{"x-forwarded-for": {"5.6.7.8"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := iris.StatusNotFound, resp.StatusCode; expected != got {
+		t.Fatalf("expected status code %d but got %d", expected, got)
+	}
+	if expected, got := "404 Not Found", resp.StatusDescription; expected != got {
+		t.Fatalf("expected status description '%s' but got '%s'", expected, got)
+	}
+	if resp.MultiValueHeaders == nil || resp.Headers != nil {
+		t.Fatalf("expected multi value headers only")
+	}
+
+	resp, err = adapter.ALB(ALBTargetGroupRequest{
+		HTTPMethod: "POST",
+		Path:       "/users/42",
+		Headers:    map[string]string{"X-Forwarded-For": "1.1.1.1, 9.9.9.9"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first entry is sent by the client, the last one is the load balancer's.
+	if expected, got := "   9.9.9.9", resp.Body; expected != got {
+		t.Fatalf("expected body '%s' but got '%s'", expected, got)
+	}
+	if resp.Headers == nil || resp.MultiValueHeaders != nil {
+		t.Fatalf("expected single value headers only")
+	}
+}
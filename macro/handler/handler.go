@@ -44,8 +44,8 @@ func MakeHandler(tmpl macro.Template) context.Handler {
 				continue // allow.
 			}
 
-			if !p.Eval(ctx.Params().Get(p.Name), &ctx.Params().Store) {
-				ctx.StatusCode(p.ErrCode)
+			if errCode, passed := p.Validate(ctx.Params().Get(p.Name), &ctx.Params().Store); !passed {
+				ctx.StatusCode(errCode)
 				ctx.StopExecution()
 				return
 			}
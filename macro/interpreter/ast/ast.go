@@ -18,7 +18,9 @@ type (
 	}
 
 	// TrailingParamType if implemented and its `Trailing()` returns true
-	// then it should be declared at the end of a route path and can accept any trailing path segment as one parameter.
+	// then it can accept more than one path segments as one parameter, it's declared once in a route path,
+	// usually at its end, i.e /files/{p:path}, a declaration in the middle, i.e /files/{p:path}/edit, is matched
+	// with the shortest value which the rest segments match.
 	TrailingParamType interface {
 		ParamType
 		Trailing() bool
@@ -108,14 +110,15 @@ func LookupParamType(indentOrAlias string, paramTypes ...ParamType) (ParamType,
 // its source ({param:type}),
 // its name ("param"),
 // its attached functions by the user (min, max...)
-// and the http error code if that parameter
-// failed to be evaluated.
+// and the http error codes if that parameter
+// is not of its type or its functions failed to be evaluated.
 type ParamStatement struct {
-	Src       string      // the original unparsed source, i.e: {id:int range(1,5) else 404}
-	Name      string      // id
-	Type      ParamType   // int
-	Funcs     []ParamFunc // range
-	ErrorCode int         // 404
+	Src           string      // the original unparsed source, i.e: {id:int range(1,5) else 404}
+	Name          string      // id
+	Type          ParamType   // int
+	Funcs         []ParamFunc // range
+	ErrorCode     int         // 404
+	FuncErrorCode int         // 422, the ErrorCode if "else" is used.
 }
 
 // ParamFunc holds the name of a parameter's function
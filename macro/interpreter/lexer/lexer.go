@@ -117,21 +117,47 @@ func (l *Lexer) NextToken() (t token.Token) {
 func (l *Lexer) NextDynamicToken() (t token.Token) {
 	// calculate anything, even spaces.
 
-	// numbers
+	pos := l.pos
+	// numbers, unless they're the start of a regexp, i.e regexp(1[0-9]+).
 	lit := l.readNumber()
-	if lit != "" {
+	if typ := resolveTokenType(l.ch); lit != "" && (typ == token.RPAREN || typ == token.COMMA) {
 		return l.newToken(token.INT, lit)
 	}
 
-	lit = l.readIdentifierFuncArgument()
+	lit = l.input[pos:l.pos] + l.readIdentifierFuncArgument()
 	return l.newToken(token.IDENT, lit)
 }
 
 // used to skip any illegal token if inside parenthesis, used to be able to set custom regexp inside a func.
+// The nested parenthesis, i.e regexp(^(a|b)$), and the escaped or inside brackets ones, i.e regexp(^[(]\)$), are part of the argument.
 func (l *Lexer) readIdentifierFuncArgument() string {
 	pos := l.pos
-	for resolveTokenType(l.ch) != token.RPAREN {
-		l.readChar()
+	depth, inBrackets := 0, false
+	for l.ch != 0 {
+		switch l.ch {
+		case '\\':
+			// skip the escaped char.
+			l.readChar()
+		case '[':
+			inBrackets = true
+		case ']':
+			inBrackets = false
+		case '(':
+			if !inBrackets {
+				depth++
+			}
+		case ')':
+			if !inBrackets {
+				if depth == 0 {
+					return l.input[pos:l.pos]
+				}
+				depth--
+			}
+		}
+
+		if l.ch != 0 {
+			l.readChar()
+		}
 	}
 
 	return l.input[pos:l.pos]
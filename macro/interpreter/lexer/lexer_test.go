@@ -36,7 +36,7 @@ func TestNextToken(t *testing.T) {
 		tok := l.NextToken()
 
 		if tok.Type != tt.expectedType {
-			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%v, got=%v",
 				i, tt.expectedType, tok.Type)
 		}
 
@@ -48,6 +48,28 @@ func TestNextToken(t *testing.T) {
 	}
 }
 
+func TestNextDynamicToken(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{`5)`, token.INT, "5"},
+		{`^[a-z]+$)`, token.IDENT, "^[a-z]+$"},
+		{`^([a-z]+)-(x|y)$) else 400`, token.IDENT, "^([a-z]+)-(x|y)$"},
+		{`^[()]\)$)`, token.IDENT, "^[()]\\)$"},
+		{`1[0-9]+)`, token.IDENT, "1[0-9]+"},
+		{`^(a`, token.IDENT, "^(a"}, // missing ')', EOF.
+	}
+
+	for i, tt := range tests {
+		tok := New(tt.input).NextDynamicToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected=%v %q, got=%v %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
 // EMEINA STO:
 // 30/232 selida apto making a interpeter in Go.
 // den ekana to skipWhitespaces giati skeftomai
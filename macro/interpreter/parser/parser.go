@@ -21,7 +21,8 @@ func Parse(fullpath string, paramTypes []ast.ParamType) ([]*ast.ParamStatement,
 	pathParts := strings.SplitN(fullpath, "/", -1)
 	p := new(ParamParser)
 	statements := make([]*ast.ParamStatement, 0)
-	for i, s := range pathParts {
+	hasTrailing := false
+	for _, s := range pathParts {
 		if s == "" { // if starts with /
 			continue
 		}
@@ -37,9 +38,13 @@ func Parse(fullpath string, paramTypes []ast.ParamType) ([]*ast.ParamStatement,
 			// exit on first error
 			return nil, err
 		}
-		// if we have param type path but it's not the last path part
-		if ast.IsTrailing(stmt.Type) && i < len(pathParts)-1 {
-			return nil, fmt.Errorf("%s: parameter type \"%s\" should be registered to the very last of a path", s, stmt.Type.Indent())
+		// a param type path can be registered in the middle of a path too, i.e /files/{p:path}/edit,
+		// but only once, the rest of the segments are matched after it.
+		if ast.IsTrailing(stmt.Type) {
+			if hasTrailing {
+				return nil, fmt.Errorf("%s: parameter type \"%s\" can be registered only once in a path", s, stmt.Type.Indent())
+			}
+			hasTrailing = true
 		}
 
 		statements = append(statements, stmt)
@@ -81,6 +86,10 @@ const (
 	// per-parameter. An error code can be setted via
 	// the "else" keyword inside a route's path.
 	DefaultParamErrorCode = 404
+	// DefaultParamFuncErrorCode is the default http error code, 422 unprocessable entity,
+	// of a parameter which is of its type but one of its functions failed, i.e the min(1) of the {id:uint64 min(1)} on "0".
+	// The "else" keyword sets it too.
+	DefaultParamFuncErrorCode = 422
 )
 
 // func parseParamFuncArg(t token.Token) (a ast.ParamFuncArg, err error) {
@@ -109,9 +118,10 @@ func (p *ParamParser) Parse(paramTypes []ast.ParamType) (*ast.ParamStatement, er
 	l := lexer.New(p.src)
 
 	stmt := &ast.ParamStatement{
-		ErrorCode: DefaultParamErrorCode,
-		Type:      ast.GetMasterParamType(paramTypes...),
-		Src:       p.src,
+		ErrorCode:     DefaultParamErrorCode,
+		FuncErrorCode: DefaultParamFuncErrorCode,
+		Type:          ast.GetMasterParamType(paramTypes...),
+		Src:           p.src,
 	}
 
 	lastParamFunc := ast.ParamFunc{}
@@ -122,6 +132,9 @@ func (p *ParamParser) Parse(paramTypes []ast.ParamType) (*ast.ParamStatement, er
 			if stmt.Name == "" {
 				p.appendErr("[1:] parameter name is missing")
 			}
+			if lastParamFunc.Name != "" {
+				p.appendErr("[%d:] parameter function %s is missing its parenthesis", t.Start, lastParamFunc.Name)
+			}
 			break
 		}
 
@@ -175,6 +188,7 @@ func (p *ParamParser) Parse(paramTypes []ast.ParamType) (*ast.ParamStatement, er
 				continue
 			}
 			stmt.ErrorCode = errCode
+			stmt.FuncErrorCode = errCode
 		case token.RBRACE:
 			// check if } but not {
 			if stmt.Name == "" {
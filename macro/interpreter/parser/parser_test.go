@@ -106,7 +106,8 @@ func TestParseParam(t *testing.T) {
 						Name: "max",
 						Args: []string{"5"}},
 				},
-				ErrorCode: 404,
+				ErrorCode:     404,
+				FuncErrorCode: 404,
 			}}, // 0
 
 		{true,
@@ -120,7 +121,8 @@ func TestParseParam(t *testing.T) {
 						Name: "range",
 						Args: []string{"1", "5"}},
 				},
-				ErrorCode: 404,
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			}}, // 1
 		{true,
 			ast.ParamStatement{
@@ -132,35 +134,40 @@ func TestParseParam(t *testing.T) {
 						Name: "contains",
 						Args: []string{"."}},
 				},
-				ErrorCode: 404,
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			}}, // 2
 		{true,
 			ast.ParamStatement{
-				Src:       "{username:alphabetical}",
-				Name:      "username",
-				Type:      mustLookupParamType("alphabetical"),
-				ErrorCode: 404,
+				Src:           "{username:alphabetical}",
+				Name:          "username",
+				Type:          mustLookupParamType("alphabetical"),
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			}}, // 3
 		{true,
 			ast.ParamStatement{
-				Src:       "{myparam}",
-				Name:      "myparam",
-				Type:      mustLookupParamType("string"),
-				ErrorCode: 404,
+				Src:           "{myparam}",
+				Name:          "myparam",
+				Type:          mustLookupParamType("string"),
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			}}, // 4
 		{false,
 			ast.ParamStatement{
-				Src:       "{myparam_:thisianunexpected}",
-				Name:      "myparam_",
-				Type:      nil,
-				ErrorCode: 404,
+				Src:           "{myparam_:thisianunexpected}",
+				Name:          "myparam_",
+				Type:          nil,
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			}}, // 5
 		{true,
 			ast.ParamStatement{
-				Src:       "{myparam2}",
-				Name:      "myparam2", // we now allow integers to the parameter names.
-				Type:      ast.GetMasterParamType(testParamTypes...),
-				ErrorCode: 404,
+				Src:           "{myparam2}",
+				Name:          "myparam2", // we now allow integers to the parameter names.
+				Type:          ast.GetMasterParamType(testParamTypes...),
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			}}, // 6
 		{true,
 			ast.ParamStatement{
@@ -171,42 +178,48 @@ func TestParseParam(t *testing.T) {
 					{
 						Name: "even"},
 				},
-				ErrorCode: 404,
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			}}, // 7
 		{true,
 			ast.ParamStatement{
-				Src:       "{id:int64 else 404}",
-				Name:      "id",
-				Type:      mustLookupParamType("int64"),
-				ErrorCode: 404,
+				Src:           "{id:int64 else 404}",
+				Name:          "id",
+				Type:          mustLookupParamType("int64"),
+				ErrorCode:     404,
+				FuncErrorCode: 404,
 			}}, // 8
 		{true,
 			ast.ParamStatement{
-				Src:       "{id:long else 404}", // backwards-compatible test.
-				Name:      "id",
-				Type:      mustLookupParamType("int64"),
-				ErrorCode: 404,
+				Src:           "{id:long else 404}", // backwards-compatible test.
+				Name:          "id",
+				Type:          mustLookupParamType("int64"),
+				ErrorCode:     404,
+				FuncErrorCode: 404,
 			}}, // 9
 		{true,
 			ast.ParamStatement{
-				Src:       "{id:long else 404}",
-				Name:      "id",
-				Type:      mustLookupParamType("int64"), // backwards-compatible test of LookupParamType.
-				ErrorCode: 404,
+				Src:           "{id:long else 404}",
+				Name:          "id",
+				Type:          mustLookupParamType("int64"), // backwards-compatible test of LookupParamType.
+				ErrorCode:     404,
+				FuncErrorCode: 404,
 			}}, // 10
 		{true,
 			ast.ParamStatement{
-				Src:       "{has:bool else 404}",
-				Name:      "has",
-				Type:      mustLookupParamType("bool"),
-				ErrorCode: 404,
+				Src:           "{has:bool else 404}",
+				Name:          "has",
+				Type:          mustLookupParamType("bool"),
+				ErrorCode:     404,
+				FuncErrorCode: 404,
 			}}, // 11
 		{true,
 			ast.ParamStatement{
-				Src:       "{has:boolean else 404}", // backwards-compatible test.
-				Name:      "has",
-				Type:      mustLookupParamType("bool"),
-				ErrorCode: 404,
+				Src:           "{has:boolean else 404}", // backwards-compatible test.
+				Name:          "has",
+				Type:          mustLookupParamType("bool"),
+				ErrorCode:     404,
+				FuncErrorCode: 404,
 			}}, // 12
 
 	}
@@ -250,7 +263,8 @@ func TestParse(t *testing.T) {
 						Name: "max",
 						Args: []string{"5"}},
 				},
-				ErrorCode: 404,
+				ErrorCode:     404,
+				FuncErrorCode: 404,
 			},
 			}}, // 0
 		{"/admin/{id:uint64 range(1,5)}", true,
@@ -263,7 +277,8 @@ func TestParse(t *testing.T) {
 						Name: "range",
 						Args: []string{"1", "5"}},
 				},
-				ErrorCode: 404,
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			},
 			}}, // 1
 		{"/files/{file:path contains(.)}", true,
@@ -276,49 +291,64 @@ func TestParse(t *testing.T) {
 						Name: "contains",
 						Args: []string{"."}},
 				},
-				ErrorCode: 404,
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			},
 			}}, // 2
 		{"/profile/{username:alphabetical}", true,
 			[]ast.ParamStatement{{
-				Src:       "{username:alphabetical}",
-				Name:      "username",
-				Type:      paramTypeAlphabetical,
-				ErrorCode: 404,
+				Src:           "{username:alphabetical}",
+				Name:          "username",
+				Type:          paramTypeAlphabetical,
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			},
 			}}, // 3
 		{"/something/here/{myparam}", true,
 			[]ast.ParamStatement{{
-				Src:       "{myparam}",
-				Name:      "myparam",
-				Type:      paramTypeString,
-				ErrorCode: 404,
+				Src:           "{myparam}",
+				Name:          "myparam",
+				Type:          paramTypeString,
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			},
 			}}, // 4
 		{"/unexpected/{myparam_:thisianunexpected}", false,
 			[]ast.ParamStatement{{
-				Src:       "{myparam_:thisianunexpected}",
-				Name:      "myparam_",
-				Type:      nil,
-				ErrorCode: 404,
+				Src:           "{myparam_:thisianunexpected}",
+				Name:          "myparam_",
+				Type:          nil,
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			},
 			}}, // 5
 		{"/p2/{myparam2}", true,
 			[]ast.ParamStatement{{
-				Src:       "{myparam2}",
-				Name:      "myparam2", // we now allow integers to the parameter names.
-				Type:      paramTypeString,
-				ErrorCode: 404,
+				Src:           "{myparam2}",
+				Name:          "myparam2", // we now allow integers to the parameter names.
+				Type:          paramTypeString,
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			},
 			}}, // 6
-		{"/assets/{file:path}/invalid", false, // path should be in the end segment
+		{"/assets/{file:path}/edit", true, // path can be in the middle too
 			[]ast.ParamStatement{{
-				Src:       "{file:path}",
-				Name:      "file",
-				Type:      paramTypePath,
-				ErrorCode: 404,
+				Src:           "{file:path}",
+				Name:          "file",
+				Type:          paramTypePath,
+				ErrorCode:     404,
+				FuncErrorCode: 422,
 			},
 			}}, // 7
+		{"/assets/{file:path}/{other:path}", false, // path should be registered once
+			[]ast.ParamStatement{{
+				Src:           "{file:path}",
+				Name:          "file",
+				Type:          paramTypePath,
+				ErrorCode:     404,
+				FuncErrorCode: 422,
+			},
+			}}, // 8
 	}
 	for i, tt := range tests {
 		statements, err := Parse(tt.path, testParamTypes)
@@ -17,6 +17,64 @@ type (
 	ParamEvaluator func(paramValue string) (interface{}, bool)
 )
 
+var (
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+	paramValueType = reflect.TypeOf("")
+)
+
+// Evaluator converts a typed "fn" to a `ParamEvaluator` of a custom parameter type, the "fn" can be one of:
+//
+//	func(paramValue string) (T, bool)
+//	func(paramValue string) (T, error)
+//	func(paramValue string) bool, the value is kept as string.
+//
+// The T value is stored to the `ctx.Params()`, i.e `ctx.Params().GetValue("d").(time.Time)`,
+// and it's the input argument of the macro's parameter functions, i.e func(minYear int) func(time.Time) bool.
+// It panics on any other signature.
+//
+// Usage:
+//
+//	app.Macros().Register("date", "", false, false, macro.Evaluator(func(paramValue string) (time.Time, error) {
+//		return time.Parse("2006-01-02", paramValue)
+//	}))
+//	app.Get("/archive/{d:date}", handler)
+func Evaluator(fn interface{}) ParamEvaluator {
+	switch f := fn.(type) {
+	case ParamEvaluator:
+		return f
+	case func(string) (interface{}, bool):
+		return f
+	case func(string) bool:
+		return func(paramValue string) (interface{}, bool) {
+			if !f(paramValue) {
+				return nil, false
+			}
+			return paramValue, true
+		}
+	}
+
+	v := reflect.ValueOf(fn)
+	typ := v.Type()
+	if typ.Kind() != reflect.Func || typ.NumIn() != 1 || typ.In(0) != paramValueType || typ.NumOut() != 2 ||
+		(typ.Out(1).Kind() != reflect.Bool && typ.Out(1) != errorType) {
+		panic(fmt.Sprintf("macro: evaluator should be a func(string) (T, bool) or func(string) (T, error) but got: %s", typ))
+	}
+
+	withError := typ.Out(1) == errorType
+	return func(paramValue string) (interface{}, bool) {
+		out := v.Call([]reflect.Value{reflect.ValueOf(paramValue)})
+		if withError {
+			if !out[1].IsNil() {
+				return nil, false
+			}
+		} else if !out[1].Bool() {
+			return nil, false
+		}
+
+		return out[0].Interface(), true
+	}
+}
+
 var goodEvaluatorFuncs = []reflect.Type{
 	reflect.TypeOf(func(string) (interface{}, bool) { return nil, false }),
 	reflect.TypeOf(ParamEvaluator(func(string) (interface{}, bool) { return nil, false })),
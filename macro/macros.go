@@ -433,7 +433,23 @@ type Macros []*Macro
 // The "alias" is optionally and it should be unique, it is the alias of the parameter type.
 // "isMaster" and "isTrailing" is for default parameter type and wildcard respectfully.
 // The "evaluator" is the function that is converted to an Iris handler which is executed every time
-// before the main chain of a route's handlers that contains this macro of the specific parameter type.
+// before the main chain of a route's handlers that contains this macro of the specific parameter type,
+// see `Evaluator` for its typed forms.
+// It should be called before the registration of the routes which use it.
+// Note that the `app.Macros()` returns the process-global `Defaults`,
+// a registered type is shared by all the applications of the process.
+// Returns nil if the "indent" or the "alias" is taken or if a master is registered already.
+//
+// The custom types are matched like the built-in ones, a not passed evaluator fires the 404
+// and a not passed parameter function the 422, their values are stored to the `ctx.Params()`.
+//
+// Usage:
+//
+//	slug := app.Macros().Register("slug", "", false, false, macro.Evaluator(macro.MustRegexp("^[a-z0-9]+(-[a-z0-9]+)*$")))
+//	slug.RegisterFunc("max", func(n int) func(string) bool {
+//		return func(paramValue string) bool { return len(paramValue) <= n }
+//	})
+//	app.Get("/posts/{s:slug max(64)}", handler)
 //
 // Read https://github.com/kataras/iris/tree/master/_examples/routing/macros for more details.
 func (ms *Macros) Register(indent, alias string, isMaster, isTrailing bool, evaluator ParamEvaluator) *Macro {
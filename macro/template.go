@@ -1,8 +1,10 @@
 package macro
 
 import (
+	"fmt"
 	"reflect"
 
+	"github.com/kataras/iris/core/errors"
 	"github.com/kataras/iris/core/memstore"
 	"github.com/kataras/iris/macro/interpreter/ast"
 	"github.com/kataras/iris/macro/interpreter/parser"
@@ -30,6 +32,7 @@ type TemplateParam struct {
 	Name          string          `json:"name"`
 	Index         int             `json:"index"`
 	ErrCode       int             `json:"errCode"`
+	FuncErrCode   int             `json:"funcErrCode"`
 	TypeEvaluator ParamEvaluator  `json:"-"`
 	Funcs         []reflect.Value `json:"-"`
 
@@ -71,18 +74,28 @@ func (p *TemplateParam) CanEval() bool {
 // It is called from the converted macro handler (middleware)
 // from the higher-level component of "kataras/iris/macro/handler#MakeHandler".
 func (p *TemplateParam) Eval(paramValue string, paramSetter memstore.ValueSetter) bool {
+	_, passed := p.Validate(paramValue, paramSetter)
+	return passed
+}
+
+// Validate is like `Eval` but it reports the http error code of the failure too,
+// the `ErrCode` if the "paramValue" is not of the parameter's type, i.e 404 on "abc" of the {id:uint64},
+// and the `FuncErrCode` if one of its functions is not passed, i.e 422 on "0" of the {id:uint64 min(1)}.
+//
+// It is called from the converted macro handler (middleware).
+func (p *TemplateParam) Validate(paramValue string, paramSetter memstore.ValueSetter) (errCode int, passed bool) {
 	if p.TypeEvaluator == nil {
 		for _, fn := range p.stringInFuncs {
 			if !fn(paramValue) {
-				return false
+				return p.FuncErrCode, false
 			}
 		}
-		return true
+		return 0, true
 	}
 
 	newValue, passed := p.TypeEvaluator(paramValue)
 	if !passed {
-		return false
+		return p.ErrCode, false
 	}
 
 	if len(p.Funcs) > 0 {
@@ -91,13 +104,30 @@ func (p *TemplateParam) Eval(paramValue string, paramSetter memstore.ValueSetter
 			// or make it as func(interface{}) bool and pass directly the "newValue"
 			// but that would not be as easy for end-developer, so keep that "slower":
 			if !evalFunc.Call(paramIn)[0].Interface().(bool) { // i.e func(paramValue int) bool
-				return false
+				return p.FuncErrCode, false
 			}
 		}
 	}
 
 	paramSetter.Set(p.Name, newValue)
-	return true
+	return 0, true
+}
+
+var (
+	errUnknownParamFunc     = errors.New("macro: function '%s' of the parameter '%s' is not registered for the '%s' type")
+	errInvalidParamFuncArgs = errors.New("macro: invalid arguments of the function '%s' of the parameter '%s': %v")
+)
+
+// buildParamFunc builds the evaluator of a parameter function with the "args",
+// the `ParamFuncBuilder`s panic on invalid arguments, i.e min(abc) of an int.
+func buildParamFunc(builder ParamFuncBuilder, args []string) (fn reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	return builder(args), nil
 }
 
 // Parse takes a full route path and a macro map (macro map contains the macro types with their registered param functions)
@@ -126,6 +156,7 @@ func Parse(src string, macros Macros) (Template, error) {
 			Name:          p.Name,
 			Index:         idx,
 			ErrCode:       p.ErrorCode,
+			FuncErrCode:   p.FuncErrorCode,
 			TypeEvaluator: typEval,
 		}
 
@@ -136,13 +167,16 @@ func Parse(src string, macros Macros) (Template, error) {
 					tmplFn = m.getFunc(paramfn.Name)
 				}
 
-				if tmplFn == nil { // if not found then just skip this param.
-					continue
+				if tmplFn == nil { // a typo shouldn't skip the validation silently.
+					return tmpl, errUnknownParamFunc.Format(paramfn.Name, p.Src, p.Type.Indent())
 				}
 			}
 
-			evalFn := tmplFn(paramfn.Args)
-			if evalFn.IsNil() || !evalFn.IsValid() || evalFn.Kind() != reflect.Func {
+			evalFn, err := buildParamFunc(tmplFn, paramfn.Args)
+			if err != nil {
+				return tmpl, errInvalidParamFuncArgs.Format(paramfn.Name, p.Src, err)
+			}
+			if !evalFn.IsValid() || evalFn.Kind() != reflect.Func || evalFn.IsNil() {
 				continue
 			}
 			tmplParam.Funcs = append(tmplParam.Funcs, evalFn)
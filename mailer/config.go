@@ -0,0 +1,70 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+// Config is the configuration of the `Mailer`, see `New`.
+type Config struct {
+	// Host is the host of the SMTP server, i.e "smtp.example.com".
+	//
+	// Defaults to "localhost".
+	Host string
+	// Port is the port of the SMTP server.
+	//
+	// Defaults to 587, the submission port.
+	Port int
+	// Username and Password are the credentials of the PLAIN authentication,
+	// the authentication is skipped if the Username is empty.
+	Username string
+	Password string
+	// From is the default sender of the messages, i.e "Support <support@example.com>".
+	From string
+	// TLS connects through implicit TLS, i.e on the port 465,
+	// otherwise the connection is upgraded through STARTTLS when the server supports it.
+	//
+	// Defaults to false.
+	TLS bool
+	// TLSConfig is the TLS configuration of the connection,
+	// its ServerName defaults to the Host.
+	TLSConfig *tls.Config
+	// Timeout is the timeout of the connection and of each message's delivery.
+	//
+	// Defaults to 10 seconds.
+	Timeout time.Duration
+	// QueueSize is the capacity of the asynchronous queue of the `Mailer#Send`.
+	//
+	// Defaults to 100.
+	QueueSize int
+	// Workers is the number of the goroutines which deliver the queued messages.
+	//
+	// Defaults to 1.
+	Workers int
+	// MaxRetries is the number of the retries of a failed delivery,
+	// the delay between them starts at the RetryDelay and it's doubled on each retry,
+	// a negative value disables the retries.
+	//
+	// Defaults to 3.
+	MaxRetries int
+	// RetryDelay is the delay before the first retry.
+	//
+	// Defaults to 2 seconds.
+	RetryDelay time.Duration
+	// OnError is fired when a queued message fails after all the retries.
+	// Optional.
+	OnError func(msg *Message, err error)
+}
+
+// DefaultConfig returns the default configuration of the `Mailer`.
+func DefaultConfig() Config {
+	return Config{
+		Host:       "localhost",
+		Port:       587,
+		Timeout:    10 * time.Second,
+		QueueSize:  100,
+		Workers:    1,
+		MaxRetries: 3,
+		RetryDelay: 2 * time.Second,
+	}
+}
@@ -0,0 +1,336 @@
+// Package mailer provides an SMTP mailer with an asynchronous queue, retries and graceful draining,
+// the HTML bodies can be rendered by the view engine of the Application, see `Application#Mailer`.
+package mailer
+
+import (
+	"bytes"
+	stdContext "context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+var (
+	// ErrQueueFull is returned from the `Mailer#Send` when the queue is full.
+	ErrQueueFull = errors.New("mailer: the queue is full")
+	// ErrClosed is returned from the `Mailer#Send` after `Close`
+	// and it's passed to the `Config#OnError` for the queued messages which are dropped on close.
+	ErrClosed = errors.New("mailer: closed")
+
+	errInvalidAddress = errors.New("mailer: invalid address '%s'")
+	errNoRecipients   = errors.New("mailer: the message has no recipients")
+	errNoRenderer     = errors.New("mailer: the template '%s' requires a renderer")
+	errNoAuth         = errors.New("mailer: the server does not support authentication")
+)
+
+// Renderer renders the "filename" view with the "layout" and the "data" to the "w",
+// it's the `Application#View` when the Mailer is configured through the Application.
+type Renderer func(w io.Writer, filename, layout string, data interface{}) error
+
+// Mailer sends e-mail messages through SMTP, see `New`.
+// 邮件服务: 异步队列, 失败重试, 关闭时等待队列发送完毕
+type Mailer struct {
+	config   Config
+	renderer Renderer
+
+	queue chan *Message
+	wg    sync.WaitGroup
+
+	mu     sync.RWMutex // protects the queue's close.
+	closed bool
+
+	// abort is closed when the `Close` times out, the retries and the queued messages are dropped.
+	abort     chan struct{}
+	closeOnce sync.Once
+	abortOnce sync.Once
+}
+
+// New returns a new Mailer of the "c" configuration and starts its workers,
+// the optional "renderer" renders the `Message#Template`.
+// Its `Close` should be called on shutdown, it's called automatically on the shutdown
+// of the Application's hosts when it's configured through the `Application#ConfigureMailer`.
+func New(c Config, renderer ...Renderer) *Mailer {
+	def := DefaultConfig()
+	if c.Host == "" {
+		c.Host = def.Host
+	}
+	if c.Port <= 0 {
+		c.Port = def.Port
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = def.Timeout
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = def.QueueSize
+	}
+	if c.Workers <= 0 {
+		c.Workers = def.Workers
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = def.MaxRetries
+	} else if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.RetryDelay <= 0 {
+		c.RetryDelay = def.RetryDelay
+	}
+
+	m := &Mailer{
+		config: c,
+		queue:  make(chan *Message, c.QueueSize),
+		abort:  make(chan struct{}),
+	}
+	if len(renderer) > 0 {
+		m.renderer = renderer[0]
+	}
+
+	m.wg.Add(c.Workers)
+	for i := 0; i < c.Workers; i++ {
+		go m.work()
+	}
+
+	return m
+}
+
+// prepare returns a copy of the "msg" with the default sender and the rendered template,
+// the errors of the message are reported before it's queued.
+func (m *Mailer) prepare(msg *Message) (*Message, error) {
+	prepared := *msg
+	if prepared.From == "" {
+		prepared.From = m.config.From
+	}
+
+	if prepared.Template != "" {
+		if m.renderer == nil {
+			return nil, errNoRenderer.Format(prepared.Template)
+		}
+
+		var b bytes.Buffer
+		if err := m.renderer(&b, prepared.Template, prepared.Layout, prepared.Data); err != nil {
+			return nil, err
+		}
+		prepared.HTML = b.String()
+	}
+
+	if _, err := mail.ParseAddress(prepared.From); err != nil {
+		return nil, errInvalidAddress.Format(prepared.From)
+	}
+
+	if _, err := prepared.recipients(); err != nil {
+		return nil, err
+	}
+
+	return &prepared, nil
+}
+
+// Send renders and validates the "msg" and queues it for delivery,
+// it returns `ErrQueueFull` if the queue is full and `ErrClosed` after `Close`.
+// The delivery errors are passed to the `Config#OnError`.
+//
+// Usage:
+//
+//	err := app.Mailer().Send(&mailer.Message{
+//		To:       []string{user.Email},
+//		Subject:  "Welcome",
+//		Template: "emails/welcome.html",
+//		Data:     user,
+//	})
+func (m *Mailer) Send(msg *Message) error {
+	prepared, err := m.prepare(msg)
+	if err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+
+	select {
+	case m.queue <- prepared:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// SendNow renders, validates and delivers the "msg" synchronously, without retries.
+func (m *Mailer) SendNow(msg *Message) error {
+	prepared, err := m.prepare(msg)
+	if err != nil {
+		return err
+	}
+
+	return m.deliver(prepared)
+}
+
+// Pending returns the number of the queued messages.
+func (m *Mailer) Pending() int {
+	return len(m.queue)
+}
+
+// Close stops accepting messages and waits for the queued ones to be delivered,
+// if the "ctx" is done first the retries and the remaining messages are dropped
+// and the ctx's error is returned. It's safe to call it more than once.
+func (m *Mailer) Close(ctx stdContext.Context) error {
+	m.closeOnce.Do(func() {
+		m.mu.Lock()
+		m.closed = true
+		close(m.queue)
+		m.mu.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		m.abortOnce.Do(func() { close(m.abort) })
+		return ctx.Err()
+	}
+}
+
+func (m *Mailer) aborted() bool {
+	select {
+	case <-m.abort:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *Mailer) work() {
+	defer m.wg.Done()
+
+	for msg := range m.queue {
+		var err error
+		if m.aborted() {
+			err = ErrClosed
+		} else {
+			err = m.deliverWithRetry(msg)
+		}
+
+		if err != nil && m.config.OnError != nil {
+			m.config.OnError(msg, err)
+		}
+	}
+}
+
+func (m *Mailer) deliverWithRetry(msg *Message) error {
+	delay := m.config.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err := m.deliver(msg)
+		if err == nil || attempt >= m.config.MaxRetries {
+			return err
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-m.abort:
+			t.Stop()
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// deliver sends the "msg" through a new SMTP connection.
+func (m *Mailer) deliver(msg *Message) error {
+	rcpt, err := msg.recipients()
+	if err != nil {
+		return err
+	}
+
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return errInvalidAddress.Format(msg.From)
+	}
+
+	body, err := msg.bytes(time.Now())
+	if err != nil {
+		return err
+	}
+
+	tlsConfig := &tls.Config{ServerName: m.config.Host}
+	if m.config.TLSConfig != nil {
+		tlsConfig = m.config.TLSConfig.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = m.config.Host
+		}
+	}
+
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+	dialer := &net.Dialer{Timeout: m.config.Timeout}
+
+	var conn net.Conn
+	if m.config.TLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(m.config.Timeout))
+
+	c, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if !m.config.TLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err = c.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+
+	if m.config.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errNoAuth
+		}
+		if err = c.Auth(smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err = c.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range rcpt {
+		if err = c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(body); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
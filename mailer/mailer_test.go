@@ -0,0 +1,194 @@
+package mailer_test
+
+import (
+	stdContext "context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/mailer"
+)
+
+// smtpServer is a minimal SMTP server which records the received messages,
+// the first "failures" MAIL commands are rejected with a temporary error.
+type smtpServer struct {
+	ln       net.Listener
+	failures int32
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func newSMTPServer(t *testing.T, failures int32) *smtpServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &smtpServer{ln: ln, failures: failures}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	c := textproto.NewConn(conn)
+	c.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+
+		switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+		case "EHLO", "HELO":
+			c.PrintfLine("250 localhost")
+		case "MAIL":
+			if atomic.AddInt32(&s.failures, -1) >= 0 {
+				c.PrintfLine("451 try again later")
+				continue
+			}
+			c.PrintfLine("250 OK")
+		case "RCPT":
+			c.PrintfLine("250 OK")
+		case "DATA":
+			c.PrintfLine("354 go ahead")
+			b, err := io.ReadAll(c.DotReader())
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, string(b))
+			s.mu.Unlock()
+			c.PrintfLine("250 OK")
+		case "QUIT":
+			c.PrintfLine("221 bye")
+			return
+		default:
+			c.PrintfLine("250 OK")
+		}
+	}
+}
+
+func (s *smtpServer) config() mailer.Config {
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return mailer.Config{Host: host, Port: p, From: "Iris <noreply@example.com>", RetryDelay: 10 * time.Millisecond}
+}
+
+func (s *smtpServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+func TestMailerSendNow(t *testing.T) {
+	s := newSMTPServer(t, 0)
+	defer s.ln.Close()
+
+	renderer := func(w io.Writer, filename, layout string, data interface{}) error {
+		_, err := fmt.Fprintf(w, "<h1>%s: %v</h1>", filename, data)
+		return err
+	}
+
+	m := mailer.New(s.config(), renderer)
+	defer m.Close(stdContext.Background())
+
+	err := m.SendNow(&mailer.Message{
+		To:       []string{"Gopher <gopher@example.com>"},
+		Bcc:      []string{"audit@example.com"},
+		Subject:  "Welcome",
+		Text:     "Hello",
+		Template: "welcome.html",
+		Data:     "gopher",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := s.received()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message but got %d", len(messages))
+	}
+
+	msg := messages[0]
+	for _, expected := range []string{
+		"From: \"Iris\" <noreply@example.com>",
+		"To: \"Gopher\" <gopher@example.com>",
+		"Subject: Welcome",
+		"multipart/alternative",
+		"Hello",
+		"<h1>welcome.html: gopher</h1>",
+	} {
+		if !strings.Contains(msg, expected) {
+			t.Fatalf("expected the message to contain %q but got:\n%s", expected, msg)
+		}
+	}
+	if strings.Contains(msg, "audit@example.com") {
+		t.Fatalf("the bcc recipients should not be visible:\n%s", msg)
+	}
+
+	if err = m.SendNow(&mailer.Message{Subject: "no recipients"}); err == nil {
+		t.Fatal("expected an error for a message without recipients")
+	}
+
+	if err = m.SendNow(&mailer.Message{To: []string{"a@example.com"}, Template: "x.html"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = mailer.New(s.config()).SendNow(&mailer.Message{To: []string{"a@example.com"}, Template: "x.html"}); err == nil {
+		t.Fatal("expected an error for a template without a renderer")
+	}
+}
+
+func TestMailerQueueRetryAndClose(t *testing.T) {
+	// the first two deliveries fail, they are retried.
+	s := newSMTPServer(t, 2)
+	defer s.ln.Close()
+
+	var failed int32
+	c := s.config()
+	c.OnError = func(msg *mailer.Message, err error) {
+		atomic.AddInt32(&failed, 1)
+	}
+	m := mailer.New(c)
+
+	for i := 0; i < 3; i++ {
+		if err := m.Send(&mailer.Message{To: []string{"gopher@example.com"}, Subject: fmt.Sprintf("msg %d", i), Text: "body"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := stdContext.WithTimeout(stdContext.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(s.received()); got != 3 {
+		t.Fatalf("expected 3 delivered messages after close but got %d", got)
+	}
+	if got := atomic.LoadInt32(&failed); got != 0 {
+		t.Fatalf("expected no failures but got %d", got)
+	}
+
+	if err := m.Send(&mailer.Message{To: []string{"gopher@example.com"}, Text: "late"}); !mailer.ErrClosed.Equal(err) {
+		t.Fatalf("expected ErrClosed but got %v", err)
+	}
+}
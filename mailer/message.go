@@ -0,0 +1,187 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message is an e-mail message.
+//
+// The body is the Text, the HTML or both of them (multipart/alternative),
+// the HTML can be rendered from a Template through the view engine of the Application.
+type Message struct {
+	// From is the sender, defaults to the `Config#From`.
+	From    string
+	To      []string
+	Cc      []string
+	Bcc     []string
+	ReplyTo string
+	Subject string
+	// Text is the plain text body.
+	Text string
+	// HTML is the HTML body.
+	HTML string
+	// Template is the view which is rendered as the HTML body, with the Layout and the Data,
+	// i.e "emails/welcome.html", see `Application#RegisterView`.
+	Template string
+	Layout   string
+	Data     interface{}
+	// Headers are the extra headers of the message, i.e "List-Unsubscribe".
+	Headers map[string]string
+}
+
+// formatAddressList parses and formats the "list" addresses for a header,
+// the display names are encoded if needed.
+func formatAddressList(list []string) (string, error) {
+	formatted := make([]string, 0, len(list))
+	for _, s := range list {
+		addr, err := mail.ParseAddress(s)
+		if err != nil {
+			return "", errInvalidAddress.Format(s)
+		}
+		formatted = append(formatted, addr.String())
+	}
+
+	return strings.Join(formatted, ", "), nil
+}
+
+// recipients returns the addresses of the To, the Cc and the Bcc.
+func (msg *Message) recipients() ([]string, error) {
+	var rcpt []string
+	for _, list := range [][]string{msg.To, msg.Cc, msg.Bcc} {
+		for _, s := range list {
+			addr, err := mail.ParseAddress(s)
+			if err != nil {
+				return nil, errInvalidAddress.Format(s)
+			}
+			rcpt = append(rcpt, addr.Address)
+		}
+	}
+
+	if len(rcpt) == 0 {
+		return nil, errNoRecipients
+	}
+
+	return rcpt, nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func messageID(from string) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	domain := "localhost"
+	if idx := strings.LastIndexByte(from, '@'); idx >= 0 {
+		domain = strings.TrimRight(from[idx+1:], ">")
+	}
+
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
+
+// bytes returns the RFC 5322 form of the message, the Bcc is not included.
+func (msg *Message) bytes(now time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	}
+
+	from, err := formatAddressList([]string{msg.From})
+	if err != nil {
+		return nil, err
+	}
+	header("From", from)
+
+	for _, h := range []struct {
+		key  string
+		list []string
+	}{{"To", msg.To}, {"Cc", msg.Cc}} {
+		if len(h.list) == 0 {
+			continue
+		}
+		value, err := formatAddressList(h.list)
+		if err != nil {
+			return nil, err
+		}
+		header(h.key, value)
+	}
+
+	if msg.ReplyTo != "" {
+		replyTo, err := formatAddressList([]string{msg.ReplyTo})
+		if err != nil {
+			return nil, err
+		}
+		header("Reply-To", replyTo)
+	}
+
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from))
+	header("MIME-Version", "1.0")
+
+	keys := make([]string, 0, len(msg.Headers))
+	for k := range msg.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		header(textproto.CanonicalMIMEHeaderKey(k), mime.QEncoding.Encode("utf-8", msg.Headers[k]))
+	}
+
+	if msg.Text != "" && msg.HTML != "" {
+		mw := multipart.NewWriter(&b)
+		header("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+		b.WriteString("\r\n")
+
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain; charset=utf-8", msg.Text},
+			{"text/html; charset=utf-8", msg.HTML},
+		} {
+			pw, err := mw.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.contentType},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err = writeQuotedPrintable(pw, part.body); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := mw.Close(); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+
+	contentType, body := "text/plain; charset=utf-8", msg.Text
+	if msg.HTML != "" {
+		contentType, body = "text/html; charset=utf-8", msg.HTML
+	}
+	header("Content-Type", contentType)
+	header("Content-Transfer-Encoding", "quoted-printable")
+	b.WriteString("\r\n")
+
+	if err := writeQuotedPrintable(&b, body); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"bytes"
+)
+
+// Label is a label of a `Sample`, i.e `route="user"`.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a value of a `Family` with its labels.
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// Family is a metric of a `Collector` with its samples,
+// its name is prefixed by the `Options#Namespace`, i.e "bandwidth_sent_bytes" as "iris_bandwidth_sent_bytes".
+type Family struct {
+	Name string
+	// Type is the Prometheus type of the metric, i.e "counter" or "gauge".
+	Type    string
+	Help    string
+	Samples []Sample
+}
+
+// Collector provides custom metrics to the `Metrics`, see `Metrics#Register`.
+type Collector interface {
+	// Collect returns the current values of the collector's metrics,
+	// it's called on each `Metrics#Expose`.
+	Collect() []Family
+}
+
+// CollectorFunc is a function which implements the `Collector`.
+type CollectorFunc func() []Family
+
+// Collect calls the "fn".
+func (fn CollectorFunc) Collect() []Family {
+	return fn()
+}
+
+// Register adds a "c" collector, its metrics are exposed after the requests' ones,
+// i.e the `middleware/bandwidth#Meter`.
+//
+// Usage: app.Metrics().Register(meter)
+// 注册自定义的指标收集器
+func (m *Metrics) Register(c Collector) {
+	m.mu.Lock()
+	m.collectors = append(m.collectors, c)
+	m.mu.Unlock()
+}
+
+func (m *Metrics) exposeCollectors(buf *bytes.Buffer) {
+	m.mu.Lock()
+	collectors := append([]Collector(nil), m.collectors...)
+	m.mu.Unlock()
+
+	for _, c := range collectors {
+		for _, f := range c.Collect() {
+			name := m.opts.Namespace + "_" + f.Name
+			writeHeader(buf, name, f.Type, f.Help)
+			for _, s := range f.Samples {
+				writeSample(buf, name, sampleLabels(s.Labels), formatFloat(s.Value))
+			}
+		}
+	}
+}
+
+func sampleLabels(labels []Label) string {
+	var s string
+	for i, l := range labels {
+		if i > 0 {
+			s += ","
+		}
+		s += l.Name + `="` + labelValueReplacer.Replace(l.Value) + `"`
+	}
+	return s
+}
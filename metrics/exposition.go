@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Expose returns the metrics in the Prometheus text exposition format, the series are sorted,
+// the metrics of the registered collectors follow, see `Register`.
+func (m *Metrics) Expose() []byte {
+	m.mu.Lock()
+	keys := make([]seriesKey, 0, len(m.series))
+	snapshot := make(map[seriesKey]series, len(m.series))
+	for k, s := range m.series {
+		keys = append(keys, k)
+		cp := *s
+		cp.durationHist = append([]uint64(nil), s.durationHist...)
+		cp.sizeHist = append([]uint64(nil), s.sizeHist...)
+		snapshot[k] = cp
+	}
+	m.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+
+	ns := m.opts.Namespace
+	buf := new(bytes.Buffer)
+
+	name := ns + "_http_requests_total"
+	writeHeader(buf, name, "counter", "The number of the served requests.")
+	for _, k := range keys {
+		writeSample(buf, name, labels(k, ""), strconv.FormatUint(snapshot[k].count, 10))
+	}
+
+	name = ns + "_http_request_duration_seconds"
+	writeHeader(buf, name, "histogram", "The duration of the requests in seconds.")
+	for _, k := range keys {
+		s := snapshot[k]
+		writeHistogram(buf, name, k, m.opts.DurationBuckets, s.durationHist, s.durationSum, s.count)
+	}
+
+	name = ns + "_http_response_size_bytes"
+	writeHeader(buf, name, "histogram", "The size of the response bodies which are sent to the clients, in bytes.")
+	for _, k := range keys {
+		s := snapshot[k]
+		writeHistogram(buf, name, k, m.opts.SizeBuckets, s.sizeHist, s.sizeSum, s.count)
+	}
+
+	name = ns + "_http_requests_in_flight"
+	writeHeader(buf, name, "gauge", "The number of the requests which are served right now.")
+	writeSample(buf, name, "", strconv.FormatInt(atomic.LoadInt64(&m.inFlight), 10))
+
+	m.exposeCollectors(buf)
+	return buf.Bytes()
+}
+
+func writeHeader(buf *bytes.Buffer, name, typ, help string) {
+	buf.WriteString("# HELP " + name + " " + help + "\n")
+	buf.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+func writeSample(buf *bytes.Buffer, name, labels, value string) {
+	buf.WriteString(name)
+	if labels != "" {
+		buf.WriteString("{" + labels + "}")
+	}
+	buf.WriteString(" " + value + "\n")
+}
+
+func writeHistogram(buf *bytes.Buffer, name string, k seriesKey, buckets []float64, hist []uint64, sum float64, count uint64) {
+	var cumulative uint64
+	for i, upper := range buckets {
+		cumulative += hist[i]
+		writeSample(buf, name+"_bucket", labels(k, formatFloat(upper)), strconv.FormatUint(cumulative, 10))
+	}
+	writeSample(buf, name+"_bucket", labels(k, "+Inf"), strconv.FormatUint(count, 10))
+	writeSample(buf, name+"_sum", labels(k, ""), formatFloat(sum))
+	writeSample(buf, name+"_count", labels(k, ""), strconv.FormatUint(count, 10))
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels returns the labels of a sample, the "le" is the upper bound of a histogram's bucket.
+func labels(k seriesKey, le string) string {
+	s := `route="` + labelValueReplacer.Replace(k.route) + `",method="` + labelValueReplacer.Replace(k.method) +
+		`",status="` + strconv.Itoa(k.status) + `"`
+	if le != "" {
+		s += `,le="` + le + `"`
+	}
+	return s
+}
@@ -0,0 +1,194 @@
+// Package metrics provides the Prometheus instrumentation of the requests,
+// without any external dependency, see `iris#Application.Metrics`.
+//
+// The requests are labeled by the name of the matched route, its method and the status code,
+// the unmatched requests are labeled with the `UnmatchedRoute` instead of their raw path,
+// so the number of the series is bounded:
+//
+//	iris_http_requests_total{route="GET/users/{id:uint64}",method="GET",status="200"} 42
+//	iris_http_request_duration_seconds_bucket{route="...",method="GET",status="200",le="0.1"} 40
+//	iris_http_response_size_bytes_bucket{route="...",method="GET",status="200",le="1000"} 41
+//	iris_http_requests_in_flight 3
+//
+// The status code and the response size are collected after the response is flushed to the client,
+// so the responses of the recorders, the fired error codes and the compressed bodies are measured as they're sent.
+//
+//	m := app.Metrics()
+//	app.Get("/metrics", m.Handler())
+//
+// Custom metrics, i.e the bandwidth usage of the `middleware/bandwidth`, are exposed by the registered collectors,
+// see `Metrics#Register`.
+//
+// 请求的 Prometheus 指标(请求数、耗时、进行中的请求数、响应大小)
+package metrics
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// UnmatchedRoute is the "route" label of the requests which didn't match a route, i.e the 404 ones.
+const UnmatchedRoute = "unmatched"
+
+var (
+	// DefaultDurationBuckets are the default upper bounds, in seconds, of the duration histogram.
+	DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	// DefaultSizeBuckets are the default upper bounds, in bytes, of the response size histogram.
+	DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+)
+
+// Options are the options of the `New`.
+type Options struct {
+	// Namespace is the prefix of the metrics' names.
+	//
+	// Defaults to "iris".
+	Namespace string
+	// DurationBuckets are the upper bounds, in seconds, of the request duration histogram.
+	//
+	// Defaults to `DefaultDurationBuckets`.
+	DurationBuckets []float64
+	// SizeBuckets are the upper bounds, in bytes, of the response size histogram.
+	//
+	// Defaults to `DefaultSizeBuckets`.
+	SizeBuckets []float64
+}
+
+type seriesKey struct {
+	route, method string
+	status        int
+}
+
+// series are the collected values of a route, method and status code.
+type series struct {
+	count        uint64
+	durationSum  float64
+	durationHist []uint64
+	sizeSum      float64
+	sizeHist     []uint64
+}
+
+// Metrics collects the requests' metrics and exposes them in the Prometheus text format.
+type Metrics struct {
+	opts     Options
+	inFlight int64
+
+	mu         sync.Mutex
+	series     map[seriesKey]*series
+	collectors []Collector
+}
+
+// New returns a new empty Metrics, its `Instrument` should be registered as a router middleware,
+// the `iris#Application.Metrics` does that automatically.
+func New(opts ...Options) *Metrics {
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if o.Namespace == "" {
+		o.Namespace = "iris"
+	}
+	if len(o.DurationBuckets) == 0 {
+		o.DurationBuckets = DefaultDurationBuckets
+	}
+	if len(o.SizeBuckets) == 0 {
+		o.SizeBuckets = DefaultSizeBuckets
+	}
+
+	return &Metrics{opts: o, series: make(map[seriesKey]*series)}
+}
+
+// Instrument is the pre-routing handler which measures the request, see `Router#UseRouter`,
+// the values are collected at the end of the request through the `context.OnEnd`.
+func (m *Metrics) Instrument(ctx context.Context) {
+	start := time.Now()
+	atomic.AddInt64(&m.inFlight, 1)
+
+	var size int64
+	ctx.TrackBandwidth(func(_, out int64) {
+		size += out
+	})
+
+	context.OnEnd(ctx, func() {
+		atomic.AddInt64(&m.inFlight, -1)
+
+		route := UnmatchedRoute
+		if r := ctx.GetCurrentRoute(); r != nil {
+			route = r.Name()
+		}
+
+		m.observe(seriesKey{route: route, method: ctx.Method(), status: ctx.GetStatusCode()}, time.Since(start), size)
+	})
+
+	ctx.Next()
+}
+
+func (m *Metrics) observe(key seriesKey, elapsed time.Duration, size int64) {
+	m.mu.Lock()
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{
+			durationHist: make([]uint64, len(m.opts.DurationBuckets)),
+			sizeHist:     make([]uint64, len(m.opts.SizeBuckets)),
+		}
+		m.series[key] = s
+	}
+
+	s.count++
+	seconds := elapsed.Seconds()
+	s.durationSum += seconds
+	observeBuckets(s.durationHist, m.opts.DurationBuckets, seconds)
+	s.sizeSum += float64(size)
+	observeBuckets(s.sizeHist, m.opts.SizeBuckets, float64(size))
+	m.mu.Unlock()
+}
+
+// observeBuckets increments the non-cumulative count of the first bucket which the "v" fits in.
+func observeBuckets(hist []uint64, buckets []float64, v float64) {
+	if idx := sort.SearchFloat64s(buckets, v); idx < len(buckets) {
+		hist[idx]++
+	}
+}
+
+// InFlight returns the number of the requests which are served right now.
+func (m *Metrics) InFlight() int64 {
+	return atomic.LoadInt64(&m.inFlight)
+}
+
+// Count returns the number of the served requests of the "routeName" with the "method" and the "statusCode".
+func (m *Metrics) Count(routeName, method string, statusCode int) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok := m.series[seriesKey{route: routeName, method: method, status: statusCode}]; ok {
+		return s.count
+	}
+
+	return 0
+}
+
+// Reset removes the collected values, the in-flight requests and the registered collectors are kept.
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	m.series = make(map[seriesKey]*series)
+	m.mu.Unlock()
+}
+
+// Handler returns the handler of the metrics endpoint, i.e `app.Get("/metrics", m.Handler())`,
+// it renders the metrics in the Prometheus text exposition format.
+func (m *Metrics) Handler() context.Handler {
+	return func(ctx context.Context) {
+		// not through the ctx.ContentType, the version contains dots.
+		ctx.Header(context.ContentTypeHeaderKey, ContentType)
+		ctx.Write(m.Expose())
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
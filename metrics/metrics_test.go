@@ -0,0 +1,90 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	app := iris.New()
+	m := app.Metrics()
+	app.Get("/metrics", m.Handler())
+	app.Get("/users/{id:uint64}", func(ctx context.Context) {
+		ctx.WriteString("user")
+	}).Name = "user"
+	app.Get("/fail", func(ctx context.Context) {
+		ctx.StatusCode(iris.StatusInternalServerError)
+	}).Name = "fail"
+
+	e := httptest.New(t, app)
+
+	e.GET("/users/1").Expect().Status(iris.StatusOK)
+	e.GET("/users/2").Expect().Status(iris.StatusOK)
+	e.GET("/fail").Expect().Status(iris.StatusInternalServerError)
+	e.GET("/notfound").Expect().Status(iris.StatusNotFound)
+
+	if expected, got := uint64(2), m.Count("user", "GET", iris.StatusOK); expected != got {
+		t.Fatalf("expected %d requests but got %d", expected, got)
+	}
+	if expected, got := uint64(1), m.Count(metrics.UnmatchedRoute, "GET", iris.StatusNotFound); expected != got {
+		t.Fatalf("expected %d unmatched requests but got %d", expected, got)
+	}
+	if got := m.InFlight(); got != 0 {
+		t.Fatalf("expected no in-flight requests but got %d", got)
+	}
+
+	body := e.GET("/metrics").Expect().Status(iris.StatusOK).
+		ContentType("text/plain", "utf-8").Body().Raw()
+
+	for _, expected := range []string{
+		"# TYPE iris_http_requests_total counter\n",
+		`iris_http_requests_total{route="user",method="GET",status="200"} 2` + "\n",
+		`iris_http_requests_total{route="fail",method="GET",status="500"} 1` + "\n",
+		`iris_http_response_size_bytes_bucket{route="user",method="GET",status="200",le="100"} 2` + "\n",
+		`iris_http_response_size_bytes_sum{route="user",method="GET",status="200"} 8` + "\n",
+		`iris_http_request_duration_seconds_count{route="user",method="GET",status="200"} 2` + "\n",
+		// the metrics request itself.
+		"iris_http_requests_in_flight 1\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("expected the metrics to contain:\n%s\nbut got:\n%s", expected, body)
+		}
+	}
+}
+
+func TestMetricsCollector(t *testing.T) {
+	m := metrics.New(metrics.Options{Namespace: "app"})
+	m.Register(metrics.CollectorFunc(func() []metrics.Family {
+		return []metrics.Family{{
+			Name: "queue_size",
+			Type: "gauge",
+			Help: "The size of the queues.",
+			Samples: []metrics.Sample{
+				{Labels: []metrics.Label{{Name: "queue", Value: `mail"s`}}, Value: 3},
+				{Value: 0.5},
+			},
+		}}
+	}))
+
+	body := string(m.Expose())
+	for _, expected := range []string{
+		"# HELP app_queue_size The size of the queues.\n# TYPE app_queue_size gauge\n",
+		`app_queue_size{queue="mail\"s"} 3` + "\n",
+		"app_queue_size 0.5\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("expected the metrics to contain:\n%s\nbut got:\n%s", expected, body)
+		}
+	}
+
+	// the collectors are kept.
+	m.Reset()
+	if body := string(m.Expose()); !strings.Contains(body, "app_queue_size 0.5\n") {
+		t.Fatalf("expected the collector to be kept after the reset but got:\n%s", body)
+	}
+}
@@ -0,0 +1,163 @@
+// Package accesslog provides a middleware which records the access logs of the requests,
+// the method, path, status code, bytes written, latency, remote address and route name of each one,
+// with pluggable formatters (Common Log Format, JSON) and an async buffered writer.
+// It can be registered globally or per route.
+package accesslog
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// Log is the access log of a request.
+type Log struct {
+	// Time is the time that the request started.
+	Time time.Time `json:"time"`
+	// Latency is the time that the handlers took to serve the request.
+	Latency time.Duration `json:"latency"`
+	Method  string        `json:"method"`
+	Path    string        `json:"path"`
+	Query   string        `json:"query,omitempty"`
+	Proto   string        `json:"proto"`
+	// StatusCode is the response's status code.
+	StatusCode int `json:"status"`
+	// BytesWritten is the number of the response body bytes written.
+	BytesWritten int    `json:"bytes"`
+	RemoteAddr   string `json:"remoteAddr"`
+	// RouteName is the name of the route which served the request, if any.
+	RouteName string `json:"route,omitempty"`
+	Referer   string `json:"referer,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+}
+
+// AccessLog records the access logs, see `New`.
+type AccessLog struct {
+	config Config
+	async  *AsyncWriter
+
+	mu sync.Mutex // protects the synchronous writes.
+}
+
+// New returns a new access log of the "c" configs.
+// Register its `Handler` to all routes with the `UseGlobal` (or `Use` for a Party)
+// or to specific routes only, it should be the first handler.
+// The requests which do not match a route (404) are logged only if the Handler
+// is registered to the error handlers too, see `Party#OnErrorCode`.
+// Call its `Close` on shutdown to write the waiting logs.
+//
+// Usage:
+// ac := accesslog.New(accesslog.Config{Output: f, Formatter: accesslog.JSONFormatter})
+// defer ac.Close()
+// app.UseGlobal(ac.Handler)
+func New(c ...Config) *AccessLog {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		config = c[0]
+		def := DefaultConfig()
+		if config.Output == nil {
+			config.Output = def.Output
+		}
+		if config.Formatter == nil {
+			config.Formatter = def.Formatter
+		}
+		if config.QueueSize <= 0 {
+			config.QueueSize = def.QueueSize
+		}
+	}
+
+	ac := &AccessLog{config: config}
+	if !config.Sync {
+		ac.async = NewAsyncWriter(config.Output, config.QueueSize)
+	}
+
+	return ac
+}
+
+// Handler is the middleware which records the access log of the request
+// after the next handlers are executed.
+func (ac *AccessLog) Handler(ctx context.Context) {
+	if ac.config.Skip != nil && ac.config.Skip(ctx) {
+		ctx.Next()
+		return
+	}
+
+	start := time.Now()
+	ctx.Next()
+	latency := time.Since(start)
+
+	r := ctx.Request()
+	l := &Log{
+		Time:       start,
+		Latency:    latency,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Query:      r.URL.RawQuery,
+		Proto:      r.Proto,
+		StatusCode: ctx.GetStatusCode(),
+		RemoteAddr: ctx.RemoteAddr(),
+		Referer:    r.Referer(),
+		UserAgent:  r.UserAgent(),
+	}
+
+	if n := ctx.ResponseWriter().Written(); n > 0 {
+		l.BytesWritten = n
+	}
+
+	if route := ctx.GetCurrentRoute(); route != nil {
+		l.RouteName = route.Name()
+	}
+
+	if err := ac.Write(l); err != nil {
+		ctx.Application().Logger().Errorf("accesslog: %v", err)
+	}
+}
+
+// Write formats and writes the "l", useful to record the logs of custom sources.
+func (ac *AccessLog) Write(l *Log) error {
+	b, err := ac.config.Formatter(l)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	if ac.async != nil {
+		_, err = ac.async.Write(b)
+		return err
+	}
+
+	ac.mu.Lock()
+	_, err = ac.config.Output.Write(b)
+	ac.mu.Unlock()
+	return err
+}
+
+// Dropped returns the number of the logs which are dropped because the async queue was full.
+func (ac *AccessLog) Dropped() uint64 {
+	if ac.async == nil {
+		return 0
+	}
+
+	return ac.async.Dropped()
+}
+
+// Close writes the waiting logs when `Sync` is false
+// and closes the `Config#Output` if it's an io.Closer, except the os.Stdout and os.Stderr.
+func (ac *AccessLog) Close() error {
+	if ac.async != nil {
+		ac.async.Close()
+	}
+
+	if ac.config.Output == os.Stdout || ac.config.Output == os.Stderr {
+		return nil
+	}
+
+	if c, ok := ac.config.Output.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package accesslog_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/middleware/accesslog"
+)
+
+// blockingWriter blocks the writes until its "release" is closed.
+type blockingWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAccessLogAsyncByDefault(t *testing.T) {
+	w := &blockingWriter{release: make(chan struct{})}
+	// a partial config, it should be still async.
+	ac := accesslog.New(accesslog.Config{Output: w, Formatter: accesslog.JSONFormatter})
+
+	written := make(chan error, 1)
+	go func() {
+		written <- ac.Write(&accesslog.Log{Method: "GET", Path: "/"})
+	}()
+
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the write to not wait for the output")
+	}
+
+	close(w.release)
+	if err := ac.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := w.String(); !strings.Contains(got, `"path":"/"`) {
+		t.Fatalf("expected the waiting log to be written on close but got %q", got)
+	}
+
+	// a write after close should not panic.
+	if err := ac.Write(&accesslog.Log{Method: "GET", Path: "/"}); !accesslog.ErrClosed.Equal(err) {
+		t.Fatalf("expected the ErrClosed but got %v", err)
+	}
+	if err := ac.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommonFormatterEscape(t *testing.T) {
+	l := &accesslog.Log{
+		Time:       time.Date(2019, 10, 10, 13, 55, 36, 0, time.UTC),
+		Method:     "GET",
+		Path:       "/a\n127.0.0.1 - - [10/Oct/2019:13:55:36 +0000] \"GET /admin HTTP/1.1\" 200 1",
+		Proto:      "HTTP/1.1",
+		StatusCode: 404,
+		RemoteAddr: "127.0.0.1",
+	}
+
+	b, err := accesslog.CommonFormatter(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `127.0.0.1 - - [10/Oct/2019:13:55:36 +0000] "GET /a\x0a127.0.0.1 - - [10/Oct/2019:13:55:36 +0000] \"GET /admin HTTP/1.1\" 200 1 HTTP/1.1" 404 -`
+	if got := string(b); got != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+}
+
+func TestAccessLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	ac := accesslog.New(accesslog.Config{Output: &buf, Sync: true})
+
+	app := iris.New()
+	app.UseGlobal(ac.Handler)
+	app.Get("/hello", func(ctx iris.Context) {
+		ctx.WriteString("hello")
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/hello").WithQuery("name", "kataras").Expect().Status(httptest.StatusOK)
+
+	line := buf.String()
+	if !strings.HasSuffix(line, "\"GET /hello?name=kataras HTTP/1.1\" 200 5\n") {
+		t.Fatalf("unexpected log line: %q", line)
+	}
+}
@@ -0,0 +1,45 @@
+package accesslog
+
+import (
+	"io"
+	"os"
+
+	"github.com/kataras/iris/context"
+)
+
+// Config the configs for the access log middleware.
+type Config struct {
+	// Output is the writer of the formatted logs,
+	// it's wrapped by an `AsyncWriter` unless `Sync` is true.
+	//
+	// Defaults to the os.Stdout.
+	Output io.Writer
+	// Formatter formats each one of the logs,
+	// see `CommonFormatter`, `CombinedFormatter` and `JSONFormatter`.
+	//
+	// Defaults to the `CommonFormatter`.
+	Formatter Formatter
+	// Sync writes the logs on the request's goroutine, the requests wait for the writes.
+	// By default the logs are written on a background goroutine through a buffered `AsyncWriter`.
+	//
+	// Defaults to false.
+	Sync bool
+	// QueueSize is the maximum number of the logs which are waiting to be written
+	// when `Sync` is false, the logs are dropped when it's exceeded.
+	//
+	// Defaults to 1024.
+	QueueSize int
+	// Skip reports whether the request should not be logged, i.e the health checks.
+	//
+	// Defaults to nil.
+	Skip func(ctx context.Context) bool
+}
+
+// DefaultConfig returns the default configs for the access log middleware.
+func DefaultConfig() Config {
+	return Config{
+		Output:    os.Stdout,
+		Formatter: CommonFormatter,
+		QueueSize: 1024,
+	}
+}
@@ -0,0 +1,84 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Formatter formats a log as a single line, the new line is appended by the `AccessLog`.
+type Formatter func(l *Log) ([]byte, error)
+
+// clfTimeFormat is the time format of the Common Log Format.
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// CommonFormatter formats the logs as the Common Log Format (CLF), i.e
+// 127.0.0.1 - - [10/Oct/2019:13:55:36 -0700] "GET /index.html HTTP/1.1" 200 2326
+var CommonFormatter Formatter = func(l *Log) ([]byte, error) {
+	return []byte(commonLine(l)), nil
+}
+
+// CombinedFormatter formats the logs as the Combined Log Format,
+// the Common Log Format plus the "Referer" and the "User-Agent" request headers.
+var CombinedFormatter Formatter = func(l *Log) ([]byte, error) {
+	return []byte(commonLine(l) + " " + strconv.Quote(l.Referer) + " " + strconv.Quote(l.UserAgent)), nil
+}
+
+// escape escapes the quotes, the backslashes and the non-printable bytes of a request's value, like the Apache does,
+// i.e a decoded path with a new line would start a forged log line.
+func escape(s string) string {
+	const hex = "0123456789abcdef"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7f:
+			b.WriteString("\\x")
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xf])
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+func commonLine(l *Log) string {
+	uri := l.Path
+	if l.Query != "" {
+		uri += "?" + l.Query
+	}
+
+	bytes := "-"
+	if l.BytesWritten > 0 {
+		bytes = strconv.Itoa(l.BytesWritten)
+	}
+
+	var b strings.Builder
+	b.WriteString(dash(escape(l.RemoteAddr)))
+	b.WriteString(" - - [")
+	b.WriteString(l.Time.Format(clfTimeFormat))
+	b.WriteString("] \"")
+	b.WriteString(escape(l.Method) + " " + escape(uri) + " " + escape(l.Proto))
+	b.WriteString("\" ")
+	b.WriteString(strconv.Itoa(l.StatusCode))
+	b.WriteString(" ")
+	b.WriteString(bytes)
+	return b.String()
+}
+
+// JSONFormatter formats the logs as JSON objects, one per line.
+var JSONFormatter Formatter = func(l *Log) ([]byte, error) {
+	return json.Marshal(l)
+}
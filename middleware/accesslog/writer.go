@@ -0,0 +1,101 @@
+package accesslog
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+// ErrClosed is returned by the `AsyncWriter#Write` after its `Close`.
+var ErrClosed = errors.New("accesslog: write after close")
+
+// AsyncWriter writes the logs to the underline writer on its own goroutine
+// through a buffer which is flushed when there are no more logs waiting,
+// so the callers are never blocked by a slow writer, i.e a file on a busy disk.
+//
+// The logs are dropped when more than "queueSize" are waiting, see `Dropped`.
+// 异步写入(带缓冲)，避免请求等待日志写入
+type AsyncWriter struct {
+	w       *bufio.Writer
+	queue   chan []byte
+	dropped uint64
+
+	// mu guards the queue, the writes after the `Close` are rejected.
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewAsyncWriter returns a new `AsyncWriter` of "w",
+// call its `Close` to write the waiting logs before exit.
+func NewAsyncWriter(w io.Writer, queueSize int) *AsyncWriter {
+	if queueSize <= 0 {
+		queueSize = DefaultConfig().QueueSize
+	}
+
+	a := &AsyncWriter{
+		w:     bufio.NewWriter(w),
+		queue: make(chan []byte, queueSize),
+		done:  make(chan struct{}),
+	}
+
+	go a.loop()
+	return a
+}
+
+func (a *AsyncWriter) loop() {
+	defer close(a.done)
+
+	for p := range a.queue {
+		a.w.Write(p)
+		if len(a.queue) == 0 {
+			a.w.Flush()
+		}
+	}
+
+	a.w.Flush()
+}
+
+// Write queues a copy of "p", it never blocks.
+// It returns the `ErrClosed` after the `Close`.
+func (a *AsyncWriter) Write(p []byte) (n int, err error) {
+	b := make([]byte, len(p))
+	copy(b, p)
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		return 0, ErrClosed
+	}
+
+	select {
+	case a.queue <- b:
+	default:
+		atomic.AddUint64(&a.dropped, 1)
+	}
+
+	return len(p), nil
+}
+
+// Dropped returns the number of the writes which are dropped because the queue was full.
+func (a *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// Close writes the waiting logs, the underline writer is not closed.
+// The writes after it return the `ErrClosed`.
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+	<-a.done
+
+	return nil
+}
@@ -0,0 +1,295 @@
+// Package bandwidth provides a middleware which accounts the request and response bytes
+// per route and per remote IP over rolling windows, it's built on the `context.Context#TrackBandwidth`.
+// Useful for fair-use throttling and billing.
+package bandwidth
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/metrics"
+)
+
+// Usage is the bandwidth usage of a route or a remote IP over the rolling window.
+type Usage struct {
+	// Requests is the number of the requests started in the window.
+	Requests int64 `json:"requests"`
+	// BytesIn is the number of the request body bytes read.
+	BytesIn int64 `json:"bytesIn"`
+	// BytesOut is the number of the response body bytes sent to the clients.
+	BytesOut int64 `json:"bytesOut"`
+}
+
+type bucket struct {
+	slot int64
+	Usage
+}
+
+// window is a ring buffer of buckets, each one of them keeps the usage of a "resolution" period.
+type window struct {
+	mu       sync.Mutex
+	buckets  []bucket
+	lastSlot int64
+}
+
+func newWindow(size int) *window {
+	return &window{buckets: make([]bucket, size)}
+}
+
+func (w *window) add(slot int64, requests, in, out int64) {
+	w.mu.Lock()
+	b := &w.buckets[slot%int64(len(w.buckets))]
+	if b.slot != slot {
+		*b = bucket{slot: slot}
+	}
+	b.Requests += requests
+	b.BytesIn += in
+	b.BytesOut += out
+	w.lastSlot = slot
+	w.mu.Unlock()
+}
+
+func (w *window) usage(slot int64) (u Usage) {
+	size := int64(len(w.buckets))
+
+	w.mu.Lock()
+	for _, b := range w.buckets {
+		if b.slot > slot-size && b.slot <= slot {
+			u.Requests += b.Requests
+			u.BytesIn += b.BytesIn
+			u.BytesOut += b.BytesOut
+		}
+	}
+	w.mu.Unlock()
+
+	return
+}
+
+func (w *window) idle(slot int64) bool {
+	w.mu.Lock()
+	idle := w.lastSlot <= slot-int64(len(w.buckets))
+	w.mu.Unlock()
+	return idle
+}
+
+// Meter accounts the request and response bytes per route and per remote IP, see `New`.
+type Meter struct {
+	config Config
+	size   int
+
+	mu     sync.RWMutex
+	routes map[string]*window
+	addrs  map[string]*window
+}
+
+// New returns a new bandwidth Meter based on the "c" configs,
+// its `Handler` should be registered as a middleware.
+//
+// Usage:
+// meter := bandwidth.New(bandwidth.Config{Window: time.Hour, Resolution: time.Minute})
+// app.Use(meter.Handler)
+// app.Get("/debug/bandwidth", meter.ReportHandler)
+// app.Metrics().Register(meter)
+// and meter.RemoteAddr(ctx.RemoteAddr()).BytesOut for throttling.
+func New(c ...Config) *Meter {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		if v := c[0].Window; v > 0 {
+			config.Window = v
+		}
+		if v := c[0].Resolution; v > 0 {
+			config.Resolution = v
+		}
+		if v := c[0].MaxRemoteAddrs; v > 0 {
+			config.MaxRemoteAddrs = v
+		}
+	}
+
+	size := int(config.Window / config.Resolution)
+	if size < 1 {
+		size = 1
+	}
+
+	return &Meter{
+		config: config,
+		size:   size,
+		routes: make(map[string]*window),
+		addrs:  make(map[string]*window),
+	}
+}
+
+func (m *Meter) slot(t time.Time) int64 {
+	return t.UnixNano() / int64(m.config.Resolution)
+}
+
+func (m *Meter) window(windows map[string]*window, key string, limit int) *window {
+	m.mu.RLock()
+	w, ok := windows[key]
+	m.mu.RUnlock()
+	if ok {
+		return w
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if w, ok = windows[key]; ok {
+		return w
+	}
+
+	if limit > 0 && len(windows) >= limit {
+		slot := m.slot(time.Now())
+		for k, w := range windows {
+			if w.idle(slot) {
+				delete(windows, k)
+			}
+		}
+
+		if len(windows) >= limit {
+			return nil
+		}
+	}
+
+	w = newWindow(m.size)
+	windows[key] = w
+	return w
+}
+
+// Handler is the middleware which accounts the current request's bytes
+// to its route and to its remote IP.
+func (m *Meter) Handler(ctx context.Context) {
+	var routeName string
+	if r := ctx.GetCurrentRoute(); r != nil {
+		routeName = r.Name()
+	}
+
+	routeWindow := m.window(m.routes, routeName, 0)
+	addrWindow := m.window(m.addrs, ctx.RemoteAddr(), m.config.MaxRemoteAddrs)
+
+	add := func(requests, in, out int64) {
+		slot := m.slot(time.Now())
+		routeWindow.add(slot, requests, in, out)
+		if addrWindow != nil {
+			addrWindow.add(slot, requests, in, out)
+		}
+	}
+
+	add(1, 0, 0)
+	ctx.TrackBandwidth(func(in, out int64) {
+		add(0, in, out)
+	})
+
+	ctx.Next()
+}
+
+func (m *Meter) usage(windows map[string]*window, key string) Usage {
+	m.mu.RLock()
+	w, ok := windows[key]
+	m.mu.RUnlock()
+	if !ok {
+		return Usage{}
+	}
+
+	return w.usage(m.slot(time.Now()))
+}
+
+// Route returns the usage of the "routeName" route over the rolling window.
+func (m *Meter) Route(routeName string) Usage {
+	return m.usage(m.routes, routeName)
+}
+
+// RemoteAddr returns the usage of the "ip" remote address over the rolling window,
+// the "ip" is the `context.Context#RemoteAddr`.
+func (m *Meter) RemoteAddr(ip string) Usage {
+	return m.usage(m.addrs, ip)
+}
+
+// Entry is the usage of a route or a remote IP, see `Report`.
+type Entry struct {
+	Key string `json:"key"`
+	Usage
+}
+
+// Report is the bandwidth report of the routes and the remote IPs over the rolling window,
+// the entries are sorted by their sent bytes, descending.
+type Report struct {
+	Window      time.Duration `json:"window"`
+	Routes      []Entry       `json:"routes"`
+	RemoteAddrs []Entry       `json:"remoteAddrs"`
+}
+
+func (m *Meter) entries(windows map[string]*window, slot int64) []Entry {
+	m.mu.RLock()
+	entries := make([]Entry, 0, len(windows))
+	for key, w := range windows {
+		if u := w.usage(slot); u.Requests > 0 || u.BytesIn > 0 || u.BytesOut > 0 {
+			entries = append(entries, Entry{Key: key, Usage: u})
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].BytesOut == entries[j].BytesOut {
+			return entries[i].Key < entries[j].Key
+		}
+		return entries[i].BytesOut > entries[j].BytesOut
+	})
+
+	return entries
+}
+
+// Report returns the current bandwidth report.
+func (m *Meter) Report() Report {
+	slot := m.slot(time.Now())
+	return Report{
+		Window:      m.config.Window,
+		Routes:      m.entries(m.routes, slot),
+		RemoteAddrs: m.entries(m.addrs, slot),
+	}
+}
+
+// Collect returns the usage of the routes and the remote IPs over the rolling window as gauges,
+// it implements the `metrics#Collector` so the meter can be exposed through the metrics registry,
+// i.e `app.Metrics().Register(meter)`:
+//
+//	iris_bandwidth_route_sent_bytes{route="user"} 4096
+//	iris_bandwidth_remote_addr_received_bytes{remote_addr="203.0.113.7"} 512
+//
+// The number of the remote IPs' series is bounded by the `Config#MaxRemoteAddrs`.
+func (m *Meter) Collect() []metrics.Family {
+	report := m.Report()
+	return append(
+		usageFamilies("route", "the routes", report.Routes),
+		usageFamilies("remote_addr", "the remote IPs", report.RemoteAddrs)...)
+}
+
+// usageFamilies returns the usage families of the "entries", the "kind" is their label.
+func usageFamilies(kind, of string, entries []Entry) []metrics.Family {
+	families := []metrics.Family{
+		{Name: "bandwidth_" + kind + "_requests", Help: "The number of the requests of " + of + " over the rolling window."},
+		{Name: "bandwidth_" + kind + "_received_bytes", Help: "The request body bytes of " + of + " over the rolling window."},
+		{Name: "bandwidth_" + kind + "_sent_bytes", Help: "The response body bytes of " + of + " over the rolling window."},
+	}
+
+	for i := range families {
+		families[i].Type = "gauge"
+		families[i].Samples = make([]metrics.Sample, 0, len(entries))
+	}
+
+	for _, e := range entries {
+		labels := []metrics.Label{{Name: kind, Value: e.Key}}
+		families[0].Samples = append(families[0].Samples, metrics.Sample{Labels: labels, Value: float64(e.Requests)})
+		families[1].Samples = append(families[1].Samples, metrics.Sample{Labels: labels, Value: float64(e.BytesIn)})
+		families[2].Samples = append(families[2].Samples, metrics.Sample{Labels: labels, Value: float64(e.BytesOut)})
+	}
+
+	return families
+}
+
+// ReportHandler renders the current bandwidth report as JSON,
+// it can be registered to a debug endpoint, i.e `app.Get("/debug/bandwidth", meter.ReportHandler)`.
+func (m *Meter) ReportHandler(ctx context.Context) {
+	ctx.JSON(m.Report())
+}
@@ -0,0 +1,102 @@
+package bandwidth_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/middleware/bandwidth"
+)
+
+func newApp(meter *bandwidth.Meter) *iris.Application {
+	app := iris.New()
+	app.Use(meter.Handler)
+	app.Post("/upload", func(ctx iris.Context) {
+		b, _ := ioutil.ReadAll(ctx.Request().Body)
+		ctx.Writef("%d", len(b))
+	}).Name = "upload"
+	app.Get("/download", func(ctx iris.Context) {
+		ctx.WriteString(strings.Repeat("a", 100))
+	}).Name = "download"
+	app.Get("/metrics", app.Metrics().Handler())
+	app.Metrics().Register(meter)
+
+	return app
+}
+
+func TestMeter(t *testing.T) {
+	meter := bandwidth.New(bandwidth.Config{Window: time.Minute, Resolution: time.Second})
+	app := newApp(meter)
+	e := httptest.New(t, app, httptest.RemoteAddr("203.0.113.7:1234"))
+	other := httptest.New(t, app, httptest.RemoteAddr("198.51.100.9:1234"))
+
+	e.POST("/upload").WithText(strings.Repeat("b", 50)).Expect().Status(iris.StatusOK).Body().Equal("50")
+	e.GET("/download").Expect().Status(iris.StatusOK)
+	other.GET("/download").Expect().Status(iris.StatusOK)
+
+	if expected, got := (bandwidth.Usage{Requests: 1, BytesIn: 50, BytesOut: 2}), meter.Route("upload"); expected != got {
+		t.Fatalf("expected the upload usage %+v but got %+v", expected, got)
+	}
+	if expected, got := (bandwidth.Usage{Requests: 2, BytesOut: 200}), meter.Route("download"); expected != got {
+		t.Fatalf("expected the download usage %+v but got %+v", expected, got)
+	}
+	if expected, got := (bandwidth.Usage{Requests: 2, BytesIn: 50, BytesOut: 102}), meter.RemoteAddr("203.0.113.7"); expected != got {
+		t.Fatalf("expected the remote address usage %+v but got %+v", expected, got)
+	}
+
+	report := meter.Report()
+	if len(report.Routes) != 2 || report.Routes[0].Key != "download" || report.Routes[1].Key != "upload" {
+		t.Fatalf("expected the routes sorted by the sent bytes but got %+v", report.Routes)
+	}
+	if len(report.RemoteAddrs) != 2 || report.RemoteAddrs[0].Key != "203.0.113.7" {
+		t.Fatalf("expected the remote addresses sorted by the sent bytes but got %+v", report.RemoteAddrs)
+	}
+}
+
+func TestMeterWindow(t *testing.T) {
+	meter := bandwidth.New(bandwidth.Config{Window: 40 * time.Millisecond, Resolution: 10 * time.Millisecond, MaxRemoteAddrs: 1})
+	app := newApp(meter)
+	e := httptest.New(t, app, httptest.RemoteAddr("203.0.113.7:1234"))
+	other := httptest.New(t, app, httptest.RemoteAddr("198.51.100.9:1234"))
+
+	e.GET("/download").Expect().Status(iris.StatusOK)
+	// no room for it.
+	other.GET("/download").Expect().Status(iris.StatusOK)
+	if got := meter.RemoteAddr("198.51.100.9"); got.Requests != 0 {
+		t.Fatalf("expected the remote address to not be tracked but got %+v", got)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if got := meter.Route("download"); got != (bandwidth.Usage{}) {
+		t.Fatalf("expected the usage to be out of the window but got %+v", got)
+	}
+
+	// the idle one is removed.
+	other.GET("/download").Expect().Status(iris.StatusOK)
+	if expected, got := (bandwidth.Usage{Requests: 1, BytesOut: 100}), meter.RemoteAddr("198.51.100.9"); expected != got {
+		t.Fatalf("expected the remote address usage %+v but got %+v", expected, got)
+	}
+}
+
+func TestMeterMetrics(t *testing.T) {
+	meter := bandwidth.New()
+	e := httptest.New(t, newApp(meter), httptest.RemoteAddr("203.0.113.7:1234"))
+
+	e.GET("/download").Expect().Status(iris.StatusOK)
+	body := e.GET("/metrics").Expect().Status(iris.StatusOK).Body().Raw()
+
+	for _, expected := range []string{
+		"# TYPE iris_bandwidth_route_sent_bytes gauge\n",
+		`iris_bandwidth_route_requests{route="download"} 1` + "\n",
+		`iris_bandwidth_route_sent_bytes{route="download"} 100` + "\n",
+		`iris_bandwidth_remote_addr_received_bytes{remote_addr="203.0.113.7"} 0` + "\n",
+		`iris_bandwidth_remote_addr_sent_bytes{remote_addr="203.0.113.7"} 100` + "\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("expected the metrics to contain:\n%s\nbut got:\n%s", expected, body)
+		}
+	}
+}
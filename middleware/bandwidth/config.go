@@ -0,0 +1,31 @@
+package bandwidth
+
+import "time"
+
+// Config the configs for the bandwidth meter.
+type Config struct {
+	// Window is the rolling window that the bytes are aggregated on.
+	//
+	// Defaults to one minute.
+	Window time.Duration
+	// Resolution is the duration of each one of the window's buckets,
+	// the window is kept as a ring buffer of Window / Resolution buckets.
+	//
+	// Defaults to one second.
+	Resolution time.Duration
+	// MaxRemoteAddrs is the maximum number of the tracked remote IPs,
+	// the idle ones are removed when it's exceeded
+	// and the new ones are not tracked until there is room for them.
+	//
+	// Defaults to 10000.
+	MaxRemoteAddrs int
+}
+
+// DefaultConfig returns the default configs for the bandwidth meter.
+func DefaultConfig() Config {
+	return Config{
+		Window:         time.Minute,
+		Resolution:     time.Second,
+		MaxRemoteAddrs: 10000,
+	}
+}
@@ -128,5 +128,7 @@ func (b *basicAuthMiddleware) Serve(ctx context.Context) {
 			return
 		}
 	}
+	// expose the authenticated user to the next handlers, see `context.GetClaims(ctx).Subject()`.
+	context.SetClaims(ctx, context.Claims{"sub": auth.Username})
 	ctx.Next() // continue
 }
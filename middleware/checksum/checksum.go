@@ -0,0 +1,294 @@
+// Package checksum provides a middleware which verifies the request bodies against
+// their `Content-MD5` (RFC 1864) or `Digest` (RFC 3230) request headers,
+// i.e "Content-MD5: Q2hlY2sgSW50ZWdyaXR5IQ==" or "Digest: SHA-256=X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=".
+//
+// The small bodies, up to the `Config#MaxBufferSize`, are read and verified before the next handlers.
+// The rest are hashed while they're read by the next handlers, they're not buffered,
+// so the large uploads are verified without being kept in memory.
+// When the checksum does not match, the body's `Read` returns the `ErrMismatch`
+// instead of the end of the body, so the `ReadJSON`, the `FormFile` and the `UploadFormFiles` fail,
+// and the request is rejected with 400 Bad Request, see `Config#OnMismatch`.
+// The rest of a body which is not read to its end by the next handlers is read and verified after them.
+//
+// The expected checksum can be a claim of a signed url too, see `URLParam`,
+// so the body is bound to the url's signature, i.e of the `Application#SignURL`.
+package checksum
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+)
+
+var (
+	// ErrMissing is passed to the `Config#OnMismatch` when the `Config#Required` is true
+	// and the request has no supported checksum header.
+	ErrMissing = errors.New("checksum: missing Content-MD5 or Digest header")
+	// ErrInvalidHeader is passed to the `Config#OnMismatch` when a checksum cannot be decoded.
+	ErrInvalidHeader = errors.New("checksum: invalid %s header")
+	// ErrMismatch is returned by the body's `Read` and passed to the `Config#OnMismatch`
+	// when the received body does not match its checksum.
+	ErrMismatch = errors.New("checksum: the body does not match its %s checksum")
+)
+
+// Algorithms are the supported algorithms of the `Digest` header, by their lowercase names.
+var Algorithms = map[string]func() hash.Hash{
+	"md5":     md5.New,
+	"sha":     sha1.New,
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+}
+
+// Checksum is an expected checksum of a request body.
+type Checksum struct {
+	// Algorithm is the lowercase name of the algorithm, see `Algorithms`.
+	Algorithm string
+	Sum       []byte
+}
+
+// Parse returns the checksums of the `Content-MD5` and `Digest` headers,
+// the algorithms of the `Digest` which are not supported are ignored.
+func Parse(header http.Header) ([]Checksum, error) {
+	var checksums []Checksum
+
+	if v := strings.TrimSpace(header.Get("Content-MD5")); v != "" {
+		sum, err := base64.StdEncoding.DecodeString(v)
+		if err != nil || len(sum) != md5.Size {
+			return nil, ErrInvalidHeader.Format("Content-MD5")
+		}
+		checksums = append(checksums, Checksum{Algorithm: "md5", Sum: sum})
+	}
+
+	digests, err := ParseDigest(header["Digest"]...)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(checksums, digests...), nil
+}
+
+// ParseDigest returns the checksums of the `Digest` header's "values",
+// i.e "SHA-256=X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=", the algorithms which are not supported are ignored.
+func ParseDigest(values ...string) ([]Checksum, error) {
+	var checksums []Checksum
+
+	for _, value := range values {
+		for _, instance := range strings.Split(value, ",") {
+			idx := strings.IndexByte(instance, '=')
+			if idx <= 0 {
+				return nil, ErrInvalidHeader.Format("Digest")
+			}
+
+			algorithm := strings.ToLower(strings.TrimSpace(instance[:idx]))
+			newHash, ok := Algorithms[algorithm]
+			if !ok {
+				continue
+			}
+
+			sum, err := base64.StdEncoding.DecodeString(strings.TrimSpace(instance[idx+1:]))
+			if err != nil || len(sum) != newHash().Size() {
+				return nil, ErrInvalidHeader.Format("Digest")
+			}
+			checksums = append(checksums, Checksum{Algorithm: algorithm, Sum: sum})
+		}
+	}
+
+	return checksums, nil
+}
+
+// Digest returns the `Digest` header's value of the "body" for the "algorithm", see `Algorithms`,
+// i.e "sha-256=X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=".
+// It's the expected checksum of a signed url's claim too, see `URLParam`.
+func Digest(algorithm string, body []byte) string {
+	algorithm = strings.ToLower(algorithm)
+	newHash, ok := Algorithms[algorithm]
+	if !ok {
+		return ""
+	}
+
+	h := newHash()
+	h.Write(body)
+	return algorithm + "=" + base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// URLParam returns a `Config#Checksums` which reads the expected checksum of the body
+// from the "name" url query parameter, in the `Digest` format, see `Digest`.
+// The url should be signed and verified before the checksum middleware,
+// so the body is bound to the url's signature.
+//
+// Usage:
+// app.Put("/uploads", app.RequireSignedURL(), checksum.New(checksum.Config{Required: true, Checksums: checksum.URLParam("digest")}), upload).Name = "upload"
+// link, _ := app.SignURL("upload", nil, time.Hour, url.Values{"digest": {checksum.Digest("sha-256", file)}})
+func URLParam(name string) func(ctx context.Context) ([]Checksum, error) {
+	return func(ctx context.Context) ([]Checksum, error) {
+		values := ctx.Request().URL.Query()[name]
+		if len(values) == 0 {
+			return nil, nil
+		}
+
+		checksums, err := ParseDigest(values...)
+		if err != nil {
+			return nil, ErrInvalidHeader.Format(name)
+		}
+		return checksums, nil
+	}
+}
+
+// Reader verifies the checksums of the body it reads, see `NewReader`.
+type Reader struct {
+	io.ReadCloser
+	checksums []Checksum
+	hashes    []hash.Hash
+	err       error
+}
+
+// NewReader returns a reader which hashes the "body" while it's read,
+// its `Read` returns the `ErrMismatch` instead of the `io.EOF` when the body does not match the "checksums".
+func NewReader(body io.ReadCloser, checksums []Checksum) *Reader {
+	r := &Reader{ReadCloser: body, checksums: checksums}
+	for _, c := range checksums {
+		r.hashes = append(r.hashes, Algorithms[c.Algorithm]())
+	}
+
+	return r
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	for _, h := range r.hashes {
+		h.Write(p[:n])
+	}
+
+	if err == io.EOF {
+		if r.err = r.verify(); r.err != nil {
+			return n, r.err
+		}
+	}
+
+	return n, err
+}
+
+func (r *Reader) verify() error {
+	for i, h := range r.hashes {
+		if sum := h.Sum(nil); !bytes.Equal(sum, r.checksums[i].Sum) {
+			return ErrMismatch.Format(r.checksums[i].Algorithm)
+		}
+	}
+
+	return io.EOF
+}
+
+// Err returns the `ErrMismatch` if the body is read and it does not match its checksum, otherwise nil.
+func (r *Reader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+
+	return r.err
+}
+
+// Verified reports whether the whole body is read and it matches its checksums.
+func (r *Reader) Verified() bool {
+	return r.err == io.EOF
+}
+
+// New returns a new checksum verification middleware based on the "c" configs,
+// the default configs are used if "c" is missing.
+//
+// Usage:
+// app.Post("/uploads", checksum.New(), upload)
+// app.Post("/webhooks", checksum.New(checksum.Config{Required: true}), verifySignature, handleWebhook)
+func New(c ...Config) context.Handler {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		config = c[0]
+		if config.MaxBufferSize == 0 {
+			config.MaxBufferSize = DefaultConfig().MaxBufferSize
+		}
+	}
+
+	if config.Checksums == nil {
+		config.Checksums = func(ctx context.Context) ([]Checksum, error) {
+			return Parse(ctx.Request().Header)
+		}
+	}
+
+	if config.OnMismatch == nil {
+		config.OnMismatch = func(ctx context.Context, err error) {
+			ctx.StatusCode(http.StatusBadRequest)
+		}
+	}
+
+	return func(ctx context.Context) {
+		checksums, err := config.Checksums(ctx)
+		if err == nil && len(checksums) == 0 && config.Required {
+			err = ErrMissing
+		}
+
+		if err != nil {
+			ctx.StopExecution()
+			config.OnMismatch(ctx, err)
+			return
+		}
+
+		if len(checksums) == 0 || ctx.Request().Body == nil {
+			ctx.Next()
+			return
+		}
+
+		r := ctx.Request()
+		body := NewReader(r.Body, checksums)
+
+		if n := r.ContentLength; n >= 0 && n <= config.MaxBufferSize {
+			// a small body is verified before the next handlers.
+			b, err := ioutil.ReadAll(body)
+			if err == nil && !body.Verified() {
+				err = io.ErrUnexpectedEOF
+			}
+
+			if err != nil {
+				ctx.StopExecution()
+				config.OnMismatch(ctx, err)
+				return
+			}
+
+			r.Body = ioutil.NopCloser(bytes.NewReader(b))
+			ctx.Next()
+			return
+		}
+
+		r.Body = body
+		ctx.Next()
+
+		if !body.Verified() && body.Err() == nil {
+			// the next handlers didn't read the whole body, verify the rest of it.
+			io.Copy(ioutil.Discard, body)
+		}
+
+		if err = body.Err(); err != nil {
+			if ctx.ResponseWriter().Written() != context.NoWritten {
+				// too late to reject it.
+				context.Logger(ctx).Warnf("%s: %v", ctx.Path(), err)
+				return
+			}
+
+			ctx.StopExecution()
+			config.OnMismatch(ctx, err)
+		}
+	}
+}
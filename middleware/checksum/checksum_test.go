@@ -0,0 +1,133 @@
+package checksum_test
+
+import (
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/middleware/checksum"
+)
+
+const testBody = `{"name":"iris"}`
+
+func digest(body string) string {
+	return checksum.Digest("sha-256", []byte(body))
+}
+
+func newTestApp(config checksum.Config) (*iris.Application, *int) {
+	app := iris.New()
+	var calls int
+
+	app.Post("/read", checksum.New(config), func(ctx context.Context) {
+		calls++
+		b, err := ioutil.ReadAll(ctx.Request().Body)
+		if err != nil {
+			ctx.StatusCode(iris.StatusBadRequest)
+			return
+		}
+		ctx.Write(b)
+	})
+	app.Post("/partial", checksum.New(config), func(ctx context.Context) {
+		calls++
+		b := make([]byte, 4)
+		ctx.Request().Body.Read(b)
+	})
+	app.Post("/ignore", checksum.New(config), func(ctx context.Context) {
+		calls++
+		ctx.WriteString("ok")
+	})
+
+	return app, &calls
+}
+
+func TestChecksumBuffered(t *testing.T) {
+	app, calls := newTestApp(checksum.Config{})
+	e := httptest.New(t, app)
+
+	e.POST("/read").WithHeader("Digest", digest(testBody)).WithBytes([]byte(testBody)).Expect().
+		Status(httptest.StatusOK).Body().Equal(testBody)
+	if *calls != 1 {
+		t.Fatalf("expected the handler to be called once but called %d times", *calls)
+	}
+
+	// the small bodies are verified before the handlers.
+	for _, path := range []string{"/read", "/partial", "/ignore"} {
+		e.POST(path).WithHeader("Digest", digest("other")).WithBytes([]byte(testBody)).Expect().
+			Status(httptest.StatusBadRequest)
+	}
+	if *calls != 1 {
+		t.Fatalf("expected the handlers to not be called on mismatch but called %d times", *calls)
+	}
+}
+
+func TestChecksumStreaming(t *testing.T) {
+	app, calls := newTestApp(checksum.Config{MaxBufferSize: -1})
+	e := httptest.New(t, app)
+
+	for _, path := range []string{"/read", "/partial"} {
+		e.POST(path).WithHeader("Digest", digest(testBody)).WithBytes([]byte(testBody)).Expect().
+			Status(httptest.StatusOK)
+		// the rest of the partially read body is verified after the handler.
+		e.POST(path).WithHeader("Digest", digest("other")).WithBytes([]byte(testBody)).Expect().
+			Status(httptest.StatusBadRequest)
+	}
+
+	// the response is sent already, the mismatch is logged.
+	e.POST("/ignore").WithHeader("Digest", digest("other")).WithBytes([]byte(testBody)).Expect().
+		Status(httptest.StatusOK).Body().Equal("ok")
+
+	if *calls != 5 {
+		t.Fatalf("expected the handlers to be called 5 times but called %d times", *calls)
+	}
+}
+
+func TestChecksumHeaders(t *testing.T) {
+	app, _ := newTestApp(checksum.Config{Required: true})
+	e := httptest.New(t, app)
+
+	// Content-MD5 of the testBody.
+	e.POST("/read").WithHeader("Content-MD5", "gRAZtd2gq3HsQOzRbcv0sw==").WithBytes([]byte(testBody)).Expect().
+		Status(httptest.StatusOK).Body().Equal(testBody)
+	e.POST("/read").WithHeader("Content-MD5", "invalid").WithBytes([]byte(testBody)).Expect().
+		Status(httptest.StatusBadRequest)
+	// required.
+	e.POST("/read").WithBytes([]byte(testBody)).Expect().Status(httptest.StatusBadRequest)
+	// unsupported algorithms are ignored, the supported ones are verified.
+	e.POST("/read").WithHeader("Digest", "unixsum=30637, "+digest(testBody)).WithBytes([]byte(testBody)).Expect().
+		Status(httptest.StatusOK)
+}
+
+func TestChecksumSignedURL(t *testing.T) {
+	app := iris.New()
+	app.Configure(iris.WithURLSigningKey("secret"))
+	app.Put("/uploads", app.RequireSignedURL(), checksum.New(checksum.Config{
+		Required:  true,
+		Checksums: checksum.URLParam("digest"),
+	}), func(ctx context.Context) {
+		b, _ := ioutil.ReadAll(ctx.Request().Body)
+		ctx.Write(b)
+	}).Name = "upload"
+
+	link, err := app.SignURL("upload", nil, time.Hour, url.Values{"digest": {digest(testBody)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(link)
+
+	e := httptest.New(t, app)
+	e.PUT(u.Path).WithQueryString(u.RawQuery).WithBytes([]byte(testBody)).Expect().
+		Status(httptest.StatusOK).Body().Equal(testBody)
+	// a different body than the signed one.
+	e.PUT(u.Path).WithQueryString(u.RawQuery).WithBytes([]byte(strings.ToUpper(testBody))).Expect().
+		Status(httptest.StatusBadRequest)
+	// a different digest than the signed one.
+	query := u.Query()
+	query.Set("digest", digest("other"))
+	e.PUT(u.Path).WithQueryString(query.Encode()).WithBytes([]byte("other")).Expect().
+		Status(httptest.StatusForbidden)
+}
@@ -0,0 +1,37 @@
+package checksum
+
+import (
+	"github.com/kataras/iris/context"
+)
+
+// Config the configs for the checksum verification middleware.
+type Config struct {
+	// Required rejects the requests without a supported `Content-MD5` or `Digest` header.
+	//
+	// Defaults to false, the requests without a checksum are not verified.
+	Required bool
+	// Checksums returns the expected checksums of the request body,
+	// i.e the `URLParam` reads them from a claim of a signed url.
+	//
+	// Defaults to the `Parse` of the request headers.
+	Checksums func(ctx context.Context) ([]Checksum, error)
+	// MaxBufferSize is the max size of a body, by its Content-Length, which is read and verified before the next handlers,
+	// the larger ones and the ones without a Content-Length are verified while they're read by them.
+	// A negative value disables it.
+	//
+	// Defaults to 1MB.
+	MaxBufferSize int64
+	// OnMismatch is called when the request is rejected with one of the `ErrMissing`, `ErrInvalidHeader`
+	// or `ErrMismatch` errors, the `ErrMismatch` of a large body is known after it's read by the next handlers,
+	// so it's called after them, if the response is not written yet, otherwise the mismatch is logged.
+	//
+	// Defaults to a handler which sends 400 Bad Request.
+	OnMismatch func(ctx context.Context, err error)
+}
+
+// DefaultConfig returns the default configs for the checksum verification middleware.
+func DefaultConfig() Config {
+	return Config{
+		MaxBufferSize: 1 << 20,
+	}
+}
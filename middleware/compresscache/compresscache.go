@@ -0,0 +1,282 @@
+// Package compresscache provides a middleware which compresses the dynamic responses once
+// and caches the compressed bodies on disk, keyed by their request path and the hash of their body,
+// so the repeated requests of the same content skip the recompression.
+package compresscache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// Cache is the disk cache of the compressed responses, see `New`.
+type Cache struct {
+	config    Config
+	offers    []string
+	encodings map[string]Encoding
+
+	mu       sync.Mutex
+	inflight map[string]*call
+}
+
+// call is an in-flight compression, the concurrent requests of the same entry wait for it.
+type call struct {
+	wg   sync.WaitGroup
+	body []byte
+	err  error
+}
+
+// New returns a new compression cache of the "c" configs and creates its directory.
+//
+// Its `Handler` records the response of the next handlers, tags it with the ETag, the handler's one
+// or the hash of the body, and sends the compressed body of the client's preferred encoding,
+// which is read from the disk or compressed and stored once.
+// The disk entries are keyed by the request path and the hash of the body, not by the ETag,
+// a handler's ETag may be shared by different bodies, i.e a version number or a weak validator of another route.
+// The compressed representation has its own ETag, the original one suffixed by the encoding,
+// and a matched "If-None-Match" is answered with a 304 "Not Modified" without touching the disk.
+//
+// Register it before the `cache.Handler`, so the cache keeps the uncompressed bodies
+// and the compression of its cached responses is served from the disk.
+// Do not combine it with the `ctx.Gzip(true)`, the already encoded responses are sent as they are.
+//
+// Usage:
+// cc, err := compresscache.New(compresscache.Config{Dir: "./.compressed"})
+// app.Get("/report", cc.Handler, report)
+// 动态响应压缩一次后以请求路径和响应体的哈希为键缓存到磁盘, 后续相同内容的请求直接读取而不再重复压缩
+func New(c ...Config) (*Cache, error) {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		config = c[0]
+		def := DefaultConfig()
+		if config.Dir == "" {
+			config.Dir = def.Dir
+		}
+		if config.MinSize <= 0 {
+			config.MinSize = def.MinSize
+		}
+		if len(config.Encodings) == 0 {
+			config.Encodings = def.Encodings
+		}
+		if len(config.ContentTypes) == 0 {
+			config.ContentTypes = def.ContentTypes
+		}
+	}
+
+	if err := os.MkdirAll(config.Dir, os.FileMode(0755)); err != nil {
+		return nil, err
+	}
+
+	cc := &Cache{
+		config:    config,
+		encodings: make(map[string]Encoding, len(config.Encodings)),
+		inflight:  make(map[string]*call),
+	}
+
+	for _, enc := range config.Encodings {
+		cc.offers = append(cc.offers, enc.Name)
+		cc.encodings[enc.Name] = enc
+	}
+
+	return cc, nil
+}
+
+// Handler is the middleware which compresses the successful (200) GET and HEAD responses
+// of a compressible content type and at least the `Config#MinSize`.
+func (cc *Cache) Handler(ctx context.Context) {
+	if method := ctx.Method(); method != http.MethodGet && method != http.MethodHead {
+		ctx.Next()
+		return
+	}
+
+	encoding := ctx.NegotiateEncoding(cc.offers...)
+	if encoding == "" {
+		ctx.Next()
+		return
+	}
+
+	ctx.Record()
+	ctx.Next()
+
+	w, ok := ctx.IsRecording()
+	if !ok || w.IsPassThrough() || w.StatusCode() != http.StatusOK || len(w.Body()) < cc.config.MinSize {
+		return
+	}
+
+	h := w.Header()
+	if h.Get(context.ContentEncodingHeaderKey) != "" || !cc.compressible(h.Get(context.ContentTypeHeaderKey)) {
+		return
+	}
+
+	body := w.Body()
+	etag := h.Get(context.ETagHeaderKey)
+	if etag == "" || strings.HasPrefix(etag, "W/") {
+		// the weak ones do not guarantee byte-identical bodies, they can't be the key.
+		etag = context.ComputeETag(body, false)
+	}
+
+	encodedETag := strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+	h.Add(context.VaryHeaderKey, context.AcceptEncodingHeaderKey)
+	h.Set(context.ETagHeaderKey, encodedETag)
+
+	if ctx.CheckIfNoneMatch(encodedETag) {
+		w.ResetBody()
+		ctx.WriteNotModified()
+		return
+	}
+
+	compressed, err := cc.load(cc.Filename(ctx.Path(), body, encoding), encoding, body)
+	if err != nil {
+		h.Set(context.ETagHeaderKey, etag)
+		context.Logger(ctx).Errorf("compresscache: %v", err)
+		return
+	}
+
+	h.Set(context.ContentEncodingHeaderKey, encoding)
+	h.Del(context.ContentLengthHeaderKey)
+	w.SetBody(compressed)
+}
+
+func (cc *Cache) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, t := range cc.config.ContentTypes {
+		t = strings.ToLower(t)
+		if mediaType == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Filename returns the cache file of the "body" of the request "path" and the "encoding",
+// it does not report whether the file exists.
+func (cc *Cache) Filename(path string, body []byte, encoding string) string {
+	h := sha256.New()
+	h.Write([]byte(path))
+	h.Write([]byte{0}) // the path can't contain a zero byte, so the path and the body can't be shifted.
+	h.Write(body)
+	return filepath.Join(cc.config.Dir, hex.EncodeToString(h.Sum(nil))+"."+encoding)
+}
+
+// load returns the compressed "body" of the "filename" from the disk,
+// or compresses and stores it, once for the concurrent requests of the same entry.
+func (cc *Cache) load(filename, encoding string, body []byte) ([]byte, error) {
+	if b, err := os.ReadFile(filename); err == nil {
+		return b, nil
+	}
+
+	cc.mu.Lock()
+	if c, ok := cc.inflight[filename]; ok {
+		cc.mu.Unlock()
+		c.wg.Wait()
+		return c.body, c.err
+	}
+
+	c := new(call)
+	c.wg.Add(1)
+	cc.inflight[filename] = c
+	cc.mu.Unlock()
+
+	c.body, c.err = cc.compress(cc.encodings[encoding], body)
+	if c.err == nil {
+		c.err = cc.store(filename, c.body)
+	}
+
+	cc.mu.Lock()
+	delete(cc.inflight, filename)
+	cc.mu.Unlock()
+	c.wg.Done()
+
+	return c.body, c.err
+}
+
+func (cc *Cache) compress(enc Encoding, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := enc.New(&buf, enc.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = zw.Write(body); err != nil {
+		zw.Close()
+		return nil, err
+	}
+
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// store writes the "b" to a temporary file and renames it to the "filename",
+// so the readers never see a partial entry.
+func (cc *Cache) store(filename string, b []byte) error {
+	f, err := os.CreateTemp(cc.config.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(b)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+
+	return err
+}
+
+// Prune removes the cache files which are stored before the "maxAge",
+// they are compressed and stored again on their next request.
+// It returns the number of the removed files.
+func (cc *Cache) Prune(maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(cc.config.Dir)
+	if err != nil {
+		return 0, err
+	}
+
+	deadline := time.Now().Add(-maxAge)
+	n := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(deadline) {
+			continue
+		}
+
+		if err = os.Remove(filepath.Join(cc.config.Dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return n, err
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+// Clear removes all the cache files.
+func (cc *Cache) Clear() error {
+	_, err := cc.Prune(0)
+	return err
+}
@@ -0,0 +1,131 @@
+package compresscache_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/middleware/compresscache"
+)
+
+func gunzip(t *testing.T, b []byte) string {
+	t.Helper()
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func newTestApp(t *testing.T) (*iris.Application, *compresscache.Cache, string) {
+	dir, err := os.MkdirTemp("", "compresscache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	cc, err := compresscache.New(compresscache.Config{Dir: dir, MinSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return iris.New(), cc, dir
+}
+
+func TestCompressCache(t *testing.T) {
+	app, cc, dir := newTestApp(t)
+	body := strings.Repeat("compressible response;", 20)
+	app.Get("/", cc.Handler, func(ctx iris.Context) {
+		ctx.ContentType("text/plain")
+		ctx.WriteString(body)
+	})
+	app.Get("/small", cc.Handler, func(ctx iris.Context) {
+		ctx.ContentType("text/plain")
+		ctx.WriteString("small")
+	})
+
+	e := httptest.New(t, app)
+	for i := 0; i < 2; i++ {
+		r := e.GET("/").WithHeader("Accept-Encoding", "gzip").Expect().Status(httptest.StatusOK)
+		r.Header("Content-Encoding").Equal("gzip")
+		if got := gunzip(t, []byte(r.Body().Raw())); got != body {
+			t.Fatalf("[%d] expected the decompressed body to be the original one but got '%s'", i, got)
+		}
+
+		if _, err := os.Stat(cc.Filename("/", []byte(body), "gzip")); err != nil {
+			t.Fatalf("[%d] expected the compressed body to be stored: %v", i, err)
+		}
+	}
+
+	etag := e.GET("/").WithHeader("Accept-Encoding", "gzip").Expect().Header("ETag").Raw()
+	e.GET("/").WithHeader("Accept-Encoding", "gzip").WithHeader("If-None-Match", etag).Expect().
+		Status(httptest.StatusNotModified)
+
+	e.GET("/").Expect().Status(httptest.StatusOK).Body().Equal(body)
+	e.GET("/small").WithHeader("Accept-Encoding", "gzip").Expect().Status(httptest.StatusOK).Body().Equal("small")
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one cache file but got %d", len(entries))
+	}
+}
+
+func TestCompressCacheSameETag(t *testing.T) {
+	app, cc, _ := newTestApp(t)
+	// the routes share the ETag but not the body,
+	// they should not be served each other's compressed body.
+	handler := func(body string) iris.Handler {
+		return func(ctx iris.Context) {
+			ctx.Header("ETag", `"v1"`)
+			ctx.ContentType("text/plain")
+			ctx.WriteString(body)
+		}
+	}
+	bodyA, bodyB := strings.Repeat("a", 100), strings.Repeat("b", 100)
+	app.Get("/a", cc.Handler, handler(bodyA))
+	app.Get("/b", cc.Handler, handler(bodyB))
+
+	e := httptest.New(t, app)
+	for _, tt := range []struct{ path, body string }{{"/a", bodyA}, {"/b", bodyB}, {"/a", bodyA}} {
+		r := e.GET(tt.path).WithHeader("Accept-Encoding", "gzip").Expect().Status(httptest.StatusOK)
+		r.Header("Content-Encoding").Equal("gzip")
+		if got := gunzip(t, []byte(r.Body().Raw())); got != tt.body {
+			t.Fatalf("%s: expected the body '%s' but got '%s'", tt.path, tt.body, got)
+		}
+	}
+}
+
+func TestCompressCachePassThrough(t *testing.T) {
+	app, cc, dir := newTestApp(t)
+	body := strings.Repeat("compressible response;", 20)
+	app.Get("/", context.RecordWithLimit(32, context.RecorderPassThrough), cc.Handler, func(ctx iris.Context) {
+		ctx.ContentType("text/plain")
+		ctx.WriteString(body)
+	})
+
+	e := httptest.New(t, app)
+	r := e.GET("/").WithHeader("Accept-Encoding", "gzip").Expect().Status(httptest.StatusOK)
+	r.Header("Content-Encoding").Empty()
+	r.Body().Equal(body)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected the sent response to not be stored but got %d files", len(entries))
+	}
+}
@@ -0,0 +1,75 @@
+package compresscache
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/gzip"
+)
+
+// Encoder returns a writer which compresses to "w" at the "level".
+type Encoder func(w io.Writer, level int) (io.WriteCloser, error)
+
+// Encoding is a content coding of the compressed responses, see `Config#Encodings`.
+type Encoding struct {
+	// Name is the "Content-Encoding" value, i.e "gzip" or "br".
+	Name string
+	// Level is the compression level passed to the `New`,
+	// the responses are compressed once so the best compression is preferred.
+	Level int
+	// New creates the compression writer.
+	New Encoder
+}
+
+// GzipEncoder is the `Encoder` of the "gzip" content coding.
+func GzipEncoder(w io.Writer, level int) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, level)
+}
+
+// Gzip is the "gzip" `Encoding` with the best compression level.
+var Gzip = Encoding{Name: "gzip", Level: gzip.BestCompression, New: GzipEncoder}
+
+// Config the configs for the compression cache middleware.
+type Config struct {
+	// Dir is the directory which the compressed responses are stored to,
+	// it's created if it does not exist.
+	//
+	// Defaults to "iris-compresscache" under the os.TempDir().
+	Dir string
+	// MinSize is the minimum size, in bytes, of the response bodies which are compressed,
+	// the smaller ones are sent as they are.
+	//
+	// Defaults to 1024.
+	MinSize int
+	// Encodings are the supported content codings, in the server's order of preference,
+	// the one which the client prefers most, through its "Accept-Encoding", is used.
+	// There is no brotli implementation in the dependencies of the iris,
+	// a third-party one can be registered as: Encoding{Name: "br", Level: 11, New: func(w io.Writer, level int) (io.WriteCloser, error) {
+	// return brotli.NewWriterLevel(w, level), nil }}.
+	//
+	// Defaults to the `Gzip`.
+	Encodings []Encoding
+	// ContentTypes are the compressible content types, a value which ends with "/"
+	// matches all the subtypes, i.e "text/".
+	//
+	// Defaults to "text/", "application/json", "application/javascript",
+	// "application/xml" and "image/svg+xml".
+	ContentTypes []string
+}
+
+// DefaultConfig returns the default configs for the compression cache middleware.
+func DefaultConfig() Config {
+	return Config{
+		Dir:       filepath.Join(os.TempDir(), "iris-compresscache"),
+		MinSize:   1024,
+		Encodings: []Encoding{Gzip},
+		ContentTypes: []string{
+			"text/",
+			"application/json",
+			"application/javascript",
+			"application/xml",
+			"image/svg+xml",
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package csrf
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// Mode is the storage of the CSRF token, see `Config#Mode`.
+type Mode uint8
+
+const (
+	// DoubleSubmit keeps the token in a cookie, the unsafe requests should send it back
+	// through the `Config#Header` or the `Config#FormField`, the server is stateless.
+	DoubleSubmit Mode = iota
+	// Synchronizer keeps the token in the request's session, a sessions middleware
+	// should be registered before the CSRF one, see `sessions.Get`.
+	Synchronizer
+)
+
+// Config the configs for the CSRF protection middleware.
+type Config struct {
+	// Mode is the storage of the token, `DoubleSubmit` or `Synchronizer`.
+	//
+	// Defaults to `DoubleSubmit`.
+	Mode Mode
+	// CookieName is the name of the cookie of the `DoubleSubmit` mode.
+	//
+	// Defaults to "_csrf".
+	CookieName string
+	// CookiePath is the path of the cookie.
+	//
+	// Defaults to "/".
+	CookiePath string
+	// CookieExpires is the lifetime of the cookie, the token is the same until it's expired.
+	//
+	// Defaults to 12 hours.
+	CookieExpires time.Duration
+	// CookieSecure sets the "Secure" attribute of the cookie, it should be true on TLS servers.
+	//
+	// Defaults to false, the `Configuration#CookieSecure` is used.
+	CookieSecure bool
+	// CookieSameSite is the "SameSite" attribute of the cookie.
+	//
+	// Defaults to the `Configuration#CookieSameSite` or to `http.SameSiteLaxMode` if that's not set.
+	CookieSameSite http.SameSite
+	// CookieEncoder and CookieDecoder sign or encrypt the cookie's value,
+	// i.e the `Encode` and the `Decode` of the github.com/gorilla/securecookie, see `Context#SetCookie`.
+	//
+	// Defaults to nil, the token is stored as it's.
+	CookieEncoder context.CookieEncoder
+	CookieDecoder context.CookieDecoder
+	// SessionKey is the session's key of the token of the `Synchronizer` mode.
+	//
+	// Defaults to "_csrf".
+	SessionKey string
+	// Header is the request header of the token.
+	//
+	// Defaults to "X-CSRF-Token".
+	Header string
+	// FormField is the form field of the token, it's used when the header is missing.
+	//
+	// Defaults to "csrf_token".
+	FormField string
+	// TokenLength is the number of the random bytes of the token.
+	//
+	// Defaults to 32.
+	TokenLength int
+	// ExemptRoutes are the names of the routes which are not protected, i.e the webhooks,
+	// see `Route#Name`.
+	ExemptRoutes []string
+	// Exempt reports whether the request is not protected, in addition to the `ExemptRoutes`.
+	//
+	// Defaults to nil.
+	Exempt func(ctx context.Context) bool
+	// OnFailure is called when an unsafe request has a missing or an invalid token,
+	// the next handlers are not executed.
+	//
+	// Defaults to a handler which sends 403 Forbidden.
+	OnFailure func(ctx context.Context, err error)
+}
+
+// DefaultConfig returns the default configs for the CSRF protection middleware.
+func DefaultConfig() Config {
+	return Config{
+		Mode:          DoubleSubmit,
+		CookieName:    "_csrf",
+		CookiePath:    "/",
+		CookieExpires: 12 * time.Hour,
+		SessionKey:    "_csrf",
+		Header:        "X-CSRF-Token",
+		FormField:     "csrf_token",
+		TokenLength:   32,
+	}
+}
@@ -0,0 +1,250 @@
+// Package csrf provides a Cross-Site Request Forgery protection middleware.
+//
+// It issues a token per client, kept in a cookie (double-submit) or in the session (synchronizer token),
+// and it validates the token of the unsafe requests (POST, PUT, PATCH, DELETE...),
+// which should be sent back through the "X-CSRF-Token" request header or the "csrf_token" form field.
+//
+// The token of the request is available through the `csrf.Token(ctx)` and the views
+// receive it as "csrfToken", i.e `<input type="hidden" name="csrf_token" value="{{ .csrfToken }}">`.
+// It's masked with a random pad on each request, so it's safe to be rendered in compressed responses (BREACH).
+package csrf
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+	"github.com/kataras/iris/sessions"
+)
+
+var (
+	// ErrTokenMissing is passed to the `Config#OnFailure` when the unsafe request has no token.
+	ErrTokenMissing = errors.New("csrf: token is missing")
+	// ErrTokenInvalid is passed to the `Config#OnFailure` when the token of the unsafe request does not match.
+	ErrTokenInvalid = errors.New("csrf: token is invalid")
+	// ErrNoSession is passed to the `Config#OnFailure` when the `Synchronizer` mode is used
+	// but no sessions middleware is registered.
+	ErrNoSession = errors.New("csrf: the synchronizer mode requires a sessions middleware")
+)
+
+// isSafe reports whether the "method" has no side effects, RFC 7231 section 4.2.1.
+func isSafe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+
+	return false
+}
+
+// New returns a new CSRF protection middleware based on the "c" configs,
+// the default configs are used if "c" is missing.
+//
+// Usage:
+// app.Use(csrf.New())
+// app.Get("/profile", func(ctx iris.Context) { ctx.View("profile.html") })
+// and inside the form: <input type="hidden" name="csrf_token" value="{{ .csrfToken }}">
+// app.Post("/profile", updateProfile)
+func New(c ...Config) context.Handler {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		config = c[0]
+		def := DefaultConfig()
+		if config.CookieName == "" {
+			config.CookieName = def.CookieName
+		}
+		if config.CookiePath == "" {
+			config.CookiePath = def.CookiePath
+		}
+		if config.CookieExpires <= 0 {
+			config.CookieExpires = def.CookieExpires
+		}
+		if config.SessionKey == "" {
+			config.SessionKey = def.SessionKey
+		}
+		if config.Header == "" {
+			config.Header = def.Header
+		}
+		if config.FormField == "" {
+			config.FormField = def.FormField
+		}
+		if config.TokenLength <= 0 {
+			config.TokenLength = def.TokenLength
+		}
+	}
+
+	if config.OnFailure == nil {
+		config.OnFailure = func(ctx context.Context, err error) {
+			ctx.StatusCode(http.StatusForbidden)
+		}
+	}
+
+	exempt := make(map[string]struct{}, len(config.ExemptRoutes))
+	for _, name := range config.ExemptRoutes {
+		exempt[name] = struct{}{}
+	}
+
+	s := &store{config: config}
+
+	return func(ctx context.Context) {
+		if config.Mode == Synchronizer && sessions.Get(ctx) == nil {
+			ctx.StopExecution()
+			config.OnFailure(ctx, ErrNoSession)
+			return
+		}
+
+		token, issued := s.get(ctx), false
+		if len(token) != config.TokenLength {
+			token, issued = generate(config.TokenLength), true
+			s.set(ctx, token)
+		}
+
+		ctx.Values().Set(context.CSRFTokenContextKey, mask(token))
+		// the token differs per client.
+		ctx.ResponseWriter().Header().Add("Vary", "Cookie")
+
+		if !isSafe(ctx.Method()) && !isExempt(ctx, exempt, config.Exempt) {
+			if err := verify(ctx, config, token, issued); err != nil {
+				ctx.StopExecution()
+				config.OnFailure(ctx, err)
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
+
+// Token returns the masked CSRF token of the current request, as issued by the middleware,
+// or empty if it's not registered, see `context.CSRFToken` too.
+// It should be sent back by the unsafe requests, i.e as a hidden form field or a request header.
+func Token(ctx context.Context) string {
+	return context.CSRFToken(ctx)
+}
+
+func isExempt(ctx context.Context, routes map[string]struct{}, fn func(context.Context) bool) bool {
+	if route := ctx.GetCurrentRoute(); route != nil {
+		if _, ok := routes[route.Name()]; ok {
+			return true
+		}
+	}
+
+	return fn != nil && fn(ctx)
+}
+
+func verify(ctx context.Context, config Config, token []byte, issued bool) error {
+	sent := ctx.GetHeader(config.Header)
+	if sent == "" {
+		sent = ctx.FormValue(config.FormField)
+	}
+
+	if sent == "" {
+		return ErrTokenMissing
+	}
+
+	// a new token can't be known by the client.
+	if issued {
+		return ErrTokenInvalid
+	}
+
+	if unmasked := unmask(sent); unmasked == nil || subtle.ConstantTimeCompare(unmasked, token) != 1 {
+		return ErrTokenInvalid
+	}
+
+	return nil
+}
+
+// store reads and writes the raw token of the client.
+type store struct {
+	config Config
+}
+
+func (s *store) get(ctx context.Context) []byte {
+	var encoded string
+	if s.config.Mode == Synchronizer {
+		encoded = sessions.Get(ctx).GetString(s.config.SessionKey)
+	} else {
+		var options []context.CookieOption
+		if s.config.CookieDecoder != nil {
+			options = append(options, context.CookieDecode(s.config.CookieDecoder))
+		}
+		encoded = ctx.GetCookie(s.config.CookieName, options...)
+	}
+
+	token, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil
+	}
+
+	return token
+}
+
+func (s *store) set(ctx context.Context, token []byte) {
+	encoded := base64.RawURLEncoding.EncodeToString(token)
+	if s.config.Mode == Synchronizer {
+		sessions.Get(ctx).Set(s.config.SessionKey, encoded)
+		return
+	}
+
+	options := []context.CookieOption{
+		context.CookiePath(s.config.CookiePath),
+		context.CookieExpires(s.config.CookieExpires),
+		// the application's cookie defaults are kept, unless they're set here.
+		func(c *http.Cookie) {
+			if s.config.CookieSecure {
+				c.Secure = true
+			}
+
+			if s.config.CookieSameSite != 0 {
+				c.SameSite = s.config.CookieSameSite
+			} else if c.SameSite == 0 {
+				c.SameSite = http.SameSiteLaxMode
+			}
+		},
+	}
+	if s.config.CookieEncoder != nil {
+		options = append(options, context.CookieEncode(s.config.CookieEncoder))
+	}
+
+	ctx.SetCookieKV(s.config.CookieName, encoded, options...)
+}
+
+func generate(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("csrf: unable to read random bytes: " + err.Error())
+	}
+	return b
+}
+
+// mask returns the base64 of a random pad followed by the pad XOR the "token",
+// so the token differs on each response.
+func mask(token []byte) string {
+	pad := generate(len(token))
+	masked := make([]byte, 2*len(token))
+	copy(masked, pad)
+	for i := range token {
+		masked[len(token)+i] = pad[i] ^ token[i]
+	}
+
+	return base64.RawURLEncoding.EncodeToString(masked)
+}
+
+// unmask returns the token of a `mask`ed one, nil if it's invalid.
+func unmask(s string) []byte {
+	masked, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(masked) == 0 || len(masked)%2 != 0 {
+		return nil
+	}
+
+	n := len(masked) / 2
+	token := make([]byte, n)
+	for i := range token {
+		token[i] = masked[i] ^ masked[n+i]
+	}
+
+	return token
+}
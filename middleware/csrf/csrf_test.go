@@ -0,0 +1,171 @@
+package csrf_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/middleware/csrf"
+	"github.com/kataras/iris/sessions"
+)
+
+func TestCookieDefaults(t *testing.T) {
+	tests := []struct {
+		configurators []iris.Configurator
+		config        []csrf.Config
+		secure        bool
+		sameSite      http.SameSite
+	}{
+		{nil, nil, false, http.SameSiteLaxMode},
+		// the application's defaults are kept.
+		{[]iris.Configurator{iris.WithCookieSecure, iris.WithCookieSameSite(http.SameSiteStrictMode)}, nil, true, http.SameSiteStrictMode},
+		{[]iris.Configurator{iris.WithCookieSecure, iris.WithCookieSameSite(http.SameSiteStrictMode)}, []csrf.Config{{}}, true, http.SameSiteStrictMode},
+		// unless they're set explicitly.
+		{[]iris.Configurator{iris.WithCookieSameSite(http.SameSiteStrictMode)}, []csrf.Config{{CookieSecure: true, CookieSameSite: http.SameSiteNoneMode}}, true, http.SameSiteNoneMode},
+		{nil, []csrf.Config{{CookieSameSite: http.SameSiteStrictMode}}, false, http.SameSiteStrictMode},
+	}
+
+	for i, tt := range tests {
+		app := iris.New().Configure(tt.configurators...)
+		app.Use(csrf.New(tt.config...))
+		app.Get("/", func(ctx iris.Context) {})
+
+		c := httptest.New(t, app).GET("/").Expect().Status(httptest.StatusOK).
+			Cookie(csrf.DefaultConfig().CookieName).Raw()
+		if c.Secure != tt.secure {
+			t.Fatalf("[%d] expected the cookie's secure to be %v but got %v", i, tt.secure, c.Secure)
+		}
+		if c.SameSite != tt.sameSite {
+			t.Fatalf("[%d] expected the cookie's same site to be %v but got %v", i, tt.sameSite, c.SameSite)
+		}
+		if !c.HttpOnly || c.Path != "/" {
+			t.Fatalf("[%d] expected an http only cookie of the root path but got %#v", i, c)
+		}
+	}
+}
+
+// newApp returns an application which sends the token of the "/token" and protects the "/submit"
+// and the exempt "/webhook" route, the "failure" is the last error of the `Config#OnFailure`.
+func newApp(config csrf.Config, failure *error, middleware ...iris.Handler) *iris.Application {
+	config.OnFailure = func(ctx iris.Context, err error) {
+		*failure = err
+		ctx.StatusCode(iris.StatusForbidden)
+	}
+	config.ExemptRoutes = []string{"webhook"}
+
+	app := iris.New()
+	app.Use(middleware...)
+	app.Use(csrf.New(config))
+	app.Get("/token", func(ctx iris.Context) {
+		ctx.WriteString(csrf.Token(ctx))
+	})
+	app.Post("/submit", func(ctx iris.Context) {
+		ctx.WriteString("submitted")
+	})
+	app.Post("/webhook", func(ctx iris.Context) {
+		ctx.WriteString("hooked")
+	}).Name = "webhook"
+
+	return app
+}
+
+func testVerification(t *testing.T, config csrf.Config, middleware ...iris.Handler) {
+	var failure error
+	app := newApp(config, &failure, middleware...)
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+
+	// a new client, the token of the request is just issued.
+	e.POST("/submit").WithHeader("X-CSRF-Token", "token").Expect().Status(httptest.StatusForbidden)
+	if !csrf.ErrTokenInvalid.Equal(failure) {
+		t.Fatalf("expected the invalid token error but got %v", failure)
+	}
+
+	r := e.GET("/token").Expect().Status(httptest.StatusOK)
+	r.Header("Vary").Contains("Cookie")
+	token := r.Body().NotEmpty().Raw()
+	// the token is masked on each request.
+	other := e.GET("/token").Expect().Status(httptest.StatusOK).Body().NotEqual(token).Raw()
+
+	failure = nil
+	e.POST("/submit").Expect().Status(httptest.StatusForbidden)
+	if !csrf.ErrTokenMissing.Equal(failure) {
+		t.Fatalf("expected the missing token error but got %v", failure)
+	}
+
+	// tampered, the first character is a part of the pad's first byte.
+	tampered := []byte(token)
+	if tampered[0] == 'A' {
+		tampered[0] = 'B'
+	} else {
+		tampered[0] = 'A'
+	}
+	for _, invalid := range []string{string(tampered), token[:len(token)/2], "not base64!", token + other} {
+		failure = nil
+		e.POST("/submit").WithHeader("X-CSRF-Token", invalid).Expect().Status(httptest.StatusForbidden)
+		if !csrf.ErrTokenInvalid.Equal(failure) {
+			t.Fatalf("%q: expected the invalid token error but got %v", invalid, failure)
+		}
+	}
+
+	// both of the masked tokens, through the header and the form field.
+	e.POST("/submit").WithHeader("X-CSRF-Token", token).Expect().Status(httptest.StatusOK).Body().Equal("submitted")
+	e.POST("/submit").WithFormField("csrf_token", other).Expect().Status(httptest.StatusOK).Body().Equal("submitted")
+
+	// the exempt routes.
+	e.POST("/webhook").Expect().Status(httptest.StatusOK).Body().Equal("hooked")
+}
+
+func TestDoubleSubmit(t *testing.T) {
+	testVerification(t, csrf.Config{})
+}
+
+func TestDoubleSubmitAnotherClient(t *testing.T) {
+	var failure error
+	app := newApp(csrf.Config{}, &failure)
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	token := e.GET("/token").Expect().Status(httptest.StatusOK).Body().Raw()
+
+	// the token of a client is not valid for another one.
+	e = httptest.New(t, app, httptest.URL("http://example.com"))
+	e.GET("/token").Expect().Status(httptest.StatusOK)
+	e.POST("/submit").WithHeader("X-CSRF-Token", token).Expect().Status(httptest.StatusForbidden)
+	if !csrf.ErrTokenInvalid.Equal(failure) {
+		t.Fatalf("expected the invalid token error but got %v", failure)
+	}
+}
+
+func TestSynchronizer(t *testing.T) {
+	sess := sessions.New(sessions.Config{Cookie: "sessionid"})
+	testVerification(t, csrf.Config{Mode: csrf.Synchronizer}, sess.Handler())
+
+	// the token is kept in the session, not in a cookie.
+	var failure error
+	app := newApp(csrf.Config{Mode: csrf.Synchronizer}, &failure, sess.Handler())
+	httptest.New(t, app, httptest.URL("http://example.com")).GET("/token").Expect().Status(httptest.StatusOK).
+		Cookies().Equal([]string{"sessionid"})
+
+	// without a sessions middleware.
+	failure = nil
+	app = newApp(csrf.Config{Mode: csrf.Synchronizer}, &failure)
+	httptest.New(t, app, httptest.URL("http://example.com")).GET("/token").Expect().Status(httptest.StatusForbidden)
+	if !csrf.ErrNoSession.Equal(failure) {
+		t.Fatalf("expected the no session error but got %v", failure)
+	}
+}
+
+func TestExempt(t *testing.T) {
+	var failure error
+	app := newApp(csrf.Config{Exempt: func(ctx context.Context) bool {
+		return strings.HasPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	}}, &failure)
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+
+	// the token-authenticated clients do not use cookies.
+	e.POST("/submit").WithHeader("Authorization", "Bearer token").Expect().Status(httptest.StatusOK)
+	e.POST("/submit").Expect().Status(httptest.StatusForbidden)
+	// the safe methods are never verified.
+	e.GET("/token").Expect().Status(httptest.StatusOK)
+}
@@ -0,0 +1,176 @@
+// Package jsonschema provides a middleware which validates the JSON request bodies
+// against a JSON Schema registered per route, the schema can be loaded from a file or embedded.
+//
+// The invalid requests are rejected before the route's main handler with a
+// 422 (Unprocessable Entity) problem response, see `context#Problem`, which lists the violations:
+//
+//	{
+//	  "status": 422,
+//	  "title": "Unprocessable Entity",
+//	  "detail": "the request body does not match the schema",
+//	  "violations": [{"path": "/email", "keyword": "format", "message": "must be a valid email"}]
+//	}
+//
+// The malformed JSON bodies are rejected with a 400 (Bad Request) problem
+// and the bodies larger than the `MaxBodySize` with a 413 (Request Entity Too Large) one.
+// The body is restored after the validation, so the main handler can still read it through the `ReadJSON`.
+//
+// The registered schemas are collected by their routes, see `Validator#Schemas`,
+// and they are the request bodies of the routes' OpenAPI document, see `Validator#OpenAPI`.
+package jsonschema
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
+)
+
+// RouteSchema is the schema of a route's request body, see `Validator#Schemas`.
+type RouteSchema struct {
+	Method string
+	// Path is the route's registered path, i.e "/users/{id:uint64}".
+	Path string
+	// RouteName is the route's unique name.
+	RouteName string
+	Schema    *Schema
+	// Description and Deprecation are the route's documentation,
+	// see `router#Route.SetDescription` and `router#Route.Deprecated`.
+	Description string
+	Deprecation *context.RouteDeprecation
+}
+
+// Validator validates the request bodies of the routes against their schemas
+// and keeps the registered schemas by route.
+type Validator struct {
+	mu      sync.RWMutex
+	schemas []RouteSchema
+	// routes are the routes of the schemas, by index, for their documentation.
+	routes []*router.Route
+}
+
+// New returns a new empty Validator, register the routes' schemas through its `Route`.
+//
+// Usage:
+//
+//	v := jsonschema.New()
+//	v.Route(app.Post("/users", createUser), jsonschema.MustCompile(userSchema))
+//	// or loaded from a file:
+//	s, err := jsonschema.Load("./schemas/user.json")
+//	v.Route(app.Put("/users/{id:uint64}", updateUser), s)
+func New() *Validator {
+	return new(Validator)
+}
+
+// Route registers the "schema" of the "route" and inserts its validation middleware
+// right before the route's main handlers, after the middleware of its Party (i.e the authentication one),
+// see `router#Route.UseBeforeMain`. It should be called before the `Application#Build`.
+//
+// Returns the route itself.
+func (v *Validator) Route(route *router.Route, schema *Schema) *router.Route {
+	v.mu.Lock()
+	v.schemas = append(v.schemas, RouteSchema{
+		Method:    route.Method,
+		Path:      route.Tmpl().Src,
+		RouteName: route.Name,
+		Schema:    schema,
+	})
+	v.routes = append(v.routes, route)
+	v.mu.Unlock()
+
+	return route.UseBeforeMain(Handler(schema))
+}
+
+// Schemas returns the registered schemas by route, in the order of their registration,
+// with the current documentation of their routes.
+func (v *Validator) Schemas() []RouteSchema {
+	v.mu.RLock()
+	schemas := make([]RouteSchema, len(v.schemas))
+	copy(schemas, v.schemas)
+	for i, route := range v.routes {
+		schemas[i].Description = route.Description()
+		schemas[i].Deprecation = route.Deprecation()
+	}
+	v.mu.RUnlock()
+	return schemas
+}
+
+// Schema returns the schema of the "routeName" route, if any.
+func (v *Validator) Schema(routeName string) (*Schema, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	for _, s := range v.schemas {
+		if s.RouteName == routeName {
+			return s.Schema, true
+		}
+	}
+
+	return nil, false
+}
+
+// MaxBodySize is the maximum size, in bytes, of the request bodies which are validated,
+// the larger ones are rejected with a 413 (Request Entity Too Large) problem
+// before they are read to the end, the whole body is kept in memory for the validation.
+//
+// Defaults to 4MB.
+var MaxBodySize int64 = 4 << 20
+
+// Handler returns the validation middleware of the "schema",
+// which is not registered to a `Validator`, so it's not listed by its `Schemas`.
+func Handler(schema *Schema) context.Handler {
+	return func(ctx context.Context) {
+		r := ctx.Request()
+		if r.Body == nil {
+			reject(ctx, http.StatusBadRequest, "the request body is missing", nil)
+			return
+		}
+
+		limit := MaxBodySize
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, limit+1))
+		r.Body.Close()
+		if err != nil {
+			reject(ctx, http.StatusBadRequest, "the request body cannot be read", nil)
+			return
+		}
+
+		if int64(len(body)) > limit {
+			reject(ctx, http.StatusRequestEntityTooLarge, "the request body is too large", nil)
+			return
+		}
+		// restore the body for the next handlers.
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		if len(bytes.TrimSpace(body)) == 0 {
+			reject(ctx, http.StatusBadRequest, "the request body is missing", nil)
+			return
+		}
+
+		violations, err := schema.Validate(body)
+		if err != nil {
+			reject(ctx, http.StatusBadRequest, "the request body is not a valid JSON: "+err.Error(), nil)
+			return
+		}
+
+		if len(violations) > 0 {
+			reject(ctx, http.StatusUnprocessableEntity, "the request body does not match the schema", violations)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func reject(ctx context.Context, statusCode int, detail string, violations []Violation) {
+	p := context.NewProblem().Status(statusCode).Detail(detail)
+	if violations != nil {
+		p.Key("violations", violations)
+	}
+
+	ctx.Problem(p)
+	ctx.StopExecution()
+}
@@ -0,0 +1,116 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/middleware/jsonschema"
+)
+
+const userSchema = `{
+	"definitions": {"name": {"type": "string", "minLength": 1}},
+	"type": "object",
+	"properties": {"name": {"$ref": "#/definitions/name"}},
+	"required": ["name"]
+}`
+
+func TestHandler(t *testing.T) {
+	app := iris.New()
+	v := jsonschema.New()
+	v.Route(app.Post("/users", func(ctx iris.Context) {
+		var user struct {
+			Name string `json:"name"`
+		}
+		if err := ctx.ReadJSON(&user); err != nil {
+			ctx.StatusCode(iris.StatusInternalServerError)
+			return
+		}
+		ctx.WriteString(user.Name)
+	}), jsonschema.MustCompile([]byte(userSchema)))
+
+	e := httptest.New(t, app)
+	e.POST("/users").WithBytes([]byte(`{"name": "kataras"}`)).Expect().Status(httptest.StatusOK).
+		Body().Equal("kataras")
+	r := e.POST("/users").WithBytes([]byte(`{"name": ""}`)).Expect().Status(httptest.StatusUnprocessableEntity)
+	r.ContentType("application/problem+json", "utf-8")
+	var problem struct {
+		Violations []jsonschema.Violation `json:"violations"`
+	}
+	if err := json.Unmarshal([]byte(r.Body().Raw()), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (jsonschema.Violation{Path: "/name", Keyword: "minLength", Message: "must be at least 1 characters long"}); len(problem.Violations) != 1 || problem.Violations[0] != expected {
+		t.Fatalf("expected the violation %#+v but got %#+v", expected, problem.Violations)
+	}
+
+	e.POST("/users").WithBytes([]byte(`{"name": `)).Expect().Status(httptest.StatusBadRequest)
+	e.POST("/users").Expect().Status(httptest.StatusBadRequest)
+
+	large := `{"name": "` + strings.Repeat("a", int(jsonschema.MaxBodySize)) + `"}`
+	e.POST("/users").WithBytes([]byte(large)).Expect().Status(httptest.StatusRequestEntityTooLarge)
+}
+
+func TestOpenAPI(t *testing.T) {
+	app := iris.New()
+	v := jsonschema.New()
+	v.Route(app.Put("/users/{id:uint64}", func(ctx iris.Context) {}), jsonschema.MustCompile([]byte(userSchema))).
+		SetDescription("Updates a user.")
+	app.Get("/openapi.json", v.OpenAPIHandler("Users", "1.0.0"))
+
+	e := httptest.New(t, app)
+	body := e.GET("/openapi.json").Expect().Status(httptest.StatusOK).Body().Raw()
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title string `json:"title"`
+		} `json:"info"`
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Description string `json:"description"`
+			Parameters  []struct {
+				Name string `json:"name"`
+				In   string `json:"in"`
+			} `json:"parameters"`
+			RequestBody struct {
+				Content map[string]struct {
+					Schema map[string]string `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]string `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.OpenAPI != jsonschema.OpenAPIVersion || doc.Info.Title != "Users" {
+		t.Fatalf("unexpected document: %s", body)
+	}
+
+	op, ok := doc.Paths["/users/{id}"]["put"]
+	if !ok {
+		t.Fatalf("expected the PUT /users/{id} operation: %s", body)
+	}
+
+	if op.Description != "Updates a user." || len(op.Parameters) != 1 || op.Parameters[0].Name != "id" || op.Parameters[0].In != "path" {
+		t.Fatalf("unexpected operation: %s", body)
+	}
+
+	ref := op.RequestBody.Content["application/json"].Schema["$ref"]
+	if expected := "#/components/schemas/PUT_users_id_uint64"; ref != expected {
+		t.Fatalf("expected the request body's schema to be '%s' but got '%s'", expected, ref)
+	}
+
+	name := doc.Components.Schemas["PUT_users_id_uint64"].Properties["name"]["$ref"]
+	if expected := "#/components/schemas/PUT_users_id_uint64/definitions/name"; name != expected {
+		t.Fatalf("expected the local $ref to be rewritten to '%s' but got '%s'", expected, name)
+	}
+}
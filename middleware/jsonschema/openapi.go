@@ -0,0 +1,141 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/kataras/iris/context"
+)
+
+// OpenAPIVersion is the version of the OpenAPI documents of the `Validator#OpenAPI`,
+// the 3.1 is the first one which is compatible with the JSON Schema.
+const OpenAPIVersion = "3.1.0"
+
+// OpenAPI returns the OpenAPI document of the registered routes, as JSON,
+// their schemas are the (required) "application/json" request bodies of their operations.
+// The "title" and "version" are the API's info.
+//
+// Each schema is stored to the document's "components/schemas" by its route's name
+// and its local "$ref"s are rewritten to point inside it.
+//
+// Usage:
+//
+//	app.Get("/openapi.json", v.OpenAPIHandler("My API", "1.0.0"))
+func (v *Validator) OpenAPI(title, version string) ([]byte, error) {
+	paths := make(map[string]map[string]interface{})
+	components := make(map[string]interface{})
+
+	for _, s := range v.Schemas() {
+		name := componentName(s.RouteName)
+		var schema interface{}
+		if err := json.Unmarshal(s.Schema.Raw(), &schema); err != nil {
+			return nil, err
+		}
+		components[name] = rewriteRefs(schema, "#/components/schemas/"+escapePointer(name))
+
+		operation := map[string]interface{}{
+			"operationId": s.RouteName,
+			"requestBody": map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					context.ContentJSONHeaderValue: map[string]interface{}{
+						"schema": map[string]string{"$ref": "#/components/schemas/" + escapePointer(name)},
+					},
+				},
+			},
+			"responses": map[string]interface{}{
+				"400": map[string]string{"description": "The request body is missing or it is not a valid JSON."},
+				"413": map[string]string{"description": "The request body is too large."},
+				"422": map[string]string{"description": "The request body does not match the schema."},
+			},
+		}
+		if s.Description != "" {
+			operation["description"] = s.Description
+		}
+		if s.Deprecation != nil {
+			operation["deprecated"] = true
+		}
+		if params := pathParamRegexp.FindAllStringSubmatch(s.Path, -1); len(params) > 0 {
+			parameters := make([]map[string]interface{}, 0, len(params))
+			for _, param := range params {
+				parameters = append(parameters, map[string]interface{}{
+					"name":     param[1],
+					"in":       "path",
+					"required": true,
+					"schema":   map[string]string{"type": "string"},
+				})
+			}
+			operation["parameters"] = parameters
+		}
+
+		path := openAPIPath(s.Path)
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path][strings.ToLower(s.Method)] = operation
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"openapi": OpenAPIVersion,
+		"info": map[string]string{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": components,
+		},
+	})
+}
+
+// OpenAPIHandler returns a handler which sends the `OpenAPI` document of the registered routes.
+func (v *Validator) OpenAPIHandler(title, version string) context.Handler {
+	return func(ctx context.Context) {
+		b, err := v.OpenAPI(title, version)
+		if err != nil {
+			ctx.StatusCode(http.StatusInternalServerError)
+			context.Logger(ctx).Errorf("jsonschema: openapi: %v", err)
+			return
+		}
+
+		ctx.ContentType(context.ContentJSONHeaderValue)
+		ctx.Write(b)
+	}
+}
+
+var pathParamRegexp = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// openAPIPath converts the route's path to an OpenAPI one, i.e "/users/{id:uint64}" to "/users/{id}".
+func openAPIPath(path string) string {
+	return pathParamRegexp.ReplaceAllString(path, "{$1}")
+}
+
+var componentNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// componentName returns the OpenAPI component's name of the "routeName", i.e "POST/users" to "POST_users".
+func componentName(routeName string) string {
+	return strings.Trim(componentNameRegexp.ReplaceAllString(routeName, "_"), "_")
+}
+
+// rewriteRefs prefixes the local "$ref"s of the decoded "schema" with the "base",
+// so they point inside the schema when it's a part of another document.
+func rewriteRefs(schema interface{}, base string) interface{} {
+	switch v := schema.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" && strings.HasPrefix(ref, "#") {
+				v[key] = base + strings.TrimPrefix(ref, "#")
+				continue
+			}
+			v[key] = rewriteRefs(value, base)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = rewriteRefs(value, base)
+		}
+	}
+
+	return schema
+}
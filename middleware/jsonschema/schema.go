@@ -0,0 +1,682 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+var (
+	errInvalidSchema = errors.New("jsonschema: invalid schema. Trace: %s")
+	errInvalidRef    = errors.New("jsonschema: unresolved $ref '%s', only the local '#', '#/definitions/...' and '#/$defs/...' are supported")
+	errInvalidRegexp = errors.New("jsonschema: invalid pattern '%s'. Trace: %s")
+	errRefCycle      = errors.New("jsonschema: a $ref cycle without a property or an item in between, it never ends")
+)
+
+// Schema is a compiled JSON Schema, see `Compile` and `Load`.
+//
+// The common subset of the draft 7 (and later) keywords is supported:
+// "type", "enum", "const", "properties", "required", "additionalProperties", "minProperties", "maxProperties",
+// "items", "minItems", "maxItems", "uniqueItems", "minLength", "maxLength", "pattern", "format",
+// "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf",
+// "allOf", "anyOf", "oneOf", "not" and the local "$ref"s, the rest of the keywords are ignored.
+// The supported formats are "email", "date-time", "date", "uuid", "uri", "ipv4" and "ipv6".
+type Schema struct {
+	raw  json.RawMessage
+	root *node
+}
+
+// Compile parses and compiles the "data" JSON Schema, i.e an embedded one.
+func Compile(data []byte) (*Schema, error) {
+	root := new(node)
+	if err := json.Unmarshal(data, root); err != nil {
+		return nil, errInvalidSchema.Format(err.Error())
+	}
+
+	s := &Schema{raw: json.RawMessage(data), root: root}
+	if err := s.compile(root); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCycles(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// MustCompile same as `Compile` but it panics on errors.
+func MustCompile(data []byte) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// Load reads and compiles the JSON Schema of the "filename" file.
+func Load(filename string) (*Schema, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Compile(data)
+}
+
+// Raw returns the JSON document of the schema, as it was given.
+func (s *Schema) Raw() json.RawMessage {
+	return s.raw
+}
+
+// Title returns the "title" of the schema, if any.
+func (s *Schema) Title() string {
+	return s.root.Title
+}
+
+// Violation is a failed rule of a JSON document.
+type Violation struct {
+	// Path is the JSON pointer of the invalid value, i.e "/user/emails/0", empty for the whole document.
+	Path string `json:"path"`
+	// Keyword is the failed keyword of the schema, i.e "required".
+	Keyword string `json:"keyword"`
+	// Message is the human-readable description of the violation.
+	Message string `json:"message"`
+}
+
+// Validate validates the "data" JSON document against the schema and returns its violations, if any.
+// It returns an error if the "data" is not a valid JSON.
+func (s *Schema) Validate(data []byte) ([]Violation, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if dec.More() {
+		return nil, errInvalidSchema.Format("extra data after the JSON document")
+	}
+
+	return s.ValidateValue(v), nil
+}
+
+// ValidateValue validates a decoded JSON value against the schema and returns its violations, if any.
+func (s *Schema) ValidateValue(v interface{}) []Violation {
+	var violations []Violation
+	s.validate(s.root, "", normalize(v), &violations)
+	return violations
+}
+
+// node is a (sub) schema, the booleans "true" and "false" are valid schemas too.
+type node struct {
+	isBool    bool
+	boolValue bool
+
+	Ref         string `json:"$ref"`
+	Title       string `json:"title"`
+	Type        types  `json:"type"`
+	Enum        []interface{}
+	Const       json.RawMessage `json:"const"`
+	constValue  interface{}
+	Definitions map[string]*node `json:"definitions"`
+	Defs        map[string]*node `json:"$defs"`
+
+	Properties           map[string]*node `json:"properties"`
+	Required             []string         `json:"required"`
+	AdditionalProperties *node            `json:"additionalProperties"`
+	MinProperties        *int             `json:"minProperties"`
+	MaxProperties        *int             `json:"maxProperties"`
+
+	Items       *node `json:"items"`
+	MinItems    *int  `json:"minItems"`
+	MaxItems    *int  `json:"maxItems"`
+	UniqueItems bool  `json:"uniqueItems"`
+
+	MinLength *int   `json:"minLength"`
+	MaxLength *int   `json:"maxLength"`
+	Pattern   string `json:"pattern"`
+	pattern   *regexp.Regexp
+	Format    string `json:"format"`
+
+	Minimum          *float64        `json:"minimum"`
+	Maximum          *float64        `json:"maximum"`
+	ExclusiveMinimum json.RawMessage `json:"exclusiveMinimum"`
+	ExclusiveMaximum json.RawMessage `json:"exclusiveMaximum"`
+	MultipleOf       *float64        `json:"multipleOf"`
+	// the numeric exclusive limits, the boolean ones (draft 4) apply to the minimum and maximum.
+	exclusiveMin, exclusiveMax *float64
+
+	AllOf []*node `json:"allOf"`
+	AnyOf []*node `json:"anyOf"`
+	OneOf []*node `json:"oneOf"`
+	Not   *node   `json:"not"`
+}
+
+func (n *node) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		n.isBool, n.boolValue = true, true
+		return nil
+	case "false":
+		n.isBool, n.boolValue = true, false
+		return nil
+	}
+
+	type plain node
+	p := (*plain)(n)
+	if err := json.Unmarshal(data, p); err != nil {
+		return err
+	}
+
+	var enum struct {
+		Enum []interface{} `json:"enum"`
+	}
+	if err := json.Unmarshal(data, &enum); err != nil {
+		return err
+	}
+	n.Enum = enum.Enum
+	return nil
+}
+
+// types is the "type" keyword, a single type name or a list of them.
+type types []string
+
+func (t *types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = types{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+// compile resolves the references, the regular expressions and the exclusive limits of the "n" and its sub schemas.
+func (s *Schema) compile(n *node) error {
+	if n == nil || n.isBool {
+		return nil
+	}
+
+	if n.Ref != "" {
+		if _, err := s.resolve(n.Ref); err != nil {
+			return err
+		}
+	}
+
+	if n.Pattern != "" {
+		re, err := regexp.Compile(n.Pattern)
+		if err != nil {
+			return errInvalidRegexp.Format(n.Pattern, err.Error())
+		}
+		n.pattern = re
+	}
+
+	if len(n.Const) > 0 {
+		var v interface{}
+		if err := json.Unmarshal(n.Const, &v); err != nil {
+			return errInvalidSchema.Format(err.Error())
+		}
+		n.constValue = v
+	}
+
+	var err error
+	if n.exclusiveMin, n.Minimum, err = exclusiveLimit(n.ExclusiveMinimum, n.Minimum); err != nil {
+		return err
+	}
+	if n.exclusiveMax, n.Maximum, err = exclusiveLimit(n.ExclusiveMaximum, n.Maximum); err != nil {
+		return err
+	}
+
+	for _, child := range n.children() {
+		if err := s.compile(child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// children returns the sub schemas of the "n", some of them may be nil.
+func (n *node) children() []*node {
+	children := make([]*node, 0, len(n.Properties)+len(n.Definitions)+len(n.Defs)+len(n.AllOf)+len(n.AnyOf)+len(n.OneOf)+3)
+	for _, m := range []map[string]*node{n.Properties, n.Definitions, n.Defs} {
+		for _, child := range m {
+			children = append(children, child)
+		}
+	}
+	children = append(children, n.AllOf...)
+	children = append(children, n.AnyOf...)
+	children = append(children, n.OneOf...)
+	return append(children, n.AdditionalProperties, n.Items, n.Not)
+}
+
+// sameValue returns the schemas which validate the same value as the "n" does,
+// its "$ref" or its "allOf", "anyOf", "oneOf" and "not" ones.
+func (s *Schema) sameValue(n *node) []*node {
+	if n == nil || n.isBool {
+		return nil
+	}
+
+	if n.Ref != "" {
+		// the siblings of a $ref are ignored, see `validate`.
+		ref, _ := s.resolve(n.Ref)
+		return []*node{ref}
+	}
+
+	nodes := make([]*node, 0, len(n.AllOf)+len(n.AnyOf)+len(n.OneOf)+1)
+	nodes = append(nodes, n.AllOf...)
+	nodes = append(nodes, n.AnyOf...)
+	nodes = append(nodes, n.OneOf...)
+	if n.Not != nil {
+		nodes = append(nodes, n.Not)
+	}
+
+	return nodes
+}
+
+// checkCycles reports the "$ref" cycles which validate the same value forever, i.e {"$ref": "#"}
+// or the "a" refers to the "b" and the "b" to the "a".
+// The cycles through the "properties", "additionalProperties" and "items" are fine,
+// they validate a part of the value, which is finite.
+func (s *Schema) checkCycles() error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[*node]int)
+
+	var follow func(n *node) error
+	follow = func(n *node) error {
+		switch state[n] {
+		case visiting:
+			return errRefCycle
+		case visited:
+			return nil
+		}
+
+		state[n] = visiting
+		for _, next := range s.sameValue(n) {
+			if next == nil {
+				continue
+			}
+			if err := follow(next); err != nil {
+				return err
+			}
+		}
+		state[n] = visited
+		return nil
+	}
+
+	var walk func(n *node) error
+	walk = func(n *node) error {
+		if n == nil || n.isBool {
+			return nil
+		}
+
+		if err := follow(n); err != nil {
+			return err
+		}
+
+		for _, child := range n.children() {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return walk(s.root)
+}
+
+// exclusiveLimit returns the numeric exclusive limit of the "raw" keyword and the inclusive limit,
+// a boolean "raw" (draft 4) makes the inclusive "limit" an exclusive one.
+func exclusiveLimit(raw json.RawMessage, limit *float64) (*float64, *float64, error) {
+	if len(raw) == 0 {
+		return nil, limit, nil
+	}
+
+	switch string(bytes.TrimSpace(raw)) {
+	case "true":
+		return limit, nil, nil
+	case "false":
+		return nil, limit, nil
+	}
+
+	var f float64
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, nil, errInvalidSchema.Format(err.Error())
+	}
+
+	return &f, limit, nil
+}
+
+// resolve returns the sub schema of a local "ref".
+func (s *Schema) resolve(ref string) (*node, error) {
+	if ref == "#" {
+		return s.root, nil
+	}
+
+	for prefix, defs := range map[string]map[string]*node{
+		"#/definitions/": s.root.Definitions,
+		"#/$defs/":       s.root.Defs,
+	} {
+		if strings.HasPrefix(ref, prefix) {
+			name := unescapePointer(strings.TrimPrefix(ref, prefix))
+			if n, ok := defs[name]; ok {
+				return n, nil
+			}
+		}
+	}
+
+	return nil, errInvalidRef.Format(ref)
+}
+
+func (s *Schema) validate(n *node, path string, v interface{}, violations *[]Violation) {
+	if n == nil {
+		return
+	}
+
+	report := func(keyword, format string, args ...interface{}) {
+		*violations = append(*violations, Violation{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.isBool {
+		if !n.boolValue {
+			report("false", "is not allowed")
+		}
+		return
+	}
+
+	if n.Ref != "" {
+		// the siblings of a $ref are ignored, like the draft 7 does.
+		ref, _ := s.resolve(n.Ref)
+		s.validate(ref, path, v, violations)
+		return
+	}
+
+	if len(n.Type) > 0 && !matchType(n.Type, v) {
+		report("type", "must be of type %s", strings.Join(n.Type, " or "))
+		// the rest of the keywords are meaningless for a different type.
+		return
+	}
+
+	if n.Enum != nil {
+		found := false
+		for _, e := range n.Enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report("enum", "must be one of %s", jsonString(n.Enum))
+		}
+	}
+
+	if len(n.Const) > 0 && !reflect.DeepEqual(n.constValue, v) {
+		report("const", "must be equal to %s", string(n.Const))
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		s.validateObject(n, path, value, violations, report)
+	case []interface{}:
+		if n.MinItems != nil && len(value) < *n.MinItems {
+			report("minItems", "must have at least %d items", *n.MinItems)
+		}
+		if n.MaxItems != nil && len(value) > *n.MaxItems {
+			report("maxItems", "must have at most %d items", *n.MaxItems)
+		}
+		if n.UniqueItems {
+			for i := 1; i < len(value); i++ {
+				for j := 0; j < i; j++ {
+					if reflect.DeepEqual(value[i], value[j]) {
+						report("uniqueItems", "must have unique items, the %d and %d items are equal", j, i)
+						i = len(value)
+						break
+					}
+				}
+			}
+		}
+		if n.Items != nil {
+			for i, item := range value {
+				s.validate(n.Items, path+"/"+strconv.Itoa(i), item, violations)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(value)
+		if n.MinLength != nil && length < *n.MinLength {
+			report("minLength", "must be at least %d characters long", *n.MinLength)
+		}
+		if n.MaxLength != nil && length > *n.MaxLength {
+			report("maxLength", "must be at most %d characters long", *n.MaxLength)
+		}
+		if n.pattern != nil && !n.pattern.MatchString(value) {
+			report("pattern", "must match the pattern %s", n.Pattern)
+		}
+		if n.Format != "" && !matchFormat(n.Format, value) {
+			report("format", "must be a valid %s", n.Format)
+		}
+	case float64:
+		if n.Minimum != nil && value < *n.Minimum {
+			report("minimum", "must be greater than or equal to %v", *n.Minimum)
+		}
+		if n.exclusiveMin != nil && value <= *n.exclusiveMin {
+			report("exclusiveMinimum", "must be greater than %v", *n.exclusiveMin)
+		}
+		if n.Maximum != nil && value > *n.Maximum {
+			report("maximum", "must be less than or equal to %v", *n.Maximum)
+		}
+		if n.exclusiveMax != nil && value >= *n.exclusiveMax {
+			report("exclusiveMaximum", "must be less than %v", *n.exclusiveMax)
+		}
+		if m := n.MultipleOf; m != nil && *m > 0 {
+			if q := value / *m; math.Abs(q-math.Round(q)) > 1e-9 {
+				report("multipleOf", "must be a multiple of %v", *m)
+			}
+		}
+	}
+
+	for _, sub := range n.AllOf {
+		s.validate(sub, path, v, violations)
+	}
+
+	if len(n.AnyOf) > 0 && s.countValid(n.AnyOf, path, v) == 0 {
+		report("anyOf", "must match at least one of the schemas")
+	}
+
+	if len(n.OneOf) > 0 {
+		if matched := s.countValid(n.OneOf, path, v); matched != 1 {
+			report("oneOf", "must match exactly one of the schemas, matched %d", matched)
+		}
+	}
+
+	if n.Not != nil && s.countValid([]*node{n.Not}, path, v) == 1 {
+		report("not", "must not match the schema")
+	}
+}
+
+func (s *Schema) validateObject(n *node, path string, value map[string]interface{}, violations *[]Violation, report func(keyword, format string, args ...interface{})) {
+	for _, name := range n.Required {
+		if _, ok := value[name]; !ok {
+			*violations = append(*violations, Violation{
+				Path:    path + "/" + escapePointer(name),
+				Keyword: "required",
+				Message: "is required",
+			})
+		}
+	}
+
+	if n.MinProperties != nil && len(value) < *n.MinProperties {
+		report("minProperties", "must have at least %d properties", *n.MinProperties)
+	}
+	if n.MaxProperties != nil && len(value) > *n.MaxProperties {
+		report("maxProperties", "must have at most %d properties", *n.MaxProperties)
+	}
+
+	// sorted, so the violations are always in the same order.
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		childPath := path + "/" + escapePointer(name)
+		if prop, ok := n.Properties[name]; ok {
+			s.validate(prop, childPath, value[name], violations)
+			continue
+		}
+
+		if n.AdditionalProperties != nil {
+			if n.AdditionalProperties.isBool && !n.AdditionalProperties.boolValue {
+				*violations = append(*violations, Violation{
+					Path:    childPath,
+					Keyword: "additionalProperties",
+					Message: "is not an allowed property",
+				})
+				continue
+			}
+			s.validate(n.AdditionalProperties, childPath, value[name], violations)
+		}
+	}
+}
+
+func (s *Schema) countValid(schemas []*node, path string, v interface{}) int {
+	valid := 0
+	for _, sub := range schemas {
+		var violations []Violation
+		s.validate(sub, path, v, &violations)
+		if len(violations) == 0 {
+			valid++
+		}
+	}
+
+	return valid
+}
+
+func matchType(names types, v interface{}) bool {
+	for _, name := range names {
+		switch name {
+		case "null":
+			if v == nil {
+				return true
+			}
+		case "boolean":
+			if _, ok := v.(bool); ok {
+				return true
+			}
+		case "string":
+			if _, ok := v.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := v.(float64); ok {
+				return true
+			}
+		case "integer":
+			if f, ok := v.(float64); ok && f == math.Trunc(f) && !math.IsInf(f, 0) {
+				return true
+			}
+		case "array":
+			if _, ok := v.([]interface{}); ok {
+				return true
+			}
+		case "object":
+			if _, ok := v.(map[string]interface{}); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+var uuidRegexp = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+// matchFormat reports whether the "value" is of the "format", the unknown formats are always valid.
+func matchFormat(format, value string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "uuid":
+		return uuidRegexp.MatchString(value)
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil && !strings.Contains(value, ":")
+	case "ipv6":
+		ip := net.ParseIP(value)
+		return ip != nil && strings.Contains(value, ":")
+	}
+
+	return true
+}
+
+// normalize converts the `json.Number`s of the "v" to float64s, like the schema's values.
+func normalize(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		f, _ := value.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = normalize(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalize(item)
+		}
+	}
+
+	return v
+}
+
+func jsonString(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapePointer(name string) string {
+	return pointerEscaper.Replace(name)
+}
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+func unescapePointer(name string) string {
+	return pointerUnescaper.Replace(name)
+}
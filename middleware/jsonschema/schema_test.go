@@ -0,0 +1,91 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func TestCompileRefCycles(t *testing.T) {
+	invalid := []string{
+		`{"$ref": "#"}`,
+		`{"definitions": {"a": {"$ref": "#/definitions/b"}, "b": {"$ref": "#/definitions/a"}}, "$ref": "#/definitions/a"}`,
+		`{"definitions": {"a": {"$ref": "#/definitions/a"}}}`,
+		`{"allOf": [{"$ref": "#"}]}`,
+		`{"$defs": {"a": {"anyOf": [{"type": "string"}, {"$ref": "#/$defs/a"}]}}, "properties": {"x": {"$ref": "#/$defs/a"}}}`,
+	}
+
+	for i, schema := range invalid {
+		if _, err := Compile([]byte(schema)); !errRefCycle.Equal(err) {
+			t.Fatalf("[%d] expected a $ref cycle error but got: %v", i, err)
+		}
+	}
+
+	// recursive through the properties and the items, they consume the value.
+	tree := MustCompile([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"children": {"type": "array", "items": {"$ref": "#"}}
+		},
+		"required": ["name"]
+	}`))
+
+	violations, err := tree.Validate([]byte(`{"name": "root", "children": [{"name": "a", "children": [{"children": []}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(violations) != 1 || violations[0].Path != "/children/0/children/0/name" || violations[0].Keyword != "required" {
+		t.Fatalf("expected a single required violation of the nested name but got: %#+v", violations)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := MustCompile([]byte(`{
+		"type": "object",
+		"properties": {
+			"email": {"type": "string", "format": "email"},
+			"age": {"type": "integer", "minimum": 18, "exclusiveMaximum": 130},
+			"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true, "maxItems": 3}
+		},
+		"required": ["email"],
+		"additionalProperties": false
+	}`))
+
+	tests := []struct {
+		data     string
+		expected []Violation
+	}{
+		{`{"email": "a@example.com", "age": 18, "tags": ["a", "b"]}`, nil},
+		{`{}`, []Violation{{Path: "/email", Keyword: "required", Message: "is required"}}},
+		{`{"email": "invalid", "age": 17.5, "tags": ["a", "a"], "other": 1}`, []Violation{
+			{Path: "/age", Keyword: "type", Message: "must be of type integer"},
+			{Path: "/email", Keyword: "format", Message: "must be a valid email"},
+			{Path: "/other", Keyword: "additionalProperties", Message: "is not an allowed property"},
+			{Path: "/tags", Keyword: "uniqueItems", Message: "must have unique items, the 0 and 1 items are equal"},
+		}},
+		{`{"email": "a@example.com", "age": 130}`, []Violation{
+			{Path: "/age", Keyword: "exclusiveMaximum", Message: "must be less than 130"},
+		}},
+	}
+
+	for i, tt := range tests {
+		violations, err := s.Validate([]byte(tt.data))
+		if err != nil {
+			t.Fatalf("[%d] %v", i, err)
+		}
+
+		if len(violations) != len(tt.expected) {
+			t.Fatalf("[%d] expected %d violations but got %d: %#+v", i, len(tt.expected), len(violations), violations)
+		}
+
+		for j := range violations {
+			if violations[j] != tt.expected[j] {
+				t.Fatalf("[%d:%d] expected violation %#+v but got %#+v", i, j, tt.expected[j], violations[j])
+			}
+		}
+	}
+
+	if _, err := s.Validate([]byte(`{"email": `)); err == nil {
+		t.Fatalf("expected an error for an invalid JSON")
+	}
+}
@@ -0,0 +1,22 @@
+package proxyurl
+
+// Config the configs for the proxy url rewriting middleware.
+type Config struct {
+	// BaseURL is the public base URL of the application, i.e "https://example.com/app",
+	// it takes precedence over the forwarded headers of the proxies.
+	//
+	// Defaults to empty, the scheme, the host and the prefix are read from the trusted proxies,
+	// see `Configuration#SSLProxyHeaders`, `Configuration#HostProxyHeaders` and `context#ForwardedPrefixHeaderKey`,
+	// the prefix is read only from the `Configuration#TrustedProxies`.
+	BaseURL string
+	// DisableHTML if true then only the "Location" and the "Content-Location" headers are rewritten,
+	// the HTML responses are not recorded.
+	//
+	// Defaults to false.
+	DisableHTML bool
+}
+
+// DefaultConfig returns the default configs for the proxy url rewriting middleware.
+func DefaultConfig() Config {
+	return Config{}
+}
@@ -0,0 +1,203 @@
+// Package proxyurl provides a middleware which rewrites the absolute URLs of the responses
+// to the public URL of the application behind a reverse proxy, i.e nginx,
+// so an application which is served under a path prefix works without per-handler fixes.
+//
+// The "Location" and the "Content-Location" headers and the "href", "src", "action" and "formaction"
+// attributes of the HTML responses are rewritten:
+//
+//	http://{internal host}/login => https://example.com/app/login
+//	/login                       => /app/login
+//
+// The public URL is the `Config#BaseURL` or the one of the trusted proxies' headers,
+// i.e "X-Forwarded-Proto", "X-Forwarded-Host" and "X-Forwarded-Prefix".
+package proxyurl
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+)
+
+// ErrInvalidBaseURL is the panic of the `New` when the `Config#BaseURL` is not an absolute URL.
+var ErrInvalidBaseURL = errors.New("proxyurl: invalid base url '%s'")
+
+// htmlURLAttr matches the url attributes of the HTML elements, i.e ` href="/login"`.
+var htmlURLAttr = regexp.MustCompile(`(?i)\s(?:href|src|action|formaction)\s*=\s*(?:"[^"]*"|'[^']*')`)
+
+// rewriter rewrites the URLs of a response.
+type rewriter struct {
+	// internal are the origins of the application as seen by the server, i.e "http://10.0.0.5:8080".
+	internal []string
+	// public is the origin of the application as seen by the client, i.e "https://example.com".
+	public string
+	// prefix is the path prefix of the application as seen by the client, i.e "/app".
+	prefix string
+}
+
+func newRewriter(ctx context.Context, base *url.URL) *rewriter {
+	host := ctx.Host()
+	r := &rewriter{internal: []string{"http://" + host, "https://" + host}}
+
+	if base != nil {
+		r.public = base.Scheme + "://" + base.Host
+		r.prefix = strings.TrimRight(base.Path, "/")
+	} else {
+		public, err := url.Parse(context.FullRequestURI(ctx))
+		if err != nil {
+			return nil
+		}
+		r.public = public.Scheme + "://" + public.Host
+		r.prefix = context.ForwardedPrefix(ctx)
+	}
+
+	if r.prefix == "" && r.public == "http://"+host {
+		// nothing to rewrite.
+		return nil
+	}
+
+	return r
+}
+
+func (r *rewriter) withPrefix(path string) string {
+	if r.prefix == "" || path == r.prefix || strings.HasPrefix(path, r.prefix+"/") {
+		return path
+	}
+
+	return r.prefix + path
+}
+
+// rewrite returns the public URL of the "u", the URLs of other hosts and the relative ones, i.e "../login", are kept.
+func (r *rewriter) rewrite(u string) string {
+	if strings.HasPrefix(u, "//") {
+		return u
+	}
+
+	if strings.HasPrefix(u, "/") {
+		return r.withPrefix(u)
+	}
+
+	for _, origin := range r.internal {
+		if len(u) < len(origin) || !strings.EqualFold(u[:len(origin)], origin) {
+			continue
+		}
+
+		rest := u[len(origin):]
+		if rest == "" || rest[0] == '?' || rest[0] == '#' {
+			rest = "/" + rest
+		}
+		if rest[0] != '/' {
+			// i.e http://host:8080 of http://host.
+			continue
+		}
+
+		return r.public + r.withPrefix(rest)
+	}
+
+	return u
+}
+
+func (r *rewriter) rewriteHTML(body []byte) []byte {
+	return htmlURLAttr.ReplaceAllFunc(body, func(attr []byte) []byte {
+		q := bytes.IndexAny(attr, `"'`)
+		value := string(attr[q+1 : len(attr)-1])
+		rewritten := r.rewrite(value)
+		if rewritten == value {
+			return attr
+		}
+
+		b := make([]byte, 0, len(attr)+len(rewritten)-len(value))
+		b = append(b, attr[:q+1]...)
+		b = append(b, rewritten...)
+		return append(b, attr[len(attr)-1])
+	})
+}
+
+func (r *rewriter) rewriteHeaders(h http.Header) {
+	for _, key := range [...]string{"Location", "Content-Location"} {
+		if v := h.Get(key); v != "" {
+			h.Set(key, r.rewrite(v))
+		}
+	}
+}
+
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "text/html" || mediaType == "application/xhtml+xml")
+}
+
+// New returns a new proxy url rewriting middleware based on the "c" configs,
+// the default configs are used if "c" is missing.
+// It records only the HTML responses of the next handlers, the rest are written directly
+// with their headers rewritten. Register it before the `cache.Handler`
+// so the cached responses are rewritten per request and after the compression ones,
+// the encoded responses are not rewritten.
+// It panics if the `Config#BaseURL` is not an absolute URL.
+//
+// Usage:
+// app.Use(proxyurl.New())
+// app.Run(iris.Addr(":8080"), iris.WithHostProxyHeader("X-Forwarded-Host"), iris.WithSSLProxyHeader("X-Forwarded-Proto", "https"), iris.WithTrustedProxies("10.0.0.0/8"))
+// 反向代理(例如 nginx 的路径前缀)下, 改写响应中的绝对地址(Location 头和 HTML 链接)为客户端看到的地址
+func New(c ...Config) context.Handler {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		config = c[0]
+	}
+
+	var base *url.URL
+	if config.BaseURL != "" {
+		u, err := url.Parse(config.BaseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			panic(ErrInvalidBaseURL.Format(config.BaseURL))
+		}
+		base = u
+	}
+
+	recordable := func(h http.Header) bool {
+		return !config.DisableHTML && h.Get(context.ContentEncodingHeaderKey) == "" && isHTML(h.Get(context.ContentTypeHeaderKey))
+	}
+
+	return func(ctx context.Context) {
+		r := newRewriter(ctx, base)
+		if r == nil {
+			ctx.Next()
+			return
+		}
+
+		ctx.Record()
+		ctx.Recorder().SetRecordFilter(func(w *context.ResponseRecorder) bool {
+			if recordable(w.Header()) {
+				return true
+			}
+
+			// the headers are sent now.
+			r.rewriteHeaders(w.Header())
+			return false
+		})
+		ctx.Next()
+		ctx.Recorder().SetRecordFilter(nil)
+
+		w, ok := ctx.IsRecording()
+		if !ok || w.IsPassThrough() {
+			// the headers and the body are sent already.
+			return
+		}
+
+		h := w.Header()
+		r.rewriteHeaders(h)
+
+		if !recordable(h) {
+			return
+		}
+
+		if body := w.Body(); len(body) > 0 {
+			w.SetBody(r.rewriteHTML(body))
+			h.Del(context.ContentLengthHeaderKey)
+		}
+	}
+}
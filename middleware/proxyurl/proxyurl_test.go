@@ -0,0 +1,91 @@
+package proxyurl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/middleware/proxyurl"
+)
+
+const largeBodySize = 1 << 20
+
+func newApp(t *testing.T, c proxyurl.Config, configurators ...iris.Configurator) *iris.Application {
+	app := iris.New().Configure(configurators...)
+	app.Use(proxyurl.New(c))
+	app.Get("/page", func(ctx iris.Context) {
+		ctx.HTML(`<a href="/login">login</a><img src='http://internal.local/logo.png'><a href="https://other.com/x">x</a>`)
+	})
+	app.Get("/redirect", func(ctx iris.Context) {
+		ctx.Redirect("/login", iris.StatusFound)
+	})
+	app.Get("/file", func(ctx iris.Context) {
+		// not an HTML response, it's written directly.
+		ctx.ContentType("application/octet-stream")
+		ctx.Header("Content-Location", "/file.bin")
+		ctx.WriteString(`href="/login"`)
+		if w, ok := ctx.IsRecording(); !ok || !w.IsPassThrough() {
+			t.Errorf("expected a non-html response to not be recorded")
+		}
+		ctx.Write(make([]byte, largeBodySize))
+	})
+
+	return app
+}
+
+func TestProxyURLBaseURL(t *testing.T) {
+	app := newApp(t, proxyurl.Config{BaseURL: "https://example.com/app"})
+	e := httptest.New(t, app, httptest.URL("http://internal.local"), httptest.RemoteAddr("203.0.113.7:1234"))
+
+	e.GET("/page").Expect().Status(iris.StatusOK).
+		Body().Equal(`<a href="/app/login">login</a><img src='https://example.com/app/logo.png'><a href="https://other.com/x">x</a>`)
+
+	// the client follows the rewritten location.
+	if expected, got := "/app/login", e.GET("/redirect").Expect().Raw().Request.URL.Path; got != expected {
+		t.Fatalf("expected the location %q but got %q", expected, got)
+	}
+
+	r := e.GET("/file").Expect().Status(iris.StatusOK)
+	r.Header("Content-Location").Equal("/app/file.bin")
+	if body := r.Body().Raw(); !strings.HasPrefix(body, `href="/login"`) || len(body) != len(`href="/login"`)+largeBodySize {
+		t.Fatalf("expected the non-html body to be written as it is")
+	}
+}
+
+func TestProxyURLDisableHTML(t *testing.T) {
+	app := newApp(t, proxyurl.Config{BaseURL: "https://example.com/app", DisableHTML: true})
+	e := httptest.New(t, app, httptest.URL("http://internal.local"), httptest.RemoteAddr("203.0.113.7:1234"))
+
+	if got := e.GET("/page").Expect().Status(iris.StatusOK).Body().Raw(); !strings.HasPrefix(got, `<a href="/login">`) {
+		t.Fatalf("expected the html body to not be rewritten but got %q", got)
+	}
+}
+
+func TestProxyURLForwardedPrefix(t *testing.T) {
+	// no trusted proxies, the prefix header of a client is never honored.
+	e := httptest.New(t, newApp(t, proxyurl.Config{}), httptest.URL("http://internal.local"), httptest.RemoteAddr("10.0.0.2:1234"))
+	if expected, got := "/login", e.GET("/redirect").WithHeader("X-Forwarded-Prefix", "/app").Expect().Raw().Request.URL.Path; got != expected {
+		t.Fatalf("expected the location %q but got %q", expected, got)
+	}
+
+	app := newApp(t, proxyurl.Config{}, iris.WithTrustedProxies("10.0.0.0/8"))
+	tests := []struct {
+		remoteAddr string
+		prefix     string
+		expected   string
+	}{
+		{"10.0.0.2:1234", "/app", "/app/login"},
+		// from a client, not the proxy.
+		{"203.0.113.7:1234", "/app", "/login"},
+		// not a path.
+		{"10.0.0.2:1234", "//evil.com", "/login"},
+	}
+
+	for i, tt := range tests {
+		e := httptest.New(t, app, httptest.URL("http://internal.local"), httptest.RemoteAddr(tt.remoteAddr))
+		if got := e.GET("/redirect").WithHeader("X-Forwarded-Prefix", tt.prefix).Expect().Raw().Request.URL.Path; got != tt.expected {
+			t.Fatalf("[%d] expected the location %q but got %q", i, tt.expected, got)
+		}
+	}
+}
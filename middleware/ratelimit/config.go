@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// Config the configs for the rate limiting middleware.
+type Config struct {
+	// Algorithm is the rate limiting algorithm, `TokenBucket` or `SlidingWindow`.
+	//
+	// Defaults to `TokenBucket`.
+	Algorithm Algorithm
+	// Limit is the number of the allowed requests of a client per `Period`.
+	//
+	// Defaults to 60.
+	Limit int
+	// Period is the period of the `Limit`.
+	//
+	// Defaults to 1 minute.
+	Period time.Duration
+	// Burst is the capacity of the token bucket, the number of the requests
+	// that a client can send at once, after an idle period. It's ignored by the `SlidingWindow`.
+	//
+	// Defaults to the `Limit`.
+	Burst int
+	// Key returns the client's key of the request, see `ByRemoteAddr`, `ByHeader` and `ByClaim`.
+	//
+	// Defaults to `ByRemoteAddr`.
+	Key KeyFunc
+	// Scope is the namespace of the keys, it should be unique per limiter when a `Store` is shared,
+	// i.e different limits per Party on the same Redis.
+	//
+	// Defaults to empty.
+	Scope string
+	// Store keeps the state of the clients.
+	//
+	// Defaults to a `NewMemoryStore`, use a shared store, i.e the `redisstore`, when there are more than one servers.
+	Store Store
+	// OnLimit is called when the request is rejected, after the "Retry-After" header is set,
+	// the next handlers are not executed.
+	//
+	// Defaults to a handler which sends 429 Too Many Requests,
+	// so the registered error code handler of the 429 is fired.
+	OnLimit func(ctx context.Context, result Result)
+	// OnError is called when the `Store` fails, the request is allowed (fail-open) unless it stops the execution.
+	//
+	// Defaults to a handler which logs the error as a warning.
+	OnError func(ctx context.Context, err error)
+}
+
+// DefaultConfig returns the default configs for the rate limiting middleware.
+func DefaultConfig() Config {
+	return Config{
+		Algorithm: TokenBucket,
+		Limit:     60,
+		Period:    time.Minute,
+	}
+}
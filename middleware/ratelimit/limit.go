@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Algorithm is a rate limiting algorithm, see `Config#Algorithm`.
+type Algorithm uint8
+
+const (
+	// TokenBucket refills the bucket of each client with `Limit` tokens per `Period`, up to the `Burst`,
+	// each request takes a token. It allows short bursts and a steady average rate.
+	TokenBucket Algorithm = iota
+	// SlidingWindow allows `Limit` requests in any `Period`, the previous window's count
+	// is weighted by its overlap with the sliding window, a close and memory efficient approximation.
+	SlidingWindow
+)
+
+// String returns the name of the algorithm.
+func (a Algorithm) String() string {
+	if a == SlidingWindow {
+		return "sliding-window"
+	}
+	return "token-bucket"
+}
+
+// Limit is the limit of a client, as passed to the `Store#Take`.
+type Limit struct {
+	Algorithm Algorithm
+	Limit     int
+	Period    time.Duration
+	// Burst is the capacity of the token bucket.
+	Burst int
+}
+
+// Result is the result of a `Store#Take`.
+type Result struct {
+	// Allowed reports whether the request is allowed.
+	Allowed bool
+	// Limit is the `Limit#Limit`.
+	Limit int
+	// Remaining is the number of the requests that can be sent right now.
+	Remaining int
+	// RetryAfter is the time that the client should wait before the next request, zero if allowed.
+	RetryAfter time.Duration
+	// Reset is the time until the limit is fully restored.
+	Reset time.Duration
+}
+
+// rate returns the refill rate of the token bucket, in tokens per nanosecond.
+func (l Limit) rate() float64 {
+	return float64(l.Limit) / float64(l.Period)
+}
+
+// TokenBucketResult returns the result of a token bucket,
+// the "tokens" are the remaining tokens after the request.
+// It's used by the `Store` implementations.
+func (l Limit) TokenBucketResult(allowed bool, tokens float64) Result {
+	r := Result{Allowed: allowed, Limit: l.Limit, Remaining: int(math.Floor(tokens))}
+	if !allowed {
+		r.RetryAfter = time.Duration(math.Ceil((1 - tokens) / l.rate()))
+	}
+	r.Reset = time.Duration(math.Ceil((float64(l.Burst) - tokens) / l.rate()))
+	return r
+}
+
+// RefillTokens returns the tokens of a bucket which had the "tokens" before the "elapsed" time.
+// It's used by the `Store` implementations.
+func (l Limit) RefillTokens(tokens float64, elapsed time.Duration) float64 {
+	if elapsed > 0 {
+		tokens += float64(elapsed) * l.rate()
+	}
+	return math.Min(float64(l.Burst), tokens)
+}
+
+// SlidingWindowWeight returns the weight of the previous window's count at the "now" time,
+// and the elapsed time of the current window, which starts at a multiple of the `Period`.
+// It's used by the `Store` implementations.
+func (l Limit) SlidingWindowWeight(now time.Time) (weight float64, elapsed time.Duration) {
+	elapsed = time.Duration(now.UnixNano() % int64(l.Period))
+	return 1 - float64(elapsed)/float64(l.Period), elapsed
+}
+
+// SlidingWindowResult returns the result of a sliding window, the "current" and the "previous"
+// are the counts of the current, including the request if allowed, and the previous windows.
+// It's used by the `Store` implementations.
+func (l Limit) SlidingWindowResult(allowed bool, current, previous int, now time.Time) Result {
+	weight, elapsed := l.SlidingWindowWeight(now)
+	estimated := float64(previous)*weight + float64(current)
+
+	r := Result{Allowed: allowed, Limit: l.Limit, Remaining: int(math.Max(0, math.Floor(float64(l.Limit)-estimated)))}
+	untilNextWindow := l.Period - elapsed
+	if !allowed {
+		// the time until the weighted previous count leaves enough room for one more request.
+		// in the next window the current count is weighted as the previous one.
+		r.RetryAfter = untilNextWindow
+		if current > 0 {
+			r.RetryAfter += time.Duration((1 - float64(l.Limit-1)/float64(current)) * float64(l.Period))
+		}
+		if previous > 0 && current < l.Limit {
+			need := float64(l.Limit-1-current) / float64(previous)
+			if wait := time.Duration((1-need)*float64(l.Period)) - elapsed; wait < untilNextWindow {
+				r.RetryAfter = wait
+			}
+		}
+		if r.RetryAfter < 0 {
+			r.RetryAfter = 0
+		}
+	}
+
+	r.Reset = untilNextWindow
+	if current > 0 {
+		// the current window becomes the previous one and it's weighted until the end of the next one.
+		r.Reset += l.Period
+	}
+	return r
+}
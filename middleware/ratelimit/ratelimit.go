@@ -0,0 +1,130 @@
+// Package ratelimit provides a rate limiting middleware, with the token bucket and the sliding window algorithms,
+// the clients are keyed by their IP, a request header, a claim or a custom function.
+//
+// The "X-RateLimit-Limit", "X-RateLimit-Remaining" and "X-RateLimit-Reset" (in seconds) response headers
+// are sent on each request and the "Retry-After" (in seconds) on the rejected ones,
+// the rejected requests stop the execution and fire the error code handler of the 429 (Too Many Requests).
+//
+// Each Party can register its own limits, the state is kept in memory or in a shared store, see `Store`.
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// KeyFunc returns the client's key of a request, see `Config#Key`.
+type KeyFunc func(ctx context.Context) string
+
+// ByRemoteAddr is the `KeyFunc` which keys the clients by their IP, see `Context#RemoteAddr`.
+func ByRemoteAddr(ctx context.Context) string {
+	return ctx.RemoteAddr()
+}
+
+// ByHeader returns a `KeyFunc` which keys the clients by the value of the "name" request header,
+// i.e an API key, the IP is used when the header is missing.
+func ByHeader(name string) KeyFunc {
+	return func(ctx context.Context) string {
+		if v := ctx.GetHeader(name); v != "" {
+			return "h:" + v
+		}
+		return ctx.RemoteAddr()
+	}
+}
+
+// ByClaim returns a `KeyFunc` which keys the authenticated clients by the "name" claim, i.e "sub",
+// see `context.GetClaims`, the IP is used when the client is not authenticated.
+func ByClaim(name string) KeyFunc {
+	return func(ctx context.Context) string {
+		if v := context.GetClaims(ctx).GetString(name); v != "" {
+			return "c:" + v
+		}
+		return ctx.RemoteAddr()
+	}
+}
+
+// New returns a new rate limiting middleware based on the "c" configs,
+// the default configs are used if "c" is missing.
+//
+// Usage:
+// app.Use(ratelimit.New())
+// api := app.Party("/api", ratelimit.New(ratelimit.Config{Limit: 10, Period: time.Second, Key: ratelimit.ByHeader("X-API-Key")}))
+func New(c ...Config) context.Handler {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		config = c[0]
+		def := DefaultConfig()
+		if config.Limit <= 0 {
+			config.Limit = def.Limit
+		}
+		if config.Period <= 0 {
+			config.Period = def.Period
+		}
+	}
+
+	if config.Burst <= 0 {
+		config.Burst = config.Limit
+	}
+
+	if config.Key == nil {
+		config.Key = ByRemoteAddr
+	}
+
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+
+	if config.OnLimit == nil {
+		config.OnLimit = func(ctx context.Context, result Result) {
+			ctx.StatusCode(http.StatusTooManyRequests)
+		}
+	}
+
+	if config.OnError == nil {
+		config.OnError = func(ctx context.Context, err error) {
+			context.Logger(ctx).Warnf("ratelimit: %v", err)
+		}
+	}
+
+	limit := Limit{
+		Algorithm: config.Algorithm,
+		Limit:     config.Limit,
+		Period:    config.Period,
+		Burst:     config.Burst,
+	}
+	prefix := config.Scope + ":" + config.Algorithm.String() + ":"
+
+	return func(ctx context.Context) {
+		result, err := config.Store.Take(prefix+config.Key(ctx), limit, time.Now())
+		if err != nil {
+			config.OnError(ctx, err)
+			if !ctx.IsStopped() {
+				ctx.Next()
+			}
+			return
+		}
+
+		h := ctx.ResponseWriter().Header()
+		h.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		h.Set("X-RateLimit-Reset", seconds(result.Reset))
+
+		if !result.Allowed {
+			h.Set("Retry-After", seconds(result.RetryAfter))
+			ctx.StopExecution()
+			config.OnLimit(ctx, result)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// seconds returns the "d" in seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,121 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/middleware/ratelimit"
+)
+
+type takeTest struct {
+	at         time.Duration
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+}
+
+func testTake(t *testing.T, s ratelimit.Store, limit ratelimit.Limit, tests []takeTest) {
+	t.Helper()
+
+	// a multiple of the periods, the start of a window.
+	start := time.Unix(1000, 0)
+	for i, tt := range tests {
+		r, err := s.Take("client", limit, start.Add(tt.at))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if r.Allowed != tt.allowed || r.Remaining != tt.remaining || r.RetryAfter != tt.retryAfter {
+			t.Fatalf("[%d] at %s: expected allowed: %t, remaining: %d and retry after: %s but got %+v",
+				i, tt.at, tt.allowed, tt.remaining, tt.retryAfter, r)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	limit := ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Limit: 2, Period: time.Second, Burst: 3}
+
+	testTake(t, ratelimit.NewMemoryStore(), limit, []takeTest{
+		{0, true, 2, 0},
+		{0, true, 1, 0},
+		{0, true, 0, 0},
+		// a token per 500ms.
+		{0, false, 0, 500 * time.Millisecond},
+		{250 * time.Millisecond, false, 0, 250 * time.Millisecond},
+		{500 * time.Millisecond, true, 0, 0},
+		// refilled up to the burst.
+		{10 * time.Second, true, 2, 0},
+	})
+
+	// shorter than a millisecond.
+	limit = ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Limit: 1, Period: 500 * time.Microsecond, Burst: 1}
+	testTake(t, ratelimit.NewMemoryStore(), limit, []takeTest{
+		{0, true, 0, 0},
+		{0, false, 0, 500 * time.Microsecond},
+		{500 * time.Microsecond, true, 0, 0},
+	})
+}
+
+func TestSlidingWindow(t *testing.T) {
+	limit := ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Limit: 2, Period: time.Second}
+
+	testTake(t, ratelimit.NewMemoryStore(), limit, []takeTest{
+		{0, true, 1, 0},
+		{100 * time.Millisecond, true, 0, 0},
+		// until the next window and until the current count, weighted as the previous one, leaves room:
+		// 800ms + (1 - 1/2) * 1s.
+		{200 * time.Millisecond, false, 0, 1300 * time.Millisecond},
+		// the previous count is weighted by 0.6, 2 * 0.6 + 1 > 2, room at 0.5: 500ms - 400ms.
+		{1400 * time.Millisecond, false, 0, 100 * time.Millisecond},
+		{1500 * time.Millisecond, true, 0, 0},
+		// the previous window is not adjacent.
+		{3500 * time.Millisecond, true, 1, 0},
+	})
+}
+
+func TestMemoryStorePurge(t *testing.T) {
+	s := ratelimit.NewMemoryStore()
+	s.PurgeInterval = time.Second
+
+	limit := ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Limit: 1, Period: time.Second, Burst: 1}
+	now := time.Now()
+	s.Take("a", limit, now)
+	s.Take("b", limit, now)
+	if expected, got := 2, s.Len(); expected != got {
+		t.Fatalf("expected %d clients but got %d", expected, got)
+	}
+
+	// both are refilled, "a" is the new one.
+	s.Take("a", limit, now.Add(2*time.Second))
+	if expected, got := 1, s.Len(); expected != got {
+		t.Fatalf("expected %d client after the purge but got %d", expected, got)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	app := iris.New()
+	app.Use(ratelimit.New(ratelimit.Config{Limit: 2, Period: time.Minute, Key: ratelimit.ByHeader("X-API-Key")}))
+	app.Get("/", func(ctx iris.Context) {
+		ctx.WriteString("ok")
+	})
+	app.OnErrorCode(iris.StatusTooManyRequests, func(ctx iris.Context) {
+		ctx.WriteString("slow down")
+	})
+
+	e := httptest.New(t, app)
+
+	r := e.GET("/").WithHeader("X-API-Key", "a").Expect().Status(httptest.StatusOK)
+	r.Header("X-RateLimit-Limit").Equal("2")
+	r.Header("X-RateLimit-Remaining").Equal("1")
+	r.Header("X-RateLimit-Reset").Equal("30")
+	e.GET("/").WithHeader("X-API-Key", "a").Expect().Status(httptest.StatusOK).Header("X-RateLimit-Remaining").Equal("0")
+
+	r = e.GET("/").WithHeader("X-API-Key", "a").Expect().Status(httptest.StatusTooManyRequests)
+	r.Header("Retry-After").Equal("30")
+	r.Body().Equal("slow down")
+
+	// another client.
+	e.GET("/").WithHeader("X-API-Key", "b").Expect().Status(httptest.StatusOK).Body().Equal("ok")
+}
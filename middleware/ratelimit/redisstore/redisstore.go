@@ -0,0 +1,114 @@
+// Package redisstore provides a Redis `ratelimit.Store`, so the limits are shared between the servers.
+// The state of each client is updated atomically through Lua scripts.
+package redisstore
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/kataras/iris/middleware/ratelimit"
+)
+
+// KEYS[1] the bucket, ARGV: the rate (tokens per microsecond), the burst, the now (in microseconds)
+// and the ttl (in milliseconds).
+var tokenBucketScript = redis.NewScript(1, `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 't', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate)
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HMSET', KEYS[1], 't', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return {allowed, tostring(tokens)}
+`)
+
+// KEYS[1] the current window, KEYS[2] the previous window, ARGV: the limit, the weight of the previous window
+// and the ttl (in milliseconds).
+var slidingWindowScript = redis.NewScript(2, `
+local limit = tonumber(ARGV[1])
+local weight = tonumber(ARGV[2])
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+if previous * weight + current + 1 > limit then
+	return {0, current, previous}
+end
+current = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {1, current, previous}
+`)
+
+// Store is the Redis `ratelimit.Store`.
+type Store struct {
+	pool   *redis.Pool
+	prefix string
+}
+
+var _ ratelimit.Store = (*Store)(nil)
+
+// New returns a new Redis store which uses the "pool" connections,
+// the keys are prefixed by the "prefix", i.e "ratelimit:".
+//
+// Usage:
+// pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", "127.0.0.1:6379") }}
+// app.Use(ratelimit.New(ratelimit.Config{Store: redisstore.New(pool, "ratelimit:")}))
+func New(pool *redis.Pool, prefix string) *Store {
+	return &Store{pool: pool, prefix: prefix}
+}
+
+// Take consumes a request of the "key" client atomically.
+func (s *Store) Take(key string, limit ratelimit.Limit, now time.Time) (ratelimit.Result, error) {
+	c := s.pool.Get()
+	defer c.Close()
+
+	if limit.Algorithm == ratelimit.SlidingWindow {
+		weight, elapsed := limit.SlidingWindowWeight(now)
+		window := (now.UnixNano() - int64(elapsed)) / int64(limit.Period)
+		current := s.prefix + key + ":" + strconv.FormatInt(window, 10)
+		previous := s.prefix + key + ":" + strconv.FormatInt(window-1, 10)
+
+		values, err := redis.Ints(slidingWindowScript.Do(c, current, previous,
+			limit.Limit, strconv.FormatFloat(weight, 'f', -1, 64), milliseconds(2*limit.Period)))
+		if err != nil {
+			return ratelimit.Result{}, err
+		}
+
+		return limit.SlidingWindowResult(values[0] == 1, values[1], values[2], now), nil
+	}
+
+	// not per millisecond, the period may be shorter than that.
+	rate := float64(limit.Limit) * float64(time.Microsecond) / float64(limit.Period)
+	values, err := redis.Values(tokenBucketScript.Do(c, s.prefix+key,
+		strconv.FormatFloat(rate, 'f', -1, 64), limit.Burst, now.UnixNano()/int64(time.Microsecond),
+		milliseconds(time.Duration(float64(limit.Burst)/float64(limit.Limit)*float64(limit.Period))+time.Second)))
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	allowed, err := redis.Int(values[0], nil)
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	tokens, err := redis.Float64(values[1], nil)
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	return limit.TokenBucketResult(allowed == 1, tokens), nil
+}
+
+func milliseconds(d time.Duration) int64 {
+	if ms := int64(d / time.Millisecond); ms > 0 {
+		return ms
+	}
+	return 1
+}
@@ -0,0 +1,127 @@
+package redisstore_test
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/kataras/iris/middleware/ratelimit"
+	"github.com/kataras/iris/middleware/ratelimit/redisstore"
+)
+
+// scriptConn records the arguments of the scripts and replies with the "reply".
+type scriptConn struct {
+	args  []interface{}
+	reply interface{}
+}
+
+func (c *scriptConn) Close() error { return nil }
+func (c *scriptConn) Err() error   { return nil }
+func (c *scriptConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	if commandName != "EVALSHA" {
+		// i.e the reset of the pool.
+		return nil, nil
+	}
+
+	c.args = args
+	return c.reply, nil
+}
+func (c *scriptConn) Send(commandName string, args ...interface{}) error { return nil }
+func (c *scriptConn) Flush() error                                       { return nil }
+func (c *scriptConn) Receive() (interface{}, error)                      { return nil, nil }
+
+func newPool(dial func() (redis.Conn, error)) *redis.Pool {
+	return &redis.Pool{Dial: dial}
+}
+
+func TestTokenBucketArgs(t *testing.T) {
+	c := &scriptConn{reply: []interface{}{int64(0), []byte("0.25")}}
+	s := redisstore.New(newPool(func() (redis.Conn, error) { return c, nil }), "ratelimit:")
+
+	// shorter than a millisecond.
+	limit := ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Limit: 1, Period: 500 * time.Microsecond, Burst: 1}
+	now := time.Unix(1000, 0)
+	r, err := s.Take("client", limit, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the hash, the number of the keys, the key, the rate, the burst, the now and the ttl.
+	if expected, got := "ratelimit:client", c.args[2]; expected != got {
+		t.Fatalf("expected the key %q but got %v", expected, got)
+	}
+
+	rate, err := strconv.ParseFloat(c.args[3].(string), 64)
+	if err != nil || math.IsInf(rate, 0) || rate != 0.002 {
+		t.Fatalf("expected the rate of 0.002 tokens per microsecond but got %v", c.args[3])
+	}
+	if expected, got := now.UnixNano()/int64(time.Microsecond), c.args[5]; expected != got {
+		t.Fatalf("expected the now in microseconds %d but got %v", expected, got)
+	}
+
+	if r.Allowed || r.RetryAfter != 375*time.Microsecond {
+		t.Fatalf("expected the request to be rejected for 375µs but got %+v", r)
+	}
+}
+
+func TestSlidingWindowArgs(t *testing.T) {
+	c := &scriptConn{reply: []interface{}{int64(1), int64(1), int64(2)}}
+	s := redisstore.New(newPool(func() (redis.Conn, error) { return c, nil }), "ratelimit:")
+
+	limit := ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Limit: 4, Period: time.Second}
+	now := time.Unix(1000, int64(250*time.Millisecond))
+	r, err := s.Take("client", limit, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the hash, the number of the keys, the current and the previous windows, the limit, the weight and the ttl.
+	if expected, got := "ratelimit:client:1000", c.args[2]; expected != got {
+		t.Fatalf("expected the current window %q but got %v", expected, got)
+	}
+	if expected, got := "ratelimit:client:999", c.args[3]; expected != got {
+		t.Fatalf("expected the previous window %q but got %v", expected, got)
+	}
+	if expected, got := "0.75", c.args[5]; expected != got {
+		t.Fatalf("expected the weight %q but got %v", expected, got)
+	}
+
+	// 2 * 0.75 + 1.
+	if !r.Allowed || r.Remaining != 1 {
+		t.Fatalf("expected the request to be allowed with 1 remaining but got %+v", r)
+	}
+}
+
+// TestStore runs the scripts on the Redis server of the REDIS_ADDR, i.e "127.0.0.1:6379", it's skipped if missing.
+func TestStore(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR is not set")
+	}
+
+	pool := newPool(func() (redis.Conn, error) { return redis.Dial("tcp", addr) })
+	defer pool.Close()
+
+	prefix := "iris-ratelimit-test:" + strconv.FormatInt(time.Now().UnixNano(), 10) + ":"
+	s := redisstore.New(pool, prefix)
+	now := time.Now()
+
+	for _, limit := range []ratelimit.Limit{
+		{Algorithm: ratelimit.TokenBucket, Limit: 2, Period: time.Minute, Burst: 2},
+		{Algorithm: ratelimit.SlidingWindow, Limit: 2, Period: time.Minute},
+	} {
+		key := limit.Algorithm.String()
+		for i, expected := range []bool{true, true, false} {
+			r, err := s.Take(key, limit, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Allowed != expected {
+				t.Fatalf("%s: [%d] expected allowed: %t but got %+v", key, i, expected, r)
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Store keeps the rate limiting state of the clients, see `Config#Store`.
+type Store interface {
+	// Take consumes a request of the "key" client, based on its "limit", at the "now" time.
+	// It should be atomic.
+	Take(key string, limit Limit, now time.Time) (Result, error)
+}
+
+type memoryEntry struct {
+	// the tokens of the token bucket or the count of the current window.
+	tokens   float64
+	previous int
+	// the last refill time of the token bucket or the start of the current window.
+	last    time.Time
+	expires time.Time
+}
+
+// MemoryStore is the in-memory `Store`,
+// the idle clients are removed periodically.
+type MemoryStore struct {
+	mu         sync.Mutex
+	entries    map[string]*memoryEntry
+	lastPurged time.Time
+	// PurgeInterval is the minimum interval between the removals of the idle clients.
+	PurgeInterval time.Duration
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns a new, empty, in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:       make(map[string]*memoryEntry),
+		lastPurged:    time.Now(),
+		PurgeInterval: time.Minute,
+	}
+}
+
+// Take consumes a request of the "key" client.
+func (s *MemoryStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastPurged) >= s.PurgeInterval {
+		for k, e := range s.entries {
+			if !e.expires.After(now) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastPurged = now
+	}
+
+	e, ok := s.entries[key]
+	if !ok || !e.expires.After(now) {
+		e = &memoryEntry{last: now}
+		if limit.Algorithm == TokenBucket {
+			e.tokens = float64(limit.Burst)
+		} else {
+			_, elapsed := limit.SlidingWindowWeight(now)
+			e.last = now.Add(-elapsed)
+		}
+		s.entries[key] = e
+	}
+
+	if limit.Algorithm == SlidingWindow {
+		return s.takeWindow(e, limit, now), nil
+	}
+
+	e.tokens = limit.RefillTokens(e.tokens, now.Sub(e.last))
+	e.last = now
+	allowed := e.tokens >= 1
+	if allowed {
+		e.tokens--
+	}
+
+	r := limit.TokenBucketResult(allowed, e.tokens)
+	e.expires = now.Add(r.Reset)
+	return r, nil
+}
+
+func (s *MemoryStore) takeWindow(e *memoryEntry, limit Limit, now time.Time) Result {
+	weight, elapsed := limit.SlidingWindowWeight(now)
+	start := now.Add(-elapsed)
+	if start.After(e.last) {
+		// a new window, the current count becomes the previous one if they're adjacent.
+		if start.Sub(e.last) == limit.Period {
+			e.previous = int(e.tokens)
+		} else {
+			e.previous = 0
+		}
+		e.tokens, e.last = 0, start
+	}
+
+	current := int(e.tokens)
+	allowed := float64(e.previous)*weight+float64(current)+1 <= float64(limit.Limit)
+	if allowed {
+		current++
+		e.tokens++
+	}
+
+	e.expires = start.Add(2 * limit.Period)
+	return limit.SlidingWindowResult(allowed, current, e.previous, now)
+}
+
+// Len returns the number of the clients, including the idle ones which are not removed yet.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	n := len(s.entries)
+	s.mu.Unlock()
+	return n
+}
@@ -46,7 +46,9 @@ func New() context.Handler {
 				logMessage += fmt.Sprintf("At Request: %s\n", getRequestLogs(ctx))
 				logMessage += fmt.Sprintf("Trace: %s\n", err)
 				logMessage += fmt.Sprintf("\n%s", stacktrace)
-				ctx.Application().Logger().Warn(logMessage)
+				context.Logger(ctx).Warn(logMessage)
+				// the 500 marks the request's span as failed, record the panic too.
+				context.GetSpan(ctx).RecordError(fmt.Errorf("recovered from a panic: %v", err))
 
 				ctx.StatusCode(500)
 				ctx.StopExecution()
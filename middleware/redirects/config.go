@@ -0,0 +1,23 @@
+package redirects
+
+import "net/http"
+
+// Config the configs for the redirects table.
+type Config struct {
+	// Loader loads the redirect rules, i.e from a file (see `FileLoader`) or a database,
+	// it's called by `New` and on each `Redirects#Load`.
+	//
+	// Defaults to nil, the rules can be added through the `Redirects#Add`.
+	Loader Loader
+	// StatusCode is the status code of the rules without one.
+	//
+	// Defaults to 301 (Moved Permanently).
+	StatusCode int
+}
+
+// DefaultConfig returns the default configs for the redirects table.
+func DefaultConfig() Config {
+	return Config{
+		StatusCode: http.StatusMovedPermanently,
+	}
+}
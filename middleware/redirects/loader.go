@@ -0,0 +1,69 @@
+package redirects
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+var errInvalidRecord = errors.New("redirects: %s: invalid record %v")
+
+// FileLoader returns a `Loader` of the "filename",
+// a JSON file (".json" extension) of an array of `Rule`s
+// or a CSV file of "from,to[,status]" records, the lines starting with '#' are comments.
+func FileLoader(filename string) Loader {
+	return func() ([]Rule, error) {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		if strings.ToLower(filepath.Ext(filename)) == ".json" {
+			var rules []Rule
+			if err = json.NewDecoder(f).Decode(&rules); err != nil {
+				return nil, err
+			}
+			return rules, nil
+		}
+
+		return readCSV(filename, f)
+	}
+}
+
+func readCSV(filename string, r io.Reader) ([]Rule, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var rules []Rule
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rules, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record) < 2 || len(record) > 3 {
+			return nil, errInvalidRecord.Format(filename, record)
+		}
+
+		rule := Rule{From: record[0], To: record[1]}
+		if len(record) == 3 && strings.TrimSpace(record[2]) != "" {
+			if rule.StatusCode, err = strconv.Atoi(strings.TrimSpace(record[2])); err != nil {
+				return nil, errInvalidRecord.Format(filename, record)
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+}
@@ -0,0 +1,370 @@
+// Package redirects provides a declarative table of old path to new path redirects,
+// loaded from a CSV or JSON file or a custom loader (i.e a database) with hot reload,
+// which is served by a router wrapper before the routing.
+// Useful for CMS-style sites and site migrations.
+package redirects
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/kataras/iris/core/errors"
+	"github.com/kataras/iris/core/host"
+	"github.com/kataras/iris/core/router"
+)
+
+// Rule is a redirect rule of the table.
+//
+// The "From" is a request path, it may contain "{name}" placeholders of a single path segment
+// and a final "{name:path}" placeholder of the rest of the path,
+// i.e "/blog/{year}/{slug}" or "/docs/{rest:path}".
+// The "To" is the redirect target, it may contain the placeholders of the "From",
+// i.e "/posts/{slug}?year={year}" or "https://docs.example.com/{rest}".
+type Rule struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	StatusCode int    `json:"status,omitempty"`
+}
+
+// Loader loads the redirect rules, see `FileLoader`.
+type Loader func() ([]Rule, error)
+
+var (
+	errInvalidRule       = errors.New("redirects: invalid rule '%s' -> '%s'")
+	errInvalidStatusCode = errors.New("redirects: invalid status code %d of '%s'")
+)
+
+// pattern is a parsed rule's "From" with placeholders.
+type pattern struct {
+	Rule
+	segments []string
+	// wildcard is the name of the final "{name:path}" placeholder, if any.
+	wildcard string
+}
+
+func (p *pattern) match(segments []string) (map[string]string, bool) {
+	if len(segments) < len(p.segments) || (p.wildcard == "" && len(segments) != len(p.segments)) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, s := range p.segments {
+		if name, ok := placeholder(s); ok {
+			if segments[i] == "" {
+				return nil, false
+			}
+			params[name] = segments[i]
+			continue
+		}
+
+		if s != segments[i] {
+			return nil, false
+		}
+	}
+
+	if p.wildcard != "" {
+		rest := segments[len(p.segments):]
+		for _, s := range rest {
+			// an empty segment, i.e "/docs//evil.com", would make the "/{rest}"
+			// a protocol-relative url of a foreign host.
+			if s == "" {
+				return nil, false
+			}
+		}
+		params[p.wildcard] = strings.Join(rest, "/")
+	}
+
+	return params, true
+}
+
+// placeholder returns the name of the "{name}" segment.
+func placeholder(segment string) (string, bool) {
+	if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+		return segment[1 : len(segment)-1], true
+	}
+
+	return "", false
+}
+
+// isLocal reports whether the "to" is a path of the same host.
+func isLocal(to string) bool {
+	to = strings.Replace(to, "\\", "/", -1)
+	return strings.HasPrefix(to, "/") && !strings.HasPrefix(to, "//")
+}
+
+func cleanPath(path string) string {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	return path
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
+
+// Redirects is the redirects table, see `New`.
+//
+// Its rules can be changed at serve-time,
+// the exact paths are matched first and then the patterns, in the order they were added.
+// 声明式的重定向表(旧路径->新路径), 支持从文件或数据库加载以及热加载
+type Redirects struct {
+	config Config
+
+	mu       sync.RWMutex
+	exact    map[string]Rule
+	patterns []*pattern
+}
+
+// New returns a new redirects table and loads its rules through the `Config#Loader`, if any.
+//
+// Usage:
+// r, err := redirects.New(redirects.Config{Loader: redirects.FileLoader("./redirects.csv")})
+// r.Watch("./redirects.csv")
+// app.WrapRouter(r.Wrapper())
+func New(c ...Config) (*Redirects, error) {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		config = c[0]
+		if config.StatusCode == 0 {
+			config.StatusCode = DefaultConfig().StatusCode
+		}
+	}
+
+	r := &Redirects{
+		config: config,
+		exact:  make(map[string]Rule),
+	}
+
+	if config.Loader != nil {
+		if err := r.Load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func (r *Redirects) compile(rule Rule) (Rule, *pattern, error) {
+	rule.From, rule.To = strings.TrimSpace(rule.From), strings.TrimSpace(rule.To)
+	if rule.StatusCode == 0 {
+		rule.StatusCode = r.config.StatusCode
+	}
+
+	switch rule.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		if rule.To == "" {
+			return rule, nil, errInvalidRule.Format(rule.From, rule.To)
+		}
+	case http.StatusGone:
+		// the removed pages have no target.
+	default:
+		return rule, nil, errInvalidStatusCode.Format(rule.StatusCode, rule.From)
+	}
+
+	if !strings.HasPrefix(rule.From, "/") {
+		return rule, nil, errInvalidRule.Format(rule.From, rule.To)
+	}
+	rule.From = cleanPath(rule.From)
+
+	if !strings.Contains(rule.From, "{") {
+		return rule, nil, nil
+	}
+
+	p := &pattern{Rule: rule, segments: splitPath(rule.From)}
+	if last := p.segments[len(p.segments)-1]; strings.HasSuffix(last, ":path}") {
+		p.wildcard = strings.TrimSuffix(strings.TrimPrefix(last, "{"), ":path}")
+		p.segments = p.segments[:len(p.segments)-1]
+	}
+
+	return rule, p, nil
+}
+
+// Load replaces the rules with the ones of the `Config#Loader`,
+// the current rules are kept if the loader fails or a rule is invalid.
+func (r *Redirects) Load() error {
+	if r.config.Loader == nil {
+		return nil
+	}
+
+	rules, err := r.config.Loader()
+	if err != nil {
+		return err
+	}
+
+	exact := make(map[string]Rule, len(rules))
+	var patterns []*pattern
+	for _, rule := range rules {
+		rule, p, err := r.compile(rule)
+		if err != nil {
+			return err
+		}
+
+		if p != nil {
+			patterns = append(patterns, p)
+		} else {
+			exact[rule.From] = rule
+		}
+	}
+
+	r.mu.Lock()
+	r.exact = exact
+	r.patterns = patterns
+	r.mu.Unlock()
+	return nil
+}
+
+// Add adds or replaces the rule of the "from" path at serve-time,
+// a zero "statusCode" defaults to the `Config#StatusCode`.
+func (r *Redirects) Add(from, to string, statusCode int) error {
+	rule, p, err := r.compile(Rule{From: from, To: to, StatusCode: statusCode})
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p == nil {
+		r.exact[rule.From] = rule
+		return nil
+	}
+
+	for i, existing := range r.patterns {
+		if existing.From == rule.From {
+			r.patterns[i] = p
+			return nil
+		}
+	}
+	r.patterns = append(r.patterns, p)
+	return nil
+}
+
+// Remove removes the rule of the "from" path at serve-time,
+// it reports whether the rule existed.
+func (r *Redirects) Remove(from string) bool {
+	from = cleanPath(strings.TrimSpace(from))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.exact[from]; ok {
+		delete(r.exact, from)
+		return true
+	}
+
+	for i, p := range r.patterns {
+		if p.From == from {
+			r.patterns = append(r.patterns[:i:i], r.patterns[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rules returns a copy of the current rules, the exact ones are not sorted.
+func (r *Redirects) Rules() []Rule {
+	r.mu.RLock()
+	rules := make([]Rule, 0, len(r.exact)+len(r.patterns))
+	for _, rule := range r.exact {
+		rules = append(rules, rule)
+	}
+	for _, p := range r.patterns {
+		rules = append(rules, p.Rule)
+	}
+	r.mu.RUnlock()
+
+	return rules
+}
+
+// Match returns the redirect target and the status code of the "path",
+// the "ok" is false if no rule matches it.
+func (r *Redirects) Match(path string) (to string, statusCode int, ok bool) {
+	path = cleanPath(path)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if rule, found := r.exact[path]; found {
+		return rule.To, rule.StatusCode, true
+	}
+
+	if len(r.patterns) == 0 {
+		return
+	}
+
+	segments := splitPath(path)
+	for _, p := range r.patterns {
+		params, found := p.match(segments)
+		if !found {
+			continue
+		}
+
+		to = p.To
+		for name, value := range params {
+			to = strings.Replace(to, "{"+name+"}", value, -1)
+		}
+
+		// a local target should stay local whatever the request path's values are,
+		// browsers treat the "/\\evil.com" as the "//evil.com".
+		if isLocal(p.To) && !isLocal(to) {
+			continue
+		}
+		return to, p.StatusCode, true
+	}
+
+	return
+}
+
+// Wrapper returns the router wrapper which serves the redirects before the routing,
+// the request's query is kept when the target has no query.
+//
+// Usage:
+// app.WrapRouter(r.Wrapper())
+func (r *Redirects) Wrapper() router.WrapperFunc {
+	return func(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		to, statusCode, ok := r.Match(req.URL.Path)
+		if !ok {
+			next(w, req)
+			return
+		}
+
+		if statusCode == http.StatusGone {
+			w.WriteHeader(statusCode)
+			return
+		}
+
+		if req.URL.RawQuery != "" && !strings.Contains(to, "?") {
+			to += "?" + req.URL.RawQuery
+		}
+
+		http.Redirect(w, req, to, statusCode)
+	}
+}
+
+// Watch reloads the rules on each change of the "filename", i.e the `FileLoader`'s one,
+// the errors are passed to the optional "onError".
+// Call the returned watcher's `Stop` to stop watching.
+func (r *Redirects) Watch(filename string, onError ...func(error)) (*host.Watcher, error) {
+	w := host.NewWatcher([]string{filename}, func(evt host.WatchEvent) {
+		if evt.Op == host.WatchRemove {
+			return
+		}
+
+		if err := r.Load(); err != nil {
+			for _, fn := range onError {
+				fn(err)
+			}
+		}
+	})
+
+	if err := w.Start(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
@@ -0,0 +1,97 @@
+package redirects_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/middleware/redirects"
+)
+
+func TestRedirects(t *testing.T) {
+	r, err := redirects.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := []redirects.Rule{
+		{From: "/old", To: "/new"},
+		{From: "/blog/{year}/{slug}", To: "/posts/{slug}?year={year}", StatusCode: iris.StatusFound},
+		{From: "/docs/{rest:path}", To: "/{rest}"},
+		{From: "/removed", StatusCode: iris.StatusGone},
+	}
+	for _, rule := range rules {
+		if err = r.Add(rule.From, rule.To, rule.StatusCode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wrapper := r.Wrapper()
+	next := func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("not redirected"))
+	}
+
+	tests := []struct {
+		path       string
+		statusCode int
+		location   string
+	}{
+		{"/old?q=1", iris.StatusMovedPermanently, "/new?q=1"},
+		{"/blog/2019/hello", iris.StatusFound, "/posts/hello?year=2019"},
+		{"/docs/a/b", iris.StatusMovedPermanently, "/a/b"},
+		{"/removed", iris.StatusGone, ""},
+		{"/other", iris.StatusOK, ""},
+	}
+
+	for i, tt := range tests {
+		w := httptest.NewRecorder()
+		wrapper(w, httptest.NewRequest(iris.MethodGet, tt.path, nil), next)
+
+		if w.Code != tt.statusCode {
+			t.Fatalf("[%d] %s: expected status code %d but got %d", i, tt.path, tt.statusCode, w.Code)
+		}
+
+		if location := w.Header().Get("Location"); location != tt.location {
+			t.Fatalf("[%d] %s: expected location '%s' but got '%s'", i, tt.path, tt.location, location)
+		}
+	}
+}
+
+func TestRedirectsOpenRedirect(t *testing.T) {
+	r, err := redirects.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = r.Add("/docs/{rest:path}", "/{rest}", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Add("/u/{name}", "/{name}", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{
+		"/docs//evil.com",
+		"/docs//evil.com/path",
+		"/docs/a//evil.com",
+		"/docs/\\evil.com",
+		"/u/\\evil.com",
+	} {
+		if to, _, ok := r.Match(path); ok {
+			t.Fatalf("expected '%s' to not match but it redirects to '%s'", path, to)
+		}
+
+		w := httptest.NewRecorder()
+		r.Wrapper()(w, httptest.NewRequest(iris.MethodGet, "http://example.com"+path, nil), func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(iris.StatusNotFound)
+		})
+		if location := w.Header().Get("Location"); location != "" {
+			t.Fatalf("expected '%s' to not redirect but it redirects to '%s'", path, location)
+		}
+	}
+
+	if to, _, ok := r.Match("/docs/evil.com"); !ok || to != "/evil.com" {
+		t.Fatalf("expected '/docs/evil.com' to redirect to the local '/evil.com' but got '%s'", to)
+	}
+}
@@ -0,0 +1,49 @@
+package replay
+
+import (
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// Config the configs for the replay protection middleware.
+type Config struct {
+	// NonceHeader is the request header of the unique, per request, nonce.
+	//
+	// Defaults to "X-Nonce".
+	NonceHeader string
+	// TimestampHeader is the request header of the request's creation time,
+	// as unix seconds or RFC3339.
+	//
+	// Defaults to "X-Timestamp".
+	TimestampHeader string
+	// Window is the maximum difference between the request's timestamp and the server's time,
+	// the nonces are remembered for that long.
+	//
+	// Defaults to 5 minutes.
+	Window time.Duration
+	// Store remembers the used nonces.
+	//
+	// Defaults to a `NewMemoryStore`, use a shared store when there are more than one servers.
+	Store NonceStore
+	// Scope returns the namespace of the request's nonce, i.e the client's API key,
+	// so the clients cannot block each other's nonces.
+	//
+	// Defaults to nil, a single namespace.
+	Scope func(ctx context.Context) string
+	// OnReject is called when the request is rejected with one of the `ErrMissingHeaders`,
+	// `ErrInvalidTimestamp`, `ErrExpired` or `ErrReplayed` errors,
+	// the next handlers are not executed.
+	//
+	// Defaults to a handler which sends 401 Unauthorized.
+	OnReject func(ctx context.Context, err error)
+}
+
+// DefaultConfig returns the default configs for the replay protection middleware.
+func DefaultConfig() Config {
+	return Config{
+		NonceHeader:     "X-Nonce",
+		TimestampHeader: "X-Timestamp",
+		Window:          5 * time.Minute,
+	}
+}
@@ -0,0 +1,107 @@
+// Package replay provides a middleware which protects the API endpoints,
+// i.e the signed webhooks, from replayed requests,
+// each request should carry a unique nonce and its creation timestamp.
+// The requests which are too old or their nonce is already used are rejected.
+//
+// Note that the nonce and the timestamp should be signed by the client too,
+// otherwise an attacker can replay a request with a different nonce.
+package replay
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+)
+
+var (
+	// ErrMissingHeaders is passed to the `Config#OnReject` when the nonce or the timestamp is missing.
+	ErrMissingHeaders = errors.New("replay: missing nonce or timestamp")
+	// ErrInvalidTimestamp is passed to the `Config#OnReject` when the timestamp cannot be parsed.
+	ErrInvalidTimestamp = errors.New("replay: invalid timestamp")
+	// ErrExpired is passed to the `Config#OnReject` when the timestamp is out of the `Config#Window`.
+	ErrExpired = errors.New("replay: timestamp out of the allowed window")
+	// ErrReplayed is passed to the `Config#OnReject` when the nonce is already used.
+	ErrReplayed = errors.New("replay: nonce already used")
+)
+
+// New returns a new replay protection middleware based on the "c" configs,
+// the default configs are used if "c" is missing.
+//
+// Usage:
+// app.Post("/webhooks", verifySignature, replay.New(), handleWebhook)
+func New(c ...Config) context.Handler {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		config = c[0]
+		def := DefaultConfig()
+		if config.NonceHeader == "" {
+			config.NonceHeader = def.NonceHeader
+		}
+		if config.TimestampHeader == "" {
+			config.TimestampHeader = def.TimestampHeader
+		}
+		if config.Window <= 0 {
+			config.Window = def.Window
+		}
+	}
+
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+
+	if config.OnReject == nil {
+		config.OnReject = func(ctx context.Context, err error) {
+			ctx.StatusCode(http.StatusUnauthorized)
+		}
+	}
+
+	return func(ctx context.Context) {
+		if err := verify(ctx, config); err != nil {
+			ctx.StopExecution()
+			config.OnReject(ctx, err)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func verify(ctx context.Context, config Config) error {
+	nonce, timestamp := ctx.GetHeader(config.NonceHeader), ctx.GetHeader(config.TimestampHeader)
+	if nonce == "" || timestamp == "" {
+		return ErrMissingHeaders
+	}
+
+	t, err := parseTimestamp(timestamp)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	now := time.Now()
+	if d := now.Sub(t); d > config.Window || d < -config.Window {
+		return ErrExpired
+	}
+
+	if config.Scope != nil {
+		nonce = config.Scope(ctx) + ":" + nonce
+	}
+
+	// after the window the timestamp check rejects the request anyway.
+	if !config.Store.Use(nonce, t.Add(config.Window)) {
+		return ErrReplayed
+	}
+
+	return nil
+}
+
+// parseTimestamp parses unix seconds or RFC3339 times.
+func parseTimestamp(s string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,116 @@
+package replay_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/core/errors"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/middleware/replay"
+)
+
+func TestReplay(t *testing.T) {
+	var rejected error
+	app := iris.New()
+	app.Post("/webhooks", replay.New(replay.Config{
+		Window: time.Minute,
+		Scope: func(ctx iris.Context) string {
+			return ctx.GetHeader("X-API-Key")
+		},
+		OnReject: func(ctx iris.Context, err error) {
+			rejected = err
+			ctx.StatusCode(iris.StatusUnauthorized)
+		},
+	}), func(ctx iris.Context) {
+		ctx.WriteString("handled")
+	})
+
+	e := httptest.New(t, app)
+	now := time.Now()
+	unix := func(d time.Duration) string {
+		return strconv.FormatInt(now.Add(d).Unix(), 10)
+	}
+
+	tests := []struct {
+		apiKey    string
+		nonce     string
+		timestamp string
+		// accepted if nil.
+		err *errors.Error
+	}{
+		{"a", "1", unix(0), nil},
+		{"a", "1", unix(0), &replay.ErrReplayed},
+		// a different timestamp does not make it a new request.
+		{"a", "1", unix(time.Second), &replay.ErrReplayed},
+		{"a", "2", now.UTC().Format(time.RFC3339), nil},
+		// the nonces are scoped by the client.
+		{"b", "1", unix(0), nil},
+		// within the window, both ways.
+		{"a", "3", unix(-50 * time.Second), nil},
+		{"a", "4", unix(50 * time.Second), nil},
+		{"a", "5", unix(-2 * time.Minute), &replay.ErrExpired},
+		{"a", "6", unix(2 * time.Minute), &replay.ErrExpired},
+		{"a", "7", "yesterday", &replay.ErrInvalidTimestamp},
+		{"a", "", unix(0), &replay.ErrMissingHeaders},
+		{"a", "8", "", &replay.ErrMissingHeaders},
+		// the rejected ones are not used.
+		{"a", "5", unix(0), nil},
+	}
+
+	for i, tt := range tests {
+		rejected = nil
+		r := e.POST("/webhooks").WithHeader("X-API-Key", tt.apiKey).
+			WithHeader("X-Nonce", tt.nonce).WithHeader("X-Timestamp", tt.timestamp).Expect()
+
+		if tt.err == nil {
+			r.Status(httptest.StatusOK).Body().Equal("handled")
+			if rejected != nil {
+				t.Fatalf("[%d] expected the request to be accepted but got %v", i, rejected)
+			}
+			continue
+		}
+
+		r.Status(httptest.StatusUnauthorized).Body().NotEqual("handled")
+		if !tt.err.Equal(rejected) {
+			t.Fatalf("[%d] expected the %v error but got %v", i, tt.err, rejected)
+		}
+	}
+}
+
+func TestReplayDefaults(t *testing.T) {
+	app := iris.New()
+	app.Use(replay.New())
+	app.Post("/", func(ctx iris.Context) {})
+
+	e := httptest.New(t, app)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	e.POST("/").WithHeader("X-Nonce", "n").WithHeader("X-Timestamp", timestamp).Expect().Status(httptest.StatusOK)
+	e.POST("/").WithHeader("X-Nonce", "n").WithHeader("X-Timestamp", timestamp).Expect().Status(httptest.StatusUnauthorized)
+	// the default window is 5 minutes.
+	e.POST("/").WithHeader("X-Nonce", "m").WithHeader("X-Timestamp", strconv.FormatInt(time.Now().Add(-4*time.Minute).Unix(), 10)).
+		Expect().Status(httptest.StatusOK)
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := replay.NewMemoryStore()
+	s.PurgeInterval = 0
+
+	now := time.Now()
+	if !s.Use("a", now.Add(time.Minute)) || s.Use("a", now.Add(time.Minute)) {
+		t.Fatalf("expected the nonce to be used once")
+	}
+
+	// an expired nonce can be used again.
+	if !s.Use("b", now.Add(-time.Second)) || !s.Use("b", now.Add(time.Minute)) {
+		t.Fatalf("expected the expired nonce to be used again")
+	}
+
+	s.Use("c", now.Add(-time.Second))
+	// "c" is removed before "d" is added.
+	s.Use("d", now.Add(time.Minute))
+	if expected, got := 3, s.Len(); expected != got {
+		t.Fatalf("expected %d nonces after the purge but got %d", expected, got)
+	}
+}
@@ -0,0 +1,66 @@
+package replay
+
+import (
+	"sync"
+	"time"
+)
+
+// NonceStore remembers the used nonces, see `Config#Store`.
+type NonceStore interface {
+	// Use marks the "nonce" as used until the "expires" time,
+	// it reports false if it's already used and not expired yet.
+	// It should be atomic.
+	Use(nonce string, expires time.Time) bool
+}
+
+// MemoryStore is the in-memory `NonceStore`,
+// the expired nonces are removed periodically.
+type MemoryStore struct {
+	mu         sync.Mutex
+	nonces     map[string]time.Time
+	lastPurged time.Time
+	// PurgeInterval is the minimum interval between the removals of the expired nonces.
+	PurgeInterval time.Duration
+}
+
+// NewMemoryStore returns a new, empty, in-memory nonce store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		nonces:        make(map[string]time.Time),
+		lastPurged:    time.Now(),
+		PurgeInterval: time.Minute,
+	}
+}
+
+// Use marks the "nonce" as used until the "expires" time,
+// it reports false if it's already used and not expired yet.
+func (s *MemoryStore) Use(nonce string, expires time.Time) bool {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastPurged) >= s.PurgeInterval {
+		for k, exp := range s.nonces {
+			if !exp.After(now) {
+				delete(s.nonces, k)
+			}
+		}
+		s.lastPurged = now
+	}
+
+	if exp, ok := s.nonces[nonce]; ok && exp.After(now) {
+		return false
+	}
+
+	s.nonces[nonce] = expires
+	return true
+}
+
+// Len returns the number of the remembered nonces, including the expired ones which are not removed yet.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	n := len(s.nonces)
+	s.mu.Unlock()
+	return n
+}
@@ -0,0 +1,62 @@
+package sameorigin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+)
+
+// Config the configs for the same-origin validation middleware.
+type Config struct {
+	// AllowedOrigins are the origins, in addition to the application's one, which can send the state-changing requests,
+	// i.e "https://admin.example.com". A "*" can be used as the first label of the host, i.e "https://*.example.com".
+	//
+	// Defaults to empty, only the application's origin is allowed,
+	// it's the scheme and the host of the request as seen by the client, see `context.FullRequestURI`.
+	AllowedOrigins []string
+	// Methods are the validated request methods.
+	//
+	// Defaults to "POST", "PUT", "PATCH" and "DELETE".
+	Methods []string
+	// RequireHeader if true then the validated requests without both the "Origin" and the "Referer" headers
+	// are rejected, otherwise they're allowed, i.e the requests of the non-browser clients.
+	//
+	// Defaults to false.
+	RequireHeader bool
+	// ExemptRoutes are the names of the routes which are not validated, i.e the webhooks,
+	// see `Route#Name`.
+	ExemptRoutes []string
+	// OnFailure is called when a validated request comes from a different origin,
+	// the next handlers are not executed.
+	//
+	// Defaults to a handler which sends 403 Forbidden.
+	OnFailure func(ctx context.Context, err error)
+}
+
+var errInvalidOrigin = errors.New("sameorigin: allowed origin '%s' should be in the form of scheme://host")
+
+// Validate reports whether the `AllowedOrigins` are valid origins,
+// it's called when the configs are loaded as a section of a configuration file, see `Application#ConfigureSection`.
+func (c *Config) Validate() error {
+	for _, origin := range c.AllowedOrigins {
+		idx := strings.Index(origin, "://")
+		if idx <= 0 {
+			return errInvalidOrigin.Format(origin)
+		}
+
+		if host := strings.TrimRight(origin[idx+3:], "/"); host == "" || strings.Contains(host, "/") {
+			return errInvalidOrigin.Format(origin)
+		}
+	}
+
+	return nil
+}
+
+// DefaultConfig returns the default configs for the same-origin validation middleware.
+func DefaultConfig() Config {
+	return Config{
+		Methods: []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+	}
+}
@@ -0,0 +1,156 @@
+// Package sameorigin provides a middleware which validates the "Origin" or the "Referer" headers
+// of the state-changing requests (POST, PUT, PATCH, DELETE) against the application's origin
+// or the allowed ones, as a defense in depth for the CSRF tokens, see the csrf middleware.
+//
+// The "Origin" header is validated first, the "Referer" one is used when it's missing,
+// i.e by the older browsers.
+package sameorigin
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/errors"
+)
+
+var (
+	// ErrOriginMissing is passed to the `Config#OnFailure` when the request has no "Origin" and "Referer" headers
+	// and the `Config#RequireHeader` is true.
+	ErrOriginMissing = errors.New("sameorigin: origin is missing")
+	// ErrOriginMismatch is passed to the `Config#OnFailure` when the origin of the request is not allowed.
+	ErrOriginMismatch = errors.New("sameorigin: origin '%s' is not allowed")
+)
+
+// New returns a new same-origin validation middleware based on the "c" configs,
+// the default configs are used if "c" is missing.
+//
+// Usage:
+// app.Use(sameorigin.New())
+// or with the origins of other applications:
+// app.Use(sameorigin.New(sameorigin.Config{AllowedOrigins: []string{"https://*.example.com"}}))
+func New(c ...Config) context.Handler {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		config = c[0]
+		if len(config.Methods) == 0 {
+			config.Methods = DefaultConfig().Methods
+		}
+	}
+
+	if config.OnFailure == nil {
+		config.OnFailure = func(ctx context.Context, err error) {
+			ctx.StatusCode(http.StatusForbidden)
+		}
+	}
+
+	methods := make(map[string]struct{}, len(config.Methods))
+	for _, method := range config.Methods {
+		methods[strings.ToUpper(method)] = struct{}{}
+	}
+
+	exempt := make(map[string]struct{}, len(config.ExemptRoutes))
+	for _, name := range config.ExemptRoutes {
+		exempt[name] = struct{}{}
+	}
+
+	allowed := make([]string, 0, len(config.AllowedOrigins))
+	for _, origin := range config.AllowedOrigins {
+		allowed = append(allowed, strings.ToLower(strings.TrimRight(origin, "/")))
+	}
+
+	return func(ctx context.Context) {
+		if _, ok := methods[ctx.Method()]; !ok || isExempt(ctx, exempt) {
+			ctx.Next()
+			return
+		}
+
+		origin, ok := requestOrigin(ctx)
+		if !ok {
+			if config.RequireHeader {
+				ctx.StopExecution()
+				config.OnFailure(ctx, ErrOriginMissing)
+				return
+			}
+
+			ctx.Next()
+			return
+		}
+
+		if origin != appOrigin(ctx) && !matchOrigin(allowed, origin) {
+			ctx.StopExecution()
+			config.OnFailure(ctx, ErrOriginMismatch.Format(origin))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func isExempt(ctx context.Context, routes map[string]struct{}) bool {
+	if route := ctx.GetCurrentRoute(); route != nil {
+		_, ok := routes[route.Name()]
+		return ok
+	}
+
+	return false
+}
+
+// requestOrigin returns the origin of the "Origin" header or of the parsed "Referer" one, i.e "https://example.com",
+// the "null" and the invalid ones are returned as they're, so they're not allowed.
+// It reports false if both headers are missing.
+func requestOrigin(ctx context.Context) (string, bool) {
+	if origin := ctx.GetHeader("Origin"); origin != "" {
+		return strings.ToLower(origin), true
+	}
+
+	// the `GetReferrer` falls back to the "referer" url parameter, which is not sent by the browser.
+	if ctx.GetHeader("Referer") == "" {
+		return "", false
+	}
+
+	ref := ctx.GetReferrer()
+	if ref.Type == context.ReferrerInvalid {
+		return "null", true
+	}
+
+	u, err := url.Parse(ref.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "null", true
+	}
+
+	return strings.ToLower(u.Scheme + "://" + u.Host), true
+}
+
+// appOrigin returns the origin of the application as seen by the client.
+func appOrigin(ctx context.Context) string {
+	u, err := url.Parse(context.FullRequestURI(ctx))
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// matchOrigin reports whether the "origin" is one of the "allowed" ones,
+// a "*" label matches any subdomain, i.e "https://*.example.com" matches "https://admin.example.com".
+func matchOrigin(allowed []string, origin string) bool {
+	for _, a := range allowed {
+		if a == origin {
+			return true
+		}
+
+		idx := strings.Index(a, "://*.")
+		if idx == -1 || !strings.HasPrefix(origin, a[:idx+3]) {
+			continue
+		}
+
+		suffix := a[idx+4:] // ".example.com".
+		if host := origin[idx+3:]; len(host) > len(suffix) && strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,157 @@
+package sameorigin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/middleware/sameorigin"
+)
+
+type originTest struct {
+	method  string
+	path    string
+	origin  string
+	referer string
+	status  int
+}
+
+func testOrigins(t *testing.T, app *iris.Application, tests []originTest, header ...http.Header) {
+	t.Helper()
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"), httptest.RemoteAddr("10.0.0.1:1234"))
+	for _, tt := range tests {
+		r := e.Request(tt.method, tt.path)
+		if tt.origin != "" {
+			r.WithHeader("Origin", tt.origin)
+		}
+		if tt.referer != "" {
+			r.WithHeader("Referer", tt.referer)
+		}
+		for _, h := range header {
+			for k := range h {
+				r.WithHeader(k, h.Get(k))
+			}
+		}
+
+		r.Expect().Status(tt.status)
+	}
+}
+
+func newApp(c ...sameorigin.Config) *iris.Application {
+	app := iris.New()
+	app.Use(sameorigin.New(c...))
+	app.Get("/", func(ctx iris.Context) {})
+	app.Post("/", func(ctx iris.Context) {})
+	app.Delete("/", func(ctx iris.Context) {})
+	app.Post("/webhook", func(ctx iris.Context) {}).Name = "webhook"
+	return app
+}
+
+func TestSameOrigin(t *testing.T) {
+	testOrigins(t, newApp(sameorigin.Config{ExemptRoutes: []string{"webhook"}}), []originTest{
+		{iris.MethodPost, "/", "http://example.com", "", iris.StatusOK},
+		{iris.MethodPost, "/", "HTTP://EXAMPLE.COM", "", iris.StatusOK},
+		{iris.MethodDelete, "/", "http://example.com", "", iris.StatusOK},
+		{iris.MethodPost, "/", "https://example.com", "", iris.StatusForbidden},
+		{iris.MethodPost, "/", "http://example.com:8080", "", iris.StatusForbidden},
+		{iris.MethodPost, "/", "http://evil.com", "", iris.StatusForbidden},
+		{iris.MethodDelete, "/", "http://evil.com", "", iris.StatusForbidden},
+		{iris.MethodPost, "/", "null", "", iris.StatusForbidden},
+		// the safe methods are not validated.
+		{iris.MethodGet, "/", "http://evil.com", "", iris.StatusOK},
+		// the "Origin" is validated first.
+		{iris.MethodPost, "/", "http://evil.com", "http://example.com/form", iris.StatusForbidden},
+		{iris.MethodPost, "/", "http://example.com", "http://evil.com/form", iris.StatusOK},
+		// the "Referer" when the "Origin" is missing.
+		{iris.MethodPost, "/", "", "http://example.com/form?a=b", iris.StatusOK},
+		{iris.MethodPost, "/", "", "http://evil.com/form", iris.StatusForbidden},
+		{iris.MethodPost, "/", "", "/form", iris.StatusForbidden},
+		// the non-browser clients.
+		{iris.MethodPost, "/", "", "", iris.StatusOK},
+		// the exempt routes.
+		{iris.MethodPost, "/webhook", "http://evil.com", "", iris.StatusOK},
+	})
+}
+
+func TestSameOriginRequireHeader(t *testing.T) {
+	var failure error
+	app := newApp(sameorigin.Config{RequireHeader: true, OnFailure: func(ctx iris.Context, err error) {
+		failure = err
+		ctx.StatusCode(iris.StatusBadRequest)
+	}})
+
+	testOrigins(t, app, []originTest{
+		{iris.MethodPost, "/", "http://example.com", "", iris.StatusOK},
+		{iris.MethodPost, "/", "", "", iris.StatusBadRequest},
+	})
+	if !sameorigin.ErrOriginMissing.Equal(failure) {
+		t.Fatalf("expected the missing origin error but got %v", failure)
+	}
+
+	testOrigins(t, app, []originTest{{iris.MethodPost, "/", "http://evil.com", "", iris.StatusBadRequest}})
+	if !sameorigin.ErrOriginMismatch.Equal(failure) {
+		t.Fatalf("expected the origin mismatch error but got %v", failure)
+	}
+}
+
+func TestSameOriginAllowedOrigins(t *testing.T) {
+	app := newApp(sameorigin.Config{
+		AllowedOrigins: []string{"https://admin.example.org/", "https://*.example.net"},
+		Methods:        []string{"post"},
+	})
+
+	testOrigins(t, app, []originTest{
+		{iris.MethodPost, "/", "http://example.com", "", iris.StatusOK},
+		{iris.MethodPost, "/", "https://admin.example.org", "", iris.StatusOK},
+		{iris.MethodPost, "/", "", "https://admin.example.org/dashboard", iris.StatusOK},
+		{iris.MethodPost, "/", "http://admin.example.org", "", iris.StatusForbidden},
+		{iris.MethodPost, "/", "https://example.org", "", iris.StatusForbidden},
+		{iris.MethodPost, "/", "https://a.example.net", "", iris.StatusOK},
+		{iris.MethodPost, "/", "https://a.b.example.net", "", iris.StatusOK},
+		{iris.MethodPost, "/", "https://example.net", "", iris.StatusForbidden},
+		{iris.MethodPost, "/", "https://evilexample.net", "", iris.StatusForbidden},
+		{iris.MethodPost, "/", "https://a.example.net.evil.com", "", iris.StatusForbidden},
+		{iris.MethodPost, "/", "http://a.example.net", "", iris.StatusForbidden},
+		// only the "Methods" are validated.
+		{iris.MethodDelete, "/", "http://evil.com", "", iris.StatusOK},
+	})
+}
+
+func TestSameOriginBehindProxy(t *testing.T) {
+	forwarded := http.Header{"X-Forwarded-Proto": {"https"}, "X-Forwarded-Host": {"www.example.com"}}
+	tests := []originTest{{iris.MethodPost, "/", "https://www.example.com", "", iris.StatusOK}}
+
+	proxyHeaders := []iris.Configurator{iris.WithSSLProxyHeader("X-Forwarded-Proto", "https"), iris.WithHostProxyHeader("X-Forwarded-Host")}
+
+	// the forwarded headers of the trusted proxies are the origin of the application as seen by the client.
+	app := newApp()
+	app.Configure(append(proxyHeaders, iris.WithTrustedProxies("10.0.0.0/8"))...)
+	testOrigins(t, app, tests, forwarded)
+
+	// but not of any client.
+	app = newApp()
+	app.Configure(append(proxyHeaders, iris.WithTrustedProxies("192.168.1.10"))...)
+	tests[0].status = iris.StatusForbidden
+	testOrigins(t, app, tests, forwarded)
+
+	// and not at all without trusted proxies.
+	app = newApp()
+	app.Configure(proxyHeaders...)
+	testOrigins(t, app, tests, forwarded)
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, origin := range []string{"example.com", "://example.com", "https://", "https://example.com/path"} {
+		c := sameorigin.Config{AllowedOrigins: []string{origin}}
+		if err := c.Validate(); err == nil {
+			t.Fatalf("expected an error of the origin %q", origin)
+		}
+	}
+
+	c := sameorigin.Config{AllowedOrigins: []string{"https://example.com", "https://*.example.com/"}}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,56 @@
+package secure
+
+// NoncePlaceholder is the placeholder of the per-request nonce
+// inside the `Config#ContentSecurityPolicy`, i.e "script-src 'self' 'nonce-{nonce}'".
+const NoncePlaceholder = "{nonce}"
+
+// Config the configs for the security headers middleware.
+type Config struct {
+	// ContentSecurityPolicy is the "Content-Security-Policy" response header's value,
+	// any `NoncePlaceholder` is replaced with the request's nonce,
+	// which is exposed to the views as {{ .cspNonce }}.
+	// Empty to not send the header.
+	//
+	// Defaults to "default-src 'self'; script-src 'self' 'nonce-{nonce}'; style-src 'self' 'nonce-{nonce}'".
+	ContentSecurityPolicy string
+	// ReportOnly sends the policy as "Content-Security-Policy-Report-Only" instead,
+	// the browser reports but does not enforce the violations.
+	//
+	// Defaults to false.
+	ReportOnly bool
+	// FrameOptions is the "X-Frame-Options" response header's value.
+	// Empty to not send the header.
+	//
+	// Defaults to "SAMEORIGIN".
+	FrameOptions string
+	// ContentTypeNosniff sends the "X-Content-Type-Options: nosniff" response header.
+	//
+	// Defaults to true.
+	ContentTypeNosniff bool
+	// ReferrerPolicy is the "Referrer-Policy" response header's value.
+	// Empty to not send the header.
+	//
+	// Defaults to "strict-origin-when-cross-origin".
+	ReferrerPolicy string
+	// STSSeconds is the max-age of the "Strict-Transport-Security" response header,
+	// the header is sent only on TLS requests.
+	// Zero to not send the header.
+	//
+	// Defaults to 0.
+	STSSeconds int64
+	// NonceSize is the length of the random bytes of the nonce, before its base64 encoding.
+	//
+	// Defaults to 16.
+	NonceSize int
+}
+
+// DefaultConfig returns the default configs for the security headers middleware.
+func DefaultConfig() Config {
+	return Config{
+		ContentSecurityPolicy: "default-src 'self'; script-src 'self' 'nonce-{nonce}'; style-src 'self' 'nonce-{nonce}'",
+		FrameOptions:          "SAMEORIGIN",
+		ContentTypeNosniff:    true,
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+		NonceSize:             16,
+	}
+}
@@ -0,0 +1,93 @@
+// Package secure provides a middleware which sends the common security response headers
+// and generates a per-request Content-Security-Policy nonce.
+//
+// The nonce, the CSRF token (when a CSRF protection middleware is registered) and the "X-Request-Id"
+// are exposed to the views automatically, i.e `<script nonce="{{ .cspNonce }}">`,
+// as "cspNonce", "csrfToken" and "requestID", see `context#View`.
+// Different policies can be registered per Party or per route.
+package secure
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris/context"
+)
+
+// New returns a new security headers middleware based on the "c" configs,
+// the default configs are used if "c" is missing.
+//
+// Usage:
+// app.Use(secure.New())
+// app.Get("/", func(ctx iris.Context) { ctx.View("index.html") })
+// and inside the template: <script nonce="{{ .cspNonce }}">...</script>
+func New(c ...Config) context.Handler {
+	config := DefaultConfig()
+	if len(c) > 0 {
+		config = c[0]
+		if config.NonceSize <= 0 {
+			config.NonceSize = DefaultConfig().NonceSize
+		}
+	}
+
+	cspHeaderKey := "Content-Security-Policy"
+	if config.ReportOnly {
+		cspHeaderKey = "Content-Security-Policy-Report-Only"
+	}
+	needsNonce := strings.Contains(config.ContentSecurityPolicy, NoncePlaceholder)
+
+	var stsValue string
+	if config.STSSeconds > 0 {
+		stsValue = "max-age=" + strconv.FormatInt(config.STSSeconds, 10) + "; includeSubDomains"
+	}
+
+	return func(ctx context.Context) {
+		if config.ContentSecurityPolicy != "" {
+			policy := config.ContentSecurityPolicy
+			if needsNonce {
+				nonce, err := generateNonce(config.NonceSize)
+				if err != nil {
+					context.Logger(ctx).Errorf("secure: nonce: %v", err)
+					ctx.StatusCode(http.StatusInternalServerError)
+					ctx.StopExecution()
+					return
+				}
+
+				ctx.Values().Set(context.CSPNonceContextKey, nonce)
+				policy = strings.Replace(policy, NoncePlaceholder, nonce, -1)
+			}
+
+			ctx.Header(cspHeaderKey, policy)
+		}
+
+		if config.FrameOptions != "" {
+			ctx.Header("X-Frame-Options", config.FrameOptions)
+		}
+
+		if config.ContentTypeNosniff {
+			ctx.Header("X-Content-Type-Options", "nosniff")
+		}
+
+		if config.ReferrerPolicy != "" {
+			ctx.Header("Referrer-Policy", config.ReferrerPolicy)
+		}
+
+		if stsValue != "" && context.IsTLS(ctx) {
+			ctx.Header("Strict-Transport-Security", stsValue)
+		}
+
+		ctx.Next()
+	}
+}
+
+func generateNonce(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,48 @@
+package slo
+
+import "time"
+
+// Config the configs for the SLO tracker.
+type Config struct {
+	// Objective is the target success ratio of each route, i.e 0.999 for 99.9%,
+	// its error budget is the 1 - Objective.
+	//
+	// Defaults to 0.99.
+	Objective float64
+	// Window is the rolling window that the ratios are calculated on.
+	//
+	// Defaults to one hour.
+	Window time.Duration
+	// Resolution is the interval that the routes' statistics are sampled,
+	// it should be smaller than the Window.
+	//
+	// Defaults to one minute.
+	Resolution time.Duration
+	// MinHits is the minimum number of requests in the window
+	// that are required for a route to be reported as exceeding its error budget,
+	// it prevents alerts on low traffic.
+	//
+	// Defaults to 1.
+	MinHits uint64
+	// BurnRateThreshold is the burn rate that a route is considered as exceeding its error budget.
+	// A burn rate of 1 means that the route consumes its error budget exactly at the objective's rate.
+	//
+	// Defaults to 1.
+	BurnRateThreshold float64
+	// OnBudgetExceeded, if not nil, is called when a route starts to exceed its error budget,
+	// it's not called again for the same route until it recovers.
+	//
+	// Defaults to nil.
+	OnBudgetExceeded func(Report)
+}
+
+// DefaultConfig returns the default configs for the SLO tracker.
+func DefaultConfig() Config {
+	return Config{
+		Objective:         0.99,
+		Window:            time.Hour,
+		Resolution:        time.Minute,
+		MinHits:           1,
+		BurnRateThreshold: 1,
+	}
+}
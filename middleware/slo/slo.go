@@ -0,0 +1,196 @@
+// Package slo provides error budget (SLO) tracking of the routes over rolling windows,
+// it's built on the per-route statistics that the router keeps, see `context.RouteReadOnly#Stats`.
+package slo
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/context"
+)
+
+// Report is the SLO report of a route over the rolling window.
+type Report struct {
+	Route  string `json:"route"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Hits and Errors are the requests and the failed requests in the window.
+	Hits   uint64 `json:"hits"`
+	Errors uint64 `json:"errors"`
+	// ErrorRatio is the Errors / Hits.
+	ErrorRatio float64 `json:"errorRatio"`
+	// BurnRate is the ErrorRatio / (1 - Objective),
+	// greater than 1 means that the error budget is consumed faster than allowed.
+	BurnRate float64 `json:"burnRate"`
+	// BudgetRemaining is the remaining part of the error budget in the window, 1 - BurnRate,
+	// negative when exceeded.
+	BudgetRemaining float64 `json:"budgetRemaining"`
+	// Exceeded reports whether the route exceeds its error budget.
+	Exceeded bool `json:"exceeded"`
+}
+
+type sample struct {
+	time   time.Time
+	hits   uint64
+	errors uint64
+}
+
+type routeWindow struct {
+	method, path string
+	samples      []sample
+	exceeded     bool
+}
+
+// Tracker samples the routes' statistics and calculates their SLO reports, see `New`.
+type Tracker struct {
+	config Config
+	app    context.Application
+
+	mu      sync.RWMutex
+	routes  map[string]*routeWindow
+	reports []Report
+
+	once sync.Once
+	stop chan struct{}
+	// now returns the time of a sample, it's replaced by the tests.
+	now func() time.Time
+}
+
+// New returns a new SLO Tracker of the "app"'s routes based on the "c" configs.
+// Its `Start` should be called after the application's build in order to sample the routes periodically.
+//
+// Usage:
+// tracker := slo.New(app, slo.Config{Objective: 0.999, OnBudgetExceeded: alert})
+// app.Get("/debug/slo", tracker.Handler)
+// app.Build(); tracker.Start(); defer tracker.Stop()
+func New(app context.Application, c Config) *Tracker {
+	config := DefaultConfig()
+	if c.Objective > 0 && c.Objective < 1 {
+		config.Objective = c.Objective
+	}
+	if c.Window > 0 {
+		config.Window = c.Window
+	}
+	if c.Resolution > 0 {
+		config.Resolution = c.Resolution
+	}
+	if c.MinHits > 0 {
+		config.MinHits = c.MinHits
+	}
+	if c.BurnRateThreshold > 0 {
+		config.BurnRateThreshold = c.BurnRateThreshold
+	}
+	config.OnBudgetExceeded = c.OnBudgetExceeded
+
+	return &Tracker{
+		config: config,
+		app:    app,
+		routes: make(map[string]*routeWindow),
+		stop:   make(chan struct{}),
+		now:    time.Now,
+	}
+}
+
+// Start samples the routes' statistics every `Config#Resolution`, on a new goroutine.
+func (t *Tracker) Start() {
+	t.Sample()
+
+	go func() {
+		ticker := time.NewTicker(t.config.Resolution)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+				t.Sample()
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic sampling, it's safe to be called more than once.
+func (t *Tracker) Stop() {
+	t.once.Do(func() {
+		close(t.stop)
+	})
+}
+
+// Sample takes a sample of the routes' statistics and updates the reports,
+// the `OnBudgetExceeded` is fired for the routes that started to exceed their error budget.
+// It's called by the `Start` periodically, it can be called manually as well.
+func (t *Tracker) Sample() {
+	now := t.now()
+	budget := 1 - t.config.Objective
+
+	var exceeded []Report
+
+	t.mu.Lock()
+	reports := make([]Report, 0, len(t.routes))
+	for _, r := range t.app.GetRoutesReadOnly() {
+		stats := r.Stats()
+		w, ok := t.routes[r.Name()]
+		if !ok {
+			// the base of a new route is zero, the whole statistics so far are in the window.
+			w = &routeWindow{method: r.Method(), path: r.Path(), samples: []sample{{time: now}}}
+			t.routes[r.Name()] = w
+		}
+
+		w.samples = append(w.samples, sample{time: now, hits: stats.Hits, errors: stats.Errors})
+		// keep the newest sample that is older than the window as the base of the window.
+		for len(w.samples) > 1 && now.Sub(w.samples[1].time) >= t.config.Window {
+			w.samples = w.samples[1:]
+		}
+
+		first, last := w.samples[0], w.samples[len(w.samples)-1]
+		report := Report{
+			Route:  r.Name(),
+			Method: w.method,
+			Path:   w.path,
+			Hits:   last.hits - first.hits,
+			Errors: last.errors - first.errors,
+		}
+		if report.Hits > 0 {
+			report.ErrorRatio = float64(report.Errors) / float64(report.Hits)
+		}
+		report.BurnRate = report.ErrorRatio / budget
+		report.BudgetRemaining = 1 - report.BurnRate
+		report.Exceeded = report.Hits >= t.config.MinHits && report.BurnRate >= t.config.BurnRateThreshold
+
+		if report.Exceeded && !w.exceeded {
+			exceeded = append(exceeded, report)
+		}
+		w.exceeded = report.Exceeded
+
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].BurnRate > reports[j].BurnRate
+	})
+	t.reports = reports
+	t.mu.Unlock()
+
+	if t.config.OnBudgetExceeded != nil {
+		for _, report := range exceeded {
+			t.config.OnBudgetExceeded(report)
+		}
+	}
+}
+
+// Reports returns the reports of the latest sample, sorted by their burn rate, the highest first.
+func (t *Tracker) Reports() []Report {
+	t.mu.RLock()
+	reports := make([]Report, len(t.reports))
+	copy(reports, t.reports)
+	t.mu.RUnlock()
+	return reports
+}
+
+// Handler renders the `Reports` as JSON,
+// it can be registered to a debug endpoint, i.e `app.Get("/debug/slo", tracker.Handler)`.
+func (t *Tracker) Handler(ctx context.Context) {
+	ctx.JSON(t.Reports())
+}
@@ -0,0 +1,220 @@
+package slo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iris-contrib/httpexpect"
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/httptest"
+)
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func (c *clock) add(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func newApp() *iris.Application {
+	app := iris.New()
+	app.Get("/ok", func(ctx iris.Context) {})
+	app.Get("/fail", func(ctx iris.Context) {
+		if ctx.URLParamExists("fail") {
+			ctx.StatusCode(iris.StatusInternalServerError)
+		}
+	})
+
+	return app
+}
+
+// serve sends "n" requests to the "uri", i.e "/fail?fail=1".
+func serve(e *httpexpect.Expect, uri string, n int) {
+	path, query := uri, ""
+	if idx := strings.IndexByte(uri, '?'); idx > 0 {
+		path, query = uri[:idx], uri[idx+1:]
+	}
+
+	for i := 0; i < n; i++ {
+		e.GET(path).WithQueryString(query).Expect()
+	}
+}
+
+func newTracker(app *iris.Application, c Config) (*Tracker, *clock) {
+	tracker := New(app, c)
+	clk := &clock{t: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tracker.now = clk.now
+	return tracker, clk
+}
+
+func report(t *testing.T, tracker *Tracker, route string) Report {
+	t.Helper()
+
+	for _, r := range tracker.Reports() {
+		if r.Route == route {
+			return r
+		}
+	}
+
+	t.Fatalf("expected a report of the %s route", route)
+	return Report{}
+}
+
+func TestNew(t *testing.T) {
+	tracker := New(iris.New(), Config{Objective: 1, MinHits: 5})
+	expected := DefaultConfig()
+	expected.MinHits = 5
+	if tracker.config.Objective != expected.Objective || tracker.config.Window != expected.Window ||
+		tracker.config.Resolution != expected.Resolution || tracker.config.MinHits != expected.MinHits ||
+		tracker.config.BurnRateThreshold != expected.BurnRateThreshold {
+		t.Fatalf("expected the configuration %#v but got %#v", expected, tracker.config)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	var alerts []Report
+
+	app := newApp()
+	e := httptest.New(t, app)
+	tracker, clk := newTracker(app, Config{
+		Objective:  0.9,
+		Window:     10 * time.Minute,
+		Resolution: time.Minute,
+		OnBudgetExceeded: func(r Report) {
+			alerts = append(alerts, r)
+		},
+	})
+
+	// the requests before the first sample are in the window.
+	serve(e, "/ok", 4)
+	serve(e, "/fail", 3)
+	serve(e, "/fail?fail=1", 1)
+	tracker.Sample()
+
+	reports := tracker.Reports()
+	if len(reports) != 2 {
+		t.Fatalf("expected two reports but got %v", reports)
+	}
+	// the highest burn rate first.
+	fail := reports[0]
+	if fail.Route != "GET/fail" || fail.Method != iris.MethodGet || fail.Path != "/fail" || fail.Hits != 4 || fail.Errors != 1 {
+		t.Fatalf("unexpected report %#v", fail)
+	}
+	if fail.ErrorRatio != 0.25 || fail.BurnRate < 2.49 || fail.BurnRate > 2.51 || fail.BudgetRemaining > -1.49 || !fail.Exceeded {
+		t.Fatalf("unexpected ratios of the report %#v", fail)
+	}
+	if ok := reports[1]; ok.Route != "GET/ok" || ok.Hits != 4 || ok.Errors != 0 || ok.BurnRate != 0 || ok.BudgetRemaining != 1 || ok.Exceeded {
+		t.Fatalf("unexpected report %#v", ok)
+	}
+
+	if len(alerts) != 1 || alerts[0].Route != "GET/fail" {
+		t.Fatalf("expected an alert of the GET/fail route but got %v", alerts)
+	}
+
+	// not fired again until it recovers.
+	clk.add(time.Minute)
+	serve(e, "/fail?fail=1", 1)
+	tracker.Sample()
+	if r := report(t, tracker, "GET/fail"); r.Hits != 5 || r.Errors != 2 || !r.Exceeded || len(alerts) != 1 {
+		t.Fatalf("expected the report to be exceeded without a new alert but got %#v and %d alerts", r, len(alerts))
+	}
+
+	// the rolling window, the requests above are out of it.
+	clk.add(10 * time.Minute)
+	serve(e, "/fail", 2)
+	tracker.Sample()
+	if r := report(t, tracker, "GET/fail"); r.Hits != 2 || r.Errors != 0 || r.Exceeded {
+		t.Fatalf("expected the report to be recovered but got %#v", r)
+	}
+
+	clk.add(time.Minute)
+	serve(e, "/fail?fail=1", 1)
+	tracker.Sample()
+	if r := report(t, tracker, "GET/fail"); r.Hits != 3 || r.Errors != 1 || !r.Exceeded {
+		t.Fatalf("expected the report to be exceeded again but got %#v", r)
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("expected a new alert after the recovery but got %d alerts", len(alerts))
+	}
+}
+
+func TestTrackerThresholds(t *testing.T) {
+	app := newApp()
+	e := httptest.New(t, app)
+	tracker, _ := newTracker(app, Config{Objective: 0.9, MinHits: 5, BurnRateThreshold: 3})
+
+	serve(e, "/fail?fail=1", 1)
+	serve(e, "/fail", 3)
+	tracker.Sample()
+	// low traffic.
+	if r := report(t, tracker, "GET/fail"); r.Exceeded {
+		t.Fatalf("expected the report to not be exceeded with less than the minimum hits but got %#v", r)
+	}
+
+	serve(e, "/fail", 1)
+	tracker.Sample()
+	// a burn rate of 2.
+	if r := report(t, tracker, "GET/fail"); r.Hits != 5 || r.Exceeded {
+		t.Fatalf("expected the report to not be exceeded under the burn rate threshold but got %#v", r)
+	}
+
+	serve(e, "/fail?fail=1", 1)
+	tracker.Sample()
+	if r := report(t, tracker, "GET/fail"); !r.Exceeded {
+		t.Fatalf("expected the report to be exceeded but got %#v", r)
+	}
+}
+
+func TestTrackerHandler(t *testing.T) {
+	app := newApp()
+	e := httptest.New(t, app)
+	tracker, _ := newTracker(app, Config{})
+	serve(e, "/fail?fail=1", 1)
+	tracker.Sample()
+
+	// the debug endpoint is not tracked.
+	debug := iris.New()
+	debug.Get("/debug/slo", tracker.Handler)
+	body := httptest.New(t, debug).GET("/debug/slo").Expect().Status(iris.StatusOK).Body().Raw()
+
+	var reports []Report
+	if err := json.Unmarshal([]byte(body), &reports); err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 || reports[0].Route != "GET/fail" || reports[0].Errors != 1 || !reports[0].Exceeded {
+		t.Fatalf("unexpected reports %v", reports)
+	}
+}
+
+func TestTrackerStart(t *testing.T) {
+	app := newApp()
+	e := httptest.New(t, app)
+	tracker := New(app, Config{Resolution: 10 * time.Millisecond})
+	tracker.Start()
+	defer tracker.Stop()
+
+	// the first sample is taken immediately.
+	if reports := tracker.Reports(); len(reports) != 2 {
+		t.Fatalf("expected two reports but got %v", reports)
+	}
+
+	serve(e, "/ok", 1)
+	deadline := time.Now().Add(time.Second)
+	for report(t, tracker, "GET/ok").Hits != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the routes to be sampled periodically")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	tracker.Stop()
+	// safe to be called more than once.
+	tracker.Stop()
+}
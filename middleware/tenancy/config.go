@@ -0,0 +1,98 @@
+package tenancy
+
+import (
+	"strings"
+
+	"github.com/kataras/iris/context"
+)
+
+// Resolver resolves the tenant's identifier of a request,
+// it should return an empty string if the request does not belong to a tenant.
+type Resolver func(ctx context.Context) string
+
+// FromSubdomain returns a Resolver which uses the request's subdomain
+// as the tenant's identifier, i.e "acme.mydomain.com" -> "acme".
+// The "www" subdomain is ignored.
+func FromSubdomain() Resolver {
+	return func(ctx context.Context) string {
+		if subdomain := ctx.Subdomain(); subdomain != "www" {
+			return subdomain
+		}
+		return ""
+	}
+}
+
+// FromHeader returns a Resolver which uses the "headerName"
+// request header's value as the tenant's identifier, i.e "X-Tenant-Id".
+//
+// Any client can send the header, so it's honored only when the request comes from one of the
+// `Configuration#TrustedProxies`, the proxy in front of the application should set or strip it,
+// see `context.IsFromTrustedProxy`. It's not one of the default resolvers.
+func FromHeader(headerName string) Resolver {
+	return func(ctx context.Context) string {
+		if !context.IsFromTrustedProxy(ctx) {
+			return ""
+		}
+
+		return strings.TrimSpace(ctx.GetHeader(headerName))
+	}
+}
+
+// FromPathParam returns a Resolver which uses the "paramName"
+// route's named path parameter as the tenant's identifier,
+// i.e "/{tenant}/users" and FromPathParam("tenant").
+func FromPathParam(paramName string) Resolver {
+	return func(ctx context.Context) string {
+		return ctx.Params().Get(paramName)
+	}
+}
+
+// Tenant is the per-tenant configuration.
+type Tenant struct {
+	// ID is the tenant's identifier, as resolved by the `Config#Resolvers`.
+	ID string
+	// ViewData are set as the template's binding data of each request of the tenant,
+	// i.e the tenant's display name or theme.
+	ViewData context.Map
+	// Values are custom, per-tenant, values.
+	Values context.Map
+}
+
+// Config the configs for the tenancy middleware.
+type Config struct {
+	// Resolvers are executed by order, the first non-empty identifier wins.
+	// The `FromHeader` is opt-in, i.e []Resolver{FromHeader("X-Tenant-Id"), FromSubdomain()}
+	// when a trusted proxy resolves the tenant.
+	//
+	// Defaults to the `FromSubdomain()`.
+	Resolvers []Resolver
+	// Tenants are the registered tenants, based on their identifiers,
+	// see `Tenancy#Register` too.
+	Tenants map[string]*Tenant
+	// AllowUnregistered, if true, accepts tenant identifiers
+	// that they are not registered, a `Tenant` with just its `ID` filled is used instead.
+	//
+	// Defaults to false.
+	AllowUnregistered bool
+	// DBLookup, if not nil, is called at most once per request by the `DB` function
+	// in order to retrieve the database handle of a tenant.
+	//
+	// Defaults to nil.
+	DBLookup func(tenant *Tenant) (interface{}, error)
+	// OnMissing fires when a tenant is required (see `Tenancy#Require`) but missing,
+	// it's responsible to send the response.
+	//
+	// Defaults to a handler which sends a 404 Not Found.
+	OnMissing context.Handler
+}
+
+// DefaultConfig returns the default configs for the tenancy middleware.
+func DefaultConfig() Config {
+	return Config{
+		Resolvers: []Resolver{FromSubdomain()},
+		Tenants:   make(map[string]*Tenant),
+		OnMissing: func(ctx context.Context) {
+			ctx.NotFound()
+		},
+	}
+}
@@ -0,0 +1,156 @@
+// Package tenancy provides multi-tenant support via middleware,
+// the tenant of a request is resolved by its subdomain, a header or a path parameter
+// and it's available to the next handlers through the `tenancy.Get(ctx)` and `tenancy.ID(ctx)`.
+package tenancy
+
+import (
+	"sync"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
+)
+
+const (
+	tenantContextKey = "iris.tenancy.tenant"
+	dbContextKey     = "iris.tenancy.db"
+)
+
+// Tenancy resolves the tenant of each request, see `New`.
+type Tenancy struct {
+	config Config
+	mu     sync.RWMutex
+}
+
+// New returns a new Tenancy based on the "c" configs,
+// its `Handler` should be registered as a middleware, i.e `app.Use(t.Handler)`.
+func New(c Config) *Tenancy {
+	config := DefaultConfig()
+	if len(c.Resolvers) > 0 {
+		config.Resolvers = c.Resolvers
+	}
+	if c.Tenants != nil {
+		config.Tenants = c.Tenants
+	}
+	config.AllowUnregistered = c.AllowUnregistered
+	config.DBLookup = c.DBLookup
+	if c.OnMissing != nil {
+		config.OnMissing = c.OnMissing
+	}
+
+	return &Tenancy{config: config}
+}
+
+// Register adds or replaces a tenant, it's safe to be called at serve-time.
+func (t *Tenancy) Register(tenant Tenant) {
+	t.mu.Lock()
+	t.config.Tenants[tenant.ID] = &tenant
+	t.mu.Unlock()
+}
+
+// Unregister removes a tenant based on its identifier.
+func (t *Tenancy) Unregister(id string) {
+	t.mu.Lock()
+	delete(t.config.Tenants, id)
+	t.mu.Unlock()
+}
+
+func (t *Tenancy) resolve(ctx context.Context) *Tenant {
+	for _, resolver := range t.config.Resolvers {
+		id := resolver(ctx)
+		if id == "" {
+			continue
+		}
+
+		t.mu.RLock()
+		tenant, ok := t.config.Tenants[id]
+		t.mu.RUnlock()
+		if ok {
+			return tenant
+		}
+
+		if t.config.AllowUnregistered {
+			return &Tenant{ID: id}
+		}
+	}
+
+	return nil
+}
+
+// Handler resolves the tenant of the request, if any, and continues to the next handler.
+// The tenant's identifier is available through the `ID`
+// and the whole tenant through the `Get`.
+func (t *Tenancy) Handler(ctx context.Context) {
+	if tenant := t.resolve(ctx); tenant != nil {
+		ctx.Values().Set(tenantContextKey, tenant)
+		for key, value := range tenant.ViewData {
+			ctx.ViewData(key, value)
+		}
+	}
+
+	ctx.Next()
+}
+
+// Require is a middleware which fires the `Config#OnMissing` and stops the execution
+// if the request does not belong to a tenant, the `Handler` should be executed before it.
+func (t *Tenancy) Require(ctx context.Context) {
+	if Get(ctx) == nil {
+		t.config.OnMissing(ctx)
+		ctx.StopExecution()
+		return
+	}
+
+	ctx.Next()
+}
+
+// Party returns a new child Party of the "parent" which resolves
+// and requires a tenant for all of its routes.
+//
+// Usage:
+// t := tenancy.New(tenancy.Config{Resolvers: []tenancy.Resolver{tenancy.FromPathParam("tenant")}})
+// t.Register(tenancy.Tenant{ID: "acme"})
+// tenantRoutes := t.Party(app, "/{tenant}")
+// tenantRoutes.Get("/users", listUsers)
+func (t *Tenancy) Party(parent router.Party, relativePath string, handlers ...context.Handler) router.Party {
+	return parent.Party(relativePath, append(context.Handlers{t.Handler, t.Require}, handlers...)...)
+}
+
+// Get returns the tenant of the request, nil if the request does not belong to a tenant.
+func Get(ctx context.Context) *Tenant {
+	if tenant, ok := ctx.Values().Get(tenantContextKey).(*Tenant); ok {
+		return tenant
+	}
+
+	return nil
+}
+
+// ID returns the identifier of the tenant that the request belongs to, empty if none.
+// 多租户: 当前请求所属的租户
+func ID(ctx context.Context) string {
+	if tenant := Get(ctx); tenant != nil {
+		return tenant.ID
+	}
+
+	return ""
+}
+
+// DB returns the database handle of the request's tenant
+// based on the `Config#DBLookup`, it's called once per request.
+// It returns nil and nil if the request does not belong to a tenant or no lookup is registered.
+func (t *Tenancy) DB(ctx context.Context) (interface{}, error) {
+	if db := ctx.Values().Get(dbContextKey); db != nil {
+		return db, nil
+	}
+
+	tenant := Get(ctx)
+	if tenant == nil || t.config.DBLookup == nil {
+		return nil, nil
+	}
+
+	db, err := t.config.DBLookup(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Values().Set(dbContextKey, db)
+	return db, nil
+}
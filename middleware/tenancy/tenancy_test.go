@@ -0,0 +1,83 @@
+package tenancy_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/middleware/tenancy"
+)
+
+func newApp(t *testing.T, c tenancy.Config, configurators ...iris.Configurator) *iris.Application {
+	tn := tenancy.New(c)
+	tn.Register(tenancy.Tenant{ID: "acme"})
+	tn.Register(tenancy.Tenant{ID: "globex"})
+
+	app := iris.New().Configure(configurators...)
+	app.Use(tn.Handler)
+	app.Get("/", func(ctx iris.Context) {
+		ctx.WriteString(tenancy.ID(ctx))
+	})
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+type tenancyTest struct {
+	host       string
+	remoteAddr string
+	header     string
+	expected   string
+}
+
+func testTenancy(t *testing.T, app *iris.Application, tests []tenancyTest) {
+	t.Helper()
+
+	for i, tt := range tests {
+		r := httptest.NewRequest(iris.MethodGet, "http://"+tt.host+"/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		if tt.header != "" {
+			r.Header.Set("X-Tenant-Id", tt.header)
+		}
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if got := w.Body.String(); got != tt.expected {
+			t.Fatalf("[%d] expected the tenant %q but got %q", i, tt.expected, got)
+		}
+	}
+}
+
+func TestTenancyDefaultConfig(t *testing.T) {
+	app := newApp(t, tenancy.Config{})
+
+	testTenancy(t, app, []tenancyTest{
+		{"acme.mydomain.com", "203.0.113.7:1234", "", "acme"},
+		{"www.mydomain.com", "203.0.113.7:1234", "", ""},
+		{"unknown.mydomain.com", "203.0.113.7:1234", "", ""},
+		// the header is not a default resolver, a client can't switch its tenant.
+		{"acme.mydomain.com", "203.0.113.7:1234", "globex", "acme"},
+		{"mydomain.com", "203.0.113.7:1234", "globex", ""},
+	})
+}
+
+func TestTenancyFromHeader(t *testing.T) {
+	c := tenancy.Config{Resolvers: []tenancy.Resolver{tenancy.FromHeader("X-Tenant-Id"), tenancy.FromSubdomain()}}
+
+	// no trusted proxies, the header is never honored.
+	testTenancy(t, newApp(t, c), []tenancyTest{
+		{"acme.mydomain.com", "10.0.0.2:1234", "globex", "acme"},
+	})
+
+	app := newApp(t, c, iris.WithTrustedProxies("10.0.0.0/8"))
+	testTenancy(t, app, []tenancyTest{
+		{"acme.mydomain.com", "10.0.0.2:1234", "globex", "globex"},
+		{"mydomain.com", "10.0.0.2:1234", "acme", "acme"},
+		// from a client, not the proxy.
+		{"acme.mydomain.com", "203.0.113.7:1234", "globex", "acme"},
+		{"mydomain.com", "203.0.113.7:1234", "globex", ""},
+	})
+}
@@ -0,0 +1,15 @@
+// Package otel provides an OpenTelemetry adapter of the `context#Tracer`,
+// the router starts the server span of each request through it, named after the matched route's template,
+// i.e "GET /api/user/{id:uint64}", so the spans are exported by the OpenTelemetry SDK.
+//
+// The spans are exported by the tracer provider of the application, i.e
+// `go.opentelemetry.io/otel/sdk/trace#NewTracerProvider` with the exporter of your choice.
+//
+// Usage:
+//
+//	app.Tracer(otel.New(otel.Tracer("my-service"), nil))
+//
+// The remote parent of a request is extracted by the propagator, the span is set to the request's context,
+// see `context#ResetRequest`, so the outgoing calls of the `ctx.Request().Context()` and the `context.StdContext(ctx)`
+// are traced as its children.
+package otel
@@ -0,0 +1,105 @@
+package otel
+
+import (
+	"fmt"
+
+	"github.com/kataras/iris/context"
+
+	otelapi "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer returns the named tracer of the global OpenTelemetry tracer provider.
+func Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return otelapi.Tracer(name, opts...)
+}
+
+// New returns a `context#Tracer` which starts the server spans of the requests through the OpenTelemetry's "tracer",
+// their remote parent is extracted from the request headers by the "propagator",
+// the global one, i.e the W3C Trace Context, if it's nil.
+func New(tracer trace.Tracer, propagator propagation.TextMapPropagator) context.Tracer {
+	return &otelTracer{tracer: tracer, propagator: propagator}
+}
+
+type otelTracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func (t *otelTracer) Start(ctx context.Context, spanName string) context.Span {
+	propagator := t.propagator
+	if propagator == nil {
+		propagator = otelapi.GetTextMapPropagator()
+	}
+
+	r := ctx.Request()
+	parent := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	spanCtx, span := t.tracer.Start(parent, spanName, trace.WithSpanKind(trace.SpanKindServer))
+	context.ResetRequest(ctx, r.WithContext(spanCtx))
+
+	return &otelSpan{span: span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s *otelSpan) SpanContext() context.SpanContext {
+	sc := s.span.SpanContext()
+	return context.SpanContext{
+		TraceID: sc.TraceID(),
+		SpanID:  sc.SpanID(),
+		Sampled: sc.IsSampled(),
+		Remote:  sc.IsRemote(),
+	}
+}
+
+func (s *otelSpan) SetAttribute(key string, value interface{}) {
+	s.span.SetAttributes(keyValue(key, value))
+}
+
+func (s *otelSpan) SetStatus(code context.SpanStatus, description string) {
+	switch code {
+	case context.SpanStatusError:
+		s.span.SetStatus(codes.Error, description)
+	case context.SpanStatusOK:
+		s.span.SetStatus(codes.Ok, description)
+	default:
+		s.span.SetStatus(codes.Unset, description)
+	}
+}
+
+func (s *otelSpan) RecordError(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+	}
+}
+
+func (s *otelSpan) End() {
+	s.span.End()
+}
+
+// keyValue converts an attribute of the router, i.e the "http.status_code", to an OpenTelemetry one.
+func keyValue(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case []string:
+		return attribute.StringSlice(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
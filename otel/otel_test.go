@@ -0,0 +1,104 @@
+package otel_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/otel"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	app := iris.New()
+	app.Tracer(otel.New(provider.Tracer("test"), propagation.TraceContext{}))
+	app.Get("/user/{id:uint64}", func(ctx context.Context) {
+		// the span is carried by the request's context.
+		if expected, got := context.GetSpan(ctx).SpanContext().SpanIDString(), trace.SpanContextFromContext(ctx.Request().Context()).SpanID().String(); expected != got {
+			ctx.StatusCode(iris.StatusInternalServerError)
+			return
+		}
+
+		ctx.WriteString(context.GetSpan(ctx).SpanContext().TraceIDString())
+	})
+	app.Get("/fail", func(ctx context.Context) {
+		ctx.StatusCode(iris.StatusInternalServerError)
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/user/42").WithHeader("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01").Expect().
+		Status(iris.StatusOK).Body().Equal("4bf92f3577b34da6a3ce929d0e0e4736")
+	e.GET("/fail").Expect().Status(iris.StatusInternalServerError)
+
+	spans := exporter.GetSpans()
+	if expected, got := 2, len(spans); expected != got {
+		t.Fatalf("expected %d spans but got %d", expected, got)
+	}
+
+	user := spans[0]
+	if expected, got := "GET /user/{id:uint64}", user.Name; expected != got {
+		t.Fatalf("expected span name '%s' but got '%s'", expected, got)
+	}
+	if user.SpanKind != trace.SpanKindServer || !user.Parent.IsRemote() || user.Parent.SpanID().String() != "00f067aa0ba902b7" {
+		t.Fatalf("expected a server span of the remote parent but got %#v", user)
+	}
+	attrs := attribute.NewSet(user.Attributes...)
+	if v, _ := attrs.Value("http.route"); v.AsString() != "/user/{id:uint64}" {
+		t.Fatalf("expected the route's template as the 'http.route' attribute but got '%s'", v.Emit())
+	}
+	if v, _ := attrs.Value("http.target"); v.AsString() != "/user/42" {
+		t.Fatalf("expected the request's path as the 'http.target' attribute but got '%s'", v.Emit())
+	}
+	if v, _ := attrs.Value("http.status_code"); v.AsInt64() != iris.StatusOK {
+		t.Fatalf("expected the 'http.status_code' attribute to be %d but got '%s'", iris.StatusOK, v.Emit())
+	}
+
+	if fail := spans[1]; fail.Status.Code != codes.Error {
+		t.Fatalf("expected an error span but got %#v", fail.Status)
+	}
+}
+
+func TestTracerPanic(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	app := iris.New()
+	app.Tracer(otel.New(provider.Tracer("test"), nil))
+	// the panic is recovered outside of the router, after the span is ended.
+	app.WrapRouter(func(w http.ResponseWriter, r *http.Request, router http.HandlerFunc) {
+		defer func() {
+			recover()
+		}()
+		router(w, r)
+	})
+	app.Get("/panic", func(ctx context.Context) {
+		panic("oops")
+	})
+
+	e := httptest.New(t, app)
+	e.GET("/panic").Expect()
+
+	spans := exporter.GetSpans()
+	if expected, got := 1, len(spans); expected != got {
+		t.Fatalf("expected %d spans but got %d", expected, got)
+	}
+
+	span := spans[0]
+	if span.Status.Code != codes.Error {
+		t.Fatalf("expected an error span but got %#v", span.Status)
+	}
+	if expected, got := 1, len(span.Events); expected != got || span.Events[0].Name != "exception" {
+		t.Fatalf("expected the panic to be recorded as an exception event but got %#v", span.Events)
+	}
+}
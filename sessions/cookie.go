@@ -19,14 +19,10 @@ var (
 )
 
 // GetCookie returns cookie's value by it's name
-// returns empty string if nothing was found
+// returns empty string if nothing was found.
+// It's the `context.GetCookie`, a modified signed cookie is not found, see `Configuration#CookieSecret`.
 func GetCookie(ctx context.Context, name string) string {
-	c, err := ctx.Request().Cookie(name)
-	if err != nil {
-		return ""
-	}
-
-	return c.Value
+	return ctx.GetCookie(name)
 }
 
 // AddCookie adds a cookie
@@ -37,26 +33,51 @@ func AddCookie(ctx context.Context, cookie *http.Cookie, reclaim bool) {
 	ctx.SetCookie(cookie)
 }
 
-// RemoveCookie deletes a cookie by it's name/key
-// If "purge" is true then it removes the, temp, cookie from the request as well.
+// RemoveCookie deletes a cookie by it's name/key through the `context.RemoveCookie`.
+// If "config.AllowReclaim" is true then it removes the, temp, cookie from the request as well.
 func RemoveCookie(ctx context.Context, config Config) {
-	cookie, err := ctx.Request().Cookie(config.Cookie)
-	if err != nil {
-		return
+	// the context.RemoveCookie clears the request's cookies too.
+	requestCookies := ctx.Request().Header["Cookie"]
+
+	if domain := formatCookieDomain(ctx, config.DisableSubdomainPersistence); domain != "" {
+		ctx.RemoveCookie(config.Cookie, context.CookieDomain(domain))
+	} else {
+		ctx.RemoveCookie(config.Cookie)
 	}
 
-	cookie.Expires = CookieExpireDelete
-	// MaxAge<0 means delete cookie now, equivalently 'Max-Age: 0'
-	cookie.MaxAge = -1
-	cookie.Value = ""
-	cookie.Path = "/"
-	cookie.Domain = formatCookieDomain(ctx, config.DisableSubdomainPersistence)
+	if !config.AllowReclaim {
+		ctx.Request().Header["Cookie"] = requestCookies
+	}
+}
 
-	AddCookie(ctx, cookie, config.AllowReclaim)
+// cookieExpires is a `context.CookieOption` which sets the session cookie's lifetime,
+// a negative "expires" makes it a browser session cookie and a zero one never expires.
+func cookieExpires(expires time.Duration) context.CookieOption {
+	return func(c *http.Cookie) {
+		// MaxAge=0 means no 'Max-Age' attribute specified.
+		// MaxAge<0 means delete cookie now, equivalently 'Max-Age: 0'
+		// MaxAge>0 means Max-Age attribute present and given in seconds
+		if expires < 0 {
+			c.Expires = time.Time{}
+			c.MaxAge = 0
+			return
+		}
+
+		if expires == 0 { // unlimited life
+			c.Expires = CookieExpireUnlimited
+		} else { // > 0
+			c.Expires = time.Now().Add(expires)
+		}
+		c.MaxAge = int(c.Expires.Sub(time.Now()).Seconds())
+	}
+}
 
-	if config.AllowReclaim {
-		// delete request's cookie also, which is temporary available.
-		ctx.Request().Header.Set("Cookie", "")
+// reclaimCookie is a `context.CookieOption` which adds the session cookie to the request as well,
+// so the next handlers of the same request can read it, see `Config#AllowReclaim`.
+// It should be the last option, the reclaimed value is not signed, the `context.GetCookie` reads it as it's.
+func reclaimCookie(ctx context.Context) context.CookieOption {
+	return func(c *http.Cookie) {
+		ctx.Request().AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
 	}
 }
 
@@ -0,0 +1,284 @@
+package file
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/core/errors"
+	"github.com/kataras/iris/sessions"
+
+	"github.com/kataras/golog"
+)
+
+// DefaultFileMode used as the default database's "fileMode"
+// for creating the sessions directory and its session files.
+var (
+	DefaultFileMode = 0755
+)
+
+// fileExt is the extension of each session file.
+const fileExt = ".session"
+
+// Database the file-based session storage,
+// each session is stored to its own file inside the sessions directory.
+//
+// Useful for development and single-instance deployments,
+// prefer the redis, boltdb or badger databases for high load.
+// 基于文件的session存储, 每个session一个文件
+type Database struct {
+	dir      string
+	fileMode os.FileMode
+	mu       sync.RWMutex
+}
+
+// entry is the contents of a session file.
+type entry struct {
+	Expires time.Time         `json:"expires,omitempty"`
+	Values  map[string][]byte `json:"values"`
+}
+
+var errPathMissing = errors.New("directory is required")
+
+var _ sessions.Database = (*Database)(nil)
+
+// New creates and returns a new file-based storage
+// instance which stores the sessions inside the "directory".
+//
+// It will remove any expired session files.
+func New(directory string, fileMode os.FileMode) (*Database, error) {
+	if directory == "" {
+		golog.Error(errPathMissing)
+		return nil, errPathMissing
+	}
+
+	if fileMode <= 0 {
+		fileMode = os.FileMode(DefaultFileMode)
+	}
+
+	if err := os.MkdirAll(directory, fileMode); err != nil {
+		golog.Errorf("error while trying to create the sessions directory %s: %v", directory, err)
+		return nil, err
+	}
+
+	db := &Database{dir: directory, fileMode: fileMode}
+	return db, db.cleanup()
+}
+
+// cleanup removes any expired session files on initialization.
+func (db *Database) cleanup() error {
+	files, err := ioutil.ReadDir(db.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), fileExt) {
+			continue
+		}
+
+		filename := filepath.Join(db.dir, f.Name())
+		e, err := readEntry(filename)
+		if err != nil || e.expired() {
+			os.Remove(filename)
+		}
+	}
+
+	return nil
+}
+
+func (e *entry) expired() bool {
+	return !e.Expires.IsZero() && e.Expires.Before(time.Now())
+}
+
+func (db *Database) filename(sid string) string {
+	// the session id is generated by the manager but it's coming from the client's cookie too,
+	// escape it so it can't point outside of the sessions directory.
+	return filepath.Join(db.dir, url.PathEscape(sid)+fileExt)
+}
+
+func readEntry(filename string) (*entry, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	e := new(entry)
+	if err = json.Unmarshal(b, e); err != nil {
+		return nil, err
+	}
+
+	if e.Values == nil {
+		e.Values = make(map[string][]byte)
+	}
+
+	return e, nil
+}
+
+// read returns the session's entry or a new empty one if not exists,
+// the caller should hold the lock.
+func (db *Database) read(sid string) *entry {
+	e, err := readEntry(db.filename(sid))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			golog.Debugf("unable to read session file for '%s': %v", sid, err)
+		}
+		return &entry{Values: make(map[string][]byte)}
+	}
+
+	return e
+}
+
+// write saves the session's entry to its file, the caller should hold the lock.
+func (db *Database) write(sid string, e *entry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		golog.Debug(err)
+		return
+	}
+
+	if err = ioutil.WriteFile(db.filename(sid), b, db.fileMode); err != nil {
+		golog.Debugf("unable to write session file for '%s': %v", sid, err)
+	}
+}
+
+// Acquire receives a session's lifetime from the database,
+// if the return value is LifeTime{} then the session manager sets the life time based on the expiration duration lives in configuration.
+func (db *Database) Acquire(sid string, expires time.Duration) sessions.LifeTime {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := readEntry(db.filename(sid))
+	if err == nil && !e.expired() {
+		if e.Expires.IsZero() {
+			return sessions.LifeTime{} // does not expire.
+		}
+		return sessions.LifeTime{Time: e.Expires}
+	}
+
+	// not found or expired, create a new one.
+	e = &entry{Values: make(map[string][]byte)}
+	if expires > 0 {
+		e.Expires = time.Now().Add(expires)
+	}
+	db.write(sid, e)
+
+	return sessions.LifeTime{}
+}
+
+// OnUpdateExpiration will re-set the database's session's entry ttl.
+func (db *Database) OnUpdateExpiration(sid string, newExpires time.Duration) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := readEntry(db.filename(sid))
+	if err != nil {
+		return sessions.ErrNotFound
+	}
+
+	e.Expires = time.Now().Add(newExpires)
+	db.write(sid, e)
+	return nil
+}
+
+// Set sets a key value of a specific session.
+// Ignore the "immutable".
+func (db *Database) Set(sid string, lifetime sessions.LifeTime, key string, value interface{}, immutable bool) {
+	valueBytes, err := sessions.DefaultTranscoder.Marshal(value)
+	if err != nil {
+		golog.Debug(err)
+		return
+	}
+
+	db.mu.Lock()
+	e := db.read(sid)
+	e.Values[key] = valueBytes
+	db.write(sid, e)
+	db.mu.Unlock()
+}
+
+// Get retrieves a session value based on the key.
+func (db *Database) Get(sid string, key string) (value interface{}) {
+	db.mu.RLock()
+	e := db.read(sid)
+	db.mu.RUnlock()
+
+	valueBytes, ok := e.Values[key]
+	if !ok {
+		return nil
+	}
+
+	if err := sessions.DefaultTranscoder.Unmarshal(valueBytes, &value); err != nil {
+		golog.Debugf("unable to retrieve value of key '%s' of '%s': %v", key, sid, err)
+	}
+
+	return
+}
+
+// Visit loops through all session keys and values.
+func (db *Database) Visit(sid string, cb func(key string, value interface{})) {
+	db.mu.RLock()
+	e := db.read(sid)
+	db.mu.RUnlock()
+
+	for k, v := range e.Values {
+		var value interface{}
+		if err := sessions.DefaultTranscoder.Unmarshal(v, &value); err != nil {
+			golog.Debugf("unable to retrieve value of key '%s' of '%s': %v", k, sid, err)
+			continue
+		}
+
+		cb(k, value)
+	}
+}
+
+// Len returns the length of the session's entries (keys).
+func (db *Database) Len(sid string) int {
+	db.mu.RLock()
+	n := len(db.read(sid).Values)
+	db.mu.RUnlock()
+	return n
+}
+
+// Delete removes a session key value based on its key.
+func (db *Database) Delete(sid string, key string) (deleted bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e := db.read(sid)
+	if _, deleted = e.Values[key]; deleted {
+		delete(e.Values, key)
+		db.write(sid, e)
+	}
+
+	return
+}
+
+// Clear removes all session key values but it keeps the session entry.
+func (db *Database) Clear(sid string) {
+	db.mu.Lock()
+	e := db.read(sid)
+	e.Values = make(map[string][]byte)
+	db.write(sid, e)
+	db.mu.Unlock()
+}
+
+// Release destroys the session, it clears and removes the session entry,
+// session manager will create a new session ID on the next request after this call.
+func (db *Database) Release(sid string) {
+	db.mu.Lock()
+	if err := os.Remove(db.filename(sid)); err != nil && !os.IsNotExist(err) {
+		golog.Debugf("unable to remove session file for '%s': %v", sid, err)
+	}
+	db.mu.Unlock()
+}
+
+// Close does nothing, the session files are kept in order to be restored on the next run.
+func (db *Database) Close() error {
+	return nil
+}
@@ -1,7 +1,6 @@
 package sessions
 
 import (
-	"net/http"
 	"time"
 
 	"github.com/kataras/iris/context"
@@ -34,43 +33,46 @@ func (s *Sessions) UseDatabase(db Database) {
 	s.provider.RegisterDatabase(db)
 }
 
-// updateCookie gains the ability of updating the session browser cookie to any method which wants to update it
+// updateCookie gains the ability of updating the session browser cookie to any method which wants to update it,
+// the cookie is set through the `context.SetCookieKV`, so it's signed by the `Configuration#CookieSecret`, if any.
 func (s *Sessions) updateCookie(ctx context.Context, sid string, expires time.Duration) {
-	cookie := &http.Cookie{}
-
-	// The RFC makes no mention of encoding url value, so here I think to encode both sessionid key and the value using the safe(to put and to use as cookie) url-encoding
-	cookie.Name = s.config.Cookie
-
-	cookie.Value = sid
-	cookie.Path = "/"
-	cookie.Domain = formatCookieDomain(ctx, s.config.DisableSubdomainPersistence)
-	cookie.HttpOnly = true
-	// MaxAge=0 means no 'Max-Age' attribute specified.
-	// MaxAge<0 means delete cookie now, equivalently 'Max-Age: 0'
-	// MaxAge>0 means Max-Age attribute present and given in seconds
-	if expires >= 0 {
-		if expires == 0 { // unlimited life
-			cookie.Expires = CookieExpireUnlimited
-		} else { // > 0
-			cookie.Expires = time.Now().Add(expires)
-		}
-		cookie.MaxAge = int(cookie.Expires.Sub(time.Now()).Seconds())
+	options := []context.CookieOption{cookieExpires(expires)}
+
+	if domain := formatCookieDomain(ctx, s.config.DisableSubdomainPersistence); domain != "" {
+		options = append(options, context.CookieDomain(domain))
 	}
 
 	// set the cookie to secure if this is a tls wrapped request
 	// and the configuration allows it.
 	if ctx.Request().TLS != nil && s.config.CookieSecureTLS {
-		cookie.Secure = true
+		options = append(options, context.CookieSecure(true))
 	}
 
 	// encode the session id cookie client value right before send it.
-	cookie.Value = s.encodeCookieValue(cookie.Value)
-	AddCookie(ctx, cookie, s.config.AllowReclaim)
+	if encode := s.config.Encode; encode != nil {
+		options = append(options, context.CookieEncode(encode))
+	}
+
+	if s.config.AllowReclaim {
+		options = append(options, reclaimCookie(ctx))
+	}
+
+	ctx.SetCookieKV(s.config.Cookie, sid, options...)
+}
+
+// getCookieValue returns the decoded session id of the request's cookie,
+// the cookie is read through the `context.GetCookie`, so a modified signed cookie is not found.
+func (s *Sessions) getCookieValue(ctx context.Context) string {
+	if decode := s.config.Decode; decode != nil {
+		return ctx.GetCookie(s.config.Cookie, context.CookieDecode(decode))
+	}
+
+	return ctx.GetCookie(s.config.Cookie)
 }
 
 // Start should start the session for the particular request.
 func (s *Sessions) Start(ctx context.Context) *Session {
-	cookieValue := s.decodeCookieValue(GetCookie(ctx, s.config.Cookie))
+	cookieValue := s.getCookieValue(ctx)
 
 	if cookieValue == "" { // cookie doesn't exists, let's generate a session and add set a cookie
 		sid := s.config.SessionIDGenerator()
@@ -88,6 +90,29 @@ func (s *Sessions) Start(ctx context.Context) *Session {
 	return sess
 }
 
+// Handler returns a middleware which starts the session for each request
+// and stores it to the context, so the next handlers can retrieve it
+// through the `sessions.Get(ctx)`.
+//
+// Register it through `app.UseSessions(sess)` or `app.Use(sess.Handler())`.
+func (s *Sessions) Handler() context.Handler {
+	return func(ctx context.Context) {
+		sess := s.Start(ctx)
+		ctx.Values().Set(context.SessionContextKey, sess)
+		ctx.Next()
+	}
+}
+
+// Get returns the session of the current request, as started by the `Sessions#Handler`,
+// nil if the sessions middleware is not registered.
+func Get(ctx context.Context) *Session {
+	if sess, ok := ctx.Values().Get(context.SessionContextKey).(*Session); ok {
+		return sess
+	}
+
+	return nil
+}
+
 // ShiftExpiration move the expire date of a session to a new date
 // by using session default timeout configuration.
 // It will return `ErrNotImplemented` if a database is used and it does not support this feature, yet.
@@ -100,7 +125,7 @@ func (s *Sessions) ShiftExpiration(ctx context.Context) error {
 // It will return `ErrNotFound` when trying to update expiration on a non-existence or not valid session entry.
 // It will return `ErrNotImplemented` if a database is used and it does not support this feature, yet.
 func (s *Sessions) UpdateExpiration(ctx context.Context, expires time.Duration) error {
-	cookieValue := s.decodeCookieValue(GetCookie(ctx, s.config.Cookie))
+	cookieValue := s.getCookieValue(ctx)
 	if cookieValue == "" {
 		return ErrNotFound
 	}
@@ -130,16 +155,17 @@ func (s *Sessions) OnDestroy(listeners ...DestroyListener) {
 
 // Destroy remove the session data and remove the associated cookie.
 func (s *Sessions) Destroy(ctx context.Context) {
-	cookieValue := GetCookie(ctx, s.config.Cookie)
 	// decode the client's cookie value in order to find the server's session id
 	// to destroy the session data.
-	cookieValue = s.decodeCookieValue(cookieValue)
+	cookieValue := s.getCookieValue(ctx)
 	if cookieValue == "" { // nothing to destroy
 		return
 	}
 	RemoveCookie(ctx, s.config)
 
 	s.provider.Destroy(cookieValue)
+	// the stored session, if any, is not valid anymore.
+	ctx.Values().Remove(context.SessionContextKey)
 }
 
 // DestroyByID removes the session entry
@@ -160,36 +186,3 @@ func (s *Sessions) DestroyByID(sid string) {
 func (s *Sessions) DestroyAll() {
 	s.provider.DestroyAll()
 }
-
-// let's keep these funcs simple, we can do it with two lines but we may add more things in the future.
-func (s *Sessions) decodeCookieValue(cookieValue string) string {
-	if cookieValue == "" {
-		return ""
-	}
-
-	var cookieValueDecoded *string
-
-	if decode := s.config.Decode; decode != nil {
-		err := decode(s.config.Cookie, cookieValue, &cookieValueDecoded)
-		if err == nil {
-			cookieValue = *cookieValueDecoded
-		} else {
-			cookieValue = ""
-		}
-	}
-
-	return cookieValue
-}
-
-func (s *Sessions) encodeCookieValue(cookieValue string) string {
-	if encode := s.config.Encode; encode != nil {
-		newVal, err := encode(s.config.Cookie, cookieValue)
-		if err == nil {
-			cookieValue = newVal
-		} else {
-			cookieValue = ""
-		}
-	}
-
-	return cookieValue
-}
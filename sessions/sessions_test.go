@@ -1,12 +1,15 @@
 package sessions_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/kataras/iris"
 	"github.com/kataras/iris/context"
 	"github.com/kataras/iris/httptest"
 	"github.com/kataras/iris/sessions"
+	"github.com/kataras/iris/sessions/sessiondb/file"
 )
 
 func TestSessions(t *testing.T) {
@@ -104,6 +107,23 @@ func testSessions(t *testing.T, sess *sessions.Sessions, app *iris.Application)
 	e.GET("/multi_start_set_get").Expect().Status(iris.StatusOK).Body().Equal("value")
 }
 
+func TestSessionsCookieSecret(t *testing.T) {
+	app := iris.New().Configure(iris.WithCookieSecret("secret"))
+	sess := sessions.New(sessions.Config{Cookie: "mycustomsessionid"})
+	testSessions(t, sess, app)
+
+	// a modified session cookie is not found, a new session is started.
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	c := e.POST("/set").WithJSON(map[string]interface{}{"Name": "iris"}).Expect().
+		Status(iris.StatusOK).Cookie("mycustomsessionid").Value()
+	e.GET("/get").Expect().Status(iris.StatusOK).JSON().Object().Equal(map[string]interface{}{"Name": "iris"})
+
+	tampered := c.Raw()
+	tampered = tampered[:len(tampered)-2] + "xx"
+	httptest.New(t, app, httptest.URL("http://example.com")).GET("/get").WithCookie("mycustomsessionid", tampered).
+		Expect().Status(iris.StatusOK).JSON().Object().Empty()
+}
+
 func TestFlashMessages(t *testing.T) {
 	app := iris.New()
 
@@ -192,3 +212,37 @@ func TestFlashMessages(t *testing.T) {
 	e.POST("/set").WithJSON(values).Expect().Status(iris.StatusOK)
 	e.GET("/get_single").Expect().Status(iris.StatusOK).Body().Equal(valueSingleValue)
 }
+
+func TestContextSession(t *testing.T) {
+	app := iris.New()
+	sess := sessions.New(sessions.Config{Cookie: "mycustomsessionid"})
+	dir := filepath.Join(os.TempDir(), "iris_sessions_test")
+	defer os.RemoveAll(dir)
+	db, err := file.New(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess.UseDatabase(db)
+	app.UseSessions(sess)
+
+	app.Get("/set", func(ctx context.Context) {
+		sessions.Get(ctx).Set("name", "iris")
+	})
+
+	app.Get("/get", func(ctx context.Context) {
+		ctx.WriteString(sessions.Get(ctx).GetString("name"))
+	})
+
+	app.Get("/destroy", func(ctx context.Context) {
+		sess.Destroy(ctx)
+		if sessions.Get(ctx) != nil {
+			t.Fatalf("expected a nil session after destroy")
+		}
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	e.GET("/set").Expect().Status(iris.StatusOK).Cookies().NotEmpty()
+	e.GET("/get").Expect().Status(iris.StatusOK).Body().Equal("iris")
+	e.GET("/destroy").Expect().Status(iris.StatusOK)
+	e.GET("/get").Expect().Status(iris.StatusOK).Body().Equal("")
+}
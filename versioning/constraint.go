@@ -0,0 +1,121 @@
+package versioning
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// parseConstraint parses a version constraint, i.e ">= 1, < 3", it supports the semver ranges too:
+//
+//	"1.2.x" or "1.2.*": >= 1.2.0, < 1.3.0
+//	"1.x": >= 1.0.0, < 2.0.0
+//	"x" or "*": any version
+//	"^1.2.3": >= 1.2.3, < 2.0.0
+//	"~1.2.3": >= 1.2.3, < 1.3.0
+func parseConstraint(c string) (version.Constraints, error) {
+	parts := strings.Split(c, ",")
+	for i, part := range parts {
+		parts[i] = expandRange(strings.TrimSpace(part))
+	}
+
+	return version.NewConstraint(strings.Join(parts, ", "))
+}
+
+func expandRange(c string) string {
+	switch {
+	case c == "x" || c == "X" || c == "*":
+		return ">= 0.0.0"
+	case strings.HasPrefix(c, "^"):
+		segments := versionSegments(c[1:])
+		if segments == nil {
+			return c
+		}
+		return ">= " + c[1:] + ", < " + caretUpper(segments)
+	case strings.HasPrefix(c, "~") && !strings.HasPrefix(c, "~>"):
+		segments := versionSegments(c[1:])
+		if segments == nil {
+			return c
+		}
+		minor := 0
+		if len(segments) > 1 {
+			minor = segments[1]
+		}
+		return ">= " + c[1:] + ", < " + strconv.Itoa(segments[0]) + "." + strconv.Itoa(minor+1) + ".0"
+	}
+
+	// the wildcards: "1.x", "1.2.x".
+	fields := strings.Split(c, ".")
+	last := fields[len(fields)-1]
+	if len(fields) < 2 || (last != "x" && last != "X" && last != "*") {
+		return c
+	}
+
+	segments := versionSegments(strings.Join(fields[:len(fields)-1], "."))
+	if segments == nil {
+		return c
+	}
+
+	lower := make([]string, 3)
+	for i := range lower {
+		lower[i] = "0"
+		if i < len(segments) {
+			lower[i] = strconv.Itoa(segments[i])
+		}
+	}
+
+	upper := append([]int(nil), segments...)
+	upper[len(upper)-1]++
+	upperStr := make([]string, 3)
+	for i := range upperStr {
+		upperStr[i] = "0"
+		if i < len(upper) {
+			upperStr[i] = strconv.Itoa(upper[i])
+		}
+	}
+
+	return ">= " + strings.Join(lower, ".") + ", < " + strings.Join(upperStr, ".")
+}
+
+// caretUpper returns the exclusive upper bound of a caret range of the "segments",
+// the left-most non-zero segment is increased, i.e "^1.2.3" is "< 2.0.0", "^0.2.3" is "< 0.3.0"
+// and "^0.0.3" is "< 0.0.4", the last one if they're zeros, i.e "^0.0" is "< 0.1.0".
+func caretUpper(segments []int) string {
+	idx := len(segments) - 1
+	for i, s := range segments {
+		if s != 0 {
+			idx = i
+			break
+		}
+	}
+
+	upper := make([]string, 3)
+	for i := range upper {
+		switch {
+		case i < idx:
+			upper[i] = strconv.Itoa(segments[i])
+		case i == idx:
+			upper[i] = strconv.Itoa(segments[i] + 1)
+		default:
+			upper[i] = "0"
+		}
+	}
+
+	return strings.Join(upper, ".")
+}
+
+// versionSegments returns the numeric segments of "v", i.e [1 2] of "1.2", nil if it's not numeric.
+func versionSegments(v string) []int {
+	fields := strings.Split(strings.TrimSpace(v), ".")
+	segments := make([]int, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return nil
+		}
+		segments = append(segments, n)
+	}
+
+	return segments
+}
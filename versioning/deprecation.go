@@ -36,7 +36,7 @@ func Deprecated(handler context.Handler, options DeprecationOptions) context.Han
 	}
 
 	return func(ctx context.Context) {
-		handler(ctx)
+		// before the handler, the headers can't be modified after the response is written.
 		ctx.Header("X-API-Warn", options.WarnMessage)
 
 		if !options.DeprecationDate.IsZero() {
@@ -46,5 +46,7 @@ func Deprecated(handler context.Handler, options DeprecationOptions) context.Han
 		if options.DeprecationInfo != "" {
 			ctx.Header("X-API-Deprecation-Info", options.DeprecationInfo)
 		}
+
+		handler(ctx)
 	}
 }
@@ -0,0 +1,179 @@
+package versioning
+
+import (
+	"net/http"
+
+	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/core/router"
+
+	"github.com/hashicorp/go-version"
+)
+
+type (
+	versionHandlers struct {
+		constraints version.Constraints
+		handlers    context.Handlers
+	}
+
+	vendpoint struct {
+		method, path string
+		versions     []*versionHandlers
+		// route is the actual route of all the versions.
+		route *router.Route
+	}
+
+	// Party is a `router.Party` which can register the same routes for different API versions,
+	// see `NewParty`.
+	//
+	// The requested version is resolved by the `GetVersion`, the "Accept-Version" and the "Accept" headers by default,
+	// the `FromQuery` and the `FromPath` middlewares can resolve it from a url query parameter or from a path segment instead.
+	// 同一个路由, 按请求的版本(例如 Accept-Version: 1.2.3)来执行不同的handlers
+	Party struct {
+		router.Party
+
+		endpoints []*vendpoint
+		notFound  context.Handler
+	}
+
+	// VersionParty registers the routes of a version constraint of a `Party`, see `Party#Version`.
+	VersionParty struct {
+		p           *Party
+		constraints version.Constraints
+		middleware  context.Handlers
+	}
+)
+
+// NewParty returns a new versioned Party of the "p" Party.
+//
+// Usage:
+//
+//	api := versioning.NewParty(app.Party("/api"))
+//	api.Version("1.x").Get("/users", listUsersV1)
+//	api.Version(">= 2, < 3", authV2).Get("/users", listUsersV2)
+//
+//	// GET /api/users with "Accept-Version: 1.4" executes the listUsersV1,
+//	// with "Accept-Version: 2.1.0" executes the authV2 and listUsersV2.
+func NewParty(p router.Party) *Party {
+	return &Party{Party: p, notFound: NotFoundHandler}
+}
+
+// NotFound sets the handler which is executed when the requested version is missing
+// or no registered version matches it.
+//
+// Defaults to the `NotFoundHandler`.
+func (p *Party) NotFound(handler context.Handler) *Party {
+	p.notFound = handler
+	return p
+}
+
+// Version returns a registrar of the routes of the "constraint" versions, i.e "1.2.x", "^2.0.0" or ">= 1, < 3",
+// the optional "middleware" are executed before the handlers of its routes.
+// When more than one constraints match the requested version the first registered one is executed.
+//
+// It panics if the "constraint" is not valid.
+func (p *Party) Version(constraint string, middleware ...context.Handler) *VersionParty {
+	constraints, err := parseConstraint(constraint)
+	if err != nil {
+		panic(err)
+	}
+
+	return &VersionParty{p: p, constraints: constraints, middleware: middleware}
+}
+
+func (p *Party) handle(method, path string, vh *versionHandlers) *router.Route {
+	for _, e := range p.endpoints {
+		if e.method == method && e.path == path {
+			e.versions = append(e.versions, vh)
+			return e.route
+		}
+	}
+
+	e := &vendpoint{method: method, path: path, versions: []*versionHandlers{vh}}
+	p.endpoints = append(p.endpoints, e)
+	e.route = p.Party.Handle(method, path, p.dispatcher(e))
+	return e.route
+}
+
+// dispatcher returns the handler of the actual route of the "e" versioned endpoint.
+func (p *Party) dispatcher(e *vendpoint) context.Handler {
+	return func(ctx context.Context) {
+		versionString := GetVersion(ctx)
+		if versionString == NotFound {
+			p.notFound(ctx)
+			return
+		}
+
+		ver, err := version.NewVersion(versionString)
+		if err != nil {
+			p.notFound(ctx)
+			return
+		}
+
+		for _, vh := range e.versions {
+			if vh.constraints.Check(ver) {
+				ctx.Header("X-API-Version", ver.String())
+				// the version's handlers, followed by the rest of the route's ones, i.e the `Done` handlers.
+				handlers := append(vh.handlers[0:len(vh.handlers):len(vh.handlers)], ctx.Handlers()[ctx.HandlerIndex(-1)+1:]...)
+				ctx.HandlerIndex(0)
+				ctx.Do(handlers)
+				return
+			}
+		}
+
+		p.notFound(ctx)
+	}
+}
+
+// Deprecated marks the routes of this version as deprecated, their responses
+// contain the `DeprecationOptions` headers. It should be called before the routes' registration.
+// Returns itself.
+func (v *VersionParty) Deprecated(options DeprecationOptions) *VersionParty {
+	v.middleware = append(v.middleware, Deprecated(func(ctx context.Context) { ctx.Next() }, options))
+	return v
+}
+
+// Handle registers the "handlers" of this version to the "method" and the "path" of the Party.
+//
+// Returns the actual route of the "method" and the "path", which is shared by all the versions.
+func (v *VersionParty) Handle(method string, path string, handlers ...context.Handler) *router.Route {
+	vh := &versionHandlers{
+		constraints: v.constraints,
+		handlers:    append(append(context.Handlers{}, v.middleware...), handlers...),
+	}
+	return v.p.handle(method, path, vh)
+}
+
+// Get registers a versioned route for the Get http method.
+func (v *VersionParty) Get(path string, handlers ...context.Handler) *router.Route {
+	return v.Handle(http.MethodGet, path, handlers...)
+}
+
+// Post registers a versioned route for the Post http method.
+func (v *VersionParty) Post(path string, handlers ...context.Handler) *router.Route {
+	return v.Handle(http.MethodPost, path, handlers...)
+}
+
+// Put registers a versioned route for the Put http method.
+func (v *VersionParty) Put(path string, handlers ...context.Handler) *router.Route {
+	return v.Handle(http.MethodPut, path, handlers...)
+}
+
+// Delete registers a versioned route for the Delete http method.
+func (v *VersionParty) Delete(path string, handlers ...context.Handler) *router.Route {
+	return v.Handle(http.MethodDelete, path, handlers...)
+}
+
+// Patch registers a versioned route for the Patch http method.
+func (v *VersionParty) Patch(path string, handlers ...context.Handler) *router.Route {
+	return v.Handle(http.MethodPatch, path, handlers...)
+}
+
+// Head registers a versioned route for the Head http method.
+func (v *VersionParty) Head(path string, handlers ...context.Handler) *router.Route {
+	return v.Handle(http.MethodHead, path, handlers...)
+}
+
+// Options registers a versioned route for the Options http method.
+func (v *VersionParty) Options(path string, handlers ...context.Handler) *router.Route {
+	return v.Handle(http.MethodOptions, path, handlers...)
+}
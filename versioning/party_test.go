@@ -0,0 +1,84 @@
+package versioning_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/httptest"
+	"github.com/kataras/iris/versioning"
+)
+
+func TestIfRanges(t *testing.T) {
+	tests := []struct {
+		version, constraint string
+		expected            bool
+	}{
+		{"1.2.5", "1.2.x", true},
+		{"1.3.0", "1.2.x", false},
+		{"1.9.0", "1.x", true},
+		{"2.0.0", "1.x", false},
+		{"0.1.0", "*", true},
+		{"1.9.9", "^1.2.3", true},
+		{"1.2.2", "^1.2.3", false},
+		{"2.0.0", "^1.2.3", false},
+		{"0.2.9", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"0.0.3", "^0.0.3", true},
+		{"0.0.4", "^0.0.3", false},
+		{"0.0.9", "^0.0", true},
+		{"0.1.0", "^0.0", false},
+		{"0.9.0", "^0", true},
+		{"1.0.0", "^0", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"1.4.0", "1.2.x, 1.4.x", false},
+	}
+
+	for i, tt := range tests {
+		if got := versioning.If(tt.version, tt.constraint); got != tt.expected {
+			t.Fatalf("[%d] expected %s on %s to be %v", i, tt.constraint, tt.version, tt.expected)
+		}
+	}
+}
+
+func TestParty(t *testing.T) {
+	app := iris.New()
+	app.UseRouter(versioning.FromPath("/api"))
+	app.Use(versioning.FromQuery("version"))
+
+	api := versioning.NewParty(app.Party("/api"))
+	route := api.Version("1.2.x").Get("/users", sendHandler(v10Response))
+	// the versions share the same route.
+	if got := api.Version("2.x", func(ctx iris.Context) {
+		ctx.Header("X-Middleware", "v2")
+		ctx.Next()
+	}).Get("/users", sendHandler(v2Response)); route == nil || got != route {
+		t.Fatalf("expected the route of the first version but got %v", got)
+	}
+	api.Version("< 1.2").Deprecated(versioning.DeprecationOptions{
+		DeprecationDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}).Get("/users", sendHandler("old"))
+
+	e := httptest.New(t, app)
+	ex := e.GET("/api/users").WithHeader(versioning.AcceptVersionHeaderKey, "1.2.3").Expect()
+	ex.Status(iris.StatusOK).Body().Equal(v10Response)
+	ex.Header("X-API-Version").Equal("1.2.3")
+
+	ex = e.GET("/api/users").WithHeader(versioning.AcceptVersionHeaderKey, "2.1").Expect()
+	ex.Status(iris.StatusOK).Body().Equal(v2Response)
+	ex.Header("X-Middleware").Equal("v2")
+
+	e.GET("/api/users").WithQuery("version", "2.0.1").Expect().Status(iris.StatusOK).Body().Equal(v2Response)
+	e.GET("/api/v2/users").Expect().Status(iris.StatusOK).Body().Equal(v2Response)
+	e.GET("/api/v1.2.9/users").Expect().Status(iris.StatusOK).Body().Equal(v10Response)
+
+	ex = e.GET("/api/users").WithHeader(versioning.AcceptVersionHeaderKey, "1.0").Expect()
+	ex.Status(iris.StatusOK).Body().Equal("old")
+	ex.Header("X-API-Warn").Equal(versioning.DefaultDeprecationOptions.WarnMessage)
+	ex.Header("X-API-Deprecation-Date").NotEmpty()
+
+	e.GET("/api/users").WithHeader(versioning.AcceptVersionHeaderKey, "3.0").Expect().
+		Status(iris.StatusNotImplemented).Body().Equal("version not found")
+	e.GET("/api/users").Expect().Status(iris.StatusNotImplemented)
+}
@@ -4,6 +4,8 @@ import (
 	"strings"
 
 	"github.com/kataras/iris/context"
+
+	"github.com/hashicorp/go-version"
 )
 
 const (
@@ -93,3 +95,62 @@ func GetVersion(ctx context.Context) string {
 
 	return NotFound
 }
+
+// FromQuery returns a middleware which sets the requested version of `GetVersion`
+// from the "param" url query parameter, i.e "?version=1.2", if it's present,
+// otherwise the version is read from the headers.
+//
+// Usage:
+//
+//	app.Use(versioning.FromQuery("version"))
+func FromQuery(param string) context.Handler {
+	return func(ctx context.Context) {
+		if version := ctx.URLParam(param); version != "" {
+			ctx.Values().Set(Key, version)
+		}
+		ctx.Next()
+	}
+}
+
+// FromPath returns a router middleware which sets the requested version of `GetVersion`
+// from the path segment after the "prefix", i.e "/api/v1.2/users" of the "/api" prefix,
+// and it removes the segment from the path before the routing, so the routes are registered once,
+// i.e "/api/users", for all the versions.
+// The paths without a version segment are served as they're.
+//
+// Usage:
+//
+//	app.UseRouter(versioning.FromPath("/api"))
+func FromPath(prefix string) context.Handler {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix != "/" {
+		prefix += "/"
+	}
+
+	return func(ctx context.Context) {
+		r := ctx.Request()
+		if path := r.URL.Path; strings.HasPrefix(path, prefix) {
+			rem := path[len(prefix):]
+			segment := rem
+			if idx := strings.IndexByte(rem, '/'); idx != -1 {
+				segment, rem = rem[:idx], rem[idx:]
+			} else {
+				rem = ""
+			}
+
+			if len(segment) > 1 && (segment[0] == 'v' || segment[0] == 'V') {
+				if _, err := version.NewVersion(segment[1:]); err == nil {
+					ctx.Values().Set(Key, segment[1:])
+					// 去掉路径中的版本, 例如 /api/v1.2/users => /api/users
+					r.URL.Path = strings.TrimSuffix(prefix, "/") + rem
+					if r.URL.Path == "" {
+						r.URL.Path = "/"
+					}
+					r.URL.RawPath = ""
+				}
+			}
+		}
+
+		ctx.Next()
+	}
+}
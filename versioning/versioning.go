@@ -7,14 +7,14 @@ import (
 )
 
 // If reports whether the "version" is matching to the "is".
-// the "is" can be a constraint like ">= 1, < 3".
+// the "is" can be a constraint like ">= 1, < 3" or a semver range like "1.2.x", "^1.2" and "~1.2.3".
 func If(v string, is string) bool {
 	ver, err := version.NewVersion(v)
 	if err != nil {
 		return false
 	}
 
-	constraints, err := version.NewConstraint(is)
+	constraints, err := parseConstraint(is)
 	if err != nil {
 		return false
 	}
@@ -29,7 +29,7 @@ func Match(ctx context.Context, expectedVersion string) bool {
 }
 
 // Map is a map of versions targets to a handlers,
-// a handler per version or constraint, the key can be something like ">1, <=2" or just "1".
+// a handler per version or constraint, the key can be something like ">1, <=2", "1.2.x" or just "1".
 type Map map[string]context.Handler
 
 // NewMatcher creates a single handler which decides what handler
@@ -81,7 +81,7 @@ func buildConstraints(versionsHandler Map) (constraintsHandlers []*constraintsHa
 			continue
 		}
 
-		constraints, err := version.NewConstraint(v)
+		constraints, err := parseConstraint(v)
 		if err != nil {
 			panic(err)
 		}
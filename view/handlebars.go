@@ -53,6 +53,14 @@ func (s *HandlebarsEngine) Ext() string {
 	return s.extension
 }
 
+// rootDir returns the templates directory, empty for the embedded templates, see `View#Paths`.
+func (s *HandlebarsEngine) rootDir() string {
+	if s.assetFn != nil {
+		return ""
+	}
+	return s.directory
+}
+
 // Binary optionally, use it when template files are distributed
 // inside the app executable (.go generated files).
 //
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/kataras/iris/context"
 )
 
 type (
@@ -33,13 +35,69 @@ type (
 
 		//
 		middleware func(name string, contents []byte) (string, error)
-		Templates  *template.Template
+		// Templates are the parsed templates, they're not executed directly,
+		// each execution uses a copy of them, see `acquireTemplates`.
+		Templates *template.Template
+		// executions is a pool of the copies of the Templates, the per-request functions,
+		// i.e the {{ yield }}, are installed to a copy which is used by a single execution at a time.
+		executions sync.Pool // *htmlExecution
+		// partials are the shared partials of the `View`, they're imported on `Load`
+		// and re-imported when they're reloaded, see `reloadPartials`.
+		partials *Partials
+		// imported are the names of the imported partials.
+		imported map[string]struct{}
+		// tmu guards the Templates on the re-import of the partials, and its error.
+		tmu         sync.RWMutex
+		partialsErr error
 		//
 	}
 )
 
 var _ Engine = &HTMLEngine{}
 
+// htmlExecution is a copy of the engine's Templates, see `HTMLEngine#acquireTemplates`.
+type htmlExecution struct {
+	master *template.Template // the Templates that it was copied from.
+	tpl    *template.Template
+}
+
+// acquireTemplates returns a copy of the Templates which is not used by other executions,
+// the templates are shared between the concurrent requests so the per-request functions
+// cannot be installed on them, i.e one's {{ yield }} would render another request's data.
+// The copies of the previous Templates, before a `Load`, are dropped.
+// 每次执行使用模板的副本, 避免并发请求之间共享每个请求的模板函数
+func (s *HTMLEngine) acquireTemplates() (*htmlExecution, error) {
+	s.tmu.RLock()
+	master, err := s.Templates, s.partialsErr
+	s.tmu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		v := s.executions.Get()
+		if v == nil {
+			break
+		}
+
+		if e := v.(*htmlExecution); e.master == master {
+			return e, nil
+		}
+	}
+
+	// the master is never executed, so it can be cloned.
+	tpl, err := master.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	return &htmlExecution{master: master, tpl: tpl}, nil
+}
+
+func (s *HTMLEngine) releaseTemplates(e *htmlExecution) {
+	s.executions.Put(e)
+}
+
 var emptyFuncs = template.FuncMap{
 	"yield": func() (string, error) {
 		return "", fmt.Errorf("yield was called, yet no layout defined")
@@ -58,6 +116,32 @@ var emptyFuncs = template.FuncMap{
 	}, "render": func() (string, error) {
 		return "", nil
 	},
+	// the security context of the request, see `securityFunc`.
+	context.CSPNonceViewDataKey:  securityFunc(context.CSPNonceViewDataKey),
+	context.CSRFTokenViewDataKey: securityFunc(context.CSRFTokenViewDataKey),
+	context.RequestIDViewDataKey: securityFunc(context.RequestIDViewDataKey),
+}
+
+// securityFunc returns the {{ cspNonce . }}, {{ csrfToken . }} or {{ requestID . }} template function,
+// it reads the "key" of the request's security context from the given binding data,
+// which is injected to the map view data by the `context#View`, so it's the same as the {{ .cspNonce }}.
+//
+// The values are passed through the binding data of each execution and not through per-request functions,
+// the templates are shared between the concurrent requests.
+// 安全相关的值通过模板数据传递, 模板是并发共享的, 不能为每个请求设置函数
+func securityFunc(key string) func(binding interface{}) string {
+	return func(binding interface{}) string {
+		var data map[string]interface{}
+		switch v := binding.(type) {
+		case context.Map:
+			data = v
+		case map[string]interface{}:
+			data = v
+		}
+
+		value, _ := data[key].(string)
+		return value
+	}
 }
 
 // HTML creates and returns a new html view engine.
@@ -85,6 +169,14 @@ func (s *HTMLEngine) Ext() string {
 	return s.extension
 }
 
+// rootDir returns the templates directory, empty for the embedded templates, see `View#Paths`.
+func (s *HTMLEngine) rootDir() string {
+	if s.assetFn != nil {
+		return ""
+	}
+	return s.directory
+}
+
 // Binary optionally, use it when template files are distributed
 // inside the app executable (.go generated files).
 //
@@ -286,7 +378,82 @@ func (s *HTMLEngine) loadDirectory() error {
 		return nil
 	})
 
-	return templateErr
+	if templateErr != nil {
+		return templateErr
+	}
+
+	return s.importPartials()
+}
+
+// importPartials parses the shared partials, see `Partials`, to the templates,
+// except the ones with the same name of a template of the engine.
+func (s *HTMLEngine) importPartials() error {
+	if s.partials == nil {
+		return nil
+	}
+
+	imported := make(map[string]struct{})
+	err := s.partials.visitSources(func(name, contents string) error {
+		if s.Templates.Lookup(name) != nil {
+			return nil
+		}
+
+		imported[name] = struct{}{}
+		_, err := s.Templates.New(name).Option(s.options...).Funcs(emptyFuncs).Funcs(s.funcs).Parse(contents)
+		return err
+	})
+
+	s.tmu.Lock()
+	s.imported, s.partialsErr = imported, nil
+	s.tmu.Unlock()
+	return err
+}
+
+// reloadPartials re-imports the "changed" shared partials to a copy of the Templates,
+// the executions of the previous ones are not affected, see `acquireTemplates`.
+// A parse error is returned by the next executions, until a next successful reload.
+// It's a no-op when the `Reload` is true, the `Load` imports them before each execution.
+func (s *HTMLEngine) reloadPartials(changed []string) {
+	if s.reload || s.partials == nil {
+		return
+	}
+
+	names := make(map[string]struct{}, len(changed))
+	for _, name := range changed {
+		names[name] = struct{}{}
+	}
+
+	s.tmu.Lock()
+	defer s.tmu.Unlock()
+
+	if s.Templates == nil {
+		return
+	}
+
+	// the master is never executed, so it can be cloned.
+	tpl, err := s.Templates.Clone()
+	if err == nil {
+		err = s.partials.visitSources(func(name, contents string) error {
+			if _, ok := names[name]; !ok {
+				return nil
+			}
+			// the engine's own templates take precedence.
+			if _, ok := s.imported[name]; !ok && tpl.Lookup(name) != nil {
+				return nil
+			}
+
+			s.imported[name] = struct{}{}
+			_, err := tpl.New(name).Option(s.options...).Funcs(emptyFuncs).Funcs(s.funcs).Parse(contents)
+			return err
+		})
+	}
+
+	if err != nil {
+		s.partialsErr = err
+		return
+	}
+
+	s.Templates, s.partialsErr = tpl, nil
 }
 
 // loadAssets loads the templates by binary (go-bindata for embedded).
@@ -375,27 +542,32 @@ func (s *HTMLEngine) loadAssets() error {
 			tmpl.Funcs(emptyFuncs).Funcs(s.funcs).Parse(contents)
 		}
 	}
-	return templateErr
+
+	if templateErr != nil {
+		return templateErr
+	}
+
+	return s.importPartials()
 }
 
-func (s *HTMLEngine) executeTemplateBuf(name string, binding interface{}) (*bytes.Buffer, error) {
+func (s *HTMLEngine) executeTemplateBuf(tpl *template.Template, name string, binding interface{}) (*bytes.Buffer, error) {
 	buf := new(bytes.Buffer)
-	err := s.Templates.ExecuteTemplate(buf, name, binding)
+	err := tpl.ExecuteTemplate(buf, name, binding)
 
 	return buf, err
 }
 
-func (s *HTMLEngine) layoutFuncsFor(name string, binding interface{}) {
+func (s *HTMLEngine) layoutFuncsFor(tpl *template.Template, name string, binding interface{}) {
 	funcs := template.FuncMap{
 		"yield": func() (template.HTML, error) {
-			buf, err := s.executeTemplateBuf(name, binding)
+			buf, err := s.executeTemplateBuf(tpl, name, binding)
 			// Return safe HTML here since we are rendering our own template.
 			return template.HTML(buf.String()), err
 		},
 		"part": func(partName string) (template.HTML, error) {
 			nameTemp := strings.Replace(name, ".html", "", -1)
 			fullPartName := fmt.Sprintf("%s-%s", nameTemp, partName)
-			buf, err := s.executeTemplateBuf(fullPartName, binding)
+			buf, err := s.executeTemplateBuf(tpl, fullPartName, binding)
 			if err != nil {
 				return "", nil
 			}
@@ -406,8 +578,8 @@ func (s *HTMLEngine) layoutFuncsFor(name string, binding interface{}) {
 		},
 		"partial": func(partialName string) (template.HTML, error) {
 			fullPartialName := fmt.Sprintf("%s-%s", partialName, name)
-			if s.Templates.Lookup(fullPartialName) != nil {
-				buf, err := s.executeTemplateBuf(fullPartialName, binding)
+			if tpl.Lookup(fullPartialName) != nil {
+				buf, err := s.executeTemplateBuf(tpl, fullPartialName, binding)
 				return template.HTML(buf.String()), err
 			}
 			return "", nil
@@ -420,14 +592,14 @@ func (s *HTMLEngine) layoutFuncsFor(name string, binding interface{}) {
 			ext := filepath.Ext(name)
 			root := name[:len(name)-len(ext)]
 			fullPartialName := fmt.Sprintf("%s%s%s", root, partialName, ext)
-			if s.Templates.Lookup(fullPartialName) != nil {
-				buf, err := s.executeTemplateBuf(fullPartialName, binding)
+			if tpl.Lookup(fullPartialName) != nil {
+				buf, err := s.executeTemplateBuf(tpl, fullPartialName, binding)
 				return template.HTML(buf.String()), err
 			}
 			return "", nil
 		},
 		"render": func(fullPartialName string) (template.HTML, error) {
-			buf, err := s.executeTemplateBuf(fullPartialName, binding)
+			buf, err := s.executeTemplateBuf(tpl, fullPartialName, binding)
 			return template.HTML(buf.String()), err
 		},
 	}
@@ -435,22 +607,18 @@ func (s *HTMLEngine) layoutFuncsFor(name string, binding interface{}) {
 	for k, v := range s.layoutFuncs {
 		funcs[k] = v
 	}
-	if tpl := s.Templates.Lookup(name); tpl != nil {
-		tpl.Funcs(funcs)
-	}
+	tpl.Funcs(funcs)
 }
 
-func (s *HTMLEngine) runtimeFuncsFor(name string, binding interface{}) {
+func (s *HTMLEngine) runtimeFuncsFor(tpl *template.Template, name string, binding interface{}) {
 	funcs := template.FuncMap{
 		"render": func(fullPartialName string) (template.HTML, error) {
-			buf, err := s.executeTemplateBuf(fullPartialName, binding)
+			buf, err := s.executeTemplateBuf(tpl, fullPartialName, binding)
 			return template.HTML(buf.String()), err
 		},
 	}
 
-	if tpl := s.Templates.Lookup(name); tpl != nil {
-		tpl.Funcs(funcs)
-	}
+	tpl.Funcs(funcs)
 }
 
 var zero = time.Time{}
@@ -470,12 +638,18 @@ func (s *HTMLEngine) ExecuteWriter(w io.Writer, name string, layout string, bind
 
 	layout = getLayout(layout, s.layout)
 
+	e, err := s.acquireTemplates()
+	if err != nil {
+		return err
+	}
+	defer s.releaseTemplates(e)
+
 	if layout != "" {
-		s.layoutFuncsFor(name, bindingData)
+		s.layoutFuncsFor(e.tpl, name, bindingData)
 		name = layout
 	} else {
-		s.runtimeFuncsFor(name, bindingData)
+		s.runtimeFuncsFor(e.tpl, name, bindingData)
 	}
 
-	return s.Templates.ExecuteTemplate(w, name, bindingData)
+	return e.tpl.ExecuteTemplate(w, name, bindingData)
 }
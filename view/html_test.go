@@ -0,0 +1,87 @@
+package view
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/kataras/iris/context"
+)
+
+func newHTMLTestEngine(t *testing.T, files map[string]string) *HTMLEngine {
+	dir, err := ioutil.TempDir("", "iris-view")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	engine := HTML(dir, ".html")
+	if err := engine.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	return engine
+}
+
+// Run with -race, the security context of a request should not be rendered into another request's page.
+func TestHTMLSecurityFuncsConcurrentRender(t *testing.T) {
+	engine := newHTMLTestEngine(t, map[string]string{
+		"index.html":  `<script nonce="{{ cspNonce . }}"></script><input value="{{ csrfToken . }}">{{ .csrfToken }}`,
+		"layout.html": `<script nonce="{{ cspNonce . }}"></script>{{ yield }}`,
+		"page.html":   `<input value="{{ csrfToken . }}">`,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			nonce, token := fmt.Sprintf("nonce-%d", i), fmt.Sprintf("token-%d", i)
+			data := context.Map{context.CSPNonceViewDataKey: nonce, context.CSRFTokenViewDataKey: token}
+
+			for j := 0; j < 20; j++ {
+				var b bytes.Buffer
+				if err := engine.ExecuteWriter(&b, "index.html", "", data); err != nil {
+					t.Error(err)
+					return
+				}
+				if expected := fmt.Sprintf(`<script nonce="%s"></script><input value="%s">%s`, nonce, token, token); b.String() != expected {
+					t.Errorf("expected:\n%s\nbut got:\n%s", expected, b.String())
+					return
+				}
+
+				b.Reset()
+				if err := engine.ExecuteWriter(&b, "page.html", "layout.html", data); err != nil {
+					t.Error(err)
+					return
+				}
+				if expected := fmt.Sprintf(`<script nonce="%s"></script><input value="%s">`, nonce, token); b.String() != expected {
+					t.Errorf("expected:\n%s\nbut got:\n%s", expected, b.String())
+					return
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	// the missing security context renders empty values.
+	var b bytes.Buffer
+	if err := engine.ExecuteWriter(&b, "index.html", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `<script nonce=""></script><input value="">`; b.String() != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, b.String())
+	}
+}
@@ -0,0 +1,347 @@
+package view
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"text/template/parse"
+	"time"
+
+	"github.com/kataras/iris/core/errors"
+)
+
+// SharedPartialFuncName is the name of the template function which renders a shared partial,
+// it's available to all the registered view engines:
+//
+//	html, amber: {{ shared "header" . }}
+//	django: {{ shared("header", data) }}
+//	handlebars: {{shared "header"}}, the current context is the partial's data.
+const SharedPartialFuncName = "shared"
+
+var errPartialNotFound = errors.New("shared partial '%s' not found")
+
+type partialSource struct {
+	contents string
+	// filename and modTime are set for the partials of the `AddFile`.
+	filename string
+	modTime  time.Time
+}
+
+// Partials is the shared partials registry, the common fragments, i.e headers, footers and components,
+// are registered once and they're available to all the view engines of a `View`,
+// through the `SharedPartialFuncName` function, and to the `context#ViewPartial`.
+//
+// The partials are Go templates (html/template) and they can `define` or `template` each other,
+// the `HTMLEngine` imports them too, so its templates can use them as their own, i.e {{ template "header" . }},
+// a template of the engine with the same name takes precedence.
+// 共享的模板片段，注册一次，所有的模板引擎都可以使用
+type Partials struct {
+	mu      sync.RWMutex
+	sources map[string]*partialSource
+	funcs   template.FuncMap
+	reload  bool
+	// deps are the partials that each partial uses, by name.
+	deps      map[string][]string
+	templates *template.Template
+	onChange  []func(changed []string)
+}
+
+// NewPartials returns a new empty shared partials registry.
+func NewPartials() *Partials {
+	return &Partials{
+		sources: make(map[string]*partialSource),
+		funcs:   make(template.FuncMap),
+		deps:    make(map[string][]string),
+	}
+}
+
+// Reload if true then the partials of the `AddFile` are checked for changes before each render,
+// a changed partial is re-parsed and the `OnChange` listeners are notified with it and its dependents,
+// the partials which use it. Use it on development only.
+// The partials which are imported by the `HTMLEngine` are reloaded when its `Reload` is true too.
+func (p *Partials) Reload(developmentMode bool) *Partials {
+	p.mu.Lock()
+	p.reload = developmentMode
+	p.mu.Unlock()
+	return p
+}
+
+// AddFunc adds the function to the partials' function map.
+func (p *Partials) AddFunc(funcName string, funcBody interface{}) {
+	p.mu.Lock()
+	p.funcs[funcName] = funcBody
+	p.mu.Unlock()
+}
+
+// Add registers the "contents" Go template as the "name" partial.
+func (p *Partials) Add(name, contents string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sources[name] = &partialSource{contents: contents}
+	return p.build()
+}
+
+// AddFile registers the contents of the "filename" as the "name" partial.
+func (p *Partials) AddFile(name, filename string) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sources[name] = &partialSource{contents: string(b), filename: filename, modTime: info.ModTime()}
+	return p.build()
+}
+
+// OnChange registers a listener of the reloaded partials, see `Reload`,
+// the "changed" are the modified partials and their dependents.
+func (p *Partials) OnChange(cb func(changed []string)) {
+	p.mu.Lock()
+	p.onChange = append(p.onChange, cb)
+	p.mu.Unlock()
+}
+
+// Names returns the sorted names of the registered partials.
+func (p *Partials) Names() []string {
+	p.mu.RLock()
+	names := make([]string, 0, len(p.sources))
+	for name := range p.sources {
+		names = append(names, name)
+	}
+	p.mu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// Len returns the number of the registered partials.
+func (p *Partials) Len() int {
+	p.mu.RLock()
+	n := len(p.sources)
+	p.mu.RUnlock()
+	return n
+}
+
+// Dependents returns the sorted names of the partials which use the "name" partial, directly or not.
+func (p *Partials) Dependents(name string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.dependents(name)
+}
+
+func (p *Partials) dependents(names ...string) []string {
+	seen := make(map[string]struct{})
+	queue := append([]string(nil), names...)
+	for len(queue) > 0 {
+		target := queue[0]
+		queue = queue[1:]
+		for partial, deps := range p.deps {
+			if _, ok := seen[partial]; ok {
+				continue
+			}
+			for _, dep := range deps {
+				if dep == target {
+					seen[partial] = struct{}{}
+					queue = append(queue, partial)
+					break
+				}
+			}
+		}
+	}
+
+	dependents := make([]string, 0, len(seen))
+	for name := range seen {
+		dependents = append(dependents, name)
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+// build parses all the sources to a new template set, html/template can't re-parse after an execution.
+func (p *Partials) build() error {
+	t := template.New("").Funcs(template.FuncMap{SharedPartialFuncName: p.Render}).Funcs(p.funcs)
+	deps := make(map[string][]string, len(p.sources))
+	for name, source := range p.sources {
+		tmpl, err := t.New(name).Parse(source.contents)
+		if err != nil {
+			return err
+		}
+		deps[name] = partialDeps(tmpl.Tree.Root)
+	}
+
+	p.templates = t
+	p.deps = deps
+	return nil
+}
+
+// partialDeps returns the names of the templates and the shared partials which are used by the "node".
+func partialDeps(node parse.Node) (deps []string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			deps = append(deps, partialDeps(child)...)
+		}
+	case *parse.TemplateNode:
+		deps = append(deps, n.Name)
+	case *parse.ActionNode:
+		deps = append(deps, partialDeps(n.Pipe)...)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			if len(cmd.Args) > 1 {
+				if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok && ident.Ident == SharedPartialFuncName {
+					if s, ok := cmd.Args[1].(*parse.StringNode); ok {
+						deps = append(deps, s.Text)
+					}
+				}
+			}
+		}
+	case *parse.IfNode:
+		deps = append(deps, partialDeps(n.List)...)
+		deps = append(deps, partialDeps(n.ElseList)...)
+	case *parse.RangeNode:
+		deps = append(deps, partialDeps(n.List)...)
+		deps = append(deps, partialDeps(n.ElseList)...)
+	case *parse.WithNode:
+		deps = append(deps, partialDeps(n.List)...)
+		deps = append(deps, partialDeps(n.ElseList)...)
+	}
+
+	return
+}
+
+// refresh re-reads the modified files of the partials, when the `Reload` is true.
+func (p *Partials) refresh() error {
+	p.mu.RLock()
+	reload := p.reload
+	p.mu.RUnlock()
+	if !reload {
+		return nil
+	}
+
+	return p.reloadFiles()
+}
+
+// files returns the files of the partials of the `AddFile`.
+func (p *Partials) files() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var filenames []string
+	for _, source := range p.sources {
+		if source.filename != "" {
+			filenames = append(filenames, source.filename)
+		}
+	}
+
+	sort.Strings(filenames)
+	return filenames
+}
+
+// reloadFiles re-reads the modified files of the partials and notifies the `OnChange` listeners.
+func (p *Partials) reloadFiles() error {
+	p.mu.Lock()
+	var changed []string
+	for name, source := range p.sources {
+		if source.filename == "" {
+			continue
+		}
+
+		info, err := os.Stat(source.filename)
+		if err != nil || !info.ModTime().After(source.modTime) {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(source.filename)
+		if err != nil {
+			continue
+		}
+
+		source.contents, source.modTime = string(b), info.ModTime()
+		changed = append(changed, name)
+	}
+
+	if len(changed) == 0 {
+		p.mu.Unlock()
+		return nil
+	}
+
+	err := p.build()
+	changed = append(changed, p.dependents(changed...)...)
+	listeners := p.onChange
+	p.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	for _, cb := range listeners {
+		cb(changed)
+	}
+	return nil
+}
+
+// visitSources calls the "visitor" for each registered partial and its contents.
+func (p *Partials) visitSources(visitor func(name, contents string) error) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for name, source := range p.sources {
+		if err := visitor(name, source.contents); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExecuteWriter renders the "name" partial with the "bindingData" to the "w".
+func (p *Partials) ExecuteWriter(w io.Writer, name string, bindingData interface{}) error {
+	if err := p.refresh(); err != nil {
+		return err
+	}
+
+	p.mu.RLock()
+	t := p.templates
+	p.mu.RUnlock()
+
+	if t == nil || t.Lookup(name) == nil {
+		return errPartialNotFound.Format(name)
+	}
+
+	return t.ExecuteTemplate(w, name, bindingData)
+}
+
+// Render returns the rendered "name" partial, the optional "bindingData" is its data.
+// It's the `SharedPartialFuncName` template function of the html based engines.
+func (p *Partials) Render(name string, bindingData ...interface{}) (template.HTML, error) {
+	var data interface{}
+	if len(bindingData) > 0 {
+		data = bindingData[0]
+	}
+
+	buf := new(bytes.Buffer)
+	if err := p.ExecuteWriter(buf, name, data); err != nil {
+		return "", err
+	}
+
+	return template.HTML(buf.String()), nil
+}
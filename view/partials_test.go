@@ -0,0 +1,181 @@
+package view
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePartialFile writes the "contents" to the "filename" with a modification time after the previous one.
+func writePartialFile(t *testing.T, filename, contents string, modTime time.Time) {
+	t.Helper()
+
+	if err := ioutil.WriteFile(filename, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filename, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newPartialsTestView(t *testing.T, files map[string]string) (*View, string) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "iris-partials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	filename := filepath.Join(dir, "header.html")
+	writePartialFile(t, filename, `<h1>{{ .Title }} v1</h1>`, time.Now().Add(-time.Hour))
+
+	templatesDir := filepath.Join(dir, "templates")
+	if err := os.Mkdir(templatesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(templatesDir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v := new(View)
+	v.Register(HTML(templatesDir, ".html"))
+	if err := v.Partials().Reload(true).AddFile("header", filename); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	return v, filename
+}
+
+func TestPartialsReloadHTML(t *testing.T) {
+	v, filename := newPartialsTestView(t, map[string]string{
+		"index.html":  `{{ template "header" . }}|{{ shared "header" . }}`,
+		"layout.html": `[{{ yield }}]`,
+	})
+	v.EnableRenderCache(time.Minute)
+
+	data := map[string]interface{}{"Title": "home"}
+	expected := "<h1>home v1</h1>|<h1>home v1</h1>"
+	if got := renderString(t, v, "index.html", data); got != expected {
+		t.Fatalf("expected %q but got %q", expected, got)
+	}
+
+	writePartialFile(t, filename, `<h1>{{ .Title }} v2</h1>`, time.Now())
+
+	// both the imported and the shared partial are reloaded, the cached render is dropped.
+	expected = "<h1>home v2</h1>|<h1>home v2</h1>"
+	if got := renderString(t, v, "index.html", data); got != expected {
+		t.Fatalf("expected %q after the reload but got %q", expected, got)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := v.ExecuteWriter(buf, "index.html", "layout.html", data); err != nil {
+		t.Fatal(err)
+	}
+	if expected, got := "["+expected+"]", buf.String(); got != expected {
+		t.Fatalf("expected %q with the layout but got %q", expected, got)
+	}
+}
+
+func TestPartialsReloadHTMLPrecedence(t *testing.T) {
+	v, filename := newPartialsTestView(t, map[string]string{
+		"index.html":  `{{ template "header" . }}|{{ shared "header" . }}`,
+		"blocks.html": `{{ define "header" }}own{{ end }}`,
+	})
+
+	writePartialFile(t, filename, `<h1>{{ .Title }} v2</h1>`, time.Now())
+
+	expected := "own|<h1>home v2</h1>"
+	if got := renderString(t, v, "index.html", map[string]interface{}{"Title": "home"}); got != expected {
+		t.Fatalf("expected the engine's template to take precedence %q but got %q", expected, got)
+	}
+}
+
+func TestPartialsReloadHTMLError(t *testing.T) {
+	v, filename := newPartialsTestView(t, map[string]string{
+		"index.html": `{{ template "header" . }}`,
+	})
+
+	data := map[string]interface{}{"Title": "home"}
+	writePartialFile(t, filename, `<h1>{{ .Title </h1>`, time.Now().Add(-time.Minute))
+	if err := v.ExecuteWriter(new(bytes.Buffer), "index.html", "", data); err == nil {
+		t.Fatalf("expected a parse error of the reloaded partial")
+	}
+
+	writePartialFile(t, filename, `<h1>{{ .Title }} fixed</h1>`, time.Now())
+	if expected, got := "<h1>home fixed</h1>", renderString(t, v, "index.html", data); got != expected {
+		t.Fatalf("expected %q after the fix but got %q", expected, got)
+	}
+}
+
+func TestPartialsHandlebars(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-partials-handlebars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, contents := range map[string]string{
+		"index.hbs":   `{{shared "header"}}`,
+		"missing.hbs": `{{shared "missing"}}`,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v := new(View)
+	v.Register(Handlebars(dir, ".hbs"))
+	if err := v.Partials().Add("header", `<h1>{{ .Title }}</h1>`); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]interface{}{"Title": "home"}
+	if expected, got := "<h1>home</h1>", renderString(t, v, "index.hbs", data); got != expected {
+		t.Fatalf("expected %q but got %q", expected, got)
+	}
+
+	err = v.ExecuteWriter(new(bytes.Buffer), "missing.hbs", "", data)
+	if err == nil || !strings.Contains(err.Error(), "'missing' not found") {
+		t.Fatalf("expected the error of the missing partial but got %v", err)
+	}
+}
+
+func TestPartialsDependents(t *testing.T) {
+	p := NewPartials()
+	for name, contents := range map[string]string{
+		"a": `{{ template "b" . }}`,
+		"b": `{{ shared "c" . }}`,
+		"c": `c`,
+		"d": `d`,
+	} {
+		if err := p.Add(name, contents); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if expected, got := []string{"a", "b"}, p.Dependents("c"); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected the dependents %v but got %v", expected, got)
+	}
+
+	if got := p.Dependents("d"); len(got) != 0 {
+		t.Fatalf("expected no dependents but got %v", got)
+	}
+
+	if expected, got := []string{"a", "b", "c", "d"}, p.Names(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected the names %v but got %v", expected, got)
+	}
+}
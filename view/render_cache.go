@@ -0,0 +1,229 @@
+package view
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRenderCacheMaxEntries is the default maximum number
+// of the rendered templates that the render cache can keep.
+var DefaultRenderCacheMaxEntries = 1024
+
+type renderCacheEntry struct {
+	filename string
+	body     []byte
+	expires  time.Time
+}
+
+// renderCache keeps the rendered bytes of the templates
+// based on the hash of the template name, the layout and the binding data.
+// 模板渲染结果的缓存，key是模板名+layout+数据的hash
+type renderCache struct {
+	mu         sync.RWMutex
+	entries    map[string]*renderCacheEntry
+	ttl        time.Duration
+	maxEntries int
+}
+
+func newRenderCache(ttl time.Duration, maxEntries int) *renderCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultRenderCacheMaxEntries
+	}
+
+	return &renderCache{
+		entries:    make(map[string]*renderCacheEntry),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+	}
+}
+
+// RenderCacheKeyer can be implemented by the binding data of a template
+// to provide their own key of the render cache, see `View#EnableRenderCache`.
+// The key should be different for any data which render differently.
+type RenderCacheKeyer interface {
+	RenderCacheKey() string
+}
+
+// key returns the cache key of a render, the binding data are keyed by their `RenderCacheKeyer`
+// or normalized through their JSON representation (map keys are sorted),
+// it returns false if the binding data cannot be fully represented,
+// i.e they contain functions or structs with unexported or `json:"-"` fields,
+// two different data would have the same key, in that case the render should not be cached.
+func (c *renderCache) key(filename, layout string, bindingData interface{}) (string, bool) {
+	var data []byte
+	if keyer, ok := bindingData.(RenderCacheKeyer); ok {
+		data = append([]byte("keyer:"), keyer.RenderCacheKey()...)
+	} else {
+		if !jsonLossless(reflect.ValueOf(bindingData), 0) {
+			return "", false
+		}
+
+		b, err := json.Marshal(bindingData)
+		if err != nil {
+			return "", false
+		}
+		data = b
+	}
+
+	h := sha256.New()
+	h.Write([]byte(filename))
+	h.Write([]byte{0})
+	h.Write([]byte(layout))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// maxLosslessDepth is the maximum depth of the binding data that `jsonLossless` walks,
+// the deeper ones are not cached.
+const maxLosslessDepth = 32
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// jsonLossless reports whether the JSON representation of the "v" keeps all of its values,
+// the unexported and the `json:"-"` struct fields are omitted by the encoding/json.
+// The `json.Marshaler`s, i.e the time.Time, are trusted to represent their whole value.
+func jsonLossless(v reflect.Value, depth int) bool {
+	if depth > maxLosslessDepth {
+		return false
+	}
+
+	if !v.IsValid() {
+		return true
+	}
+
+	if v.Type().Implements(jsonMarshalerType) {
+		return true
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return true
+		}
+		return jsonLossless(v.Elem(), depth+1)
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if !jsonLossless(iter.Value(), depth+1) {
+				return false
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return true // base64.
+		}
+		for i := 0; i < v.Len(); i++ {
+			if !jsonLossless(v.Index(i), depth+1) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.PkgPath != "" { // unexported, the fields of an embedded struct are encoded.
+				if t := f.Type; !f.Anonymous || (t.Kind() != reflect.Struct && (t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct)) {
+					return false
+				}
+			}
+
+			if name := strings.Split(f.Tag.Get("json"), ",")[0]; name == "-" {
+				return false
+			}
+
+			if !jsonLossless(v.Field(i), depth+1) {
+				return false
+			}
+		}
+	case reflect.Func, reflect.Chan, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return false
+	}
+
+	return true
+}
+
+func (c *renderCache) get(key string) ([]byte, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+
+	return entry.body, true
+}
+
+func (c *renderCache) set(key, filename string, body []byte) {
+	now := time.Now()
+
+	c.mu.Lock()
+	if len(c.entries) >= c.maxEntries {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+
+		// still full, drop any of them.
+		for k := range c.entries {
+			if len(c.entries) < c.maxEntries {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = &renderCacheEntry{filename: filename, body: body, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+}
+
+// invalidate removes the cached renders of the "filenames", all if empty.
+func (c *renderCache) invalidate(filenames ...string) {
+	c.mu.Lock()
+	if len(filenames) == 0 {
+		c.entries = make(map[string]*renderCacheEntry)
+	} else {
+		for k, entry := range c.entries {
+			for _, filename := range filenames {
+				if entry.filename == filename {
+					delete(c.entries, k)
+					break
+				}
+			}
+		}
+	}
+	c.mu.Unlock()
+}
+
+// render executes the "render" and keeps its result, if not already cached,
+// and writes the rendered bytes to the "w".
+func (c *renderCache) render(w io.Writer, filename, layout string, bindingData interface{}, render func(w *bytes.Buffer) error) error {
+	key, ok := c.key(filename, layout, bindingData)
+	if ok {
+		if body, found := c.get(key); found {
+			_, err := w.Write(body)
+			return err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := render(buf); err != nil {
+		return err
+	}
+
+	if ok {
+		c.set(key, filename, buf.Bytes())
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
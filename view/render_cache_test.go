@@ -0,0 +1,127 @@
+package view
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type greeting struct {
+	Name string
+	lang string
+}
+
+func (g greeting) Hello() string {
+	if g.lang == "el" {
+		return "Γεια σου " + g.Name
+	}
+
+	return "Hello " + g.Name
+}
+
+type hiddenGreeting struct {
+	Name string
+	Lang string `json:"-"`
+}
+
+type keyedGreeting struct {
+	greeting
+}
+
+func (g keyedGreeting) RenderCacheKey() string {
+	return g.Name + "/" + g.lang
+}
+
+func newCachedTestView(t *testing.T) *View {
+	engine := newHTMLTestEngine(t, map[string]string{
+		"greeting.html": `{{ .Hello }}`,
+		"hidden.html":   `{{ .Lang }} {{ .Name }}`,
+	})
+
+	v := new(View)
+	v.Register(engine)
+	v.EnableRenderCache(time.Minute)
+	return v
+}
+
+func renderString(t *testing.T, v *View, filename string, bindingData interface{}) string {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	if err := v.ExecuteWriter(buf, filename, "", bindingData); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+func TestRenderCacheLossyBindingData(t *testing.T) {
+	v := newCachedTestView(t)
+
+	tests := []struct {
+		filename    string
+		bindingData interface{}
+		expected    string
+	}{
+		// the unexported fields are not part of the JSON.
+		{"greeting.html", greeting{Name: "kataras", lang: "en"}, "Hello kataras"},
+		{"greeting.html", greeting{Name: "kataras", lang: "el"}, "Γεια σου kataras"},
+		{"greeting.html", &greeting{Name: "kataras", lang: "en"}, "Hello kataras"},
+		{"greeting.html", map[string]interface{}{"Hello": "x"}, "x"},
+		// neither the `json:"-"` ones.
+		{"hidden.html", hiddenGreeting{Name: "kataras", Lang: "en"}, "en kataras"},
+		{"hidden.html", hiddenGreeting{Name: "kataras", Lang: "el"}, "el kataras"},
+		// the keyers provide their own key.
+		{"greeting.html", keyedGreeting{greeting{Name: "makis", lang: "en"}}, "Hello makis"},
+		{"greeting.html", keyedGreeting{greeting{Name: "makis", lang: "el"}}, "Γεια σου makis"},
+	}
+
+	for i, tt := range tests {
+		if got := renderString(t, v, tt.filename, tt.bindingData); got != tt.expected {
+			t.Fatalf("[%d] expected %q but got %q", i, tt.expected, got)
+		}
+	}
+
+	if n := len(v.cache.entries); n != 3 {
+		t.Fatalf("expected 3 cached renders, the map and the keyed ones, but got %d", n)
+	}
+}
+
+func TestRenderCache(t *testing.T) {
+	v := newCachedTestView(t)
+
+	type data struct {
+		Name string
+		Lang string
+	}
+
+	render := func(name string) string {
+		return renderString(t, v, "hidden.html", data{Name: name})
+	}
+
+	if got := render("kataras"); got != " kataras" {
+		t.Fatalf("unexpected render %q", got)
+	}
+
+	// change the cached entry, the next render of the same data should be served from the cache.
+	for _, entry := range v.cache.entries {
+		entry.body = []byte("cached")
+	}
+
+	if got := render("kataras"); got != "cached" {
+		t.Fatalf("expected the cached render but got %q", got)
+	}
+	if got := render("makis"); got != " makis" {
+		t.Fatalf("expected a new render for different data but got %q", got)
+	}
+
+	filenames := []string{"/hidden.html"}
+	v.InvalidateRenderCache(filenames...)
+	if filenames[0] != "/hidden.html" {
+		t.Fatalf("the caller's filenames should not be modified but got %q", filenames[0])
+	}
+
+	if got := render("kataras"); got != " kataras" {
+		t.Fatalf("expected a new render after the invalidation but got %q", got)
+	}
+}
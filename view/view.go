@@ -1,9 +1,15 @@
 package view
 
 import (
+	"bytes"
 	"io"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/aymerick/raymond"
+	"github.com/flosch/pongo2"
 	"github.com/kataras/iris/core/errors"
 )
 
@@ -12,6 +18,13 @@ import (
 // for each of the registered view engines.
 type View struct {
 	engines []Engine
+	// cache is the optional render cache, see `EnableRenderCache`.
+	cache *renderCache
+	// partials is the optional shared partials registry, see `Partials`.
+	partials *Partials
+	// mu guards the engines on a reload of the changed templates, see `Reload`.
+	mu      sync.RWMutex
+	changed uint32
 }
 
 // Register registers a view engine.
@@ -32,6 +45,69 @@ func (v *View) Find(filename string) Engine {
 	return nil
 }
 
+// EnableRenderCache enables the render cache, the rendered bytes of a template
+// are kept for "ttl" and they're reused when the same template is executed
+// with the same layout and the same (JSON-equal) binding data.
+// Binding data that cannot be fully represented as JSON, i.e functions
+// or structs with unexported or `json:"-"` fields, are never cached,
+// unless they implement the `RenderCacheKeyer`.
+// The optional "maxEntries" sets the maximum number of the cached renders,
+// defaults to the `DefaultRenderCacheMaxEntries`.
+//
+// Useful for heavy templates that are rendered for anonymous traffic,
+// it should be called before serve.
+func (v *View) EnableRenderCache(ttl time.Duration, maxEntries ...int) {
+	max := 0
+	if len(maxEntries) > 0 {
+		max = maxEntries[0]
+	}
+
+	v.cache = newRenderCache(ttl, max)
+}
+
+// InvalidateRenderCache removes the cached renders of the "filenames",
+// all of them if no filenames are given. It's a no-op if the render cache is not enabled.
+func (v *View) InvalidateRenderCache(filenames ...string) {
+	if v.cache == nil {
+		return
+	}
+
+	// do not modify the caller's slice.
+	names := make([]string, len(filenames))
+	for i, filename := range filenames {
+		if len(filename) > 2 && filename[0] == '/' {
+			filename = filename[1:]
+		}
+		names[i] = filename
+	}
+
+	v.cache.invalidate(names...)
+}
+
+// Partials returns the shared partials registry of the view engines, see `Partials`,
+// it's created on the first call, which should happen before the `Load`.
+func (v *View) Partials() *Partials {
+	if v.partials == nil {
+		v.partials = NewPartials()
+	}
+
+	return v.partials
+}
+
+// HasPartials reports whether the shared partials registry is used.
+func (v *View) HasPartials() bool {
+	return v.partials != nil
+}
+
+// ExecutePartial renders the "name" shared partial with the "bindingData" to the "w".
+func (v *View) ExecutePartial(w io.Writer, name string, bindingData interface{}) error {
+	if v.partials == nil {
+		return errPartialNotFound.Format(name)
+	}
+
+	return v.partials.ExecuteWriter(w, name, bindingData)
+}
+
 // Len returns the length of view engines registered so far.
 func (v *View) Len() int {
 	return len(v.engines)
@@ -54,12 +130,37 @@ func (v *View) ExecuteWriter(w io.Writer, filename string, layout string, bindin
 		return errNoViewEngineForExt.Format(filepath.Ext(filename))
 	}
 
+	if atomic.LoadUint32(&v.changed) == 1 {
+		if err := v.reload(); err != nil {
+			return err
+		}
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if v.partials != nil {
+		if err := v.partials.refresh(); err != nil {
+			return err
+		}
+	}
+
+	if v.cache != nil {
+		return v.cache.render(w, filename, layout, bindingData, func(buf *bytes.Buffer) error {
+			return e.ExecuteWriter(buf, filename, layout, bindingData)
+		})
+	}
+
 	return e.ExecuteWriter(w, filename, layout, bindingData)
 }
 
-// AddFunc adds a function to all registered engines.
+// AddFunc adds a function to all registered engines and to the shared partials.
 // Each template engine that supports functions has its own AddFunc too.
 func (v *View) AddFunc(funcName string, funcBody interface{}) {
+	if v.partials != nil {
+		v.partials.AddFunc(funcName, funcBody)
+	}
+
 	for i, n := 0, len(v.engines); i < n; i++ {
 		e := v.engines[i]
 		if engineFuncer, ok := e.(EngineFuncer); ok {
@@ -68,8 +169,13 @@ func (v *View) AddFunc(funcName string, funcBody interface{}) {
 	}
 }
 
-// Load compiles all the registered engines.
+// Load compiles all the registered engines,
+// the shared partials are registered to them before that, see `Partials`.
 func (v *View) Load() error {
+	if v.partials != nil {
+		v.loadPartials()
+	}
+
 	for i, n := 0, len(v.engines); i < n; i++ {
 		e := v.engines[i]
 		if err := e.Load(); err != nil {
@@ -78,3 +184,104 @@ func (v *View) Load() error {
 	}
 	return nil
 }
+
+// Paths returns the templates directories of the registered engines, except the embedded ones,
+// and the files of the shared partials, i.e to watch them for changes, see `Reload`.
+func (v *View) Paths() []string {
+	var paths []string
+	seen := make(map[string]struct{})
+	add := func(path string) {
+		if _, ok := seen[path]; ok || path == "" {
+			return
+		}
+		seen[path] = struct{}{}
+		paths = append(paths, path)
+	}
+
+	for _, e := range v.engines {
+		if d, ok := e.(interface{ rootDir() string }); ok {
+			add(d.rootDir())
+		}
+	}
+
+	if v.partials != nil {
+		for _, filename := range v.partials.files() {
+			add(filename)
+		}
+	}
+
+	return paths
+}
+
+// Reload marks the templates as changed, the engines and the files of the shared partials
+// are loaded again and the render cache is invalidated before the next execution,
+// which waits for the in-progress ones.
+// Unlike the engines' `Reload`, the templates are not loaded on each execution,
+// it's called on a file change, see the `Paths`.
+func (v *View) Reload() {
+	atomic.StoreUint32(&v.changed, 1)
+}
+
+func (v *View) reload() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if atomic.LoadUint32(&v.changed) == 0 {
+		// reloaded by another execution.
+		return nil
+	}
+
+	if v.partials != nil {
+		if err := v.partials.reloadFiles(); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range v.engines {
+		if err := e.Load(); err != nil {
+			// keep it marked, the next execution will try again.
+			return err
+		}
+	}
+
+	atomic.StoreUint32(&v.changed, 0)
+	v.InvalidateRenderCache()
+	return nil
+}
+
+// loadPartials registers the `SharedPartialFuncName` function to the engines, by their kind,
+// and invalidates the render cache and the partials imported by the `HTMLEngine` when a partial is reloaded.
+func (v *View) loadPartials() {
+	p := v.partials
+	for _, e := range v.engines {
+		switch engine := e.(type) {
+		case *HTMLEngine:
+			engine.partials = p
+			engine.AddFunc(SharedPartialFuncName, p.Render)
+			p.OnChange(engine.reloadPartials)
+		case *DjangoEngine:
+			engine.AddFunc(SharedPartialFuncName, func(name string, bindingData ...interface{}) (*pongo2.Value, error) {
+				contents, err := p.Render(name, bindingData...)
+				return pongo2.AsSafeValue(string(contents)), err
+			})
+		case *HandlebarsEngine:
+			engine.AddFunc(SharedPartialFuncName, func(name string, options *raymond.Options) raymond.SafeString {
+				contents, err := p.Render(name, options.Ctx())
+				if err != nil {
+					// the helpers can't return an error,
+					// raymond recovers it and returns it from the template's execution.
+					panic(err)
+				}
+				return raymond.SafeString(contents)
+			})
+		case EngineFuncer:
+			// the html/template based, i.e amber.
+			engine.AddFunc(SharedPartialFuncName, p.Render)
+		}
+	}
+
+	p.OnChange(func(changed []string) {
+		// the templates which use the partials are not known, invalidate all.
+		v.InvalidateRenderCache()
+	})
+}
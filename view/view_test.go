@@ -0,0 +1,54 @@
+package view
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestViewReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-view-reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "index.html")
+	partialFilename := filepath.Join(dir, "header.partial")
+	writePartialFile(t, filename, `{{ shared "header" . }} v1`, time.Now().Add(-time.Hour))
+	writePartialFile(t, partialFilename, `<h1>{{ .Title }}</h1>`, time.Now().Add(-time.Hour))
+
+	v := new(View)
+	v.Register(HTML(dir, ".html"))
+	v.EnableRenderCache(time.Minute)
+	if err = v.Partials().AddFile("header", partialFilename); err != nil {
+		t.Fatal(err)
+	}
+	if err = v.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := []string{dir, partialFilename}, v.Paths(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected the paths %v but got %v", expected, got)
+	}
+
+	data := map[string]interface{}{"Title": "home"}
+	if expected, got := "<h1>home</h1> v1", renderString(t, v, "index.html", data); expected != got {
+		t.Fatalf("expected %q but got %q", expected, got)
+	}
+
+	writePartialFile(t, filename, `{{ shared "header" . }} v2`, time.Now())
+	writePartialFile(t, partialFilename, `<h2>{{ .Title }}</h2>`, time.Now())
+	// the templates are not loaded on each execution.
+	if expected, got := "<h1>home</h1> v1", renderString(t, v, "index.html", data); expected != got {
+		t.Fatalf("expected %q before the reload but got %q", expected, got)
+	}
+
+	v.Reload()
+	if expected, got := "<h2>home</h2> v2", renderString(t, v, "index.html", data); expected != got {
+		t.Fatalf("expected %q after the reload but got %q", expected, got)
+	}
+}
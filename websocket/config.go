@@ -108,6 +108,14 @@ type Config struct {
 	// subprotocol by selecting the first match in this list with a protocol
 	// requested by the client.
 	Subprotocols []string
+
+	// SSEOwner identifies the owner of the SSE fallback transport's requests,
+	// i.e by the session's ID or the authenticated user's one.
+	// If not nil then the client's messages (POST requests) are accepted only
+	// when they have the same, non-empty, owner with their stream's request.
+	//
+	// Defaults to nil, the connection's token is required only.
+	SSEOwner func(ctx context.Context) string
 }
 
 // Validate validates the configuration
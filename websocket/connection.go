@@ -239,6 +239,8 @@ type (
 		ctx    context.Context
 		values ConnectionValues
 		server *Server
+		// unregister removes the connection from the application's long-lived connections, see `Server#registerLongLived`.
+		unregister func()
 		// #119 , websocket writers are not protected by locks inside the gorilla's websocket code
 		// so we must protect them otherwise we're getting concurrent connection error on multi writers in the same time.
 		writerMu sync.Mutex
@@ -257,6 +259,13 @@ var _ Connection = &connection{}
 // Use the `Connection#Disconnect` instead.
 const CloseMessage = websocket.CloseMessage
 
+const (
+	// TextMessage denotes a text data message, see `Connection#Write`.
+	TextMessage = websocket.TextMessage
+	// BinaryMessage denotes a binary data message, see `Connection#Write`.
+	BinaryMessage = websocket.BinaryMessage
+)
+
 func newConnection(ctx context.Context, s *Server, underlineConn UnderlineConnection, id string) *connection {
 	c := &connection{
 		underline:                underlineConn,
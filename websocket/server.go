@@ -2,9 +2,11 @@ package websocket
 
 import (
 	"bytes"
+	"errors"
 	"sync"
 
 	"github.com/kataras/iris/context"
+	"github.com/kataras/iris/events"
 
 	"github.com/gorilla/websocket"
 )
@@ -124,7 +126,7 @@ func (s *Server) Handler() context.Handler {
 func (s *Server) Upgrade(ctx context.Context) Connection {
 	conn, err := s.upgrader.Upgrade(ctx.ResponseWriter(), ctx.Request(), ctx.ResponseWriter().Header())
 	if err != nil {
-		ctx.Application().Logger().Warnf("websocket error: %v\n", err)
+		context.Logger(ctx).Warnf("websocket error: %v", err)
 		ctx.StatusCode(503) // Status Service Unavailable
 		return &connection{err: err}
 	}
@@ -132,6 +134,50 @@ func (s *Server) Upgrade(ctx context.Context) Connection {
 	return s.handleConnection(ctx, conn)
 }
 
+// UpgradeContext upgrades the request's connection to a websocket one
+// and fires the `OnConnection` listeners, it's called by the `context.Context#Upgrade`.
+// The caller should call the connection's `Wait` last, after the registration of its callbacks.
+//
+// Usage:
+// app.Get("/chat/{room}", func(ctx iris.Context) {
+// 	c, err := ctx.Upgrade(ws)
+// 	if err != nil {
+// 		return
+// 	}
+// 	conn := c.(websocket.Connection)
+// 	conn.Join(ctx.Params().Get("room"))
+// 	conn.OnMessage(func(data []byte) {
+// 		conn.To(ctx.Params().Get("room")).EmitMessage(data)
+// 	})
+// 	conn.Wait()
+// })
+//
+// A request without the websocket "Upgrade" header is answered with a "426 Upgrade Required"
+// and the `ErrUpgradeRequired` is returned.
+func (s *Server) UpgradeContext(ctx context.Context) (context.UpgradedConn, error) {
+	if !websocket.IsWebSocketUpgrade(ctx.Request()) {
+		ctx.Header("Upgrade", "websocket")
+		ctx.Header("Connection", "Upgrade")
+		ctx.StatusCode(426) // Status Upgrade Required
+		return nil, ErrUpgradeRequired
+	}
+
+	c := s.Upgrade(ctx)
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range s.onConnectionListeners {
+		s.onConnectionListeners[i](c)
+	}
+
+	return c, nil
+}
+
+// ErrUpgradeRequired is returned by the `Server#UpgradeContext`
+// when the request is not a websocket upgrade one.
+var ErrUpgradeRequired = errors.New("websocket: the client is not using the websocket protocol")
+
 func (s *Server) addConnection(c *connection) {
 	s.connections.Store(c.id, c)
 }
@@ -160,6 +206,8 @@ func (s *Server) handleConnection(ctx context.Context, websocketConn UnderlineCo
 
 	// join to itself
 	s.Join(c.id, c.id)
+	// notified on the shutdown of the hosts.
+	s.registerLongLived(c)
 
 	return c
 }
@@ -411,6 +459,29 @@ func (s *Server) emitMessage(from, to string, data []byte) {
 	}
 }
 
+// EmitEvents emits the events of the "topic" of the "bus", i.e the `Application#Events`, to all the connections,
+// the websocket event's name is the event's topic and its message is the event's payload.
+// The events are emitted by an asynchronous subscriber, the publishers don't wait for the clients,
+// its serialization errors are passed to the bus' `events#Config.OnError`.
+// The "topic" can end with a "*", see `events#Bus.Subscribe`.
+//
+// Usage:
+//
+//	ws.EmitEvents(app.Events(), "products.*")
+//	// [...]
+//	app.Events().Publish("products.updated", product)
+func (s *Server) EmitEvents(bus *events.Bus, topic string) *events.Subscription {
+	return bus.SubscribeAsync(topic, func(evt events.Event) {
+		message, err := s.messageSerializer.serialize(evt.Topic, evt.Payload)
+		if err != nil {
+			// recovered by the bus.
+			panic(err)
+		}
+
+		s.emitMessage("", All, message)
+	})
+}
+
 // Disconnect force-disconnects a websocket connection based on its connection.ID()
 // What it does?
 // 1. remove the connection from the list
@@ -433,6 +504,9 @@ func (s *Server) Disconnect(connID string) (err error) {
 		err = conn.underline.Close()
 
 		s.connections.Delete(connID)
+		if conn.unregister != nil {
+			conn.unregister()
+		}
 	}
 
 	return
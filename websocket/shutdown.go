@@ -0,0 +1,60 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/kataras/iris/context"
+
+	"github.com/gorilla/websocket"
+)
+
+// ShutdownEvent is the name of the final SSE event which is sent to the clients
+// of the SSE transport when the server is shutting down.
+const ShutdownEvent = "shutdown"
+
+// shutdownWriteTimeout is the write deadline of the close frame when there is no `Config#WriteTimeout`.
+const shutdownWriteTimeout = time.Second
+
+// longLivedConn is the `context#LongLivedConn` of a connection,
+// it's registered to the application on connect, so the shutdown of its hosts
+// notifies the client and closes the connection after a grace period, see `host#Supervisor.Connections`.
+type longLivedConn struct {
+	c *connection
+}
+
+var _ context.LongLivedConn = longLivedConn{}
+
+// NotifyShutdown sends a "going away" close frame to the websocket clients,
+// they respond with a close frame and the connection is closed,
+// and a final `ShutdownEvent` event to the SSE clients.
+func (l longLivedConn) NotifyShutdown() {
+	c := l.c
+	if sseConn, ok := c.underline.(*sseConnection); ok {
+		sseConn.writeEvent(ShutdownEvent, []byte("server shutdown"))
+		return
+	}
+
+	timeout := c.server.config.WriteTimeout
+	if timeout <= 0 {
+		timeout = shutdownWriteTimeout
+	}
+
+	c.writerMu.Lock()
+	c.underline.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutdown"), time.Now().Add(timeout))
+	c.writerMu.Unlock()
+}
+
+// Close disconnects the connection.
+func (l longLivedConn) Close() error {
+	return l.c.Disconnect()
+}
+
+// registerLongLived registers the "c" to its application, it's unregistered on disconnect.
+func (s *Server) registerLongLived(c *connection) {
+	if c.ctx == nil {
+		return
+	}
+
+	c.unregister = c.ctx.Application().RegisterLongLivedConn(longLivedConn{c})
+}
@@ -0,0 +1,413 @@
+package websocket
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris/context"
+
+	"github.com/gorilla/websocket"
+)
+
+// SSE fallback transport.
+//
+// Clients behind proxies that do not allow the websocket upgrade can use
+// the same server, with the same `Connection` API (`On`, `Emit`, rooms),
+// through Server-Sent Events for the server to client messages
+// and POST requests for the client to server messages:
+//
+// 1. GET the endpoint with "Accept: text/event-stream", the first event is the "token" one
+// and its data is the connection's secret token, the next one is the "open" event
+// and its data is the connection's ID, each next server message is sent as a "message" event.
+// 2. POST the messages to the same endpoint with the connection's ID
+// as the `SSEConnectionIDHeaderKey` request header's value and its token
+// as the `SSEConnectionTokenHeaderKey` one, one message per request body.
+//
+// The token is sent only to the stream's owner, the ID may be known to others,
+// i.e when it's generated by the `Config#IDGenerator`.
+// See the `Config#SSEOwner` too.
+//
+// See `Server#HandlerWithFallback` and `Server#SSEHandler`.
+// 当客户端无法使用websocket时，用SSE(服务端->客户端) + POST(客户端->服务端)代替
+
+// SSEConnectionIDHeaderKey is the request header which the clients of the SSE transport
+// should send their connection's ID on their POST requests.
+const SSEConnectionIDHeaderKey = "X-Websocket-Connection-Id"
+
+// SSEConnectionTokenHeaderKey is the request header which the clients of the SSE transport
+// should send the token, of their stream's "token" event, on their POST requests.
+const SSEConnectionTokenHeaderKey = "X-Websocket-Connection-Token"
+
+var (
+	errSSEClosed      = errors.New("sse connection closed")
+	errSSEReadTimeout = errors.New("sse connection read timeout")
+	errSSEReadLimit   = errors.New("sse message exceeds the read limit")
+)
+
+// sseConnection is the `UnderlineConnection` of the SSE transport.
+type sseConnection struct {
+	ctx context.Context
+	// done is the `context.Done` of the stream's request,
+	// it's captured by the handler because the connection is used by other goroutines.
+	done <-chan struct{}
+	// w is the response writer which the events are written to and flushed.
+	w context.ResponseWriter
+	// token and owner authenticate the POST requests of the stream's client.
+	token string
+	owner string
+
+	mu           sync.Mutex
+	readDeadline time.Time
+	readLimit    int64
+	pongHandler  func(appData string) error
+
+	incoming  chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+var _ UnderlineConnection = (*sseConnection)(nil)
+
+func newSSEConnection(ctx context.Context, w context.ResponseWriter, owner string) *sseConnection {
+	return &sseConnection{
+		ctx:      ctx,
+		done:     context.Done(ctx),
+		w:        w,
+		token:    randomString(32),
+		owner:    owner,
+		incoming: make(chan []byte, 16),
+		closed:   make(chan struct{}),
+	}
+}
+
+func (c *sseConnection) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetWriteDeadline is a no-op, the writes are flushed directly to the response.
+func (c *sseConnection) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *sseConnection) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline = t
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *sseConnection) SetReadLimit(limit int64) {
+	c.mu.Lock()
+	c.readLimit = limit
+	c.mu.Unlock()
+}
+
+func (c *sseConnection) SetPongHandler(h func(appData string) error) {
+	c.mu.Lock()
+	c.pongHandler = h
+	c.mu.Unlock()
+}
+
+// SetPingHandler is a no-op, the clients cannot send pings through SSE.
+func (c *sseConnection) SetPingHandler(h func(appData string) error) {}
+
+func (c *sseConnection) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	return c.WriteMessage(messageType, data)
+}
+
+// writeEvent writes a single event to the response and flushes it.
+func (c *sseConnection) writeEvent(event string, data []byte) error {
+	var b bytes.Buffer
+	if event != "" {
+		b.WriteString("event: ")
+		b.WriteString(event)
+		b.WriteByte('\n')
+	}
+
+	// each line of the data should be prefixed.
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		b.WriteString("data: ")
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+
+	return c.write(b.Bytes())
+}
+
+func (c *sseConnection) write(b []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isClosed() {
+		return errSSEClosed
+	}
+
+	select {
+	case <-c.done:
+		// the execution of the stream's request is stopped, nothing should be flushed.
+		return errSSEClosed
+	default:
+	}
+
+	if _, err := c.w.Write(b); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return nil
+}
+
+func (c *sseConnection) WriteMessage(messageType int, data []byte) error {
+	switch messageType {
+	case websocket.PingMessage:
+		// a comment keeps the stream alive, if it's written then the client is still there.
+		if err := c.write([]byte(": ping\n\n")); err != nil {
+			return err
+		}
+
+		c.mu.Lock()
+		pongHandler := c.pongHandler
+		c.mu.Unlock()
+		if pongHandler != nil {
+			return pongHandler("")
+		}
+		return nil
+	case websocket.PongMessage:
+		return nil
+	case websocket.CloseMessage:
+		return c.Close()
+	default:
+		return c.writeEvent("message", data)
+	}
+}
+
+func (c *sseConnection) ReadMessage() (int, []byte, error) {
+	for {
+		c.mu.Lock()
+		deadline := c.readDeadline
+		c.mu.Unlock()
+
+		var (
+			timer   *time.Timer
+			timeout <-chan time.Time
+		)
+		if !deadline.IsZero() {
+			d := time.Until(deadline)
+			if d <= 0 {
+				return 0, nil, errSSEReadTimeout
+			}
+			timer = time.NewTimer(d)
+			timeout = timer.C
+		}
+
+		select {
+		case data := <-c.incoming:
+			stopTimer(timer)
+			return websocket.TextMessage, data, nil
+		case <-c.closed:
+			stopTimer(timer)
+			return 0, nil, errSSEClosed
+		case <-c.ctx.Request().Context().Done():
+			stopTimer(timer)
+			return 0, nil, errSSEClosed
+		case <-c.done:
+			// the execution of the stream's request is stopped.
+			stopTimer(timer)
+			return 0, nil, errSSEClosed
+		case <-timeout:
+			// the deadline may be extended in the meantime (by a pong), check again.
+		}
+	}
+}
+
+func stopTimer(t *time.Timer) {
+	if t != nil {
+		t.Stop()
+	}
+}
+
+type sseMessageWriter struct {
+	bytes.Buffer
+	conn        *sseConnection
+	messageType int
+}
+
+func (w *sseMessageWriter) Close() error {
+	return w.conn.WriteMessage(w.messageType, w.Bytes())
+}
+
+func (c *sseConnection) NextWriter(messageType int) (io.WriteCloser, error) {
+	return &sseMessageWriter{conn: c, messageType: messageType}, nil
+}
+
+func (c *sseConnection) Close() error {
+	// waits for the in-flight write, the next ones are refused.
+	c.mu.Lock()
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+	c.mu.Unlock()
+	return nil
+}
+
+// isOwner reports whether the "ctx" is a request of the stream's client,
+// it should send the connection's token and, if the "ownerFunc" is not nil, it should have the same owner.
+func (c *sseConnection) isOwner(ctx context.Context, ownerFunc func(context.Context) string) bool {
+	token := ctx.GetHeader(SSEConnectionTokenHeaderKey)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(c.token)) != 1 {
+		return false
+	}
+
+	if ownerFunc != nil {
+		owner := ownerFunc(ctx)
+		return owner != "" && subtle.ConstantTimeCompare([]byte(owner), []byte(c.owner)) == 1
+	}
+
+	return true
+}
+
+// receive reads a client's message from the "body" and passes it to the connection's reader.
+func (c *sseConnection) receive(body io.Reader) error {
+	c.mu.Lock()
+	limit := c.readLimit
+	c.mu.Unlock()
+
+	if limit > 0 {
+		body = io.LimitReader(body, limit+1)
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	if limit > 0 && int64(len(data)) > limit {
+		return errSSEReadLimit
+	}
+
+	select {
+	case c.incoming <- data:
+		return nil
+	case <-c.closed:
+		return errSSEClosed
+	}
+}
+
+// SSEHandler returns the handler of the SSE fallback transport,
+// it should be registered for both GET and POST methods of the same path,
+// i.e `app.Any("/events", ws.SSEHandler())`.
+// The connections share the same API and rooms with the websocket ones.
+//
+// See `HandlerWithFallback` too.
+func (s *Server) SSEHandler() context.Handler {
+	return func(ctx context.Context) {
+		if ctx.Method() == http.MethodPost {
+			s.receiveSSEMessage(ctx)
+			return
+		}
+
+		// the events are streamed, the gzip writer would keep them until the end of the request.
+		if gzipWriter, ok := ctx.ResponseWriter().(*context.GzipResponseWriter); ok {
+			ctx.ResetResponseWriter(gzipWriter.ResponseWriter)
+		}
+
+		w := ctx.ResponseWriter()
+		if _, ok := w.Flusher(); !ok {
+			ctx.StatusCode(http.StatusHTTPVersionNotSupported)
+			return
+		}
+
+		ctx.ContentType("text/event-stream")
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Header("Connection", "keep-alive")
+		// disable buffering of reverse proxies, i.e nginx.
+		ctx.Header("X-Accel-Buffering", "no")
+
+		var owner string
+		if s.config.SSEOwner != nil {
+			owner = s.config.SSEOwner(ctx)
+		}
+
+		sseConn := newSSEConnection(ctx, w, owner)
+		c := s.handleConnection(ctx, sseConn)
+
+		if err := sseConn.writeEvent("token", []byte(sseConn.token)); err != nil {
+			c.Disconnect()
+			return
+		}
+
+		if err := sseConn.writeEvent("open", []byte(c.id)); err != nil {
+			c.Disconnect()
+			return
+		}
+
+		for i := range s.onConnectionListeners {
+			s.onConnectionListeners[i](c)
+		}
+
+		c.Wait()
+		// the response should not be written after the handler returns.
+		sseConn.Close()
+	}
+}
+
+func (s *Server) receiveSSEMessage(ctx context.Context) {
+	c, ok := s.getConnection(ctx.GetHeader(SSEConnectionIDHeaderKey))
+	if !ok {
+		ctx.NotFound()
+		return
+	}
+
+	sseConn, ok := c.underline.(*sseConnection)
+	if !ok {
+		// it's a websocket connection.
+		ctx.StatusCode(http.StatusBadRequest)
+		return
+	}
+
+	if !sseConn.isOwner(ctx, s.config.SSEOwner) {
+		// do not tell the difference between a wrong token and a missing connection.
+		ctx.NotFound()
+		return
+	}
+
+	if err := sseConn.receive(ctx.Request().Body); err != nil {
+		if err == errSSEReadLimit {
+			ctx.StatusCode(http.StatusRequestEntityTooLarge)
+			return
+		}
+		ctx.StatusCode(http.StatusGone)
+		return
+	}
+
+	ctx.StatusCode(http.StatusNoContent)
+}
+
+// HandlerWithFallback returns a handler which upgrades the websocket requests, like the `Handler`,
+// and serves the rest of them through the SSE fallback transport, like the `SSEHandler`.
+// It should be registered for both GET and POST methods of the same path,
+// i.e `app.Any("/events", ws.HandlerWithFallback())`.
+func (s *Server) HandlerWithFallback() context.Handler {
+	websocketHandler := s.Handler()
+	sseHandler := s.SSEHandler()
+
+	return func(ctx context.Context) {
+		if websocket.IsWebSocketUpgrade(ctx.Request()) {
+			websocketHandler(ctx)
+			return
+		}
+
+		sseHandler(ctx)
+	}
+}
@@ -0,0 +1,241 @@
+package websocket_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/events"
+	"github.com/kataras/iris/websocket"
+)
+
+type sseClient struct {
+	t      *testing.T
+	url    string
+	header http.Header
+	resp   *http.Response
+	events *bufio.Reader
+}
+
+func newSSEServer(t *testing.T, config websocket.Config, messages chan<- string, middleware ...iris.Handler) *httptest.Server {
+	ws := websocket.New(config)
+	ws.OnConnection(func(c websocket.Connection) {
+		c.OnMessage(func(data []byte) {
+			messages <- string(data)
+			c.EmitMessage([]byte("echo: " + string(data)))
+		})
+	})
+
+	app := iris.New()
+	app.Any("/events", append(middleware, ws.SSEHandler())...)
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(app)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func openSSE(t *testing.T, url string, header http.Header) *sseClient {
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	if expected, got := "text/event-stream", resp.Header.Get("Content-Type"); !strings.HasPrefix(got, expected) {
+		t.Fatalf("expected content type '%s' but got '%s'", expected, got)
+	}
+
+	return &sseClient{t: t, url: url, header: header, resp: resp, events: bufio.NewReader(resp.Body)}
+}
+
+// next reads the next event of the stream, the comments are skipped.
+func (c *sseClient) next() (event, data string) {
+	done := make(chan struct{})
+	timer := time.AfterFunc(5*time.Second, func() {
+		c.resp.Body.Close()
+		close(done)
+	})
+	defer timer.Stop()
+
+	for {
+		line, err := c.events.ReadString('\n')
+		if err != nil {
+			select {
+			case <-done:
+				c.t.Fatalf("timeout while waiting for an event")
+			default:
+			}
+			c.t.Fatal(err)
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && (event != "" || data != ""):
+			return
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func (c *sseClient) post(id, token, message string, header http.Header) int {
+	req, _ := http.NewRequest(http.MethodPost, c.url, strings.NewReader(message))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set(websocket.SSEConnectionIDHeaderKey, id)
+	req.Header.Set(websocket.SSEConnectionTokenHeaderKey, token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func (c *sseClient) handshake() (token, id string) {
+	event, token := c.next()
+	if event != "token" || token == "" {
+		c.t.Fatalf("expected the token event but got '%s': '%s'", event, token)
+	}
+
+	event, id = c.next()
+	if event != "open" || id == "" {
+		c.t.Fatalf("expected the open event but got '%s': '%s'", event, id)
+	}
+
+	return
+}
+
+func expectMessage(t *testing.T, messages <-chan string, expected string) {
+	select {
+	case got := <-messages:
+		if got != expected {
+			t.Fatalf("expected message '%s' but got '%s'", expected, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout while waiting for the message '%s'", expected)
+	}
+}
+
+func TestSSEToken(t *testing.T) {
+	messages := make(chan string, 1)
+	srv := newSSEServer(t, websocket.Config{}, messages)
+
+	c := openSSE(t, srv.URL+"/events", nil)
+	token, id := c.handshake()
+
+	// the connection's ID is not enough.
+	if expected, got := iris.StatusNotFound, c.post(id, "", "hi", nil); expected != got {
+		t.Fatalf("expected status code %d but got %d", expected, got)
+	}
+	if expected, got := iris.StatusNotFound, c.post(id, token+"x", "hi", nil); expected != got {
+		t.Fatalf("expected status code %d but got %d", expected, got)
+	}
+
+	if expected, got := iris.StatusNoContent, c.post(id, token, "hi", nil); expected != got {
+		t.Fatalf("expected status code %d but got %d", expected, got)
+	}
+	expectMessage(t, messages, "hi")
+
+	if event, data := c.next(); event != "message" || data != "echo: hi" {
+		t.Fatalf("expected the echo message but got '%s': '%s'", event, data)
+	}
+}
+
+func TestSSEOwner(t *testing.T) {
+	messages := make(chan string, 1)
+	srv := newSSEServer(t, websocket.Config{
+		SSEOwner: func(ctx iris.Context) string {
+			return ctx.GetHeader("X-User")
+		},
+	}, messages)
+
+	owner := http.Header{"X-User": {"kataras"}}
+	c := openSSE(t, srv.URL+"/events", owner)
+	token, id := c.handshake()
+
+	if expected, got := iris.StatusNotFound, c.post(id, token, "hi", http.Header{"X-User": {"other"}}); expected != got {
+		t.Fatalf("expected status code %d but got %d", expected, got)
+	}
+	if expected, got := iris.StatusNotFound, c.post(id, token, "hi", nil); expected != got {
+		t.Fatalf("expected status code %d but got %d", expected, got)
+	}
+
+	if expected, got := iris.StatusNoContent, c.post(id, token, "hi", owner); expected != got {
+		t.Fatalf("expected status code %d but got %d", expected, got)
+	}
+	expectMessage(t, messages, "hi")
+}
+
+func TestSSEGzip(t *testing.T) {
+	messages := make(chan string, 1)
+	// the events should be flushed to the client even if the route's response is gzipped.
+	srv := newSSEServer(t, websocket.Config{}, messages, func(ctx iris.Context) {
+		ctx.Gzip(true)
+		ctx.Next()
+	})
+
+	c := openSSE(t, srv.URL+"/events", http.Header{"Accept-Encoding": {"gzip"}})
+	if got := c.resp.Header.Get("Content-Encoding"); got != "" {
+		t.Fatalf("expected a plain stream but got content encoding '%s'", got)
+	}
+
+	token, id := c.handshake()
+	if expected, got := iris.StatusNoContent, c.post(id, token, "hi", nil); expected != got {
+		t.Fatalf("expected status code %d but got %d", expected, got)
+	}
+	expectMessage(t, messages, "hi")
+}
+
+func TestEmitEvents(t *testing.T) {
+	bus := events.New()
+	defer bus.Close(context.Background())
+
+	ws := websocket.New(websocket.Config{})
+	ws.EmitEvents(bus, "products.*")
+
+	connected := make(chan struct{})
+	ws.OnConnection(func(c websocket.Connection) {
+		close(connected)
+	})
+
+	app := iris.New()
+	app.Any("/events", ws.SSEHandler())
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(app)
+	// closed after the stream, see `openSSE`.
+	t.Cleanup(srv.Close)
+
+	c := openSSE(t, srv.URL+"/events", nil)
+	c.handshake()
+	<-connected
+
+	if err := bus.Publish("products.updated", "42"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := websocket.DefaultEvtMessageKey + "products.updated;0;42"
+	if event, data := c.next(); event != "message" || data != expected {
+		t.Fatalf("expected the message '%s' but got '%s': '%s'", expected, event, data)
+	}
+}